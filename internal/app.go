@@ -91,6 +91,13 @@ func setup(app *orz.App) error {
 
 	// 启动指标监控任务（用于告警检测）
 	go startMetricsMonitoring(ctx, components, app.Logger())
+	// 启动未确认告警的重复通知和升级任务
+	go startAlertEscalation(ctx, components, app.Logger())
+	// 启动预测告警任务
+	go startForecastAlerts(ctx, components, app.Logger())
+
+	// 将指标服务注入到 Notifier，用于通知模板读取最新指标（避免循环依赖）
+	components.Notifier.SetMetricService(components.MetricService)
 
 	// 启动服务监控任务调度器
 	monitorScheduler := scheduler.NewMonitorScheduler(components.MonitorService, app.Logger())
@@ -98,6 +105,11 @@ func setup(app *orz.App) error {
 	components.MonitorService.SetScheduler(monitorScheduler)
 	monitorScheduler.Start(ctx)
 
+	// 启动定时审计调度器
+	auditScheduler := scheduler.NewAuditScheduler(components.AuditScheduleService, app.Logger())
+	components.AuditScheduleService.SetScheduler(auditScheduler)
+	auditScheduler.Start(ctx)
+
 	// 启动流量重置检查任务(每小时检查一次)
 	go startTrafficResetCheck(ctx, components, app.Logger())
 
@@ -250,10 +262,39 @@ func setupApi(app *orz.App, components *AppComponents) error {
 		// VPS审计结果（管理员访问）
 		adminApi.GET("/agents/:id/audit/result", components.AgentHandler.GetAuditResult)
 		adminApi.GET("/agents/:id/audit/results", components.AgentHandler.ListAuditResults)
+		adminApi.GET("/agents/:id/audit/diff", components.AgentHandler.DiffAuditResults)
+
+		// 定时审计
+		adminApi.GET("/audit-schedules", components.AuditScheduleHandler.Paging)
+		adminApi.POST("/audit-schedules", components.AuditScheduleHandler.Create)
+		adminApi.GET("/audit-schedules/:id", components.AuditScheduleHandler.Get)
+		adminApi.PUT("/audit-schedules/:id", components.AuditScheduleHandler.Update)
+		adminApi.DELETE("/audit-schedules/:id", components.AuditScheduleHandler.Delete)
+		adminApi.POST("/audit-schedules/:id/run", components.AuditScheduleHandler.Run)
+
+		// 审计规则包
+		adminApi.GET("/audit-rule-packs", components.AuditRuleHandler.List)
+		adminApi.POST("/audit-rule-packs", components.AuditRuleHandler.Create)
+		adminApi.POST("/audit-rule-packs/validate", components.AuditRuleHandler.Validate)
+		adminApi.POST("/audit-rule-packs/reload", components.AuditRuleHandler.Reload)
+		adminApi.GET("/audit-rule-packs/:id", components.AuditRuleHandler.Get)
+		adminApi.PUT("/audit-rule-packs/:id", components.AuditRuleHandler.Update)
+		adminApi.DELETE("/audit-rule-packs/:id", components.AuditRuleHandler.Delete)
+		adminApi.POST("/audit-rule-packs/:id/enable", components.AuditRuleHandler.Enable)
+		adminApi.POST("/audit-rule-packs/:id/disable", components.AuditRuleHandler.Disable)
+
+		// 离线漏洞库
+		adminApi.GET("/vuln-db/status", components.VulnerabilityHandler.Status)
+		adminApi.POST("/vuln-db/import", components.VulnerabilityHandler.Import)
+		adminApi.DELETE("/vuln-db", components.VulnerabilityHandler.Clear)
+		adminApi.GET("/agents/:id/vulnerabilities", components.VulnerabilityHandler.GetAgentVulnerabilities)
 
 		// 防篡改管理（管理员功能）
 		adminApi.GET("/agents/:id/tamper/config", components.TamperHandler.GetConfig)
 		adminApi.PUT("/agents/:id/tamper/config", components.TamperHandler.UpdateConfig)
+		adminApi.POST("/agents/:id/tamper/snapshot", components.TamperHandler.RefreshSnapshot)
+		adminApi.POST("/agents/:id/tamper/unlock", components.TamperHandler.Unlock)
+		adminApi.DELETE("/agents/:id/tamper/unlock", components.TamperHandler.Relock)
 		adminApi.GET("/agents/:id/tamper/events", components.TamperHandler.ListEvents)
 		adminApi.DELETE("/agents/:id/tamper/events", components.TamperHandler.DeleteEvents)
 
@@ -262,17 +303,49 @@ func setupApi(app *orz.App, components *AppComponents) error {
 		adminApi.POST("/agents/:id/ssh-login/config", components.SSHLoginHandler.UpdateConfig)
 		adminApi.GET("/agents/:id/ssh-login/events", components.SSHLoginHandler.ListEvents)
 		adminApi.DELETE("/agents/:id/ssh-login/events", components.SSHLoginHandler.DeleteEvents)
+		adminApi.GET("/agents/:id/ssh-login/profile", components.SSHLoginHandler.GetProfile)
+		adminApi.GET("/agents/:id/ssh-login/bans", components.SSHLoginHandler.ListBans)
+		adminApi.DELETE("/agents/:id/ssh-login/bans/:banId", components.SSHLoginHandler.LiftBan)
+		adminApi.GET("/agents/:id/ssh-login/sessions", components.SSHLoginHandler.ListSessions)
+		adminApi.GET("/agents/:id/ssh-login/sessions/:sessionId/sudo", components.SSHLoginHandler.ListSessionSudoEvents)
 
 		// 通用属性管理
 		adminApi.GET("/properties/:id", components.PropertyHandler.GetProperty)
 		adminApi.PUT("/properties/:id", components.PropertyHandler.SetProperty)
 
-		// 通知渠道测试（从数据库读取配置测试）
-		adminApi.POST("/notification-channels/:type/test", components.PropertyHandler.TestNotificationChannel)
+		// 通知渠道测试（从数据库读取配置测试，按渠道ID，兼容旧版按类型）
+		adminApi.POST("/notification-channels/:id/test", components.PropertyHandler.TestNotificationChannel)
+		// 通知模板预览（使用示例数据渲染）
+		adminApi.POST("/notification-channels/template/preview", components.PropertyHandler.PreviewNotificationTemplate)
 
 		// 告警记录查询
 		adminApi.GET("/alert-records", components.AlertHandler.ListAlertRecords)
 		adminApi.DELETE("/alert-records", components.AlertHandler.ClearAlertRecords)
+		adminApi.POST("/alert-records/:id/ack", components.AlertHandler.AcknowledgeAlertRecord)
+		adminApi.POST("/alert-records/:id/assign", components.AlertHandler.AssignAlertRecord)
+
+		// 告警规则集
+		adminApi.GET("/alert-rule-sets", components.AlertHandler.ListRuleSets)
+		adminApi.POST("/alert-rule-sets", components.AlertHandler.CreateRuleSet)
+		adminApi.GET("/alert-rule-sets/:id", components.AlertHandler.GetRuleSet)
+		adminApi.PUT("/alert-rule-sets/:id", components.AlertHandler.UpdateRuleSet)
+		adminApi.DELETE("/alert-rule-sets/:id", components.AlertHandler.DeleteRuleSet)
+		adminApi.GET("/agents/:id/alert-rules", components.AlertHandler.GetEffectiveRules)
+
+		// PromQL 告警规则
+		adminApi.GET("/alert-promql-rules", components.AlertHandler.ListPromQLRules)
+		adminApi.POST("/alert-promql-rules", components.AlertHandler.CreatePromQLRule)
+		adminApi.POST("/alert-promql-rules/preview", components.AlertHandler.PreviewPromQL)
+		adminApi.GET("/alert-promql-rules/:id", components.AlertHandler.GetPromQLRule)
+		adminApi.PUT("/alert-promql-rules/:id", components.AlertHandler.UpdatePromQLRule)
+		adminApi.DELETE("/alert-promql-rules/:id", components.AlertHandler.DeletePromQLRule)
+
+		// 告警静默
+		adminApi.GET("/silences", components.AlertSilenceHandler.Paging)
+		adminApi.POST("/silences", components.AlertSilenceHandler.Create)
+		adminApi.GET("/silences/:id", components.AlertSilenceHandler.Get)
+		adminApi.PUT("/silences/:id", components.AlertSilenceHandler.Update)
+		adminApi.DELETE("/silences/:id", components.AlertSilenceHandler.Delete)
 
 		// 服务监控配置
 		adminApi.GET("/monitors", components.MonitorHandler.List)
@@ -310,17 +383,26 @@ func setupApi(app *orz.App, components *AppComponents) error {
 func autoMigrate(database *gorm.DB) error {
 	// 自动迁移数据库表
 	return database.AutoMigrate(
-		&models.Agent{},         // 探针
-		&models.ApiKey{},        // ApiKey
-		&models.AuditResult{},   // 审计历史
-		&models.Property{},      // 系统属性
-		&models.AlertRecord{},   // 告警记录
-		&models.AlertState{},    // 告警状态
-		&models.MonitorTask{},   // 服务监控
-		&models.TamperEvent{},   // 防篡改事件
-		&models.DDNSConfig{},    // DDNS 配置
-		&models.DDNSRecord{},    // DDNS 记录
-		&models.SSHLoginEvent{}, // SSH 登录事件
+		&models.Agent{},           // 探针
+		&models.ApiKey{},          // ApiKey
+		&models.AuditResult{},     // 审计历史
+		&models.Property{},        // 系统属性
+		&models.AlertRecord{},     // 告警记录
+		&models.AlertState{},      // 告警状态
+		&models.AlertRuleSet{},    // 告警规则集
+		&models.AlertPromQLRule{}, // PromQL 告警规则
+		&models.AlertSilence{},    // 告警静默
+		&models.MonitorTask{},     // 服务监控
+		&models.TamperEvent{},     // 防篡改事件
+		&models.DDNSConfig{},      // DDNS 配置
+		&models.DDNSRecord{},      // DDNS 记录
+		&models.SSHLoginEvent{},   // SSH 登录事件
+		&models.SSHBan{},          // SSH 暴力破解封禁
+		&models.SSHSession{},      // SSH 会话
+		&models.SSHSudoEvent{},    // SSH 会话内的 sudo 记录
+		&models.AuditRulePack{},   // 审计规则包
+		&models.AuditSchedule{},   // 定时审计计划
+		&models.Vulnerability{},   // 离线漏洞库
 	)
 }
 
@@ -376,54 +458,109 @@ func startMetricsMonitoring(ctx context.Context, components *AppComponents, logg
 			logger.Info("指标监控任务已停止")
 			return
 		case <-ticker.C:
-			// 检查所有在线探针的最新指标
-			agents, err := components.AgentService.ListOnlineAgents(ctx)
+			// 每轮检查只加载一次告警配置和规则集，所有探针共用
+			resolver, err := components.AlertService.NewAlertRuleResolver(ctx)
 			if err != nil {
-				logger.Error("获取在线探针失败", zap.Error(err))
-				continue
-			}
+				logger.Error("加载告警规则失败", zap.Error(err))
+			} else {
+				checkMetricAlerts(ctx, components, resolver, logger)
 
-			for _, agent := range agents {
-				// 获取最新指标
-				latest, ok := components.MetricService.GetLatestMetrics(agent.ID)
-				if !ok {
-					logger.Debug("获取探针最新指标失败", zap.String("agentId", agent.ID))
-					continue
-				}
-				if latest == nil {
-					logger.Debug("探针最新指标为空", zap.String("agentId", agent.ID))
-					continue
+				// 检查监控相关告警（证书和服务下线）
+				if err := components.AlertService.CheckMonitorAlerts(ctx, resolver); err != nil {
+					logger.Error("检查监控告警失败", zap.Error(err))
 				}
+			}
 
-				// 提取 CPU、内存、磁盘使用率、网速
-				var cpuUsage, memoryUsage, diskUsage, networkSpeed float64
+			// 评估自定义 PromQL 告警规则
+			if err := components.AlertService.EvaluatePromQLRules(ctx); err != nil {
+				logger.Error("评估PromQL告警规则失败", zap.Error(err))
+			}
+		}
+	}
+}
 
-				if latest.CPU != nil {
-					cpuUsage = latest.CPU.UsagePercent
-				}
+// checkMetricAlerts 检查所有在线探针的最新指标
+func checkMetricAlerts(ctx context.Context, components *AppComponents, resolver *service.AlertRuleResolver, logger *zap.Logger) {
+	agents, err := components.AgentService.ListOnlineAgents(ctx)
+	if err != nil {
+		logger.Error("获取在线探针失败", zap.Error(err))
+		return
+	}
 
-				if latest.Memory != nil {
-					memoryUsage = latest.Memory.UsagePercent
-				}
+	for _, agent := range agents {
+		// 获取最新指标
+		latest, ok := components.MetricService.GetLatestMetrics(agent.ID)
+		if !ok {
+			logger.Debug("获取探针最新指标失败", zap.String("agentId", agent.ID))
+			continue
+		}
+		if latest == nil {
+			logger.Debug("探针最新指标为空", zap.String("agentId", agent.ID))
+			continue
+		}
 
-				if latest.Disk != nil {
-					diskUsage = latest.Disk.UsagePercent
-				}
+		// 提取 CPU、内存、磁盘使用率、网速
+		var cpuUsage, memoryUsage, diskUsage, networkSpeed float64
 
-				if latest.Network != nil {
-					// 网速 = (发送速率 + 接收速率) / 1024 / 1024 (转换为 MB/s)
-					networkSpeed = float64(latest.Network.TotalBytesSentRate+latest.Network.TotalBytesRecvRate) / 1024 / 1024
-				}
+		if latest.CPU != nil {
+			cpuUsage = latest.CPU.UsagePercent
+		}
 
-				// 检查告警规则
-				if err := components.AlertService.CheckMetrics(ctx, agent.ID, cpuUsage, memoryUsage, diskUsage, networkSpeed); err != nil {
-					logger.Error("检查告警规则失败", zap.String("agentId", agent.ID), zap.Error(err))
-				}
+		if latest.Memory != nil {
+			memoryUsage = latest.Memory.UsagePercent
+		}
+
+		if latest.Disk != nil {
+			diskUsage = latest.Disk.UsagePercent
+		}
+
+		if latest.Network != nil {
+			// 网速 = (发送速率 + 接收速率) / 1024 / 1024 (转换为 MB/s)
+			networkSpeed = float64(latest.Network.TotalBytesSentRate+latest.Network.TotalBytesRecvRate) / 1024 / 1024
+		}
+
+		// 检查告警规则
+		if err := components.AlertService.CheckMetrics(ctx, resolver, agent.ID, cpuUsage, memoryUsage, diskUsage, networkSpeed, latest.Disks, latest.NetworkInterfaces); err != nil {
+			logger.Error("检查告警规则失败", zap.String("agentId", agent.ID), zap.Error(err))
+		}
+	}
+}
+
+// startAlertEscalation 启动未确认告警的重复通知和升级任务
+func startAlertEscalation(ctx context.Context, components *AppComponents, logger *zap.Logger) {
+	logger.Info("启动告警升级任务")
+
+	ticker := time.NewTicker(1 * time.Minute) // 每分钟检查一次
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("告警升级任务已停止")
+			return
+		case <-ticker.C:
+			if err := components.AlertService.ProcessUnacknowledgedAlerts(ctx); err != nil {
+				logger.Error("处理未确认告警失败", zap.Error(err))
 			}
+		}
+	}
+}
+
+// startForecastAlerts 启动预测告警检查任务（磁盘写满预测和流量超额预测）
+func startForecastAlerts(ctx context.Context, components *AppComponents, logger *zap.Logger) {
+	logger.Info("启动预测告警任务")
+
+	ticker := time.NewTicker(5 * time.Minute) // 每5分钟检查一次
+	defer ticker.Stop()
 
-			// 检查监控相关告警（证书和服务下线）
-			if err := components.AlertService.CheckMonitorAlerts(ctx); err != nil {
-				logger.Error("检查监控告警失败", zap.Error(err))
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("预测告警任务已停止")
+			return
+		case <-ticker.C:
+			if err := components.AlertService.CheckForecastAlerts(ctx); err != nil {
+				logger.Error("检查预测告警失败", zap.Error(err))
 			}
 		}
 	}
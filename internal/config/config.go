@@ -8,6 +8,7 @@ type AppConfig struct {
 	GitHub          *GitHubOAuthConfig `json:"GitHub"`          // GitHub OAuth配置（可选）
 	GeoIP           *GeoIPConfig       `json:"GeoIP"`           // GeoIP配置（可选）
 	VictoriaMetrics *VMConfig          `json:"VictoriaMetrics"` // VictoriaMetrics配置（可选）
+	Audit           *AuditConfig       `json:"Audit"`           // 安全审计配置（可选）
 }
 
 // JWTConfig JWT配置
@@ -39,6 +40,7 @@ type GeoIPConfig struct {
 	Enabled    bool   `json:"Enabled"`    // 是否启用GeoIP查询
 	DBPath     string `json:"DBPath"`     // GeoIP数据库文件路径（如：GeoLite2-City.mmdb）
 	DBLanguage string `json:"DBLanguage"` // 数据库语言（如：zh-CN、en）
+	ASNDBPath  string `json:"ASNDBPath"`  // ASN数据库文件路径（可选，如：GeoLite2-ASN.mmdb），用于SSH异常登录检测
 }
 
 // VMConfig VictoriaMetrics配置
@@ -49,3 +51,8 @@ type VMConfig struct {
 	WriteTimeout  int    `json:"WriteTimeout"`  // 写入超时（秒）
 	QueryTimeout  int    `json:"QueryTimeout"`  // 查询超时（秒）
 }
+
+// AuditConfig 安全审计配置
+type AuditConfig struct {
+	RulePackDir string `json:"RulePackDir"` // YAML 规则包目录（启动时加载 *.yaml/*.yml）
+}
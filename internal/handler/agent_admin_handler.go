@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -115,7 +116,7 @@ func (h *AgentHandler) SendCommand(c echo.Context) error {
 	})
 }
 
-// GetAuditResult 获取审计结果(原始数据)
+// GetAuditResult 获取审计结果(原始数据及安全分析)
 func (h *AgentHandler) GetAuditResult(c echo.Context) error {
 	agentID := c.Param("id")
 	ctx := c.Request().Context()
@@ -144,6 +145,35 @@ func (h *AgentHandler) ListAuditResults(c echo.Context) error {
 	})
 }
 
+// DiffAuditResults 对比两次审计结果（base/target 为空时对比最近两次）
+func (h *AgentHandler) DiffAuditResults(c echo.Context) error {
+	agentID := c.Param("id")
+	ctx := c.Request().Context()
+
+	var baseID, targetID int64
+	if v := c.QueryParam("base"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return orz.NewError(400, "base 参数格式错误")
+		}
+		baseID = id
+	}
+	if v := c.QueryParam("target"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return orz.NewError(400, "target 参数格式错误")
+		}
+		targetID = id
+	}
+
+	diff, err := h.agentService.DiffAuditResults(ctx, agentID, baseID, targetID)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, diff)
+}
+
 // UpdateInfo 更新探针信息（名称、标签、到期时间、可见性、权重、备注）
 func (h *AgentHandler) UpdateInfo(c echo.Context) error {
 	agentID := c.Param("id")
@@ -97,6 +97,10 @@ func (h *AgentHandler) handleWebSocketMessage(ctx context.Context, agentID strin
 
 	case protocol.MessageTypeSSHLoginConfigResult:
 		return h.handleSSHLoginConfigResultMessage(ctx, agentID, data)
+	case protocol.MessageTypeSSHBanResult:
+		return h.handleSSHBanResultMessage(ctx, agentID, data)
+	case protocol.MessageTypeSSHSudoEvent:
+		return h.handleSSHSudoEventMessage(ctx, agentID, data)
 
 	case protocol.MessageTypeTamperProtect:
 		return h.handleTamperProtectMessage(ctx, agentID, data)
@@ -233,6 +237,24 @@ func (h *AgentHandler) handleSSHLoginConfigResultMessage(ctx context.Context, ag
 	return h.sshLoginService.HandleConfigResult(ctx, agentID, resultData)
 }
 
+func (h *AgentHandler) handleSSHBanResultMessage(ctx context.Context, agentID string, data json.RawMessage) error {
+	var resultData protocol.SSHBanResult
+	if err := json.Unmarshal(data, &resultData); err != nil {
+		h.logger.Error("failed to unmarshal ssh ban result", zap.Error(err))
+		return err
+	}
+	return h.sshLoginService.HandleBanResult(ctx, agentID, resultData)
+}
+
+func (h *AgentHandler) handleSSHSudoEventMessage(ctx context.Context, agentID string, data json.RawMessage) error {
+	var eventData protocol.SSHSudoEvent
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		h.logger.Error("failed to unmarshal ssh sudo event", zap.Error(err))
+		return err
+	}
+	return h.sshLoginService.HandleSudoEvent(ctx, agentID, eventData)
+}
+
 func (h *AgentHandler) handleTamperProtectMessage(ctx context.Context, agentID string, data json.RawMessage) error {
 	var protectResp protocol.TamperProtectResponse
 	if err := json.Unmarshal(data, &protectResp); err != nil {
@@ -270,17 +292,11 @@ func (h *AgentHandler) sendRegisterError(conn *websocket.Conn, errMsg string) er
 // sendTamperConfig 发送防篡改配置（探针初始化时发送完整配置作为新增）
 func (h *AgentHandler) sendTamperConfig(conn *websocket.Conn, agentID string) error {
 	// 使用 TamperService 构建初始配置（复用逻辑，会自动判断 enabled 状态）
-	added, removed, err := h.tamperService.BuildInitialConfig(context.Background(), agentID)
+	configData, err := h.tamperService.BuildInitialConfig(context.Background(), agentID)
 	if err != nil {
 		return err
 	}
 
-	// 使用增量配置格式
-	configData := protocol.TamperProtectConfig{
-		Added:   added,
-		Removed: removed,
-	}
-
 	msgData, err := json.Marshal(protocol.OutboundMessage{
 		Type: protocol.MessageTypeTamperProtect,
 		Data: configData,
@@ -293,15 +309,13 @@ func (h *AgentHandler) sendTamperConfig(conn *websocket.Conn, agentID string) er
 }
 
 func (h *AgentHandler) sendSSHLoginConfig(conn *websocket.Conn, agentID string) error {
-	config, err := h.sshLoginService.GetConfig(context.Background(), agentID)
+	config, err := h.sshLoginService.BuildAgentConfig(context.Background(), agentID)
 	if err != nil {
 		return err
 	}
 	msgData, err := json.Marshal(protocol.OutboundMessage{
 		Type: protocol.MessageTypeSSHLoginConfig,
-		Data: protocol.SSHLoginConfig{
-			Enabled: config.Enabled,
-		},
+		Data: config,
 	})
 	if err != nil {
 		return err
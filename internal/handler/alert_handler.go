@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strconv"
+
 	"github.com/dushixiang/pika/internal/service"
 	"github.com/go-orz/orz"
 	"github.com/labstack/echo/v4"
@@ -22,6 +24,8 @@ func NewAlertHandler(logger *zap.Logger, alertService *service.AlertService) *Al
 // ListAlertRecords 列出告警记录
 func (h *AlertHandler) ListAlertRecords(c echo.Context) error {
 	agentID := c.QueryParam("agentId")
+	ruleSource := c.QueryParam("ruleSource")
+	ruleSetID := c.QueryParam("ruleSetId")
 
 	pr := orz.GetPageRequest(c, "createdAt", "firedAt")
 
@@ -31,6 +35,19 @@ func (h *AlertHandler) ListAlertRecords(c echo.Context) error {
 	if agentID != "" {
 		builder.Equal("agent_id", agentID)
 	}
+	if ruleSource != "" {
+		builder.Equal("rule_source", ruleSource)
+	}
+	if ruleSetID != "" {
+		builder.Equal("rule_set_id", ruleSetID)
+	}
+	switch c.QueryParam("acknowledged") {
+	case "true":
+		builder.NotEqual("acknowledged_at", 0)
+	case "false":
+		builder.Equal("acknowledged_at", 0)
+	}
+	builder.Equal("assignee", c.QueryParam("assignee"))
 
 	ctx := c.Request().Context()
 	page, err := builder.Execute(ctx)
@@ -42,6 +59,46 @@ func (h *AlertHandler) ListAlertRecords(c echo.Context) error {
 	return orz.Ok(c, page)
 }
 
+// AcknowledgeAlertRecord 确认告警
+func (h *AlertHandler) AcknowledgeAlertRecord(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return orz.NewError(400, "告警记录ID格式错误")
+	}
+
+	var req service.AlertAckRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	operator, _ := c.Get("username").(string)
+	record, err := h.alertService.AcknowledgeAlert(c.Request().Context(), id, operator, &req)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, record)
+}
+
+// AssignAlertRecord 指派告警负责人
+func (h *AlertHandler) AssignAlertRecord(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return orz.NewError(400, "告警记录ID格式错误")
+	}
+
+	var req service.AlertAssignRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	operator, _ := c.Get("username").(string)
+	record, err := h.alertService.AssignAlert(c.Request().Context(), id, operator, &req)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, record)
+}
+
 // ClearAlertRecords 清空告警记录
 func (h *AlertHandler) ClearAlertRecords(c echo.Context) error {
 	if err := h.alertService.Clear(c.Request().Context()); err != nil {
@@ -51,3 +108,159 @@ func (h *AlertHandler) ClearAlertRecords(c echo.Context) error {
 
 	return orz.Ok(c, orz.Map{})
 }
+
+// ListRuleSets 列出告警规则集
+func (h *AlertHandler) ListRuleSets(c echo.Context) error {
+	ruleSets, err := h.alertService.ListRuleSets(c.Request().Context())
+	if err != nil {
+		h.logger.Error("获取告警规则集失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, ruleSets)
+}
+
+// GetRuleSet 获取告警规则集详情
+func (h *AlertHandler) GetRuleSet(c echo.Context) error {
+	ruleSet, err := h.alertService.GetRuleSet(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, ruleSet)
+}
+
+// CreateRuleSet 创建告警规则集
+func (h *AlertHandler) CreateRuleSet(c echo.Context) error {
+	var req service.AlertRuleSetRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ruleSet, err := h.alertService.CreateRuleSet(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("创建告警规则集失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, ruleSet)
+}
+
+// UpdateRuleSet 更新告警规则集
+func (h *AlertHandler) UpdateRuleSet(c echo.Context) error {
+	var req service.AlertRuleSetRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ruleSet, err := h.alertService.UpdateRuleSet(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		h.logger.Error("更新告警规则集失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, ruleSet)
+}
+
+// DeleteRuleSet 删除告警规则集
+func (h *AlertHandler) DeleteRuleSet(c echo.Context) error {
+	if err := h.alertService.DeleteRuleSet(c.Request().Context(), c.Param("id")); err != nil {
+		h.logger.Error("删除告警规则集失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
+
+// GetEffectiveRules 获取探针生效的告警规则
+func (h *AlertHandler) GetEffectiveRules(c echo.Context) error {
+	rules, err := h.alertService.GetEffectiveAlertRules(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, rules)
+}
+
+// ListPromQLRules 列出 PromQL 告警规则
+func (h *AlertHandler) ListPromQLRules(c echo.Context) error {
+	rules, err := h.alertService.ListPromQLRules(c.Request().Context())
+	if err != nil {
+		h.logger.Error("获取PromQL告警规则失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, rules)
+}
+
+// GetPromQLRule 获取 PromQL 告警规则详情
+func (h *AlertHandler) GetPromQLRule(c echo.Context) error {
+	rule, err := h.alertService.GetPromQLRule(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, rule)
+}
+
+// CreatePromQLRule 创建 PromQL 告警规则
+func (h *AlertHandler) CreatePromQLRule(c echo.Context) error {
+	var req service.AlertPromQLRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rule, err := h.alertService.CreatePromQLRule(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("创建PromQL告警规则失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, rule)
+}
+
+// UpdatePromQLRule 更新 PromQL 告警规则
+func (h *AlertHandler) UpdatePromQLRule(c echo.Context) error {
+	var req service.AlertPromQLRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rule, err := h.alertService.UpdatePromQLRule(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		h.logger.Error("更新PromQL告警规则失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, rule)
+}
+
+// DeletePromQLRule 删除 PromQL 告警规则
+func (h *AlertHandler) DeletePromQLRule(c echo.Context) error {
+	if err := h.alertService.DeletePromQLRule(c.Request().Context(), c.Param("id")); err != nil {
+		h.logger.Error("删除PromQL告警规则失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
+
+// PreviewPromQL 预览 PromQL 表达式当前返回的序列
+func (h *AlertHandler) PreviewPromQL(c echo.Context) error {
+	var req struct {
+		Expr string `json:"expr" validate:"required"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	series, err := h.alertService.PreviewPromQL(c.Request().Context(), req.Expr)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, series)
+}
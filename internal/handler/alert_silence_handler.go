@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type AlertSilenceHandler struct {
+	logger         *zap.Logger
+	silenceService *service.AlertSilenceService
+}
+
+func NewAlertSilenceHandler(logger *zap.Logger, silenceService *service.AlertSilenceService) *AlertSilenceHandler {
+	return &AlertSilenceHandler{
+		logger:         logger,
+		silenceService: silenceService,
+	}
+}
+
+// Paging 告警静默分页查询
+// GET /api/admin/silences
+func (h *AlertSilenceHandler) Paging(c echo.Context) error {
+	pr := orz.GetPageRequest(c, "createdAt", "name")
+
+	builder := orz.NewPageBuilder(h.silenceService.AlertSilenceRepo.Repository).
+		PageRequest(pr).
+		Contains("name", c.QueryParam("name"))
+
+	page, err := builder.Execute(c.Request().Context())
+	if err != nil {
+		h.logger.Error("获取告警静默失败", zap.Error(err))
+		return err
+	}
+	h.silenceService.FillActive(page.Items)
+
+	return orz.Ok(c, page)
+}
+
+// Get 获取告警静默详情
+// GET /api/admin/silences/:id
+func (h *AlertSilenceHandler) Get(c echo.Context) error {
+	silence, err := h.silenceService.GetSilence(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, silence)
+}
+
+// Create 创建告警静默
+// POST /api/admin/silences
+func (h *AlertSilenceHandler) Create(c echo.Context) error {
+	var req service.AlertSilenceRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	silence, err := h.silenceService.CreateSilence(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("创建告警静默失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, silence)
+}
+
+// Update 更新告警静默
+// PUT /api/admin/silences/:id
+func (h *AlertSilenceHandler) Update(c echo.Context) error {
+	var req service.AlertSilenceRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	silence, err := h.silenceService.UpdateSilence(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		h.logger.Error("更新告警静默失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, silence)
+}
+
+// Delete 删除告警静默
+// DELETE /api/admin/silences/:id
+func (h *AlertSilenceHandler) Delete(c echo.Context) error {
+	if err := h.silenceService.DeleteSilence(c.Request().Context(), c.Param("id")); err != nil {
+		h.logger.Error("删除告警静默失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
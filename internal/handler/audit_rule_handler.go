@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"io"
+
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type AuditRuleHandler struct {
+	logger           *zap.Logger
+	auditRuleService *service.AuditRuleService
+}
+
+func NewAuditRuleHandler(logger *zap.Logger, auditRuleService *service.AuditRuleService) *AuditRuleHandler {
+	return &AuditRuleHandler{
+		logger:           logger,
+		auditRuleService: auditRuleService,
+	}
+}
+
+// maxAuditRulePackSize 规则包文件大小上限
+const maxAuditRulePackSize = 1 << 20
+
+// AuditRulePackRequest 规则包上传/更新请求
+type AuditRulePackRequest struct {
+	Content string `json:"content"` // YAML 内容
+}
+
+// readContent 读取规则包内容，支持 JSON 请求体或 multipart 文件上传（字段名 file）
+func (h *AuditRuleHandler) readContent(c echo.Context) (string, error) {
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		// 多读取一个字节判断是否超出限制，避免截断后的 YAML 被当作完整规则包
+		data, err := io.ReadAll(io.LimitReader(file, maxAuditRulePackSize+1))
+		if err != nil {
+			return "", err
+		}
+		if len(data) > maxAuditRulePackSize {
+			return "", orz.NewError(400, "规则包文件不能超过 1MB")
+		}
+		return string(data), nil
+	}
+
+	var req AuditRulePackRequest
+	if err := c.Bind(&req); err != nil {
+		return "", err
+	}
+	if req.Content == "" {
+		return "", orz.NewError(400, "规则包内容不能为空")
+	}
+	return req.Content, nil
+}
+
+// List 获取规则包列表
+func (h *AuditRuleHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	packs, err := h.auditRuleService.ListPacks(ctx)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"items": packs,
+		"total": len(packs),
+	})
+}
+
+// Get 获取规则包详情
+func (h *AuditRuleHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	pack, err := h.auditRuleService.GetPack(ctx, id)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, pack)
+}
+
+// Create 上传规则包
+func (h *AuditRuleHandler) Create(c echo.Context) error {
+	content, err := h.readContent(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	pack, err := h.auditRuleService.CreatePack(ctx, content)
+	if err != nil {
+		h.logger.Error("failed to create audit rule pack", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, pack)
+}
+
+// Update 更新规则包
+func (h *AuditRuleHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+	content, err := h.readContent(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	pack, err := h.auditRuleService.UpdatePack(ctx, id, content)
+	if err != nil {
+		h.logger.Error("failed to update audit rule pack", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, pack)
+}
+
+// Delete 删除规则包
+func (h *AuditRuleHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	if err := h.auditRuleService.DeletePack(ctx, id); err != nil {
+		h.logger.Error("failed to delete audit rule pack", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
+
+// Enable 启用规则包
+func (h *AuditRuleHandler) Enable(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	if err := h.auditRuleService.UpdatePackEnabled(ctx, id, true); err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
+
+// Disable 禁用规则包
+func (h *AuditRuleHandler) Disable(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	if err := h.auditRuleService.UpdatePackEnabled(ctx, id, false); err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
+
+// Validate 校验规则包内容
+func (h *AuditRuleHandler) Validate(c echo.Context) error {
+	content, err := h.readContent(c)
+	if err != nil {
+		return err
+	}
+
+	spec, err := service.ParseAuditRulePack([]byte(content))
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+	return orz.Ok(c, spec)
+}
+
+// Reload 重新加载规则包（包括磁盘目录）
+func (h *AuditRuleHandler) Reload(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.auditRuleService.Reload(ctx); err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type AuditScheduleHandler struct {
+	logger               *zap.Logger
+	auditScheduleService *service.AuditScheduleService
+}
+
+func NewAuditScheduleHandler(logger *zap.Logger, auditScheduleService *service.AuditScheduleService) *AuditScheduleHandler {
+	return &AuditScheduleHandler{
+		logger:               logger,
+		auditScheduleService: auditScheduleService,
+	}
+}
+
+// Paging 定时审计计划分页查询
+func (h *AuditScheduleHandler) Paging(c echo.Context) error {
+	name := c.QueryParam("name")
+
+	pr := orz.GetPageRequest(c, "created_at", "name")
+
+	builder := orz.NewPageBuilder(h.auditScheduleService.AuditScheduleRepo).
+		PageRequest(pr).
+		Contains("name", name)
+
+	ctx := c.Request().Context()
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{
+		"items": page.Items,
+		"total": page.Total,
+	})
+}
+
+// Create 创建定时审计计划
+func (h *AuditScheduleHandler) Create(c echo.Context) error {
+	var req service.AuditScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	schedule, err := h.auditScheduleService.CreateSchedule(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to create audit schedule", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, schedule)
+}
+
+// Get 获取定时审计计划详情
+func (h *AuditScheduleHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	schedule, err := h.auditScheduleService.GetSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, schedule)
+}
+
+// Update 更新定时审计计划
+func (h *AuditScheduleHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.AuditScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	schedule, err := h.auditScheduleService.UpdateSchedule(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to update audit schedule", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, schedule)
+}
+
+// Delete 删除定时审计计划
+func (h *AuditScheduleHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	if err := h.auditScheduleService.DeleteSchedule(ctx, id); err != nil {
+		h.logger.Error("failed to delete audit schedule", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
+
+// Run 立即执行定时审计计划
+func (h *AuditScheduleHandler) Run(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	if err := h.auditScheduleService.RunScheduleNow(ctx, id); err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{})
+}
@@ -85,6 +85,22 @@ func (h *PropertyHandler) SetProperty(c echo.Context) error {
 		}
 	}
 
+	// 特殊校验：通知渠道路由规则和模板语法，并为新渠道生成ID
+	if id == service.PropertyIDNotificationChannels {
+		data, err := json.Marshal(req.Value)
+		if err != nil {
+			return orz.NewError(400, "无效的通知渠道配置")
+		}
+		var channels []models.NotificationChannelConfig
+		if err := json.Unmarshal(data, &channels); err != nil {
+			return orz.NewError(400, "无效的通知渠道配置")
+		}
+		if err := service.NormalizeNotificationChannels(channels); err != nil {
+			return err
+		}
+		req.Value = channels
+	}
+
 	if err := h.service.Set(c.Request().Context(), id, req.Name, req.Value); err != nil {
 		h.logger.Error("设置属性失败", zap.String("id", id), zap.Error(err))
 		return orz.NewError(500, "设置属性失败")
@@ -169,9 +185,9 @@ func (h *PropertyHandler) GetLogo(c echo.Context) error {
 
 // TestNotificationChannel 测试通知渠道（从数据库读取配置）
 func (h *PropertyHandler) TestNotificationChannel(c echo.Context) error {
-	channelType := c.Param("type")
-	if channelType == "" {
-		return orz.NewError(400, "缺少渠道类型参数")
+	channelID := c.Param("id")
+	if channelID == "" {
+		return orz.NewError(400, "缺少渠道ID参数")
 	}
 
 	ctx := c.Request().Context()
@@ -182,15 +198,8 @@ func (h *PropertyHandler) TestNotificationChannel(c echo.Context) error {
 		return orz.NewError(500, "获取通知渠道配置失败")
 	}
 
-	// 查找指定类型的渠道
-	var targetChannel *models.NotificationChannelConfig
-	for i := range channels {
-		if channels[i].Type == channelType {
-			targetChannel = &channels[i]
-			break
-		}
-	}
-
+	// 查找指定的渠道（兼容旧版按类型测试的请求）
+	targetChannel := service.FindNotificationChannel(channels, channelID)
 	if targetChannel == nil {
 		return orz.NewError(404, "通知渠道不存在，请先配置")
 	}
@@ -204,9 +213,29 @@ func (h *PropertyHandler) TestNotificationChannel(c echo.Context) error {
 	sendErr := h.notifier.SendTestNotification(ctx, targetChannel.Type, targetChannel.Config, message)
 
 	if sendErr != nil {
-		h.logger.Error("发送测试通知失败", zap.String("type", channelType), zap.Error(sendErr))
+		h.logger.Error("发送测试通知失败", zap.String("channelId", channelID), zap.String("type", targetChannel.Type), zap.Error(sendErr))
 		return orz.NewError(500, "发送测试通知失败: "+sendErr.Error())
 	}
 
 	return orz.Ok(c, orz.Map{})
 }
+
+// PreviewNotificationTemplate 使用示例告警记录预览通知模板
+func (h *PropertyHandler) PreviewNotificationTemplate(c echo.Context) error {
+	var req struct {
+		Template string `json:"template"`
+		Event    string `json:"event"`
+		MaskIP   bool   `json:"maskIP"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "无效的请求参数")
+	}
+
+	content, err := h.notifier.PreviewTemplate(req.Template, req.Event, req.MaskIP)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"content": content,
+	})
+}
@@ -70,7 +70,8 @@ func (h *SSHLoginHandler) ListEvents(c echo.Context) error {
 		Equal("agentId", agentID).
 		Equal("username", c.QueryParam("username")).
 		Equal("ip", c.QueryParam("ip")).
-		Equal("status", c.QueryParam("status"))
+		Equal("status", c.QueryParam("status")).
+		Equal("severity", c.QueryParam("severity"))
 
 	ctx := c.Request().Context()
 	page, err := builder.Execute(ctx)
@@ -81,6 +82,20 @@ func (h *SSHLoginHandler) ListEvents(c echo.Context) error {
 	return orz.Ok(c, page)
 }
 
+// GetProfile 获取登录画像（常用国家、运营商、网段和登录时段），指定 username 时为该用户的画像
+// GET /api/admin/agents/:id/ssh-login/profile?username=
+func (h *SSHLoginHandler) GetProfile(c echo.Context) error {
+	agentID := c.Param("id")
+
+	profile, err := h.service.GetLoginProfile(c.Request().Context(), agentID, c.QueryParam("username"))
+	if err != nil {
+		h.logger.Error("获取SSH登录画像失败", zap.Error(err))
+		return orz.NewError(500, "获取登录画像失败")
+	}
+
+	return orz.Ok(c, profile)
+}
+
 // GetEvent 获取单个SSH登录事件
 // GET /api/ssh-login/events/:id
 func (h *SSHLoginHandler) GetEvent(c echo.Context) error {
@@ -116,3 +131,85 @@ func (h *SSHLoginHandler) DeleteEvents(c echo.Context) error {
 
 	return orz.Ok(c, orz.Map{})
 }
+
+// ListBans 查询探针的SSH封禁记录
+// GET /api/admin/agents/:id/ssh-login/bans
+func (h *SSHLoginHandler) ListBans(c echo.Context) error {
+	agentID := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.service.ExpireBans(ctx); err != nil {
+		h.logger.Warn("更新到期SSH封禁失败", zap.Error(err))
+	}
+
+	pageReq := orz.GetPageRequest(c, "createdAt")
+	builder := orz.NewPageBuilder(h.service.SSHBanRepo.Repository).
+		PageRequest(pageReq).
+		Equal("agentId", agentID).
+		Equal("status", c.QueryParam("status")).
+		Equal("cidr", c.QueryParam("cidr"))
+
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// LiftBan 手动解除SSH封禁
+// DELETE /api/admin/agents/:id/ssh-login/bans/:banId
+func (h *SSHLoginHandler) LiftBan(c echo.Context) error {
+	agentID := c.Param("id")
+	banID := c.Param("banId")
+
+	operator, _ := c.Get("username").(string)
+	if err := h.service.LiftBan(c.Request().Context(), agentID, banID, operator); err != nil {
+		h.logger.Error("解除SSH封禁失败", zap.Error(err), zap.String("agentId", agentID), zap.String("banId", banID))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
+// ListSessions 查询探针的SSH会话（在线和历史），在线会话的时长为截至当前的时长
+// GET /api/admin/agents/:id/ssh-login/sessions
+func (h *SSHLoginHandler) ListSessions(c echo.Context) error {
+	agentID := c.Param("id")
+
+	pageReq := orz.GetPageRequest(c, "startedAt")
+	builder := orz.NewPageBuilder(h.service.SSHSessionRepo.Repository).
+		PageRequest(pageReq).
+		Equal("agentId", agentID).
+		Equal("status", c.QueryParam("status")).
+		Equal("username", c.QueryParam("username")).
+		Equal("ip", c.QueryParam("ip"))
+
+	page, err := builder.Execute(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	h.service.FillLiveDuration(page.Items)
+
+	return orz.Ok(c, page)
+}
+
+// ListSessionSudoEvents 查询SSH会话内的 sudo 提权记录
+// GET /api/admin/agents/:id/ssh-login/sessions/:sessionId/sudo
+func (h *SSHLoginHandler) ListSessionSudoEvents(c echo.Context) error {
+	agentID := c.Param("id")
+	sessionID := c.Param("sessionId")
+
+	pageReq := orz.GetPageRequest(c, "timestamp")
+	builder := orz.NewPageBuilder(h.service.SSHSudoEventRepo.Repository).
+		PageRequest(pageReq).
+		Equal("agentId", agentID).
+		Equal("sessionId", sessionID)
+
+	page, err := builder.Execute(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
@@ -54,6 +54,70 @@ func (h *TamperHandler) GetConfig(c echo.Context) error {
 	return orz.Ok(c, config)
 }
 
+// RefreshSnapshot 以当前内容刷新强制恢复快照
+// POST /api/agents/:id/tamper/snapshot
+func (h *TamperHandler) RefreshSnapshot(c echo.Context) error {
+	agentID := c.Param("id")
+
+	var req struct {
+		Paths []string `json:"paths"` // 为空时刷新所有强制恢复目录
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := h.tamperService.RefreshSnapshot(c.Request().Context(), agentID, req.Paths); err != nil {
+		h.logger.Error("刷新防篡改快照失败", zap.Error(err), zap.String("agentId", agentID))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
+// Unlock 临时解除目录保护（支持管理员 API Key 调用，便于发布流水线使用）
+// POST /api/admin/agents/:id/tamper/unlock
+func (h *TamperHandler) Unlock(c echo.Context) error {
+	agentID := c.Param("id")
+
+	var req struct {
+		Path    string `json:"path"`
+		Minutes int    `json:"minutes"`
+		Reason  string `json:"reason"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Path == "" {
+		return orz.NewError(400, "请指定目录")
+	}
+
+	operator, _ := c.Get("username").(string)
+	window, err := h.tamperService.Unlock(c.Request().Context(), agentID, req.Path, req.Minutes, req.Reason, operator)
+	if err != nil {
+		h.logger.Error("临时解除防篡改保护失败", zap.Error(err), zap.String("agentId", agentID), zap.String("path", req.Path))
+		return err
+	}
+
+	return orz.Ok(c, window)
+}
+
+// Relock 提前结束临时解锁，立即重新保护
+// DELETE /api/admin/agents/:id/tamper/unlock?path=
+func (h *TamperHandler) Relock(c echo.Context) error {
+	agentID := c.Param("id")
+	path := c.QueryParam("path")
+	if path == "" {
+		return orz.NewError(400, "请指定目录")
+	}
+
+	if err := h.tamperService.Relock(c.Request().Context(), agentID, path); err != nil {
+		h.logger.Error("重新保护目录失败", zap.Error(err), zap.String("agentId", agentID), zap.String("path", path))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
 // ListEvents 获取探针的防篡改事件
 // GET /api/agents/:id/tamper/events
 func (h *TamperHandler) ListEvents(c echo.Context) error {
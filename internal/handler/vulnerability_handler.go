@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type VulnerabilityHandler struct {
+	logger               *zap.Logger
+	vulnerabilityService *service.VulnerabilityService
+}
+
+func NewVulnerabilityHandler(logger *zap.Logger, vulnerabilityService *service.VulnerabilityService) *VulnerabilityHandler {
+	return &VulnerabilityHandler{
+		logger:               logger,
+		vulnerabilityService: vulnerabilityService,
+	}
+}
+
+// Import 导入离线漏洞库（multipart 文件上传，字段名 file）
+// 查询参数 format: osv（默认，支持 JSON 数组/单个对象/zip 包）或 debian（Debian Security Tracker JSON）
+func (h *VulnerabilityHandler) Import(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = service.VulnSourceOSV
+	}
+	if format != service.VulnSourceOSV && format != service.VulnSourceDebian {
+		return orz.NewError(400, "format 仅支持 osv 或 debian")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return orz.NewError(400, "请上传漏洞库文件")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx := c.Request().Context()
+	result, err := h.vulnerabilityService.Import(ctx, format, file, fileHeader.Size)
+	if err != nil {
+		h.logger.Error("导入漏洞库失败", zap.String("format", format), zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, result)
+}
+
+// Status 获取漏洞库状态
+func (h *VulnerabilityHandler) Status(c echo.Context) error {
+	ctx := c.Request().Context()
+	status, err := h.vulnerabilityService.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, status)
+}
+
+// Clear 清空漏洞库，可通过查询参数 source 仅清空指定来源
+func (h *VulnerabilityHandler) Clear(c echo.Context) error {
+	ctx := c.Request().Context()
+	deleted, err := h.vulnerabilityService.Clear(ctx, c.QueryParam("source"))
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"deleted": deleted,
+	})
+}
+
+// GetAgentVulnerabilities 获取探针已安装软件包的漏洞匹配结果
+func (h *VulnerabilityHandler) GetAgentVulnerabilities(c echo.Context) error {
+	agentID := c.Param("id")
+	ctx := c.Request().Context()
+
+	report, err := h.vulnerabilityService.MatchAgent(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, report)
+}
@@ -45,6 +45,7 @@ type LatestMetrics struct {
 	CPU               *protocol.CPUData               `json:"cpu,omitempty"`
 	Memory            *protocol.MemoryData            `json:"memory,omitempty"`
 	Disk              *DiskSummary                    `json:"disk,omitempty"`
+	Disks             []protocol.DiskData             `json:"-"` // 各挂载点数据，仅用于按挂载点告警
 	DiskIO            *DiskIOSummary                  `json:"diskIO,omitempty"`
 	Network           *NetworkSummary                 `json:"network,omitempty"`
 	NetworkInterfaces []protocol.NetworkData          `json:"networkInterfaces,omitempty"`
@@ -73,6 +74,7 @@ func (lm *LatestMetrics) Snapshot() *LatestMetrics {
 		CPU:               lm.CPU,
 		Memory:            lm.Memory,
 		Disk:              lm.Disk,
+		Disks:             lm.Disks,
 		DiskIO:            lm.DiskIO,
 		Network:           lm.Network,
 		NetworkInterfaces: lm.NetworkInterfaces,
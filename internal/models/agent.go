@@ -54,20 +54,68 @@ type TrafficStatsData struct {
 	AlertSent100 bool   `json:"alertSent100"` // 是否已发送100%告警
 }
 
+// 防篡改保护模式
+const (
+	TamperModeImmutable = "immutable" // 不可变属性（chattr +i）
+	TamperModeHash      = "hash"      // SHA-256 哈希基线
+	TamperModeHybrid    = "hybrid"    // 不可变属性 + 哈希基线
+)
+
+// TamperUnlockWindow 防篡改临时解锁窗口（用于发布），窗口内不发送通知，到期后探针自动重新保护并更新基线
+type TamperUnlockWindow struct {
+	Path      string `json:"path"`      // 受保护的目录
+	Until     int64  `json:"until"`     // 到期时间（时间戳毫秒）
+	Reason    string `json:"reason"`    // 解锁原因
+	CreatedBy string `json:"createdBy"` // 操作人
+	CreatedAt int64  `json:"createdAt"` // 解锁时间（时间戳毫秒）
+}
+
 // TamperProtectConfigData 防篡改保护配置数据
 type TamperProtectConfigData struct {
-	Enabled      bool     `json:"enabled"`                // 是否启用
-	Paths        []string `json:"paths"`                  // 受保护的目录列表
-	ApplyStatus  string   `json:"applyStatus,omitempty"`  // 配置应用状态: success/failed/pending
-	ApplyMessage string   `json:"applyMessage,omitempty"` // 应用结果消息
+	Enabled      bool                 `json:"enabled"`                // 是否启用
+	Paths        []string             `json:"paths"`                  // 受保护的目录列表
+	Mode         string               `json:"mode,omitempty"`         // 保护模式: immutable(不可变属性，默认)/hash(SHA-256 哈希基线)/hybrid(两者同时)
+	HashInterval int                  `json:"hashInterval,omitempty"` // 哈希基线定期校验间隔(秒)，默认 300
+	EnforcePaths []string             `json:"enforcePaths,omitempty"` // 启用强制恢复策略的目录（Paths 的子集），按快照自动回滚变化
+	Unlocks      []TamperUnlockWindow `json:"unlocks,omitempty"`      // 临时解锁窗口
+	ApplyStatus  string               `json:"applyStatus,omitempty"`  // 配置应用状态: success/failed/pending
+	ApplyMessage string               `json:"applyMessage,omitempty"` // 应用结果消息
 }
 
 // SSHLoginConfigData SSH登录监控配置数据
 type SSHLoginConfigData struct {
-	Enabled      bool     `json:"enabled"`                // 是否启用
-	IPWhitelist  []string `json:"ipWhitelist,omitempty"`  // IP白名单，白名单中的IP只记录不发送通知，支持 IP 或 CIDR
-	ApplyStatus  string   `json:"applyStatus,omitempty"`  // 配置应用状态: success/failed/pending
-	ApplyMessage string   `json:"applyMessage,omitempty"` // 应用结果消息
+	Enabled      bool          `json:"enabled"`                // 是否启用
+	IPWhitelist  []string      `json:"ipWhitelist,omitempty"`  // IP白名单，白名单中的IP只记录不发送通知，也不会被封禁，支持 IP 或 CIDR
+	BanPolicy    *SSHBanPolicy `json:"banPolicy,omitempty"`    // 暴力破解封禁策略
+	ApplyStatus  string        `json:"applyStatus,omitempty"`  // 配置应用状态: success/failed/pending
+	ApplyMessage string        `json:"applyMessage,omitempty"` // 应用结果消息
+}
+
+// SSHBanPolicy SSH暴力破解封禁策略：时间窗口内同一 IP（或网段）登录失败达到阈值后由探针封禁
+type SSHBanPolicy struct {
+	Enabled     bool `json:"enabled"`     // 是否启用自动封禁
+	MaxFailures int  `json:"maxFailures"` // 时间窗口内允许的登录失败次数，达到后封禁
+	Window      int  `json:"window"`      // 统计时间窗口（分钟）
+	BanDuration int  `json:"banDuration"` // 封禁时长（分钟）
+	IPv4Prefix  int  `json:"ipv4Prefix"`  // IPv4 聚合前缀长度，32 表示按单个 IP 统计和封禁
+	IPv6Prefix  int  `json:"ipv6Prefix"`  // IPv6 聚合前缀长度，128 表示按单个 IP 统计和封禁
+}
+
+// IsNetWhitelisted 判断网段是否与白名单有交集，避免按网段封禁时误封白名单地址
+func (r SSHLoginConfigData) IsNetWhitelisted(ipNet *net.IPNet) bool {
+	for _, whitelistIP := range r.IPWhitelist {
+		if strings.Contains(whitelistIP, "/") {
+			_, whitelistNet, err := net.ParseCIDR(whitelistIP)
+			if err == nil && (whitelistNet.Contains(ipNet.IP) || ipNet.Contains(whitelistNet.IP)) {
+				return true
+			}
+			continue
+		}
+		if ip := net.ParseIP(whitelistIP); ip != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 func (r SSHLoginConfigData) IsIPWhitelisted(ip string) bool {
@@ -1,24 +1,50 @@
 package models
 
+import "gorm.io/datatypes"
+
 // AlertRecord 告警记录
 type AlertRecord struct {
-	ID                 int64   `gorm:"primaryKey;autoIncrement" json:"id"`           // 记录ID
-	AgentID            string  `gorm:"index" json:"agentId"`                         // 探针ID
-	AgentName          string  `json:"agentName"`                                    // 探针名称
-	AlertType          string  `json:"alertType"`                                    // 告警类型: cpu, memory, disk, network
-	Message            string  `json:"message"`                                      // 告警消息
-	Threshold          float64 `json:"threshold"`                                    // 告警阈值
-	ActualValue        float64 `json:"actualValue"`                                  // 告警触发时的实际值
-	ResolvedValue      float64 `json:"resolvedValue,omitempty"`                      // 恢复时的实际值
-	Level              string  `json:"level"`                                        // 告警级别: info, warning, critical
-	Status             string  `json:"status"`                                       // 状态: firing（告警中）, resolved（已恢复）
-	FiredAt            int64   `gorm:"index" json:"firedAt"`                         // 触发时间（时间戳毫秒）
-	ResolvedAt         int64   `json:"resolvedAt,omitempty"`                         // 恢复时间（时间戳毫秒）
-	NotificationStatus string  `json:"notificationStatus"`                           // 通知状态: pending, sent, failed
-	NotificationSentAt int64   `json:"notificationSentAt,omitempty"`                 // 通知发送时间（时间戳毫秒）
-	NotificationError  string  `gorm:"type:text" json:"notificationError,omitempty"` // 通知发送失败原因
-	CreatedAt          int64   `json:"createdAt"`                                    // 创建时间（时间戳毫秒）
-	UpdatedAt          int64   `json:"updatedAt" gorm:"autoUpdateTime:milli"`        // 更新时间（时间戳毫秒）
+	ID                  int64                                 `gorm:"primaryKey;autoIncrement" json:"id"`           // 记录ID
+	AgentID             string                                `gorm:"index" json:"agentId"`                         // 探针ID
+	AgentName           string                                `json:"agentName"`                                    // 探针名称
+	AlertType           string                                `json:"alertType"`                                    // 告警类型: cpu, memory, disk, network, disk_forecast, traffic_forecast
+	Message             string                                `json:"message"`                                      // 告警消息
+	Threshold           float64                               `json:"threshold"`                                    // 告警阈值
+	ActualValue         float64                               `json:"actualValue"`                                  // 告警触发时的实际值
+	ResolvedValue       float64                               `json:"resolvedValue,omitempty"`                      // 恢复时的实际值
+	Level               string                                `json:"level"`                                        // 告警级别: info, warning, critical
+	Status              string                                `json:"status"`                                       // 状态: firing（告警中）, resolved（已恢复）
+	FiredAt             int64                                 `gorm:"index" json:"firedAt"`                         // 触发时间（时间戳毫秒）
+	ResolvedAt          int64                                 `json:"resolvedAt,omitempty"`                         // 恢复时间（时间戳毫秒）
+	NotificationStatus  string                                `json:"notificationStatus"`                           // 通知状态: pending, sent, failed, silenced, inhibited
+	NotificationSentAt  int64                                 `json:"notificationSentAt,omitempty"`                 // 通知发送时间（时间戳毫秒）
+	NotificationError   string                                `gorm:"type:text" json:"notificationError,omitempty"` // 通知发送失败原因
+	RuleSource          string                                `json:"ruleSource,omitempty"`                         // 产生告警的规则来源: global, tag, agent, promql
+	RuleSetID           string                                `gorm:"index" json:"ruleSetId,omitempty"`             // 产生告警的规则集或 PromQL 规则ID（全局规则为空）
+	RuleSetName         string                                `json:"ruleSetName,omitempty"`                        // 产生告警的规则集或 PromQL 规则名称
+	Labels              datatypes.JSONType[map[string]string] `json:"labels"`                                       // 告警序列的标签（PromQL 规则）
+	MonitorID           string                                `json:"monitorId,omitempty"`                          // 监控项ID（证书、服务下线告警）
+	Device              string                                `json:"device,omitempty"`                             // 磁盘挂载点或网卡名称（按设备告警）
+	StateID             string                                `gorm:"index" json:"stateId,omitempty"`               // 产生告警的告警状态ID，同一告警的触发和恢复共用，作为外部事件的去重标识
+	Silenced            bool                                  `gorm:"default:false" json:"silenced"`                // 是否被静默（不发送通知）
+	SilenceID           string                                `json:"silenceId,omitempty"`                          // 匹配的静默ID
+	Inhibited           bool                                  `gorm:"default:false" json:"inhibited"`               // 是否被抑制（由其他告警引起，不发送通知）
+	InhibitedBy         int64                                 `json:"inhibitedBy,omitempty"`                        // 引起抑制的告警记录ID（探针当前离线时为 0）
+	InhibitReason       string                                `json:"inhibitReason,omitempty"`                      // 抑制原因（抑制规则名称或父监控项）
+	AcknowledgedAt      int64                                 `gorm:"default:0" json:"acknowledgedAt,omitempty"`    // 确认时间（时间戳毫秒）
+	AcknowledgedBy      string                                `json:"acknowledgedBy,omitempty"`                     // 确认人
+	AckComment          string                                `gorm:"type:text" json:"ackComment,omitempty"`        // 确认备注
+	Assignee            string                                `gorm:"index" json:"assignee,omitempty"`              // 负责人
+	AssignedAt          int64                                 `json:"assignedAt,omitempty"`                         // 指派时间（时间戳毫秒）
+	RepeatCount         int                                   `json:"repeatCount"`                                  // 未确认时已重复通知的次数
+	LastRepeatAt        int64                                 `json:"lastRepeatAt,omitempty"`                       // 最后一次重复通知时间（时间戳毫秒）
+	EscalatedAt         int64                                 `json:"escalatedAt,omitempty"`                        // 升级通知时间（时间戳毫秒）
+	Flapping            bool                                  `gorm:"default:false" json:"flapping"`                // 是否为抖动告警（频繁触发/恢复合并为一条记录）
+	FlapCount           int                                   `json:"flapCount,omitempty"`                          // 抖动期间的状态变化次数
+	FlapEndedAt         int64                                 `json:"flapEndedAt,omitempty"`                        // 抖动结束时间（时间戳毫秒）
+	NotificationThreads datatypes.JSONType[map[string]string] `json:"-"`                                            // 各通知渠道触发消息的会话标识，恢复消息回复到同一会话
+	CreatedAt           int64                                 `json:"createdAt"`                                    // 创建时间（时间戳毫秒）
+	UpdatedAt           int64                                 `json:"updatedAt" gorm:"autoUpdateTime:milli"`        // 更新时间（时间戳毫秒）
 }
 
 func (AlertRecord) TableName() string {
@@ -27,20 +53,69 @@ func (AlertRecord) TableName() string {
 
 // AlertState 告警状态（持久化到数据库，用于判断是否持续超过阈值）
 type AlertState struct {
-	ID            string  `gorm:"primaryKey" json:"id"`                  // 状态ID（格式：agentId:configId:alertType）
-	AgentID       string  `gorm:"index" json:"agentId"`                  // 探针ID
-	AlertType     string  `gorm:"index" json:"alertType"`                // 告警类型
-	Value         float64 `json:"value"`                                 // 当前值
-	Threshold     float64 `json:"threshold"`                             // 阈值
-	StartTime     int64   `json:"startTime"`                             // 开始超过阈值的时间
-	Duration      int     `json:"duration"`                              // 需要持续的时间（秒）
-	LastCheckTime int64   `json:"lastCheckTime"`                         // 上次检查时间
-	IsFiring      bool    `json:"isFiring"`                              // 是否正在告警
-	LastRecordID  int64   `json:"lastRecordId"`                          // 最后一条告警记录ID
-	CreatedAt     int64   `json:"createdAt"`                             // 创建时间（时间戳毫秒）
-	UpdatedAt     int64   `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+	ID            string                     `gorm:"primaryKey" json:"id"`                  // 状态ID（格式：agentId:configId:alertType）
+	AgentID       string                     `gorm:"index" json:"agentId"`                  // 探针ID
+	AlertType     string                     `gorm:"index" json:"alertType"`                // 告警类型
+	Device        string                     `json:"device,omitempty"`                      // 磁盘挂载点或网卡名称（按设备告警时）
+	Value         float64                    `json:"value"`                                 // 当前值
+	Threshold     float64                    `json:"threshold"`                             // 阈值
+	StartTime     int64                      `json:"startTime"`                             // 开始超过阈值的时间
+	Duration      int                        `json:"duration"`                              // 需要持续的时间（秒）
+	LastCheckTime int64                      `json:"lastCheckTime"`                         // 上次检查时间
+	IsFiring      bool                       `json:"isFiring"`                              // 是否正在告警
+	LastRecordID  int64                      `json:"lastRecordId"`                          // 最后一条告警记录ID
+	RecoveryStart int64                      `json:"recoveryStart"`                         // 告警中低于恢复阈值的开始时间
+	Transitions   datatypes.JSONSlice[int64] `json:"transitions"`                           // 抖动检测窗口内的状态变化时间（时间戳毫秒）
+	FlapRecordID  int64                      `json:"flapRecordId"`                          // 抖动告警记录ID，不为 0 时处于抖动中
+	CreatedAt     int64                      `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt     int64                      `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
 }
 
 func (AlertState) TableName() string {
 	return "alert_states"
 }
+
+// 告警规则来源
+const (
+	AlertRuleSourceGlobal = "global" // 全局告警配置
+	AlertRuleSourceTag    = "tag"    // 按标签匹配的规则集
+	AlertRuleSourceAgent  = "agent"  // 按探针匹配的规则集
+	AlertRuleSourcePromQL = "promql" // 自定义 PromQL 规则
+)
+
+// AlertRuleSet 告警规则集，按探针或标签覆盖全局告警规则（优先级：探针 > 标签 > 全局）
+type AlertRuleSet struct {
+	ID          string                         `gorm:"primaryKey" json:"id"`                  // 规则集ID (UUID)
+	Name        string                         `json:"name"`                                  // 规则集名称
+	Description string                         `json:"description"`                           // 描述
+	Enabled     bool                           `json:"enabled"`                               // 是否启用
+	AgentIDs    datatypes.JSONSlice[string]    `json:"agentIds"`                              // 指定探针（按探针匹配时优先于标签）
+	Tags        datatypes.JSONSlice[string]    `json:"tags"`                                  // 指定标签
+	Priority    int                            `json:"priority"`                              // 优先级，同一层级匹配多个规则集时数值大的生效
+	Rules       datatypes.JSONType[AlertRules] `json:"rules"`                                 // 告警规则（完整替换全局规则）
+	CreatedAt   int64                          `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt   int64                          `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (AlertRuleSet) TableName() string {
+	return "alert_rule_sets"
+}
+
+// AlertPromQLRule 自定义 PromQL 告警规则，表达式返回的每个序列独立触发和恢复告警
+type AlertPromQLRule struct {
+	ID          string                                `gorm:"primaryKey" json:"id"`                  // 规则ID (UUID)
+	Name        string                                `json:"name"`                                  // 规则名称
+	Description string                                `json:"description"`                           // 描述
+	Enabled     bool                                  `json:"enabled"`                               // 是否启用
+	Expr        string                                `gorm:"type:text" json:"expr"`                 // PromQL 表达式，返回的序列即为告警中的序列
+	For         int                                   `json:"for"`                                   // 序列持续存在多久后触发（秒）
+	Severity    string                                `json:"severity"`                              // 告警级别: info, warning, critical
+	Labels      datatypes.JSONType[map[string]string] `json:"labels"`                                // 附加到告警的标签
+	Summary     string                                `gorm:"type:text" json:"summary"`              // 告警消息模板，支持 {{value}}、{{labels.xxx}}
+	CreatedAt   int64                                 `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt   int64                                 `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (AlertPromQLRule) TableName() string {
+	return "alert_promql_rules"
+}
@@ -0,0 +1,34 @@
+package models
+
+import "gorm.io/datatypes"
+
+// 静默时间类型
+const (
+	AlertSilenceModeOnce   = "once"   // 指定开始和结束时间
+	AlertSilenceModeWeekly = "weekly" // 每周固定时段
+)
+
+// AlertSilence 告警静默（维护窗口），匹配的告警仍会记录但不发送通知
+type AlertSilence struct {
+	ID         string                      `gorm:"primaryKey" json:"id"`                  // 静默ID (UUID)
+	Name       string                      `json:"name"`                                  // 名称
+	Comment    string                      `gorm:"type:text" json:"comment"`              // 备注（如维护原因）
+	Enabled    bool                        `json:"enabled"`                               // 是否启用
+	AgentIDs   datatypes.JSONSlice[string] `json:"agentIds"`                              // 匹配的探针，为空表示不限
+	Tags       datatypes.JSONSlice[string] `json:"tags"`                                  // 匹配的探针标签（任一），为空表示不限
+	AlertTypes datatypes.JSONSlice[string] `json:"alertTypes"`                            // 匹配的告警类型，为空表示不限
+	MonitorIDs datatypes.JSONSlice[string] `json:"monitorIds"`                            // 匹配的监控项，为空表示不限
+	Mode       string                      `json:"mode"`                                  // 时间类型: once, weekly
+	StartsAt   int64                       `json:"startsAt"`                              // 开始时间（once，时间戳毫秒）
+	EndsAt     int64                       `json:"endsAt"`                                // 结束时间（once，时间戳毫秒）
+	Weekdays   datatypes.JSONSlice[int]    `json:"weekdays"`                              // 每周生效的日期（weekly，0 表示周日）
+	StartTime  string                      `json:"startTime"`                             // 每日开始时间 HH:mm（weekly）
+	EndTime    string                      `json:"endTime"`                               // 每日结束时间 HH:mm（weekly，早于开始时间表示跨天）
+	Active     bool                        `gorm:"-" json:"active"`                       // 当前是否生效
+	CreatedAt  int64                       `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt  int64                       `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (AlertSilence) TableName() string {
+	return "alert_silences"
+}
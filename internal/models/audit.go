@@ -1,5 +1,7 @@
 package models
 
+import "gorm.io/datatypes"
+
 // AuditResult 审计结果模型
 type AuditResult struct {
 	ID        int64  `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -9,9 +11,32 @@ type AuditResult struct {
 	StartTime int64  `gorm:"not null" json:"startTime"`
 	EndTime   int64  `gorm:"not null" json:"endTime"`
 	CreatedAt int64  `gorm:"not null" json:"createdAt"`
+
+	RiskScore   int    `gorm:"default:0" json:"riskScore"`                // 风险评分 (0-100)
+	ThreatLevel string `gorm:"type:varchar(16);index" json:"threatLevel"` // 威胁等级: low/medium/high/critical
+	Analysis    string `gorm:"type:text" json:"analysis"`                 // JSON格式的安全分析结果
 }
 
 // TableName 表名
 func (AuditResult) TableName() string {
 	return "audit_results"
 }
+
+// AuditSchedule 定时审计计划
+type AuditSchedule struct {
+	ID             string                      `gorm:"primaryKey" json:"id"`                  // 计划ID (UUID)
+	Name           string                      `json:"name"`                                  // 计划名称
+	Enabled        bool                        `json:"enabled"`                               // 是否启用
+	Cron           string                      `gorm:"type:varchar(64)" json:"cron"`          // Cron 表达式（标准 5 段，支持 @daily 等描述符）
+	AgentIDs       datatypes.JSONSlice[string] `json:"agentIds"`                              // 指定探针
+	Tags           datatypes.JSONSlice[string] `json:"tags"`                                  // 指定标签（与探针取并集，都为空时对所有探针生效）
+	MaxConcurrency int                         `json:"maxConcurrency"`                        // 同时执行审计的探针数量上限
+	LastRunAt      int64                       `json:"lastRunAt"`                             // 上次执行时间
+	LastRunMessage string                      `json:"lastRunMessage"`                        // 上次执行结果
+	CreatedAt      int64                       `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt      int64                       `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (AuditSchedule) TableName() string {
+	return "audit_schedules"
+}
@@ -0,0 +1,19 @@
+package models
+
+// AuditRulePack 审计规则包（YAML 格式）
+type AuditRulePack struct {
+	ID          string `gorm:"primaryKey" json:"id"`                // 规则包ID (UUID，磁盘加载的规则包为 file:文件名)
+	Name        string `gorm:"type:varchar(128);index" json:"name"` // 规则包名称
+	Description string `json:"description"`                         // 描述
+	Version     string `gorm:"type:varchar(32)" json:"version"`     // 版本
+	Source      string `gorm:"type:varchar(16)" json:"source"`      // 来源: upload/file
+	Enabled     bool   `json:"enabled"`                             // 是否启用
+	RuleCount   int    `json:"ruleCount"`                           // 规则数量
+	Content     string `gorm:"type:text" json:"content"`            // YAML 原文
+	CreatedAt   int64  `json:"createdAt"`                           // 创建时间（时间戳毫秒）
+	UpdatedAt   int64  `json:"updatedAt"`                           // 更新时间（时间戳毫秒）
+}
+
+func (AuditRulePack) TableName() string {
+	return "audit_rule_packs"
+}
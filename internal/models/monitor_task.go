@@ -18,6 +18,7 @@ type MonitorTask struct {
 	Interval         int                                            `json:"interval"`                              // 检测频率（秒），默认 60
 	AgentIds         datatypes.JSONSlice[string]                    `json:"agentIds"`                              // 指定的探针 ID 列表（JSON 数组）
 	AgentNames       []string                                       `gorm:"-" json:"agentNames"`                   // 指定的探针名称列表
+	ParentID         string                                         `gorm:"index" json:"parentId"`                 // 父监控项 ID，父监控项下线时抑制本监控项的告警
 	HTTPConfig       datatypes.JSONType[protocol.HTTPMonitorConfig] `json:"httpConfig"`                            // HTTP 监控配置
 	TCPConfig        datatypes.JSONType[protocol.TCPMonitorConfig]  `json:"tcpConfig"`                             // TCP 监控配置
 	ICMPConfig       datatypes.JSONType[protocol.ICMPMonitorConfig] `json:"icmpConfig"`                            // ICMP 监控配置
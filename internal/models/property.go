@@ -13,11 +13,22 @@ func (Property) TableName() string {
 	return "properties"
 }
 
-// NotificationChannelConfig 通知渠道配置（存储在 Property 中）
+// NotificationChannelConfig 通知渠道配置（存储在 Property 中），同一类型可以配置多个渠道
 type NotificationChannelConfig struct {
-	Type    string                 `json:"type"`    // 类型: dingtalk, wecom, feishu, webhook
-	Enabled bool                   `json:"enabled"` // 是否启用
-	Config  map[string]interface{} `json:"config"`  // 配置对象
+	ID      string                 `json:"id"`               // 渠道ID，旧版本配置没有ID时使用渠道类型
+	Name    string                 `json:"name"`             // 渠道名称
+	Type    string                 `json:"type"`             // 类型: dingtalk, wecom, feishu, webhook
+	Enabled bool                   `json:"enabled"`          // 是否启用
+	Routes  []NotificationRoute    `json:"routes,omitempty"` // 路由规则，为空时接收所有通知，否则匹配任一规则时才发送
+	Config  map[string]interface{} `json:"config"`           // 配置对象
+}
+
+// NotificationRoute 通知路由规则，各条件同时满足时匹配，条件为空表示不限
+type NotificationRoute struct {
+	AlertTypes []string `json:"alertTypes,omitempty"` // 告警类型
+	Levels     []string `json:"levels,omitempty"`     // 告警级别: info, warning, critical
+	AgentTags  []string `json:"agentTags,omitempty"`  // 探针标签（任一）
+	Events     []string `json:"events,omitempty"`     // 事件类型: firing, resolved, notice, tamper, ssh_login, traffic
 }
 
 // 配置格式说明：
@@ -30,6 +41,8 @@ type NotificationChannelConfig struct {
 //   "headers": {"key": "value"},  // 可选：自定义请求头
 //   "customBody": ""  // 自定义请求体模板，支持变量替换
 // }
+// 所有渠道均可配置 templates: { "firing": "...", "resolved": "...", "notice": "...", "tamper": "...", "ssh_login": "...", "traffic": "..." }
+// 按事件类型使用 text/template 模板渲染通知消息，未配置的事件使用默认消息
 
 // DNSProviderConfig DNS 服务商配置（存储在 Property 中）
 type DNSProviderConfig struct {
@@ -113,29 +126,42 @@ type AlertConfig struct {
 	MaskIP        bool               `json:"maskIP"`        // 是否在通知中打码 IP 地址
 	Rules         AlertRules         `json:"rules"`         // 告警规则
 	Notifications AlertNotifications `json:"notifications"` // 通知开关
+	Escalation    AlertEscalation    `json:"escalation"`    // 未确认严重告警的重复通知和升级
+	InhibitRules  []AlertInhibitRule `json:"inhibitRules"`  // 告警抑制规则
+	Flapping      AlertFlapping      `json:"flapping"`      // 指标告警抖动检测
 }
 
 // AlertRules 告警规则
 type AlertRules struct {
 	// CPU 告警配置
-	CPUEnabled   bool    `json:"cpuEnabled"`   // 是否启用CPU告警
-	CPUThreshold float64 `json:"cpuThreshold"` // CPU使用率阈值(0-100)
-	CPUDuration  int     `json:"cpuDuration"`  // 持续时间（秒）
+	CPUEnabled           bool    `json:"cpuEnabled"`           // 是否启用CPU告警
+	CPUThreshold         float64 `json:"cpuThreshold"`         // CPU使用率阈值(0-100)
+	CPUDuration          int     `json:"cpuDuration"`          // 持续时间（秒）
+	CPURecoveryThreshold float64 `json:"cpuRecoveryThreshold"` // 恢复阈值，低于该值才开始恢复（0 表示与告警阈值相同）
+	CPURecoveryDuration  int     `json:"cpuRecoveryDuration"`  // 恢复持续时间（秒）
 
 	// 内存告警配置
-	MemoryEnabled   bool    `json:"memoryEnabled"`   // 是否启用内存告警
-	MemoryThreshold float64 `json:"memoryThreshold"` // 内存使用率阈值(0-100)
-	MemoryDuration  int     `json:"memoryDuration"`  // 持续时间（秒）
+	MemoryEnabled           bool    `json:"memoryEnabled"`           // 是否启用内存告警
+	MemoryThreshold         float64 `json:"memoryThreshold"`         // 内存使用率阈值(0-100)
+	MemoryDuration          int     `json:"memoryDuration"`          // 持续时间（秒）
+	MemoryRecoveryThreshold float64 `json:"memoryRecoveryThreshold"` // 恢复阈值，低于该值才开始恢复（0 表示与告警阈值相同）
+	MemoryRecoveryDuration  int     `json:"memoryRecoveryDuration"`  // 恢复持续时间（秒）
 
 	// 磁盘告警配置
-	DiskEnabled   bool    `json:"diskEnabled"`   // 是否启用磁盘告警
-	DiskThreshold float64 `json:"diskThreshold"` // 磁盘使用率阈值(0-100)
-	DiskDuration  int     `json:"diskDuration"`  // 持续时间（秒）
+	DiskEnabled           bool     `json:"diskEnabled"`           // 是否启用磁盘告警
+	DiskThreshold         float64  `json:"diskThreshold"`         // 磁盘使用率阈值(0-100)
+	DiskDuration          int      `json:"diskDuration"`          // 持续时间（秒）
+	DiskRecoveryThreshold float64  `json:"diskRecoveryThreshold"` // 恢复阈值，低于该值才开始恢复（0 表示与告警阈值相同）
+	DiskRecoveryDuration  int      `json:"diskRecoveryDuration"`  // 恢复持续时间（秒）
+	DiskMountPoints       []string `json:"diskMountPoints"`       // 按挂载点告警，支持精确名称或以 ~ 开头的正则，为空时按所有磁盘汇总告警
 
 	// 网络告警配置
-	NetworkEnabled   bool    `json:"networkEnabled"`   // 是否启用网络告警
-	NetworkThreshold float64 `json:"networkThreshold"` // 网速阈值(MB/s)
-	NetworkDuration  int     `json:"networkDuration"`  // 持续时间（秒）
+	NetworkEnabled           bool     `json:"networkEnabled"`           // 是否启用网络告警
+	NetworkThreshold         float64  `json:"networkThreshold"`         // 网速阈值(MB/s)
+	NetworkDuration          int      `json:"networkDuration"`          // 持续时间（秒）
+	NetworkRecoveryThreshold float64  `json:"networkRecoveryThreshold"` // 恢复阈值，低于该值才开始恢复（0 表示与告警阈值相同）
+	NetworkRecoveryDuration  int      `json:"networkRecoveryDuration"`  // 恢复持续时间（秒）
+	NetworkInterfaces        []string `json:"networkInterfaces"`        // 按网卡告警，支持精确名称或以 ~ 开头的正则，为空时按所有网卡汇总告警
 
 	// HTTPS 证书告警配置
 	CertEnabled   bool    `json:"certEnabled"`   // 是否启用证书告警
@@ -148,13 +174,54 @@ type AlertRules struct {
 	// 探针离线告警配置
 	AgentOfflineEnabled  bool `json:"agentOfflineEnabled"`  // 是否启用探针离线告警
 	AgentOfflineDuration int  `json:"agentOfflineDuration"` // 持续时间（秒）
+
+	// 磁盘写满预测告警配置（按挂载点对剩余空间做线性回归）
+	DiskForecastEnabled  bool `json:"diskForecastEnabled"`  // 是否启用磁盘写满预测告警
+	DiskForecastHours    int  `json:"diskForecastHours"`    // 预计多少小时内写满时告警
+	DiskForecastLookback int  `json:"diskForecastLookback"` // 用于预测的历史数据时长（小时）
+
+	// 流量超额预测告警配置
+	TrafficForecastEnabled bool `json:"trafficForecastEnabled"` // 按当前计费周期的使用趋势预测重置前超出限额时告警
 }
 
 // AlertNotifications 告警通知开关
 type AlertNotifications struct {
 	TrafficEnabled         bool `json:"trafficEnabled"`         // 流量告警通知
 	SSHLoginSuccessEnabled bool `json:"sshLoginSuccessEnabled"` // SSH 登录成功通知
+	SSHBanEnabled          bool `json:"sshBanEnabled"`          // SSH 暴力破解封禁通知
 	TamperEventEnabled     bool `json:"tamperEventEnabled"`     // 防篡改事件通知
+	AuditDriftEnabled      bool `json:"auditDriftEnabled"`      // 定时审计资产变化通知
+}
+
+// AlertEscalation 未确认严重告警的重复通知和升级配置
+type AlertEscalation struct {
+	RepeatEnabled      bool     `json:"repeatEnabled"`      // 是否重复通知未确认的严重告警
+	RepeatInterval     int      `json:"repeatInterval"`     // 重复通知间隔（分钟）
+	RepeatMaxTimes     int      `json:"repeatMaxTimes"`     // 最多重复通知次数（0 表示不限制）
+	EscalationEnabled  bool     `json:"escalationEnabled"`  // 是否在超时未确认后升级通知
+	EscalationTimeout  int      `json:"escalationTimeout"`  // 告警触发后多久未确认则升级（分钟）
+	EscalationChannels []string `json:"escalationChannels"` // 升级通知渠道ID（无需启用，仅用于升级）
+}
+
+// AlertFlapping 指标告警抖动检测：窗口内状态变化次数达到阈值时合并为一条抖动告警，窗口内不再变化后结束
+type AlertFlapping struct {
+	Enabled   bool `json:"enabled"`   // 是否启用抖动检测
+	Window    int  `json:"window"`    // 检测窗口（分钟）
+	Threshold int  `json:"threshold"` // 窗口内触发/恢复的次数达到该值时判定为抖动
+}
+
+// AlertInhibitRule 告警抑制规则：同一探针存在告警中的源告警时，不再通知目标类型的告警
+type AlertInhibitRule struct {
+	Name        string   `json:"name"`        // 规则名称
+	Enabled     bool     `json:"enabled"`     // 是否启用
+	SourceTypes []string `json:"sourceTypes"` // 源告警类型，如 agent_offline
+	TargetTypes []string `json:"targetTypes"` // 被抑制的告警类型，如 service, cert, cpu
+}
+
+// AuditConfig 安全审计配置
+type AuditConfig struct {
+	RetentionDays      int `json:"retentionDays"`      // 审计结果保留天数（0 表示不按时间清理）
+	MaxResultsPerAgent int `json:"maxResultsPerAgent"` // 每个探针最多保留的审计结果数量（0 表示不限制）
 }
 
 // AgentInstallConfig 探针安装配置
@@ -1,20 +1,121 @@
 package models
 
+import "gorm.io/datatypes"
+
+// SSH登录异常类型
+const (
+	SSHLoginAnomalyNewCountry       = "new_country"       // 从未出现过的国家
+	SSHLoginAnomalyNewASN           = "new_asn"           // 从未出现过的运营商（ASN）
+	SSHLoginAnomalyImpossibleTravel = "impossible_travel" // 不可能的位移：与上次登录的距离和时间间隔不符
+	SSHLoginAnomalyUnusualHour      = "unusual_hour"      // 从未登录过的时段
+	SSHLoginAnomalyNewNetwork       = "new_network"       // 从未出现过的来源网段
+)
+
+// SSH登录异常严重程度
+const (
+	SSHLoginSeverityHigh = "high" // 新国家、新 ASN 或不可能的位移，无视登录成功通知开关发送告警
+	SSHLoginSeverityLow  = "low"  // 仅时段或网段异常，只做记录
+)
+
 // SSHLoginEvent SSH登录事件
 type SSHLoginEvent struct {
-	ID         string `gorm:"primaryKey" json:"id"`              // 事件ID (UUID)
-	AgentID    string `gorm:"index;not null" json:"agentId"`     // 探针ID
-	Username   string `gorm:"index" json:"username"`             // 用户名
-	IP         string `gorm:"index" json:"ip"`                   // 来源IP
-	IPLocation string `gorm:"index" json:"ipLocation,omitempty"` // IP归属地
-	Port       string `json:"port,omitempty"`                    // 来源端口
-	Status     string `gorm:"index" json:"status"`               // 状态: success
-	TTY        string `json:"tty,omitempty"`                     // 终端
-	SessionID  string `json:"sessionId,omitempty"`               // 会话ID
-	Timestamp  int64  `gorm:"index" json:"timestamp"`            // 登录时间（毫秒时间戳）
-	CreatedAt  int64  `json:"createdAt"`                         // 记录创建时间（毫秒）
+	ID            string                      `gorm:"primaryKey" json:"id"`                                     // 事件ID (UUID)
+	AgentID       string                      `gorm:"index;not null;index:idx_ssh_login_failed" json:"agentId"` // 探针ID
+	Username      string                      `gorm:"index" json:"username"`                                    // 用户名
+	IP            string                      `gorm:"index" json:"ip"`                                          // 来源IP
+	IPLocation    string                      `gorm:"index" json:"ipLocation,omitempty"`                        // IP归属地
+	CountryCode   string                      `json:"countryCode,omitempty"`                                    // 国家代码（ISO 3166-1）
+	ASN           uint                        `json:"asn,omitempty"`                                            // 自治系统号
+	ASOrg         string                      `json:"asOrg,omitempty"`                                          // 自治系统所属组织
+	Port          string                      `json:"port,omitempty"`                                           // 来源端口
+	Status        string                      `gorm:"index;index:idx_ssh_login_failed" json:"status"`           // 状态: success/failed
+	TTY           string                      `json:"tty,omitempty"`                                            // 终端
+	SessionID     string                      `json:"sessionId,omitempty"`                                      // 会话ID
+	Anomalies     datatypes.JSONSlice[string] `json:"anomalies,omitempty"`                                      // 异常类型
+	AnomalyDetail string                      `json:"anomalyDetail,omitempty"`                                  // 异常说明
+	Severity      string                      `gorm:"index" json:"severity,omitempty"`                          // 异常严重程度: high/low，正常登录为空
+	Timestamp     int64                       `gorm:"index" json:"timestamp"`                                   // 登录时间（毫秒时间戳）
+	CreatedAt     int64                       `gorm:"index:idx_ssh_login_failed" json:"createdAt"`              // 记录创建时间（毫秒）
 }
 
 func (SSHLoginEvent) TableName() string {
 	return "ssh_login_events"
 }
+
+// SSH封禁状态
+const (
+	SSHBanStatusPending = "pending" // 已下发，等待探针确认
+	SSHBanStatusActive  = "active"  // 生效中
+	SSHBanStatusFailed  = "failed"  // 探针执行失败
+	SSHBanStatusLifted  = "lifted"  // 已手动解除
+	SSHBanStatusExpired = "expired" // 已到期
+)
+
+// SSHBan SSH暴力破解封禁记录
+type SSHBan struct {
+	ID        string `gorm:"primaryKey" json:"id"`          // 封禁ID (UUID)
+	AgentID   string `gorm:"index;not null" json:"agentId"` // 探针ID
+	CIDR      string `gorm:"column:cidr;index" json:"cidr"` // 封禁的 IP 或网段
+	Failures  int    `json:"failures"`                      // 触发封禁时窗口内的失败次数
+	Reason    string `json:"reason"`                        // 封禁原因
+	Status    string `gorm:"index" json:"status"`           // 状态: pending/active/failed/lifted/expired
+	Message   string `json:"message,omitempty"`             // 探针执行结果
+	ExpiresAt int64  `gorm:"index" json:"expiresAt"`        // 到期时间（毫秒）
+	LiftedBy  string `json:"liftedBy,omitempty"`            // 解除封禁的操作人
+	LiftedAt  int64  `json:"liftedAt,omitempty"`            // 解除时间（毫秒）
+	CreatedAt int64  `json:"createdAt"`                     // 封禁时间（毫秒）
+}
+
+func (SSHBan) TableName() string {
+	return "ssh_bans"
+}
+
+// SSH会话状态
+const (
+	SSHSessionStatusActive = "active" // 在线
+	SSHSessionStatusClosed = "closed" // 已结束
+)
+
+// SSHSession SSH会话，由登录事件开始、登出事件结束
+type SSHSession struct {
+	ID           string `gorm:"primaryKey" json:"id"`          // 会话ID (UUID)
+	AgentID      string `gorm:"index;not null" json:"agentId"` // 探针ID
+	SessionID    string `gorm:"index" json:"sessionId"`        // 探针上报的会话ID（sshd 会话进程 PID）
+	Username     string `gorm:"index" json:"username"`         // 用户名
+	IP           string `gorm:"index" json:"ip"`               // 来源IP
+	IPLocation   string `json:"ipLocation,omitempty"`          // IP归属地
+	Port         string `json:"port,omitempty"`                // 来源端口
+	TTY          string `json:"tty,omitempty"`                 // 终端
+	LoginEventID string `json:"loginEventId,omitempty"`        // 对应的登录事件ID
+	Status       string `gorm:"index" json:"status"`           // 状态: active/closed
+	StartedAt    int64  `gorm:"index" json:"startedAt"`        // 开始时间（毫秒）
+	EndedAt      int64  `json:"endedAt,omitempty"`             // 结束时间（毫秒）
+	Duration     int64  `json:"duration"`                      // 会话时长（秒），在线会话为截至查询时的时长
+	SudoCount    int    `json:"sudoCount"`                     // sudo 提权次数
+	LastSudoAt   int64  `json:"lastSudoAt,omitempty"`          // 最近一次 sudo 时间（毫秒）
+	CreatedAt    int64  `json:"createdAt"`                     // 记录创建时间（毫秒）
+}
+
+func (SSHSession) TableName() string {
+	return "ssh_sessions"
+}
+
+// SSHSudoEvent SSH会话内的 sudo 提权记录
+type SSHSudoEvent struct {
+	ID        string `gorm:"primaryKey" json:"id"`          // 记录ID (UUID)
+	AgentID   string `gorm:"index;not null" json:"agentId"` // 探针ID
+	SessionID string `gorm:"index" json:"sessionId"`        // 所属会话ID（SSHSession.ID），无法关联时为空
+	Username  string `gorm:"index" json:"username"`         // 执行 sudo 的用户
+	TTY       string `json:"tty,omitempty"`                 // 终端
+	RunAs     string `json:"runAs,omitempty"`               // 目标用户
+	Command   string `json:"command"`                       // 执行的命令
+	PWD       string `json:"pwd,omitempty"`                 // 工作目录
+	Success   bool   `json:"success"`                       // 是否通过认证
+	Message   string `json:"message,omitempty"`             // 失败原因
+	Timestamp int64  `gorm:"index" json:"timestamp"`        // 时间（毫秒）
+	CreatedAt int64  `json:"createdAt"`                     // 记录创建时间（毫秒）
+}
+
+func (SSHSudoEvent) TableName() string {
+	return "ssh_sudo_events"
+}
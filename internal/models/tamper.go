@@ -1,14 +1,23 @@
 package models
 
+import (
+	"github.com/dushixiang/pika/internal/protocol"
+	"gorm.io/datatypes"
+)
+
 // TamperEvent 防篡改事件
 type TamperEvent struct {
-	ID        string `gorm:"primaryKey" json:"id"`          // 事件ID (UUID)
-	AgentID   string `gorm:"index;not null" json:"agentId"` // 探针ID
-	Path      string `gorm:"index" json:"path"`             // 被修改的路径
-	Operation string `json:"operation"`                     // 操作类型: write, remove, rename, chmod, create
-	Details   string `json:"details"`                       // 详细信息
-	Timestamp int64  `gorm:"index" json:"timestamp"`        // 事件时间（时间戳毫秒）
-	CreatedAt int64  `json:"createdAt"`                     // 记录创建时间（时间戳毫秒）
+	ID        string                                     `gorm:"primaryKey" json:"id"`            // 事件ID (UUID)
+	AgentID   string                                     `gorm:"index;not null" json:"agentId"`   // 探针ID
+	Path      string                                     `gorm:"index" json:"path"`               // 被修改的路径
+	Operation string                                     `json:"operation"`                       // 操作类型: write, remove, rename, chmod, create, attr_tamper, modified, added, deleted, restore, unlock, relock
+	Details   string                                     `json:"details"`                         // 详细信息
+	OldHash   string                                     `gorm:"type:varchar(64)" json:"oldHash"` // 基线 SHA-256（哈希模式）
+	NewHash   string                                     `gorm:"type:varchar(64)" json:"newHash"` // 当前 SHA-256（哈希模式）
+	Restored  bool                                       `json:"restored"`                        // 是否已自动恢复（attr_tamper / restore）
+	Process   datatypes.JSONType[protocol.TamperProcess] `json:"process"`                         // 修改文件的进程（PID 为 0 表示未归因）
+	Timestamp int64                                      `gorm:"index" json:"timestamp"`          // 事件时间（时间戳毫秒）
+	CreatedAt int64                                      `json:"createdAt"`                       // 记录创建时间（时间戳毫秒）
 }
 
 func (TamperEvent) TableName() string {
@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/datatypes"
+
+// Vulnerability 离线漏洞库条目（每个受影响的软件包版本区间一条）
+type Vulnerability struct {
+	ID           int64                       `gorm:"primaryKey;autoIncrement" json:"id"`
+	VulnID       string                      `gorm:"type:varchar(64);index" json:"vulnId"`                               // 漏洞ID，如 CVE-2024-1234 / DSA-5532-1
+	Aliases      datatypes.JSONSlice[string] `json:"aliases"`                                                            // 别名（通常包含 CVE ID）
+	Ecosystem    string                      `gorm:"type:varchar(64);index:idx_vuln_ecosystem_package" json:"ecosystem"` // 生态，如 Debian:12 / Ubuntu:22.04:LTS / Alpine:v3.19
+	Package      string                      `gorm:"type:varchar(128);index:idx_vuln_ecosystem_package" json:"package"`  // 软件包名（Debian/Ubuntu/Alpine 为源码包名）
+	Introduced   string                      `gorm:"type:varchar(128)" json:"introduced"`                                // 引入版本，0 表示所有版本，为空时仅匹配 Versions
+	Fixed        string                      `gorm:"type:varchar(128)" json:"fixed"`                                     // 修复版本，空表示尚未修复
+	LastAffected string                      `gorm:"type:varchar(128)" json:"lastAffected"`                              // 最后受影响版本（与 Fixed 二选一）
+	Versions     datatypes.JSONSlice[string] `json:"versions"`                                                           // 明确列出的受影响版本
+	Severity     string                      `gorm:"type:varchar(16)" json:"severity"`                                   // 严重程度: critical/high/medium/low/unknown
+	Summary      string                      `gorm:"type:text" json:"summary"`                                           // 描述
+	Source       string                      `gorm:"type:varchar(16);index" json:"source"`                               // 数据来源: osv/debian
+	ImportedAt   int64                       `json:"importedAt"`                                                         // 导入时间（时间戳毫秒）
+}
+
+func (Vulnerability) TableName() string {
+	return "vulnerabilities"
+}
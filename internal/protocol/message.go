@@ -75,6 +75,9 @@ const (
 	MessageTypeSSHLoginConfig       MessageType = "ssh_login_config"
 	MessageTypeSSHLoginConfigResult MessageType = "ssh_login_config_result" // Agent 反馈配置应用结果
 	MessageTypeSSHLoginEvent        MessageType = "ssh_login_event"
+	MessageTypeSSHBan               MessageType = "ssh_ban"        // 服务端下发封禁/解封指令
+	MessageTypeSSHBanResult         MessageType = "ssh_ban_result" // Agent 反馈封禁/解封结果
+	MessageTypeSSHSudoEvent         MessageType = "ssh_sudo_event" // Agent 上报会话内的 sudo 提权记录
 )
 
 type MetricType string
@@ -250,6 +253,13 @@ type VPSAuditResult struct {
 	CollectWarnings []string `json:"collectWarnings,omitempty"`
 }
 
+// VPSAuditReport 审计结果及Server端安全分析
+type VPSAuditReport struct {
+	VPSAuditResult
+	// Server端安全分析结果
+	Analysis *VPSAuditAnalysis `json:"analysis,omitempty"`
+}
+
 // VPSAuditAnalysis VPS安全分析结果(Server端分析后的结果)
 type VPSAuditAnalysis struct {
 	// 关联的审计ID
@@ -260,6 +270,8 @@ type VPSAuditAnalysis struct {
 	RiskScore int `json:"riskScore"`
 	// 威胁等级: low/medium/high/critical
 	ThreatLevel string `json:"threatLevel"`
+	// 各严重程度风险项数量
+	SeverityCounts map[string]int `json:"severityCounts,omitempty"`
 	// 修复建议
 	Recommendations []string `json:"recommendations,omitempty"`
 	// 分析时间
@@ -285,11 +297,12 @@ type SecurityCheck struct {
 
 // SecurityCheckSub 安全检查子项
 type SecurityCheckSub struct {
-	Name     string `json:"name"`               // 子检查名称
-	Status   string `json:"status"`             // pass/fail/warn/skip
-	Severity string `json:"severity,omitempty"` // 严重程度: high/medium/low
-	Message  string `json:"message"`            // 检查消息
-	Evidence string `json:"evidence,omitempty"` // 证据信息(简化为字符串)
+	Name        string `json:"name"`                  // 子检查名称
+	Status      string `json:"status"`                // pass/fail/warn/skip
+	Severity    string `json:"severity,omitempty"`    // 严重程度: critical/high/medium/low
+	Message     string `json:"message"`               // 检查消息
+	Evidence    string `json:"evidence,omitempty"`    // 证据信息(简化为字符串)
+	Remediation string `json:"remediation,omitempty"` // 修复建议
 }
 
 // Evidence 安全事件证据
@@ -324,8 +337,22 @@ type MonitorData struct {
 
 // TamperProtectConfig 防篡改保护配置（增量更新）
 type TamperProtectConfig struct {
-	Added   []string `json:"added,omitempty"`   // 新增保护的目录
-	Removed []string `json:"removed,omitempty"` // 移除保护的目录
+	Added        []string `json:"added,omitempty"`        // 新增保护的目录
+	Removed      []string `json:"removed,omitempty"`      // 移除保护的目录
+	Mode         string   `json:"mode,omitempty"`         // 保护模式: immutable(默认)/hash/hybrid
+	HashInterval int      `json:"hashInterval,omitempty"` // 哈希基线校验间隔(秒)
+	// 启用强制恢复策略的目录（完整列表），被修改、新增、删除的文件会按快照自动恢复
+	Enforce []string `json:"enforce,omitempty"`
+	// 需要以当前内容刷新快照的目录（为空表示不刷新）
+	RefreshSnapshot []string `json:"refreshSnapshot,omitempty"`
+	// 临时解锁窗口，探针到期后自行重新保护并更新基线
+	Unlock []TamperUnlock `json:"unlock,omitempty"`
+}
+
+// TamperUnlock 防篡改临时解锁
+type TamperUnlock struct {
+	Path     string `json:"path"`     // 受保护的目录
+	Duration int    `json:"duration"` // 剩余解锁时长(秒)，0 表示立即重新保护
 }
 
 // TamperProtectResponse 防篡改保护响应
@@ -340,10 +367,25 @@ type TamperProtectResponse struct {
 // TamperEventData 防篡改事件数据
 type TamperEventData struct {
 	Path      string `json:"path"`               // 被修改的路径
-	Operation string `json:"operation"`          // 操作类型: write, remove, rename, chmod, create, attr_tamper
+	Operation string `json:"operation"`          // 操作类型: write, remove, rename, chmod, create, attr_tamper, modified, added, deleted, restore, unlock, relock
 	Timestamp int64  `json:"timestamp"`          // 事件时间(毫秒)
 	Details   string `json:"details"`            // 详细信息
-	Restored  bool   `json:"restored,omitempty"` // 是否已自动恢复（仅用于 attr_tamper 操作）
+	Restored  bool   `json:"restored,omitempty"` // 是否已自动恢复（attr_tamper / restore 操作）
+	OldHash   string `json:"oldHash,omitempty"`  // 基线 SHA-256（仅哈希模式: modified/deleted）
+	NewHash   string `json:"newHash,omitempty"`  // 当前 SHA-256（仅哈希模式: modified/added）
+	// 修改文件的进程（Linux 下通过 fanotify 归因，删除/重命名等操作可能为空）
+	Process *TamperProcess `json:"process,omitempty"`
+}
+
+// TamperProcess 防篡改事件关联的进程信息
+type TamperProcess struct {
+	PID         int32    `json:"pid"`
+	PPID        int32    `json:"ppid"`
+	UID         uint32   `json:"uid"`
+	Username    string   `json:"username,omitempty"`
+	Exe         string   `json:"exe,omitempty"`
+	Cmdline     string   `json:"cmdline,omitempty"`
+	ProcessTree []string `json:"processTree,omitempty"` // 父进程链（从祖先到当前进程）
 }
 
 // TamperAlertData 防篡改属性告警数据
@@ -364,6 +406,7 @@ type AssetInventory struct {
 	FileAssets    *FileAssets    `json:"fileAssets,omitempty"`    // 文件资产
 	KernelAssets  *KernelAssets  `json:"kernelAssets,omitempty"`  // 内核资产
 	LoginAssets   *LoginAssets   `json:"loginAssets,omitempty"`   // 登录资产
+	PackageAssets *PackageAssets `json:"packageAssets,omitempty"` // 软件包资产
 }
 
 // AuditStatistics 审计统计摘要
@@ -689,11 +732,56 @@ type LoginStatistics struct {
 	HighFrequencyIPs map[string]int `json:"highFrequencyIPs,omitempty"` // 高频IP (登录次数>10)
 }
 
+// ==================== 软件包资产 ====================
+
+// PackageAssets 软件包资产
+type PackageAssets struct {
+	Manager       string        `json:"manager"`                 // 包管理器: dpkg/rpm/apk
+	Distro        string        `json:"distro,omitempty"`        // 发行版ID (os-release ID)，如 debian/ubuntu/alpine
+	DistroVersion string        `json:"distroVersion,omitempty"` // 发行版版本 (os-release VERSION_ID)
+	Codename      string        `json:"codename,omitempty"`      // 发行版代号 (os-release VERSION_CODENAME)
+	Packages      []PackageInfo `json:"packages,omitempty"`      // 已安装软件包
+	Total         int           `json:"total"`                   // 软件包总数
+}
+
+// PackageInfo 软件包信息
+type PackageInfo struct {
+	Name    string `json:"name"`             // 包名
+	Version string `json:"version"`          // 版本（rpm 为 [epoch:]version-release）
+	Arch    string `json:"arch,omitempty"`   // 架构
+	Source  string `json:"source,omitempty"` // 源码包名（dpkg Source / apk origin / rpm sourcerpm）
+}
+
 // ==================== SSH 登录监控相关数据结构 ====================
 
 // SSHLoginConfig SSH登录监控配置
 type SSHLoginConfig struct {
-	Enabled bool `json:"enabled"` // 是否启用监控
+	Enabled  bool            `json:"enabled"`            // 是否启用监控
+	Bans     []SSHBan        `json:"bans,omitempty"`     // 当前生效的封禁（全量同步，Agent 以此为准）
+	Sessions []SSHLoginEvent `json:"sessions,omitempty"` // 服务端记录的在线会话（Agent 重启后以此恢复会话跟踪）
+}
+
+// SSHBan 生效中的封禁
+type SSHBan struct {
+	CIDR     string `json:"cidr"`     // 封禁的 IP 或网段（CIDR 格式）
+	Duration int64  `json:"duration"` // 剩余封禁时长（秒）
+}
+
+// SSHBanRequest 封禁/解封指令
+type SSHBanRequest struct {
+	ID       string `json:"id"`                 // 封禁记录ID
+	Action   string `json:"action"`             // ban/unban
+	CIDR     string `json:"cidr"`               // 封禁的 IP 或网段（CIDR 格式）
+	Duration int64  `json:"duration,omitempty"` // 封禁时长（秒）
+}
+
+// SSHBanResult 封禁/解封结果（Agent 反馈）
+type SSHBanResult struct {
+	ID      string `json:"id"`      // 封禁记录ID
+	Action  string `json:"action"`  // ban/unban
+	CIDR    string `json:"cidr"`    // 封禁的 IP 或网段
+	Success bool   `json:"success"` // 是否成功
+	Message string `json:"message"` // 结果描述信息
 }
 
 // SSHLoginConfigResult SSH登录监控配置应用结果（Agent 反馈）
@@ -709,7 +797,22 @@ type SSHLoginEvent struct {
 	IP        string `json:"ip"`                  // 来源IP
 	Port      string `json:"port,omitempty"`      // 来源端口
 	Timestamp int64  `json:"timestamp"`           // 登录时间（毫秒时间戳）
-	Status    string `json:"status"`              // success/failed
+	Status    string `json:"status"`              // success/failed/logout
+	TTY       string `json:"tty,omitempty"`       // 终端
+	SessionID string `json:"sessionId,omitempty"` // 会话ID（sshd 会话进程 PID）
+	OpenedAt  int64  `json:"openedAt,omitempty"`  // 会话开始时间（毫秒时间戳，仅 logout）
+	Duration  int64  `json:"duration,omitempty"`  // 会话时长（秒，仅 logout）
+}
+
+// SSHSudoEvent SSH会话内的 sudo 提权记录
+type SSHSudoEvent struct {
+	SessionID string `json:"sessionId,omitempty"` // 所属会话ID，无法关联时为空
+	Username  string `json:"username"`            // 执行 sudo 的用户
 	TTY       string `json:"tty,omitempty"`       // 终端
-	SessionID string `json:"sessionId,omitempty"` // 会话ID
+	RunAs     string `json:"runAs,omitempty"`     // 目标用户
+	Command   string `json:"command,omitempty"`   // 执行的命令
+	PWD       string `json:"pwd,omitempty"`       // 工作目录
+	Success   bool   `json:"success"`             // 是否通过认证
+	Message   string `json:"message,omitempty"`   // 失败原因
+	Timestamp int64  `json:"timestamp"`           // 时间（毫秒时间戳）
 }
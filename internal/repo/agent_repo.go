@@ -123,6 +123,80 @@ func (r *AgentRepo) ListAuditResults(ctx context.Context, agentID string) ([]mod
 	return audits, err
 }
 
+// GetAuditResultByID 根据ID获取探针的审计结果
+func (r *AgentRepo) GetAuditResultByID(ctx context.Context, agentID string, id int64) (*models.AuditResult, error) {
+	var audit models.AuditResult
+	err := r.db.WithContext(ctx).
+		Where("agent_id = ? AND id = ?", agentID, id).
+		First(&audit).Error
+	if err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// GetPreviousAuditResult 获取指定审计之前的一次同类型审计结果
+func (r *AgentRepo) GetPreviousAuditResult(ctx context.Context, agentID string, resultType string, beforeID int64) (*models.AuditResult, error) {
+	var audit models.AuditResult
+	err := r.db.WithContext(ctx).
+		Where("agent_id = ? AND type = ? AND id < ?", agentID, resultType, beforeID).
+		Order("id DESC").
+		First(&audit).Error
+	if err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// DeleteAuditResultsBefore 删除指定时间之前的审计结果，每个探针最新的一条始终保留
+func (r *AgentRepo) DeleteAuditResultsBefore(ctx context.Context, before int64) (int64, error) {
+	// 子查询包一层派生表，兼容 MySQL 不允许在 DELETE 中直接查询同一张表的限制
+	latest := r.db.Table("(?) AS latest",
+		r.db.Model(&models.AuditResult{}).Select("MAX(id) AS id").Group("agent_id"),
+	).Select("id")
+	result := r.db.WithContext(ctx).
+		Where("created_at < ? AND id NOT IN (?)", before, latest).
+		Delete(&models.AuditResult{})
+	return result.RowsAffected, result.Error
+}
+
+// TrimAuditResults 每个探针只保留最新的 keep 条审计结果
+func (r *AgentRepo) TrimAuditResults(ctx context.Context, keep int) (int64, error) {
+	var agentIDs []string
+	if err := r.db.WithContext(ctx).
+		Model(&models.AuditResult{}).
+		Distinct("agent_id").
+		Pluck("agent_id", &agentIDs).Error; err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, agentID := range agentIDs {
+		// 找到第 keep 条记录的ID，删除更早的记录
+		var ids []int64
+		if err := r.db.WithContext(ctx).
+			Model(&models.AuditResult{}).
+			Where("agent_id = ?", agentID).
+			Order("id DESC").
+			Offset(keep-1).
+			Limit(1).
+			Pluck("id", &ids).Error; err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		result := r.db.WithContext(ctx).
+			Where("agent_id = ? AND id < ?", agentID, ids[0]).
+			Delete(&models.AuditResult{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+	}
+	return total, nil
+}
+
 // GetStatistics 获取探针统计数据
 func (r *AgentRepo) GetStatistics(ctx context.Context) (total int64, online int64, err error) {
 	// 获取总数
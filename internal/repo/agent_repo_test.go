@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(&models.AuditResult{}); err != nil {
+		t.Fatalf("创建测试表失败: %v", err)
+	}
+	return db
+}
+
+func TestDeleteAuditResultsBeforeKeepsLatest(t *testing.T) {
+	db := newTestDB(t)
+	r := NewAgentRepo(db)
+	ctx := context.Background()
+
+	results := []models.AuditResult{
+		{AgentID: "a", Type: "vps_audit", Result: "{}", CreatedAt: 100},
+		{AgentID: "a", Type: "vps_audit", Result: "{}", CreatedAt: 200},
+		{AgentID: "a", Type: "vps_audit", Result: "{}", CreatedAt: 900},
+		{AgentID: "b", Type: "vps_audit", Result: "{}", CreatedAt: 100}, // 唯一一条，已过期
+		{AgentID: "c", Type: "vps_audit", Result: "{}", CreatedAt: 150},
+		{AgentID: "c", Type: "vps_audit", Result: "{}", CreatedAt: 300}, // 最新一条，已过期
+	}
+	if err := db.Create(&results).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+
+	deleted, err := r.DeleteAuditResultsBefore(ctx, 500)
+	if err != nil {
+		t.Fatalf("清理失败: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("deleted = %d, want 3", deleted)
+	}
+
+	var remaining []models.AuditResult
+	db.Order("id").Find(&remaining)
+	want := map[string]int64{"a": 900, "b": 100, "c": 300}
+	if len(remaining) != len(want) {
+		t.Fatalf("应保留 %d 条，实际 %d 条: %+v", len(want), len(remaining), remaining)
+	}
+	for _, result := range remaining {
+		if want[result.AgentID] != result.CreatedAt {
+			t.Errorf("探针 %s 保留了 createdAt=%d 的结果，应保留 %d", result.AgentID, result.CreatedAt, want[result.AgentID])
+		}
+	}
+}
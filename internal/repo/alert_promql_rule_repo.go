@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type AlertPromQLRuleRepo struct {
+	orz.Repository[models.AlertPromQLRule, string]
+	db *gorm.DB
+}
+
+func NewAlertPromQLRuleRepo(db *gorm.DB) *AlertPromQLRuleRepo {
+	return &AlertPromQLRuleRepo{
+		Repository: orz.NewRepository[models.AlertPromQLRule, string](db),
+		db:         db,
+	}
+}
+
+// FindEnabled 查询启用的 PromQL 告警规则
+func (r *AlertPromQLRuleRepo) FindEnabled(ctx context.Context) ([]models.AlertPromQLRule, error) {
+	var rules []models.AlertPromQLRule
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("created_at asc").
+		Find(&rules).Error
+	return rules, err
+}
@@ -55,6 +55,74 @@ func (r *AlertRecordRepo) GetLatestAlertRecord(ctx context.Context, configID str
 	return &record, nil
 }
 
+// MarkSilenced 将告警记录标记为已静默
+func (r *AlertRecordRepo) MarkSilenced(ctx context.Context, id int64, silenceID string) error {
+	return r.db.WithContext(ctx).Model(&models.AlertRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"silenced":            true,
+			"silence_id":          silenceID,
+			"notification_status": "silenced",
+		}).Error
+}
+
+// MarkInhibited 将告警记录标记为已抑制
+func (r *AlertRecordRepo) MarkInhibited(ctx context.Context, id int64, inhibitedBy int64, reason string) error {
+	return r.db.WithContext(ctx).Model(&models.AlertRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"inhibited":           true,
+			"inhibited_by":        inhibitedBy,
+			"inhibit_reason":      reason,
+			"notification_status": "inhibited",
+		}).Error
+}
+
+// FindFiringByAgentAndTypes 查询探针指定类型中最早触发的告警中记录，不存在时返回 nil
+func (r *AlertRecordRepo) FindFiringByAgentAndTypes(ctx context.Context, agentID string, alertTypes []string, excludeID int64) (*models.AlertRecord, error) {
+	var records []models.AlertRecord
+	err := r.db.WithContext(ctx).
+		Where("agent_id = ? AND alert_type IN ? AND status = ? AND id <> ?", agentID, alertTypes, "firing", excludeID).
+		Order("fired_at asc").
+		Limit(1).
+		Find(&records).Error
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+// FindFiringByMonitor 查询监控项指定类型中最早触发的告警中记录，不存在时返回 nil
+func (r *AlertRecordRepo) FindFiringByMonitor(ctx context.Context, monitorID string, alertType string) (*models.AlertRecord, error) {
+	var records []models.AlertRecord
+	err := r.db.WithContext(ctx).
+		Where("monitor_id = ? AND alert_type = ? AND status = ?", monitorID, alertType, "firing").
+		Order("fired_at asc").
+		Limit(1).
+		Find(&records).Error
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+// FindUnacknowledgedFiring 查询指定级别中未确认、未静默、未抑制的告警中记录
+func (r *AlertRecordRepo) FindUnacknowledgedFiring(ctx context.Context, level string) ([]models.AlertRecord, error) {
+	var records []models.AlertRecord
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND level = ? AND acknowledged_at = ? AND silenced = ? AND inhibited = ?", "firing", level, 0, false, false).
+		Order("fired_at asc").
+		Find(&records).Error
+	return records, err
+}
+
+// UpdateFields 更新告警记录的部分字段
+func (r *AlertRecordRepo) UpdateFields(ctx context.Context, id int64, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.AlertRecord{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
 func (r *AlertRecordRepo) Clear(ctx context.Context) error {
 	return r.db.WithContext(ctx).Where("1=1").Delete(&models.AlertRecord{}).Error
 }
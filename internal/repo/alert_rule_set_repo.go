@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type AlertRuleSetRepo struct {
+	orz.Repository[models.AlertRuleSet, string]
+	db *gorm.DB
+}
+
+func NewAlertRuleSetRepo(db *gorm.DB) *AlertRuleSetRepo {
+	return &AlertRuleSetRepo{
+		Repository: orz.NewRepository[models.AlertRuleSet, string](db),
+		db:         db,
+	}
+}
+
+// FindEnabled 查询启用的告警规则集
+func (r *AlertRuleSetRepo) FindEnabled(ctx context.Context) ([]models.AlertRuleSet, error) {
+	var ruleSets []models.AlertRuleSet
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("priority desc, created_at asc").
+		Find(&ruleSets).Error
+	return ruleSets, err
+}
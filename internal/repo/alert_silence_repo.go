@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type AlertSilenceRepo struct {
+	orz.Repository[models.AlertSilence, string]
+	db *gorm.DB
+}
+
+func NewAlertSilenceRepo(db *gorm.DB) *AlertSilenceRepo {
+	return &AlertSilenceRepo{
+		Repository: orz.NewRepository[models.AlertSilence, string](db),
+		db:         db,
+	}
+}
+
+// FindEnabled 查询启用的告警静默
+func (r *AlertSilenceRepo) FindEnabled(ctx context.Context) ([]models.AlertSilence, error) {
+	var silences []models.AlertSilence
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("created_at asc").
+		Find(&silences).Error
+	return silences, err
+}
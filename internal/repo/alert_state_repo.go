@@ -46,6 +46,18 @@ func (r *AlertStateRepo) DeleteAlertStatesByConfigID(ctx context.Context, config
 	return r.db.WithContext(ctx).Where("config_id = ?", configID).Delete(&models.AlertState{}).Error
 }
 
+// FindByIDPrefix 查询ID以指定前缀开头的告警状态
+func (r *AlertStateRepo) FindByIDPrefix(ctx context.Context, prefix string) ([]models.AlertState, error) {
+	var states []models.AlertState
+	err := r.db.WithContext(ctx).Where("id LIKE ?", prefix+"%").Find(&states).Error
+	return states, err
+}
+
+// DeleteByIDPrefix 删除ID以指定前缀开头的告警状态
+func (r *AlertStateRepo) DeleteByIDPrefix(ctx context.Context, prefix string) error {
+	return r.db.WithContext(ctx).Where("id LIKE ?", prefix+"%").Delete(&models.AlertState{}).Error
+}
+
 // LoadAllStates 加载所有告警状态
 func (r *AlertStateRepo) LoadAllStates(ctx context.Context) ([]models.AlertState, error) {
 	var states []models.AlertState
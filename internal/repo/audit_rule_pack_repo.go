@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type AuditRulePackRepo struct {
+	orz.Repository[models.AuditRulePack, string]
+	db *gorm.DB
+}
+
+func NewAuditRulePackRepo(db *gorm.DB) *AuditRulePackRepo {
+	return &AuditRulePackRepo{
+		Repository: orz.NewRepository[models.AuditRulePack, string](db),
+		db:         db,
+	}
+}
+
+// FindAllOrderByName 查询所有规则包
+func (r *AuditRulePackRepo) FindAllOrderByName(ctx context.Context) ([]models.AuditRulePack, error) {
+	var packs []models.AuditRulePack
+	err := r.db.WithContext(ctx).
+		Order("name ASC").
+		Find(&packs).Error
+	return packs, err
+}
+
+// UpdateEnabled 更新启用状态
+func (r *AuditRulePackRepo) UpdateEnabled(ctx context.Context, id string, enabled bool, updatedAt int64) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AuditRulePack{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"enabled":    enabled,
+			"updated_at": updatedAt,
+		}).Error
+}
@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type AuditScheduleRepo struct {
+	orz.Repository[models.AuditSchedule, string]
+	db *gorm.DB
+}
+
+func NewAuditScheduleRepo(db *gorm.DB) *AuditScheduleRepo {
+	return &AuditScheduleRepo{
+		Repository: orz.NewRepository[models.AuditSchedule, string](db),
+		db:         db,
+	}
+}
+
+// FindByEnabled 根据启用状态查询定时审计计划
+func (r *AuditScheduleRepo) FindByEnabled(ctx context.Context, enabled bool) ([]models.AuditSchedule, error) {
+	var schedules []models.AuditSchedule
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", enabled).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// UpdateLastRun 更新上次执行信息
+func (r *AuditScheduleRepo) UpdateLastRun(ctx context.Context, id string, lastRunAt int64, message string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AuditSchedule{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_run_at":      lastRunAt,
+			"last_run_message": message,
+		}).Error
+}
@@ -79,3 +79,11 @@ func (r *MonitorRepo) FindByEnabledAndType(ctx context.Context, enabled bool, ty
 	}
 	return monitors, nil
 }
+
+// ClearParent 清除指向指定父监控项的依赖
+func (r *MonitorRepo) ClearParent(ctx context.Context, parentID string) error {
+	return r.GetDB(ctx).
+		Model(&models.MonitorTask{}).
+		Where("parent_id = ?", parentID).
+		Update("parent_id", "").Error
+}
@@ -24,3 +24,148 @@ func NewSSHLoginEventRepo(db *gorm.DB) *SSHLoginEventRepo {
 func (r *SSHLoginEventRepo) DeleteEventsByAgentID(ctx context.Context, agentID string) error {
 	return r.GetDB(ctx).Where("agent_id = ?", agentID).Delete(&models.SSHLoginEvent{}).Error
 }
+
+// SSHFailedIPCount 来源 IP 的登录失败次数
+type SSHFailedIPCount struct {
+	IP    string `json:"ip"`
+	Count int    `json:"count"`
+}
+
+// CountFailedByIPSince 按来源 IP 统计探针在指定时间之后（按服务端记录时间）的登录失败次数
+func (r *SSHLoginEventRepo) CountFailedByIPSince(ctx context.Context, agentID string, since int64) ([]SSHFailedIPCount, error) {
+	var counts []SSHFailedIPCount
+	err := r.GetDB(ctx).Model(&models.SSHLoginEvent{}).
+		Select("ip, COUNT(*) AS count").
+		Where("agent_id = ? AND status = ? AND created_at >= ?", agentID, "failed", since).
+		Group("ip").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// FindSuccessSince 查询探针在指定时间之后的登录成功事件（按时间倒序，最多 limit 条），username 为空时查询所有用户
+func (r *SSHLoginEventRepo) FindSuccessSince(ctx context.Context, agentID, username string, since int64, limit int) ([]models.SSHLoginEvent, error) {
+	var events []models.SSHLoginEvent
+	db := r.GetDB(ctx).
+		Select("id", "username", "ip", "ip_location", "country_code", "asn", "as_org", "timestamp").
+		Where("agent_id = ? AND status = ? AND timestamp >= ?", agentID, "success", since)
+	if username != "" {
+		db = db.Where("username = ?", username)
+	}
+	err := db.Order("timestamp desc").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// SSHBanRepo SSH封禁记录数据访问层
+type SSHBanRepo struct {
+	orz.Repository[models.SSHBan, string]
+}
+
+// NewSSHBanRepo 创建仓库
+func NewSSHBanRepo(db *gorm.DB) *SSHBanRepo {
+	return &SSHBanRepo{
+		Repository: orz.NewRepository[models.SSHBan, string](db),
+	}
+}
+
+// FindLatest 查询探针指定网段最近一次封禁
+func (r *SSHBanRepo) FindLatest(ctx context.Context, agentID, cidr string) (*models.SSHBan, bool, error) {
+	var ban models.SSHBan
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND cidr = ?", agentID, cidr).
+		Order("created_at desc").
+		Limit(1).
+		Find(&ban).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &ban, ban.ID != "", nil
+}
+
+// FindActiveByAgentID 查询探针生效中的封禁
+func (r *SSHBanRepo) FindActiveByAgentID(ctx context.Context, agentID string, now int64) ([]models.SSHBan, error) {
+	var bans []models.SSHBan
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND status IN ? AND expires_at > ?", agentID,
+			[]string{models.SSHBanStatusPending, models.SSHBanStatusActive}, now).
+		Find(&bans).Error
+	return bans, err
+}
+
+// MarkExpired 将已到期的封禁标记为到期
+func (r *SSHBanRepo) MarkExpired(ctx context.Context, now int64) error {
+	return r.GetDB(ctx).Model(&models.SSHBan{}).
+		Where("status IN ? AND expires_at <= ?", []string{models.SSHBanStatusPending, models.SSHBanStatusActive}, now).
+		Update("status", models.SSHBanStatusExpired).Error
+}
+
+// DeleteByAgentID 删除探针的所有封禁记录
+func (r *SSHBanRepo) DeleteByAgentID(ctx context.Context, agentID string) error {
+	return r.GetDB(ctx).Where("agent_id = ?", agentID).Delete(&models.SSHBan{}).Error
+}
+
+// SSHSessionRepo SSH会话数据访问层
+type SSHSessionRepo struct {
+	orz.Repository[models.SSHSession, string]
+}
+
+// NewSSHSessionRepo 创建仓库
+func NewSSHSessionRepo(db *gorm.DB) *SSHSessionRepo {
+	return &SSHSessionRepo{
+		Repository: orz.NewRepository[models.SSHSession, string](db),
+	}
+}
+
+// FindActive 查询探针上指定会话ID的在线会话
+func (r *SSHSessionRepo) FindActive(ctx context.Context, agentID, sessionID string) (*models.SSHSession, bool, error) {
+	var session models.SSHSession
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND session_id = ? AND status = ?", agentID, sessionID, models.SSHSessionStatusActive).
+		Order("started_at desc").
+		Limit(1).
+		Find(&session).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &session, session.ID != "", nil
+}
+
+// FindActiveByAgentID 查询探针的所有在线会话
+func (r *SSHSessionRepo) FindActiveByAgentID(ctx context.Context, agentID string) ([]models.SSHSession, error) {
+	var sessions []models.SSHSession
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND status = ?", agentID, models.SSHSessionStatusActive).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// IncrSudo 累加会话的 sudo 次数
+func (r *SSHSessionRepo) IncrSudo(ctx context.Context, id string, timestamp int64) error {
+	return r.GetDB(ctx).Model(&models.SSHSession{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"sudo_count":   gorm.Expr("sudo_count + 1"),
+			"last_sudo_at": gorm.Expr("CASE WHEN last_sudo_at > ? THEN last_sudo_at ELSE ? END", timestamp, timestamp),
+		}).Error
+}
+
+// DeleteByAgentID 删除探针的所有会话
+func (r *SSHSessionRepo) DeleteByAgentID(ctx context.Context, agentID string) error {
+	return r.GetDB(ctx).Where("agent_id = ?", agentID).Delete(&models.SSHSession{}).Error
+}
+
+// SSHSudoEventRepo sudo 提权记录数据访问层
+type SSHSudoEventRepo struct {
+	orz.Repository[models.SSHSudoEvent, string]
+}
+
+// NewSSHSudoEventRepo 创建仓库
+func NewSSHSudoEventRepo(db *gorm.DB) *SSHSudoEventRepo {
+	return &SSHSudoEventRepo{
+		Repository: orz.NewRepository[models.SSHSudoEvent, string](db),
+	}
+}
+
+// DeleteByAgentID 删除探针的所有 sudo 记录
+func (r *SSHSudoEventRepo) DeleteByAgentID(ctx context.Context, agentID string) error {
+	return r.GetDB(ctx).Where("agent_id = ?", agentID).Delete(&models.SSHSudoEvent{}).Error
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestCountFailedByIPSince(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&models.SSHLoginEvent{}); err != nil {
+		t.Fatalf("创建测试表失败: %v", err)
+	}
+	r := NewSSHLoginEventRepo(db)
+	ctx := context.Background()
+
+	events := []models.SSHLoginEvent{
+		{ID: "1", AgentID: "a", IP: "203.0.113.1", Status: "failed", CreatedAt: 100},
+		{ID: "2", AgentID: "a", IP: "203.0.113.1", Status: "failed", CreatedAt: 200},
+		{ID: "3", AgentID: "a", IP: "203.0.113.1", Status: "failed", CreatedAt: 300},
+		{ID: "4", AgentID: "a", IP: "203.0.113.2", Status: "failed", CreatedAt: 250},
+		{ID: "5", AgentID: "a", IP: "203.0.113.3", Status: "success", CreatedAt: 300}, // 登录成功不计入
+		{ID: "6", AgentID: "b", IP: "203.0.113.1", Status: "failed", CreatedAt: 300},  // 其他探针
+	}
+	if err := db.Create(&events).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+
+	counts, err := r.CountFailedByIPSince(ctx, "a", 200)
+	if err != nil {
+		t.Fatalf("CountFailedByIPSince() 失败: %v", err)
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].IP < counts[j].IP })
+	want := []SSHFailedIPCount{{IP: "203.0.113.1", Count: 2}, {IP: "203.0.113.2", Count: 1}}
+	if len(counts) != len(want) {
+		t.Fatalf("CountFailedByIPSince() = %+v, want %+v", counts, want)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("CountFailedByIPSince()[%d] = %+v, want %+v", i, counts[i], want[i])
+		}
+	}
+}
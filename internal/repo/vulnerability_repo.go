@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type VulnerabilityRepo struct {
+	orz.Repository[models.Vulnerability, int64]
+	db *gorm.DB
+}
+
+func NewVulnerabilityRepo(db *gorm.DB) *VulnerabilityRepo {
+	return &VulnerabilityRepo{
+		Repository: orz.NewRepository[models.Vulnerability, int64](db),
+		db:         db,
+	}
+}
+
+// VulnerabilitySourceStat 漏洞库各数据来源统计
+type VulnerabilitySourceStat struct {
+	Source     string `json:"source"`
+	Count      int64  `json:"count"`
+	ImportedAt int64  `json:"importedAt"`
+}
+
+// CreateBatch 批量写入漏洞条目（支持事务上下文）
+func (r *VulnerabilityRepo) CreateBatch(ctx context.Context, items []models.Vulnerability) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.GetDB(ctx).WithContext(ctx).CreateInBatches(items, 500).Error
+}
+
+// DeleteBySource 删除指定来源的漏洞条目，source 为空时清空漏洞库（支持事务上下文）
+func (r *VulnerabilityRepo) DeleteBySource(ctx context.Context, source string) (int64, error) {
+	query := r.GetDB(ctx).WithContext(ctx)
+	if source != "" {
+		query = query.Where("source = ?", source)
+	} else {
+		query = query.Where("1=1")
+	}
+	result := query.Delete(&models.Vulnerability{})
+	return result.RowsAffected, result.Error
+}
+
+// GetSourceStats 按数据来源统计条目数量
+func (r *VulnerabilityRepo) GetSourceStats(ctx context.Context) ([]VulnerabilitySourceStat, error) {
+	var stats []VulnerabilitySourceStat
+	err := r.db.WithContext(ctx).
+		Model(&models.Vulnerability{}).
+		Select("source, COUNT(*) AS count, MAX(imported_at) AS imported_at").
+		Group("source").
+		Order("source ASC").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// FindByEcosystemsAndPackages 查询指定生态下若干软件包的漏洞条目
+// ecosystemPrefixes 按前缀匹配（如 Ubuntu:22.04 可匹配 Ubuntu:22.04:LTS）
+func (r *VulnerabilityRepo) FindByEcosystemsAndPackages(ctx context.Context, ecosystems []string, packages []string) ([]models.Vulnerability, error) {
+	var items []models.Vulnerability
+	if len(ecosystems) == 0 || len(packages) == 0 {
+		return items, nil
+	}
+
+	ecosystemQuery := r.db.Where("1 = 0")
+	for _, ecosystem := range ecosystems {
+		ecosystemQuery = ecosystemQuery.Or("ecosystem = ?", ecosystem).Or("ecosystem LIKE ?", ecosystem+":%")
+	}
+
+	// 分批查询，避免 IN 参数过多
+	const batchSize = 500
+	for start := 0; start < len(packages); start += batchSize {
+		end := min(start+batchSize, len(packages))
+		var batch []models.Vulnerability
+		if err := r.db.WithContext(ctx).
+			Where(ecosystemQuery).
+			Where("package IN ?", packages[start:end]).
+			Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		items = append(items, batch...)
+	}
+	return items, nil
+}
@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// auditPruneSpec 审计结果清理周期
+const auditPruneSpec = "@every 1h"
+
+// AuditScheduler 定时审计调度器
+type AuditScheduler struct {
+	mu                   sync.Mutex
+	cron                 *cron.Cron
+	tasks                map[string]cron.EntryID // scheduleID -> cron EntryID
+	auditScheduleService *service.AuditScheduleService
+	logger               *zap.Logger
+	ctx                  context.Context
+	cancel               context.CancelFunc
+}
+
+// NewAuditScheduler 创建定时审计调度器
+func NewAuditScheduler(auditScheduleService *service.AuditScheduleService, logger *zap.Logger) *AuditScheduler {
+	return &AuditScheduler{
+		cron:                 cron.New(), // 标准 5 段 cron 表达式
+		tasks:                make(map[string]cron.EntryID),
+		auditScheduleService: auditScheduleService,
+		logger:               logger,
+	}
+}
+
+// Start 启动调度器
+func (s *AuditScheduler) Start(ctx context.Context) {
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	s.logger.Info("启动定时审计调度器")
+
+	s.LoadTasks()
+
+	// 定期清理历史审计结果
+	if _, err := s.cron.AddFunc(auditPruneSpec, func() {
+		if err := s.auditScheduleService.PruneAuditResults(s.ctx); err != nil {
+			s.logger.Error("清理审计结果失败", zap.Error(err))
+		}
+	}); err != nil {
+		s.logger.Error("添加审计结果清理任务失败", zap.Error(err))
+	}
+
+	s.cron.Start()
+}
+
+// Stop 停止调度器
+func (s *AuditScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	// 同时取消立即执行的计划
+	s.auditScheduleService.Shutdown()
+
+	ctx := s.cron.Stop()
+	<-ctx.Done()
+
+	s.logger.Info("定时审计调度器已停止")
+}
+
+// LoadTasks 加载所有启用的定时审计计划
+func (s *AuditScheduler) LoadTasks() {
+	schedules, err := s.auditScheduleService.FindByEnabled(context.Background(), true)
+	if err != nil {
+		s.logger.Error("加载定时审计计划失败", zap.Error(err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, schedule := range schedules {
+		if err := s.addTaskLocked(schedule.ID, schedule.Cron); err != nil {
+			s.logger.Error("添加定时审计计划失败",
+				zap.String("scheduleID", schedule.ID),
+				zap.String("name", schedule.Name),
+				zap.Error(err))
+		}
+	}
+}
+
+// AddTask 添加定时审计计划
+func (s *AuditScheduler) AddTask(scheduleID string, spec string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTaskLocked(scheduleID, spec)
+}
+
+// addTaskLocked 添加定时审计计划（需要持有锁）
+func (s *AuditScheduler) addTaskLocked(scheduleID string, spec string) error {
+	if entryID, exists := s.tasks[scheduleID]; exists {
+		s.cron.Remove(entryID)
+		delete(s.tasks, scheduleID)
+	}
+
+	entryID, err := s.cron.AddFunc(spec, func() {
+		s.executeTask(scheduleID)
+	})
+	if err != nil {
+		return fmt.Errorf("添加 cron 任务失败: %w", err)
+	}
+	s.tasks[scheduleID] = entryID
+
+	s.logger.Info("添加定时审计计划", zap.String("scheduleID", scheduleID), zap.String("cron", spec))
+	return nil
+}
+
+// UpdateTask 更新定时审计计划
+func (s *AuditScheduler) UpdateTask(scheduleID string, spec string) error {
+	return s.AddTask(scheduleID, spec)
+}
+
+// RemoveTask 删除定时审计计划
+func (s *AuditScheduler) RemoveTask(scheduleID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entryID, exists := s.tasks[scheduleID]; exists {
+		s.cron.Remove(entryID)
+		delete(s.tasks, scheduleID)
+		s.logger.Info("删除定时审计计划", zap.String("scheduleID", scheduleID))
+	}
+}
+
+// executeTask 执行定时审计计划
+func (s *AuditScheduler) executeTask(scheduleID string) {
+	schedule, err := s.auditScheduleService.GetSchedule(s.ctx, scheduleID)
+	if err != nil {
+		s.logger.Error("查询定时审计计划失败", zap.String("scheduleID", scheduleID), zap.Error(err))
+		return
+	}
+	if !schedule.Enabled {
+		s.logger.Warn("定时审计计划已禁用，跳过执行", zap.String("scheduleID", scheduleID))
+		return
+	}
+
+	if err := s.auditScheduleService.RunSchedule(s.ctx, scheduleID); err != nil {
+		s.logger.Error("执行定时审计计划失败", zap.String("scheduleID", scheduleID), zap.Error(err))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/dushixiang/pika/internal/models"
@@ -23,21 +24,35 @@ type AgentService struct {
 	AgentRepo         *repo.AgentRepo
 	TamperEventRepo   *repo.TamperEventRepo
 	SSHLoginEventRepo *repo.SSHLoginEventRepo
+	SSHBanRepo        *repo.SSHBanRepo
+	SSHSessionRepo    *repo.SSHSessionRepo
+	SSHSudoEventRepo  *repo.SSHSudoEventRepo
 	apiKeyService     *ApiKeyService
 	metricService     *MetricService
 	geoipService      *GeoIPService
+	auditRuleService  *AuditRuleService
+	notificationSvc   *NotificationService
+	auditScheduleSvc  *AuditScheduleService
+	auditAnalyzer     *AuditAnalyzer
 }
 
-func NewAgentService(logger *zap.Logger, db *gorm.DB, apiKeyService *ApiKeyService, metricService *MetricService, geoipService *GeoIPService) *AgentService {
+func NewAgentService(logger *zap.Logger, db *gorm.DB, apiKeyService *ApiKeyService, metricService *MetricService, geoipService *GeoIPService, auditRuleService *AuditRuleService, notificationSvc *NotificationService, auditScheduleSvc *AuditScheduleService) *AgentService {
 	return &AgentService{
 		logger:            logger,
 		Service:           orz.NewService(db),
 		AgentRepo:         repo.NewAgentRepo(db),
 		TamperEventRepo:   repo.NewTamperEventRepo(db),
 		SSHLoginEventRepo: repo.NewSSHLoginEventRepo(db),
+		SSHBanRepo:        repo.NewSSHBanRepo(db),
+		SSHSessionRepo:    repo.NewSSHSessionRepo(db),
+		SSHSudoEventRepo:  repo.NewSSHSudoEventRepo(db),
 		apiKeyService:     apiKeyService,
 		metricService:     metricService,
 		geoipService:      geoipService,
+		auditRuleService:  auditRuleService,
+		notificationSvc:   notificationSvc,
+		auditScheduleSvc:  auditScheduleSvc,
+		auditAnalyzer:     NewAuditAnalyzer(),
 	}
 }
 
@@ -201,6 +216,7 @@ func (s *AgentService) handleVPSAuditResponse(ctx context.Context, agentID strin
 		s.logger.Error("vps audit failed",
 			zap.String("agentID", agentID),
 			zap.String("error", resp.Error))
+		s.auditScheduleSvc.CompleteAudit(resp.ID)
 		return nil
 	}
 
@@ -210,6 +226,8 @@ func (s *AgentService) handleVPSAuditResponse(ctx context.Context, agentID strin
 	}
 
 	if resp.Status == "success" {
+		defer s.auditScheduleSvc.CompleteAudit(resp.ID)
+
 		// 解析审计结果
 		var auditResult protocol.VPSAuditResult
 		if err := json.Unmarshal([]byte(resp.Result), &auditResult); err != nil {
@@ -218,43 +236,64 @@ func (s *AgentService) handleVPSAuditResponse(ctx context.Context, agentID strin
 		}
 
 		// 存储审计结果
-		return s.SaveAuditResult(ctx, agentID, &auditResult)
+		record, err := s.SaveAuditResult(ctx, agentID, &auditResult)
+		if err != nil {
+			return err
+		}
+
+		// 定时审计与上一次结果对比，发生变化时发送通知
+		if IsScheduledAuditCommand(resp.ID) {
+			go s.notifyAuditDrift(agentID, record, &auditResult)
+		}
+		return nil
 	}
 
 	return nil
 }
 
 // SaveAuditResult 保存审计结果
-func (s *AgentService) SaveAuditResult(ctx context.Context, agentID string, result *protocol.VPSAuditResult) error {
+func (s *AgentService) SaveAuditResult(ctx context.Context, agentID string, result *protocol.VPSAuditResult) (*models.AuditResult, error) {
 	// 为登录记录添加 IP 归属地信息
 	s.enrichLoginRecordsWithLocation(result)
 
 	// 将结果序列化为JSON存储
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Server 端安全分析
+	analysis := s.analyzeAuditResult(ctx, agentID, "", result)
+	analysisJSON, err := json.Marshal(analysis)
+	if err != nil {
+		return nil, err
 	}
 
 	auditRecord := &models.AuditResult{
-		AgentID:   agentID,
-		Type:      "vps_audit",
-		Result:    string(resultJSON),
-		StartTime: result.StartTime,
-		EndTime:   result.EndTime,
-		CreatedAt: time.Now().UnixMilli(),
+		AgentID:     agentID,
+		Type:        "vps_audit",
+		Result:      string(resultJSON),
+		StartTime:   result.StartTime,
+		EndTime:     result.EndTime,
+		CreatedAt:   time.Now().UnixMilli(),
+		RiskScore:   analysis.RiskScore,
+		ThreatLevel: analysis.ThreatLevel,
+		Analysis:    string(analysisJSON),
 	}
 
 	// 保存到数据库
 	if err := s.AgentRepo.SaveAuditResult(ctx, auditRecord); err != nil {
-		return err
+		return nil, err
 	}
 
 	s.logger.Info("审计结果保存成功",
 		zap.String("agentId", agentID),
 		zap.Int64("auditId", auditRecord.ID),
+		zap.Int("riskScore", analysis.RiskScore),
+		zap.String("threatLevel", analysis.ThreatLevel),
 	)
 
-	return nil
+	return auditRecord, nil
 }
 
 // enrichLoginRecordsWithLocation 为登录记录添加IP归属地信息
@@ -300,8 +339,8 @@ func (s *AgentService) enrichLoginRecordsWithLocation(result *protocol.VPSAuditR
 	}
 }
 
-// GetAuditResult 获取最新的审计结果(原始数据)
-func (s *AgentService) GetAuditResult(ctx context.Context, agentID string) (*protocol.VPSAuditResult, error) {
+// GetAuditResult 获取最新的审计结果(原始数据及安全分析)
+func (s *AgentService) GetAuditResult(ctx context.Context, agentID string) (*protocol.VPSAuditReport, error) {
 	record, err := s.AgentRepo.GetLatestAuditResultByType(ctx, agentID, "vps_audit")
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -315,7 +354,34 @@ func (s *AgentService) GetAuditResult(ctx context.Context, agentID string) (*pro
 		return nil, err
 	}
 
-	return &result, nil
+	return &protocol.VPSAuditReport{
+		VPSAuditResult: result,
+		Analysis:       s.getAuditAnalysis(ctx, record, &result),
+	}, nil
+}
+
+// analyzeAuditResult 执行内置检查及规则包检查
+func (s *AgentService) analyzeAuditResult(ctx context.Context, agentID, auditID string, result *protocol.VPSAuditResult) *protocol.VPSAuditAnalysis {
+	var tags []string
+	if agent, err := s.AgentRepo.FindById(ctx, agentID); err == nil {
+		tags = agent.Tags
+	}
+	ruleChecks := s.auditRuleService.Evaluate(tags, &result.AssetInventory)
+	return s.auditAnalyzer.Analyze(auditID, result, ruleChecks...)
+}
+
+// getAuditAnalysis 读取审计记录中的安全分析结果，历史记录没有分析结果时现场分析
+func (s *AgentService) getAuditAnalysis(ctx context.Context, record *models.AuditResult, result *protocol.VPSAuditResult) *protocol.VPSAuditAnalysis {
+	auditID := strconv.FormatInt(record.ID, 10)
+	if record.Analysis != "" {
+		var analysis protocol.VPSAuditAnalysis
+		if err := json.Unmarshal([]byte(record.Analysis), &analysis); err == nil {
+			analysis.AuditID = auditID
+			return &analysis
+		}
+		s.logger.Warn("failed to parse audit analysis, re-analyzing", zap.Int64("auditId", record.ID))
+	}
+	return s.analyzeAuditResult(ctx, record.AgentID, auditID, result)
 }
 
 // ListAuditResults 获取审计结果列表
@@ -326,32 +392,142 @@ func (s *AgentService) ListAuditResults(ctx context.Context, agentID string) ([]
 	}
 
 	results := make([]map[string]interface{}, 0, len(records))
-	for _, record := range records {
+	for i := range records {
+		record := &records[i]
 		var auditResult protocol.VPSAuditResult
 		if err := json.Unmarshal([]byte(record.Result), &auditResult); err != nil {
 			s.logger.Error("failed to parse audit result", zap.Error(err))
 			continue
 		}
 
-		// TODO: 统计安全检查结果应该来自 Server 端分析后的 VPSAuditAnalysis
-		// Agent 端已经不再产生 SecurityChecks,需要实现 Server 端分析逻辑
+		analysis := s.getAuditAnalysis(ctx, record, &auditResult)
 
 		results = append(results, map[string]interface{}{
-			"id":          record.ID,
-			"agentId":     record.AgentID,
-			"type":        record.Type,
-			"startTime":   record.StartTime,
-			"endTime":     record.EndTime,
-			"createdAt":   record.CreatedAt,
-			"systemInfo":  auditResult.SystemInfo,
-			"statistics":  auditResult.Statistics,
-			"collectTime": auditResult.EndTime - auditResult.StartTime,
+			"id":             record.ID,
+			"agentId":        record.AgentID,
+			"type":           record.Type,
+			"startTime":      record.StartTime,
+			"endTime":        record.EndTime,
+			"createdAt":      record.CreatedAt,
+			"systemInfo":     auditResult.SystemInfo,
+			"statistics":     auditResult.Statistics,
+			"collectTime":    auditResult.EndTime - auditResult.StartTime,
+			"riskScore":      analysis.RiskScore,
+			"threatLevel":    analysis.ThreatLevel,
+			"severityCounts": analysis.SeverityCounts,
 		})
 	}
 
 	return results, nil
 }
 
+// DiffAuditResults 对比两次审计结果，targetID 为空时使用最新一次，baseID 为空时使用 target 的上一次
+func (s *AgentService) DiffAuditResults(ctx context.Context, agentID string, baseID, targetID int64) (*AuditDiff, error) {
+	var target *models.AuditResult
+	var err error
+	if targetID > 0 {
+		target, err = s.AgentRepo.GetAuditResultByID(ctx, agentID, targetID)
+	} else {
+		target, err = s.AgentRepo.GetLatestAuditResultByType(ctx, agentID, "vps_audit")
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "审计结果不存在")
+		}
+		return nil, err
+	}
+
+	var base *models.AuditResult
+	if baseID > 0 {
+		base, err = s.AgentRepo.GetAuditResultByID(ctx, agentID, baseID)
+	} else {
+		base, err = s.AgentRepo.GetPreviousAuditResult(ctx, agentID, target.Type, target.ID)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "没有可对比的审计结果")
+		}
+		return nil, err
+	}
+
+	return s.diffAuditRecords(base, target)
+}
+
+// diffAuditRecords 对比两条审计记录
+func (s *AgentService) diffAuditRecords(base, target *models.AuditResult) (*AuditDiff, error) {
+	var baseResult, targetResult protocol.VPSAuditResult
+	if err := json.Unmarshal([]byte(base.Result), &baseResult); err != nil {
+		return nil, fmt.Errorf("解析审计结果失败: %w", err)
+	}
+	if err := json.Unmarshal([]byte(target.Result), &targetResult); err != nil {
+		return nil, fmt.Errorf("解析审计结果失败: %w", err)
+	}
+
+	diff := DiffAuditResult(&baseResult, &targetResult)
+	diff.BaseID = base.ID
+	diff.TargetID = target.ID
+	diff.BaseTime = base.CreatedAt
+	diff.TargetTime = target.CreatedAt
+	return diff, nil
+}
+
+// notifyAuditDrift 对比上一次审计结果，发生变化时发送通知
+func (s *AgentService) notifyAuditDrift(agentID string, record *models.AuditResult, result *protocol.VPSAuditResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	previous, err := s.AgentRepo.GetPreviousAuditResult(ctx, agentID, record.Type, record.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("获取上一次审计结果失败", zap.String("agentId", agentID), zap.Error(err))
+		}
+		return
+	}
+
+	var previousResult protocol.VPSAuditResult
+	if err := json.Unmarshal([]byte(previous.Result), &previousResult); err != nil {
+		s.logger.Error("failed to parse audit result", zap.Error(err))
+		return
+	}
+
+	diff := DiffAuditResult(&previousResult, result)
+	if !diff.HasDrift() {
+		return
+	}
+
+	agent, err := s.AgentRepo.FindById(ctx, agentID)
+	if err != nil {
+		s.logger.Error("获取探针信息失败", zap.String("agentId", agentID), zap.Error(err))
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	alertRecord := &models.AlertRecord{
+		AgentID:     agentID,
+		AgentName:   agent.Name,
+		AlertType:   "audit_drift",
+		Message:     fmt.Sprintf("定时审计检测到资产变化（新增 %d 项，移除 %d 项）：\n%s", diff.TotalAdded, diff.TotalRemoved, diff.Summary(5)),
+		Threshold:   0,
+		ActualValue: float64(diff.TotalAdded + diff.TotalRemoved),
+		Level:       "warning",
+		Status:      "notice",
+		FiredAt:     now,
+		CreatedAt:   now,
+	}
+
+	s.logger.Info("定时审计检测到资产变化",
+		zap.String("agentId", agentID),
+		zap.Int64("baseId", previous.ID),
+		zap.Int64("targetId", record.ID),
+		zap.Int("added", diff.TotalAdded),
+		zap.Int("removed", diff.TotalRemoved),
+	)
+
+	if err := s.notificationSvc.SendAlertNotification(ctx, NotificationTypeAuditDrift, alertRecord, &agent); err != nil {
+		s.logger.Error("发送审计变更通知失败", zap.String("agentId", agentID), zap.Error(err))
+	}
+}
+
 // GetStatistics 获取探针统计数据
 func (s *AgentService) GetStatistics(ctx context.Context) (map[string]interface{}, error) {
 	total, online, err := s.AgentRepo.GetStatistics(ctx)
@@ -395,7 +571,23 @@ func (s *AgentService) DeleteAgent(ctx context.Context, agentID string) error {
 			return err
 		}
 
-		// 4. 最后删除探针本身
+		// 4. 删除探针的SSH封禁记录
+		if err := s.SSHBanRepo.DeleteByAgentID(ctx, agentID); err != nil {
+			s.logger.Error("删除探针SSH封禁记录失败", zap.String("agentId", agentID), zap.Error(err))
+			return err
+		}
+
+		// 5. 删除探针的SSH会话和 sudo 记录
+		if err := s.SSHSudoEventRepo.DeleteByAgentID(ctx, agentID); err != nil {
+			s.logger.Error("删除探针sudo记录失败", zap.String("agentId", agentID), zap.Error(err))
+			return err
+		}
+		if err := s.SSHSessionRepo.DeleteByAgentID(ctx, agentID); err != nil {
+			s.logger.Error("删除探针SSH会话失败", zap.String("agentId", agentID), zap.Error(err))
+			return err
+		}
+
+		// 6. 最后删除探针本身
 		if err := s.AgentRepo.DeleteById(ctx, agentID); err != nil {
 			s.logger.Error("删除探针失败", zap.String("agentId", agentID), zap.Error(err))
 			return err
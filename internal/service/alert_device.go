@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+)
+
+// deviceMatcher 按磁盘挂载点或网卡名称匹配告警目标，以 ~ 开头的目标按正则匹配，其余按名称精确匹配
+type deviceMatcher struct {
+	names    []string
+	patterns []*regexp.Regexp
+}
+
+// newDeviceMatcher 编译告警目标，正则无效时忽略该目标并返回错误
+func newDeviceMatcher(targets []string) (*deviceMatcher, error) {
+	matcher := &deviceMatcher{}
+	var errs []string
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if !strings.HasPrefix(target, "~") {
+			matcher.names = append(matcher.names, target)
+			continue
+		}
+		pattern, err := regexp.Compile(strings.TrimPrefix(target, "~"))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target, err))
+			continue
+		}
+		matcher.patterns = append(matcher.patterns, pattern)
+	}
+	if len(errs) > 0 {
+		return matcher, fmt.Errorf("正则无效：%s", strings.Join(errs, "; "))
+	}
+	return matcher, nil
+}
+
+func (m *deviceMatcher) match(name string) bool {
+	if slices.Contains(m.names, name) {
+		return true
+	}
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAlertRuleTargets 校验告警规则中的挂载点和网卡目标
+func validateAlertRuleTargets(rules *models.AlertRules) error {
+	if _, err := newDeviceMatcher(rules.DiskMountPoints); err != nil {
+		return orz.NewError(400, "挂载点"+err.Error())
+	}
+	if _, err := newDeviceMatcher(rules.NetworkInterfaces); err != nil {
+		return orz.NewError(400, "网卡"+err.Error())
+	}
+	return nil
+}
+
+// deviceStateKey 按设备告警的状态ID
+func deviceStateKey(agentID, alertType, device string) string {
+	return fmt.Sprintf("%s:global:%s:%s", agentID, alertType, device)
+}
+
+// checkDeviceAlerts 对匹配目标的每个挂载点或网卡单独检查告警，values 为设备名称到当前值的映射
+func (s *AlertService) checkDeviceAlerts(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, alertType string, targets []string, values map[string]float64, threshold metricThreshold, now int64) {
+	matcher, err := newDeviceMatcher(targets)
+	if err != nil {
+		s.logger.Warn("告警目标配置无效", zap.String("agentId", agent.ID), zap.String("alertType", alertType), zap.Error(err))
+	}
+
+	devices := make([]string, 0, len(values))
+	keep := make(map[string]bool)
+	for device := range values {
+		if matcher.match(device) {
+			devices = append(devices, device)
+			keep[deviceStateKey(agent.ID, alertType, device)] = true
+		}
+	}
+	slices.Sort(devices)
+
+	s.closeStaleAlertStates(ctx, config, agent, alertType, keep, now)
+	for _, device := range devices {
+		s.checkAlert(ctx, config, agent, rules, alertType, device, values[device], threshold, now)
+	}
+}
+
+// closeStaleAlertStates 关闭不再检查的告警状态（汇总与按设备告警相互切换、设备不再匹配或已移除），告警中时发送恢复通知
+func (s *AlertService) closeStaleAlertStates(ctx context.Context, config *models.AlertConfig, agent *models.Agent, alertType string, keep map[string]bool, now int64) {
+	aggregateKey := fmt.Sprintf("%s:global:%s", agent.ID, alertType)
+	states, err := s.AlertStateRepo.FindByIDPrefix(ctx, aggregateKey)
+	if err != nil {
+		s.logger.Error("查询告警状态失败", zap.String("agentId", agent.ID), zap.Error(err))
+		return
+	}
+
+	for i := range states {
+		state := &states[i]
+		// 前缀同样会匹配其他告警类型（如 disk_forecast），只处理汇总和按设备的状态
+		if state.ID != aggregateKey && !strings.HasPrefix(state.ID, aggregateKey+":") {
+			continue
+		}
+		if keep[state.ID] {
+			continue
+		}
+
+		if state.FlapRecordID > 0 {
+			state.IsFiring = false
+			s.endFlapping(ctx, agent, state, now)
+		} else if state.IsFiring {
+			s.resolveAlert(ctx, config, agent, state)
+		}
+		if err := s.AlertStateRepo.DeleteAlertState(ctx, state.ID); err != nil {
+			s.logger.Error("删除告警状态失败", zap.String("stateId", state.ID), zap.Error(err))
+		}
+	}
+}
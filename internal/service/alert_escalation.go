@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AlertAckRequest 确认告警请求
+type AlertAckRequest struct {
+	Comment string `json:"comment"`
+}
+
+// AlertAssignRequest 指派告警请求，负责人为空表示取消指派
+type AlertAssignRequest struct {
+	Assignee string `json:"assignee"`
+}
+
+func (s *AlertService) getAlertRecord(ctx context.Context, id int64) (*models.AlertRecord, error) {
+	record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "告警记录不存在")
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// AcknowledgeAlert 确认告警，确认后不再重复通知和升级
+func (s *AlertService) AcknowledgeAlert(ctx context.Context, id int64, operator string, req *AlertAckRequest) (*models.AlertRecord, error) {
+	record, err := s.getAlertRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != "firing" {
+		return nil, orz.NewError(400, "只能确认告警中的记录")
+	}
+	if record.AcknowledgedAt > 0 {
+		return nil, orz.NewError(400, fmt.Sprintf("告警已被 %s 确认", record.AcknowledgedBy))
+	}
+
+	now := time.Now().UnixMilli()
+	record.AcknowledgedAt = now
+	record.AcknowledgedBy = operator
+	record.AckComment = strings.TrimSpace(req.Comment)
+	if err := s.AlertRecordRepo.UpdateFields(ctx, record.ID, map[string]interface{}{
+		"acknowledged_at": record.AcknowledgedAt,
+		"acknowledged_by": record.AcknowledgedBy,
+		"ack_comment":     record.AckComment,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("告警已确认",
+		zap.Int64("recordId", record.ID),
+		zap.String("operator", operator),
+		zap.String("comment", record.AckComment))
+	return record, nil
+}
+
+// AssignAlert 指派告警负责人
+func (s *AlertService) AssignAlert(ctx context.Context, id int64, operator string, req *AlertAssignRequest) (*models.AlertRecord, error) {
+	record, err := s.getAlertRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Assignee = strings.TrimSpace(req.Assignee)
+	record.AssignedAt = 0
+	if record.Assignee != "" {
+		record.AssignedAt = time.Now().UnixMilli()
+	}
+	if err := s.AlertRecordRepo.UpdateFields(ctx, record.ID, map[string]interface{}{
+		"assignee":    record.Assignee,
+		"assigned_at": record.AssignedAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("告警已指派",
+		zap.Int64("recordId", record.ID),
+		zap.String("assignee", record.Assignee),
+		zap.String("operator", operator))
+	return record, nil
+}
+
+// ProcessUnacknowledgedAlerts 重复通知未确认的严重告警，超时未确认时升级到升级通知渠道
+func (s *AlertService) ProcessUnacknowledgedAlerts(ctx context.Context) error {
+	config, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return err
+	}
+	escalation := config.Escalation
+	if !config.Enabled || (!escalation.RepeatEnabled && !escalation.EscalationEnabled) {
+		return nil
+	}
+
+	records, err := s.AlertRecordRepo.FindUnacknowledgedFiring(ctx, "critical")
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	channelConfigs, err := s.propertyService.GetNotificationChannelConfigs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var enabledChannels, escalationChannels []models.NotificationChannelConfig
+	for _, channel := range channelConfigs {
+		if channel.Enabled {
+			enabledChannels = append(enabledChannels, channel)
+		}
+		// 升级渠道无需启用且不受路由规则限制，仅在升级时使用
+		if slices.Contains(escalation.EscalationChannels, channel.ID) {
+			channel.Enabled = true
+			channel.Routes = nil
+			escalationChannels = append(escalationChannels, channel)
+		}
+	}
+
+	agents := make(map[string]*models.Agent)
+	now := time.Now().UnixMilli()
+	repeatInterval := int64(escalation.RepeatInterval) * 60 * 1000
+	escalationTimeout := int64(escalation.EscalationTimeout) * 60 * 1000
+
+	for i := range records {
+		record := &records[i]
+		// 抖动期间不提醒，抖动结束后已汇总通知
+		if record.Flapping && record.FlapEndedAt == 0 {
+			continue
+		}
+
+		escalate := escalation.EscalationEnabled && record.EscalatedAt == 0 &&
+			now-record.FiredAt >= escalationTimeout
+		repeat := escalation.RepeatEnabled &&
+			(escalation.RepeatMaxTimes <= 0 || record.RepeatCount < escalation.RepeatMaxTimes) &&
+			now-max(record.FiredAt, record.LastRepeatAt) >= repeatInterval
+		if !escalate && !repeat {
+			continue
+		}
+
+		agent := s.lookupAgent(ctx, agents, record.AgentID)
+		// 告警静默期间不提醒
+		if s.silenceService.Apply(ctx, record, agent) {
+			continue
+		}
+
+		updates := make(map[string]interface{})
+		if escalate {
+			record.EscalatedAt = now
+			updates["escalated_at"] = now
+		}
+		if repeat {
+			record.RepeatCount++
+			record.LastRepeatAt = now
+			updates["repeat_count"] = record.RepeatCount
+			updates["last_repeat_at"] = now
+		}
+		// 先记录提醒时间，避免发送失败时每轮重复发送
+		if err := s.AlertRecordRepo.UpdateFields(ctx, record.ID, updates); err != nil {
+			s.logger.Error("更新告警提醒状态失败", zap.Int64("recordId", record.ID), zap.Error(err))
+			continue
+		}
+
+		// 重复通知发送到已启用的渠道，已升级的告警同时发送到升级渠道（升级渠道优先，不受路由规则限制）
+		var channels []models.NotificationChannelConfig
+		if record.EscalatedAt > 0 {
+			channels = append(channels, escalationChannels...)
+		}
+		if repeat {
+			for _, channel := range enabledChannels {
+				if !slices.ContainsFunc(channels, func(c models.NotificationChannelConfig) bool { return c.ID == channel.ID }) {
+					channels = append(channels, channel)
+				}
+			}
+		}
+		if len(channels) == 0 {
+			s.logger.Warn("没有可用的提醒通知渠道", zap.Int64("recordId", record.ID))
+			continue
+		}
+
+		s.logger.Info("发送未确认告警提醒",
+			zap.Int64("recordId", record.ID),
+			zap.String("agentId", record.AgentID),
+			zap.String("alertType", record.AlertType),
+			zap.Bool("escalate", escalate),
+			zap.Int("repeatCount", record.RepeatCount))
+
+		sendCtx, cancel := context.WithTimeout(ctx, notificationTimeout)
+		if err := s.notifier.SendNotificationByConfigs(sendCtx, channels, record, agent, config.MaskIP); err != nil {
+			s.logger.Error("发送未确认告警提醒失败", zap.Int64("recordId", record.ID), zap.Error(err))
+		}
+		s.saveNotificationThreads(ctx, record)
+		cancel()
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestProcessUnacknowledgedAlerts(t *testing.T) {
+	escalation := models.AlertEscalation{
+		RepeatEnabled:      true,
+		RepeatInterval:     10,
+		RepeatMaxTimes:     2,
+		EscalationEnabled:  true,
+		EscalationTimeout:  30,
+		EscalationChannels: []string{"oncall"},
+	}
+	ago := func(minutes int) int64 {
+		return time.Now().Add(-time.Duration(minutes) * time.Minute).UnixMilli()
+	}
+
+	tests := []struct {
+		name       string
+		record     models.AlertRecord
+		channels   []string // 收到提醒的渠道
+		repeat     int      // 处理后的重复通知次数
+		escalated  bool
+		notRemind  bool // 未到提醒时间，提醒状态不应变化
+		escalation *models.AlertEscalation
+	}{
+		{
+			name:      "未到重复通知间隔",
+			record:    models.AlertRecord{FiredAt: ago(5)},
+			notRemind: true,
+		},
+		{
+			name:     "到达重复通知间隔",
+			record:   models.AlertRecord{FiredAt: ago(15)},
+			channels: []string{"ops"},
+			repeat:   1,
+		},
+		{
+			name:      "超时未确认时升级并重复通知",
+			record:    models.AlertRecord{FiredAt: ago(40)},
+			channels:  []string{"oncall", "ops"},
+			repeat:    1,
+			escalated: true,
+		},
+		{
+			name:      "刚重复通知过时只升级",
+			record:    models.AlertRecord{FiredAt: ago(40), RepeatCount: 1, LastRepeatAt: ago(5)},
+			channels:  []string{"oncall"},
+			repeat:    1,
+			escalated: true,
+		},
+		{
+			name:      "已升级的告警重复通知时同时发送到升级渠道",
+			record:    models.AlertRecord{FiredAt: ago(60), EscalatedAt: ago(30), RepeatCount: 1, LastRepeatAt: ago(15)},
+			channels:  []string{"oncall", "ops"},
+			repeat:    2,
+			escalated: true,
+		},
+		{
+			name:      "达到最多重复次数且已升级",
+			record:    models.AlertRecord{FiredAt: ago(60), EscalatedAt: ago(30), RepeatCount: 2, LastRepeatAt: ago(15)},
+			notRemind: true,
+		},
+		{
+			name:      "重复次数不限制",
+			record:    models.AlertRecord{FiredAt: ago(60), EscalatedAt: ago(30), RepeatCount: 5, LastRepeatAt: ago(15)},
+			channels:  []string{"oncall", "ops"},
+			repeat:    6,
+			escalated: true,
+			escalation: &models.AlertEscalation{
+				RepeatEnabled:      true,
+				RepeatInterval:     10,
+				EscalationEnabled:  true,
+				EscalationTimeout:  30,
+				EscalationChannels: []string{"oncall"},
+			},
+		},
+		{
+			name:      "只启用升级时不重复通知",
+			record:    models.AlertRecord{FiredAt: ago(40)},
+			channels:  []string{"oncall"},
+			escalated: true,
+			escalation: &models.AlertEscalation{
+				EscalationEnabled:  true,
+				EscalationTimeout:  30,
+				EscalationChannels: []string{"oncall"},
+			},
+		},
+		{
+			name:      "抖动期间不提醒",
+			record:    models.AlertRecord{FiredAt: ago(40), Flapping: true},
+			notRemind: true,
+		},
+		{
+			name:     "抖动结束后继续提醒",
+			record:   models.AlertRecord{FiredAt: ago(15), Flapping: true, FlapEndedAt: ago(1)},
+			channels: []string{"ops"},
+			repeat:   1,
+		},
+		{
+			name:      "已确认的告警不提醒",
+			record:    models.AlertRecord{FiredAt: ago(40), AcknowledgedAt: ago(35)},
+			notRemind: true,
+		},
+		{
+			name:      "非严重告警不提醒",
+			record:    models.AlertRecord{FiredAt: ago(40), Level: "warning"},
+			notRemind: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var received []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				received = append(received, r.URL.Path[1:])
+				mu.Unlock()
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			config := models.AlertConfig{Enabled: true, Escalation: escalation}
+			if tt.escalation != nil {
+				config.Escalation = *tt.escalation
+			}
+			s, db := newTestAlertService(t, config)
+			ctx := context.Background()
+
+			webhook := func(path string) map[string]interface{} {
+				return map[string]interface{}{"url": server.URL + "/" + path, "customBody": `{"text":"{{message}}"}`}
+			}
+			// 升级渠道无需启用，未启用的普通渠道不接收重复通知
+			channels := []models.NotificationChannelConfig{
+				{ID: "ops", Type: "webhook", Enabled: true, Config: webhook("ops")},
+				{ID: "oncall", Type: "webhook", Enabled: false, Config: webhook("oncall")},
+				{ID: "disabled", Type: "webhook", Enabled: false, Config: webhook("disabled")},
+			}
+			value, err := json.Marshal(channels)
+			if err != nil {
+				t.Fatalf("序列化通知渠道失败: %v", err)
+			}
+			if err := db.Create(&models.Property{ID: PropertyIDNotificationChannels, Value: string(value)}).Error; err != nil {
+				t.Fatalf("写入通知渠道失败: %v", err)
+			}
+
+			record := tt.record
+			record.AgentID = "a1"
+			record.AlertType = "cpu"
+			record.Status = "firing"
+			if record.Level == "" {
+				record.Level = "critical"
+			}
+			if err := db.Create(&record).Error; err != nil {
+				t.Fatalf("写入告警记录失败: %v", err)
+			}
+
+			if err := s.ProcessUnacknowledgedAlerts(ctx); err != nil {
+				t.Fatalf("ProcessUnacknowledgedAlerts() 失败: %v", err)
+			}
+
+			mu.Lock()
+			got := slices.Clone(received)
+			mu.Unlock()
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.channels) {
+				t.Errorf("收到提醒的渠道 = %v, want %v", got, tt.channels)
+			}
+
+			updated, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, record.ID)
+			if err != nil {
+				t.Fatalf("查询告警记录失败: %v", err)
+			}
+			if tt.notRemind {
+				if updated.RepeatCount != record.RepeatCount || updated.LastRepeatAt != record.LastRepeatAt || updated.EscalatedAt != record.EscalatedAt {
+					t.Errorf("未到提醒时间时提醒状态不应变化: %+v", updated)
+				}
+				return
+			}
+			if updated.RepeatCount != tt.repeat {
+				t.Errorf("重复通知次数 = %d, want %d", updated.RepeatCount, tt.repeat)
+			}
+			if (updated.EscalatedAt > 0) != tt.escalated {
+				t.Errorf("升级时间 = %d, want escalated %v", updated.EscalatedAt, tt.escalated)
+			}
+			if tt.repeat > record.RepeatCount && updated.LastRepeatAt <= record.LastRepeatAt {
+				t.Errorf("重复通知后应更新最近提醒时间: %d", updated.LastRepeatAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/utils"
+	"go.uber.org/zap"
+)
+
+// flapAction 抖动检测结果
+type flapAction int
+
+const (
+	flapNone     flapAction = iota // 未抖动，按正常流程触发/恢复
+	flapStart                      // 开始抖动
+	flapContinue                   // 抖动中
+	flapEnd                        // 抖动结束
+)
+
+// detectFlapping 记录状态变化时间并判断抖动状态，窗口内状态变化次数达到阈值时开始抖动，窗口内不再变化时结束
+func (s *AlertService) detectFlapping(config *models.AlertConfig, state *models.AlertState, transitioned bool, now int64) flapAction {
+	if !config.Flapping.Enabled {
+		state.Transitions = nil
+		if state.FlapRecordID > 0 {
+			return flapEnd
+		}
+		return flapNone
+	}
+
+	if transitioned {
+		state.Transitions = append(state.Transitions, now)
+	}
+	windowStart := now - int64(config.Flapping.Window)*60*1000
+	transitions := state.Transitions[:0]
+	for _, t := range state.Transitions {
+		if t > windowStart {
+			transitions = append(transitions, t)
+		}
+	}
+	state.Transitions = transitions
+
+	if state.FlapRecordID > 0 {
+		if len(state.Transitions) == 0 {
+			return flapEnd
+		}
+		return flapContinue
+	}
+	if transitioned && len(state.Transitions) >= config.Flapping.Threshold {
+		return flapStart
+	}
+	return flapNone
+}
+
+// startFlapping 开始抖动：告警中的记录转为抖动告警，否则新建抖动告警记录，并发送一次抖动通知
+func (s *AlertService) startFlapping(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, state *models.AlertState, now int64) {
+	s.logger.Info("告警状态频繁变化，进入抖动",
+		zap.String("agentId", agent.ID),
+		zap.String("alertType", state.AlertType),
+		zap.Int("transitions", len(state.Transitions)))
+
+	name := getAlertTypeMetadata(state.AlertType).Name
+	if state.Device != "" {
+		name = fmt.Sprintf("%s（%s）", name, state.Device)
+	}
+	message := fmt.Sprintf("%s在%d分钟内触发/恢复%d次，判定为抖动",
+		name, config.Flapping.Window, len(state.Transitions))
+
+	var record *models.AlertRecord
+	if state.LastRecordID > 0 {
+		existingRecord, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, state.LastRecordID)
+		if err != nil {
+			s.logger.Error("获取告警记录失败", zap.Error(err))
+		} else if existingRecord.Status == "firing" {
+			record = existingRecord
+		}
+	}
+
+	if record != nil {
+		record.Flapping = true
+		record.FlapCount = len(state.Transitions)
+		record.Message = message
+		if err := s.AlertRecordRepo.UpdateAlertRecord(ctx, record); err != nil {
+			s.logger.Error("更新抖动告警记录失败", zap.Error(err))
+			return
+		}
+	} else {
+		record = &models.AlertRecord{
+			AgentID:     agent.ID,
+			AgentName:   agent.Name,
+			AlertType:   state.AlertType,
+			Message:     message,
+			Threshold:   state.Threshold,
+			ActualValue: state.Value,
+			Level:       s.calculateLevel(state.Value, state.Threshold),
+			Status:      "firing",
+			FiredAt:     now,
+			Flapping:    true,
+			FlapCount:   len(state.Transitions),
+			Device:      state.Device,
+			StateID:     state.ID,
+			CreatedAt:   now,
+		}
+		rules.applyTo(record)
+		if err := s.AlertRecordRepo.CreateAlertRecord(ctx, record); err != nil {
+			s.logger.Error("创建抖动告警记录失败", zap.Error(err))
+			return
+		}
+	}
+
+	state.FlapRecordID = record.ID
+	state.LastRecordID = 0
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+		return
+	}
+
+	go s.sendAlertNotification(record, agent)
+}
+
+// updateFlapping 抖动期间只累计状态变化次数，不发送通知
+func (s *AlertService) updateFlapping(ctx context.Context, state *models.AlertState) {
+	record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, state.FlapRecordID)
+	if err != nil {
+		s.logger.Error("获取抖动告警记录失败", zap.Error(err))
+		return
+	}
+	if err := s.AlertRecordRepo.UpdateFields(ctx, record.ID, map[string]interface{}{
+		"flap_count":   record.FlapCount + 1,
+		"actual_value": max(record.ActualValue, state.Value),
+	}); err != nil {
+		s.logger.Error("更新抖动告警记录失败", zap.Error(err))
+	}
+}
+
+// endFlapping 结束抖动并发送汇总通知：仍超过阈值时抖动记录转为普通告警中记录，否则恢复
+func (s *AlertService) endFlapping(ctx context.Context, agent *models.Agent, state *models.AlertState, now int64) {
+	s.logger.Info("告警抖动结束",
+		zap.String("agentId", agent.ID),
+		zap.String("alertType", state.AlertType),
+		zap.Bool("firing", state.IsFiring))
+
+	recordID := state.FlapRecordID
+	state.FlapRecordID = 0
+
+	record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, recordID)
+	if err != nil {
+		s.logger.Error("获取抖动告警记录失败", zap.Error(err))
+	} else if record.Status == "firing" {
+		record.FlapEndedAt = now
+		record.UpdatedAt = now
+		if state.IsFiring {
+			// 后续由正常流程恢复该记录
+			state.LastRecordID = record.ID
+		} else {
+			record.Status = "resolved"
+			record.ResolvedValue = state.Value
+			record.ResolvedAt = now
+		}
+
+		if err := s.AlertRecordRepo.UpdateAlertRecord(ctx, record); err != nil {
+			s.logger.Error("更新抖动告警记录失败", zap.Error(err))
+		} else {
+			go s.sendAlertNotification(record, agent)
+		}
+	}
+
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+	}
+}
+
+// flapSummary 抖动告警的通知摘要
+func flapSummary(record *models.AlertRecord) string {
+	switch {
+	case record.FlapEndedAt == 0:
+		return fmt.Sprintf("🔁 告警抖动中，已触发/恢复%d次，稳定前不再单独通知", record.FlapCount)
+	case record.Status == "firing":
+		return fmt.Sprintf("🔁 抖动已结束（期间触发/恢复%d次，持续%s），仍处于告警中",
+			record.FlapCount, utils.FormatDuration(record.FlapEndedAt-record.FiredAt))
+	default:
+		return fmt.Sprintf("🔁 抖动期间触发/恢复%d次", record.FlapCount)
+	}
+}
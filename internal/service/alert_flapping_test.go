@@ -0,0 +1,165 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestNewMetricThreshold(t *testing.T) {
+	tests := []struct {
+		name              string
+		recoveryThreshold float64
+		recoveryDuration  int
+		wantThreshold     float64
+		wantDuration      int
+	}{
+		{"未配置恢复阈值时与告警阈值相同", 0, 0, 80, 0},
+		{"恢复阈值高于告警阈值时无效", 90, 30, 80, 30},
+		{"正常的恢复阈值", 70, 60, 70, 60},
+		{"负的恢复时长按 0 处理", 70, -5, 70, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newMetricThreshold(80, 60, tt.recoveryThreshold, tt.recoveryDuration)
+			if got.RecoveryThreshold != tt.wantThreshold || got.RecoveryDuration != tt.wantDuration {
+				t.Errorf("newMetricThreshold() = %+v, want recovery %v/%d", got, tt.wantThreshold, tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestMetricThresholdEvaluate(t *testing.T) {
+	// 告警阈值 80 持续 60 秒，低于 70 持续 30 秒后恢复
+	threshold := newMetricThreshold(80, 60, 70, 30)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	type step struct {
+		second  int64
+		value   float64
+		fire    bool
+		resolve bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "超过阈值持续足够时间后触发",
+			steps: []step{
+				{0, 85, false, false},
+				{30, 90, false, false},
+				{60, 85, true, false},
+				{90, 95, false, false},
+			},
+		},
+		{
+			name: "持续时间内回落不触发",
+			steps: []step{
+				{0, 85, false, false},
+				{30, 75, false, false},
+				{60, 85, false, false},
+				{100, 85, false, false},
+			},
+		},
+		{
+			name: "在告警阈值和恢复阈值之间不恢复",
+			steps: []step{
+				{0, 85, false, false},
+				{60, 85, true, false},
+				{90, 75, false, false},
+				{300, 75, false, false},
+			},
+		},
+		{
+			name: "低于恢复阈值持续恢复时长后恢复",
+			steps: []step{
+				{0, 85, false, false},
+				{60, 85, true, false},
+				{90, 60, false, false},
+				{110, 65, false, false},
+				{120, 60, false, true},
+			},
+		},
+		{
+			name: "恢复计时期间回到恢复阈值以上重新计时",
+			steps: []step{
+				{0, 85, false, false},
+				{60, 85, true, false},
+				{90, 60, false, false},
+				{110, 75, false, false},
+				{120, 60, false, false},
+				{150, 60, false, true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &models.AlertState{}
+			for _, s := range tt.steps {
+				fire, resolve := threshold.evaluate(state, s.value, base+s.second*1000)
+				if fire != s.fire || resolve != s.resolve {
+					t.Fatalf("第 %d 秒值 %v: evaluate() = (%v, %v), want (%v, %v)", s.second, s.value, fire, resolve, s.fire, s.resolve)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectFlapping(t *testing.T) {
+	s := &AlertService{}
+	config := &models.AlertConfig{Flapping: models.AlertFlapping{Enabled: true, Window: 10, Threshold: 3}}
+	minute := int64(60 * 1000)
+
+	type step struct {
+		minute       int64
+		transitioned bool
+		want         flapAction
+	}
+	state := &models.AlertState{}
+	steps := []step{
+		{0, true, flapNone},
+		{1, true, flapNone},
+		{2, false, flapNone},
+		{3, true, flapStart},
+		{4, true, flapContinue},
+		{10, false, flapContinue},
+		// 第 4 分钟的状态变化移出窗口后结束抖动
+		{15, false, flapEnd},
+	}
+	for _, st := range steps {
+		got := s.detectFlapping(config, state, st.transitioned, st.minute*minute)
+		if got != st.want {
+			t.Fatalf("第 %d 分钟 detectFlapping() = %v, want %v", st.minute, got, st.want)
+		}
+		switch got {
+		case flapStart:
+			state.FlapRecordID = 1
+		case flapEnd:
+			state.FlapRecordID = 0
+		}
+	}
+	if len(state.Transitions) != 0 {
+		t.Errorf("抖动结束后窗口内不应有状态变化: %v", state.Transitions)
+	}
+
+	t.Run("窗口外的状态变化不计入", func(t *testing.T) {
+		state := &models.AlertState{}
+		for _, m := range []int64{0, 11, 22} {
+			if got := s.detectFlapping(config, state, true, m*minute); got != flapNone {
+				t.Errorf("第 %d 分钟 detectFlapping() = %v, want flapNone", m, got)
+			}
+		}
+	})
+
+	t.Run("关闭抖动检测时结束进行中的抖动", func(t *testing.T) {
+		disabled := &models.AlertConfig{}
+		state := &models.AlertState{FlapRecordID: 1, Transitions: []int64{1, 2, 3}}
+		if got := s.detectFlapping(disabled, state, true, 4); got != flapEnd {
+			t.Errorf("detectFlapping() = %v, want flapEnd", got)
+		}
+		if state.Transitions != nil {
+			t.Errorf("关闭后应清空状态变化记录")
+		}
+	})
+}
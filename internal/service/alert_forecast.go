@@ -0,0 +1,302 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/utils"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDiskForecastHours    = 24
+	defaultDiskForecastLookback = 6
+	// forecastMinPoints 线性回归至少需要的数据点数量
+	forecastMinPoints = 10
+	// trafficForecastMinElapsed 计费周期开始后至少经过多久才预测流量（毫秒）
+	trafficForecastMinElapsed = int64(24 * time.Hour / time.Millisecond)
+)
+
+// CheckForecastAlerts 检查预测类告警（磁盘写满预测和流量超额预测）
+func (s *AlertService) CheckForecastAlerts(ctx context.Context) error {
+	config, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !config.Enabled {
+		return nil
+	}
+
+	agents, err := s.agentRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	resolver, err := s.newAlertRuleResolver(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	// 按回看时长分组，同一时长只查询一次 VictoriaMetrics
+	diskSeries := make(map[int]map[string]map[string][]vmclient.DataPoint)
+	for i := range agents {
+		agent := &agents[i]
+		rules := resolver.resolve(agent)
+
+		if rules.Rules.TrafficForecastEnabled {
+			s.checkTrafficForecast(ctx, config, agent, rules, now)
+		}
+
+		if !rules.Rules.DiskForecastEnabled || s.vmClient == nil {
+			continue
+		}
+		lookback := rules.Rules.DiskForecastLookback
+		if lookback <= 0 {
+			lookback = defaultDiskForecastLookback
+		}
+		series, ok := diskSeries[lookback]
+		if !ok {
+			series, err = s.queryDiskFreeSeries(ctx, lookback, now)
+			if err != nil {
+				s.logger.Error("查询磁盘剩余空间失败", zap.Int("lookback", lookback), zap.Error(err))
+			}
+			// 查询失败时同样缓存，避免同一轮内重复请求
+			diskSeries[lookback] = series
+		}
+		for mountPoint, points := range series[agent.ID] {
+			s.checkDiskForecast(ctx, config, agent, rules, mountPoint, points, lookback, now)
+		}
+	}
+	return nil
+}
+
+// queryDiskFreeSeries 查询回看时长内各探针各挂载点的剩余空间，按 探针ID -> 挂载点 分组
+func (s *AlertService) queryDiskFreeSeries(ctx context.Context, lookback int, now int64) (map[string]map[string][]vmclient.DataPoint, error) {
+	end := time.UnixMilli(now)
+	start := end.Add(-time.Duration(lookback) * time.Hour)
+	// 每个序列约 120 个点，足够回归且不会给 VictoriaMetrics 带来压力
+	step := max(time.Duration(lookback)*time.Hour/120, time.Minute)
+
+	result, err := s.vmClient.QueryRange(ctx, "pika_disk_free_bytes", start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[string]map[string][]vmclient.DataPoint)
+	for _, point := range vmclient.ConvertToDataPoints(result) {
+		agentID, mountPoint := point.Labels["agent_id"], point.Labels["mount_point"]
+		if agentID == "" || mountPoint == "" {
+			continue
+		}
+		if series[agentID] == nil {
+			series[agentID] = make(map[string][]vmclient.DataPoint)
+		}
+		series[agentID][mountPoint] = append(series[agentID][mountPoint], point)
+	}
+	return series, nil
+}
+
+// linearRegression 最小二乘法拟合数据点，返回斜率（每毫秒的变化量）
+func linearRegression(points []vmclient.DataPoint) float64 {
+	n := float64(len(points))
+	if n < 2 {
+		return 0
+	}
+
+	// 以第一个点为时间原点，避免毫秒时间戳平方后丢失精度
+	origin := points[0].Timestamp
+	var sumX, sumY, sumXY, sumXX float64
+	for _, point := range points {
+		x := float64(point.Timestamp - origin)
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// forecastHoursToFull 按剩余空间和斜率（每毫秒变化量）计算预计写满的小时数，剩余空间不在减少时返回 -1
+func forecastHoursToFull(free, slope float64) float64 {
+	if slope >= 0 {
+		return -1
+	}
+	return free / -slope / float64(time.Hour/time.Millisecond)
+}
+
+// checkDiskForecast 按剩余空间的线性趋势预测挂载点写满时间，预计在指定小时内写满时告警
+func (s *AlertService) checkDiskForecast(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, mountPoint string, points []vmclient.DataPoint, lookback int, now int64) {
+	slices.SortFunc(points, func(a, b vmclient.DataPoint) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	// 数据不足（如新挂载的磁盘）时不预测
+	if len(points) < forecastMinPoints ||
+		points[len(points)-1].Timestamp-points[0].Timestamp < int64(lookback)*int64(time.Hour/time.Millisecond)/4 {
+		return
+	}
+
+	hours := rules.Rules.DiskForecastHours
+	if hours <= 0 {
+		hours = defaultDiskForecastHours
+	}
+
+	free := points[len(points)-1].Value
+	slope := linearRegression(points)
+	hoursToFull := forecastHoursToFull(free, slope)
+	predicting := hoursToFull >= 0 && hoursToFull <= float64(hours)
+
+	stateKey := fmt.Sprintf("%s:global:disk_forecast:%s", agent.ID, mountPoint)
+	state, err := s.AlertStateRepo.GetAlertState(ctx, stateKey)
+	if err != nil {
+		state = &models.AlertState{
+			ID:        stateKey,
+			AgentID:   agent.ID,
+			AlertType: "disk_forecast",
+		}
+	}
+	state.Threshold = float64(hours)
+	state.Value = max(hoursToFull, 0)
+	state.LastCheckTime = now
+
+	shouldFire := predicting && !state.IsFiring
+	shouldResolve := !predicting && state.IsFiring
+	if shouldFire {
+		state.IsFiring = true
+		state.StartTime = now
+	}
+
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+		return
+	}
+
+	if shouldFire {
+		level := "warning"
+		if hoursToFull <= float64(hours)/2 {
+			level = "critical"
+		}
+		message := fmt.Sprintf("挂载点 %s 按近%d小时趋势预计%s后写满（剩余%s，每小时增长%s）",
+			mountPoint, lookback,
+			utils.FormatDuration(int64(hoursToFull*float64(time.Hour/time.Millisecond))),
+			formatBytes(uint64(free)),
+			formatBytes(uint64(-slope*float64(time.Hour/time.Millisecond))))
+		s.fireForecastAlert(ctx, agent, rules, state, mountPoint, message, level, now)
+	}
+
+	if shouldResolve {
+		s.resolveAlert(ctx, config, agent, state)
+	}
+}
+
+// checkTrafficForecast 按当前计费周期的平均使用速度预测流量，重置前将超出限额时告警
+func (s *AlertService) checkTrafficForecast(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, now int64) {
+	stats := agent.TrafficStats.Data()
+	if !stats.Enabled || stats.Limit == 0 || stats.ResetDay == 0 || stats.PeriodStart == 0 {
+		return
+	}
+	// 已超出限额由流量告警通知，保持预测告警状态直到周期重置
+	if stats.Used >= stats.Limit {
+		return
+	}
+
+	nextReset := calculateNextResetDate(time.UnixMilli(stats.PeriodStart), stats.ResetDay).UnixMilli()
+	elapsed := now - stats.PeriodStart
+
+	var projected, rate float64
+	predicting := false
+	if elapsed >= trafficForecastMinElapsed && nextReset > now {
+		rate = float64(stats.Used) / float64(elapsed)
+		projected = float64(stats.Used) + rate*float64(nextReset-now)
+		predicting = projected > float64(stats.Limit)
+	}
+
+	stateKey := fmt.Sprintf("%s:global:traffic_forecast", agent.ID)
+	state, err := s.AlertStateRepo.GetAlertState(ctx, stateKey)
+	if err != nil {
+		state = &models.AlertState{
+			ID:        stateKey,
+			AgentID:   agent.ID,
+			AlertType: "traffic_forecast",
+		}
+	}
+	state.Threshold = 100
+	state.Value = projected / float64(stats.Limit) * 100
+	state.LastCheckTime = now
+
+	shouldFire := predicting && !state.IsFiring
+	shouldResolve := !predicting && state.IsFiring
+	if shouldFire {
+		state.IsFiring = true
+		state.StartTime = now
+	}
+
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+		return
+	}
+
+	if shouldFire {
+		exceedAt := stats.PeriodStart + int64(float64(stats.Limit)/rate)
+		message := fmt.Sprintf("按当前周期使用趋势预计 %s 超出流量限额，重置日 %s 前预计使用%s（限额%s，已使用%s）",
+			time.UnixMilli(exceedAt).Format("2006-01-02 15:04"),
+			time.UnixMilli(nextReset).Format("2006-01-02"),
+			formatBytes(uint64(projected)),
+			formatBytes(stats.Limit),
+			formatBytes(stats.Used))
+		s.fireForecastAlert(ctx, agent, rules, state, "", message, "warning", now)
+	}
+
+	if shouldResolve {
+		s.resolveAlert(ctx, config, agent, state)
+	}
+}
+
+// fireForecastAlert 触发预测告警
+func (s *AlertService) fireForecastAlert(ctx context.Context, agent *models.Agent, rules *EffectiveAlertRules, state *models.AlertState, device, message, level string, now int64) {
+	s.logger.Info("触发预测告警",
+		zap.String("agentId", agent.ID),
+		zap.String("alertType", state.AlertType),
+		zap.String("device", device),
+		zap.Float64("value", state.Value),
+	)
+
+	record := &models.AlertRecord{
+		AgentID:     agent.ID,
+		AgentName:   agent.Name,
+		AlertType:   state.AlertType,
+		Message:     message,
+		Threshold:   state.Threshold,
+		ActualValue: state.Value,
+		Level:       level,
+		Status:      "firing",
+		FiredAt:     now,
+		Device:      device,
+		StateID:     state.ID,
+		CreatedAt:   now,
+	}
+	rules.applyTo(record)
+
+	if err := s.AlertRecordRepo.CreateAlertRecord(ctx, record); err != nil {
+		s.logger.Error("创建预测告警记录失败", zap.Error(err))
+		return
+	}
+
+	state.LastRecordID = record.ID
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+		return
+	}
+
+	go s.sendAlertNotification(record, agent)
+}
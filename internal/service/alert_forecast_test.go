@@ -0,0 +1,73 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/vmclient"
+)
+
+func TestLinearRegression(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	hour := time.Hour.Milliseconds()
+	gb := float64(1 << 30)
+
+	// series 生成每小时一个点的序列，offsets 为各点相对直线的偏差
+	series := func(start, perHour float64, offsets ...float64) []vmclient.DataPoint {
+		points := make([]vmclient.DataPoint, len(offsets))
+		for i, offset := range offsets {
+			points[i] = vmclient.DataPoint{
+				Timestamp: base + int64(i)*hour,
+				Value:     start + perHour*float64(i) + offset,
+			}
+		}
+		return points
+	}
+
+	tests := []struct {
+		name    string
+		points  []vmclient.DataPoint
+		perHour float64
+	}{
+		{"每小时减少 1GB", series(100*gb, -gb, 0, 0, 0, 0, 0, 0), -gb},
+		{"剩余空间不变", series(50*gb, 0, 0, 0, 0, 0), 0},
+		{"剩余空间增加", series(10*gb, 2*gb, 0, 0, 0), 2 * gb},
+		// 偏差关于中点对称，不影响拟合斜率
+		{"有波动的序列", series(100*gb, -gb, 0.5*gb, -0.5*gb, 0, 0, -0.5*gb, 0.5*gb), -gb},
+		{"单个点无法拟合", series(100*gb, -gb, 0), 0},
+		{"时间戳相同无法拟合", []vmclient.DataPoint{{Timestamp: base, Value: 1}, {Timestamp: base, Value: 2}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := linearRegression(tt.points) * float64(hour)
+			if math.Abs(got-tt.perHour) > 1 {
+				t.Errorf("linearRegression() 每小时变化 = %.0f, want %.0f", got, tt.perHour)
+			}
+		})
+	}
+}
+
+func TestForecastHoursToFull(t *testing.T) {
+	perHour := func(v float64) float64 {
+		return v / float64(time.Hour.Milliseconds())
+	}
+	tests := []struct {
+		name  string
+		free  float64
+		slope float64
+		want  float64
+	}{
+		{"每小时减少 2GB 剩余 48GB", 48, perHour(-2), 24},
+		{"剩余空间不变", 48, 0, -1},
+		{"剩余空间增加", 48, perHour(1), -1},
+		{"已写满", 0, perHour(-1), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forecastHoursToFull(tt.free, tt.slope); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("forecastHoursToFull() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// alertInhibition 告警被抑制的原因
+type alertInhibition struct {
+	SourceID int64  // 引起抑制的告警记录ID
+	Reason   string // 抑制原因
+}
+
+// applyInhibition 判断告警是否应被抑制，被抑制时在告警记录中标记；已抑制触发的告警，其恢复通知同样抑制
+func (s *AlertService) applyInhibition(ctx context.Context, record *models.AlertRecord, agent *models.Agent) bool {
+	if record.Inhibited {
+		return true
+	}
+	// 只在触发时判断，未被抑制的告警正常发送恢复通知
+	if record.Status != "firing" {
+		return false
+	}
+
+	inhibition, err := s.findInhibition(ctx, record, agent)
+	if err != nil {
+		// 查询失败时按未抑制处理，避免漏发通知
+		s.logger.Error("匹配告警抑制失败", zap.Int64("recordId", record.ID), zap.Error(err))
+		return false
+	}
+	if inhibition == nil {
+		return false
+	}
+
+	record.Inhibited = true
+	record.InhibitedBy = inhibition.SourceID
+	record.InhibitReason = inhibition.Reason
+	record.NotificationStatus = "inhibited"
+	if record.ID > 0 {
+		if err := s.AlertRecordRepo.MarkInhibited(ctx, record.ID, inhibition.SourceID, inhibition.Reason); err != nil {
+			s.logger.Error("标记告警抑制失败", zap.Int64("recordId", record.ID), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("告警已抑制，不发送通知",
+		zap.Int64("recordId", record.ID),
+		zap.String("agentId", record.AgentID),
+		zap.String("alertType", record.AlertType),
+		zap.Int64("inhibitedBy", inhibition.SourceID),
+		zap.String("reason", inhibition.Reason))
+	return true
+}
+
+// findInhibition 依次匹配监控项依赖和抑制规则，未匹配时返回 nil
+func (s *AlertService) findInhibition(ctx context.Context, record *models.AlertRecord, agent *models.Agent) (*alertInhibition, error) {
+	inhibition, err := s.findParentMonitorInhibition(ctx, record)
+	if err != nil || inhibition != nil {
+		return inhibition, err
+	}
+
+	if record.AgentID == "" {
+		return nil, nil
+	}
+
+	config, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range config.InhibitRules {
+		if !rule.Enabled || len(rule.SourceTypes) == 0 || !slices.Contains(rule.TargetTypes, record.AlertType) {
+			continue
+		}
+
+		source, err := s.AlertRecordRepo.FindFiringByAgentAndTypes(ctx, record.AgentID, rule.SourceTypes, record.ID)
+		if err != nil {
+			return nil, err
+		}
+		if source != nil {
+			return &alertInhibition{SourceID: source.ID, Reason: rule.Name}, nil
+		}
+
+		// 探针离线告警需要持续一段时间才触发，此前探针已离线时同样抑制
+		if slices.Contains(rule.SourceTypes, "agent_offline") &&
+			agent != nil && agent.ID == record.AgentID && agent.Status == 0 && agent.LastSeenAt > 0 {
+			return &alertInhibition{Reason: rule.Name}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findParentMonitorInhibition 父监控项服务下线时，抑制子监控项的服务下线和证书告警
+func (s *AlertService) findParentMonitorInhibition(ctx context.Context, record *models.AlertRecord) (*alertInhibition, error) {
+	if record.MonitorID == "" || (record.AlertType != "service" && record.AlertType != "cert") {
+		return nil, nil
+	}
+
+	monitor, err := s.monitorService.MonitorRepo.FindById(ctx, record.MonitorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if monitor.ParentID == "" {
+		return nil, nil
+	}
+
+	parent, err := s.monitorService.MonitorRepo.FindById(ctx, monitor.ParentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	reason := fmt.Sprintf("父监控项 %s 下线", parent.Name)
+
+	source, err := s.AlertRecordRepo.FindFiringByMonitor(ctx, parent.ID, "service")
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		return &alertInhibition{SourceID: source.ID, Reason: reason}, nil
+	}
+
+	// 父子监控项在同一轮检查中下线时父监控项的告警记录可能尚未创建，按父监控项的当前状态判断
+	if parent.Enabled && s.monitorService.IsMonitorDown(ctx, parent.ID) {
+		return &alertInhibition{Reason: reason}, nil
+	}
+	return nil, nil
+}
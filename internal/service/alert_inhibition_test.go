@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+func TestFindInhibition(t *testing.T) {
+	rule := models.AlertInhibitRule{
+		Name:        "探针离线抑制",
+		Enabled:     true,
+		SourceTypes: []string{"agent_offline"},
+		TargetTypes: []string{"cpu", "service"},
+	}
+	disabled := rule
+	disabled.Enabled = false
+
+	online := &models.Agent{ID: "a1", Status: 1, LastSeenAt: 1000}
+	offline := &models.Agent{ID: "a1", Status: 0, LastSeenAt: 1000}
+	neverSeen := &models.Agent{ID: "a1", Status: 0}
+
+	tests := []struct {
+		name       string
+		rules      []models.AlertInhibitRule
+		source     *models.AlertRecord
+		record     models.AlertRecord
+		agent      *models.Agent
+		inhibited  bool
+		fromSource bool
+	}{
+		{
+			name:       "源告警触发中时抑制",
+			rules:      []models.AlertInhibitRule{rule},
+			source:     &models.AlertRecord{AgentID: "a1", AlertType: "agent_offline", Status: "firing"},
+			record:     models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing"},
+			agent:      online,
+			inhibited:  true,
+			fromSource: true,
+		},
+		{
+			name:   "源告警已恢复时不抑制",
+			rules:  []models.AlertInhibitRule{rule},
+			source: &models.AlertRecord{AgentID: "a1", AlertType: "agent_offline", Status: "resolved"},
+			record: models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing"},
+			agent:  online,
+		},
+		{
+			name:   "其他探针的源告警不抑制",
+			rules:  []models.AlertInhibitRule{rule},
+			source: &models.AlertRecord{AgentID: "a2", AlertType: "agent_offline", Status: "firing"},
+			record: models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing"},
+			agent:  online,
+		},
+		{
+			name:   "告警类型不在目标类型中",
+			rules:  []models.AlertInhibitRule{rule},
+			source: &models.AlertRecord{AgentID: "a1", AlertType: "agent_offline", Status: "firing"},
+			record: models.AlertRecord{AgentID: "a1", AlertType: "memory", Status: "firing"},
+			agent:  online,
+		},
+		{
+			name:   "规则未启用",
+			rules:  []models.AlertInhibitRule{disabled},
+			source: &models.AlertRecord{AgentID: "a1", AlertType: "agent_offline", Status: "firing"},
+			record: models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing"},
+			agent:  online,
+		},
+		{
+			name:      "离线告警尚未触发但探针已离线",
+			rules:     []models.AlertInhibitRule{rule},
+			record:    models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing"},
+			agent:     offline,
+			inhibited: true,
+		},
+		{
+			name:   "探针从未上线不按离线处理",
+			rules:  []models.AlertInhibitRule{rule},
+			record: models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing"},
+			agent:  neverSeen,
+		},
+		{
+			name:   "探针在线且没有源告警",
+			rules:  []models.AlertInhibitRule{rule},
+			record: models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing"},
+			agent:  online,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestAlertService(t, models.AlertConfig{Enabled: true, InhibitRules: tt.rules})
+			ctx := context.Background()
+			if tt.source != nil {
+				if err := db.Create(tt.source).Error; err != nil {
+					t.Fatalf("写入源告警失败: %v", err)
+				}
+			}
+			if err := db.Create(&tt.record).Error; err != nil {
+				t.Fatalf("写入告警记录失败: %v", err)
+			}
+
+			inhibition, err := s.findInhibition(ctx, &tt.record, tt.agent)
+			if err != nil {
+				t.Fatalf("findInhibition() 失败: %v", err)
+			}
+			if (inhibition != nil) != tt.inhibited {
+				t.Fatalf("findInhibition() = %+v, want inhibited %v", inhibition, tt.inhibited)
+			}
+			if inhibition == nil {
+				return
+			}
+			if inhibition.Reason != rule.Name {
+				t.Errorf("抑制原因 = %q, want %q", inhibition.Reason, rule.Name)
+			}
+			if tt.fromSource && inhibition.SourceID != tt.source.ID {
+				t.Errorf("抑制来源 = %d, want %d", inhibition.SourceID, tt.source.ID)
+			}
+			if !tt.fromSource && inhibition.SourceID != 0 {
+				t.Errorf("按探针状态抑制时不应有来源告警，实际 %d", inhibition.SourceID)
+			}
+		})
+	}
+}
+
+func TestFindParentMonitorInhibition(t *testing.T) {
+	tests := []struct {
+		name       string
+		alertType  string
+		parentDown bool
+		source     bool
+		parentOff  bool
+		inhibited  bool
+	}{
+		{name: "父监控项告警中", alertType: "service", source: true, inhibited: true},
+		{name: "父监控项证书告警中同样抑制子监控项证书告警", alertType: "cert", source: true, inhibited: true},
+		{name: "父监控项告警尚未创建但当前已下线", alertType: "service", parentDown: true, inhibited: true},
+		{name: "父监控项已停用时不按当前状态判断", alertType: "service", parentDown: true, parentOff: true},
+		{name: "父监控项正常", alertType: "service"},
+		{name: "非服务类告警不按父监控项抑制", alertType: "cpu", source: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestAlertService(t, models.AlertConfig{Enabled: true})
+			ctx := context.Background()
+
+			monitors := []models.MonitorTask{
+				{ID: "parent", Name: "网关", Enabled: !tt.parentOff},
+				{ID: "child", Name: "网站", Enabled: true, ParentID: "parent"},
+			}
+			if err := db.Create(&monitors).Error; err != nil {
+				t.Fatalf("写入监控项失败: %v", err)
+			}
+			var source models.AlertRecord
+			if tt.source {
+				source = models.AlertRecord{MonitorID: "parent", AlertType: "service", Status: "firing"}
+				if err := db.Create(&source).Error; err != nil {
+					t.Fatalf("写入父监控项告警失败: %v", err)
+				}
+			}
+			status := "up"
+			if tt.parentDown {
+				status = "down"
+			}
+			s.monitorService.metricService.updateMonitorCache("a1", &protocol.MonitorData{
+				AgentId:   "a1",
+				MonitorId: "parent",
+				Status:    status,
+			}, time.Now().UnixMilli())
+
+			record := &models.AlertRecord{MonitorID: "child", AlertType: tt.alertType, Status: "firing"}
+			inhibition, err := s.findParentMonitorInhibition(ctx, record)
+			if err != nil {
+				t.Fatalf("findParentMonitorInhibition() 失败: %v", err)
+			}
+			if (inhibition != nil) != tt.inhibited {
+				t.Fatalf("findParentMonitorInhibition() = %+v, want inhibited %v", inhibition, tt.inhibited)
+			}
+			if inhibition == nil {
+				return
+			}
+			if inhibition.Reason != "父监控项 网关 下线" {
+				t.Errorf("抑制原因 = %q", inhibition.Reason)
+			}
+			if inhibition.SourceID != source.ID {
+				t.Errorf("抑制来源 = %d, want %d", inhibition.SourceID, source.ID)
+			}
+		})
+	}
+}
+
+func TestApplyInhibitionResolved(t *testing.T) {
+	s := &AlertService{}
+	tests := []struct {
+		name   string
+		record models.AlertRecord
+		want   bool
+	}{
+		{"触发时已抑制的告警恢复时同样抑制", models.AlertRecord{Status: "resolved", Inhibited: true}, true},
+		{"触发时已通知的告警恢复时不重新判断", models.AlertRecord{Status: "resolved"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.applyInhibition(context.Background(), &tt.record, nil); got != tt.want {
+				t.Errorf("applyInhibition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
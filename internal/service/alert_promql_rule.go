@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"github.com/valyala/fasttemplate"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AlertPromQLRuleRequest PromQL 告警规则请求
+type AlertPromQLRuleRequest struct {
+	Name        string            `json:"name" validate:"required"`
+	Description string            `json:"description"`
+	Enabled     bool              `json:"enabled"`
+	Expr        string            `json:"expr" validate:"required"`
+	For         int               `json:"for"`
+	Severity    string            `json:"severity"`
+	Labels      map[string]string `json:"labels"`
+	Summary     string            `json:"summary"`
+}
+
+// PromQLSeries PromQL 表达式返回的序列
+type PromQLSeries struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+func validateAlertPromQLRuleRequest(req *AlertPromQLRuleRequest) error {
+	req.Expr = strings.TrimSpace(req.Expr)
+	if req.For < 0 {
+		return orz.NewError(400, "持续时间不能小于0")
+	}
+	switch req.Severity {
+	case "":
+		req.Severity = "warning"
+	case "info", "warning", "critical":
+	default:
+		return orz.NewError(400, "告警级别只能是 info、warning 或 critical")
+	}
+	return nil
+}
+
+// ListPromQLRules 查询所有 PromQL 告警规则
+func (s *AlertService) ListPromQLRules(ctx context.Context) ([]models.AlertPromQLRule, error) {
+	var rules []models.AlertPromQLRule
+	err := s.AlertPromQLRuleRepo.GetDB(ctx).Order("created_at asc").Find(&rules).Error
+	return rules, err
+}
+
+// GetPromQLRule 获取 PromQL 告警规则
+func (s *AlertService) GetPromQLRule(ctx context.Context, id string) (*models.AlertPromQLRule, error) {
+	rule, err := s.AlertPromQLRuleRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "PromQL告警规则不存在")
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreatePromQLRule 创建 PromQL 告警规则
+func (s *AlertService) CreatePromQLRule(ctx context.Context, req *AlertPromQLRuleRequest) (*models.AlertPromQLRule, error) {
+	if err := validateAlertPromQLRuleRequest(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.PreviewPromQL(ctx, req.Expr); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	rule := &models.AlertPromQLRule{
+		ID:          uuid.NewString(),
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Expr:        req.Expr,
+		For:         req.For,
+		Severity:    req.Severity,
+		Labels:      datatypes.NewJSONType(req.Labels),
+		Summary:     req.Summary,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.AlertPromQLRuleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdatePromQLRule 更新 PromQL 告警规则
+func (s *AlertService) UpdatePromQLRule(ctx context.Context, id string, req *AlertPromQLRuleRequest) (*models.AlertPromQLRule, error) {
+	if err := validateAlertPromQLRuleRequest(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.PreviewPromQL(ctx, req.Expr); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.GetPromQLRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Name = req.Name
+	rule.Description = req.Description
+	rule.Enabled = req.Enabled
+	rule.Expr = req.Expr
+	rule.For = req.For
+	rule.Severity = req.Severity
+	rule.Labels = datatypes.NewJSONType(req.Labels)
+	rule.Summary = req.Summary
+	rule.UpdatedAt = time.Now().UnixMilli()
+	if err := s.AlertPromQLRuleRepo.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	// 停用后不再评估，结束该规则正在进行的告警
+	if !rule.Enabled {
+		if err := s.closePromQLRuleStates(ctx, rule.ID); err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
+
+// DeletePromQLRule 删除 PromQL 告警规则
+func (s *AlertService) DeletePromQLRule(ctx context.Context, id string) error {
+	if err := s.closePromQLRuleStates(ctx, id); err != nil {
+		return err
+	}
+	return s.AlertPromQLRuleRepo.DeleteById(ctx, id)
+}
+
+// closePromQLRuleStates 按正常恢复流程结束规则正在进行的告警（发送恢复通知）并清理状态
+func (s *AlertService) closePromQLRuleStates(ctx context.Context, ruleID string) error {
+	prefix := promQLStatePrefix(ruleID)
+	states, err := s.AlertStateRepo.FindByIDPrefix(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	var config *models.AlertConfig
+	agents := make(map[string]*models.Agent)
+	for i := range states {
+		state := &states[i]
+		if !state.IsFiring {
+			continue
+		}
+		if config == nil {
+			if config, err = s.propertyService.GetAlertConfig(ctx); err != nil {
+				return err
+			}
+		}
+		s.resolveAlert(ctx, config, s.lookupAgent(ctx, agents, state.AgentID), state)
+	}
+	return s.AlertStateRepo.DeleteByIDPrefix(ctx, prefix)
+}
+
+// PreviewPromQL 执行 PromQL 表达式，返回当前会触发告警的序列
+func (s *AlertService) PreviewPromQL(ctx context.Context, expr string) ([]PromQLSeries, error) {
+	result, err := s.vmClient.Query(ctx, expr)
+	if err != nil {
+		return nil, orz.NewError(400, fmt.Sprintf("PromQL 查询失败: %v", err))
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, orz.NewError(400, fmt.Sprintf("PromQL 表达式需返回 vector 类型，当前为 %s", result.Data.ResultType))
+	}
+
+	points := vmclient.ConvertVectorToDataPoints(result)
+	series := make([]PromQLSeries, 0, len(points))
+	for _, point := range points {
+		if math.IsNaN(point.Value) {
+			continue
+		}
+		series = append(series, PromQLSeries{Labels: point.Labels, Value: point.Value})
+	}
+	return series, nil
+}
+
+// EvaluatePromQLRules 评估所有启用的 PromQL 告警规则
+func (s *AlertService) EvaluatePromQLRules(ctx context.Context) error {
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		s.logger.Error("获取全局告警配置失败", zap.Error(err))
+		return err
+	}
+	if !alertConfig.Enabled {
+		return nil
+	}
+
+	rules, err := s.AlertPromQLRuleRepo.FindEnabled(ctx)
+	if err != nil {
+		return err
+	}
+
+	agents := make(map[string]*models.Agent)
+	now := time.Now().UnixMilli()
+	for i := range rules {
+		if err := s.evaluatePromQLRule(ctx, alertConfig, &rules[i], agents, now); err != nil {
+			s.logger.Warn("评估PromQL告警规则失败",
+				zap.String("ruleId", rules[i].ID),
+				zap.String("ruleName", rules[i].Name),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// evaluatePromQLRule 评估单条规则：返回的每个序列独立维护告警状态，序列消失即视为恢复
+func (s *AlertService) evaluatePromQLRule(ctx context.Context, config *models.AlertConfig, rule *models.AlertPromQLRule, agents map[string]*models.Agent, now int64) error {
+	// 查询失败时保持现有状态，避免 VictoriaMetrics 不可用时误恢复
+	series, err := s.PreviewPromQL(ctx, rule.Expr)
+	if err != nil {
+		return err
+	}
+
+	prefix := promQLStatePrefix(rule.ID)
+	states, err := s.AlertStateRepo.FindByIDPrefix(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*models.AlertState, len(states))
+	for i := range states {
+		existing[states[i].ID] = &states[i]
+	}
+
+	seen := make(map[string]bool, len(series))
+	for _, item := range series {
+		stateKey := prefix + promQLFingerprint(item.Labels)
+		if seen[stateKey] {
+			continue
+		}
+		seen[stateKey] = true
+
+		state, ok := existing[stateKey]
+		if !ok {
+			state = &models.AlertState{
+				ID:        stateKey,
+				AgentID:   item.Labels["agent_id"],
+				AlertType: "promql",
+				StartTime: now,
+			}
+		}
+		state.Value = item.Value
+		state.Duration = rule.For
+		state.LastCheckTime = now
+
+		shouldFire := false
+		if !state.IsFiring && (now-state.StartTime)/1000 >= int64(rule.For) {
+			shouldFire = true
+			state.IsFiring = true
+		}
+
+		if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+			s.logger.Error("保存告警状态失败", zap.Error(err))
+			continue
+		}
+
+		if shouldFire {
+			s.firePromQLAlert(ctx, rule, s.lookupAgent(ctx, agents, state.AgentID), state, item.Labels, now)
+		}
+	}
+
+	for id, state := range existing {
+		if seen[id] {
+			continue
+		}
+		if state.IsFiring {
+			s.resolveAlert(ctx, config, s.lookupAgent(ctx, agents, state.AgentID), state)
+		}
+		if err := s.AlertStateRepo.DeleteAlertState(ctx, id); err != nil {
+			s.logger.Error("删除告警状态失败", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// firePromQLAlert 触发 PromQL 规则告警
+func (s *AlertService) firePromQLAlert(ctx context.Context, rule *models.AlertPromQLRule, agent *models.Agent, state *models.AlertState, seriesLabels map[string]string, now int64) {
+	// 规则附加的标签覆盖序列同名标签
+	labels := maps.Clone(seriesLabels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	maps.Copy(labels, rule.Labels.Data())
+
+	s.logger.Info("触发PromQL告警",
+		zap.String("ruleId", rule.ID),
+		zap.String("ruleName", rule.Name),
+		zap.String("agentId", agent.ID),
+		zap.Any("labels", labels),
+		zap.Float64("value", state.Value),
+	)
+
+	record := &models.AlertRecord{
+		AgentID:     agent.ID,
+		AgentName:   agent.Name,
+		AlertType:   "promql",
+		Message:     renderPromQLSummary(rule, labels, state.Value),
+		ActualValue: state.Value,
+		Level:       rule.Severity,
+		Status:      "firing",
+		FiredAt:     now,
+		RuleSource:  models.AlertRuleSourcePromQL,
+		RuleSetID:   rule.ID,
+		RuleSetName: rule.Name,
+		Labels:      datatypes.NewJSONType(labels),
+		StateID:     state.ID,
+		CreatedAt:   now,
+	}
+
+	if err := s.AlertRecordRepo.CreateAlertRecord(ctx, record); err != nil {
+		s.logger.Error("创建PromQL告警记录失败", zap.Error(err))
+		return
+	}
+
+	state.LastRecordID = record.ID
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+		return
+	}
+
+	go s.sendAlertNotification(record, agent)
+}
+
+// lookupAgent 获取告警所属探针，没有探针（如 PromQL 序列不带 agent_id 标签）或探针不存在时使用占位探针
+func (s *AlertService) lookupAgent(ctx context.Context, agents map[string]*models.Agent, agentID string) *models.Agent {
+	if agent, ok := agents[agentID]; ok {
+		return agent
+	}
+
+	agent := &models.Agent{ID: agentID, Name: "-", Hostname: "-"}
+	if agentID != "" {
+		if found, err := s.agentRepo.FindById(ctx, agentID); err == nil {
+			agent = &found
+		}
+	}
+	agents[agentID] = agent
+	return agent
+}
+
+func promQLStatePrefix(ruleID string) string {
+	return fmt.Sprintf("promql:%s:", ruleID)
+}
+
+// promQLFingerprint 根据标签计算序列指纹
+func promQLFingerprint(labels map[string]string) string {
+	keys := slices.Sorted(maps.Keys(labels))
+	h := sha1.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0xff})
+		h.Write([]byte(labels[key]))
+		h.Write([]byte{0xff})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// formatPromQLLabels 格式化标签，如 {agent_id="xxx", mountpoint="/"}
+func formatPromQLLabels(labels map[string]string) string {
+	keys := slices.Sorted(maps.Keys(labels))
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "__name__" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", key, labels[key]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// renderPromQLSummary 渲染告警消息，未配置模板时使用默认格式
+func renderPromQLSummary(rule *models.AlertPromQLRule, labels map[string]string, value float64) string {
+	valueStr := strconv.FormatFloat(value, 'f', 2, 64)
+	if strings.TrimSpace(rule.Summary) == "" {
+		return fmt.Sprintf("规则 %s 触发：%s 当前值%s", rule.Name, formatPromQLLabels(labels), valueStr)
+	}
+
+	t := fasttemplate.New(rule.Summary, "{{", "}}")
+	return t.ExecuteFuncString(func(w io.Writer, tag string) (int, error) {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case tag == "value":
+			return w.Write([]byte(valueStr))
+		case tag == "labels":
+			return w.Write([]byte(formatPromQLLabels(labels)))
+		case tag == "rule.name":
+			return w.Write([]byte(rule.Name))
+		case strings.HasPrefix(tag, "labels."):
+			return w.Write([]byte(labels[strings.TrimPrefix(tag, "labels.")]))
+		default:
+			return w.Write([]byte("{{" + tag + "}}"))
+		}
+	})
+}
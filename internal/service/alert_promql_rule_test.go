@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestAlertService 创建使用内存数据库的告警服务，通知队列不启动，发送的通知留在队列中供检查
+func newTestAlertService(t *testing.T, config models.AlertConfig) (*AlertService, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	// 内存数据库每个连接相互独立，通知在其他 goroutine 中处理，需共用同一个连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(
+		&models.Property{},
+		&models.Agent{},
+		&models.MonitorTask{},
+		&models.AlertRecord{},
+		&models.AlertState{},
+		&models.AlertRuleSet{},
+		&models.AlertPromQLRule{},
+		&models.AlertSilence{},
+	); err != nil {
+		t.Fatalf("创建测试表失败: %v", err)
+	}
+
+	value, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("序列化告警配置失败: %v", err)
+	}
+	if err := db.Create(&models.Property{ID: PropertyIDAlertConfig, Value: string(value)}).Error; err != nil {
+		t.Fatalf("写入告警配置失败: %v", err)
+	}
+
+	logger := zap.NewNop()
+	propertyService := NewPropertyService(logger, db)
+	metricService := NewMetricService(logger, db, propertyService, nil, nil)
+	s := &AlertService{
+		AlertRecordRepo:     repo.NewAlertRecordRepo(db),
+		AlertStateRepo:      repo.NewAlertStateRepo(db),
+		AlertRuleSetRepo:    repo.NewAlertRuleSetRepo(db),
+		AlertPromQLRuleRepo: repo.NewAlertPromQLRuleRepo(db),
+		agentRepo:           repo.NewAgentRepo(db),
+		monitorService:      NewMonitorService(logger, db, metricService, nil),
+		propertyService:     propertyService,
+		notifier:            NewNotifier(logger),
+		silenceService:      NewAlertSilenceService(logger, db),
+		logger:              logger,
+	}
+	s.notificationQueue = NewNotificationQueue(db, s, s.AlertRecordRepo, logger)
+	return s, db
+}
+
+// waitNotification 等待进入通知队列的告警记录，超时返回 0
+func waitNotification(s *AlertService, timeout time.Duration) int64 {
+	select {
+	case task := <-s.notificationQueue.taskChan:
+		return task.RecordID
+	case <-time.After(timeout):
+		return 0
+	}
+}
+
+func TestDeletePromQLRuleResolvesFiringAlerts(t *testing.T) {
+	tests := []struct {
+		name     string
+		silenced bool
+		notify   bool
+	}{
+		{"已通知的告警发送恢复通知", false, true},
+		{"触发时已静默的告警恢复时同样静默", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestAlertService(t, models.AlertConfig{Enabled: true})
+			ctx := context.Background()
+
+			rule := &models.AlertPromQLRule{ID: "rule-1", Name: "磁盘", Enabled: true, Expr: "up == 0"}
+			if err := db.Create(rule).Error; err != nil {
+				t.Fatalf("写入规则失败: %v", err)
+			}
+			record := &models.AlertRecord{AgentID: "agent-1", AlertType: "promql", Status: "firing", Silenced: tt.silenced, FiredAt: 1000}
+			if err := db.Create(record).Error; err != nil {
+				t.Fatalf("写入告警记录失败: %v", err)
+			}
+			stateID := promQLStatePrefix(rule.ID) + "abc"
+			states := []models.AlertState{
+				{ID: stateID, AgentID: "agent-1", AlertType: "promql", IsFiring: true, LastRecordID: record.ID, Value: 3},
+				{ID: promQLStatePrefix(rule.ID) + "pending", AlertType: "promql"},
+			}
+			if err := db.Create(&states).Error; err != nil {
+				t.Fatalf("写入告警状态失败: %v", err)
+			}
+
+			if err := s.DeletePromQLRule(ctx, rule.ID); err != nil {
+				t.Fatalf("DeletePromQLRule() 失败: %v", err)
+			}
+
+			resolved, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, record.ID)
+			if err != nil {
+				t.Fatalf("查询告警记录失败: %v", err)
+			}
+			if resolved.Status != "resolved" || resolved.ResolvedValue != 3 || resolved.ResolvedAt == 0 {
+				t.Errorf("删除规则后告警应恢复: %+v", resolved)
+			}
+			left, _ := s.AlertStateRepo.FindByIDPrefix(ctx, promQLStatePrefix(rule.ID))
+			if len(left) != 0 {
+				t.Errorf("删除规则后应清理告警状态，剩余 %d 个", len(left))
+			}
+
+			timeout := time.Second
+			if !tt.notify {
+				timeout = 200 * time.Millisecond
+			}
+			got := waitNotification(s, timeout)
+			if tt.notify && got != record.ID {
+				t.Errorf("应发送恢复通知，实际通知记录 %d", got)
+			}
+			if !tt.notify && got != 0 {
+				t.Errorf("静默的告警不应发送恢复通知，实际通知记录 %d", got)
+			}
+		})
+	}
+}
+
+func TestPromQLFingerprint(t *testing.T) {
+	a := promQLFingerprint(map[string]string{"agent_id": "a1", "mountpoint": "/", "__name__": "disk"})
+	b := promQLFingerprint(map[string]string{"__name__": "disk", "mountpoint": "/", "agent_id": "a1"})
+	if a != b {
+		t.Errorf("标签顺序不同时指纹应相同: %s != %s", a, b)
+	}
+	if len(a) != 16 {
+		t.Errorf("指纹长度 = %d, want 16", len(a))
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+	}{
+		{"标签值不同", map[string]string{"agent_id": "a1", "mountpoint": "/data", "__name__": "disk"}},
+		{"缺少标签", map[string]string{"agent_id": "a1", "mountpoint": "/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := promQLFingerprint(tt.labels); got == a {
+				t.Errorf("不同序列的指纹不应相同: %s", got)
+			}
+		})
+	}
+
+	// 键值之间有分隔符，拼接后相同的标签指纹不同
+	if promQLFingerprint(map[string]string{"a": "bc"}) == promQLFingerprint(map[string]string{"ab": "c"}) {
+		t.Errorf("键值边界不同的标签指纹不应相同")
+	}
+}
+
+func TestRenderPromQLSummary(t *testing.T) {
+	labels := map[string]string{"__name__": "disk_usage", "agent_id": "a1", "mountpoint": "/"}
+	tests := []struct {
+		name    string
+		summary string
+		want    string
+	}{
+		{"默认格式", "", `规则 磁盘空间 触发：{agent_id="a1", mountpoint="/"} 当前值91.50`},
+		{"值和规则名", "{{ rule.name }} 当前 {{value}}%", "磁盘空间 当前 91.50%"},
+		{"单个标签", "挂载点 {{labels.mountpoint}} 空间不足", "挂载点 / 空间不足"},
+		{"全部标签", "{{labels}}", `{agent_id="a1", mountpoint="/"}`},
+		{"不存在的标签为空", "[{{labels.device}}]", "[]"},
+		{"未知占位符原样保留", "{{agent.name}} {{value}}", "{{agent.name}} 91.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &models.AlertPromQLRule{Name: "磁盘空间", Summary: tt.summary}
+			if got := renderPromQLSummary(rule, labels, 91.5); got != tt.want {
+				t.Errorf("renderPromQLSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatePromQLRuleLifecycle(t *testing.T) {
+	// series 为 VictoriaMetrics 当前返回的序列，为空时表示序列消失
+	var series string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, series)
+	}))
+	defer server.Close()
+
+	s, db := newTestAlertService(t, models.AlertConfig{Enabled: true})
+	s.vmClient = vmclient.NewVMClient(server.URL, 0, 0)
+	ctx := context.Background()
+	config, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		t.Fatalf("获取告警配置失败: %v", err)
+	}
+
+	if err := db.Create(&models.Agent{ID: "a1", Name: "web-1"}).Error; err != nil {
+		t.Fatalf("写入探针失败: %v", err)
+	}
+	rule := &models.AlertPromQLRule{ID: "rule-1", Name: "磁盘空间", Enabled: true, Expr: "disk_usage > 90", For: 60, Severity: "critical"}
+	labels := map[string]string{"agent_id": "a1", "mountpoint": "/"}
+	stateID := promQLStatePrefix(rule.ID) + promQLFingerprint(labels)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	evaluate := func(now int64) {
+		t.Helper()
+		if err := s.evaluatePromQLRule(ctx, config, rule, map[string]*models.Agent{}, now); err != nil {
+			t.Fatalf("evaluatePromQLRule() 失败: %v", err)
+		}
+	}
+	loadState := func() *models.AlertState {
+		t.Helper()
+		states, err := s.AlertStateRepo.FindByIDPrefix(ctx, promQLStatePrefix(rule.ID))
+		if err != nil {
+			t.Fatalf("查询告警状态失败: %v", err)
+		}
+		if len(states) == 0 {
+			return nil
+		}
+		return &states[0]
+	}
+
+	// 序列出现，持续时间未满足时处于 pending
+	series = `{"metric":{"agent_id":"a1","mountpoint":"/"},"value":[1767225600,"95"]}`
+	evaluate(base)
+	state := loadState()
+	if state == nil || state.ID != stateID || state.IsFiring || state.AgentID != "a1" {
+		t.Fatalf("序列出现后应处于 pending: %+v", state)
+	}
+	if got := waitNotification(s, 100*time.Millisecond); got != 0 {
+		t.Errorf("pending 时不应发送通知，实际通知记录 %d", got)
+	}
+
+	// 持续时间满足后触发
+	evaluate(base + 60_000)
+	state = loadState()
+	if state == nil || !state.IsFiring || state.LastRecordID == 0 {
+		t.Fatalf("持续时间满足后应触发: %+v", state)
+	}
+	record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, state.LastRecordID)
+	if err != nil {
+		t.Fatalf("查询告警记录失败: %v", err)
+	}
+	if record.Status != "firing" || record.AgentName != "web-1" || record.Level != "critical" || record.StateID != stateID || record.ActualValue != 95 {
+		t.Errorf("告警记录不正确: %+v", record)
+	}
+	if got := waitNotification(s, time.Second); got != record.ID {
+		t.Errorf("触发时应发送通知，实际通知记录 %d", got)
+	}
+
+	// 告警中再次评估不重复触发
+	evaluate(base + 120_000)
+	if got := waitNotification(s, 100*time.Millisecond); got != 0 {
+		t.Errorf("告警中不应重复通知，实际通知记录 %d", got)
+	}
+
+	// 序列消失后恢复并清理状态
+	series = ""
+	evaluate(base + 180_000)
+	if state := loadState(); state != nil {
+		t.Errorf("序列消失后应删除告警状态: %+v", state)
+	}
+	record, err = s.AlertRecordRepo.GetAlertRecordByID(ctx, record.ID)
+	if err != nil {
+		t.Fatalf("查询告警记录失败: %v", err)
+	}
+	if record.Status != "resolved" || record.ResolvedAt == 0 {
+		t.Errorf("序列消失后告警应恢复: %+v", record)
+	}
+	if got := waitNotification(s, time.Second); got != record.ID {
+		t.Errorf("恢复时应发送通知，实际通知记录 %d", got)
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AlertRuleSetRequest 告警规则集请求
+type AlertRuleSetRequest struct {
+	Name        string            `json:"name" validate:"required"`
+	Description string            `json:"description"`
+	Enabled     bool              `json:"enabled"`
+	AgentIDs    []string          `json:"agentIds"`
+	Tags        []string          `json:"tags"`
+	Priority    int               `json:"priority"`
+	Rules       models.AlertRules `json:"rules"`
+}
+
+func validateAlertRuleSetRequest(req *AlertRuleSetRequest) error {
+	if len(req.AgentIDs) == 0 && len(req.Tags) == 0 {
+		return orz.NewError(400, "请至少指定一个探针或标签")
+	}
+	return validateAlertRuleTargets(&req.Rules)
+}
+
+// EffectiveAlertRules 探针生效的告警规则及其来源
+type EffectiveAlertRules struct {
+	Rules       models.AlertRules `json:"rules"`
+	Source      string            `json:"source"`                // global, tag, agent
+	RuleSetID   string            `json:"ruleSetId,omitempty"`   // 规则集ID（全局规则为空）
+	RuleSetName string            `json:"ruleSetName,omitempty"` // 规则集名称
+}
+
+// applyTo 在告警记录中标记产生告警的规则
+func (r *EffectiveAlertRules) applyTo(record *models.AlertRecord) {
+	record.RuleSource = r.Source
+	record.RuleSetID = r.RuleSetID
+	record.RuleSetName = r.RuleSetName
+}
+
+// AlertRuleResolver 解析探针生效的告警规则，同一轮检查内缓存结果
+type AlertRuleResolver struct {
+	config   *models.AlertConfig
+	ruleSets []models.AlertRuleSet
+	cache    map[string]*EffectiveAlertRules
+}
+
+// NewAlertRuleResolver 加载告警配置和启用的规则集，每轮检查创建一次，供该轮所有探针共用
+func (s *AlertService) NewAlertRuleResolver(ctx context.Context) (*AlertRuleResolver, error) {
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.newAlertRuleResolver(ctx, alertConfig)
+}
+
+func (s *AlertService) newAlertRuleResolver(ctx context.Context, config *models.AlertConfig) (*AlertRuleResolver, error) {
+	ruleSets, err := s.AlertRuleSetRepo.FindEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &AlertRuleResolver{
+		config:   config,
+		ruleSets: ruleSets,
+		cache:    make(map[string]*EffectiveAlertRules),
+	}, nil
+}
+
+// resolve 按 探针 > 标签 > 全局 的优先级选出生效规则，同一层级取优先级最高的规则集
+func (r *AlertRuleResolver) resolve(agent *models.Agent) *EffectiveAlertRules {
+	if rules, ok := r.cache[agent.ID]; ok {
+		return rules
+	}
+
+	// ruleSets 已按优先级降序排列，同一层级第一个匹配的即为生效规则集
+	var agentMatch, tagMatch *models.AlertRuleSet
+	for i := range r.ruleSets {
+		ruleSet := &r.ruleSets[i]
+		if agentMatch == nil && slices.Contains(ruleSet.AgentIDs, agent.ID) {
+			agentMatch = ruleSet
+		}
+		if tagMatch == nil && slices.ContainsFunc(agent.Tags, func(tag string) bool {
+			return slices.Contains(ruleSet.Tags, tag)
+		}) {
+			tagMatch = ruleSet
+		}
+	}
+
+	var rules *EffectiveAlertRules
+	switch {
+	case agentMatch != nil:
+		rules = newEffectiveAlertRules(models.AlertRuleSourceAgent, agentMatch)
+	case tagMatch != nil:
+		rules = newEffectiveAlertRules(models.AlertRuleSourceTag, tagMatch)
+	default:
+		rules = &EffectiveAlertRules{
+			Rules:  r.config.Rules,
+			Source: models.AlertRuleSourceGlobal,
+		}
+	}
+	r.cache[agent.ID] = rules
+	return rules
+}
+
+func newEffectiveAlertRules(source string, ruleSet *models.AlertRuleSet) *EffectiveAlertRules {
+	return &EffectiveAlertRules{
+		Rules:       ruleSet.Rules.Data(),
+		Source:      source,
+		RuleSetID:   ruleSet.ID,
+		RuleSetName: ruleSet.Name,
+	}
+}
+
+// GetEffectiveAlertRules 获取探针生效的告警规则
+func (s *AlertService) GetEffectiveAlertRules(ctx context.Context, agentID string) (*EffectiveAlertRules, error) {
+	agent, err := s.agentRepo.FindById(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "探针不存在")
+		}
+		return nil, err
+	}
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := s.newAlertRuleResolver(ctx, alertConfig)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.resolve(&agent), nil
+}
+
+// ListRuleSets 查询所有告警规则集
+func (s *AlertService) ListRuleSets(ctx context.Context) ([]models.AlertRuleSet, error) {
+	var ruleSets []models.AlertRuleSet
+	err := s.AlertRuleSetRepo.GetDB(ctx).Order("priority desc, created_at asc").Find(&ruleSets).Error
+	return ruleSets, err
+}
+
+// GetRuleSet 获取告警规则集
+func (s *AlertService) GetRuleSet(ctx context.Context, id string) (*models.AlertRuleSet, error) {
+	ruleSet, err := s.AlertRuleSetRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "告警规则集不存在")
+		}
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+// CreateRuleSet 创建告警规则集
+func (s *AlertService) CreateRuleSet(ctx context.Context, req *AlertRuleSetRequest) (*models.AlertRuleSet, error) {
+	if err := validateAlertRuleSetRequest(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	ruleSet := &models.AlertRuleSet{
+		ID:          uuid.NewString(),
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		AgentIDs:    req.AgentIDs,
+		Tags:        req.Tags,
+		Priority:    req.Priority,
+		Rules:       datatypes.NewJSONType(req.Rules),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.AlertRuleSetRepo.Create(ctx, ruleSet); err != nil {
+		return nil, err
+	}
+	return ruleSet, nil
+}
+
+// UpdateRuleSet 更新告警规则集
+func (s *AlertService) UpdateRuleSet(ctx context.Context, id string, req *AlertRuleSetRequest) (*models.AlertRuleSet, error) {
+	if err := validateAlertRuleSetRequest(req); err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := s.GetRuleSet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet.Name = req.Name
+	ruleSet.Description = req.Description
+	ruleSet.Enabled = req.Enabled
+	ruleSet.AgentIDs = req.AgentIDs
+	ruleSet.Tags = req.Tags
+	ruleSet.Priority = req.Priority
+	ruleSet.Rules = datatypes.NewJSONType(req.Rules)
+	ruleSet.UpdatedAt = time.Now().UnixMilli()
+	if err := s.AlertRuleSetRepo.Save(ctx, ruleSet); err != nil {
+		return nil, err
+	}
+	return ruleSet, nil
+}
+
+// DeleteRuleSet 删除告警规则集
+func (s *AlertService) DeleteRuleSet(ctx context.Context, id string) error {
+	return s.AlertRuleSetRepo.DeleteById(ctx, id)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"gorm.io/datatypes"
+)
+
+func TestResolveAlertRules(t *testing.T) {
+	global := models.AlertRules{CPUEnabled: true, CPUThreshold: 80}
+	ruleSet := func(id string, priority int, enabled bool, agentIDs, tags []string, cpuThreshold float64) models.AlertRuleSet {
+		return models.AlertRuleSet{
+			ID:        id,
+			Name:      id,
+			Enabled:   enabled,
+			AgentIDs:  agentIDs,
+			Tags:      tags,
+			Priority:  priority,
+			Rules:     datatypes.NewJSONType(models.AlertRules{CPUEnabled: true, CPUThreshold: cpuThreshold}),
+			CreatedAt: int64(priority),
+		}
+	}
+	ruleSets := []models.AlertRuleSet{
+		ruleSet("db-tag", 10, true, nil, []string{"db"}, 95),
+		ruleSet("web-tag-low", 1, true, nil, []string{"web"}, 85),
+		ruleSet("web-tag-high", 5, true, nil, []string{"web", "cache"}, 90),
+		ruleSet("a1-agent", 0, true, []string{"a1"}, nil, 99),
+		ruleSet("a2-disabled", 100, false, []string{"a2"}, []string{"web"}, 50),
+	}
+
+	tests := []struct {
+		name      string
+		agent     models.Agent
+		source    string
+		ruleSetID string
+		threshold float64
+	}{
+		{"按探针匹配优先于标签", models.Agent{ID: "a1", Tags: []string{"db"}}, models.AlertRuleSourceAgent, "a1-agent", 99},
+		{"同一层级取优先级最高的规则集", models.Agent{ID: "a3", Tags: []string{"web"}}, models.AlertRuleSourceTag, "web-tag-high", 90},
+		{"多个标签匹配时取优先级最高的规则集", models.Agent{ID: "a4", Tags: []string{"web", "db"}}, models.AlertRuleSourceTag, "db-tag", 95},
+		{"停用的规则集不生效", models.Agent{ID: "a2", Tags: []string{"cache"}}, models.AlertRuleSourceTag, "web-tag-high", 90},
+		{"未匹配时使用全局规则", models.Agent{ID: "a5", Tags: []string{"dev"}}, models.AlertRuleSourceGlobal, "", 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestAlertService(t, models.AlertConfig{Enabled: true, Rules: global})
+			if err := db.Create(&ruleSets).Error; err != nil {
+				t.Fatalf("写入规则集失败: %v", err)
+			}
+			if err := db.Create(&tt.agent).Error; err != nil {
+				t.Fatalf("写入探针失败: %v", err)
+			}
+
+			rules, err := s.GetEffectiveAlertRules(context.Background(), tt.agent.ID)
+			if err != nil {
+				t.Fatalf("GetEffectiveAlertRules() 失败: %v", err)
+			}
+			if rules.Source != tt.source || rules.RuleSetID != tt.ruleSetID || rules.Rules.CPUThreshold != tt.threshold {
+				t.Errorf("生效规则 = %s/%s/%.0f, want %s/%s/%.0f",
+					rules.Source, rules.RuleSetID, rules.Rules.CPUThreshold, tt.source, tt.ruleSetID, tt.threshold)
+			}
+
+			record := &models.AlertRecord{}
+			rules.applyTo(record)
+			if record.RuleSource != tt.source || record.RuleSetID != tt.ruleSetID || record.RuleSetName != rules.RuleSetName {
+				t.Errorf("告警记录的规则来源 = %+v", record)
+			}
+		})
+	}
+}
+
+func TestAlertRuleResolverCache(t *testing.T) {
+	resolver := &AlertRuleResolver{
+		config: &models.AlertConfig{},
+		ruleSets: []models.AlertRuleSet{
+			{ID: "web", Tags: []string{"web"}, Rules: datatypes.NewJSONType(models.AlertRules{CPUThreshold: 90})},
+		},
+		cache: make(map[string]*EffectiveAlertRules),
+	}
+	agent := &models.Agent{ID: "a1", Tags: []string{"web"}}
+	first := resolver.resolve(agent)
+
+	// 同一轮检查内探针标签变化不影响已解析的结果
+	agent.Tags = nil
+	if second := resolver.resolve(agent); second != first {
+		t.Errorf("同一轮检查内应复用解析结果")
+	}
+	if first.RuleSetID != "web" {
+		t.Errorf("生效规则集 = %q, want web", first.RuleSetID)
+	}
+}
+
+func TestValidateAlertRuleSetRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     AlertRuleSetRequest
+		wantErr bool
+	}{
+		{"未指定探针和标签", AlertRuleSetRequest{Name: "空"}, true},
+		{"指定标签", AlertRuleSetRequest{Name: "web", Tags: []string{"web"}}, false},
+		{"指定探针", AlertRuleSetRequest{Name: "a1", AgentIDs: []string{"a1"}}, false},
+		{"挂载点规则无效", AlertRuleSetRequest{Name: "a1", AgentIDs: []string{"a1"}, Rules: models.AlertRules{DiskMountPoints: []string{"~["}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAlertRuleSetRequest(&tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateAlertRuleSetRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
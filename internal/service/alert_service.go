@@ -8,6 +8,7 @@ import (
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/protocol"
 	"github.com/dushixiang/pika/internal/repo"
+	"github.com/dushixiang/pika/internal/vmclient"
 	"github.com/go-orz/orz"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -15,27 +16,35 @@ import (
 
 // AlertService 告警服务
 type AlertService struct {
-	Service           *orz.Service
-	AlertRecordRepo   *repo.AlertRecordRepo
-	AlertStateRepo    *repo.AlertStateRepo
-	agentRepo         *repo.AgentRepo
-	monitorService    *MonitorService
-	propertyService   *PropertyService
-	notifier          *Notifier
-	notificationQueue *NotificationQueue
-	logger            *zap.Logger
+	Service             *orz.Service
+	AlertRecordRepo     *repo.AlertRecordRepo
+	AlertStateRepo      *repo.AlertStateRepo
+	AlertRuleSetRepo    *repo.AlertRuleSetRepo
+	AlertPromQLRuleRepo *repo.AlertPromQLRuleRepo
+	agentRepo           *repo.AgentRepo
+	monitorService      *MonitorService
+	propertyService     *PropertyService
+	notifier            *Notifier
+	vmClient            *vmclient.VMClient
+	silenceService      *AlertSilenceService
+	notificationQueue   *NotificationQueue
+	logger              *zap.Logger
 }
 
-func NewAlertService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, monitorService *MonitorService, notifier *Notifier) *AlertService {
+func NewAlertService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, monitorService *MonitorService, notifier *Notifier, vmClient *vmclient.VMClient, silenceService *AlertSilenceService) *AlertService {
 	service := &AlertService{
-		Service:         orz.NewService(db),
-		AlertRecordRepo: repo.NewAlertRecordRepo(db),
-		AlertStateRepo:  repo.NewAlertStateRepo(db),
-		agentRepo:       repo.NewAgentRepo(db),
-		monitorService:  monitorService,
-		propertyService: propertyService,
-		notifier:        notifier,
-		logger:          logger,
+		Service:             orz.NewService(db),
+		AlertRecordRepo:     repo.NewAlertRecordRepo(db),
+		AlertStateRepo:      repo.NewAlertStateRepo(db),
+		AlertRuleSetRepo:    repo.NewAlertRuleSetRepo(db),
+		AlertPromQLRuleRepo: repo.NewAlertPromQLRuleRepo(db),
+		agentRepo:           repo.NewAgentRepo(db),
+		monitorService:      monitorService,
+		propertyService:     propertyService,
+		notifier:            notifier,
+		vmClient:            vmClient,
+		silenceService:      silenceService,
+		logger:              logger,
 	}
 
 	service.notificationQueue = NewNotificationQueue(db, service, service.AlertRecordRepo, logger)
@@ -69,14 +78,10 @@ func (s *AlertService) Shutdown() {
 	s.notificationQueue.Shutdown()
 }
 
-// CheckMetrics 检查指标并触发告警
-func (s *AlertService) CheckMetrics(ctx context.Context, agentID string, cpu, memory, disk, networkSpeed float64) error {
-	// 获取全局告警配置
-	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
-	if err != nil {
-		s.logger.Error("获取全局告警配置失败", zap.Error(err))
-		return err
-	}
+// CheckMetrics 检查指标并触发告警，disks 和 interfaces 为各挂载点和网卡的最新数据，用于按设备告警。
+// resolver 由调用方每轮检查创建一次，避免每个探针都查询规则集
+func (s *AlertService) CheckMetrics(ctx context.Context, resolver *AlertRuleResolver, agentID string, cpu, memory, disk, networkSpeed float64, disks []protocol.DiskData, interfaces []protocol.NetworkData) error {
+	alertConfig := resolver.config
 
 	// 如果全局告警未启用，直接返回
 	if !alertConfig.Enabled {
@@ -90,36 +95,114 @@ func (s *AlertService) CheckMetrics(ctx context.Context, agentID string, cpu, me
 		return err
 	}
 
+	// 解析探针生效的告警规则（探针 > 标签 > 全局）
+	rules := resolver.resolve(&agent)
+
 	now := time.Now().UnixMilli()
 
 	// 检查 CPU 告警
-	if alertConfig.Rules.CPUEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "cpu", cpu, alertConfig.Rules.CPUThreshold, alertConfig.Rules.CPUDuration, now)
+	if rules.Rules.CPUEnabled {
+		s.checkAlert(ctx, alertConfig, &agent, rules, "cpu", "", cpu, newMetricThreshold(rules.Rules.CPUThreshold, rules.Rules.CPUDuration, rules.Rules.CPURecoveryThreshold, rules.Rules.CPURecoveryDuration), now)
 	}
 
 	// 检查内存告警
-	if alertConfig.Rules.MemoryEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "memory", memory, alertConfig.Rules.MemoryThreshold, alertConfig.Rules.MemoryDuration, now)
+	if rules.Rules.MemoryEnabled {
+		s.checkAlert(ctx, alertConfig, &agent, rules, "memory", "", memory, newMetricThreshold(rules.Rules.MemoryThreshold, rules.Rules.MemoryDuration, rules.Rules.MemoryRecoveryThreshold, rules.Rules.MemoryRecoveryDuration), now)
 	}
 
-	// 检查磁盘告警
-	if alertConfig.Rules.DiskEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "disk", disk, alertConfig.Rules.DiskThreshold, alertConfig.Rules.DiskDuration, now)
+	// 检查磁盘告警，配置了挂载点时按挂载点分别告警
+	if rules.Rules.DiskEnabled {
+		threshold := newMetricThreshold(rules.Rules.DiskThreshold, rules.Rules.DiskDuration, rules.Rules.DiskRecoveryThreshold, rules.Rules.DiskRecoveryDuration)
+		if len(rules.Rules.DiskMountPoints) > 0 {
+			values := make(map[string]float64, len(disks))
+			for _, diskData := range disks {
+				values[diskData.MountPoint] = diskData.UsagePercent
+			}
+			s.checkDeviceAlerts(ctx, alertConfig, &agent, rules, "disk", rules.Rules.DiskMountPoints, values, threshold, now)
+		} else {
+			s.closeStaleAlertStates(ctx, alertConfig, &agent, "disk", map[string]bool{fmt.Sprintf("%s:global:disk", agent.ID): true}, now)
+			s.checkAlert(ctx, alertConfig, &agent, rules, "disk", "", disk, threshold, now)
+		}
 	}
 
-	// 检查网速告警
-	if alertConfig.Rules.NetworkEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "network", networkSpeed, alertConfig.Rules.NetworkThreshold, alertConfig.Rules.NetworkDuration, now)
+	// 检查网速告警，配置了网卡时按网卡分别告警
+	if rules.Rules.NetworkEnabled {
+		threshold := newMetricThreshold(rules.Rules.NetworkThreshold, rules.Rules.NetworkDuration, rules.Rules.NetworkRecoveryThreshold, rules.Rules.NetworkRecoveryDuration)
+		if len(rules.Rules.NetworkInterfaces) > 0 {
+			values := make(map[string]float64, len(interfaces))
+			for _, netData := range interfaces {
+				values[netData.Interface] = float64(netData.BytesSentRate+netData.BytesRecvRate) / 1024 / 1024
+			}
+			s.checkDeviceAlerts(ctx, alertConfig, &agent, rules, "network", rules.Rules.NetworkInterfaces, values, threshold, now)
+		} else {
+			s.closeStaleAlertStates(ctx, alertConfig, &agent, "network", map[string]bool{fmt.Sprintf("%s:global:network", agent.ID): true}, now)
+			s.checkAlert(ctx, alertConfig, &agent, rules, "network", "", networkSpeed, threshold, now)
+		}
 	}
 
 	return nil
 }
 
-// checkAlert 检查单个告警规则
-func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, alertType string, currentValue, threshold float64, duration int, now int64) {
-	stateKey := fmt.Sprintf("%s:global:%s", agent.ID, alertType)
+// metricThreshold 指标告警的触发和恢复条件
+type metricThreshold struct {
+	Threshold         float64 // 告警阈值
+	Duration          int     // 超过阈值持续多久后触发（秒）
+	RecoveryThreshold float64 // 恢复阈值，告警中低于该值才开始恢复计时
+	RecoveryDuration  int     // 低于恢复阈值持续多久后恢复（秒）
+}
+
+// newMetricThreshold 恢复阈值未设置或高于告警阈值时使用告警阈值
+func newMetricThreshold(threshold float64, duration int, recoveryThreshold float64, recoveryDuration int) metricThreshold {
+	if recoveryThreshold <= 0 || recoveryThreshold > threshold {
+		recoveryThreshold = threshold
+	}
+	return metricThreshold{
+		Threshold:         threshold,
+		Duration:          duration,
+		RecoveryThreshold: recoveryThreshold,
+		RecoveryDuration:  max(recoveryDuration, 0),
+	}
+}
 
-	var shouldFire, shouldResolve bool
+// evaluate 按阈值和持续时间更新告警状态，返回是否触发或恢复
+func (t metricThreshold) evaluate(state *models.AlertState, value float64, now int64) (fire, resolve bool) {
+	if value >= t.Threshold {
+		if state.StartTime == 0 {
+			state.StartTime = now
+		}
+		state.RecoveryStart = 0
+
+		elapsedSeconds := (now - state.StartTime) / 1000
+		if elapsedSeconds >= int64(t.Duration) && !state.IsFiring {
+			fire = true
+			state.IsFiring = true
+		}
+	} else {
+		state.StartTime = 0
+
+		// 告警中需低于恢复阈值并持续恢复时间后才恢复，避免在阈值附近反复触发
+		if state.IsFiring && value < t.RecoveryThreshold {
+			if state.RecoveryStart == 0 {
+				state.RecoveryStart = now
+			}
+			if (now-state.RecoveryStart)/1000 >= int64(t.RecoveryDuration) {
+				resolve = true
+				state.IsFiring = false
+				state.RecoveryStart = 0
+			}
+		} else {
+			state.RecoveryStart = 0
+		}
+	}
+	return fire, resolve
+}
+
+// checkAlert 检查单个告警规则，device 不为空时按磁盘挂载点或网卡单独维护告警状态
+func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, alertType string, device string, currentValue float64, threshold metricThreshold, now int64) {
+	stateKey := fmt.Sprintf("%s:global:%s", agent.ID, alertType)
+	if device != "" {
+		stateKey = deviceStateKey(agent.ID, alertType, device)
+	}
 
 	// 从数据库加载状态
 	state, err := s.AlertStateRepo.GetAlertState(ctx, stateKey)
@@ -129,33 +212,21 @@ func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfi
 			ID:        stateKey,
 			AgentID:   agent.ID,
 			AlertType: alertType,
+			Device:    device,
 		}
 	}
 
 	// 按探针维度更新最新阈值/持续时间，支持配置变更
 	state.AgentID = agent.ID
 	state.AlertType = alertType
-	state.Threshold = threshold
-	state.Duration = duration
+	state.Threshold = threshold.Threshold
+	state.Duration = threshold.Duration
 	state.Value = currentValue
 	state.LastCheckTime = now
 
-	if currentValue >= threshold {
-		if state.StartTime == 0 {
-			state.StartTime = now
-		}
+	shouldFire, shouldResolve := threshold.evaluate(state, currentValue, now)
 
-		elapsedSeconds := (now - state.StartTime) / 1000
-		if elapsedSeconds >= int64(duration) && !state.IsFiring {
-			shouldFire = true
-			state.IsFiring = true
-		}
-	} else {
-		if state.IsFiring {
-			shouldResolve = true
-		}
-		state.StartTime = 0
-	}
+	flap := s.detectFlapping(config, state, shouldFire || shouldResolve, now)
 
 	// 保存状态到数据库
 	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
@@ -163,8 +234,23 @@ func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfi
 		return
 	}
 
+	// 抖动期间由抖动告警记录代替单独的触发和恢复
+	switch flap {
+	case flapStart:
+		s.startFlapping(ctx, config, agent, rules, state, now)
+		return
+	case flapContinue:
+		if shouldFire || shouldResolve {
+			s.updateFlapping(ctx, state)
+		}
+		return
+	case flapEnd:
+		s.endFlapping(ctx, agent, state, now)
+		return
+	}
+
 	if shouldFire {
-		s.fireAlert(ctx, config, agent, state)
+		s.fireAlert(ctx, config, agent, rules, state)
 	}
 
 	if shouldResolve {
@@ -173,11 +259,12 @@ func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfi
 }
 
 // fireAlert 触发告警
-func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) {
+func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, state *models.AlertState) {
 	s.logger.Info("触发告警",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
 		zap.String("alertType", state.AlertType),
+		zap.String("device", state.Device),
 		zap.Float64("value", state.Value),
 		zap.Float64("threshold", state.Threshold),
 	)
@@ -195,8 +282,11 @@ func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig
 		Level:       s.calculateLevel(state.Value, state.Threshold),
 		Status:      "firing",
 		FiredAt:     now,
+		Device:      state.Device,
+		StateID:     state.ID,
 		CreatedAt:   now,
 	}
+	rules.applyTo(record)
 
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
@@ -273,8 +363,16 @@ func (s *AlertService) buildAlertMessage(state *models.AlertState) string {
 		alertTypeName = "内存使用率"
 	case "disk":
 		alertTypeName = "磁盘使用率"
+		if state.Device != "" {
+			alertTypeName = fmt.Sprintf("挂载点 %s 使用率", state.Device)
+		}
 	case "network":
-		return fmt.Sprintf("网速持续%d秒超过%.2fMB/s，当前值%.2fMB/s",
+		name := "网速"
+		if state.Device != "" {
+			name = fmt.Sprintf("网卡 %s 网速", state.Device)
+		}
+		return fmt.Sprintf("%s持续%d秒超过%.2fMB/s，当前值%.2fMB/s",
+			name,
 			state.Duration,
 			state.Threshold,
 			state.Value,
@@ -308,8 +406,15 @@ func (s *AlertService) calculateLevel(value, threshold float64) string {
 	}
 }
 
-// sendAlertNotification 发送告警通知(通过队列异步发送)
+// sendAlertNotification 发送告警通知(通过队列异步发送)，被抑制或匹配告警静默时只记录不通知
 func (s *AlertService) sendAlertNotification(record *models.AlertRecord, agent *models.Agent) {
+	ctx := context.Background()
+	if s.applyInhibition(ctx, record, agent) {
+		return
+	}
+	if s.silenceService.Apply(ctx, record, agent) {
+		return
+	}
 	s.notificationQueue.Enqueue(record.ID, agent)
 }
 
@@ -338,17 +443,26 @@ func (s *AlertService) sendAlertNotificationSync(ctx context.Context, record *mo
 		return fmt.Errorf("没有启用的通知渠道")
 	}
 
-	return s.notifier.SendNotificationByConfigs(ctx, enabledChannels, record, agent, alertConfig.MaskIP)
+	err = s.notifier.SendNotificationByConfigs(ctx, enabledChannels, record, agent, alertConfig.MaskIP)
+	s.saveNotificationThreads(ctx, record)
+	return err
 }
 
-// CheckMonitorAlerts 检查监控相关告警（证书和服务下线）
-func (s *AlertService) CheckMonitorAlerts(ctx context.Context) error {
-	// 获取全局告警配置
-	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
-	if err != nil {
-		s.logger.Error("获取全局告警配置失败", zap.Error(err))
-		return err
+// saveNotificationThreads 保存告警触发消息在各通知渠道的会话标识，供后续提醒和恢复消息回复
+func (s *AlertService) saveNotificationThreads(ctx context.Context, record *models.AlertRecord) {
+	if record.Status != "firing" || len(record.NotificationThreads.Data()) == 0 {
+		return
+	}
+	if err := s.AlertRecordRepo.UpdateFields(ctx, record.ID, map[string]interface{}{
+		"notification_threads": record.NotificationThreads,
+	}); err != nil {
+		s.logger.Error("保存通知会话失败", zap.Int64("recordId", record.ID), zap.Error(err))
 	}
+}
+
+// CheckMonitorAlerts 检查监控相关告警（证书和服务下线），规则可能被规则集按探针覆盖，是否检查由各探针生效的规则决定
+func (s *AlertService) CheckMonitorAlerts(ctx context.Context, resolver *AlertRuleResolver) error {
+	alertConfig := resolver.config
 
 	// 如果全局告警未启用，直接返回
 	if !alertConfig.Enabled {
@@ -358,31 +472,25 @@ func (s *AlertService) CheckMonitorAlerts(ctx context.Context) error {
 	now := time.Now().UnixMilli()
 
 	// 检查证书告警
-	if alertConfig.Rules.CertEnabled {
-		if err := s.checkCertificateAlerts(ctx, alertConfig, now); err != nil {
-			s.logger.Error("检查证书告警失败", zap.Error(err))
-		}
+	if err := s.checkCertificateAlerts(ctx, alertConfig, resolver, now); err != nil {
+		s.logger.Error("检查证书告警失败", zap.Error(err))
 	}
 
 	// 检查服务下线告警
-	if alertConfig.Rules.ServiceEnabled {
-		if err := s.checkServiceDownAlerts(ctx, alertConfig, now); err != nil {
-			s.logger.Error("检查服务下线告警失败", zap.Error(err))
-		}
+	if err := s.checkServiceDownAlerts(ctx, alertConfig, resolver, now); err != nil {
+		s.logger.Error("检查服务下线告警失败", zap.Error(err))
 	}
 
 	// 检查探针离线告警
-	if alertConfig.Rules.AgentOfflineEnabled {
-		if err := s.checkAgentOfflineAlerts(ctx, alertConfig, now); err != nil {
-			s.logger.Error("检查探针离线告警失败", zap.Error(err))
-		}
+	if err := s.checkAgentOfflineAlerts(ctx, alertConfig, resolver, now); err != nil {
+		s.logger.Error("检查探针离线告警失败", zap.Error(err))
 	}
 
 	return nil
 }
 
 // checkCertificateAlerts 检查证书告警
-func (s *AlertService) checkCertificateAlerts(ctx context.Context, config *models.AlertConfig, now int64) error {
+func (s *AlertService) checkCertificateAlerts(ctx context.Context, config *models.AlertConfig, resolver *AlertRuleResolver, now int64) error {
 	// 获取所有最新的监控指标（仅HTTPS类型）
 	// 这里需要查询最新的 monitor_metrics 记录，获取证书剩余天数
 	monitors, err := s.monitorService.GetLatestMonitorMetricsByType(ctx, "http")
@@ -430,10 +538,15 @@ func (s *AlertService) checkCertificateAlerts(ctx context.Context, config *model
 			continue
 		}
 
+		rules := resolver.resolve(agent)
+		if !rules.Rules.CertEnabled {
+			continue
+		}
+
 		// 检查证书剩余天数是否低于阈值
-		if certDaysLeft <= config.Rules.CertThreshold && certDaysLeft >= 0 {
+		if certDaysLeft <= rules.Rules.CertThreshold && certDaysLeft >= 0 {
 			// 触发告警（证书告警不需要持续时间，直接触发）
-			s.checkCertAlert(ctx, config, agent, &monitor, certDaysLeft, now)
+			s.checkCertAlert(ctx, config, agent, rules, &monitor, certDaysLeft, now)
 		} else {
 			// 恢复告警（如果之前触发过）
 			s.resolveCertAlert(ctx, config, agent, &monitor, certDaysLeft)
@@ -444,7 +557,7 @@ func (s *AlertService) checkCertificateAlerts(ctx context.Context, config *model
 }
 
 // checkCertAlert 检查并触发证书告警
-func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, monitor *protocol.MonitorData, certDaysLeft float64, now int64) {
+func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, monitor *protocol.MonitorData, certDaysLeft float64, now int64) {
 	stateKey := fmt.Sprintf("%s:global:cert:%s", agent.ID, monitor.MonitorId)
 
 	// 从数据库加载状态
@@ -459,12 +572,12 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 	}
 	state.AgentID = agent.ID
 	state.AlertType = "cert"
-	state.Threshold = config.Rules.CertThreshold
+	state.Threshold = rules.Rules.CertThreshold
 	state.Duration = 0
 	state.Value = certDaysLeft
 	state.LastCheckTime = now
 
-	shouldFire := certDaysLeft <= config.Rules.CertThreshold && !state.IsFiring
+	shouldFire := certDaysLeft <= rules.Rules.CertThreshold && !state.IsFiring
 
 	if shouldFire {
 		state.IsFiring = true
@@ -486,15 +599,15 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 		zap.String("monitorName", monitor.MonitorName),
 		zap.String("target", monitor.Target),
 		zap.Float64("certDaysLeft", certDaysLeft),
-		zap.Float64("threshold", config.Rules.CertThreshold),
+		zap.Float64("threshold", rules.Rules.CertThreshold),
 	)
 
 	// 构建告警消息，优先使用监控任务名称
 	var message string
 	if monitor.MonitorName != "" {
-		message = fmt.Sprintf("监控项 %s (%s) 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.MonitorName, monitor.Target, certDaysLeft, config.Rules.CertThreshold)
+		message = fmt.Sprintf("监控项 %s (%s) 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.MonitorName, monitor.Target, certDaysLeft, rules.Rules.CertThreshold)
 	} else {
-		message = fmt.Sprintf("监控项 %s 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.Target, certDaysLeft, config.Rules.CertThreshold)
+		message = fmt.Sprintf("监控项 %s 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.Target, certDaysLeft, rules.Rules.CertThreshold)
 	}
 
 	record := &models.AlertRecord{
@@ -502,13 +615,16 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 		AgentName:   agent.Name,
 		AlertType:   "cert",
 		Message:     message,
-		Threshold:   config.Rules.CertThreshold,
+		Threshold:   rules.Rules.CertThreshold,
 		ActualValue: certDaysLeft,
 		Level:       s.calculateCertLevel(certDaysLeft),
 		Status:      "firing",
 		FiredAt:     now,
+		MonitorID:   monitor.MonitorId,
+		StateID:     state.ID,
 		CreatedAt:   now,
 	}
+	rules.applyTo(record)
 
 	err = s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
@@ -583,7 +699,7 @@ func (s *AlertService) calculateCertLevel(daysLeft float64) string {
 }
 
 // checkServiceDownAlerts 检查服务下线告警
-func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *models.AlertConfig, now int64) error {
+func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *models.AlertConfig, resolver *AlertRuleResolver, now int64) error {
 	// 获取所有最新的监控指标
 	monitors, err := s.monitorService.GetAllLatestMonitorMetrics(ctx)
 	if err != nil {
@@ -623,6 +739,11 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 			continue
 		}
 
+		rules := resolver.resolve(agent)
+		if !rules.Rules.ServiceEnabled {
+			continue
+		}
+
 		stateKey := fmt.Sprintf("%s:global:service:%s", agent.ID, monitor.MonitorId)
 
 		var shouldFire, shouldResolve bool
@@ -639,7 +760,7 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 		}
 		state.AgentID = agent.ID
 		state.AlertType = "service"
-		state.Duration = config.Rules.ServiceDuration
+		state.Duration = rules.Rules.ServiceDuration
 		state.LastCheckTime = now
 
 		if monitor.Status == "down" {
@@ -648,7 +769,7 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 			}
 
 			elapsedSeconds := (now - state.StartTime) / 1000
-			if elapsedSeconds >= int64(config.Rules.ServiceDuration) && !state.IsFiring {
+			if elapsedSeconds >= int64(rules.Rules.ServiceDuration) && !state.IsFiring {
 				shouldFire = true
 				state.IsFiring = true
 			}
@@ -666,7 +787,7 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 		}
 
 		if shouldFire {
-			s.fireServiceDownAlert(ctx, config, agent, &monitor, state, now)
+			s.fireServiceDownAlert(ctx, config, agent, rules, &monitor, state, now)
 		}
 
 		if shouldResolve {
@@ -678,7 +799,7 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 }
 
 // fireServiceDownAlert 触发服务下线告警
-func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, monitor *protocol.MonitorData, state *models.AlertState, now int64) {
+func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, monitor *protocol.MonitorData, state *models.AlertState, now int64) {
 	s.logger.Info("触发服务下线告警",
 		zap.String("agentId", agent.ID),
 		zap.String("monitorId", monitor.MonitorId),
@@ -706,8 +827,11 @@ func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.
 		Level:       "critical",
 		Status:      "firing",
 		FiredAt:     now,
+		MonitorID:   monitor.MonitorId,
+		StateID:     state.ID,
 		CreatedAt:   now,
 	}
+	rules.applyTo(record)
 
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
@@ -763,7 +887,7 @@ func (s *AlertService) resolveServiceDownAlert(ctx context.Context, config *mode
 }
 
 // checkAgentOfflineAlerts 检查探针离线告警
-func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *models.AlertConfig, now int64) error {
+func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *models.AlertConfig, resolver *AlertRuleResolver, now int64) error {
 	// 获取所有探针
 	agents, err := s.agentRepo.FindAll(ctx)
 	if err != nil {
@@ -771,6 +895,11 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 	}
 
 	for _, agent := range agents {
+		rules := resolver.resolve(&agent)
+		if !rules.Rules.AgentOfflineEnabled {
+			continue
+		}
+
 		stateKey := fmt.Sprintf("%s:global:agent_offline:%s", agent.ID, agent.ID)
 
 		// 防止时钟回拨导致负数
@@ -792,14 +921,14 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 
 		state.AgentID = agent.ID
 		state.AlertType = "agent_offline"
-		state.Duration = config.Rules.AgentOfflineDuration
-		state.Threshold = float64(config.Rules.AgentOfflineDuration)
+		state.Duration = rules.Rules.AgentOfflineDuration
+		state.Threshold = float64(rules.Rules.AgentOfflineDuration)
 		state.Value = float64(offlineSeconds)
 		state.LastCheckTime = now
 
 		var shouldFire, shouldResolve bool
 
-		if offlineSeconds >= int64(config.Rules.AgentOfflineDuration) {
+		if offlineSeconds >= int64(rules.Rules.AgentOfflineDuration) {
 			if !state.IsFiring {
 				shouldFire = true
 				state.IsFiring = true
@@ -817,7 +946,7 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 		}
 
 		if shouldFire {
-			s.fireAgentOfflineAlert(ctx, config, &agent, state, offlineSeconds, now)
+			s.fireAgentOfflineAlert(ctx, config, &agent, rules, state, offlineSeconds, now)
 		}
 
 		if shouldResolve {
@@ -829,7 +958,7 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 }
 
 // fireAgentOfflineAlert 触发探针离线告警
-func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState, offlineSeconds int64, now int64) {
+func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, rules *EffectiveAlertRules, state *models.AlertState, offlineSeconds int64, now int64) {
 	s.logger.Info("触发探针离线告警",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
@@ -848,8 +977,10 @@ func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models
 		Level:       "critical",
 		Status:      "firing",
 		FiredAt:     now,
+		StateID:     state.ID,
 		CreatedAt:   now,
 	}
+	rules.applyTo(record)
 
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
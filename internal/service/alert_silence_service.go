@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// silenceClockLayout 每周静默时段的时间格式
+const silenceClockLayout = "15:04"
+
+// AlertSilenceService 告警静默服务
+type AlertSilenceService struct {
+	logger           *zap.Logger
+	AlertSilenceRepo *repo.AlertSilenceRepo
+	alertRecordRepo  *repo.AlertRecordRepo
+}
+
+func NewAlertSilenceService(logger *zap.Logger, db *gorm.DB) *AlertSilenceService {
+	return &AlertSilenceService{
+		logger:           logger,
+		AlertSilenceRepo: repo.NewAlertSilenceRepo(db),
+		alertRecordRepo:  repo.NewAlertRecordRepo(db),
+	}
+}
+
+// AlertSilenceRequest 告警静默请求
+type AlertSilenceRequest struct {
+	Name       string   `json:"name" validate:"required"`
+	Comment    string   `json:"comment"`
+	Enabled    bool     `json:"enabled"`
+	AgentIDs   []string `json:"agentIds"`
+	Tags       []string `json:"tags"`
+	AlertTypes []string `json:"alertTypes"`
+	MonitorIDs []string `json:"monitorIds"`
+	Mode       string   `json:"mode" validate:"required"`
+	StartsAt   int64    `json:"startsAt"`
+	EndsAt     int64    `json:"endsAt"`
+	Weekdays   []int    `json:"weekdays"`
+	StartTime  string   `json:"startTime"`
+	EndTime    string   `json:"endTime"`
+}
+
+func validateAlertSilenceRequest(req *AlertSilenceRequest) error {
+	switch req.Mode {
+	case models.AlertSilenceModeOnce:
+		if req.StartsAt <= 0 || req.EndsAt <= req.StartsAt {
+			return orz.NewError(400, "结束时间必须晚于开始时间")
+		}
+	case models.AlertSilenceModeWeekly:
+		if len(req.Weekdays) == 0 {
+			return orz.NewError(400, "请至少选择一天")
+		}
+		for _, day := range req.Weekdays {
+			if day < 0 || day > 6 {
+				return orz.NewError(400, "星期取值范围为 0-6")
+			}
+		}
+		if _, err := time.Parse(silenceClockLayout, req.StartTime); err != nil {
+			return orz.NewError(400, "开始时间格式错误，应为 HH:mm")
+		}
+		if _, err := time.Parse(silenceClockLayout, req.EndTime); err != nil {
+			return orz.NewError(400, "结束时间格式错误，应为 HH:mm")
+		}
+		if req.StartTime == req.EndTime {
+			return orz.NewError(400, "开始时间和结束时间不能相同")
+		}
+	default:
+		return orz.NewError(400, "不支持的静默时间类型")
+	}
+	return nil
+}
+
+func applyAlertSilenceRequest(silence *models.AlertSilence, req *AlertSilenceRequest) {
+	silence.Name = req.Name
+	silence.Comment = req.Comment
+	silence.Enabled = req.Enabled
+	silence.AgentIDs = req.AgentIDs
+	silence.Tags = req.Tags
+	silence.AlertTypes = req.AlertTypes
+	silence.MonitorIDs = req.MonitorIDs
+	silence.Mode = req.Mode
+	silence.StartsAt = 0
+	silence.EndsAt = 0
+	silence.Weekdays = nil
+	silence.StartTime = ""
+	silence.EndTime = ""
+	if req.Mode == models.AlertSilenceModeOnce {
+		silence.StartsAt = req.StartsAt
+		silence.EndsAt = req.EndsAt
+	} else {
+		silence.Weekdays = req.Weekdays
+		silence.StartTime = req.StartTime
+		silence.EndTime = req.EndTime
+	}
+}
+
+// CreateSilence 创建告警静默
+func (s *AlertSilenceService) CreateSilence(ctx context.Context, req *AlertSilenceRequest) (*models.AlertSilence, error) {
+	if err := validateAlertSilenceRequest(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	silence := &models.AlertSilence{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyAlertSilenceRequest(silence, req)
+	if err := s.AlertSilenceRepo.Create(ctx, silence); err != nil {
+		return nil, err
+	}
+	silence.Active = s.isActive(silence, time.Now())
+	return silence, nil
+}
+
+// GetSilence 获取告警静默
+func (s *AlertSilenceService) GetSilence(ctx context.Context, id string) (*models.AlertSilence, error) {
+	silence, err := s.AlertSilenceRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "告警静默不存在")
+		}
+		return nil, err
+	}
+	silence.Active = s.isActive(&silence, time.Now())
+	return &silence, nil
+}
+
+// UpdateSilence 更新告警静默
+func (s *AlertSilenceService) UpdateSilence(ctx context.Context, id string, req *AlertSilenceRequest) (*models.AlertSilence, error) {
+	if err := validateAlertSilenceRequest(req); err != nil {
+		return nil, err
+	}
+
+	silence, err := s.GetSilence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	applyAlertSilenceRequest(silence, req)
+	silence.UpdatedAt = time.Now().UnixMilli()
+	if err := s.AlertSilenceRepo.Save(ctx, silence); err != nil {
+		return nil, err
+	}
+	silence.Active = s.isActive(silence, time.Now())
+	return silence, nil
+}
+
+// DeleteSilence 删除告警静默
+func (s *AlertSilenceService) DeleteSilence(ctx context.Context, id string) error {
+	return s.AlertSilenceRepo.DeleteById(ctx, id)
+}
+
+// FillActive 计算静默当前是否生效
+func (s *AlertSilenceService) FillActive(silences []models.AlertSilence) {
+	now := time.Now()
+	for i := range silences {
+		silences[i].Active = s.isActive(&silences[i], now)
+	}
+}
+
+// Match 查找当前匹配告警的静默，未匹配时返回 nil
+func (s *AlertSilenceService) Match(ctx context.Context, record *models.AlertRecord, agent *models.Agent) (*models.AlertSilence, error) {
+	silences, err := s.AlertSilenceRepo.FindEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range silences {
+		silence := &silences[i]
+		if s.isActive(silence, now) && s.matches(silence, record, agent) {
+			return silence, nil
+		}
+	}
+	return nil, nil
+}
+
+// Apply 判断告警是否应被静默，被静默时在告警记录中标记
+// 恢复通知只跟随触发通知：触发时已静默则静默，触发时已通知则始终发送，避免 PagerDuty 等渠道的事件无法自动解决
+func (s *AlertSilenceService) Apply(ctx context.Context, record *models.AlertRecord, agent *models.Agent) bool {
+	if record.Silenced {
+		return true
+	}
+	if record.Status == "resolved" {
+		return false
+	}
+
+	silence, err := s.Match(ctx, record, agent)
+	if err != nil {
+		// 查询失败时按未静默处理，避免漏发通知
+		s.logger.Error("匹配告警静默失败", zap.Error(err))
+		return false
+	}
+	if silence == nil {
+		return false
+	}
+
+	record.Silenced = true
+	record.SilenceID = silence.ID
+	record.NotificationStatus = "silenced"
+	if record.ID > 0 {
+		if err := s.alertRecordRepo.MarkSilenced(ctx, record.ID, silence.ID); err != nil {
+			s.logger.Error("标记告警静默失败", zap.Int64("recordId", record.ID), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("告警已静默，不发送通知",
+		zap.Int64("recordId", record.ID),
+		zap.String("agentId", record.AgentID),
+		zap.String("alertType", record.AlertType),
+		zap.String("status", record.Status),
+		zap.String("silenceId", silence.ID),
+		zap.String("silenceName", silence.Name))
+	return true
+}
+
+// matches 判断告警是否满足静默的所有匹配条件（为空的条件不限）
+func (s *AlertSilenceService) matches(silence *models.AlertSilence, record *models.AlertRecord, agent *models.Agent) bool {
+	if len(silence.AgentIDs) > 0 && !slices.Contains(silence.AgentIDs, record.AgentID) {
+		return false
+	}
+	if len(silence.Tags) > 0 {
+		if agent == nil || !slices.ContainsFunc(agent.Tags, func(tag string) bool {
+			return slices.Contains(silence.Tags, tag)
+		}) {
+			return false
+		}
+	}
+	if len(silence.AlertTypes) > 0 && !slices.Contains(silence.AlertTypes, record.AlertType) {
+		return false
+	}
+	if len(silence.MonitorIDs) > 0 && !slices.Contains(silence.MonitorIDs, record.MonitorID) {
+		return false
+	}
+	return true
+}
+
+// isActive 判断静默在指定时间是否生效
+func (s *AlertSilenceService) isActive(silence *models.AlertSilence, now time.Time) bool {
+	if !silence.Enabled {
+		return false
+	}
+
+	switch silence.Mode {
+	case models.AlertSilenceModeOnce:
+		ms := now.UnixMilli()
+		return ms >= silence.StartsAt && ms < silence.EndsAt
+	case models.AlertSilenceModeWeekly:
+		start, err := time.Parse(silenceClockLayout, silence.StartTime)
+		if err != nil {
+			return false
+		}
+		end, err := time.Parse(silenceClockLayout, silence.EndTime)
+		if err != nil {
+			return false
+		}
+		startMinutes := start.Hour()*60 + start.Minute()
+		endMinutes := end.Hour()*60 + end.Minute()
+		minutes := now.Hour()*60 + now.Minute()
+		weekday := int(now.Weekday())
+
+		if startMinutes < endMinutes {
+			return slices.Contains(silence.Weekdays, weekday) && minutes >= startMinutes && minutes < endMinutes
+		}
+		// 跨天时段：开始当天的开始时间之后，或次日的结束时间之前
+		yesterday := (weekday + 6) % 7
+		return (slices.Contains(silence.Weekdays, weekday) && minutes >= startMinutes) ||
+			(slices.Contains(silence.Weekdays, yesterday) && minutes < endMinutes)
+	default:
+		return false
+	}
+}
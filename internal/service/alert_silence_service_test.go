@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestApplySilenceToResolved(t *testing.T) {
+	s := &AlertSilenceService{}
+	tests := []struct {
+		name   string
+		record models.AlertRecord
+		want   bool
+	}{
+		{"触发时已静默的告警恢复时同样静默", models.AlertRecord{Status: "resolved", Silenced: true}, true},
+		// 静默开始前已通知的告警，恢复通知不能被静默，否则 PagerDuty 等渠道的事件无法自动解决
+		{"触发时已通知的告警恢复时始终通知", models.AlertRecord{Status: "resolved"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Apply(context.Background(), &tt.record, nil); got != tt.want {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertSilenceIsActive(t *testing.T) {
+	s := &AlertSilenceService{}
+	// 2026-01-05 为周一
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+	once := models.AlertSilence{
+		Enabled:  true,
+		Mode:     models.AlertSilenceModeOnce,
+		StartsAt: at(5, 22, 0).UnixMilli(),
+		EndsAt:   at(6, 2, 0).UnixMilli(),
+	}
+	weekly := func(start, end string, weekdays ...int) models.AlertSilence {
+		return models.AlertSilence{Enabled: true, Mode: models.AlertSilenceModeWeekly, StartTime: start, EndTime: end, Weekdays: weekdays}
+	}
+	disabled := once
+	disabled.Enabled = false
+
+	tests := []struct {
+		name    string
+		silence models.AlertSilence
+		now     time.Time
+		want    bool
+	}{
+		{"单次静默开始时生效", once, at(5, 22, 0), true},
+		{"单次静默结束时不再生效", once, at(6, 2, 0), false},
+		{"单次静默开始前", once, at(5, 21, 59), false},
+		{"未启用", disabled, at(5, 23, 0), false},
+		{"每周时段内", weekly("09:00", "18:00", 1), at(5, 9, 0), true},
+		{"每周时段结束", weekly("09:00", "18:00", 1), at(5, 18, 0), false},
+		{"每周时段其他日期", weekly("09:00", "18:00", 1), at(6, 10, 0), false},
+		{"跨天时段当天开始后", weekly("22:00", "02:00", 1), at(5, 23, 30), true},
+		{"跨天时段次日结束前", weekly("22:00", "02:00", 1), at(6, 1, 59), true},
+		{"跨天时段次日结束后", weekly("22:00", "02:00", 1), at(6, 2, 0), false},
+		{"跨天时段当天开始前", weekly("22:00", "02:00", 1), at(5, 1, 0), false},
+		// 周六开始的时段跨到周日凌晨
+		{"跨天时段跨周", weekly("23:00", "01:00", 6), at(11, 0, 30), true},
+		{"开始和结束相同表示全天", weekly("00:00", "00:00", 1), at(5, 12, 0), true},
+		{"时间格式错误", weekly("9点", "18:00", 1), at(5, 10, 0), false},
+		{"未知模式", models.AlertSilence{Enabled: true, Mode: "daily"}, at(5, 10, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.isActive(&tt.silence, tt.now); got != tt.want {
+				t.Errorf("isActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertSilenceMatches(t *testing.T) {
+	s := &AlertSilenceService{}
+	record := &models.AlertRecord{AgentID: "a1", AlertType: "service", MonitorID: "m1"}
+	agent := &models.Agent{ID: "a1", Tags: []string{"prod", "web"}}
+
+	tests := []struct {
+		name    string
+		silence models.AlertSilence
+		agent   *models.Agent
+		want    bool
+	}{
+		{"条件为空时匹配所有告警", models.AlertSilence{}, agent, true},
+		{"探针匹配", models.AlertSilence{AgentIDs: []string{"a2", "a1"}}, agent, true},
+		{"探针不匹配", models.AlertSilence{AgentIDs: []string{"a2"}}, agent, false},
+		{"任一标签匹配", models.AlertSilence{Tags: []string{"db", "web"}}, agent, true},
+		{"标签不匹配", models.AlertSilence{Tags: []string{"db"}}, agent, false},
+		{"按标签匹配时缺少探针信息", models.AlertSilence{Tags: []string{"web"}}, nil, false},
+		{"告警类型不匹配", models.AlertSilence{AlertTypes: []string{"cpu"}}, agent, false},
+		{"监控项匹配", models.AlertSilence{MonitorIDs: []string{"m1"}}, agent, true},
+		{"所有条件同时满足", models.AlertSilence{AgentIDs: []string{"a1"}, Tags: []string{"prod"}, AlertTypes: []string{"service"}, MonitorIDs: []string{"m1"}}, agent, true},
+		{"任一条件不满足", models.AlertSilence{AgentIDs: []string{"a1"}, AlertTypes: []string{"service"}, MonitorIDs: []string{"m2"}}, agent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.matches(&tt.silence, record, tt.agent); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,588 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+// 安全检查状态
+const (
+	AuditStatusPass = "pass"
+	AuditStatusFail = "fail"
+	AuditStatusWarn = "warn"
+	AuditStatusSkip = "skip"
+)
+
+// 风险严重程度
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// severityScores 各严重程度对应的风险分值
+var severityScores = map[string]int{
+	SeverityCritical: 40,
+	SeverityHigh:     20,
+	SeverityMedium:   10,
+	SeverityLow:      3,
+}
+
+// severityOrder 严重程度排序权重（数字越大越严重）
+var severityOrder = map[string]int{
+	SeverityCritical: 4,
+	SeverityHigh:     3,
+	SeverityMedium:   2,
+	SeverityLow:      1,
+}
+
+const (
+	// 威胁等级阈值（与 Agent 端历史评分配置保持一致）
+	threatCriticalScore = 80
+	threatHighScore     = 50
+	threatMediumScore   = 20
+
+	maxAuditRecommendations = 20
+	maxAuditEvidenceItems   = 10
+)
+
+// auditCategory 安全检查类别
+type auditCategory struct {
+	Name  string                                                               // 类别标识
+	Title string                                                               // 类别名称
+	Check func(inventory *protocol.AssetInventory) []protocol.SecurityCheckSub // 检查函数，数据缺失时返回 nil
+}
+
+// AuditAnalyzer VPS安全分析引擎（Server端根据Agent采集的资产清单进行安全判断）
+type AuditAnalyzer struct {
+	categories []auditCategory
+}
+
+func NewAuditAnalyzer() *AuditAnalyzer {
+	return &AuditAnalyzer{
+		categories: []auditCategory{
+			{Name: "ssh_security", Title: "SSH安全配置", Check: checkSSHSecurity},
+			{Name: "system_accounts", Title: "系统账户与Sudo权限", Check: checkSystemAccounts},
+			{Name: "listening_ports", Title: "监听端口", Check: checkListeningPorts},
+			{Name: "suspicious_files", Title: "临时目录可执行文件", Check: checkTmpExecutables},
+			{Name: "cron_jobs", Title: "定时任务", Check: checkCronJobs},
+			{Name: "kernel_params", Title: "内核安全参数", Check: checkKernelParams},
+		},
+	}
+}
+
+// Analyze 对审计结果进行安全分析，extraChecks 为规则包等外部检查结果
+func (a *AuditAnalyzer) Analyze(auditID string, result *protocol.VPSAuditResult, extraChecks ...protocol.SecurityCheck) *protocol.VPSAuditAnalysis {
+	inventory := &result.AssetInventory
+
+	var checks []protocol.SecurityCheck
+	for _, category := range a.categories {
+		checks = append(checks, buildSecurityCheck(category.Name, category.Title, category.Check(inventory)))
+	}
+	checks = append(checks, extraChecks...)
+
+	return summarizeSecurityChecks(auditID, checks)
+}
+
+// buildSecurityCheck 根据子检查项汇总类别检查结果
+func buildSecurityCheck(name, title string, details []protocol.SecurityCheckSub) protocol.SecurityCheck {
+	if details == nil {
+		return protocol.SecurityCheck{
+			Category: name,
+			Status:   AuditStatusSkip,
+			Message:  fmt.Sprintf("%s: 未采集到相关数据", title),
+		}
+	}
+
+	var failCount, warnCount int
+	for _, detail := range details {
+		switch detail.Status {
+		case AuditStatusFail:
+			failCount++
+		case AuditStatusWarn:
+			warnCount++
+		}
+	}
+
+	check := protocol.SecurityCheck{
+		Category: name,
+		Details:  details,
+	}
+	switch {
+	case failCount > 0:
+		check.Status = AuditStatusFail
+		check.Message = fmt.Sprintf("%s: 发现 %d 项风险，%d 项警告", title, failCount, warnCount)
+	case warnCount > 0:
+		check.Status = AuditStatusWarn
+		check.Message = fmt.Sprintf("%s: 发现 %d 项警告", title, warnCount)
+	default:
+		check.Status = AuditStatusPass
+		check.Message = fmt.Sprintf("%s: 检查通过", title)
+	}
+	return check
+}
+
+// summarizeSecurityChecks 计算风险评分、威胁等级与修复建议
+func summarizeSecurityChecks(auditID string, checks []protocol.SecurityCheck) *protocol.VPSAuditAnalysis {
+	analysis := &protocol.VPSAuditAnalysis{
+		AuditID:        auditID,
+		SecurityChecks: checks,
+		SeverityCounts: map[string]int{},
+		AnalyzedAt:     time.Now().UnixMilli(),
+	}
+
+	type recommendation struct {
+		severity string
+		text     string
+	}
+	var recommendations []recommendation
+	seen := make(map[string]bool)
+
+	score := 0
+	highest := ""
+	for _, check := range checks {
+		for _, detail := range check.Details {
+			if detail.Status != AuditStatusFail && detail.Status != AuditStatusWarn {
+				continue
+			}
+			analysis.SeverityCounts[detail.Severity]++
+			score += severityScores[detail.Severity]
+			if severityOrder[detail.Severity] > severityOrder[highest] {
+				highest = detail.Severity
+			}
+			if detail.Remediation != "" && !seen[detail.Remediation] {
+				seen[detail.Remediation] = true
+				recommendations = append(recommendations, recommendation{severity: detail.Severity, text: detail.Remediation})
+			}
+		}
+	}
+
+	if score > 100 {
+		score = 100
+	}
+	analysis.RiskScore = score
+	analysis.ThreatLevel = threatLevelOf(score, highest)
+
+	// 按严重程度排序修复建议
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return severityOrder[recommendations[i].severity] > severityOrder[recommendations[j].severity]
+	})
+	for i, r := range recommendations {
+		if i >= maxAuditRecommendations {
+			break
+		}
+		analysis.Recommendations = append(analysis.Recommendations, r.text)
+	}
+
+	return analysis
+}
+
+// threatLevelOf 根据风险评分计算威胁等级，存在严重问题时至少为 high
+func threatLevelOf(score int, highestSeverity string) string {
+	level := SeverityLow
+	switch {
+	case score >= threatCriticalScore:
+		level = SeverityCritical
+	case score >= threatHighScore:
+		level = SeverityHigh
+	case score >= threatMediumScore:
+		level = SeverityMedium
+	}
+	if highestSeverity == SeverityCritical && severityOrder[level] < severityOrder[SeverityHigh] {
+		level = SeverityHigh
+	}
+	return level
+}
+
+// newFinding 创建一个风险项（low 级别视为警告，其余视为失败）
+func newFinding(name, severity, message, evidence, remediation string) protocol.SecurityCheckSub {
+	status := AuditStatusFail
+	if severity == SeverityLow {
+		status = AuditStatusWarn
+	}
+	return protocol.SecurityCheckSub{
+		Name:        name,
+		Status:      status,
+		Severity:    severity,
+		Message:     message,
+		Evidence:    evidence,
+		Remediation: remediation,
+	}
+}
+
+// newPass 创建一个通过项
+func newPass(name, message string) protocol.SecurityCheckSub {
+	return protocol.SecurityCheckSub{
+		Name:    name,
+		Status:  AuditStatusPass,
+		Message: message,
+	}
+}
+
+// joinEvidence 拼接证据信息，超过上限时截断
+func joinEvidence(items []string) string {
+	if len(items) > maxAuditEvidenceItems {
+		return strings.Join(items[:maxAuditEvidenceItems], "\n") + fmt.Sprintf("\n... 共 %d 项", len(items))
+	}
+	return strings.Join(items, "\n")
+}
+
+// ==================== SSH 安全配置 ====================
+
+func checkSSHSecurity(inventory *protocol.AssetInventory) []protocol.SecurityCheckSub {
+	if inventory.UserAssets == nil || inventory.UserAssets.SSHConfig == nil {
+		return nil
+	}
+	cfg := inventory.UserAssets.SSHConfig
+	details := []protocol.SecurityCheckSub{}
+
+	switch cfg.PermitRootLogin {
+	case "yes":
+		details = append(details, newFinding("permit_root_login", SeverityHigh,
+			"SSH 允许 root 使用密码直接登录",
+			"PermitRootLogin "+cfg.PermitRootLogin,
+			"在 sshd_config 中设置 PermitRootLogin no 或 prohibit-password，并使用普通用户 + sudo 管理服务器"))
+	default:
+		details = append(details, newPass("permit_root_login", fmt.Sprintf("PermitRootLogin 为 %s", cfg.PermitRootLogin)))
+	}
+
+	if cfg.PermitEmptyPasswords {
+		details = append(details, newFinding("permit_empty_passwords", SeverityCritical,
+			"SSH 允许空密码登录",
+			"PermitEmptyPasswords yes",
+			"在 sshd_config 中设置 PermitEmptyPasswords no 并重启 sshd"))
+	} else {
+		details = append(details, newPass("permit_empty_passwords", "不允许空密码登录"))
+	}
+
+	if cfg.PasswordAuthentication {
+		details = append(details, newFinding("password_authentication", SeverityMedium,
+			"SSH 启用了密码认证，存在被暴力破解的风险",
+			"PasswordAuthentication yes",
+			"配置公钥登录后，在 sshd_config 中设置 PasswordAuthentication no"))
+	} else {
+		details = append(details, newPass("password_authentication", "已禁用密码认证"))
+	}
+
+	if cfg.MaxAuthTries > 6 {
+		details = append(details, newFinding("max_auth_tries", SeverityLow,
+			fmt.Sprintf("SSH 单连接最大认证次数为 %d，过高", cfg.MaxAuthTries),
+			fmt.Sprintf("MaxAuthTries %d", cfg.MaxAuthTries),
+			"在 sshd_config 中将 MaxAuthTries 设置为 3~6"))
+	}
+
+	if cfg.X11Forwarding {
+		details = append(details, newFinding("x11_forwarding", SeverityLow,
+			"SSH 启用了 X11 转发",
+			"X11Forwarding yes",
+			"如无图形转发需求，在 sshd_config 中设置 X11Forwarding no"))
+	}
+
+	return details
+}
+
+// ==================== 系统账户与 Sudo ====================
+
+func checkSystemAccounts(inventory *protocol.AssetInventory) []protocol.SecurityCheckSub {
+	if inventory.UserAssets == nil {
+		return nil
+	}
+	details := []protocol.SecurityCheckSub{}
+
+	var rootEquivUsers []string
+	for _, user := range inventory.UserAssets.SystemUsers {
+		if user.IsRootEquiv && user.Username != "root" {
+			rootEquivUsers = append(rootEquivUsers, fmt.Sprintf("%s (uid=%s, shell=%s)", user.Username, user.UID, user.Shell))
+		}
+	}
+	if len(rootEquivUsers) > 0 {
+		details = append(details, newFinding("root_equivalent_users", SeverityCritical,
+			fmt.Sprintf("发现 %d 个 UID=0 的非 root 账户", len(rootEquivUsers)),
+			joinEvidence(rootEquivUsers),
+			"检查 /etc/passwd 中 UID 为 0 的非 root 账户，确认来源后删除或修改其 UID"))
+	} else {
+		details = append(details, newPass("root_equivalent_users", "未发现 UID=0 的非 root 账户"))
+	}
+
+	var noPasswdUsers []string
+	for _, sudoUser := range inventory.UserAssets.SudoUsers {
+		if sudoUser.NoPasswd && sudoUser.Username != "root" {
+			noPasswdUsers = append(noPasswdUsers, sudoUser.Rules)
+		}
+	}
+	if len(noPasswdUsers) > 0 {
+		details = append(details, newFinding("sudo_nopasswd", SeverityHigh,
+			fmt.Sprintf("发现 %d 条免密 sudo 规则", len(noPasswdUsers)),
+			joinEvidence(noPasswdUsers),
+			"移除 sudoers 中不必要的 NOPASSWD 规则，仅为自动化账户保留最小权限命令"))
+	} else {
+		details = append(details, newPass("sudo_nopasswd", "未发现免密 sudo 规则"))
+	}
+
+	return details
+}
+
+// ==================== 监听端口 ====================
+
+// riskyPublicPort 对公网暴露存在风险的服务端口
+type riskyPublicPort struct {
+	Service  string
+	Severity string
+}
+
+var riskyPublicPorts = map[uint32]riskyPublicPort{
+	21:    {Service: "FTP", Severity: SeverityMedium},
+	23:    {Service: "Telnet", Severity: SeverityHigh},
+	445:   {Service: "SMB", Severity: SeverityHigh},
+	2375:  {Service: "Docker API(未加密)", Severity: SeverityCritical},
+	3306:  {Service: "MySQL", Severity: SeverityHigh},
+	3389:  {Service: "RDP", Severity: SeverityMedium},
+	5432:  {Service: "PostgreSQL", Severity: SeverityHigh},
+	5900:  {Service: "VNC", Severity: SeverityHigh},
+	6379:  {Service: "Redis", Severity: SeverityHigh},
+	9200:  {Service: "Elasticsearch", Severity: SeverityHigh},
+	11211: {Service: "Memcached", Severity: SeverityHigh},
+	27017: {Service: "MongoDB", Severity: SeverityHigh},
+}
+
+// backdoorPorts 常见后门/黑客工具端口
+var backdoorPorts = map[uint32]string{
+	4444:  "Metasploit默认端口",
+	1337:  "黑客常用端口",
+	31337: "黑客常用端口",
+}
+
+func checkListeningPorts(inventory *protocol.AssetInventory) []protocol.SecurityCheckSub {
+	if inventory.NetworkAssets == nil {
+		return nil
+	}
+	details := []protocol.SecurityCheckSub{}
+
+	var publicCount int
+	seen := make(map[string]bool)
+	for _, port := range inventory.NetworkAssets.ListeningPorts {
+		key := fmt.Sprintf("%s/%d", port.Protocol, port.Port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		evidence := fmt.Sprintf("%s %s:%d pid=%d %s", port.Protocol, port.Address, port.Port, port.ProcessPID, port.ProcessName)
+
+		if desc, ok := backdoorPorts[port.Port]; ok {
+			details = append(details, newFinding(fmt.Sprintf("backdoor_port_%d", port.Port), SeverityCritical,
+				fmt.Sprintf("端口 %d 正在监听（%s）", port.Port, desc),
+				evidence,
+				"确认监听该端口的进程来源，如非业务需要立即终止进程并排查入侵痕迹"))
+			continue
+		}
+
+		if !port.IsPublic {
+			continue
+		}
+		publicCount++
+
+		if risky, ok := riskyPublicPorts[port.Port]; ok {
+			details = append(details, newFinding(fmt.Sprintf("public_port_%d", port.Port), risky.Severity,
+				fmt.Sprintf("%s 服务端口 %d 对公网开放", risky.Service, port.Port),
+				evidence,
+				fmt.Sprintf("将 %s 绑定到 127.0.0.1 或内网地址，或通过防火墙限制来源 IP", risky.Service)))
+		}
+	}
+
+	if len(details) == 0 {
+		details = append(details, newPass("listening_ports", fmt.Sprintf("共 %d 个公网监听端口，未发现高危服务", publicCount)))
+	}
+	return details
+}
+
+// ==================== 临时目录可执行文件 ====================
+
+func checkTmpExecutables(inventory *protocol.AssetInventory) []protocol.SecurityCheckSub {
+	if inventory.FileAssets == nil {
+		return nil
+	}
+
+	var shmFiles, tmpFiles []string
+	for _, file := range inventory.FileAssets.TmpExecutables {
+		evidence := fmt.Sprintf("%s (%d bytes, owner=%s)", file.Path, file.Size, file.Owner)
+		if strings.HasPrefix(file.Path, "/dev/shm/") {
+			shmFiles = append(shmFiles, evidence)
+		} else {
+			tmpFiles = append(tmpFiles, evidence)
+		}
+	}
+
+	details := []protocol.SecurityCheckSub{}
+	if len(shmFiles) > 0 {
+		details = append(details, newFinding("shm_executables", SeverityCritical,
+			fmt.Sprintf("/dev/shm 中发现 %d 个可执行文件", len(shmFiles)),
+			joinEvidence(shmFiles),
+			"/dev/shm 常被恶意程序用于驻留，检查文件来源并删除，必要时以 noexec 挂载"))
+	}
+	if len(tmpFiles) > 0 {
+		details = append(details, newFinding("tmp_executables", SeverityHigh,
+			fmt.Sprintf("临时目录中发现 %d 个可执行文件", len(tmpFiles)),
+			joinEvidence(tmpFiles),
+			"检查临时目录中可执行文件的来源，删除可疑文件，并考虑以 noexec 挂载 /tmp"))
+	}
+	if len(details) == 0 {
+		details = append(details, newPass("tmp_executables", "临时目录中未发现可执行文件"))
+	}
+	return details
+}
+
+// ==================== 定时任务 ====================
+
+// suspiciousCronPattern 可疑定时任务命令特征
+type suspiciousCronPattern struct {
+	Keywords []string // 全部命中才算匹配
+	Severity string
+	Reason   string
+}
+
+var suspiciousCronPatterns = []suspiciousCronPattern{
+	{Keywords: []string{"/dev/tcp/"}, Severity: SeverityCritical, Reason: "疑似反弹 Shell"},
+	{Keywords: []string{"curl", "| sh"}, Severity: SeverityCritical, Reason: "下载并执行远程脚本"},
+	{Keywords: []string{"curl", "|sh"}, Severity: SeverityCritical, Reason: "下载并执行远程脚本"},
+	{Keywords: []string{"curl", "| bash"}, Severity: SeverityCritical, Reason: "下载并执行远程脚本"},
+	{Keywords: []string{"wget", "| sh"}, Severity: SeverityCritical, Reason: "下载并执行远程脚本"},
+	{Keywords: []string{"wget", "|sh"}, Severity: SeverityCritical, Reason: "下载并执行远程脚本"},
+	{Keywords: []string{"wget", "| bash"}, Severity: SeverityCritical, Reason: "下载并执行远程脚本"},
+	{Keywords: []string{"base64", "-d"}, Severity: SeverityHigh, Reason: "执行 Base64 编码的内容"},
+	{Keywords: []string{"nc ", "-e"}, Severity: SeverityCritical, Reason: "疑似 netcat 反弹 Shell"},
+	{Keywords: []string{"/dev/shm/"}, Severity: SeverityHigh, Reason: "执行 /dev/shm 中的程序"},
+	{Keywords: []string{"/tmp/"}, Severity: SeverityMedium, Reason: "执行临时目录中的程序"},
+}
+
+func checkCronJobs(inventory *protocol.AssetInventory) []protocol.SecurityCheckSub {
+	if inventory.FileAssets == nil {
+		return nil
+	}
+	details := []protocol.SecurityCheckSub{}
+
+	for _, job := range inventory.FileAssets.CronJobs {
+		command := strings.ToLower(job.Command)
+		for _, pattern := range suspiciousCronPatterns {
+			matched := true
+			for _, keyword := range pattern.Keywords {
+				if !strings.Contains(command, keyword) {
+					matched = false
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+			details = append(details, newFinding("suspicious_cron", pattern.Severity,
+				fmt.Sprintf("用户 %s 的定时任务存在可疑命令：%s", job.User, pattern.Reason),
+				fmt.Sprintf("%s %s (%s)", job.Schedule, job.Command, job.FilePath),
+				"确认该定时任务的用途，如非管理员添加请删除并排查入侵来源"))
+			break
+		}
+	}
+
+	if len(details) == 0 {
+		details = append(details, newPass("cron_jobs", fmt.Sprintf("共 %d 个定时任务，未发现可疑命令", len(inventory.FileAssets.CronJobs))))
+	}
+	return details
+}
+
+// ==================== 内核安全参数 ====================
+
+// kernelParamRule 内核参数期望值
+type kernelParamRule struct {
+	Param       string
+	Expected    string
+	Severity    string
+	Message     string
+	Remediation string
+}
+
+var kernelParamRules = []kernelParamRule{
+	{
+		Param:       "kernel.randomize_va_space",
+		Expected:    "2",
+		Severity:    SeverityMedium,
+		Message:     "未启用完整的地址空间布局随机化(ASLR)",
+		Remediation: "执行 sysctl -w kernel.randomize_va_space=2 并写入 /etc/sysctl.conf",
+	},
+	{
+		Param:       "net.ipv4.ip_forward",
+		Expected:    "0",
+		Severity:    SeverityLow,
+		Message:     "已开启 IPv4 转发（容器或路由场景可忽略）",
+		Remediation: "如非路由/容器宿主机，执行 sysctl -w net.ipv4.ip_forward=0",
+	},
+	{
+		Param:       "kernel.dmesg_restrict",
+		Expected:    "1",
+		Severity:    SeverityLow,
+		Message:     "普通用户可读取内核日志",
+		Remediation: "执行 sysctl -w kernel.dmesg_restrict=1",
+	},
+	{
+		Param:       "kernel.kptr_restrict",
+		Expected:    "1",
+		Severity:    SeverityLow,
+		Message:     "内核符号地址未隐藏",
+		Remediation: "执行 sysctl -w kernel.kptr_restrict=1",
+	},
+	{
+		Param:       "kernel.yama.ptrace_scope",
+		Expected:    "1",
+		Severity:    SeverityLow,
+		Message:     "未限制 ptrace 附加任意进程",
+		Remediation: "执行 sysctl -w kernel.yama.ptrace_scope=1",
+	},
+}
+
+func checkKernelParams(inventory *protocol.AssetInventory) []protocol.SecurityCheckSub {
+	if inventory.KernelAssets == nil || len(inventory.KernelAssets.KernelParameters) == 0 {
+		return nil
+	}
+	params := inventory.KernelAssets.KernelParameters
+	details := []protocol.SecurityCheckSub{}
+
+	for _, rule := range kernelParamRules {
+		value, ok := params[rule.Param]
+		if !ok {
+			continue
+		}
+		if kernelParamSatisfied(rule, value) {
+			details = append(details, newPass(rule.Param, fmt.Sprintf("%s = %s", rule.Param, value)))
+			continue
+		}
+		severity := rule.Severity
+		// ASLR 完全关闭时提升风险等级
+		if rule.Param == "kernel.randomize_va_space" && value == "0" {
+			severity = SeverityHigh
+		}
+		details = append(details, newFinding(rule.Param, severity, rule.Message,
+			fmt.Sprintf("%s = %s", rule.Param, value), rule.Remediation))
+	}
+	return details
+}
+
+// kernelParamSatisfied 判断内核参数是否满足期望（限制类参数取值越大越严格），非数值参数精确比较
+func kernelParamSatisfied(rule kernelParamRule, value string) bool {
+	value = strings.TrimSpace(value)
+	expected, err := strconv.ParseInt(rule.Expected, 10, 64)
+	if err != nil {
+		return value == rule.Expected
+	}
+	actual, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value == rule.Expected
+	}
+	if expected == 0 {
+		return actual == 0
+	}
+	return actual >= expected
+}
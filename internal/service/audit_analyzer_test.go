@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+func TestKernelParamSatisfied(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		value    string
+		want     bool
+	}{
+		{"等于期望值", "1", "1", true},
+		{"大于期望值", "1", "2", true},
+		{"小于期望值", "2", "1", false},
+		{"多位数按数值比较", "10", "9", false},
+		{"多位数大于期望值", "20", "100", true},
+		{"期望为零时必须为零", "0", "0", true},
+		{"期望为零时非零不满足", "0", "1", false},
+		{"忽略首尾空白", "2", " 2\n", true},
+		{"非数值取值", "1", "enabled", false},
+		{"非数值期望精确比较", "enforcing", "enforcing", true},
+		{"非数值期望不一致", "enforcing", "permissive", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := kernelParamRule{Param: "test", Expected: tt.expected}
+			if got := kernelParamSatisfied(rule, tt.value); got != tt.want {
+				t.Errorf("kernelParamSatisfied(%q, %q) = %v, want %v", tt.expected, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreatLevelOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		score   int
+		highest string
+		want    string
+	}{
+		{"无风险", 0, "", SeverityLow},
+		{"中等评分", 20, SeverityMedium, SeverityMedium},
+		{"高评分", 50, SeverityHigh, SeverityHigh},
+		{"严重评分", 80, SeverityCritical, SeverityCritical},
+		{"存在严重问题时至少为 high", 40, SeverityCritical, SeverityHigh},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := threatLevelOf(tt.score, tt.highest); got != tt.want {
+				t.Errorf("threatLevelOf(%d, %q) = %q, want %q", tt.score, tt.highest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditAnalyzerAnalyze(t *testing.T) {
+	result := &protocol.VPSAuditResult{
+		AssetInventory: protocol.AssetInventory{
+			UserAssets: &protocol.UserAssets{
+				SSHConfig: &protocol.SSHConfig{
+					PermitRootLogin:      "yes",
+					PermitEmptyPasswords: true,
+				},
+			},
+			KernelAssets: &protocol.KernelAssets{
+				KernelParameters: map[string]string{
+					"kernel.randomize_va_space": "2",
+					"net.ipv4.ip_forward":       "1",
+				},
+			},
+		},
+	}
+
+	analysis := NewAuditAnalyzer().Analyze("audit-1", result)
+
+	checks := make(map[string]protocol.SecurityCheck)
+	for _, check := range analysis.SecurityChecks {
+		checks[check.Category] = check
+	}
+	if checks["ssh_security"].Status != AuditStatusFail {
+		t.Errorf("SSH 检查应失败: %+v", checks["ssh_security"])
+	}
+	if checks["kernel_params"].Status != AuditStatusWarn {
+		t.Errorf("内核参数检查应为警告: %+v", checks["kernel_params"])
+	}
+	if checks["listening_ports"].Status != AuditStatusSkip {
+		t.Errorf("未采集端口数据时应跳过: %+v", checks["listening_ports"])
+	}
+
+	// permit_root_login(high 20) + permit_empty_passwords(critical 40) + ip_forward(low 3)
+	wantScore := severityScores[SeverityHigh] + severityScores[SeverityCritical] + severityScores[SeverityLow]
+	if analysis.RiskScore != wantScore {
+		t.Errorf("RiskScore = %d, want %d", analysis.RiskScore, wantScore)
+	}
+	if analysis.ThreatLevel != SeverityHigh {
+		t.Errorf("ThreatLevel = %q, want %q", analysis.ThreatLevel, SeverityHigh)
+	}
+	if analysis.SeverityCounts[SeverityCritical] != 1 {
+		t.Errorf("SeverityCounts = %v", analysis.SeverityCounts)
+	}
+	if len(analysis.Recommendations) == 0 || analysis.Recommendations[0] != "在 sshd_config 中设置 PermitEmptyPasswords no 并重启 sshd" {
+		t.Errorf("修复建议应按严重程度排序: %v", analysis.Recommendations)
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+// AuditDiff 两次审计结果的差异
+type AuditDiff struct {
+	BaseID       int64              `json:"baseId"`       // 基准审计ID（较早）
+	TargetID     int64              `json:"targetId"`     // 对比审计ID（较新）
+	BaseTime     int64              `json:"baseTime"`     // 基准审计时间
+	TargetTime   int64              `json:"targetTime"`   // 对比审计时间
+	Sections     []AuditDiffSection `json:"sections"`     // 各类资产差异
+	TotalAdded   int                `json:"totalAdded"`   // 新增项总数
+	TotalRemoved int                `json:"totalRemoved"` // 移除项总数
+}
+
+// AuditDiffSection 单类资产差异
+type AuditDiffSection struct {
+	Name    string   `json:"name"`    // 类别标识
+	Title   string   `json:"title"`   // 类别名称
+	Added   []string `json:"added"`   // 新增项
+	Removed []string `json:"removed"` // 移除项
+}
+
+// HasDrift 是否存在变化
+func (d *AuditDiff) HasDrift() bool {
+	return d.TotalAdded > 0 || d.TotalRemoved > 0
+}
+
+// auditDiffExtractor 资产项提取器，返回每一项的唯一描述
+type auditDiffExtractor struct {
+	Name    string
+	Title   string
+	Extract func(inventory *protocol.AssetInventory) []string
+}
+
+var auditDiffExtractors = []auditDiffExtractor{
+	{Name: "listening_ports", Title: "监听端口", Extract: extractListeningPorts},
+	{Name: "users", Title: "系统用户", Extract: extractUsers},
+	{Name: "sudoers", Title: "Sudo规则", Extract: extractSudoers},
+	{Name: "ssh_keys", Title: "SSH密钥", Extract: extractSSHKeys},
+	{Name: "cron_jobs", Title: "定时任务", Extract: extractCronJobs},
+	{Name: "systemd_services", Title: "Systemd服务", Extract: extractSystemdServices},
+	{Name: "kernel_modules", Title: "内核模块", Extract: extractKernelModules},
+	{Name: "tmp_executables", Title: "临时目录可执行文件", Extract: extractTmpExecutables},
+}
+
+// DiffAuditResult 对比两次审计结果
+func DiffAuditResult(base, target *protocol.VPSAuditResult) *AuditDiff {
+	diff := &AuditDiff{
+		Sections: make([]AuditDiffSection, 0, len(auditDiffExtractors)),
+	}
+	for _, extractor := range auditDiffExtractors {
+		added, removed := diffStringSets(extractor.Extract(&base.AssetInventory), extractor.Extract(&target.AssetInventory))
+		diff.Sections = append(diff.Sections, AuditDiffSection{
+			Name:    extractor.Name,
+			Title:   extractor.Title,
+			Added:   added,
+			Removed: removed,
+		})
+		diff.TotalAdded += len(added)
+		diff.TotalRemoved += len(removed)
+	}
+	return diff
+}
+
+// diffStringSets 计算集合差异
+func diffStringSets(base, target []string) (added, removed []string) {
+	baseSet := make(map[string]bool, len(base))
+	for _, item := range base {
+		baseSet[item] = true
+	}
+	targetSet := make(map[string]bool, len(target))
+	for _, item := range target {
+		targetSet[item] = true
+	}
+
+	added = []string{}
+	removed = []string{}
+	for item := range targetSet {
+		if !baseSet[item] {
+			added = append(added, item)
+		}
+	}
+	for item := range baseSet {
+		if !targetSet[item] {
+			removed = append(removed, item)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// Summary 生成差异摘要（用于通知），每类最多列出 limit 项
+func (d *AuditDiff) Summary(limit int) string {
+	var lines []string
+	for _, section := range d.Sections {
+		if len(section.Added) == 0 && len(section.Removed) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: 新增 %d，移除 %d", section.Title, len(section.Added), len(section.Removed)))
+		lines = append(lines, summarizeDiffItems("+", section.Added, limit)...)
+		lines = append(lines, summarizeDiffItems("-", section.Removed, limit)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func summarizeDiffItems(sign string, items []string, limit int) []string {
+	var lines []string
+	for i, item := range items {
+		if i >= limit {
+			lines = append(lines, fmt.Sprintf("  %s ... 等 %d 项", sign, len(items)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s", sign, item))
+	}
+	return lines
+}
+
+func extractListeningPorts(inventory *protocol.AssetInventory) []string {
+	if inventory.NetworkAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, port := range inventory.NetworkAssets.ListeningPorts {
+		// 不包含 PID，避免进程重启造成误报
+		items = append(items, fmt.Sprintf("%s %s:%d (%s)", port.Protocol, port.Address, port.Port, port.ProcessName))
+	}
+	return items
+}
+
+func extractUsers(inventory *protocol.AssetInventory) []string {
+	if inventory.UserAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, user := range inventory.UserAssets.SystemUsers {
+		items = append(items, fmt.Sprintf("%s (uid=%s, shell=%s)", user.Username, user.UID, user.Shell))
+	}
+	return items
+}
+
+func extractSudoers(inventory *protocol.AssetInventory) []string {
+	if inventory.UserAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, sudoUser := range inventory.UserAssets.SudoUsers {
+		items = append(items, fmt.Sprintf("%s: %s", sudoUser.Username, sudoUser.Rules))
+	}
+	return items
+}
+
+func extractSSHKeys(inventory *protocol.AssetInventory) []string {
+	if inventory.UserAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, key := range inventory.UserAssets.SSHKeys {
+		items = append(items, fmt.Sprintf("%s %s %s %s", key.Username, key.KeyType, key.Fingerprint, key.Comment))
+	}
+	return items
+}
+
+func extractCronJobs(inventory *protocol.AssetInventory) []string {
+	if inventory.FileAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, job := range inventory.FileAssets.CronJobs {
+		items = append(items, fmt.Sprintf("[%s] %s %s", job.User, job.Schedule, job.Command))
+	}
+	return items
+}
+
+func extractSystemdServices(inventory *protocol.AssetInventory) []string {
+	if inventory.FileAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, svc := range inventory.FileAssets.SystemdServices {
+		items = append(items, fmt.Sprintf("%s (%s)", svc.Name, svc.ExecStart))
+	}
+	return items
+}
+
+func extractKernelModules(inventory *protocol.AssetInventory) []string {
+	if inventory.KernelAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, module := range inventory.KernelAssets.LoadedModules {
+		items = append(items, module.Name)
+	}
+	return items
+}
+
+func extractTmpExecutables(inventory *protocol.AssetInventory) []string {
+	if inventory.FileAssets == nil {
+		return nil
+	}
+	var items []string
+	for _, file := range inventory.FileAssets.TmpExecutables {
+		items = append(items, fmt.Sprintf("%s (%d bytes)", file.Path, file.Size))
+	}
+	return items
+}
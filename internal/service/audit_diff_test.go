@@ -0,0 +1,105 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+func TestDiffStringSets(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        []string
+		target      []string
+		wantAdded   []string
+		wantRemoved []string
+	}{
+		{"没有变化", []string{"a", "b"}, []string{"b", "a"}, []string{}, []string{}},
+		{"新增和移除", []string{"a", "b"}, []string{"b", "c"}, []string{"c"}, []string{"a"}},
+		{"重复项只计一次", []string{"a", "a"}, []string{"a", "b", "b"}, []string{"b"}, []string{}},
+		{"基准为空", nil, []string{"z", "y"}, []string{"y", "z"}, []string{}},
+		{"对比为空", []string{"x"}, nil, []string{}, []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := diffStringSets(tt.base, tt.target)
+			if !reflect.DeepEqual(added, tt.wantAdded) || !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("diffStringSets() = %v, %v, want %v, %v", added, removed, tt.wantAdded, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestDiffAuditResult(t *testing.T) {
+	base := &protocol.VPSAuditResult{
+		AssetInventory: protocol.AssetInventory{
+			NetworkAssets: &protocol.NetworkAssets{
+				ListeningPorts: []protocol.ListeningPort{
+					{Protocol: "tcp", Address: "0.0.0.0", Port: 22, ProcessPID: 100, ProcessName: "sshd"},
+				},
+			},
+			FileAssets: &protocol.FileAssets{
+				CronJobs: []protocol.CronJob{
+					{User: "root", Schedule: "0 3 * * *", Command: "/usr/local/bin/backup.sh"},
+				},
+			},
+		},
+	}
+	target := &protocol.VPSAuditResult{
+		AssetInventory: protocol.AssetInventory{
+			NetworkAssets: &protocol.NetworkAssets{
+				ListeningPorts: []protocol.ListeningPort{
+					// 进程重启导致 PID 变化不算变化
+					{Protocol: "tcp", Address: "0.0.0.0", Port: 22, ProcessPID: 200, ProcessName: "sshd"},
+					{Protocol: "tcp", Address: "0.0.0.0", Port: 4444, ProcessPID: 300, ProcessName: "nc"},
+				},
+			},
+			// 未采集文件资产时，之前的定时任务视为移除
+		},
+	}
+
+	diff := DiffAuditResult(base, target)
+	if len(diff.Sections) != len(auditDiffExtractors) {
+		t.Fatalf("应包含 %d 类资产，实际 %d 类", len(auditDiffExtractors), len(diff.Sections))
+	}
+	if diff.TotalAdded != 1 || diff.TotalRemoved != 1 || !diff.HasDrift() {
+		t.Errorf("TotalAdded = %d, TotalRemoved = %d", diff.TotalAdded, diff.TotalRemoved)
+	}
+
+	sections := make(map[string]AuditDiffSection)
+	for _, section := range diff.Sections {
+		sections[section.Name] = section
+	}
+	if got := sections["listening_ports"].Added; !reflect.DeepEqual(got, []string{"tcp 0.0.0.0:4444 (nc)"}) {
+		t.Errorf("新增端口 = %v", got)
+	}
+	if got := sections["cron_jobs"].Removed; !reflect.DeepEqual(got, []string{"[root] 0 3 * * * /usr/local/bin/backup.sh"}) {
+		t.Errorf("移除定时任务 = %v", got)
+	}
+
+	summary := diff.Summary(5)
+	for _, want := range []string{"监听端口: 新增 1，移除 0", "  + tcp 0.0.0.0:4444 (nc)", "定时任务: 新增 0，移除 1"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("摘要缺少 %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "系统用户") {
+		t.Errorf("摘要不应包含没有变化的类别:\n%s", summary)
+	}
+
+	if DiffAuditResult(target, target).HasDrift() {
+		t.Errorf("相同结果不应存在变化")
+	}
+}
+
+func TestAuditDiffSummaryLimit(t *testing.T) {
+	diff := &AuditDiff{Sections: []AuditDiffSection{
+		{Title: "系统用户", Added: []string{"a", "b", "c"}, Removed: []string{}},
+	}}
+	want := "系统用户: 新增 3，移除 0\n  + a\n  + b\n  + ... 等 3 项"
+	if got := diff.Summary(2); got != want {
+		t.Errorf("Summary() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,600 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/protocol"
+	"gopkg.in/yaml.v3"
+)
+
+// 规则匹配方式
+const (
+	RuleMatchAll  = "all"  // 所有值都需满足条件（默认）
+	RuleMatchAny  = "any"  // 至少一个值满足条件
+	RuleMatchNone = "none" // 没有值满足条件
+)
+
+// AuditRulePackSpec 规则包定义（YAML）
+//
+//	name: baseline
+//	description: 基础安全基线
+//	rules:
+//	  - id: ssh-permit-root-login
+//	    name: 禁止 root 登录
+//	    severity: high
+//	    target: userAssets.sshConfig.permitRootLogin
+//	    operator: eq
+//	    value: "no"
+//	    remediation: 在 sshd_config 中设置 PermitRootLogin no
+//	  - id: public-ports
+//	    name: 公网端口白名单
+//	    severity: medium
+//	    target: networkAssets.listeningPorts
+//	    where:
+//	      - field: isPublic
+//	        operator: eq
+//	        value: true
+//	    field: port
+//	    operator: in
+//	    value: [22, 80, 443]
+//	  - id: ip-forward
+//	    name: 禁用 IPv4 转发
+//	    severity: low
+//	    target: kernelAssets.kernelParameters["net.ipv4.ip_forward"]
+//	    operator: eq
+//	    value: "0"
+type AuditRulePackSpec struct {
+	Name         string          `yaml:"name"`
+	Description  string          `yaml:"description"`
+	Version      string          `yaml:"version"`
+	EnabledTags  []string        `yaml:"enabledTags"`  // 仅对包含这些标签的探针生效（为空则对所有探针生效）
+	DisabledTags []string        `yaml:"disabledTags"` // 对包含这些标签的探针不生效
+	Rules        []AuditRuleSpec `yaml:"rules"`
+}
+
+// AuditRuleSpec 单条规则定义
+type AuditRuleSpec struct {
+	ID           string               `yaml:"id"`
+	Name         string               `yaml:"name"`
+	Category     string               `yaml:"category"` // 检查类别，为空时使用规则包名称
+	Severity     string               `yaml:"severity"` // critical/high/medium/low
+	Enabled      *bool                `yaml:"enabled"`  // 默认启用
+	EnabledTags  []string             `yaml:"enabledTags"`
+	DisabledTags []string             `yaml:"disabledTags"`
+	Target       string               `yaml:"target"` // AssetInventory 中的字段路径
+	Where        []AuditRuleCondition `yaml:"where"`  // 数组元素过滤条件
+	Field        string               `yaml:"field"`  // 目标元素中的字段
+	Operator     string               `yaml:"operator"`
+	Value        interface{}          `yaml:"value"`
+	Match        string               `yaml:"match"` // all/any/none
+	Message      string               `yaml:"message"`
+	Remediation  string               `yaml:"remediation"`
+}
+
+// AuditRuleCondition 过滤条件
+type AuditRuleCondition struct {
+	Field    string      `yaml:"field"`
+	Operator string      `yaml:"operator"`
+	Value    interface{} `yaml:"value"`
+}
+
+// compiledRulePack 解析后的规则包
+type compiledRulePack struct {
+	ID           string
+	Name         string
+	EnabledTags  []string
+	DisabledTags []string
+	Rules        []*compiledRule
+}
+
+// compiledRule 解析后的规则
+type compiledRule struct {
+	Spec      AuditRuleSpec
+	Category  string
+	Target    []string
+	Where     []*ruleCondition
+	Condition *ruleCondition
+}
+
+// ruleCondition 解析后的条件
+type ruleCondition struct {
+	Field    []string
+	Operator string
+	Value    interface{}
+	Values   []interface{}
+	Regexp   *regexp.Regexp
+}
+
+// ParseAuditRulePack 解析并校验 YAML 规则包
+func ParseAuditRulePack(content []byte) (*AuditRulePackSpec, error) {
+	var spec AuditRulePackSpec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("规则包格式错误: %w", err)
+	}
+	if _, err := compileRulePack("", &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// compileRulePack 编译规则包
+func compileRulePack(id string, spec *AuditRulePackSpec) (*compiledRulePack, error) {
+	if strings.TrimSpace(spec.Name) == "" {
+		return nil, fmt.Errorf("规则包名称不能为空")
+	}
+	if len(spec.Rules) == 0 {
+		return nil, fmt.Errorf("规则包 %s 中没有规则", spec.Name)
+	}
+
+	pack := &compiledRulePack{
+		ID:           id,
+		Name:         spec.Name,
+		EnabledTags:  spec.EnabledTags,
+		DisabledTags: spec.DisabledTags,
+	}
+
+	ids := make(map[string]bool)
+	for i, ruleSpec := range spec.Rules {
+		if ruleSpec.ID == "" {
+			return nil, fmt.Errorf("第 %d 条规则缺少 id", i+1)
+		}
+		if ids[ruleSpec.ID] {
+			return nil, fmt.Errorf("规则 id 重复: %s", ruleSpec.ID)
+		}
+		ids[ruleSpec.ID] = true
+
+		rule, err := compileRule(spec.Name, ruleSpec)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s: %w", ruleSpec.ID, err)
+		}
+		pack.Rules = append(pack.Rules, rule)
+	}
+	return pack, nil
+}
+
+func compileRule(packName string, spec AuditRuleSpec) (*compiledRule, error) {
+	if spec.Severity == "" {
+		spec.Severity = SeverityMedium
+	}
+	if _, ok := severityScores[spec.Severity]; !ok {
+		return nil, fmt.Errorf("不支持的严重程度: %s", spec.Severity)
+	}
+	if spec.Match == "" {
+		spec.Match = RuleMatchAll
+	}
+	if spec.Match != RuleMatchAll && spec.Match != RuleMatchAny && spec.Match != RuleMatchNone {
+		return nil, fmt.Errorf("不支持的匹配方式: %s", spec.Match)
+	}
+	if spec.Name == "" {
+		spec.Name = spec.ID
+	}
+
+	target, err := parseRulePath(spec.Target)
+	if err != nil {
+		return nil, err
+	}
+	if len(target) == 0 {
+		return nil, fmt.Errorf("target 不能为空")
+	}
+
+	condition, err := compileCondition(spec.Field, spec.Operator, spec.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &compiledRule{
+		Spec:      spec,
+		Category:  spec.Category,
+		Target:    target,
+		Condition: condition,
+	}
+	if rule.Category == "" {
+		rule.Category = packName
+	}
+
+	for _, where := range spec.Where {
+		c, err := compileCondition(where.Field, where.Operator, where.Value)
+		if err != nil {
+			return nil, fmt.Errorf("where: %w", err)
+		}
+		rule.Where = append(rule.Where, c)
+	}
+	return rule, nil
+}
+
+func compileCondition(field, operator string, value interface{}) (*ruleCondition, error) {
+	path, err := parseRulePath(field)
+	if err != nil {
+		return nil, err
+	}
+	c := &ruleCondition{
+		Field:    path,
+		Operator: operator,
+		Value:    value,
+	}
+
+	switch operator {
+	case "eq", "ne", "gt", "gte", "lt", "lte", "contains", "not_contains":
+		if value == nil {
+			return nil, fmt.Errorf("操作符 %s 需要 value", operator)
+		}
+	case "in", "not_in":
+		values, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("操作符 %s 的 value 必须为数组", operator)
+		}
+		c.Values = values
+	case "regex", "not_regex":
+		re, err := regexp.Compile(fmt.Sprint(value))
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式错误: %w", err)
+		}
+		c.Regexp = re
+	case "exists", "not_exists":
+	default:
+		return nil, fmt.Errorf("不支持的操作符: %s", operator)
+	}
+	return c, nil
+}
+
+// parseRulePath 解析字段路径，支持 a.b.c 以及 a["b.c"] 形式
+func parseRulePath(path string) ([]string, error) {
+	var segments []string
+	var current strings.Builder
+	for i := 0; i < len(path); i++ {
+		ch := path[i]
+		switch ch {
+		case '.':
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			}
+		case '[':
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("字段路径格式错误: %s", path)
+			}
+			key := strings.Trim(path[i+1:i+end], `"'`)
+			if key != "" {
+				segments = append(segments, key)
+			}
+			i += end
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	}
+	return segments, nil
+}
+
+// tagsApplied 判断规则包/规则是否对指定标签的探针生效
+func tagsApplied(agentTags, enabledTags, disabledTags []string) bool {
+	for _, tag := range disabledTags {
+		if slices.Contains(agentTags, tag) {
+			return false
+		}
+	}
+	if len(enabledTags) == 0 {
+		return true
+	}
+	for _, tag := range enabledTags {
+		if slices.Contains(agentTags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate 对资产清单执行规则包，按类别输出检查结果
+func (p *compiledRulePack) evaluate(agentTags []string, inventory map[string]interface{}) []protocol.SecurityCheck {
+	if !tagsApplied(agentTags, p.EnabledTags, p.DisabledTags) {
+		return nil
+	}
+
+	var categories []string
+	details := make(map[string][]protocol.SecurityCheckSub)
+	for _, rule := range p.Rules {
+		if rule.Spec.Enabled != nil && !*rule.Spec.Enabled {
+			continue
+		}
+		if !tagsApplied(agentTags, rule.Spec.EnabledTags, rule.Spec.DisabledTags) {
+			continue
+		}
+		if _, ok := details[rule.Category]; !ok {
+			categories = append(categories, rule.Category)
+			details[rule.Category] = []protocol.SecurityCheckSub{}
+		}
+		if sub, ok := rule.evaluate(inventory); ok {
+			details[rule.Category] = append(details[rule.Category], sub)
+		}
+	}
+
+	var checks []protocol.SecurityCheck
+	for _, category := range categories {
+		items := details[category]
+		if len(items) == 0 {
+			items = nil
+		}
+		checks = append(checks, buildSecurityCheck(category, category, items))
+	}
+	return checks
+}
+
+// evaluate 执行单条规则，数据未采集时返回 false
+func (r *compiledRule) evaluate(inventory map[string]interface{}) (protocol.SecurityCheckSub, bool) {
+	elements, ok := resolveRulePath(inventory, r.Target)
+	if !ok {
+		return protocol.SecurityCheckSub{}, false
+	}
+
+	// 过滤数组元素
+	var filtered []interface{}
+	for _, element := range elements {
+		matched := true
+		for _, where := range r.Where {
+			if !where.matchElement(element) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, element)
+		}
+	}
+
+	var matched, unmatched []interface{}
+	for _, element := range filtered {
+		if r.Condition.matchElement(element) {
+			matched = append(matched, element)
+		} else {
+			unmatched = append(unmatched, element)
+		}
+	}
+
+	var passed bool
+	var violations []interface{}
+	switch r.Spec.Match {
+	case RuleMatchAny:
+		passed = len(matched) > 0
+		violations = unmatched
+	case RuleMatchNone:
+		passed = len(matched) == 0
+		violations = matched
+	default:
+		passed = len(unmatched) == 0
+		violations = unmatched
+	}
+
+	if passed {
+		return newPass(r.Spec.ID, r.Spec.Name), true
+	}
+
+	message := r.Spec.Message
+	if message == "" {
+		message = fmt.Sprintf("%s: 不符合规则", r.Spec.Name)
+	}
+	evidence := make([]string, 0, len(violations))
+	for _, v := range violations {
+		evidence = append(evidence, formatRuleValue(v))
+	}
+	return newFinding(r.Spec.ID, r.Spec.Severity, message, joinEvidence(evidence), r.Spec.Remediation), true
+}
+
+// resolveRulePath 根据路径取值，遇到数组时展开；路径上的对象不存在时视为未采集
+func resolveRulePath(root interface{}, path []string) ([]interface{}, bool) {
+	current := []interface{}{root}
+	throughArray := false
+	for _, segment := range path {
+		var next []interface{}
+		for _, value := range current {
+			if items, ok := value.([]interface{}); ok {
+				throughArray = true
+				for _, item := range items {
+					if m, ok := item.(map[string]interface{}); ok {
+						if v, ok := m[segment]; ok {
+							next = append(next, v)
+						}
+					}
+				}
+				continue
+			}
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			v, ok := m[segment]
+			if !ok || v == nil {
+				if !throughArray {
+					return nil, false
+				}
+				continue
+			}
+			next = append(next, v)
+		}
+		current = next
+	}
+
+	// 目标为数组时展开元素
+	var result []interface{}
+	for _, value := range current {
+		if items, ok := value.([]interface{}); ok {
+			result = append(result, items...)
+		} else {
+			result = append(result, value)
+		}
+	}
+	return result, true
+}
+
+// matchElement 判断元素（或其字段）是否满足条件
+func (c *ruleCondition) matchElement(element interface{}) bool {
+	var value interface{} = element
+	if len(c.Field) > 0 {
+		values, ok := resolveRulePath(element, c.Field)
+		if !ok || len(values) == 0 {
+			value = nil
+		} else {
+			value = values[0]
+		}
+	}
+	return c.match(value)
+}
+
+func (c *ruleCondition) match(value interface{}) bool {
+	switch c.Operator {
+	case "exists":
+		return value != nil && fmt.Sprint(value) != ""
+	case "not_exists":
+		return value == nil || fmt.Sprint(value) == ""
+	}
+	if value == nil {
+		return c.Operator == "ne" || c.Operator == "not_in" || c.Operator == "not_contains" || c.Operator == "not_regex"
+	}
+
+	switch c.Operator {
+	case "eq":
+		return ruleValueEqual(value, c.Value)
+	case "ne":
+		return !ruleValueEqual(value, c.Value)
+	case "in":
+		return slices.ContainsFunc(c.Values, func(v interface{}) bool { return ruleValueEqual(value, v) })
+	case "not_in":
+		return !slices.ContainsFunc(c.Values, func(v interface{}) bool { return ruleValueEqual(value, v) })
+	case "gt", "gte", "lt", "lte":
+		a, ok1 := ruleValueNumber(value)
+		b, ok2 := ruleValueNumber(c.Value)
+		if !ok1 || !ok2 {
+			return false
+		}
+		switch c.Operator {
+		case "gt":
+			return a > b
+		case "gte":
+			return a >= b
+		case "lt":
+			return a < b
+		default:
+			return a <= b
+		}
+	case "contains":
+		return strings.Contains(fmt.Sprint(value), fmt.Sprint(c.Value))
+	case "not_contains":
+		return !strings.Contains(fmt.Sprint(value), fmt.Sprint(c.Value))
+	case "regex":
+		return c.Regexp.MatchString(fmt.Sprint(value))
+	case "not_regex":
+		return !c.Regexp.MatchString(fmt.Sprint(value))
+	}
+	return false
+}
+
+// ruleValueEqual 比较两个值，数字按数值比较，其余按字符串比较
+func ruleValueEqual(a, b interface{}) bool {
+	if x, ok := ruleValueNumber(a); ok {
+		if y, ok := ruleValueNumber(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func ruleValueNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatRuleValue(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+// inventoryToMap 将资产清单转换为通用结构供规则取值
+// 不经过 JSON 序列化：omitempty 字段同样保留零值（如 false 的 x11Forwarding），避免规则因字段缺失被跳过
+func inventoryToMap(inventory *protocol.AssetInventory) map[string]interface{} {
+	m, _ := toRuleValue(reflect.ValueOf(inventory)).(map[string]interface{})
+	return m
+}
+
+// toRuleValue 按 JSON 字段名将结构体转换为 map，未采集的对象（nil 指针）为 nil，数字统一为 float64
+func toRuleValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toRuleValue(v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		m := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			m[name] = toRuleValue(v.Field(i))
+		}
+		return m
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = toRuleValue(iter.Value())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = toRuleValue(v.Index(i))
+		}
+		return items
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dushixiang/pika/internal/protocol"
+	"gopkg.in/yaml.v3"
+)
+
+func newRulePackTestInventory() *protocol.AssetInventory {
+	return &protocol.AssetInventory{
+		NetworkAssets: &protocol.NetworkAssets{
+			ListeningPorts: []protocol.ListeningPort{
+				{Protocol: "tcp", Address: "0.0.0.0", Port: 22, IsPublic: true},
+				{Protocol: "tcp", Address: "0.0.0.0", Port: 3306, IsPublic: true, ProcessName: "mysqld"},
+				{Protocol: "tcp", Address: "127.0.0.1", Port: 6379},
+			},
+		},
+		UserAssets: &protocol.UserAssets{
+			SSHConfig: &protocol.SSHConfig{
+				Port:            22,
+				PermitRootLogin: "no",
+				// MaxAuthTries 和 X11Forwarding 为零值，JSON 序列化时会被 omitempty 省略
+			},
+		},
+		KernelAssets: &protocol.KernelAssets{
+			KernelParameters: map[string]string{
+				"net.ipv4.ip_forward": "1",
+			},
+		},
+	}
+}
+
+func TestCompiledRuleEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      string
+		collected bool
+		status    string
+	}{
+		{
+			name:      "字符串相等",
+			rule:      "{id: r, target: userAssets.sshConfig.permitRootLogin, operator: eq, value: 'no'}",
+			collected: true,
+			status:    AuditStatusPass,
+		},
+		{
+			name:      "omitempty 省略的 false 字段按零值判断",
+			rule:      "{id: r, target: userAssets.sshConfig.x11Forwarding, operator: eq, value: false}",
+			collected: true,
+			status:    AuditStatusPass,
+		},
+		{
+			name:      "omitempty 省略的数值字段按零值判断",
+			rule:      "{id: r, target: userAssets.sshConfig.maxAuthTries, operator: gt, value: 6}",
+			collected: true,
+			status:    AuditStatusFail,
+		},
+		{
+			name:      "公网端口白名单",
+			rule:      "{id: r, target: networkAssets.listeningPorts, where: [{field: isPublic, operator: eq, value: true}], field: port, operator: in, value: [22, 80, 443]}",
+			collected: true,
+			status:    AuditStatusFail,
+		},
+		{
+			name:      "至少一个满足",
+			rule:      "{id: r, target: networkAssets.listeningPorts, field: processName, operator: eq, value: mysqld, match: any}",
+			collected: true,
+			status:    AuditStatusPass,
+		},
+		{
+			name:      "没有满足",
+			rule:      "{id: r, severity: low, target: networkAssets.listeningPorts, field: port, operator: eq, value: 23, match: none}",
+			collected: true,
+			status:    AuditStatusPass,
+		},
+		{
+			name:      "带点号的键",
+			rule:      `{id: r, severity: low, target: 'kernelAssets.kernelParameters["net.ipv4.ip_forward"]', operator: eq, value: "0"}`,
+			collected: true,
+			status:    AuditStatusWarn,
+		},
+		{
+			name:      "未采集的内核参数",
+			rule:      `{id: r, target: 'kernelAssets.kernelParameters["kernel.kptr_restrict"]', operator: eq, value: "1"}`,
+			collected: false,
+		},
+		{
+			name:      "未采集的资产",
+			rule:      "{id: r, target: fileAssets.tmpExecutables, operator: not_exists}",
+			collected: false,
+		},
+	}
+
+	inventory := inventoryToMap(newRulePackTestInventory())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec AuditRuleSpec
+			if err := yaml.Unmarshal([]byte(tt.rule), &spec); err != nil {
+				t.Fatalf("解析规则失败: %v", err)
+			}
+			rule, err := compileRule("test", spec)
+			if err != nil {
+				t.Fatalf("编译规则失败: %v", err)
+			}
+			sub, collected := rule.evaluate(inventory)
+			if collected != tt.collected {
+				t.Fatalf("collected = %v, want %v", collected, tt.collected)
+			}
+			if collected && sub.Status != tt.status {
+				t.Errorf("status = %q, want %q (%+v)", sub.Status, tt.status, sub)
+			}
+		})
+	}
+}
+
+func TestParseAuditRulePackErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"缺少名称", "rules: [{id: a, target: x, operator: exists}]"},
+		{"没有规则", "name: p"},
+		{"重复的规则 id", "name: p\nrules: [{id: a, target: x, operator: exists}, {id: a, target: y, operator: exists}]"},
+		{"不支持的操作符", "name: p\nrules: [{id: a, target: x, operator: like}]"},
+		{"in 的值不是数组", "name: p\nrules: [{id: a, target: x, operator: in, value: 1}]"},
+		{"正则表达式错误", "name: p\nrules: [{id: a, target: x, operator: regex, value: '('}]"},
+		{"不支持的严重程度", "name: p\nrules: [{id: a, severity: urgent, target: x, operator: exists}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAuditRulePack([]byte(tt.content)); err == nil {
+				t.Errorf("应返回错误")
+			}
+		})
+	}
+}
+
+func TestTagsApplied(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		enabled  []string
+		disabled []string
+		want     bool
+	}{
+		{"未限制标签", []string{"web"}, nil, nil, true},
+		{"包含启用标签", []string{"web", "prod"}, []string{"prod"}, nil, true},
+		{"不包含启用标签", []string{"web"}, []string{"prod"}, nil, false},
+		{"禁用标签优先", []string{"prod", "legacy"}, []string{"prod"}, []string{"legacy"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tagsApplied(tt.tags, tt.enabled, tt.disabled); got != tt.want {
+				t.Errorf("tagsApplied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/config"
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	AuditRulePackSourceUpload = "upload" // 通过管理接口上传
+	AuditRulePackSourceFile   = "file"   // 从磁盘目录加载
+
+	auditRulePackFileIDPrefix = "file:"
+)
+
+// AuditRuleService 审计规则包服务
+type AuditRuleService struct {
+	logger *zap.Logger
+	*orz.Service
+	AuditRulePackRepo *repo.AuditRulePackRepo
+	ruleDir           string
+
+	mu        sync.RWMutex
+	packs     []*compiledRulePack    // 已启用的规则包
+	filePacks []models.AuditRulePack // 磁盘加载的规则包
+}
+
+func NewAuditRuleService(logger *zap.Logger, db *gorm.DB, appConfig *config.AppConfig) *AuditRuleService {
+	s := &AuditRuleService{
+		logger:            logger,
+		Service:           orz.NewService(db),
+		AuditRulePackRepo: repo.NewAuditRulePackRepo(db),
+	}
+	if appConfig.Audit != nil {
+		s.ruleDir = appConfig.Audit.RulePackDir
+	}
+
+	if err := s.Reload(context.Background()); err != nil {
+		logger.Error("加载审计规则包失败", zap.Error(err))
+	}
+	return s
+}
+
+// Reload 重新加载数据库及磁盘中的规则包
+func (s *AuditRuleService) Reload(ctx context.Context) error {
+	var packs []*compiledRulePack
+
+	records, err := s.AuditRulePackRepo.FindAllOrderByName(ctx)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if !record.Enabled {
+			continue
+		}
+		pack, err := s.compile(record.ID, record.Content)
+		if err != nil {
+			s.logger.Warn("规则包解析失败，已跳过", zap.String("id", record.ID), zap.String("name", record.Name), zap.Error(err))
+			continue
+		}
+		packs = append(packs, pack)
+	}
+
+	filePacks := s.loadFromDir()
+	for _, record := range filePacks {
+		pack, err := s.compile(record.ID, record.Content)
+		if err != nil {
+			continue
+		}
+		packs = append(packs, pack)
+	}
+
+	s.mu.Lock()
+	s.packs = packs
+	s.filePacks = filePacks
+	s.mu.Unlock()
+
+	s.logger.Info("审计规则包加载完成", zap.Int("packs", len(packs)))
+	return nil
+}
+
+// loadFromDir 从磁盘目录加载 *.yaml/*.yml 规则包
+func (s *AuditRuleService) loadFromDir() []models.AuditRulePack {
+	if s.ruleDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(s.ruleDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			s.logger.Warn("读取规则包目录失败", zap.String("dir", s.ruleDir), zap.Error(err))
+		}
+		return nil
+	}
+
+	var packs []models.AuditRulePack
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(s.ruleDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			s.logger.Warn("读取规则包文件失败", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		spec, err := ParseAuditRulePack(content)
+		if err != nil {
+			s.logger.Warn("规则包解析失败，已跳过", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		info, _ := entry.Info()
+		var modTime int64
+		if info != nil {
+			modTime = info.ModTime().UnixMilli()
+		}
+		packs = append(packs, models.AuditRulePack{
+			ID:          auditRulePackFileIDPrefix + entry.Name(),
+			Name:        spec.Name,
+			Description: spec.Description,
+			Version:     spec.Version,
+			Source:      AuditRulePackSourceFile,
+			Enabled:     true,
+			RuleCount:   len(spec.Rules),
+			Content:     string(content),
+			CreatedAt:   modTime,
+			UpdatedAt:   modTime,
+		})
+	}
+	return packs
+}
+
+func (s *AuditRuleService) compile(id, content string) (*compiledRulePack, error) {
+	spec, err := ParseAuditRulePack([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	return compileRulePack(id, spec)
+}
+
+// ListPacks 获取所有规则包（包括磁盘加载的规则包）
+func (s *AuditRuleService) ListPacks(ctx context.Context) ([]models.AuditRulePack, error) {
+	packs, err := s.AuditRulePackRepo.FindAllOrderByName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	packs = append(packs, s.filePacks...)
+	s.mu.RUnlock()
+
+	sort.SliceStable(packs, func(i, j int) bool {
+		return packs[i].Name < packs[j].Name
+	})
+	return packs, nil
+}
+
+// GetPack 获取规则包
+func (s *AuditRuleService) GetPack(ctx context.Context, id string) (*models.AuditRulePack, error) {
+	if strings.HasPrefix(id, auditRulePackFileIDPrefix) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		for _, pack := range s.filePacks {
+			if pack.ID == id {
+				return &pack, nil
+			}
+		}
+		return nil, orz.NewError(404, "规则包不存在")
+	}
+
+	pack, err := s.AuditRulePackRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "规则包不存在")
+		}
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// CreatePack 上传规则包
+func (s *AuditRuleService) CreatePack(ctx context.Context, content string) (*models.AuditRulePack, error) {
+	spec, err := ParseAuditRulePack([]byte(content))
+	if err != nil {
+		return nil, orz.NewError(400, err.Error())
+	}
+
+	now := time.Now().UnixMilli()
+	pack := &models.AuditRulePack{
+		ID:          uuid.NewString(),
+		Name:        spec.Name,
+		Description: spec.Description,
+		Version:     spec.Version,
+		Source:      AuditRulePackSourceUpload,
+		Enabled:     true,
+		RuleCount:   len(spec.Rules),
+		Content:     content,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.AuditRulePackRepo.Create(ctx, pack); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("审计规则包已上传", zap.String("id", pack.ID), zap.String("name", pack.Name), zap.Int("rules", pack.RuleCount))
+	return pack, s.Reload(ctx)
+}
+
+// UpdatePack 更新规则包内容
+func (s *AuditRuleService) UpdatePack(ctx context.Context, id, content string) (*models.AuditRulePack, error) {
+	if strings.HasPrefix(id, auditRulePackFileIDPrefix) {
+		return nil, orz.NewError(400, "磁盘加载的规则包不支持在线修改")
+	}
+	pack, err := s.GetPack(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := ParseAuditRulePack([]byte(content))
+	if err != nil {
+		return nil, orz.NewError(400, err.Error())
+	}
+
+	pack.Name = spec.Name
+	pack.Description = spec.Description
+	pack.Version = spec.Version
+	pack.RuleCount = len(spec.Rules)
+	pack.Content = content
+	pack.UpdatedAt = time.Now().UnixMilli()
+	if err := s.AuditRulePackRepo.UpdateById(ctx, pack); err != nil {
+		return nil, err
+	}
+	return pack, s.Reload(ctx)
+}
+
+// UpdatePackEnabled 启用/禁用规则包
+func (s *AuditRuleService) UpdatePackEnabled(ctx context.Context, id string, enabled bool) error {
+	if strings.HasPrefix(id, auditRulePackFileIDPrefix) {
+		return orz.NewError(400, "磁盘加载的规则包不支持在线修改")
+	}
+	if _, err := s.GetPack(ctx, id); err != nil {
+		return err
+	}
+	if err := s.AuditRulePackRepo.UpdateEnabled(ctx, id, enabled, time.Now().UnixMilli()); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// DeletePack 删除规则包
+func (s *AuditRuleService) DeletePack(ctx context.Context, id string) error {
+	if strings.HasPrefix(id, auditRulePackFileIDPrefix) {
+		return orz.NewError(400, "磁盘加载的规则包请直接删除文件")
+	}
+	if err := s.AuditRulePackRepo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// Evaluate 对资产清单执行所有已启用的规则包
+func (s *AuditRuleService) Evaluate(agentTags []string, inventory *protocol.AssetInventory) []protocol.SecurityCheck {
+	s.mu.RLock()
+	packs := s.packs
+	s.mu.RUnlock()
+
+	if len(packs) == 0 {
+		return nil
+	}
+
+	data := inventoryToMap(inventory)
+	var checks []protocol.SecurityCheck
+	for _, pack := range packs {
+		checks = append(checks, pack.evaluate(agentTags, data)...)
+	}
+	return checks
+}
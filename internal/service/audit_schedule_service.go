@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/internal/repo"
+	ws "github.com/dushixiang/pika/internal/websocket"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAuditConcurrency = 5
+	// maxAuditConcurrency 所有计划（包括立即执行）同时审计的探针总数上限
+	maxAuditConcurrency = 20
+	// 单个探针审计超时时间，超时后释放并发名额
+	auditCommandTimeout = 10 * time.Minute
+)
+
+// ScheduledAuditCommandPrefix 定时审计指令ID前缀，用于区分手动触发的审计
+const ScheduledAuditCommandPrefix = "scheduled_vps_audit_"
+
+// IsScheduledAuditCommand 判断指令是否由定时审计触发
+func IsScheduledAuditCommand(cmdID string) bool {
+	return strings.HasPrefix(cmdID, ScheduledAuditCommandPrefix)
+}
+
+// AuditScheduler 定时审计调度器接口（避免循环依赖）
+type AuditScheduler interface {
+	AddTask(scheduleID string, spec string) error
+	UpdateTask(scheduleID string, spec string) error
+	RemoveTask(scheduleID string)
+}
+
+// AuditScheduleService 定时审计服务
+type AuditScheduleService struct {
+	logger *zap.Logger
+	*orz.Service
+	AuditScheduleRepo *repo.AuditScheduleRepo
+	agentRepo         *repo.AgentRepo
+	propertyService   *PropertyService
+	wsManager         *ws.Manager
+
+	// 调度器引用（用于动态管理任务）
+	scheduler AuditScheduler
+
+	// 服务生命周期，立即执行的计划在此 context 下运行
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	sem     chan struct{} // 所有计划共享的审计并发名额
+	mu      sync.Mutex
+	running map[string]bool          // 正在执行的计划
+	pending map[string]chan struct{} // 等待响应的审计指令
+}
+
+func NewAuditScheduleService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, wsManager *ws.Manager) *AuditScheduleService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AuditScheduleService{
+		logger:            logger,
+		Service:           orz.NewService(db),
+		AuditScheduleRepo: repo.NewAuditScheduleRepo(db),
+		agentRepo:         repo.NewAgentRepo(db),
+		propertyService:   propertyService,
+		wsManager:         wsManager,
+		ctx:               ctx,
+		cancel:            cancel,
+		sem:               make(chan struct{}, maxAuditConcurrency),
+		running:           make(map[string]bool),
+		pending:           make(map[string]chan struct{}),
+	}
+}
+
+// Shutdown 取消正在执行的定时审计
+func (s *AuditScheduleService) Shutdown() {
+	s.cancel()
+}
+
+// SetScheduler 设置调度器（由外部注入，避免循环依赖）
+func (s *AuditScheduleService) SetScheduler(scheduler AuditScheduler) {
+	s.scheduler = scheduler
+}
+
+// AuditScheduleRequest 定时审计计划请求
+type AuditScheduleRequest struct {
+	Name           string   `json:"name" validate:"required"`
+	Enabled        bool     `json:"enabled"`
+	Cron           string   `json:"cron" validate:"required"`
+	AgentIDs       []string `json:"agentIds"`
+	Tags           []string `json:"tags"`
+	MaxConcurrency int      `json:"maxConcurrency"`
+}
+
+func validateAuditScheduleRequest(req *AuditScheduleRequest) error {
+	if _, err := cron.ParseStandard(req.Cron); err != nil {
+		return orz.NewError(400, fmt.Sprintf("Cron 表达式错误: %v", err))
+	}
+	if req.MaxConcurrency <= 0 {
+		req.MaxConcurrency = defaultAuditConcurrency
+	}
+	return nil
+}
+
+// CreateSchedule 创建定时审计计划
+func (s *AuditScheduleService) CreateSchedule(ctx context.Context, req *AuditScheduleRequest) (*models.AuditSchedule, error) {
+	if err := validateAuditScheduleRequest(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	schedule := &models.AuditSchedule{
+		ID:             uuid.NewString(),
+		Name:           req.Name,
+		Enabled:        req.Enabled,
+		Cron:           req.Cron,
+		AgentIDs:       req.AgentIDs,
+		Tags:           req.Tags,
+		MaxConcurrency: req.MaxConcurrency,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.AuditScheduleRepo.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if schedule.Enabled && s.scheduler != nil {
+		if err := s.scheduler.AddTask(schedule.ID, schedule.Cron); err != nil {
+			s.logger.Error("添加定时审计到调度器失败", zap.String("scheduleID", schedule.ID), zap.Error(err))
+		}
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule 更新定时审计计划
+func (s *AuditScheduleService) UpdateSchedule(ctx context.Context, id string, req *AuditScheduleRequest) (*models.AuditSchedule, error) {
+	if err := validateAuditScheduleRequest(req); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Name = req.Name
+	schedule.Enabled = req.Enabled
+	schedule.Cron = req.Cron
+	schedule.AgentIDs = req.AgentIDs
+	schedule.Tags = req.Tags
+	schedule.MaxConcurrency = req.MaxConcurrency
+	schedule.UpdatedAt = time.Now().UnixMilli()
+	if err := s.AuditScheduleRepo.Save(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if s.scheduler != nil {
+		if schedule.Enabled {
+			if err := s.scheduler.UpdateTask(schedule.ID, schedule.Cron); err != nil {
+				s.logger.Error("更新定时审计调度器失败", zap.String("scheduleID", schedule.ID), zap.Error(err))
+			}
+		} else {
+			s.scheduler.RemoveTask(schedule.ID)
+		}
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule 删除定时审计计划
+func (s *AuditScheduleService) DeleteSchedule(ctx context.Context, id string) error {
+	if err := s.AuditScheduleRepo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	if s.scheduler != nil {
+		s.scheduler.RemoveTask(id)
+	}
+	return nil
+}
+
+// GetSchedule 获取定时审计计划
+func (s *AuditScheduleService) GetSchedule(ctx context.Context, id string) (*models.AuditSchedule, error) {
+	schedule, err := s.AuditScheduleRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orz.NewError(404, "定时审计计划不存在")
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindByEnabled 查询启用的定时审计计划
+func (s *AuditScheduleService) FindByEnabled(ctx context.Context, enabled bool) ([]models.AuditSchedule, error) {
+	return s.AuditScheduleRepo.FindByEnabled(ctx, enabled)
+}
+
+// RunScheduleNow 在后台立即执行定时审计计划，随服务关闭而取消
+func (s *AuditScheduleService) RunScheduleNow(ctx context.Context, id string) error {
+	if _, err := s.GetSchedule(ctx, id); err != nil {
+		return err
+	}
+	go func() {
+		if err := s.RunSchedule(s.ctx, id); err != nil {
+			s.logger.Error("执行定时审计计划失败", zap.String("scheduleID", id), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// RunSchedule 执行定时审计计划，同一计划上一次执行未结束时跳过。
+// 计划的 MaxConcurrency 限制本次执行的并发数，同时受所有计划共享的并发名额限制
+func (s *AuditScheduleService) RunSchedule(ctx context.Context, id string) error {
+	schedule, err := s.GetSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.running[id] {
+		s.mu.Unlock()
+		s.logger.Warn("定时审计上一次执行尚未结束，跳过本次执行", zap.String("scheduleID", id), zap.String("name", schedule.Name))
+		return nil
+	}
+	s.running[id] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, id)
+		s.mu.Unlock()
+	}()
+
+	agents, err := s.resolveTargetAgents(ctx, schedule)
+	if err != nil {
+		return err
+	}
+
+	maxConcurrency := schedule.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultAuditConcurrency
+	}
+
+	s.logger.Info("开始执行定时审计",
+		zap.String("scheduleID", id),
+		zap.String("name", schedule.Name),
+		zap.Int("agents", len(agents)),
+		zap.Int("maxConcurrency", maxConcurrency))
+
+	startAt := time.Now().UnixMilli()
+	var (
+		wg               sync.WaitGroup
+		countMu          sync.Mutex
+		success, timeout int
+		failed, skipped  int
+	)
+
+	queue := make(chan string)
+	for i := 0; i < maxConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for agentID := range queue {
+				err := s.runAuditWithSlot(ctx, agentID)
+
+				countMu.Lock()
+				switch {
+				case err == nil:
+					success++
+				case errors.Is(err, context.DeadlineExceeded):
+					timeout++
+				default:
+					failed++
+					s.logger.Warn("发送定时审计指令失败", zap.String("agentId", agentID), zap.Error(err))
+				}
+				countMu.Unlock()
+			}
+		}()
+	}
+
+	for _, agent := range agents {
+		if _, ok := s.wsManager.GetClient(agent.ID); !ok {
+			skipped++
+			continue
+		}
+		select {
+		case queue <- agent.ID:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	message := fmt.Sprintf("完成 %d，超时 %d，失败 %d，离线跳过 %d", success, timeout, failed, skipped)
+	if err := s.AuditScheduleRepo.UpdateLastRun(ctx, id, startAt, message); err != nil {
+		s.logger.Error("更新定时审计执行信息失败", zap.String("scheduleID", id), zap.Error(err))
+	}
+
+	s.logger.Info("定时审计执行完成", zap.String("scheduleID", id), zap.String("name", schedule.Name), zap.String("result", message))
+	return nil
+}
+
+// resolveTargetAgents 解析计划覆盖的探针
+func (s *AuditScheduleService) resolveTargetAgents(ctx context.Context, schedule *models.AuditSchedule) ([]models.Agent, error) {
+	agents, err := s.agentRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedule.AgentIDs) == 0 && len(schedule.Tags) == 0 {
+		return agents, nil
+	}
+
+	var targets []models.Agent
+	for _, agent := range agents {
+		if slices.Contains(schedule.AgentIDs, agent.ID) {
+			targets = append(targets, agent)
+			continue
+		}
+		for _, tag := range agent.Tags {
+			if slices.Contains(schedule.Tags, tag) {
+				targets = append(targets, agent)
+				break
+			}
+		}
+	}
+	return targets, nil
+}
+
+// runAuditWithSlot 占用共享的并发名额后执行审计
+func (s *AuditScheduleService) runAuditWithSlot(ctx context.Context, agentID string) error {
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.sem }()
+	return s.runAudit(ctx, agentID)
+}
+
+// runAudit 向探针发送审计指令并等待结果返回
+func (s *AuditScheduleService) runAudit(ctx context.Context, agentID string) error {
+	cmdID := fmt.Sprintf("%s%d_%s", ScheduledAuditCommandPrefix, time.Now().UnixMilli(), agentID)
+
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.pending[cmdID] = done
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, cmdID)
+		s.mu.Unlock()
+	}()
+
+	msgData, err := json.Marshal(protocol.OutboundMessage{
+		Type: protocol.MessageTypeCommand,
+		Data: protocol.CommandRequest{
+			ID:   cmdID,
+			Type: "vps_audit",
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.wsManager.SendToClient(agentID, msgData); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(auditCommandTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return context.DeadlineExceeded
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CompleteAudit 审计指令执行结束（成功或失败），释放并发名额
+func (s *AuditScheduleService) CompleteAudit(cmdID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if done, ok := s.pending[cmdID]; ok {
+		close(done)
+		delete(s.pending, cmdID)
+	}
+}
+
+// PruneAuditResults 按保留策略清理历史审计结果
+func (s *AuditScheduleService) PruneAuditResults(ctx context.Context) error {
+	config, err := s.propertyService.GetAuditConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.RetentionDays > 0 {
+		before := time.Now().AddDate(0, 0, -config.RetentionDays).UnixMilli()
+		deleted, err := s.agentRepo.DeleteAuditResultsBefore(ctx, before)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			s.logger.Info("清理过期审计结果", zap.Int("retentionDays", config.RetentionDays), zap.Int64("deleted", deleted))
+		}
+	}
+
+	if config.MaxResultsPerAgent > 0 {
+		deleted, err := s.agentRepo.TrimAuditResults(ctx, config.MaxResultsPerAgent)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			s.logger.Info("清理超出数量的审计结果", zap.Int("maxResultsPerAgent", config.MaxResultsPerAgent), zap.Int64("deleted", deleted))
+		}
+	}
+	return nil
+}
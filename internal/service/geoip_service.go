@@ -14,9 +14,27 @@ type GeoIPService struct {
 	logger *zap.Logger
 	config *config.GeoIPConfig
 	db     *geoip2.Reader
+	asnDB  *geoip2.Reader
 	mu     sync.RWMutex
 }
 
+// GeoIPInfo IP 地理信息
+type GeoIPInfo struct {
+	Location       string  // 归属地：国家-省份-城市
+	CountryCode    string  // 国家代码（ISO 3166-1）
+	Latitude       float64 // 纬度
+	Longitude      float64 // 经度
+	AccuracyRadius uint16  // 定位精度半径（公里）
+	ASN            uint    // 自治系统号
+	ASOrg          string  // 自治系统所属组织
+	Private        bool    // 是否为内网IP
+}
+
+// HasCoordinates 是否包含可用的经纬度
+func (i *GeoIPInfo) HasCoordinates() bool {
+	return i != nil && !i.Private && (i.Latitude != 0 || i.Longitude != 0)
+}
+
 func NewGeoIPService(logger *zap.Logger, appCfg *config.AppConfig) (*GeoIPService, error) {
 	cfg := appCfg.GeoIP
 	s := &GeoIPService{
@@ -34,6 +52,16 @@ func NewGeoIPService(logger *zap.Logger, appCfg *config.AppConfig) (*GeoIPServic
 			return s, nil
 		}
 		logger.Info("GeoIP service initialized successfully", zap.String("dbPath", cfg.DBPath))
+
+		if cfg.ASNDBPath != "" {
+			if err := s.loadASNDatabase(); err != nil {
+				logger.Warn("failed to load GeoIP ASN database, ASN lookup will be disabled",
+					zap.String("path", cfg.ASNDBPath),
+					zap.Error(err))
+			} else {
+				logger.Info("GeoIP ASN database loaded", zap.String("asnDbPath", cfg.ASNDBPath))
+			}
+		}
 	} else {
 		logger.Info("GeoIP service is disabled")
 	}
@@ -51,16 +79,35 @@ func (s *GeoIPService) loadDatabase() error {
 	return nil
 }
 
+// loadASNDatabase 加载 ASN 数据库
+func (s *GeoIPService) loadASNDatabase() error {
+	db, err := geoip2.Open(s.config.ASNDBPath)
+	if err != nil {
+		return fmt.Errorf("open GeoIP ASN database failed: %w", err)
+	}
+	s.asnDB = db
+	return nil
+}
+
 // LookupIP 查询 IP 归属地
 func (s *GeoIPService) LookupIP(ip string) string {
+	info := s.Lookup(ip)
+	if info == nil {
+		return ""
+	}
+	return info.Location
+}
+
+// Lookup 查询 IP 的归属地、经纬度和 ASN，服务未启用或查询失败时返回 nil
+func (s *GeoIPService) Lookup(ip string) *GeoIPInfo {
 	// 如果服务未启用或数据库未加载
 	if s.config == nil || !s.config.Enabled || s.db == nil {
-		return ""
+		return nil
 	}
 
 	// 跳过私有IP
 	if isPrivateIP(ip) {
-		return "内网IP"
+		return &GeoIPInfo{Location: "内网IP", Private: true}
 	}
 
 	s.mu.RLock()
@@ -68,7 +115,7 @@ func (s *GeoIPService) LookupIP(ip string) string {
 
 	parsedIP := net.ParseIP(ip)
 	if parsedIP == nil {
-		return ""
+		return nil
 	}
 
 	record, err := s.db.City(parsedIP)
@@ -76,7 +123,7 @@ func (s *GeoIPService) LookupIP(ip string) string {
 		s.logger.Debug("failed to lookup IP",
 			zap.String("ip", ip),
 			zap.Error(err))
-		return ""
+		return nil
 	}
 
 	// 获取语言设置，默认使用中文
@@ -85,7 +132,30 @@ func (s *GeoIPService) LookupIP(ip string) string {
 		lang = s.config.DBLanguage
 	}
 
-	// 构建位置信息：国家-省份-城市
+	info := &GeoIPInfo{
+		Location:       formatGeoLocation(record, lang),
+		CountryCode:    record.Country.IsoCode,
+		Latitude:       record.Location.Latitude,
+		Longitude:      record.Location.Longitude,
+		AccuracyRadius: record.Location.AccuracyRadius,
+	}
+
+	if s.asnDB != nil {
+		if asn, err := s.asnDB.ASN(parsedIP); err == nil {
+			info.ASN = asn.AutonomousSystemNumber
+			info.ASOrg = asn.AutonomousSystemOrganization
+		} else {
+			s.logger.Debug("failed to lookup ASN",
+				zap.String("ip", ip),
+				zap.Error(err))
+		}
+	}
+
+	return info
+}
+
+// formatGeoLocation 构建位置信息：国家-省份-城市
+func formatGeoLocation(record *geoip2.City, lang string) string {
 	var location string
 
 	// 国家
@@ -135,6 +205,9 @@ func (s *GeoIPService) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.asnDB != nil {
+		_ = s.asnDB.Close()
+	}
 	if s.db != nil {
 		return s.db.Close()
 	}
@@ -221,6 +221,7 @@ func (s *MetricService) HandleMetricData(ctx context.Context, agentID string, me
 			}
 			latestMetrics.Update(func(lm *metric.LatestMetrics) {
 				lm.Disk = summary
+				lm.Disks = diskDataList
 			})
 		}
 		metrics := s.convertToMetrics(agentID, metricType, diskDataList, timestamp)
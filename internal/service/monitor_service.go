@@ -67,9 +67,43 @@ type MonitorTaskRequest struct {
 	TCPConfig        protocol.TCPMonitorConfig  `json:"tcpConfig,omitempty"`
 	ICMPConfig       protocol.ICMPMonitorConfig `json:"icmpConfig,omitempty"`
 	AgentIds         []string                   `json:"agentIds,omitempty"`
+	ParentID         string                     `json:"parentId,omitempty"` // 父监控项 ID
+}
+
+// validateParent 校验父监控项存在且不会形成循环依赖
+func (s *MonitorService) validateParent(ctx context.Context, id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	if parentID == id {
+		return orz.NewError(400, "父监控项不能是自身")
+	}
+
+	visited := map[string]bool{id: true}
+	for current := parentID; current != ""; {
+		if visited[current] {
+			return orz.NewError(400, "父监控项存在循环依赖")
+		}
+		visited[current] = true
+
+		parent, err := s.MonitorRepo.FindById(ctx, current)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return orz.NewError(400, "父监控项不存在")
+			}
+			return err
+		}
+		current = parent.ParentID
+	}
+	return nil
 }
 
 func (s *MonitorService) CreateMonitor(ctx context.Context, req *MonitorTaskRequest) (*models.MonitorTask, error) {
+	id := uuid.NewString()
+	if err := s.validateParent(ctx, id, req.ParentID); err != nil {
+		return nil, err
+	}
+
 	// 设置默认检测频率
 	interval := req.Interval
 	if interval <= 0 {
@@ -83,7 +117,7 @@ func (s *MonitorService) CreateMonitor(ctx context.Context, req *MonitorTaskRequ
 	}
 
 	task := &models.MonitorTask{
-		ID:               uuid.NewString(),
+		ID:               id,
 		Name:             strings.TrimSpace(req.Name),
 		Type:             req.Type,
 		Target:           strings.TrimSpace(req.Target),
@@ -93,6 +127,7 @@ func (s *MonitorService) CreateMonitor(ctx context.Context, req *MonitorTaskRequ
 		Visibility:       visibility,
 		Interval:         interval,
 		AgentIds:         datatypes.JSONSlice[string](req.AgentIds),
+		ParentID:         req.ParentID,
 		HTTPConfig:       datatypes.NewJSONType(req.HTTPConfig),
 		TCPConfig:        datatypes.NewJSONType(req.TCPConfig),
 		ICMPConfig:       datatypes.NewJSONType(req.ICMPConfig),
@@ -121,6 +156,9 @@ func (s *MonitorService) UpdateMonitor(ctx context.Context, id string, req *Moni
 	if err != nil {
 		return nil, err
 	}
+	if err := s.validateParent(ctx, id, req.ParentID); err != nil {
+		return nil, err
+	}
 
 	// 记录旧状态，用于判断是否需要更新调度器
 	oldEnabled := task.Enabled
@@ -142,6 +180,7 @@ func (s *MonitorService) UpdateMonitor(ctx context.Context, id string, req *Moni
 	task.Interval = interval
 
 	task.AgentIds = req.AgentIds
+	task.ParentID = req.ParentID
 	task.HTTPConfig = datatypes.NewJSONType(req.HTTPConfig)
 	task.TCPConfig = datatypes.NewJSONType(req.TCPConfig)
 	task.ICMPConfig = datatypes.NewJSONType(req.ICMPConfig)
@@ -192,7 +231,8 @@ func (s *MonitorService) DeleteMonitor(ctx context.Context, id string) error {
 		if err := s.MonitorRepo.DeleteById(ctx, id); err != nil {
 			return err
 		}
-		return nil
+		// 解除子监控项的依赖
+		return s.MonitorRepo.ClearParent(ctx, id)
 	})
 
 	if err != nil {
@@ -392,6 +432,16 @@ func (s *MonitorService) GetLatestMonitorMetricsByType(ctx context.Context, moni
 	return result, nil
 }
 
+// IsMonitorDown 监控项的最新数据中是否有探针报告离线
+func (s *MonitorService) IsMonitorDown(ctx context.Context, monitorID string) bool {
+	for _, data := range s.metricService.GetMonitorAgentStats(ctx, monitorID) {
+		if data.Status == "down" {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAllLatestMonitorMetrics 获取所有最新监控指标（用于告警检查）
 func (s *MonitorService) GetAllLatestMonitorMetrics(ctx context.Context) ([]protocol.MonitorData, error) {
 	// 查询所有最新的监控状态
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+)
+
+var notificationRouteLevels = []string{"info", "warning", "critical"}
+
+// matchNotificationRoutes 渠道是否接收该通知，未配置路由规则时接收所有通知
+func matchNotificationRoutes(routes []models.NotificationRoute, record *models.AlertRecord, agent *models.Agent) bool {
+	if len(routes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(routes, func(route models.NotificationRoute) bool {
+		return matchNotificationRoute(&route, record, agent)
+	})
+}
+
+// matchNotificationRoute 路由规则的各条件同时满足时匹配
+func matchNotificationRoute(route *models.NotificationRoute, record *models.AlertRecord, agent *models.Agent) bool {
+	if len(route.AlertTypes) > 0 && !slices.Contains(route.AlertTypes, record.AlertType) {
+		return false
+	}
+	if len(route.Levels) > 0 && !slices.Contains(route.Levels, record.Level) {
+		return false
+	}
+	if len(route.AgentTags) > 0 {
+		if agent == nil || !slices.ContainsFunc(agent.Tags, func(tag string) bool {
+			return slices.Contains(route.AgentTags, tag)
+		}) {
+			return false
+		}
+	}
+	// 防篡改、SSH 登录和流量事件同时匹配 notice
+	if len(route.Events) > 0 &&
+		!slices.Contains(route.Events, notificationEvent(record)) &&
+		!slices.Contains(route.Events, record.Status) {
+		return false
+	}
+	return true
+}
+
+// NormalizeNotificationChannels 校验通知渠道配置，为新渠道生成ID
+func NormalizeNotificationChannels(channels []models.NotificationChannelConfig) error {
+	ids := make(map[string]bool)
+	for i := range channels {
+		channel := &channels[i]
+		channel.Name = strings.TrimSpace(channel.Name)
+		if channel.Type == "" {
+			return orz.NewError(400, "通知渠道类型不能为空")
+		}
+		if channel.ID == "" {
+			channel.ID = uuid.NewString()
+		}
+		if ids[channel.ID] {
+			return orz.NewError(400, "通知渠道ID重复: "+channel.ID)
+		}
+		ids[channel.ID] = true
+
+		name := channel.Name
+		if name == "" {
+			name = channel.Type
+		}
+		for j, route := range channel.Routes {
+			for _, level := range route.Levels {
+				if !slices.Contains(notificationRouteLevels, level) {
+					return orz.NewError(400, fmt.Sprintf("通知渠道 %s 的路由规则 %d 告警级别无效: %s", name, j+1, level))
+				}
+			}
+			for _, event := range route.Events {
+				if !slices.Contains(notificationEvents, event) {
+					return orz.NewError(400, fmt.Sprintf("通知渠道 %s 的路由规则 %d 事件类型无效: %s", name, j+1, event))
+				}
+			}
+		}
+		if err := validateNotificationTemplates(channel); err != nil {
+			return orz.NewError(400, fmt.Sprintf("通知渠道 %s: %v", name, err))
+		}
+	}
+	return nil
+}
+
+// FindNotificationChannel 按ID查找通知渠道，找不到时兼容旧版接口按类型查找第一个渠道
+func FindNotificationChannel(channels []models.NotificationChannelConfig, idOrType string) *models.NotificationChannelConfig {
+	for i := range channels {
+		if channels[i].ID == idOrType {
+			return &channels[i]
+		}
+	}
+	for i := range channels {
+		if channels[i].Type == idOrType {
+			return &channels[i]
+		}
+	}
+	return nil
+}
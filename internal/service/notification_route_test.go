@@ -0,0 +1,127 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestFindNotificationChannel(t *testing.T) {
+	channels := []models.NotificationChannelConfig{
+		{ID: "c1", Type: "dingtalk", Name: "运维群"},
+		{ID: "c2", Type: "dingtalk", Name: "值班群"},
+		{ID: "webhook", Type: "telegram"},
+		{ID: "c4", Type: "webhook"},
+	}
+	tests := []struct {
+		name   string
+		key    string
+		wantID string
+	}{
+		{"按ID查找", "c2", "c2"},
+		{"按类型查找第一个渠道", "dingtalk", "c1"},
+		{"ID优先于类型", "webhook", "webhook"},
+		{"不存在", "email", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindNotificationChannel(channels, tt.key)
+			gotID := ""
+			if got != nil {
+				gotID = got.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("FindNotificationChannel(%q) = %q, want %q", tt.key, gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestMatchNotificationRoutes(t *testing.T) {
+	agent := &models.Agent{Tags: []string{"prod", "db"}}
+	cpuFiring := &models.AlertRecord{AlertType: "cpu", Level: "critical", Status: "firing"}
+	cpuResolved := &models.AlertRecord{AlertType: "cpu", Level: "critical", Status: "resolved"}
+	tamper := &models.AlertRecord{AlertType: "tamper", Level: "warning", Status: "notice"}
+
+	tests := []struct {
+		name   string
+		routes []models.NotificationRoute
+		record *models.AlertRecord
+		agent  *models.Agent
+		want   bool
+	}{
+		{"未配置路由时接收所有通知", nil, cpuFiring, agent, true},
+		{"告警类型匹配", []models.NotificationRoute{{AlertTypes: []string{"cpu", "memory"}}}, cpuFiring, agent, true},
+		{"告警类型不匹配", []models.NotificationRoute{{AlertTypes: []string{"disk"}}}, cpuFiring, agent, false},
+		{"各条件同时满足", []models.NotificationRoute{{AlertTypes: []string{"cpu"}, Levels: []string{"critical"}, AgentTags: []string{"db"}}}, cpuFiring, agent, true},
+		{"任一条件不满足", []models.NotificationRoute{{AlertTypes: []string{"cpu"}, Levels: []string{"warning"}}}, cpuFiring, agent, false},
+		{"匹配任一规则", []models.NotificationRoute{{Levels: []string{"info"}}, {AgentTags: []string{"prod"}}}, cpuFiring, agent, true},
+		{"探针标签不匹配", []models.NotificationRoute{{AgentTags: []string{"staging"}}}, cpuFiring, agent, false},
+		{"无探针时标签条件不满足", []models.NotificationRoute{{AgentTags: []string{"prod"}}}, cpuFiring, nil, false},
+		{"事件类型匹配恢复", []models.NotificationRoute{{Events: []string{"resolved"}}}, cpuResolved, agent, true},
+		{"只接收触发事件", []models.NotificationRoute{{Events: []string{"firing"}}}, cpuResolved, agent, false},
+		{"防篡改事件匹配细分事件", []models.NotificationRoute{{Events: []string{"tamper"}}}, tamper, agent, true},
+		{"防篡改事件同时匹配 notice", []models.NotificationRoute{{Events: []string{"notice"}}}, tamper, agent, true},
+		{"防篡改事件不匹配其他细分事件", []models.NotificationRoute{{Events: []string{"ssh_login"}}}, tamper, agent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchNotificationRoutes(tt.routes, tt.record, tt.agent); got != tt.want {
+				t.Errorf("matchNotificationRoutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeNotificationChannels(t *testing.T) {
+	t.Run("生成ID并去除名称空白", func(t *testing.T) {
+		channels := []models.NotificationChannelConfig{
+			{ID: "c1", Type: "dingtalk", Name: " 运维群 "},
+			{Type: "webhook"},
+		}
+		if err := NormalizeNotificationChannels(channels); err != nil {
+			t.Fatalf("NormalizeNotificationChannels() error = %v", err)
+		}
+		if channels[0].ID != "c1" || channels[0].Name != "运维群" {
+			t.Errorf("已有渠道 = %+v", channels[0])
+		}
+		if channels[1].ID == "" {
+			t.Errorf("新渠道应生成ID")
+		}
+	})
+
+	tests := []struct {
+		name     string
+		channels []models.NotificationChannelConfig
+		wantErr  string
+	}{
+		{"类型为空", []models.NotificationChannelConfig{{ID: "c1"}}, "通知渠道类型不能为空"},
+		{"ID重复", []models.NotificationChannelConfig{{ID: "c1", Type: "dingtalk"}, {ID: "c1", Type: "webhook"}}, "通知渠道ID重复"},
+		{
+			"告警级别无效",
+			[]models.NotificationChannelConfig{{ID: "c1", Type: "dingtalk", Name: "运维群", Routes: []models.NotificationRoute{{}, {Levels: []string{"fatal"}}}}},
+			"通知渠道 运维群 的路由规则 2 告警级别无效: fatal",
+		},
+		{
+			"事件类型无效",
+			[]models.NotificationChannelConfig{{ID: "c1", Type: "webhook", Routes: []models.NotificationRoute{{Events: []string{"deleted"}}}}},
+			"通知渠道 webhook 的路由规则 1 事件类型无效: deleted",
+		},
+		{
+			"模板语法错误",
+			[]models.NotificationChannelConfig{{ID: "c1", Type: "webhook", Config: map[string]interface{}{
+				"templates": map[string]interface{}{"firing": "{{.Message"},
+			}}},
+			"firing 模板语法错误",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NormalizeNotificationChannels(tt.channels)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NormalizeNotificationChannels() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
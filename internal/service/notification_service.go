@@ -8,9 +8,12 @@ import (
 )
 
 const (
-	NotificationTypeTraffic   = "traffic"
-	NotificationTypeSSHLogin  = "ssh_login"
-	NotificationTypeTamperEvt = "tamper"
+	NotificationTypeTraffic         = "traffic"
+	NotificationTypeSSHLogin        = "ssh_login"
+	NotificationTypeSSHBan          = "ssh_ban"
+	NotificationTypeSSHLoginAnomaly = "ssh_login_anomaly" // 高危异常登录，不受登录成功通知开关影响
+	NotificationTypeTamperEvt       = "tamper"
+	NotificationTypeAuditDrift      = "audit_drift"
 )
 
 // NotificationService 统一通知发送入口
@@ -18,13 +21,15 @@ type NotificationService struct {
 	logger          *zap.Logger
 	propertyService *PropertyService
 	notifier        *Notifier
+	silenceService  *AlertSilenceService
 }
 
-func NewNotificationService(logger *zap.Logger, propertyService *PropertyService, notifier *Notifier) *NotificationService {
+func NewNotificationService(logger *zap.Logger, propertyService *PropertyService, notifier *Notifier, silenceService *AlertSilenceService) *NotificationService {
 	return &NotificationService{
 		logger:          logger,
 		propertyService: propertyService,
 		notifier:        notifier,
+		silenceService:  silenceService,
 	}
 }
 
@@ -43,6 +48,11 @@ func (s *NotificationService) SendAlertNotification(ctx context.Context, notific
 		return nil
 	}
 
+	// 匹配告警静默时只记录不通知
+	if s.silenceService.Apply(ctx, record, agent) {
+		return nil
+	}
+
 	channelConfigs, err := s.propertyService.GetNotificationChannelConfigs(ctx)
 	if err != nil {
 		return err
@@ -81,8 +91,14 @@ func isNotificationEnabled(config *models.AlertConfig, notificationType string)
 		return config.Notifications.TrafficEnabled
 	case NotificationTypeSSHLogin:
 		return config.Notifications.SSHLoginSuccessEnabled
+	case NotificationTypeSSHBan:
+		return config.Notifications.SSHBanEnabled
+	case NotificationTypeSSHLoginAnomaly:
+		return true
 	case NotificationTypeTamperEvt:
 		return config.Notifications.TamperEventEnabled
+	case NotificationTypeAuditDrift:
+		return config.Notifications.AuditDriftEnabled
 	default:
 		return true
 	}
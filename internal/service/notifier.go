@@ -106,6 +106,34 @@ var alertTypeMetadataMap = map[string]AlertTypeMetadata{
 		ShowThreshold: true,
 		ShowActual:    true,
 	},
+	"disk_forecast": {
+		Name:          "磁盘写满预测",
+		ThresholdUnit: "小时内写满",
+		ValueUnit:     "小时后写满",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"traffic_forecast": {
+		Name:          "流量超额预测",
+		ThresholdUnit: "%",
+		ValueUnit:     "%（重置前预计用量）",
+		ShowThreshold: false,
+		ShowActual:    true,
+	},
+	"test": {
+		Name:          "测试通知",
+		ThresholdUnit: "",
+		ValueUnit:     "",
+		ShowThreshold: false,
+		ShowActual:    false,
+	},
+	"promql": {
+		Name:          "自定义告警",
+		ThresholdUnit: "",
+		ValueUnit:     "",
+		ShowThreshold: false,
+		ShowActual:    true,
+	},
 	"ssh_login": {
 		Name:          "SSH登录成功",
 		ThresholdUnit: "",
@@ -113,6 +141,20 @@ var alertTypeMetadataMap = map[string]AlertTypeMetadata{
 		ShowThreshold: false,
 		ShowActual:    false,
 	},
+	"ssh_login_anomaly": {
+		Name:          "SSH异常登录",
+		ThresholdUnit: "",
+		ValueUnit:     "",
+		ShowThreshold: false,
+		ShowActual:    false,
+	},
+	"ssh_ban": {
+		Name:          "SSH暴力破解封禁",
+		ThresholdUnit: "次",
+		ValueUnit:     "次",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
 	"tamper": {
 		Name:          "防篡改事件",
 		ThresholdUnit: "",
@@ -120,6 +162,13 @@ var alertTypeMetadataMap = map[string]AlertTypeMetadata{
 		ShowThreshold: false,
 		ShowActual:    false,
 	},
+	"audit_drift": {
+		Name:          "审计资产变化",
+		ThresholdUnit: "",
+		ValueUnit:     "项",
+		ShowThreshold: false,
+		ShowActual:    true,
+	},
 }
 
 // 告警级别图标映射
@@ -131,7 +180,9 @@ var levelIconMap = map[string]string{
 
 // Notifier 告警通知服务
 type Notifier struct {
-	logger *zap.Logger
+	logger        *zap.Logger
+	metricService *MetricService // 渲染通知模板时读取最新指标
+	threadMu      sync.Mutex     // 保护告警记录中的消息会话标识
 }
 
 func NewNotifier(logger *zap.Logger) *Notifier {
@@ -140,6 +191,11 @@ func NewNotifier(logger *zap.Logger) *Notifier {
 	}
 }
 
+// SetMetricService 注入指标服务（避免循环依赖）
+func (n *Notifier) SetMetricService(metricService *MetricService) {
+	n.metricService = metricService
+}
+
 // maskIPAddress 打码 IP 地址 (例如: 192.168.1.100 -> 192.168.*.*）
 func maskIPAddress(ip string) string {
 	parts := strings.Split(ip, ".")
@@ -244,6 +300,39 @@ func (n *Notifier) buildMessage(agent *models.Agent, record *models.AlertRecord,
 	}
 }
 
+// buildLevelTitle 构建带级别的消息标题
+func buildLevelTitle(record *models.AlertRecord, levelIcon string, metadata AlertTypeMetadata) string {
+	if record.Level != "" && record.Level != "info" {
+		return fmt.Sprintf("%s %s【%s】", levelIcon, metadata.Name, strings.ToUpper(record.Level))
+	}
+	return fmt.Sprintf("%s %s", levelIcon, metadata.Name)
+}
+
+// buildFiringTitle 构建告警触发消息标题，抖动和升级时添加前缀
+func buildFiringTitle(record *models.AlertRecord, levelIcon string, metadata AlertTypeMetadata) string {
+	title := buildLevelTitle(record, levelIcon, metadata)
+	if record.Flapping && record.FlapEndedAt == 0 {
+		title = "🔁 【抖动】" + title
+	}
+	if record.EscalatedAt > 0 {
+		title = "⏫ 【升级】" + title
+	}
+	return title
+}
+
+// buildReminder 未确认告警的重复通知和升级通知提示，非提醒消息返回空字符串
+func buildReminder(record *models.AlertRecord) string {
+	remindedAt := max(record.LastRepeatAt, record.EscalatedAt)
+	if remindedAt <= record.FiredAt {
+		return ""
+	}
+	reminder := fmt.Sprintf("⏰ 告警已持续%s，尚未确认", utils.FormatDuration(remindedAt-record.FiredAt))
+	if record.RepeatCount > 0 {
+		reminder += fmt.Sprintf("（第%d次提醒）", record.RepeatCount)
+	}
+	return reminder
+}
+
 // buildFiringMessage 构建告警触发消息
 func (n *Notifier) buildFiringMessage(
 	agent *models.Agent,
@@ -252,13 +341,8 @@ func (n *Notifier) buildFiringMessage(
 	levelIcon string,
 	metadata AlertTypeMetadata,
 ) string {
-	title := fmt.Sprintf("%s %s", levelIcon, metadata.Name)
-	if record.Level != "" && record.Level != "info" {
-		title = fmt.Sprintf("%s %s【%s】", levelIcon, metadata.Name, strings.ToUpper(record.Level))
-	}
-
 	lines := []string{
-		title,
+		buildFiringTitle(record, levelIcon, metadata),
 		"",
 		fmt.Sprintf("📍 探针: %s", agent.Name),
 		fmt.Sprintf("🖥️  主机: %s", agent.Hostname),
@@ -275,6 +359,18 @@ func (n *Notifier) buildFiringMessage(
 		lines = append(lines, fmt.Sprintf("📈 当前值: %.2f%s", record.ActualValue, metadata.ValueUnit))
 	}
 
+	if record.Flapping {
+		lines = append(lines, flapSummary(record))
+	}
+
+	if record.Assignee != "" {
+		lines = append(lines, fmt.Sprintf("👤 负责人: %s", record.Assignee))
+	}
+
+	if reminder := buildReminder(record); reminder != "" {
+		lines = append(lines, reminder)
+	}
+
 	lines = append(lines, "", fmt.Sprintf("🕐 时间: %s", utils.FormatTimestamp(record.FiredAt)))
 
 	return strings.Join(lines, "\n")
@@ -309,6 +405,9 @@ func (n *Notifier) buildResolvedMessage(
 				fmt.Sprintf("⏱️  离线时长: %.0f秒", record.ActualValue),
 				"✅ 恢复状态: 已在线",
 			)
+		} else if record.AlertType == "promql" || record.AlertType == "disk_forecast" || record.AlertType == "traffic_forecast" {
+			// 序列消失或趋势变化即恢复，没有有意义的恢复值
+			lines = append(lines, fmt.Sprintf("📈 告警值: %.2f%s", record.ActualValue, metadata.ValueUnit))
 		} else {
 			lines = append(lines,
 				fmt.Sprintf("📈 告警值: %.2f%s", record.ActualValue, metadata.ValueUnit),
@@ -321,6 +420,14 @@ func (n *Notifier) buildResolvedMessage(
 		lines = append(lines, fmt.Sprintf("⏱️  持续时间: %s", durationStr))
 	}
 
+	if record.Flapping {
+		lines = append(lines, flapSummary(record))
+	}
+
+	if record.AcknowledgedBy != "" {
+		lines = append(lines, fmt.Sprintf("👤 确认人: %s", record.AcknowledgedBy))
+	}
+
 	lines = append(lines, fmt.Sprintf("🕐 恢复时间: %s", utils.FormatTimestamp(record.ResolvedAt)))
 
 	return strings.Join(lines, "\n")
@@ -333,13 +440,8 @@ func (n *Notifier) buildNoticeMessage(
 	levelIcon string,
 	metadata AlertTypeMetadata,
 ) string {
-	title := fmt.Sprintf("%s %s", levelIcon, metadata.Name)
-	if record.Level != "" && record.Level != "info" {
-		title = fmt.Sprintf("%s %s【%s】", levelIcon, metadata.Name, strings.ToUpper(record.Level))
-	}
-
 	lines := []string{
-		title,
+		buildLevelTitle(record, levelIcon, metadata),
 		"",
 		fmt.Sprintf("📍 探针: %s", agent.Name),
 		fmt.Sprintf("🖥️  主机: %s", agent.Hostname),
@@ -793,8 +895,8 @@ func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]inte
 		return err
 	}
 
-	// 构建消息内容
-	message := n.buildMessage(agent, record, maskIP)
+	// 构建消息内容，{{message}} 使用渠道模板渲染结果
+	message := n.renderMessage(config, n.buildMessage(agent, record, maskIP), agent, record, maskIP)
 
 	// 构建自定义请求体
 	reqBody, err := n.buildCustomBody(agent, record, message, cfg.CustomBody, maskIP)
@@ -808,6 +910,11 @@ func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]inte
 }
 
 func (n *Notifier) sendJSONRequest(ctx context.Context, url string, body interface{}) ([]byte, error) {
+	return n.sendJSONRequestWithHeaders(ctx, url, body, nil)
+}
+
+// sendJSONRequestWithHeaders 发送 JSON 请求，可附加请求头（如认证信息）
+func (n *Notifier) sendJSONRequestWithHeaders(ctx context.Context, url string, body interface{}, headers map[string]string) ([]byte, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("序列化请求体失败: %w", err)
@@ -819,6 +926,9 @@ func (n *Notifier) sendJSONRequest(ctx context.Context, url string, body interfa
 			return nil, err
 		}
 		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
 		return req, nil
 	}
 
@@ -986,11 +1096,12 @@ func (n *Notifier) SendNotificationByConfig(ctx context.Context, channelConfig *
 	}
 
 	n.logger.Info("发送通知",
+		zap.String("channelId", channelConfig.ID),
 		zap.String("channelType", channelConfig.Type),
 	)
 
-	// 构造通知消息内容
-	message := n.buildMessage(agent, record, maskIP)
+	// 构造通知消息内容，渠道配置了模板时使用模板渲染
+	message := n.renderMessage(channelConfig.Config, n.buildMessage(agent, record, maskIP), agent, record, maskIP)
 
 	switch channelConfig.Type {
 	case "dingtalk":
@@ -1007,12 +1118,30 @@ func (n *Notifier) SendNotificationByConfig(ctx context.Context, channelConfig *
 		return n.sendEmailByConfig(ctx, channelConfig.Config, message)
 	case "webhook":
 		return n.sendWebhookByConfig(ctx, channelConfig.Config, agent, record, maskIP)
+	case "slack":
+		return n.sendSlackByConfig(ctx, channelConfig.Config, channelConfig.ID, agent, record, maskIP)
+	case "discord":
+		return n.sendDiscordByConfig(ctx, channelConfig.Config, channelConfig.ID, agent, record, maskIP)
+	case "teams":
+		return n.sendTeamsByConfig(ctx, channelConfig.Config, agent, record, maskIP)
+	case "pagerduty":
+		return n.sendPagerDutyByConfig(ctx, channelConfig.Config, agent, record, maskIP)
+	case "opsgenie":
+		return n.sendOpsgenieByConfig(ctx, channelConfig.Config, agent, record, maskIP)
+	case "ntfy":
+		return n.sendNtfyByConfig(ctx, channelConfig.Config, message, agent, record)
+	case "gotify":
+		return n.sendGotifyByConfig(ctx, channelConfig.Config, message, agent, record)
+	case "bark":
+		return n.sendBarkByConfig(ctx, channelConfig.Config, message, agent, record)
+	case "serverchan":
+		return n.sendServerChanByConfig(ctx, channelConfig.Config, message, agent)
 	default:
 		return fmt.Errorf("不支持的通知渠道类型: %s", channelConfig.Type)
 	}
 }
 
-// SendNotificationByConfigs 根据新的配置结构向多个渠道发送通知
+// SendNotificationByConfigs 根据新的配置结构向多个渠道发送通知，只发送到路由规则匹配的渠道
 func (n *Notifier) SendNotificationByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, maskIP bool) error {
 	message := n.buildMessage(agent, record, maskIP)
 
@@ -1024,12 +1153,22 @@ func (n *Notifier) SendNotificationByConfigs(ctx context.Context, channelConfigs
 		if !cfg.Enabled {
 			continue
 		}
+		if !matchNotificationRoutes(cfg.Routes, record, agent) {
+			n.logger.Debug("通知不匹配渠道路由规则，跳过",
+				zap.String("channelId", cfg.ID),
+				zap.String("alertType", record.AlertType),
+				zap.String("level", record.Level),
+			)
+			continue
+		}
 		wg.Add(1)
 		go func(cfg models.NotificationChannelConfig) {
 			defer wg.Done()
 			err := n.sendToChannel(ctx, &cfg, message, agent, record, maskIP)
 			if err != nil {
 				n.logger.Error("发送通知失败",
+					zap.String("channelId", cfg.ID),
+					zap.String("channelName", cfg.Name),
 					zap.String("channelType", cfg.Type),
 					zap.Error(err),
 				)
@@ -1049,7 +1188,8 @@ func (n *Notifier) SendNotificationByConfigs(ctx context.Context, channelConfigs
 }
 
 func (n *Notifier) sendToChannel(ctx context.Context, channelConfig *models.NotificationChannelConfig, message string, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
-	n.logger.Info("发送通知", zap.String("channelType", channelConfig.Type))
+	n.logger.Info("发送通知", zap.String("channelId", channelConfig.ID), zap.String("channelType", channelConfig.Type))
+	message = n.renderMessage(channelConfig.Config, message, agent, record, maskIP)
 
 	channelCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -1069,6 +1209,24 @@ func (n *Notifier) sendToChannel(ctx context.Context, channelConfig *models.Noti
 		return n.sendEmailByConfig(channelCtx, channelConfig.Config, message)
 	case "webhook":
 		return n.sendWebhookByConfig(channelCtx, channelConfig.Config, agent, record, maskIP)
+	case "slack":
+		return n.sendSlackByConfig(channelCtx, channelConfig.Config, channelConfig.ID, agent, record, maskIP)
+	case "discord":
+		return n.sendDiscordByConfig(channelCtx, channelConfig.Config, channelConfig.ID, agent, record, maskIP)
+	case "teams":
+		return n.sendTeamsByConfig(channelCtx, channelConfig.Config, agent, record, maskIP)
+	case "pagerduty":
+		return n.sendPagerDutyByConfig(channelCtx, channelConfig.Config, agent, record, maskIP)
+	case "opsgenie":
+		return n.sendOpsgenieByConfig(channelCtx, channelConfig.Config, agent, record, maskIP)
+	case "ntfy":
+		return n.sendNtfyByConfig(channelCtx, channelConfig.Config, message, agent, record)
+	case "gotify":
+		return n.sendGotifyByConfig(channelCtx, channelConfig.Config, message, agent, record)
+	case "bark":
+		return n.sendBarkByConfig(channelCtx, channelConfig.Config, message, agent, record)
+	case "serverchan":
+		return n.sendServerChanByConfig(channelCtx, channelConfig.Config, message, agent)
 	default:
 		return fmt.Errorf("不支持的通知渠道类型: %s", channelConfig.Type)
 	}
@@ -1106,7 +1264,12 @@ func (n *Notifier) SendEmailByConfig(ctx context.Context, config map[string]inte
 
 // SendWebhookByConfig 导出方法供外部调用（测试用）
 func (n *Notifier) SendWebhookByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	// 为了测试，创建一个临时的 agent 和 record
+	agent, record := newTestNotificationData(message)
+	return n.sendWebhookByConfig(ctx, config, agent, record, false)
+}
+
+// newTestNotificationData 创建测试通知使用的临时探针和告警记录
+func newTestNotificationData(message string) (*models.Agent, *models.AlertRecord) {
 	agent := &models.Agent{
 		ID:       "test-agent",
 		Name:     "测试探针",
@@ -1122,7 +1285,7 @@ func (n *Notifier) SendWebhookByConfig(ctx context.Context, config map[string]in
 		ActualValue: 0,
 		FiredAt:     time.Now().UnixMilli(),
 	}
-	return n.sendWebhookByConfig(ctx, config, agent, record, false)
+	return agent, record
 }
 
 // SendTestNotification 发送测试通知（动态匹配通知渠道类型）
@@ -1142,22 +1305,37 @@ func (n *Notifier) SendTestNotification(ctx context.Context, channelType string,
 		return n.sendEmailByConfig(ctx, config, message)
 	case "webhook":
 		// Webhook 需要 agent 和 record，创建测试数据
-		agent := &models.Agent{
-			ID:       "test-agent",
-			Name:     "测试探针",
-			Hostname: "test-host",
-			IPv4:     "127.0.0.1",
-		}
-		record := &models.AlertRecord{
-			AlertType:   "test",
-			Level:       "info",
-			Status:      "firing",
-			Message:     message,
-			Threshold:   0,
-			ActualValue: 0,
-			FiredAt:     time.Now().UnixMilli(),
-		}
+		agent, record := newTestNotificationData(message)
 		return n.sendWebhookByConfig(ctx, config, agent, record, false)
+	case "slack":
+		agent, record := newTestNotificationData(message)
+		return n.sendSlackByConfig(ctx, config, "", agent, record, false)
+	case "discord":
+		agent, record := newTestNotificationData(message)
+		return n.sendDiscordByConfig(ctx, config, "", agent, record, false)
+	case "teams":
+		agent, record := newTestNotificationData(message)
+		return n.sendTeamsByConfig(ctx, config, agent, record, false)
+	case "pagerduty":
+		return n.sendIncidentTest(ctx, message, func(agent *models.Agent, record *models.AlertRecord) error {
+			return n.sendPagerDutyByConfig(ctx, config, agent, record, false)
+		})
+	case "opsgenie":
+		return n.sendIncidentTest(ctx, message, func(agent *models.Agent, record *models.AlertRecord) error {
+			return n.sendOpsgenieByConfig(ctx, config, agent, record, false)
+		})
+	case "ntfy":
+		agent, record := newTestNotificationData(message)
+		return n.sendNtfyByConfig(ctx, config, n.buildMessage(agent, record, false), agent, record)
+	case "gotify":
+		agent, record := newTestNotificationData(message)
+		return n.sendGotifyByConfig(ctx, config, n.buildMessage(agent, record, false), agent, record)
+	case "bark":
+		agent, record := newTestNotificationData(message)
+		return n.sendBarkByConfig(ctx, config, n.buildMessage(agent, record, false), agent, record)
+	case "serverchan":
+		agent, record := newTestNotificationData(message)
+		return n.sendServerChanByConfig(ctx, config, n.buildMessage(agent, record, false), agent)
 	default:
 		return fmt.Errorf("不支持的通知渠道类型: %s", channelType)
 	}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/utils"
+	"gorm.io/datatypes"
+)
+
+// slackAPIURL Slack Web API 地址
+var slackAPIURL = "https://slack.com/api"
+
+// 告警级别颜色映射
+var levelColorMap = map[string]string{
+	"info":     "#1677FF",
+	"warning":  "#FAAD14",
+	"critical": "#F5222D",
+}
+
+// 已恢复消息颜色
+const resolvedColor = "#52C41A"
+
+// Teams 自适应卡片容器样式映射
+var levelTeamsStyleMap = map[string]string{
+	"info":     "accent",
+	"warning":  "warning",
+	"critical": "attention",
+}
+
+// chatCard 卡片类通知内容（Slack、Discord、Teams 共用）
+type chatCard struct {
+	Title      string
+	Text       string
+	Color      string // 十六进制颜色
+	TeamsStyle string // Teams 容器样式
+	Fields     []chatCardField
+	Time       int64 // 事件时间（时间戳毫秒）
+}
+
+type chatCardField struct {
+	Name  string
+	Value string
+}
+
+// buildChatCard 按告警状态构建卡片内容，颜色跟随告警级别，已恢复时为绿色
+func (n *Notifier) buildChatCard(agent *models.Agent, record *models.AlertRecord, maskIP bool) *chatCard {
+	levelIcon := getLevelIcon(record.Level)
+	metadata := getAlertTypeMetadata(record.AlertType)
+
+	card := &chatCard{
+		Text:       record.Message,
+		Color:      levelColorMap["info"],
+		TeamsStyle: levelTeamsStyleMap["info"],
+		Time:       record.FiredAt,
+	}
+	if color, ok := levelColorMap[record.Level]; ok {
+		card.Color = color
+		card.TeamsStyle = levelTeamsStyleMap[record.Level]
+	}
+
+	card.Fields = []chatCardField{
+		{Name: "探针", Value: agent.Name},
+		{Name: "主机", Value: agent.Hostname},
+		{Name: "IP", Value: formatAgentIP(agent, maskIP)},
+	}
+	if record.Device != "" {
+		card.Fields = append(card.Fields, chatCardField{Name: "设备", Value: record.Device})
+	}
+
+	var extraLines []string
+	switch record.Status {
+	case "resolved":
+		card.Title = fmt.Sprintf("✅ %s已恢复", metadata.Name)
+		card.Color = resolvedColor
+		card.TeamsStyle = "good"
+		card.Time = record.ResolvedAt
+		if metadata.ShowActual {
+			card.Fields = append(card.Fields, chatCardField{Name: "告警值", Value: fmt.Sprintf("%.2f%s", record.ActualValue, metadata.ValueUnit)})
+			if hasResolvedValue(record) {
+				card.Fields = append(card.Fields, chatCardField{Name: "恢复值", Value: fmt.Sprintf("%.2f%s", record.ResolvedValue, metadata.ValueUnit)})
+			}
+		}
+		if record.FiredAt > 0 && record.ResolvedAt > record.FiredAt {
+			card.Fields = append(card.Fields, chatCardField{Name: "持续时间", Value: utils.FormatDuration(record.ResolvedAt - record.FiredAt)})
+		}
+		if record.AcknowledgedBy != "" {
+			card.Fields = append(card.Fields, chatCardField{Name: "确认人", Value: record.AcknowledgedBy})
+		}
+	default:
+		if record.Status == "firing" {
+			card.Title = buildFiringTitle(record, levelIcon, metadata)
+			extraLines = append(extraLines, buildReminder(record))
+		} else {
+			card.Title = buildLevelTitle(record, levelIcon, metadata)
+		}
+		if metadata.ShowThreshold {
+			card.Fields = append(card.Fields, chatCardField{Name: "阈值", Value: fmt.Sprintf("%.2f%s", record.Threshold, metadata.ThresholdUnit)})
+		}
+		if metadata.ShowActual {
+			card.Fields = append(card.Fields, chatCardField{Name: "当前值", Value: fmt.Sprintf("%.2f%s", record.ActualValue, metadata.ValueUnit)})
+		}
+		if record.Assignee != "" {
+			card.Fields = append(card.Fields, chatCardField{Name: "负责人", Value: record.Assignee})
+		}
+	}
+	if record.Flapping {
+		extraLines = append(extraLines, flapSummary(record))
+	}
+	for _, line := range extraLines {
+		if line != "" {
+			card.Text += "\n" + line
+		}
+	}
+	return card
+}
+
+// buildChannelCard 构建渠道的卡片内容，渠道配置了模板时卡片正文使用模板渲染结果，标题和字段不变
+func (n *Notifier) buildChannelCard(config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, maskIP bool) *chatCard {
+	card := n.buildChatCard(agent, record, maskIP)
+	if text, ok := n.renderChannelTemplate(config, agent, record, maskIP); ok {
+		card.Text = text
+	}
+	return card
+}
+
+// hasResolvedValue 恢复消息是否有有意义的恢复值
+func hasResolvedValue(record *models.AlertRecord) bool {
+	switch record.AlertType {
+	case "promql", "disk_forecast", "traffic_forecast":
+		// 序列消失或趋势变化即恢复
+		return false
+	case "service", "agent_offline":
+		return record.ResolvedValue != 0
+	}
+	return true
+}
+
+// truncateRunes 按字符数截断文本
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-3]) + "..."
+}
+
+// getNotificationThread 获取告警记录在指定渠道的消息会话标识，以渠道ID区分
+func (n *Notifier) getNotificationThread(record *models.AlertRecord, channel string) string {
+	n.threadMu.Lock()
+	defer n.threadMu.Unlock()
+	return record.NotificationThreads.Data()[channel]
+}
+
+// setNotificationThread 记录告警触发消息的会话标识，由调用方持久化
+func (n *Notifier) setNotificationThread(record *models.AlertRecord, channel, thread string) {
+	n.threadMu.Lock()
+	defer n.threadMu.Unlock()
+	threads := make(map[string]string)
+	for k, v := range record.NotificationThreads.Data() {
+		threads[k] = v
+	}
+	threads[channel] = thread
+	record.NotificationThreads = datatypes.NewJSONType(threads)
+}
+
+// startsThread 只有已保存的告警触发消息才创建会话，后续提醒和恢复消息回复到该会话
+func startsThread(record *models.AlertRecord) bool {
+	return record.ID > 0 && record.Status == "firing"
+}
+
+// escapeSlackText 转义 Slack mrkdwn 中的控制字符
+func escapeSlackText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// buildSlackBlocks 构建 Slack Block Kit 消息，颜色通过附件侧边栏展示
+func buildSlackBlocks(card *chatCard) map[string]interface{} {
+	fields := make([]map[string]interface{}, 0, len(card.Fields))
+	for _, field := range card.Fields {
+		fields = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*%s*\n%s", escapeSlackText(field.Name), escapeSlackText(field.Value)),
+		})
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": truncateRunes(card.Title, 150), "emoji": true},
+		},
+		{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": truncateRunes(escapeSlackText(card.Text), 3000)},
+		},
+	}
+	// 每个 section 最多 10 个字段
+	for i := 0; i < len(fields); i += 10 {
+		blocks = append(blocks, map[string]interface{}{
+			"type":   "section",
+			"fields": fields[i:min(i+10, len(fields))],
+		})
+	}
+	blocks = append(blocks, map[string]interface{}{
+		"type": "context",
+		"elements": []map[string]interface{}{
+			{"type": "mrkdwn", "text": "🕐 " + utils.FormatTimestamp(card.Time)},
+		},
+	})
+
+	return map[string]interface{}{
+		"text": card.Title,
+		"attachments": []map[string]interface{}{
+			{"color": card.Color, "blocks": blocks},
+		},
+	}
+}
+
+// sendSlackByConfig 发送 Slack 通知，配置 Bot Token 时使用 chat.postMessage 并将后续消息回复到触发消息所在会话，否则使用 Incoming Webhook
+// channelID 用于区分同一告警在多个 Slack 渠道的会话
+func (n *Notifier) sendSlackByConfig(ctx context.Context, config map[string]interface{}, channelID string, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
+	body := buildSlackBlocks(n.buildChannelCard(config, agent, record, maskIP))
+
+	botToken, _ := config["botToken"].(string)
+	if botToken == "" {
+		webhookURL, ok := config["webhookUrl"].(string)
+		if !ok || webhookURL == "" {
+			return fmt.Errorf("Slack 配置缺少 webhookUrl 或 botToken")
+		}
+		_, err := n.sendJSONRequest(ctx, webhookURL, body)
+		return err
+	}
+
+	channel, ok := config["channel"].(string)
+	if !ok || channel == "" {
+		return fmt.Errorf("Slack 配置缺少 channel")
+	}
+	body["channel"] = channel
+	thread := n.getNotificationThread(record, channelID)
+	if thread != "" {
+		body["thread_ts"] = thread
+	}
+
+	respBody, err := n.sendJSONRequestWithHeaders(ctx, slackAPIURL+"/chat.postMessage", body, map[string]string{
+		"Authorization": "Bearer " + botToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("解析 Slack 响应失败: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("Slack 返回错误: %s", resp.Error)
+	}
+	if thread == "" && startsThread(record) {
+		n.setNotificationThread(record, channelID, resp.TS)
+	}
+	return nil
+}
+
+// buildDiscordEmbed 构建 Discord embed 消息
+func buildDiscordEmbed(card *chatCard) map[string]interface{} {
+	color, _ := strconv.ParseInt(strings.TrimPrefix(card.Color, "#"), 16, 64)
+
+	fields := make([]map[string]interface{}, 0, len(card.Fields))
+	for _, field := range card.Fields {
+		value := field.Value
+		if value == "" {
+			value = "-"
+		}
+		fields = append(fields, map[string]interface{}{
+			"name":   field.Name,
+			"value":  truncateRunes(value, 1024),
+			"inline": true,
+		})
+	}
+
+	embed := map[string]interface{}{
+		"title":       truncateRunes(card.Title, 256),
+		"description": truncateRunes(card.Text, 4096),
+		"color":       color,
+		"fields":      fields,
+	}
+	if card.Time > 0 {
+		embed["timestamp"] = time.UnixMilli(card.Time).UTC().Format(time.RFC3339)
+	}
+	return embed
+}
+
+// sendDiscordByConfig 发送 Discord 通知，Webhook 指向论坛频道时每条告警创建一个帖子，后续提醒和恢复消息发送到该帖子
+func (n *Notifier) sendDiscordByConfig(ctx context.Context, config map[string]interface{}, channelID string, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
+	webhookURL, ok := config["webhookUrl"].(string)
+	if !ok || webhookURL == "" {
+		return fmt.Errorf("Discord 配置缺少 webhookUrl")
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("Discord webhookUrl 格式错误: %w", err)
+	}
+
+	card := n.buildChannelCard(config, agent, record, maskIP)
+	body := map[string]interface{}{
+		"embeds": []map[string]interface{}{buildDiscordEmbed(card)},
+	}
+	if username, _ := config["username"].(string); username != "" {
+		body["username"] = username
+	}
+
+	forum, _ := config["forum"].(bool)
+	thread := ""
+	query := u.Query()
+	if forum {
+		thread = n.getNotificationThread(record, channelID)
+		if thread != "" {
+			query.Set("thread_id", thread)
+		} else {
+			body["thread_name"] = truncateRunes(fmt.Sprintf("%s - %s", getAlertTypeMetadata(record.AlertType).Name, agent.Name), 100)
+		}
+		// 等待消息创建完成以获取帖子ID
+		query.Set("wait", "true")
+	}
+	u.RawQuery = query.Encode()
+
+	respBody, err := n.sendJSONRequest(ctx, u.String(), body)
+	if err != nil {
+		return err
+	}
+	if !forum || thread != "" || !startsThread(record) {
+		return nil
+	}
+
+	var resp struct {
+		ChannelID string `json:"channel_id"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("解析 Discord 响应失败: %w", err)
+	}
+	if resp.ChannelID != "" {
+		n.setNotificationThread(record, channelID, resp.ChannelID)
+	}
+	return nil
+}
+
+// buildTeamsAdaptiveCard 构建 Teams 自适应卡片消息
+func buildTeamsAdaptiveCard(card *chatCard) map[string]interface{} {
+	facts := make([]map[string]interface{}, 0, len(card.Fields))
+	for _, field := range card.Fields {
+		facts = append(facts, map[string]interface{}{"title": field.Name, "value": field.Value})
+	}
+
+	content := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"msteams": map[string]interface{}{"width": "Full"},
+		"body": []map[string]interface{}{
+			{
+				"type":  "Container",
+				"style": card.TeamsStyle,
+				"bleed": true,
+				"items": []map[string]interface{}{
+					{"type": "TextBlock", "text": card.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+				},
+			},
+			{"type": "TextBlock", "text": card.Text, "wrap": true},
+			{"type": "FactSet", "facts": facts},
+			{"type": "TextBlock", "text": "🕐 " + utils.FormatTimestamp(card.Time), "isSubtle": true, "size": "Small"},
+		},
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": content},
+		},
+	}
+}
+
+// sendTeamsByConfig 发送 Microsoft Teams 通知（Workflows 或传入 Webhook 不支持回复会话，恢复消息单独发送）
+func (n *Notifier) sendTeamsByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
+	webhookURL, ok := config["webhookUrl"].(string)
+	if !ok || webhookURL == "" {
+		return fmt.Errorf("Teams 配置缺少 webhookUrl")
+	}
+
+	_, err := n.sendJSONRequest(ctx, webhookURL, buildTeamsAdaptiveCard(n.buildChannelCard(config, agent, record, maskIP)))
+	return err
+}
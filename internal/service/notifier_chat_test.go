@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// newChatStandIn 启动记录请求并返回固定响应的本地 HTTP 服务，代替 Slack 和 Discord
+func newChatStandIn(t *testing.T, response string) (*httptest.Server, func() []capturedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []capturedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, capturedRequest{
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		mu.Unlock()
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]capturedRequest(nil), requests...)
+	}
+}
+
+// cardField 返回卡片中指定名称的字段值
+func cardField(card *chatCard, name string) (string, bool) {
+	for _, field := range card.Fields {
+		if field.Name == name {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestBuildChatCard(t *testing.T) {
+	n := NewNotifier(zap.NewNop())
+
+	t.Run("告警中", func(t *testing.T) {
+		agent, record := newIncidentTestData()
+		record.Device = "/data"
+		record.Assignee = "alice"
+		card := n.buildChatCard(agent, record, false)
+
+		if card.Color != levelColorMap["critical"] || card.TeamsStyle != "attention" {
+			t.Errorf("严重告警的颜色 = %s/%s", card.Color, card.TeamsStyle)
+		}
+		if card.Time != record.FiredAt {
+			t.Errorf("事件时间 = %d, want %d", card.Time, record.FiredAt)
+		}
+		if !strings.HasPrefix(card.Text, record.Message) {
+			t.Errorf("卡片正文 = %q", card.Text)
+		}
+		for name, want := range map[string]string{
+			"探针":  "web-01",
+			"IP":  "10.0.0.8",
+			"设备":  "/data",
+			"阈值":  "80.00%",
+			"当前值": "95.00%",
+			"负责人": "alice",
+		} {
+			if got, _ := cardField(card, name); got != want {
+				t.Errorf("字段 %s = %q, want %q", name, got, want)
+			}
+		}
+		if _, ok := cardField(card, "恢复值"); ok {
+			t.Errorf("告警中的卡片不应包含恢复值")
+		}
+	})
+
+	t.Run("已恢复", func(t *testing.T) {
+		agent, record := newIncidentTestData()
+		record.Status = "resolved"
+		record.ResolvedValue = 40
+		record.ResolvedAt = record.FiredAt + 5*60*1000
+		record.AcknowledgedBy = "bob"
+		card := n.buildChatCard(agent, record, false)
+
+		if card.Color != resolvedColor || card.TeamsStyle != "good" {
+			t.Errorf("已恢复的颜色 = %s/%s", card.Color, card.TeamsStyle)
+		}
+		if !strings.Contains(card.Title, "已恢复") {
+			t.Errorf("标题 = %q", card.Title)
+		}
+		if card.Time != record.ResolvedAt {
+			t.Errorf("事件时间 = %d, want 恢复时间 %d", card.Time, record.ResolvedAt)
+		}
+		for name, want := range map[string]string{
+			"告警值": "95.00%",
+			"恢复值": "40.00%",
+			"确认人": "bob",
+		} {
+			if got, _ := cardField(card, name); got != want {
+				t.Errorf("字段 %s = %q, want %q", name, got, want)
+			}
+		}
+		if _, ok := cardField(card, "持续时间"); !ok {
+			t.Errorf("已恢复的卡片应包含持续时间")
+		}
+		if _, ok := cardField(card, "阈值"); ok {
+			t.Errorf("已恢复的卡片不应包含阈值")
+		}
+	})
+
+	t.Run("序列消失恢复时不显示恢复值", func(t *testing.T) {
+		agent, record := newIncidentTestData()
+		record.AlertType = "promql"
+		record.Status = "resolved"
+		record.ResolvedAt = record.FiredAt + 1000
+		card := n.buildChatCard(agent, record, false)
+		if _, ok := cardField(card, "恢复值"); ok {
+			t.Errorf("PromQL 告警恢复时不应包含恢复值")
+		}
+	})
+
+	t.Run("未知级别使用默认颜色", func(t *testing.T) {
+		agent, record := newIncidentTestData()
+		record.Level = "unknown"
+		card := n.buildChatCard(agent, record, true)
+		if card.Color != levelColorMap["info"] || card.TeamsStyle != levelTeamsStyleMap["info"] {
+			t.Errorf("颜色 = %s/%s", card.Color, card.TeamsStyle)
+		}
+		if ip, _ := cardField(card, "IP"); ip != "10.0.*.*" {
+			t.Errorf("开启 IP 打码后 IP = %q", ip)
+		}
+	})
+}
+
+func TestBuildSlackBlocks(t *testing.T) {
+	card := &chatCard{
+		Title: strings.Repeat("标", 200),
+		Text:  "<b>磁盘 & 内存</b>",
+		Color: "#F5222D",
+		Time:  1700000000000,
+	}
+	for i := 0; i < 12; i++ {
+		card.Fields = append(card.Fields, chatCardField{Name: fmt.Sprintf("字段%d", i), Value: "a<b"})
+	}
+	body := buildSlackBlocks(card)
+
+	if body["text"] != card.Title {
+		t.Errorf("通知文本应使用完整标题")
+	}
+	attachment := body["attachments"].([]map[string]interface{})[0]
+	if attachment["color"] != "#F5222D" {
+		t.Errorf("附件颜色 = %v", attachment["color"])
+	}
+	blocks := attachment["blocks"].([]map[string]interface{})
+	// 标题、正文、两个字段 section、时间
+	if len(blocks) != 5 {
+		t.Fatalf("block 数量 = %d, want 5", len(blocks))
+	}
+	if title := blocks[0]["text"].(map[string]interface{})["text"].(string); len([]rune(title)) != 150 {
+		t.Errorf("标题长度 = %d, want 150", len([]rune(title)))
+	}
+	if text := blocks[1]["text"].(map[string]interface{})["text"]; text != "&lt;b&gt;磁盘 &amp; 内存&lt;/b&gt;" {
+		t.Errorf("正文未转义: %v", text)
+	}
+	first := blocks[2]["fields"].([]map[string]interface{})
+	second := blocks[3]["fields"].([]map[string]interface{})
+	if len(first) != 10 || len(second) != 2 {
+		t.Errorf("字段分组 = %d/%d, want 10/2", len(first), len(second))
+	}
+	if first[0]["text"] != "*字段0*\na&lt;b" {
+		t.Errorf("字段内容 = %v", first[0]["text"])
+	}
+	if blocks[4]["type"] != "context" {
+		t.Errorf("最后一个 block 应为时间, 实际 %v", blocks[4]["type"])
+	}
+}
+
+func TestBuildDiscordEmbed(t *testing.T) {
+	card := &chatCard{
+		Title:  "CPU 告警",
+		Text:   "CPU使用率持续超过阈值",
+		Color:  "#F5222D",
+		Fields: []chatCardField{{Name: "探针", Value: "web-01"}, {Name: "主机", Value: ""}},
+		Time:   1700000000000,
+	}
+	embed := buildDiscordEmbed(card)
+
+	if embed["color"] != int64(0xF5222D) {
+		t.Errorf("颜色 = %v, want %d", embed["color"], 0xF5222D)
+	}
+	if embed["timestamp"] != "2023-11-14T22:13:20Z" {
+		t.Errorf("时间 = %v", embed["timestamp"])
+	}
+	fields := embed["fields"].([]map[string]interface{})
+	if fields[0]["value"] != "web-01" || fields[0]["inline"] != true {
+		t.Errorf("字段 = %v", fields[0])
+	}
+	// Discord 不允许空字段值
+	if fields[1]["value"] != "-" {
+		t.Errorf("空字段值 = %v, want -", fields[1]["value"])
+	}
+
+	card.Time = 0
+	if _, ok := buildDiscordEmbed(card)["timestamp"]; ok {
+		t.Errorf("没有事件时间时不应设置 timestamp")
+	}
+}
+
+func TestBuildTeamsAdaptiveCard(t *testing.T) {
+	card := &chatCard{
+		Title:      "CPU 告警",
+		Text:       "CPU使用率持续超过阈值",
+		TeamsStyle: "attention",
+		Fields:     []chatCardField{{Name: "探针", Value: "web-01"}},
+		Time:       1700000000000,
+	}
+	body := buildTeamsAdaptiveCard(card)
+
+	if body["type"] != "message" {
+		t.Errorf("消息类型 = %v", body["type"])
+	}
+	attachment := body["attachments"].([]map[string]interface{})[0]
+	if attachment["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("附件类型 = %v", attachment["contentType"])
+	}
+	items := attachment["content"].(map[string]interface{})["body"].([]map[string]interface{})
+	if items[0]["style"] != "attention" {
+		t.Errorf("标题容器样式 = %v", items[0]["style"])
+	}
+	title := items[0]["items"].([]map[string]interface{})[0]
+	if title["text"] != card.Title {
+		t.Errorf("标题 = %v", title["text"])
+	}
+	if items[1]["text"] != card.Text {
+		t.Errorf("正文 = %v", items[1]["text"])
+	}
+	facts := items[2]["facts"].([]map[string]interface{})
+	if len(facts) != 1 || facts[0]["title"] != "探针" || facts[0]["value"] != "web-01" {
+		t.Errorf("字段 = %v", facts)
+	}
+}
+
+func TestSendSlackThread(t *testing.T) {
+	server, requests := newChatStandIn(t, `{"ok":true,"ts":"1700000000.000100"}`)
+	original := slackAPIURL
+	slackAPIURL = server.URL + "/api"
+	t.Cleanup(func() { slackAPIURL = original })
+
+	n := NewNotifier(zap.NewNop())
+	config := map[string]interface{}{"botToken": "xoxb-test", "channel": "#alerts"}
+	agent, record := newIncidentTestData()
+	ctx := context.Background()
+
+	if err := n.sendSlackByConfig(ctx, config, "slack-1", agent, record, false); err != nil {
+		t.Fatalf("发送触发消息失败: %v", err)
+	}
+	if thread := record.NotificationThreads.Data()["slack-1"]; thread != "1700000000.000100" {
+		t.Fatalf("触发消息的会话 = %q", thread)
+	}
+
+	record.Status = "resolved"
+	record.ResolvedAt = record.FiredAt + 1000
+	if err := n.sendSlackByConfig(ctx, config, "slack-1", agent, record, false); err != nil {
+		t.Fatalf("发送恢复消息失败: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("请求数量 = %d, want 2", len(got))
+	}
+	for _, req := range got {
+		if req.Path != "/api/chat.postMessage" || req.Header.Get("Authorization") != "Bearer xoxb-test" {
+			t.Errorf("请求 = %s %s", req.Path, req.Header.Get("Authorization"))
+		}
+		if req.Body["channel"] != "#alerts" {
+			t.Errorf("频道 = %v", req.Body["channel"])
+		}
+	}
+	if _, ok := got[0].Body["thread_ts"]; ok {
+		t.Errorf("触发消息不应回复到会话")
+	}
+	if got[1].Body["thread_ts"] != "1700000000.000100" {
+		t.Errorf("恢复消息应回复到触发消息所在会话, 实际 %v", got[1].Body["thread_ts"])
+	}
+}
+
+func TestSendSlackWebhook(t *testing.T) {
+	server, requests := newChatStandIn(t, "ok")
+	n := NewNotifier(zap.NewNop())
+	agent, record := newIncidentTestData()
+
+	if err := n.sendSlackByConfig(context.Background(), map[string]interface{}{}, "slack-1", agent, record, false); err == nil {
+		t.Errorf("缺少 webhookUrl 和 botToken 时应返回错误")
+	}
+	if err := n.sendSlackByConfig(context.Background(), map[string]interface{}{"webhookUrl": server.URL + "/hook"}, "slack-1", agent, record, false); err != nil {
+		t.Fatalf("sendSlackByConfig() 失败: %v", err)
+	}
+	got := requests()
+	if len(got) != 1 || got[0].Path != "/hook" {
+		t.Fatalf("请求 = %+v", got)
+	}
+	if _, ok := got[0].Body["channel"]; ok {
+		t.Errorf("Incoming Webhook 不应指定频道")
+	}
+	if len(record.NotificationThreads.Data()) != 0 {
+		t.Errorf("Incoming Webhook 不支持会话")
+	}
+}
+
+func TestSendDiscordForumThread(t *testing.T) {
+	server, requests := newChatStandIn(t, `{"id":"m1","channel_id":"thread-1"}`)
+	n := NewNotifier(zap.NewNop())
+	config := map[string]interface{}{"webhookUrl": server.URL + "/webhooks/1/token", "forum": true, "username": "Pika"}
+	agent, record := newIncidentTestData()
+	ctx := context.Background()
+
+	if err := n.sendDiscordByConfig(ctx, config, "discord-1", agent, record, false); err != nil {
+		t.Fatalf("发送触发消息失败: %v", err)
+	}
+	if thread := record.NotificationThreads.Data()["discord-1"]; thread != "thread-1" {
+		t.Fatalf("触发消息的帖子 = %q", thread)
+	}
+
+	record.Status = "resolved"
+	record.ResolvedAt = record.FiredAt + 1000
+	if err := n.sendDiscordByConfig(ctx, config, "discord-1", agent, record, false); err != nil {
+		t.Fatalf("发送恢复消息失败: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("请求数量 = %d, want 2", len(got))
+	}
+	if got[0].Query != "wait=true" || got[0].Body["thread_name"] != "CPU告警 - web-01" {
+		t.Errorf("触发消息应创建帖子: %s %v", got[0].Query, got[0].Body["thread_name"])
+	}
+	if got[0].Body["username"] != "Pika" {
+		t.Errorf("用户名 = %v", got[0].Body["username"])
+	}
+	if got[1].Query != "thread_id=thread-1&wait=true" {
+		t.Errorf("恢复消息应发送到帖子, 实际 %s", got[1].Query)
+	}
+	if _, ok := got[1].Body["thread_name"]; ok {
+		t.Errorf("恢复消息不应创建新帖子")
+	}
+}
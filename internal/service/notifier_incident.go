@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+const (
+	defaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	defaultOpsgenieAPIURL     = "https://api.opsgenie.com"
+)
+
+// PagerDuty 事件级别映射
+var levelPagerDutySeverityMap = map[string]string{
+	"info":     "info",
+	"warning":  "warning",
+	"critical": "critical",
+}
+
+// Opsgenie 告警优先级映射
+var levelOpsgeniePriorityMap = map[string]string{
+	"info":     "P5",
+	"warning":  "P3",
+	"critical": "P1",
+}
+
+// incidentDedupKey 同一告警状态的触发和恢复使用相同的去重标识，恢复时自动关闭对应事件
+func incidentDedupKey(record *models.AlertRecord) string {
+	if record.StateID != "" {
+		return record.StateID
+	}
+	return fmt.Sprintf("pika-alert-%d", record.ID)
+}
+
+// incidentDetails 事件附加信息
+func incidentDetails(card *chatCard, record *models.AlertRecord) map[string]string {
+	details := map[string]string{
+		"告警类型": record.AlertType,
+		"告警级别": record.Level,
+		"告警消息": record.Message,
+	}
+	for _, field := range card.Fields {
+		details[field.Name] = field.Value
+	}
+	return details
+}
+
+// sendPagerDutyByConfig 通过 PagerDuty Events v2 触发或恢复事件，仅处理告警触发和恢复
+func (n *Notifier) sendPagerDutyByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
+	routingKey, ok := config["routingKey"].(string)
+	if !ok || routingKey == "" {
+		return fmt.Errorf("PagerDuty 配置缺少 routingKey")
+	}
+	eventsURL := defaultPagerDutyEventsURL
+	if v, ok := config["apiUrl"].(string); ok && v != "" {
+		eventsURL = v
+	}
+
+	body := map[string]interface{}{
+		"routing_key": routingKey,
+		"dedup_key":   incidentDedupKey(record),
+	}
+	switch record.Status {
+	case "firing":
+		card := n.buildChannelCard(config, agent, record, maskIP)
+		severity, ok := levelPagerDutySeverityMap[record.Level]
+		if !ok {
+			severity = "warning"
+		}
+		summary := fmt.Sprintf("%s %s: %s", card.Title, agent.Name, card.Text)
+		body["event_action"] = "trigger"
+		body["payload"] = map[string]interface{}{
+			"summary":        truncateRunes(summary, 1024),
+			"source":         agent.Name,
+			"severity":       severity,
+			"timestamp":      time.UnixMilli(record.FiredAt).UTC().Format(time.RFC3339),
+			"component":      agent.Hostname,
+			"class":          record.AlertType,
+			"custom_details": incidentDetails(card, record),
+		}
+	case "resolved":
+		body["event_action"] = "resolve"
+	default:
+		// 通知类事件没有恢复，不创建事件
+		return nil
+	}
+
+	_, err := n.sendJSONRequest(ctx, eventsURL, body)
+	return err
+}
+
+// sendOpsgenieByConfig 通过 Opsgenie Alert API 创建或关闭告警，以去重标识作为告警别名
+func (n *Notifier) sendOpsgenieByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
+	apiKey, ok := config["apiKey"].(string)
+	if !ok || apiKey == "" {
+		return fmt.Errorf("Opsgenie 配置缺少 apiKey")
+	}
+	apiURL := defaultOpsgenieAPIURL
+	if v, ok := config["apiUrl"].(string); ok && v != "" {
+		apiURL = strings.TrimSuffix(v, "/")
+	}
+	headers := map[string]string{"Authorization": "GenieKey " + apiKey}
+	alias := incidentDedupKey(record)
+
+	switch record.Status {
+	case "firing":
+		card := n.buildChannelCard(config, agent, record, maskIP)
+		priority, ok := levelOpsgeniePriorityMap[record.Level]
+		if !ok {
+			priority = "P3"
+		}
+		body := map[string]interface{}{
+			"message":     truncateRunes(fmt.Sprintf("%s %s", card.Title, agent.Name), 130),
+			"alias":       alias,
+			"description": truncateRunes(card.Text, 15000),
+			"priority":    priority,
+			"source":      "Pika",
+			"entity":      agent.Name,
+			"tags":        []string{"pika", record.AlertType},
+			"details":     incidentDetails(card, record),
+		}
+		_, err := n.sendJSONRequestWithHeaders(ctx, apiURL+"/v2/alerts", body, headers)
+		return err
+	case "resolved":
+		closeURL := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", apiURL, url.PathEscape(alias))
+		body := map[string]interface{}{
+			"source": "Pika",
+			"note":   record.Message,
+		}
+		_, err := n.sendJSONRequestWithHeaders(ctx, closeURL, body, headers)
+		return err
+	default:
+		// 通知类事件没有恢复，不创建告警
+		return nil
+	}
+}
+
+// sendIncidentTest 测试事件管理渠道：触发一条测试事件后立即恢复，避免遗留未关闭的事件
+func (n *Notifier) sendIncidentTest(ctx context.Context, message string, send func(agent *models.Agent, record *models.AlertRecord) error) error {
+	agent, record := newTestNotificationData(message)
+	record.StateID = fmt.Sprintf("pika-test-%d", record.FiredAt)
+	if err := send(agent, record); err != nil {
+		return err
+	}
+	record.Status = "resolved"
+	record.ResolvedAt = time.Now().UnixMilli()
+	return send(agent, record)
+}
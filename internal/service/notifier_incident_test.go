@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+type capturedRequest struct {
+	Path   string
+	Query  string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// newIncidentStandIn 启动记录请求的本地 HTTP 服务，代替 PagerDuty 和 Opsgenie
+func newIncidentStandIn(t *testing.T) (*httptest.Server, func() []capturedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []capturedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, capturedRequest{
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]capturedRequest(nil), requests...)
+	}
+}
+
+func newIncidentTestData() (*models.Agent, *models.AlertRecord) {
+	agent := &models.Agent{
+		ID:       "agent-1",
+		Name:     "web-01",
+		Hostname: "web-01.local",
+		IP:       "10.0.0.8",
+	}
+	record := &models.AlertRecord{
+		ID:          42,
+		AgentID:     agent.ID,
+		AgentName:   agent.Name,
+		AlertType:   "cpu",
+		Message:     "CPU使用率持续超过阈值",
+		Threshold:   80,
+		ActualValue: 95,
+		Level:       "critical",
+		Status:      "firing",
+		FiredAt:     1700000000000,
+		StateID:     "agent-1:global:cpu",
+	}
+	return agent, record
+}
+
+func TestSendPagerDutyTriggerAndResolve(t *testing.T) {
+	server, requests := newIncidentStandIn(t)
+	n := NewNotifier(zap.NewNop())
+	config := map[string]interface{}{
+		"routingKey": "test-routing-key",
+		"apiUrl":     server.URL + "/v2/enqueue",
+	}
+	agent, record := newIncidentTestData()
+
+	if err := n.sendPagerDutyByConfig(context.Background(), config, agent, record, false); err != nil {
+		t.Fatalf("触发事件失败: %v", err)
+	}
+	record.Status = "resolved"
+	if err := n.sendPagerDutyByConfig(context.Background(), config, agent, record, false); err != nil {
+		t.Fatalf("解决事件失败: %v", err)
+	}
+	// 通知类事件没有恢复，不创建事件
+	notice := *record
+	notice.Status = "notice"
+	if err := n.sendPagerDutyByConfig(context.Background(), config, agent, &notice, false); err != nil {
+		t.Fatalf("通知事件不应返回错误: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("应发送 2 个请求，实际 %d 个", len(got))
+	}
+	trigger, resolve := got[0].Body, got[1].Body
+	if trigger["event_action"] != "trigger" || resolve["event_action"] != "resolve" {
+		t.Errorf("事件动作不正确: %v, %v", trigger["event_action"], resolve["event_action"])
+	}
+	if trigger["routing_key"] != "test-routing-key" {
+		t.Errorf("routing_key = %v", trigger["routing_key"])
+	}
+	if trigger["dedup_key"] != record.StateID || resolve["dedup_key"] != record.StateID {
+		t.Errorf("触发和解决应使用相同的 dedup_key: %v, %v", trigger["dedup_key"], resolve["dedup_key"])
+	}
+	payload, _ := trigger["payload"].(map[string]interface{})
+	if payload["severity"] != "critical" || payload["source"] != "web-01" || payload["class"] != "cpu" {
+		t.Errorf("事件内容不正确: %v", payload)
+	}
+	if summary, _ := payload["summary"].(string); !strings.Contains(summary, record.Message) {
+		t.Errorf("摘要应包含告警消息: %q", summary)
+	}
+	if _, ok := resolve["payload"]; ok {
+		t.Errorf("解决事件不应包含 payload")
+	}
+}
+
+func TestSendPagerDutyTemplate(t *testing.T) {
+	server, requests := newIncidentStandIn(t)
+	n := NewNotifier(zap.NewNop())
+	config := map[string]interface{}{
+		"routingKey": "test-routing-key",
+		"apiUrl":     server.URL,
+		"templates": map[string]interface{}{
+			NotificationEventFiring: "{{.Agent.Name}} CPU {{round .Record.ActualValue 0}}%",
+		},
+	}
+	agent, record := newIncidentTestData()
+
+	if err := n.sendPagerDutyByConfig(context.Background(), config, agent, record, false); err != nil {
+		t.Fatalf("触发事件失败: %v", err)
+	}
+	payload, _ := requests()[0].Body["payload"].(map[string]interface{})
+	if summary, _ := payload["summary"].(string); !strings.HasSuffix(summary, "web-01 CPU 95%") {
+		t.Errorf("摘要应使用模板渲染结果: %q", summary)
+	}
+}
+
+func TestSendOpsgenieCreateAndClose(t *testing.T) {
+	server, requests := newIncidentStandIn(t)
+	n := NewNotifier(zap.NewNop())
+	config := map[string]interface{}{
+		"apiKey": "test-api-key",
+		"apiUrl": server.URL + "/",
+	}
+	agent, record := newIncidentTestData()
+
+	if err := n.sendOpsgenieByConfig(context.Background(), config, agent, record, false); err != nil {
+		t.Fatalf("创建告警失败: %v", err)
+	}
+	record.Status = "resolved"
+	if err := n.sendOpsgenieByConfig(context.Background(), config, agent, record, false); err != nil {
+		t.Fatalf("关闭告警失败: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("应发送 2 个请求，实际 %d 个", len(got))
+	}
+	create, closeReq := got[0], got[1]
+	for _, req := range got {
+		if req.Header.Get("Authorization") != "GenieKey test-api-key" {
+			t.Errorf("认证头不正确: %q", req.Header.Get("Authorization"))
+		}
+	}
+	if create.Path != "/v2/alerts" {
+		t.Errorf("创建告警路径 = %s", create.Path)
+	}
+	if create.Body["alias"] != record.StateID || create.Body["priority"] != "P1" {
+		t.Errorf("告警内容不正确: %v", create.Body)
+	}
+	if closeReq.Path != "/v2/alerts/"+record.StateID+"/close" || closeReq.Query != "identifierType=alias" {
+		t.Errorf("关闭告警应使用相同的别名: %s?%s", closeReq.Path, closeReq.Query)
+	}
+}
+
+func TestIncidentDedupKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		record models.AlertRecord
+		want   string
+	}{
+		{"使用告警状态ID", models.AlertRecord{ID: 1, StateID: "agent-1:global:cpu"}, "agent-1:global:cpu"},
+		{"无状态ID时使用记录ID", models.AlertRecord{ID: 7}, "pika-alert-7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := incidentDedupKey(&tt.record); got != tt.want {
+				t.Errorf("incidentDedupKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+const (
+	defaultNtfyServerURL = "https://ntfy.sh"
+	defaultBarkServerURL = "https://api.day.app"
+)
+
+// ntfy 消息优先级映射（1-5，5 为最高）
+var ntfyPriorityMap = map[string]int{
+	"info":     3,
+	"warning":  4,
+	"critical": 5,
+	"resolved": 3,
+}
+
+// Gotify 消息优先级映射（0-10，8 以上在客户端持续提醒）
+var gotifyPriorityMap = map[string]int{
+	"info":     4,
+	"warning":  7,
+	"critical": 10,
+	"resolved": 4,
+}
+
+// Bark 中断级别映射，critical 在静音和勿扰模式下仍会响铃
+var barkLevelMap = map[string]string{
+	"info":     "active",
+	"warning":  "timeSensitive",
+	"critical": "critical",
+	"resolved": "active",
+}
+
+// ServerChan³ 的 SendKey 格式为 sctp{uid}t...
+var serverChan3KeyRegexp = regexp.MustCompile(`^sctp(\d+)t`)
+
+// pushPriorityKey 推送优先级映射的键，恢复消息使用普通优先级
+func pushPriorityKey(record *models.AlertRecord) string {
+	if record.Status == "resolved" {
+		return "resolved"
+	}
+	if _, ok := ntfyPriorityMap[record.Level]; ok {
+		return record.Level
+	}
+	return "info"
+}
+
+// splitPushMessage 将通知消息拆分为标题（首行）和正文，只有一行时正文与标题相同
+func splitPushMessage(message string) (string, string) {
+	title, body, _ := strings.Cut(message, "\n")
+	title, body = strings.TrimSpace(title), strings.TrimSpace(body)
+	if body == "" {
+		body = title
+	}
+	return title, body
+}
+
+// buildClickURL 根据配置的 Pika 访问地址生成探针详情页链接，未配置时返回空字符串
+func buildClickURL(config map[string]interface{}, agent *models.Agent) string {
+	clickURL, _ := config["clickUrl"].(string)
+	clickURL = strings.TrimSuffix(strings.TrimSpace(clickURL), "/")
+	if clickURL == "" || agent == nil || agent.ID == "" {
+		return ""
+	}
+	return clickURL + "/admin/agents/" + url.PathEscape(agent.ID)
+}
+
+// sendNtfyByConfig 发送 ntfy 通知
+func (n *Notifier) sendNtfyByConfig(ctx context.Context, config map[string]interface{}, message string, agent *models.Agent, record *models.AlertRecord) error {
+	topic, ok := config["topic"].(string)
+	if !ok || topic == "" {
+		return fmt.Errorf("ntfy 配置缺少 topic")
+	}
+	serverURL := defaultNtfyServerURL
+	if v, ok := config["serverUrl"].(string); ok && v != "" {
+		serverURL = strings.TrimSuffix(v, "/")
+	}
+
+	title, body := splitPushMessage(message)
+	payload := map[string]interface{}{
+		"topic":    topic,
+		"title":    title,
+		"message":  body,
+		"priority": ntfyPriorityMap[pushPriorityKey(record)],
+	}
+	if clickURL := buildClickURL(config, agent); clickURL != "" {
+		payload["click"] = clickURL
+	}
+
+	var headers map[string]string
+	if token, _ := config["token"].(string); token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + token}
+	}
+	_, err := n.sendJSONRequestWithHeaders(ctx, serverURL, payload, headers)
+	return err
+}
+
+// sendGotifyByConfig 发送 Gotify 通知
+func (n *Notifier) sendGotifyByConfig(ctx context.Context, config map[string]interface{}, message string, agent *models.Agent, record *models.AlertRecord) error {
+	serverURL, ok := config["serverUrl"].(string)
+	if !ok || serverURL == "" {
+		return fmt.Errorf("Gotify 配置缺少 serverUrl")
+	}
+	token, ok := config["token"].(string)
+	if !ok || token == "" {
+		return fmt.Errorf("Gotify 配置缺少 token")
+	}
+
+	title, body := splitPushMessage(message)
+	payload := map[string]interface{}{
+		"title":    title,
+		"message":  body,
+		"priority": gotifyPriorityMap[pushPriorityKey(record)],
+	}
+	if clickURL := buildClickURL(config, agent); clickURL != "" {
+		payload["extras"] = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{"url": clickURL},
+			},
+		}
+	}
+
+	_, err := n.sendJSONRequestWithHeaders(ctx, strings.TrimSuffix(serverURL, "/")+"/message", payload, map[string]string{
+		"X-Gotify-Key": token,
+	})
+	return err
+}
+
+// sendBarkByConfig 发送 Bark 通知
+func (n *Notifier) sendBarkByConfig(ctx context.Context, config map[string]interface{}, message string, agent *models.Agent, record *models.AlertRecord) error {
+	deviceKey, ok := config["deviceKey"].(string)
+	if !ok || deviceKey == "" {
+		return fmt.Errorf("Bark 配置缺少 deviceKey")
+	}
+	serverURL := defaultBarkServerURL
+	if v, ok := config["serverUrl"].(string); ok && v != "" {
+		serverURL = strings.TrimSuffix(v, "/")
+	}
+
+	title, body := splitPushMessage(message)
+	payload := map[string]interface{}{
+		"device_key": deviceKey,
+		"title":      title,
+		"body":       body,
+		"level":      barkLevelMap[pushPriorityKey(record)],
+		"group":      "Pika",
+	}
+	if clickURL := buildClickURL(config, agent); clickURL != "" {
+		payload["url"] = clickURL
+	}
+
+	_, err := n.sendJSONRequest(ctx, serverURL+"/push", payload)
+	return err
+}
+
+// serverChanSendURL 生成 Server 酱发送地址，配置了服务地址时优先使用
+func serverChanSendURL(serverURL, sendKey string) string {
+	if serverURL != "" {
+		return fmt.Sprintf("%s/%s.send", strings.TrimSuffix(serverURL, "/"), sendKey)
+	}
+	if matches := serverChan3KeyRegexp.FindStringSubmatch(sendKey); matches != nil {
+		return fmt.Sprintf("https://%s.push.ft07.com/send/%s.send", matches[1], sendKey)
+	}
+	return fmt.Sprintf("https://sctapi.ftqq.com/%s.send", sendKey)
+}
+
+// sendServerChanByConfig 发送 Server 酱通知（不支持优先级），根据 SendKey 自动区分 Server 酱 Turbo 和 Server 酱³
+func (n *Notifier) sendServerChanByConfig(ctx context.Context, config map[string]interface{}, message string, agent *models.Agent) error {
+	sendKey, ok := config["sendKey"].(string)
+	if !ok || sendKey == "" {
+		return fmt.Errorf("Server 酱配置缺少 sendKey")
+	}
+
+	serverURL, _ := config["serverUrl"].(string)
+	sendURL := serverChanSendURL(serverURL, sendKey)
+
+	title, body := splitPushMessage(message)
+	// 正文为 Markdown，保留换行
+	desp := strings.ReplaceAll(body, "\n", "\n\n")
+	if clickURL := buildClickURL(config, agent); clickURL != "" {
+		desp += fmt.Sprintf("\n\n[查看探针](%s)", clickURL)
+	}
+	payload := map[string]interface{}{
+		"title": truncateRunes(title, 32),
+		"desp":  desp,
+	}
+
+	respBody, err := n.sendJSONRequest(ctx, sendURL, payload)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("解析 Server 酱响应失败: %w", err)
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("Server 酱返回错误: %s", resp.Message)
+	}
+	return nil
+}
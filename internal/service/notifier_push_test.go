@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestPushPriority(t *testing.T) {
+	tests := []struct {
+		name   string
+		record models.AlertRecord
+		ntfy   int
+		gotify int
+		bark   string
+	}{
+		{"严重告警", models.AlertRecord{Level: "critical", Status: "firing"}, 5, 10, "critical"},
+		{"警告告警", models.AlertRecord{Level: "warning", Status: "firing"}, 4, 7, "timeSensitive"},
+		{"未知级别按普通处理", models.AlertRecord{Level: "unknown", Status: "firing"}, 3, 4, "active"},
+		{"恢复消息使用普通优先级", models.AlertRecord{Level: "critical", Status: "resolved"}, 3, 4, "active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := pushPriorityKey(&tt.record)
+			if ntfyPriorityMap[key] != tt.ntfy || gotifyPriorityMap[key] != tt.gotify || barkLevelMap[key] != tt.bark {
+				t.Errorf("优先级 = %d/%d/%s, want %d/%d/%s",
+					ntfyPriorityMap[key], gotifyPriorityMap[key], barkLevelMap[key], tt.ntfy, tt.gotify, tt.bark)
+			}
+		})
+	}
+}
+
+func TestSplitPushMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		title   string
+		body    string
+	}{
+		{"首行为标题", "🔴 CPU告警\n探针: web-01\n当前值: 95%", "🔴 CPU告警", "探针: web-01\n当前值: 95%"},
+		{"只有一行时正文与标题相同", "CPU告警", "CPU告警", "CPU告警"},
+		{"去除首尾空白", "  CPU告警  \n\n 探针: web-01 \n", "CPU告警", "探针: web-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, body := splitPushMessage(tt.message)
+			if title != tt.title || body != tt.body {
+				t.Errorf("splitPushMessage() = %q, %q, want %q, %q", title, body, tt.title, tt.body)
+			}
+		})
+	}
+}
+
+func TestBuildClickURL(t *testing.T) {
+	agent := &models.Agent{ID: "agent 1"}
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		agent  *models.Agent
+		want   string
+	}{
+		{"未配置访问地址", map[string]interface{}{}, agent, ""},
+		{"去除末尾斜杠并转义探针ID", map[string]interface{}{"clickUrl": " https://pika.example.com/ "}, agent, "https://pika.example.com/admin/agents/agent%201"},
+		{"没有探针", map[string]interface{}{"clickUrl": "https://pika.example.com"}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildClickURL(tt.config, tt.agent); got != tt.want {
+				t.Errorf("buildClickURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendNtfy(t *testing.T) {
+	server, requests := newChatStandIn(t, `{"id":"m1"}`)
+	n := NewNotifier(zap.NewNop())
+	agent, record := newIncidentTestData()
+	ctx := context.Background()
+
+	if err := n.sendNtfyByConfig(ctx, map[string]interface{}{}, "CPU告警", agent, record); err == nil {
+		t.Errorf("缺少 topic 时应返回错误")
+	}
+	config := map[string]interface{}{
+		"serverUrl": server.URL + "/",
+		"topic":     "pika",
+		"token":     "tk_test",
+		"clickUrl":  "https://pika.example.com",
+	}
+	if err := n.sendNtfyByConfig(ctx, config, "CPU告警\n当前值: 95%", agent, record); err != nil {
+		t.Fatalf("sendNtfyByConfig() 失败: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("请求数量 = %d, want 1", len(got))
+	}
+	req := got[0]
+	if req.Path != "/" || req.Header.Get("Authorization") != "Bearer tk_test" {
+		t.Errorf("请求 = %s %s", req.Path, req.Header.Get("Authorization"))
+	}
+	want := map[string]interface{}{
+		"topic":    "pika",
+		"title":    "CPU告警",
+		"message":  "当前值: 95%",
+		"priority": float64(5),
+		"click":    "https://pika.example.com/admin/agents/agent-1",
+	}
+	for k, v := range want {
+		if req.Body[k] != v {
+			t.Errorf("%s = %v, want %v", k, req.Body[k], v)
+		}
+	}
+}
+
+func TestSendGotify(t *testing.T) {
+	server, requests := newChatStandIn(t, `{"id":1}`)
+	n := NewNotifier(zap.NewNop())
+	agent, record := newIncidentTestData()
+	ctx := context.Background()
+
+	if err := n.sendGotifyByConfig(ctx, map[string]interface{}{"serverUrl": server.URL}, "CPU告警", agent, record); err == nil {
+		t.Errorf("缺少 token 时应返回错误")
+	}
+	config := map[string]interface{}{"serverUrl": server.URL + "/", "token": "app-token", "clickUrl": "https://pika.example.com"}
+	record.Status = "resolved"
+	if err := n.sendGotifyByConfig(ctx, config, "CPU告警已恢复", agent, record); err != nil {
+		t.Fatalf("sendGotifyByConfig() 失败: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("请求数量 = %d, want 1", len(got))
+	}
+	req := got[0]
+	if req.Path != "/message" || req.Header.Get("X-Gotify-Key") != "app-token" {
+		t.Errorf("请求 = %s %s", req.Path, req.Header.Get("X-Gotify-Key"))
+	}
+	if req.Body["title"] != "CPU告警已恢复" || req.Body["message"] != "CPU告警已恢复" || req.Body["priority"] != float64(4) {
+		t.Errorf("请求体 = %v", req.Body)
+	}
+	extras, _ := req.Body["extras"].(map[string]interface{})
+	notification, _ := extras["client::notification"].(map[string]interface{})
+	click, _ := notification["click"].(map[string]interface{})
+	if click["url"] != "https://pika.example.com/admin/agents/agent-1" {
+		t.Errorf("点击链接 = %v", req.Body["extras"])
+	}
+}
+
+func TestSendBark(t *testing.T) {
+	server, requests := newChatStandIn(t, `{"code":200}`)
+	n := NewNotifier(zap.NewNop())
+	agent, record := newIncidentTestData()
+	ctx := context.Background()
+
+	if err := n.sendBarkByConfig(ctx, map[string]interface{}{}, "CPU告警", agent, record); err == nil {
+		t.Errorf("缺少 deviceKey 时应返回错误")
+	}
+	config := map[string]interface{}{"serverUrl": server.URL, "deviceKey": "dk"}
+	if err := n.sendBarkByConfig(ctx, config, "CPU告警\n当前值: 95%", agent, record); err != nil {
+		t.Fatalf("sendBarkByConfig() 失败: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("请求数量 = %d, want 1", len(got))
+	}
+	req := got[0]
+	if req.Path != "/push" {
+		t.Errorf("请求路径 = %s", req.Path)
+	}
+	want := map[string]interface{}{
+		"device_key": "dk",
+		"title":      "CPU告警",
+		"body":       "当前值: 95%",
+		"level":      "critical",
+		"group":      "Pika",
+	}
+	for k, v := range want {
+		if req.Body[k] != v {
+			t.Errorf("%s = %v, want %v", k, req.Body[k], v)
+		}
+	}
+	if _, ok := req.Body["url"]; ok {
+		t.Errorf("未配置访问地址时不应设置点击链接")
+	}
+}
+
+func TestServerChanSendURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverURL string
+		sendKey   string
+		want      string
+	}{
+		{"Server 酱 Turbo", "", "SCT123abc", "https://sctapi.ftqq.com/SCT123abc.send"},
+		{"Server 酱³", "", "sctp1234tabcdef", "https://1234.push.ft07.com/send/sctp1234tabcdef.send"},
+		{"自定义服务地址", "https://push.example.com/", "sctp1234tabcdef", "https://push.example.com/sctp1234tabcdef.send"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serverChanSendURL(tt.serverURL, tt.sendKey); got != tt.want {
+				t.Errorf("serverChanSendURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendServerChan(t *testing.T) {
+	agent, _ := newIncidentTestData()
+	ctx := context.Background()
+	message := strings.Repeat("告", 40) + "\n探针: web-01\n当前值: 95%"
+	config := func(serverURL string) map[string]interface{} {
+		return map[string]interface{}{"serverUrl": serverURL, "sendKey": "SCTkey", "clickUrl": "https://pika.example.com"}
+	}
+
+	t.Run("发送成功", func(t *testing.T) {
+		server, requests := newChatStandIn(t, `{"code":0,"message":""}`)
+		n := NewNotifier(zap.NewNop())
+		if err := n.sendServerChanByConfig(ctx, config(server.URL), message, agent); err != nil {
+			t.Fatalf("sendServerChanByConfig() 失败: %v", err)
+		}
+		got := requests()
+		if len(got) != 1 || got[0].Path != "/SCTkey.send" {
+			t.Fatalf("请求 = %+v", got)
+		}
+		if title := got[0].Body["title"].(string); len([]rune(title)) != 32 {
+			t.Errorf("标题长度 = %d, want 32", len([]rune(title)))
+		}
+		wantDesp := "探针: web-01\n\n当前值: 95%\n\n[查看探针](https://pika.example.com/admin/agents/agent-1)"
+		if got[0].Body["desp"] != wantDesp {
+			t.Errorf("正文 = %q, want %q", got[0].Body["desp"], wantDesp)
+		}
+	})
+
+	t.Run("返回错误码", func(t *testing.T) {
+		server, _ := newChatStandIn(t, `{"code":40001,"message":"bad pushkey"}`)
+		n := NewNotifier(zap.NewNop())
+		err := n.sendServerChanByConfig(ctx, config(server.URL), message, agent)
+		if err == nil || !strings.Contains(err.Error(), "bad pushkey") {
+			t.Errorf("应返回 Server 酱的错误信息, 实际 %v", err)
+		}
+	})
+}
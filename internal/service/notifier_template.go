@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/internal/utils"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// 通知事件类型，用于选择消息模板和匹配路由规则；防篡改、SSH 登录和流量事件同时属于对应状态（notice）
+const (
+	NotificationEventFiring   = "firing"
+	NotificationEventResolved = "resolved"
+	NotificationEventNotice   = "notice"
+	NotificationEventTamper   = "tamper"
+	NotificationEventSSHLogin = "ssh_login"
+	NotificationEventTraffic  = "traffic"
+)
+
+var notificationEvents = []string{
+	NotificationEventFiring,
+	NotificationEventResolved,
+	NotificationEventNotice,
+	NotificationEventTamper,
+	NotificationEventSSHLogin,
+	NotificationEventTraffic,
+}
+
+// NotificationTemplateData 通知模板可访问的数据
+type NotificationTemplateData struct {
+	Event     string                // 通知事件类型
+	Agent     *models.Agent         // 探针，开启 IP 打码时 IP 已打码
+	Record    *models.AlertRecord   // 告警记录
+	Metrics   *metric.LatestMetrics // 探针最新指标，各项指标未上报时为 nil，使用前需 with 判断
+	TypeName  string                // 告警类型名称
+	LevelIcon string                // 告警级别图标
+	IP        string                // 探针IP（多个地址以 / 分隔）
+	Message   string                // 默认通知消息
+}
+
+// templateFuncs 通知模板辅助函数
+var templateFuncs = template.FuncMap{
+	// bytes 字节数格式化，例如 1.50 GiB
+	"bytes": func(v any) string {
+		return formatBytes(uint64(max(templateNumber(v), 0)))
+	},
+	// duration 时长（毫秒）格式化，例如 1时2分3秒
+	"duration": func(v any) string {
+		return utils.FormatDuration(int64(templateNumber(v)))
+	},
+	// since 距指定时间戳（毫秒）的时长
+	"since": func(v any) string {
+		return utils.FormatDuration(time.Now().UnixMilli() - int64(templateNumber(v)))
+	},
+	// time 按服务器时区格式化时间戳（毫秒）
+	"time": func(v any) string {
+		return utils.FormatTimestamp(int64(templateNumber(v)))
+	},
+	// timeIn 按指定时区格式化时间戳（毫秒），例如 timeIn .Record.FiredAt "Asia/Shanghai"
+	"timeIn": func(v any, zone string) (string, error) {
+		ms := int64(templateNumber(v))
+		if ms <= 0 {
+			return "", nil
+		}
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return "", fmt.Errorf("无效的时区 %s", zone)
+		}
+		return time.UnixMilli(ms).In(loc).Format(time.DateTime), nil
+	},
+	// percent 百分比格式化，保留两位小数
+	"percent": func(v any) string {
+		return fmt.Sprintf("%.2f%%", templateNumber(v))
+	},
+	// round 保留指定位数小数
+	"round": func(v any, precision int) string {
+		return fmt.Sprintf("%.*f", precision, templateNumber(v))
+	},
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"trim":     strings.TrimSpace,
+	"join":     strings.Join,
+	"contains": strings.Contains,
+	// default 值为空时使用默认值，例如 default "-" .Agent.Remark
+	"default": func(def string, v any) string {
+		if s := fmt.Sprint(v); v != nil && s != "" {
+			return s
+		}
+		return def
+	},
+}
+
+// templateNumber 将模板中的数值统一转换为 float64
+func templateNumber(v any) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
+
+// notificationEvent 告警记录对应的通知事件类型
+func notificationEvent(record *models.AlertRecord) string {
+	switch record.AlertType {
+	case "tamper":
+		return NotificationEventTamper
+	case "ssh_login", "ssh_login_anomaly":
+		return NotificationEventSSHLogin
+	case "traffic":
+		return NotificationEventTraffic
+	}
+	return record.Status
+}
+
+// lookupNotificationTemplate 查找渠道配置中告警记录对应的模板，未配置时返回空字符串
+func lookupNotificationTemplate(config map[string]interface{}, record *models.AlertRecord) string {
+	templates, ok := config["templates"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if text, _ := templates[notificationEvent(record)].(string); strings.TrimSpace(text) != "" {
+		return text
+	}
+	// 细分事件未配置模板时回退到状态模板
+	text, _ := templates[record.Status].(string)
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	return text
+}
+
+func parseNotificationTemplate(text string) (*template.Template, error) {
+	return template.New("notification").Funcs(templateFuncs).Parse(text)
+}
+
+// renderNotificationTemplate 渲染通知模板，渲染结果为空时返回错误
+func renderNotificationTemplate(text string, data *NotificationTemplateData) (string, error) {
+	tmpl, err := parseNotificationTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("模板语法错误: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("模板渲染失败: %w", err)
+	}
+	result := strings.TrimSpace(buf.String())
+	if result == "" {
+		return "", fmt.Errorf("模板渲染结果为空")
+	}
+	return result, nil
+}
+
+// newTemplateData 构建模板数据，开启 IP 打码时探针的各个 IP 字段均已打码
+func (n *Notifier) newTemplateData(agent *models.Agent, record *models.AlertRecord, maskIP bool) *NotificationTemplateData {
+	displayAgent := *agent
+	if maskIP {
+		for _, ip := range []*string{&displayAgent.IP, &displayAgent.IPv4, &displayAgent.IPv6} {
+			if *ip != "" {
+				*ip = maskIPAddress(*ip)
+			}
+		}
+	}
+
+	metrics := &metric.LatestMetrics{}
+	if n.metricService != nil && agent.ID != "" {
+		if latest, ok := n.metricService.GetLatestMetrics(agent.ID); ok {
+			metrics = latest
+		}
+	}
+
+	return &NotificationTemplateData{
+		Event:     notificationEvent(record),
+		Agent:     &displayAgent,
+		Record:    record,
+		Metrics:   metrics,
+		TypeName:  getAlertTypeMetadata(record.AlertType).Name,
+		LevelIcon: getLevelIcon(record.Level),
+		IP:        formatAgentIP(agent, maskIP),
+		Message:   n.buildMessage(agent, record, maskIP),
+	}
+}
+
+// renderChannelTemplate 按渠道配置的模板渲染消息，未配置模板或渲染失败时返回 false
+func (n *Notifier) renderChannelTemplate(config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, maskIP bool) (string, bool) {
+	text := lookupNotificationTemplate(config, record)
+	if text == "" {
+		return "", false
+	}
+	result, err := renderNotificationTemplate(text, n.newTemplateData(agent, record, maskIP))
+	if err != nil {
+		n.logger.Warn("渲染通知模板失败，使用默认消息",
+			zap.String("alertType", record.AlertType),
+			zap.String("status", record.Status),
+			zap.Error(err),
+		)
+		return "", false
+	}
+	return result, true
+}
+
+// renderMessage 文本类渠道的通知消息，配置了模板时使用模板渲染结果，否则使用默认消息
+func (n *Notifier) renderMessage(config map[string]interface{}, message string, agent *models.Agent, record *models.AlertRecord, maskIP bool) string {
+	if result, ok := n.renderChannelTemplate(config, agent, record, maskIP); ok {
+		return result
+	}
+	return message
+}
+
+// validateNotificationTemplates 校验渠道配置中的模板语法
+func validateNotificationTemplates(channel *models.NotificationChannelConfig) error {
+	templates, ok := channel.Config["templates"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for event, value := range templates {
+		text, _ := value.(string)
+		if _, err := parseNotificationTemplate(text); err != nil {
+			return fmt.Errorf("%s 模板语法错误: %v", event, err)
+		}
+	}
+	return nil
+}
+
+// newTemplatePreviewData 构建模板预览使用的示例探针、告警记录和指标
+func newTemplatePreviewData(event string) (*models.Agent, *models.AlertRecord, *metric.LatestMetrics) {
+	now := time.Now().UnixMilli()
+	agent := &models.Agent{
+		ID:       "preview-agent",
+		Name:     "示例探针",
+		Hostname: "web-01",
+		IPv4:     "203.0.113.10",
+		IPv6:     "2001:db8::10",
+		OS:       "linux",
+		Arch:     "amd64",
+		Version:  "1.0.0",
+		Tags:     []string{"production"},
+		Status:   1,
+	}
+	record := &models.AlertRecord{
+		ID:          1,
+		AgentID:     agent.ID,
+		AgentName:   agent.Name,
+		AlertType:   "cpu",
+		Message:     "CPU使用率持续5分钟超过阈值",
+		Threshold:   80,
+		ActualValue: 92.5,
+		Level:       "warning",
+		Status:      "firing",
+		FiredAt:     now - int64(5*time.Minute/time.Millisecond),
+	}
+
+	switch event {
+	case NotificationEventResolved:
+		record.Status = "resolved"
+		record.Message = "CPU使用率已恢复正常"
+		record.ResolvedValue = 45.3
+		record.ResolvedAt = now
+	case NotificationEventNotice:
+		record.AlertType = "ssh_ban"
+		record.Status = "notice"
+		record.Message = "SSH暴力破解封禁：来源 198.51.100.7，失败 12 次"
+		record.Threshold = 5
+		record.ActualValue = 12
+	case NotificationEventTamper:
+		record.AlertType = "tamper"
+		record.Status = "notice"
+		record.Message = "防篡改事件：路径 /etc/nginx/nginx.conf，操作 write，详情 文件内容被修改，自动恢复 成功"
+		record.Threshold = 0
+		record.ActualValue = 0
+	case NotificationEventSSHLogin:
+		record.AlertType = "ssh_login"
+		record.Status = "notice"
+		record.Message = "SSH登录成功：用户 root，来源 198.51.100.7:52344，归属地 未知，终端 pts/0，会话 1024"
+		record.Threshold = 0
+		record.ActualValue = 0
+	case NotificationEventTraffic:
+		const gib = 1 << 30
+		agent.TrafficStats = datatypes.NewJSONType(models.TrafficStatsData{
+			Enabled:  true,
+			Type:     "both",
+			Limit:    1000 * gib,
+			Used:     852 * gib,
+			ResetDay: 1,
+		})
+		record.AlertType = "traffic"
+		record.Status = "notice"
+		record.Message = fmt.Sprintf("流量使用已达到80%%，当前使用85.20%%（%s/%s）", formatBytes(852*gib), formatBytes(1000*gib))
+		record.Threshold = 80
+		record.ActualValue = 85.2
+	}
+
+	metrics := &metric.LatestMetrics{
+		Timestamp: now,
+		CPU:       &protocol.CPUData{LogicalCores: 4, PhysicalCores: 2, ModelName: "Intel(R) Xeon(R)", UsagePercent: 92.5},
+		Memory:    &protocol.MemoryData{Total: 8 << 30, Used: 5 << 30, Free: 3 << 30, Available: 3 << 30, UsagePercent: 62.5},
+		Disk:      &metric.DiskSummary{UsagePercent: 71.2, TotalDisks: 1, Total: 100 << 30, Used: 71 << 30, Free: 29 << 30},
+		Network:   &metric.NetworkSummary{TotalBytesSentRate: 1 << 20, TotalBytesRecvRate: 3 << 20, TotalInterfaces: 1},
+	}
+	return agent, record, metrics
+}
+
+// PreviewTemplate 使用示例数据渲染通知模板
+func (n *Notifier) PreviewTemplate(text, event string, maskIP bool) (string, error) {
+	if event == "" {
+		event = NotificationEventFiring
+	}
+	if !slices.Contains(notificationEvents, event) {
+		return "", orz.NewError(400, "不支持的通知事件类型: "+event)
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", orz.NewError(400, "模板不能为空")
+	}
+
+	agent, record, metrics := newTemplatePreviewData(event)
+	data := n.newTemplateData(agent, record, maskIP)
+	data.Metrics = metrics
+	result, err := renderNotificationTemplate(text, data)
+	if err != nil {
+		return "", orz.NewError(400, err.Error())
+	}
+	return result, nil
+}
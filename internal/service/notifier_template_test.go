@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestLookupNotificationTemplate(t *testing.T) {
+	config := map[string]interface{}{
+		"templates": map[string]interface{}{
+			"firing":  "告警 {{.Message}}",
+			"notice":  "通知 {{.Message}}",
+			"tamper":  "   ",
+			"traffic": "流量 {{.Message}}",
+		},
+	}
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		record models.AlertRecord
+		want   string
+	}{
+		{"按状态匹配", config, models.AlertRecord{AlertType: "cpu", Status: "firing"}, "告警 {{.Message}}"},
+		{"细分事件优先", config, models.AlertRecord{AlertType: "traffic", Status: "notice"}, "流量 {{.Message}}"},
+		{"细分事件模板为空时回退到状态模板", config, models.AlertRecord{AlertType: "tamper", Status: "notice"}, "通知 {{.Message}}"},
+		{"未配置对应模板", config, models.AlertRecord{AlertType: "cpu", Status: "resolved"}, ""},
+		{"未配置模板", map[string]interface{}{}, models.AlertRecord{AlertType: "cpu", Status: "firing"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lookupNotificationTemplate(tt.config, &tt.record); got != tt.want {
+				t.Errorf("lookupNotificationTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNotificationTemplate(t *testing.T) {
+	firedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).UnixMilli()
+	data := &NotificationTemplateData{
+		Event:  NotificationEventFiring,
+		Agent:  &models.Agent{Name: "web-01", Tags: []string{"prod", "cn"}},
+		Record: &models.AlertRecord{Level: "critical", ActualValue: 92.456, FiredAt: firedAt},
+	}
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{"字段访问", "{{.Agent.Name}} {{.Event}}", "web-01 firing", ""},
+		{"百分比和小数", "{{percent .Record.ActualValue}} {{round .Record.ActualValue 1}}", "92.46% 92.5", ""},
+		{"字节数", "{{bytes 1536}}", "1.50 KiB", ""},
+		{"指定时区", `{{timeIn .Record.FiredAt "UTC"}}`, "2026-01-02 03:04:05", ""},
+		{"默认值和字符串函数", `{{default "-" .Agent.Remark}} {{upper .Record.Level}} {{join .Agent.Tags ","}}`, "- CRITICAL prod,cn", ""},
+		{"去除首尾空白", "\n  {{.Agent.Name}}  \n", "web-01", ""},
+		{"语法错误", "{{.Agent.Name", "", "模板语法错误"},
+		{"无效时区", `{{timeIn .Record.FiredAt "Mars/Base"}}`, "", "模板渲染失败"},
+		{"渲染结果为空", "{{if false}}x{{end}}", "", "模板渲染结果为空"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderNotificationTemplate(tt.text, data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("renderNotificationTemplate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderNotificationTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderNotificationTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewTemplate(t *testing.T) {
+	n := NewNotifier(zap.NewNop())
+	tests := []struct {
+		name    string
+		text    string
+		event   string
+		maskIP  bool
+		want    string
+		wantErr bool
+	}{
+		{"默认使用触发事件", "{{.Event}} {{.Record.Status}} {{.TypeName}}", "", false, "firing firing CPU告警", false},
+		{"恢复事件", "{{.Event}} {{.Record.Status}}", NotificationEventResolved, false, "resolved resolved", false},
+		{"流量事件", "{{.Event}} {{.Record.Status}} {{.Record.AlertType}}", NotificationEventTraffic, false, "traffic notice traffic", false},
+		{"示例指标", "{{with .Metrics.CPU}}{{percent .UsagePercent}}{{end}}", "", false, "92.50%", false},
+		{"IP 打码", "{{.Agent.IPv4}}", "", true, "203.0.*.*", false},
+		{"不支持的事件", "{{.Event}}", "unknown", false, "", true},
+		{"空模板", "  ", "", false, "", true},
+		{"语法错误", "{{.Event", "", false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := n.PreviewTemplate(tt.text, tt.event, tt.maskIP)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PreviewTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PreviewTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -30,6 +30,8 @@ const (
 	PropertyIDDNSProviders = "dns_providers"
 	// PropertyIDAgentInstallConfig 探针安装配置的固定 ID
 	PropertyIDAgentInstallConfig = "agent_install_config"
+	// PropertyIDAuditConfig 安全审计配置的固定 ID
+	PropertyIDAuditConfig = "audit_config"
 )
 
 var defaultPublicIPv4APIs = []string{
@@ -128,6 +130,12 @@ func (s *PropertyService) GetNotificationChannelConfigs(ctx context.Context) ([]
 	if err != nil {
 		return nil, fmt.Errorf("获取通知渠道配置失败: %w", err)
 	}
+	// 旧版本每种类型只有一个渠道，使用类型作为渠道ID
+	for i := range allChannels {
+		if allChannels[i].ID == "" {
+			allChannels[i].ID = allChannels[i].Type
+		}
+	}
 	return allChannels, nil
 }
 
@@ -152,6 +160,15 @@ func (s *PropertyService) GetPublicIPConfig(ctx context.Context) (*models.Public
 	return &config, nil
 }
 
+// GetAuditConfig 获取安全审计配置
+func (s *PropertyService) GetAuditConfig(ctx context.Context) (*models.AuditConfig, error) {
+	var config models.AuditConfig
+	if err := s.GetValue(ctx, PropertyIDAuditConfig, &config); err != nil {
+		return nil, fmt.Errorf("获取安全审计配置失败: %w", err)
+	}
+	return &config, nil
+}
+
 // GetAlertConfig 获取告警配置
 func (s *PropertyService) GetAlertConfig(ctx context.Context) (*models.AlertConfig, error) {
 	property, err := s.Get(ctx, PropertyIDAlertConfig)
@@ -167,6 +184,9 @@ func (s *PropertyService) GetAlertConfig(ctx context.Context) (*models.AlertConf
 	}
 
 	applyAlertNotificationDefaults(&config, property.Value)
+	applyAlertEscalationDefaults(&config.Escalation)
+	applyAlertInhibitRuleDefaults(&config, property.Value)
+	applyAlertFlappingDefaults(&config.Flapping)
 
 	return &config, nil
 }
@@ -175,7 +195,9 @@ func applyAlertNotificationDefaults(config *models.AlertConfig, rawValue string)
 	defaults := models.AlertNotifications{
 		TrafficEnabled:         true,
 		SSHLoginSuccessEnabled: true,
+		SSHBanEnabled:          true,
 		TamperEventEnabled:     true,
+		AuditDriftEnabled:      true,
 	}
 
 	if rawValue == "" {
@@ -207,9 +229,56 @@ func applyAlertNotificationDefaults(config *models.AlertConfig, rawValue string)
 	if _, ok := notificationsMap["sshLoginSuccessEnabled"]; !ok {
 		config.Notifications.SSHLoginSuccessEnabled = true
 	}
+	if _, ok := notificationsMap["sshBanEnabled"]; !ok {
+		config.Notifications.SSHBanEnabled = true
+	}
 	if _, ok := notificationsMap["tamperEventEnabled"]; !ok {
 		config.Notifications.TamperEventEnabled = true
 	}
+	if _, ok := notificationsMap["auditDriftEnabled"]; !ok {
+		config.Notifications.AuditDriftEnabled = true
+	}
+}
+
+func applyAlertEscalationDefaults(config *models.AlertEscalation) {
+	if config.RepeatInterval <= 0 {
+		config.RepeatInterval = 30
+	}
+	if config.EscalationTimeout <= 0 {
+		config.EscalationTimeout = 60
+	}
+}
+
+func applyAlertFlappingDefaults(config *models.AlertFlapping) {
+	if config.Window <= 0 {
+		config.Window = 30
+	}
+	if config.Threshold < 2 {
+		config.Threshold = 6
+	}
+}
+
+// defaultAlertInhibitRules 默认抑制规则：探针离线时不再通知该探针的服务、证书和指标告警
+func defaultAlertInhibitRules() []models.AlertInhibitRule {
+	return []models.AlertInhibitRule{
+		{
+			Name:        "探针离线抑制",
+			Enabled:     true,
+			SourceTypes: []string{"agent_offline"},
+			TargetTypes: []string{"service", "cert", "cpu", "memory", "disk", "network", "promql"},
+		},
+	}
+}
+
+// applyAlertInhibitRuleDefaults 旧配置中没有抑制规则字段时使用默认规则，已保存为空列表则保持为空
+func applyAlertInhibitRuleDefaults(config *models.AlertConfig, rawValue string) {
+	var raw map[string]json.RawMessage
+	if rawValue != "" && json.Unmarshal([]byte(rawValue), &raw) == nil {
+		if _, ok := raw["inhibitRules"]; ok {
+			return
+		}
+	}
+	config.InhibitRules = defaultAlertInhibitRules()
 }
 
 func applyPublicIPConfigDefaults(config *models.PublicIPConfig) {
@@ -381,7 +450,9 @@ func (s *PropertyService) InitializeDefaultConfigs(ctx context.Context) error {
 				Notifications: models.AlertNotifications{
 					TrafficEnabled:         true,
 					SSHLoginSuccessEnabled: true,
+					SSHBanEnabled:          true,
 					TamperEventEnabled:     true,
+					AuditDriftEnabled:      true,
 				},
 				Rules: models.AlertRules{
 					CPUEnabled:           true,
@@ -402,6 +473,17 @@ func (s *PropertyService) InitializeDefaultConfigs(ctx context.Context) error {
 					ServiceDuration:      300, // 5分钟
 					AgentOfflineEnabled:  true,
 					AgentOfflineDuration: 300, // 5分钟
+					DiskForecastHours:    24,
+					DiskForecastLookback: 6,
+				},
+				Escalation: models.AlertEscalation{
+					RepeatInterval:    30, // 30分钟
+					EscalationTimeout: 60, // 60分钟
+				},
+				InhibitRules: defaultAlertInhibitRules(),
+				Flapping: models.AlertFlapping{
+					Window:    30, // 30分钟
+					Threshold: 6,
 				},
 			},
 		},
@@ -415,6 +497,14 @@ func (s *PropertyService) InitializeDefaultConfigs(ctx context.Context) error {
 			Name:  "探针安装配置",
 			Value: models.AgentInstallConfig{ServerURL: ""}, // 默认空字符串，使用自动检测
 		},
+		{
+			ID:   PropertyIDAuditConfig,
+			Name: "安全审计配置",
+			Value: models.AuditConfig{
+				RetentionDays:      90,
+				MaxResultsPerAgent: 100,
+			},
+		},
 	}
 
 	// 遍历并初始化每个配置
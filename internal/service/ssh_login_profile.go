@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+const (
+	// sshProfileWindow 构建登录画像使用的历史范围
+	sshProfileWindow = 90 * 24 * time.Hour
+	// sshProfileMaxEvents 构建登录画像最多使用的历史事件数
+	sshProfileMaxEvents = 2000
+	// sshProfileMinLogins 用户历史登录次数达到该值后才检测新国家、新 ASN 等异常，避免首次登录误报
+	sshProfileMinLogins = 3
+	// maxTravelSpeed 两次登录之间允许的最大位移速度（公里/小时），超过视为不可能的位移
+	maxTravelSpeed = 1000.0
+	// minTravelDistance 小于该距离（公里）的位移不检测，避免 GeoIP 定位误差导致误报
+	minTravelDistance = 500.0
+)
+
+// SSHLoginProfileEntry 登录画像中的一项（国家、ASN 或网段）
+type SSHLoginProfileEntry struct {
+	Key      string `json:"key"`            // 国家代码 / ASN / 网段
+	Name     string `json:"name,omitempty"` // 归属地 / 运营商
+	Count    int    `json:"count"`          // 登录次数
+	LastSeen int64  `json:"lastSeen"`       // 最近一次登录时间（毫秒）
+}
+
+// SSHLoginProfile 登录画像，由历史登录成功事件构建
+type SSHLoginProfile struct {
+	AgentID   string                 `json:"agentId"`
+	Username  string                 `json:"username,omitempty"` // 为空表示探针级画像（所有用户）
+	Since     int64                  `json:"since"`              // 画像统计起始时间（毫秒）
+	Logins    int                    `json:"logins"`             // 登录次数
+	Countries []SSHLoginProfileEntry `json:"countries"`          // 常用国家
+	ASNs      []SSHLoginProfileEntry `json:"asns"`               // 常用运营商
+	Networks  []SSHLoginProfileEntry `json:"networks"`           // 常用来源网段（IPv4 /24，IPv6 /48）
+	Hours     [24]int                `json:"hours"`              // 各时段（服务端本地时间）的登录次数
+
+	countries map[string]*SSHLoginProfileEntry
+	asns      map[string]*SSHLoginProfileEntry
+	networks  map[string]*SSHLoginProfileEntry
+}
+
+func newSSHLoginProfile(agentID, username string, since int64) *SSHLoginProfile {
+	return &SSHLoginProfile{
+		AgentID:   agentID,
+		Username:  username,
+		Since:     since,
+		countries: make(map[string]*SSHLoginProfileEntry),
+		asns:      make(map[string]*SSHLoginProfileEntry),
+		networks:  make(map[string]*SSHLoginProfileEntry),
+	}
+}
+
+// add 将一次登录计入画像
+func (p *SSHLoginProfile) add(event *models.SSHLoginEvent, geo *GeoIPInfo) {
+	p.Logins++
+	p.Hours[time.UnixMilli(event.Timestamp).Hour()]++
+
+	if network := loginNetwork(event.IP); network != "" {
+		addProfileEntry(p.networks, network, "", event.Timestamp)
+	}
+	if geo == nil || geo.Private {
+		return
+	}
+	if geo.CountryCode != "" {
+		addProfileEntry(p.countries, geo.CountryCode, countryName(geo.Location), event.Timestamp)
+	}
+	if geo.ASN != 0 {
+		addProfileEntry(p.asns, formatASN(geo.ASN), geo.ASOrg, event.Timestamp)
+	}
+}
+
+// finalize 按登录次数排序，生成对外展示的列表
+func (p *SSHLoginProfile) finalize() {
+	p.Countries = sortedProfileEntries(p.countries)
+	p.ASNs = sortedProfileEntries(p.asns)
+	p.Networks = sortedProfileEntries(p.networks)
+}
+
+func addProfileEntry(entries map[string]*SSHLoginProfileEntry, key, name string, timestamp int64) {
+	entry, ok := entries[key]
+	if !ok {
+		entry = &SSHLoginProfileEntry{Key: key, Name: name}
+		entries[key] = entry
+	}
+	entry.Count++
+	if timestamp > entry.LastSeen {
+		entry.LastSeen = timestamp
+	}
+}
+
+func sortedProfileEntries(entries map[string]*SSHLoginProfileEntry) []SSHLoginProfileEntry {
+	result := make([]SSHLoginProfileEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// sshLoginHistory 登录历史及其地理信息
+type sshLoginHistory struct {
+	event models.SSHLoginEvent
+	geo   *GeoIPInfo
+}
+
+// GetLoginProfile 获取探针（username 为空）或探针上某个用户的登录画像
+func (s *SSHLoginService) GetLoginProfile(ctx context.Context, agentID, username string) (*SSHLoginProfile, error) {
+	since := time.Now().Add(-sshProfileWindow).UnixMilli()
+	history, err := s.loadLoginHistory(ctx, agentID, username, since)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := newSSHLoginProfile(agentID, username, since)
+	for i := range history {
+		profile.add(&history[i].event, history[i].geo)
+	}
+	profile.finalize()
+	return profile, nil
+}
+
+// loadLoginHistory 加载登录成功历史，使用事件保存的国家和 ASN；
+// 未保存地理信息的事件（如记录时未启用 GeoIP）按 IP 重新查询，同一 IP 只查询一次
+func (s *SSHLoginService) loadLoginHistory(ctx context.Context, agentID, username string, since int64) ([]sshLoginHistory, error) {
+	events, err := s.SSHLoginEventRepo.FindSuccessSince(ctx, agentID, username, since, sshProfileMaxEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]*GeoIPInfo)
+	history := make([]sshLoginHistory, 0, len(events))
+	for _, event := range events {
+		geo := storedLoginGeo(&event)
+		if geo == nil {
+			var ok bool
+			if geo, ok = cache[event.IP]; !ok {
+				geo = s.lookupGeo(event.IP)
+				cache[event.IP] = geo
+			}
+		}
+		history = append(history, sshLoginHistory{event: event, geo: geo})
+	}
+	return history, nil
+}
+
+// storedLoginGeo 事件保存的地理信息，不含经纬度；未保存时返回 nil
+func storedLoginGeo(event *models.SSHLoginEvent) *GeoIPInfo {
+	if event.CountryCode == "" && event.ASN == 0 {
+		return nil
+	}
+	return &GeoIPInfo{
+		Location:    event.IPLocation,
+		CountryCode: event.CountryCode,
+		ASN:         event.ASN,
+		ASOrg:       event.ASOrg,
+	}
+}
+
+func (s *SSHLoginService) lookupGeo(ip string) *GeoIPInfo {
+	if s.geoIPSvc == nil || ip == "" {
+		return nil
+	}
+	return s.geoIPSvc.Lookup(ip)
+}
+
+// detectLoginAnomalies 将登录与该用户的历史画像比较，结果写入事件的异常字段
+func (s *SSHLoginService) detectLoginAnomalies(ctx context.Context, event *models.SSHLoginEvent, geo *GeoIPInfo) error {
+	since := time.Now().Add(-sshProfileWindow).UnixMilli()
+	history, err := s.loadLoginHistory(ctx, event.AgentID, "", since)
+	if err != nil {
+		return err
+	}
+
+	// 历史事件不保存经纬度，检测位移时只查询上一次登录的位置
+	if geo.HasCoordinates() {
+		if previous := previousLogin(history, event); previous != nil {
+			if previousGeo := s.lookupGeo(previous.event.IP); previousGeo != nil {
+				previous.geo = previousGeo
+			}
+		}
+	}
+
+	applyLoginAnomalies(event, geo, history)
+	return nil
+}
+
+// previousLogin 用户在本次登录之前的最近一次登录，历史按时间倒序
+func previousLogin(history []sshLoginHistory, event *models.SSHLoginEvent) *sshLoginHistory {
+	for i := range history {
+		item := &history[i]
+		if item.event.Username == event.Username && item.event.Timestamp <= event.Timestamp {
+			return item
+		}
+	}
+	return nil
+}
+
+// applyLoginAnomalies 根据登录历史构建探针和用户画像，检测异常并写入事件
+func applyLoginAnomalies(event *models.SSHLoginEvent, geo *GeoIPInfo, history []sshLoginHistory) {
+	since := time.Now().Add(-sshProfileWindow).UnixMilli()
+	agentProfile := newSSHLoginProfile(event.AgentID, "", since)
+	userProfile := newSSHLoginProfile(event.AgentID, event.Username, since)
+	for i := range history {
+		item := &history[i]
+		agentProfile.add(&item.event, item.geo)
+		if item.event.Username == event.Username {
+			userProfile.add(&item.event, item.geo)
+		}
+	}
+	previous := previousLogin(history, event)
+
+	var anomalies, details []string
+	severity := ""
+	flag := func(anomaly, detail, level string) {
+		anomalies = append(anomalies, anomaly)
+		details = append(details, detail)
+		if severity != models.SSHLoginSeverityHigh {
+			severity = level
+		}
+	}
+
+	if userProfile.Logins >= sshProfileMinLogins {
+		if geo != nil && !geo.Private && geo.CountryCode != "" {
+			if _, seen := userProfile.countries[geo.CountryCode]; !seen {
+				detail := fmt.Sprintf("用户首次从 %s 登录", geoDisplayName(geo))
+				if _, seen := agentProfile.countries[geo.CountryCode]; !seen {
+					detail += "（该探针此前也从未出现该国家的登录）"
+				}
+				flag(models.SSHLoginAnomalyNewCountry, detail, models.SSHLoginSeverityHigh)
+			}
+		}
+		if geo != nil && !geo.Private && geo.ASN != 0 {
+			if _, seen := userProfile.asns[formatASN(geo.ASN)]; !seen {
+				flag(models.SSHLoginAnomalyNewASN, fmt.Sprintf("用户首次从运营商 %s 登录", asnDisplayName(geo)), models.SSHLoginSeverityHigh)
+			}
+		}
+		hour := time.UnixMilli(event.Timestamp).Hour()
+		if userProfile.Hours[hour] == 0 {
+			flag(models.SSHLoginAnomalyUnusualHour, fmt.Sprintf("用户从未在 %02d 时登录", hour), models.SSHLoginSeverityLow)
+		}
+		if network := loginNetwork(event.IP); network != "" {
+			if _, seen := userProfile.networks[network]; !seen {
+				flag(models.SSHLoginAnomalyNewNetwork, fmt.Sprintf("用户首次从网段 %s 登录", network), models.SSHLoginSeverityLow)
+			}
+		}
+	}
+
+	if previous != nil && geo.HasCoordinates() && previous.geo.HasCoordinates() {
+		distance := haversineDistance(previous.geo.Latitude, previous.geo.Longitude, geo.Latitude, geo.Longitude)
+		tolerance := float64(previous.geo.AccuracyRadius) + float64(geo.AccuracyRadius)
+		if distance >= minTravelDistance && distance > tolerance {
+			hours := float64(event.Timestamp-previous.event.Timestamp) / float64(time.Hour.Milliseconds())
+			if hours <= 0 || distance/hours > maxTravelSpeed {
+				flag(models.SSHLoginAnomalyImpossibleTravel, fmt.Sprintf("距上次从 %s（%s）登录仅 %s，两地相距约 %.0f 公里",
+					geoDisplayName(previous.geo), previous.event.IP,
+					formatTravelInterval(event.Timestamp-previous.event.Timestamp), distance), models.SSHLoginSeverityHigh)
+			}
+		}
+	}
+
+	event.Anomalies = anomalies
+	event.AnomalyDetail = strings.Join(details, "；")
+	event.Severity = severity
+}
+
+// loginNetwork 来源 IP 所在网段（IPv4 /24，IPv6 /48）
+func loginNetwork(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if ip4 := parsed.To4(); ip4 != nil {
+		mask := net.CIDRMask(24, 32)
+		return (&net.IPNet{IP: ip4.Mask(mask), Mask: mask}).String()
+	}
+	mask := net.CIDRMask(48, 128)
+	return (&net.IPNet{IP: parsed.Mask(mask), Mask: mask}).String()
+}
+
+// haversineDistance 两个经纬度之间的球面距离（公里）
+func haversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func formatTravelInterval(ms int64) string {
+	if ms < 0 {
+		ms = 0
+	}
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Hour {
+		return fmt.Sprintf("%d 分钟", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%.1f 小时", d.Hours())
+}
+
+func formatASN(asn uint) string {
+	return "AS" + strconv.FormatUint(uint64(asn), 10)
+}
+
+// countryName 归属地中的国家部分
+func countryName(location string) string {
+	name, _, _ := strings.Cut(location, "-")
+	return name
+}
+
+func geoDisplayName(geo *GeoIPInfo) string {
+	if geo.Location != "" {
+		return geo.Location
+	}
+	return geo.CountryCode
+}
+
+func asnDisplayName(geo *GeoIPInfo) string {
+	if geo.ASOrg != "" {
+		return fmt.Sprintf("%s %s", formatASN(geo.ASN), geo.ASOrg)
+	}
+	return formatASN(geo.ASN)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestApplyLoginAnomalies(t *testing.T) {
+	// 使用本地时间，与画像按服务端本地时间统计登录时段一致
+	now := time.Date(2026, 6, 10, 10, 0, 0, 0, time.Local)
+	at := func(d time.Duration) int64 { return now.Add(d).UnixMilli() }
+
+	cn := &GeoIPInfo{Location: "中国-北京-北京", CountryCode: "CN", ASN: 4134, ASOrg: "CHINANET", Latitude: 39.9, Longitude: 116.4}
+	cnOtherASN := &GeoIPInfo{Location: "中国-北京-北京", CountryCode: "CN", ASN: 4837, ASOrg: "CHINA UNICOM", Latitude: 39.9, Longitude: 116.4}
+	usSameASN := &GeoIPInfo{Location: "美国", CountryCode: "US", ASN: 4134}
+	newYork := &GeoIPInfo{Location: "美国-纽约-纽约", CountryCode: "US", ASN: 4134, Latitude: 40.7, Longitude: -74.0}
+
+	// history 生成 root 用户按时间倒序的历史登录，均在 10 时从同一网段登录
+	history := func(n int, geo *GeoIPInfo) []sshLoginHistory {
+		items := make([]sshLoginHistory, 0, n)
+		for i := 1; i <= n; i++ {
+			items = append(items, sshLoginHistory{
+				event: models.SSHLoginEvent{Username: "root", IP: "203.0.113.10", Timestamp: at(-time.Duration(i) * 24 * time.Hour)},
+				geo:   geo,
+			})
+		}
+		return items
+	}
+
+	tests := []struct {
+		name      string
+		history   []sshLoginHistory
+		ip        string
+		timestamp int64
+		geo       *GeoIPInfo
+		anomalies []string
+		severity  string
+	}{
+		{
+			name:      "与画像一致",
+			history:   history(3, cn),
+			ip:        "203.0.113.20",
+			timestamp: at(0),
+			geo:       cn,
+		},
+		{
+			name:      "历史登录次数不足时不检测新国家",
+			history:   history(sshProfileMinLogins-1, cn),
+			ip:        "203.0.113.20",
+			timestamp: at(0),
+			geo:       usSameASN,
+		},
+		{
+			name:      "新国家",
+			history:   history(3, cn),
+			ip:        "203.0.113.20",
+			timestamp: at(0),
+			geo:       usSameASN,
+			anomalies: []string{models.SSHLoginAnomalyNewCountry},
+			severity:  models.SSHLoginSeverityHigh,
+		},
+		{
+			name:      "新运营商",
+			history:   history(3, cn),
+			ip:        "203.0.113.20",
+			timestamp: at(0),
+			geo:       cnOtherASN,
+			anomalies: []string{models.SSHLoginAnomalyNewASN},
+			severity:  models.SSHLoginSeverityHigh,
+		},
+		{
+			name:      "新时段和新网段为低危",
+			history:   history(3, cn),
+			ip:        "198.51.100.20",
+			timestamp: at(13 * time.Hour),
+			geo:       cn,
+			anomalies: []string{models.SSHLoginAnomalyUnusualHour, models.SSHLoginAnomalyNewNetwork},
+			severity:  models.SSHLoginSeverityLow,
+		},
+		{
+			name: "不可能的位移不受历史登录次数限制",
+			history: []sshLoginHistory{{
+				event: models.SSHLoginEvent{Username: "root", IP: "203.0.113.10", Timestamp: at(-time.Hour)},
+				geo:   cn,
+			}},
+			ip:        "198.51.100.20",
+			timestamp: at(0),
+			geo:       newYork,
+			anomalies: []string{models.SSHLoginAnomalyImpossibleTravel},
+			severity:  models.SSHLoginSeverityHigh,
+		},
+		{
+			name: "间隔足够长的位移",
+			history: []sshLoginHistory{{
+				event: models.SSHLoginEvent{Username: "root", IP: "203.0.113.10", Timestamp: at(-20 * time.Hour)},
+				geo:   cn,
+			}},
+			ip:        "198.51.100.20",
+			timestamp: at(0),
+			geo:       newYork,
+		},
+		{
+			name: "其他用户的登录不作为上一次登录",
+			history: []sshLoginHistory{{
+				event: models.SSHLoginEvent{Username: "admin", IP: "203.0.113.10", Timestamp: at(-time.Hour)},
+				geo:   cn,
+			}},
+			ip:        "198.51.100.20",
+			timestamp: at(0),
+			geo:       newYork,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &models.SSHLoginEvent{AgentID: "a1", Username: "root", IP: tt.ip, Timestamp: tt.timestamp}
+			applyLoginAnomalies(event, tt.geo, tt.history)
+			if !reflect.DeepEqual([]string(event.Anomalies), tt.anomalies) {
+				t.Errorf("异常 = %v, want %v（%s）", event.Anomalies, tt.anomalies, event.AnomalyDetail)
+			}
+			if event.Severity != tt.severity {
+				t.Errorf("严重程度 = %q, want %q", event.Severity, tt.severity)
+			}
+		})
+	}
+}
+
+func TestLoadLoginHistoryUsesStoredGeo(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(&models.SSHLoginEvent{}); err != nil {
+		t.Fatalf("创建测试表失败: %v", err)
+	}
+	events := []models.SSHLoginEvent{
+		{ID: "1", AgentID: "a1", Username: "root", IP: "203.0.113.10", IPLocation: "中国-北京-北京", CountryCode: "CN", ASN: 4134, ASOrg: "CHINANET", Status: "success", Timestamp: 2000},
+		{ID: "2", AgentID: "a1", Username: "root", IP: "198.51.100.10", Status: "success", Timestamp: 1000},
+	}
+	if err := db.Create(&events).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+
+	// 未启用 GeoIP，地理信息只能来自事件本身
+	s := &SSHLoginService{logger: zap.NewNop(), SSHLoginEventRepo: repo.NewSSHLoginEventRepo(db)}
+	history, err := s.loadLoginHistory(context.Background(), "a1", "root", 0)
+	if err != nil {
+		t.Fatalf("loadLoginHistory() 失败: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("历史数量 = %d, want 2", len(history))
+	}
+	want := &GeoIPInfo{Location: "中国-北京-北京", CountryCode: "CN", ASN: 4134, ASOrg: "CHINANET"}
+	if !reflect.DeepEqual(history[0].geo, want) {
+		t.Errorf("应使用事件保存的地理信息: %+v", history[0].geo)
+	}
+	if history[1].geo != nil {
+		t.Errorf("未保存地理信息且未启用 GeoIP 时应为空: %+v", history[1].geo)
+	}
+}
+
+func TestHaversineDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"同一地点", 39.9, 116.4, 39.9, 116.4, 0},
+		{"北京到上海", 39.9042, 116.4074, 31.2304, 121.4737, 1067},
+		{"跨越日期变更线", 0, 179.5, 0, -179.5, 111},
+		{"对跖点", 0, 0, 0, 180, 20015},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := haversineDistance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > 5 {
+				t.Errorf("haversineDistance() = %.1f, want 约 %.0f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginNetwork(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"203.0.113.77", "203.0.113.0/24"},
+		{"::ffff:203.0.113.77", "203.0.113.0/24"},
+		{"2001:db8:1234:5678::1", "2001:db8:1234::/48"},
+		{"invalid", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := loginNetwork(tt.ip); got != tt.want {
+				t.Errorf("loginNetwork(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
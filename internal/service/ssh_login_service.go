@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"sync"
 	"time"
 
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/protocol"
 	"github.com/dushixiang/pika/internal/repo"
 	"github.com/dushixiang/pika/internal/websocket"
+	"github.com/go-orz/orz"
 	"github.com/google/uuid"
 
 	"go.uber.org/zap"
@@ -23,8 +26,13 @@ type SSHLoginService struct {
 	SSHLoginEventRepo *repo.SSHLoginEventRepo
 	agentRepo         *repo.AgentRepo
 	wsManager         *websocket.Manager
+	SSHBanRepo        *repo.SSHBanRepo
+	SSHSessionRepo    *repo.SSHSessionRepo
+	SSHSudoEventRepo  *repo.SSHSudoEventRepo
 	geoIPSvc          *GeoIPService
 	notificationSvc   *NotificationService
+
+	banMu sync.Mutex // 串行化封禁判定，避免并发事件重复封禁
 }
 
 // NewSSHLoginService 创建服务
@@ -32,6 +40,9 @@ func NewSSHLoginService(logger *zap.Logger, db *gorm.DB, wsManager *websocket.Ma
 	return &SSHLoginService{
 		logger:            logger,
 		SSHLoginEventRepo: repo.NewSSHLoginEventRepo(db),
+		SSHBanRepo:        repo.NewSSHBanRepo(db),
+		SSHSessionRepo:    repo.NewSSHSessionRepo(db),
+		SSHSudoEventRepo:  repo.NewSSHSudoEventRepo(db),
 		agentRepo:         repo.NewAgentRepo(db),
 		wsManager:         wsManager,
 		geoIPSvc:          geoIPSvc,
@@ -54,10 +65,23 @@ func (s *SSHLoginService) GetConfig(ctx context.Context, agentID string) (*model
 // UpdateConfig 更新配置并下发到 Agent
 // 返回: config - 配置对象, error - 错误信息
 func (s *SSHLoginService) UpdateConfig(ctx context.Context, agentID string, req *models.SSHLoginConfigData) error {
+	banPolicy := req.BanPolicy
+	if banPolicy == nil {
+		// 未提交封禁策略（如批量更新）时保留原有策略
+		existing, err := s.GetConfig(ctx, agentID)
+		if err != nil {
+			return err
+		}
+		banPolicy = existing.BanPolicy
+	} else {
+		normalizeSSHBanPolicy(banPolicy)
+	}
+
 	// 保存配置到数据库
 	config := models.SSHLoginConfigData{
 		Enabled:     req.Enabled,
 		IPWhitelist: req.IPWhitelist,
+		BanPolicy:   banPolicy,
 		ApplyStatus: "pending",
 	}
 
@@ -71,17 +95,62 @@ func (s *SSHLoginService) UpdateConfig(ctx context.Context, agentID string, req
 
 	// 下发配置到 Agent
 	go func() {
-		if err := s.sendConfigToAgent(agentID, config.Enabled); err != nil {
+		if err := s.sendConfigToAgent(agentID); err != nil {
 			s.logger.Error("下发SSH登录监控配置到 Agent 失败", zap.String("agentId", agentID), zap.Error(err))
 		}
 	}()
 	return nil
 }
 
+// BuildAgentConfig 构建下发给 Agent 的配置，包含生效中的封禁和在线会话（Agent 以此重建封禁规则和会话跟踪）
+func (s *SSHLoginService) BuildAgentConfig(ctx context.Context, agentID string) (*protocol.SSHLoginConfig, error) {
+	config, err := s.GetConfig(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	configData := &protocol.SSHLoginConfig{
+		Enabled: config.Enabled,
+	}
+	if !config.Enabled {
+		return configData, nil
+	}
+
+	now := time.Now().UnixMilli()
+	bans, err := s.SSHBanRepo.FindActiveByAgentID(ctx, agentID, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, ban := range bans {
+		configData.Bans = append(configData.Bans, protocol.SSHBan{
+			CIDR:     ban.CIDR,
+			Duration: (ban.ExpiresAt - now + 999) / 1000,
+		})
+	}
+
+	sessions, err := s.SSHSessionRepo.FindActiveByAgentID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		configData.Sessions = append(configData.Sessions, protocol.SSHLoginEvent{
+			Username:  session.Username,
+			IP:        session.IP,
+			Port:      session.Port,
+			Timestamp: session.StartedAt,
+			Status:    "success",
+			TTY:       session.TTY,
+			SessionID: session.SessionID,
+		})
+	}
+	return configData, nil
+}
+
 // sendConfigToAgent 下发配置到 Agent
-func (s *SSHLoginService) sendConfigToAgent(agentID string, enabled bool) error {
-	configData := protocol.SSHLoginConfig{
-		Enabled: enabled,
+func (s *SSHLoginService) sendConfigToAgent(agentID string) error {
+	configData, err := s.BuildAgentConfig(context.Background(), agentID)
+	if err != nil {
+		return err
 	}
 
 	message := protocol.OutboundMessage{
@@ -137,9 +206,20 @@ func (s *SSHLoginService) HandleEvent(ctx context.Context, agentID string, event
 		return nil
 	}
 
+	// 登出事件只用于结束会话，不作为登录事件记录
+	if eventData.Status == "logout" {
+		return s.closeSession(ctx, agentID, eventData)
+	}
+
+	// 登录失败事件只用于统计暴力破解，未启用封禁策略时不记录，避免扫描流量占满事件表
+	if eventData.Status == "failed" && (config.BanPolicy == nil || !config.BanPolicy.Enabled) {
+		return nil
+	}
+
 	ipLocation := ""
-	if s.geoIPSvc != nil && eventData.IP != "" {
-		ipLocation = s.geoIPSvc.LookupIP(eventData.IP)
+	geo := s.lookupGeo(eventData.IP)
+	if geo != nil {
+		ipLocation = geo.Location
 	}
 
 	// 保存事件到数据库
@@ -156,6 +236,18 @@ func (s *SSHLoginService) HandleEvent(ctx context.Context, agentID string, event
 		Timestamp:  eventData.Timestamp,
 		CreatedAt:  time.Now().UnixMilli(),
 	}
+	if geo != nil {
+		event.CountryCode = geo.CountryCode
+		event.ASN = geo.ASN
+		event.ASOrg = geo.ASOrg
+	}
+
+	// 与用户的历史登录画像比较，检测异地、新运营商和不可能的位移
+	if event.Status == "success" {
+		if err := s.detectLoginAnomalies(ctx, event, geo); err != nil {
+			s.logger.Warn("SSH登录异常检测失败", zap.String("agentId", agentID), zap.Error(err))
+		}
+	}
 
 	if err := s.SSHLoginEventRepo.Create(ctx, event); err != nil {
 		s.logger.Error("保存SSH登录事件失败", zap.Error(err))
@@ -166,18 +258,30 @@ func (s *SSHLoginService) HandleEvent(ctx context.Context, agentID string, event
 		zap.String("agentId", agentID),
 		zap.String("username", eventData.Username),
 		zap.String("ip", eventData.IP),
-		zap.String("status", eventData.Status))
+		zap.String("status", eventData.Status),
+		zap.String("severity", event.Severity))
+
+	if eventData.Status == "failed" {
+		s.handleFailedLogin(ctx, agentID, config, ipLocation, eventData)
+		return nil
+	}
+
+	if err := s.openSession(ctx, event); err != nil {
+		s.logger.Error("保存SSH会话失败", zap.String("agentId", agentID), zap.Error(err))
+	}
 
 	if config.IsIPWhitelisted(eventData.IP) {
 		s.logger.Info("IP在白名单中，忽略事件", zap.String("agentId", agentID), zap.String("ip", eventData.IP))
 		return nil
 	}
-	s.sendLoginSuccessNotification(ctx, agentID, eventData, ipLocation)
+	s.sendLoginNotification(ctx, event)
 
 	return nil
 }
 
-func (s *SSHLoginService) sendLoginSuccessNotification(ctx context.Context, agentID string, eventData protocol.SSHLoginEvent, ipLocation string) {
+// sendLoginNotification 发送登录通知，高危异常登录使用独立的通知类型，不受登录成功通知开关影响
+func (s *SSHLoginService) sendLoginNotification(ctx context.Context, event *models.SSHLoginEvent) {
+	agentID := event.AgentID
 	if s.notificationSvc == nil {
 		return
 	}
@@ -188,12 +292,12 @@ func (s *SSHLoginService) sendLoginSuccessNotification(ctx context.Context, agen
 		return
 	}
 
-	firedAt := eventData.Timestamp
+	firedAt := event.Timestamp
 	if firedAt == 0 {
 		firedAt = time.Now().UnixMilli()
 	}
 
-	sourceIP := eventData.IP
+	sourceIP := event.IP
 	if s.notificationSvc != nil {
 		if maskIP, err := s.notificationSvc.IsMaskIPEnabled(ctx); err == nil && maskIP {
 			sourceIP = maskIPAddress(sourceIP)
@@ -201,11 +305,11 @@ func (s *SSHLoginService) sendLoginSuccessNotification(ctx context.Context, agen
 	}
 
 	sourceAddr := sourceIP
-	if eventData.Port != "" {
-		sourceAddr = fmt.Sprintf("%s:%s", sourceIP, eventData.Port)
+	if event.Port != "" {
+		sourceAddr = fmt.Sprintf("%s:%s", sourceIP, event.Port)
 	}
 
-	locationText := ipLocation
+	locationText := event.IPLocation
 	if locationText == "" {
 		locationText = "未知"
 	}
@@ -214,7 +318,7 @@ func (s *SSHLoginService) sendLoginSuccessNotification(ctx context.Context, agen
 		AgentID:     agentID,
 		AgentName:   agent.Name,
 		AlertType:   "ssh_login",
-		Message:     fmt.Sprintf("SSH登录成功：用户 %s，来源 %s，归属地 %s，终端 %s，会话 %s", eventData.Username, sourceAddr, locationText, eventData.TTY, eventData.SessionID),
+		Message:     fmt.Sprintf("SSH登录成功：用户 %s，来源 %s，归属地 %s，终端 %s，会话 %s", event.Username, sourceAddr, locationText, event.TTY, event.SessionID),
 		Threshold:   0,
 		ActualValue: 0,
 		Level:       "warning",
@@ -222,13 +326,320 @@ func (s *SSHLoginService) sendLoginSuccessNotification(ctx context.Context, agen
 		FiredAt:     firedAt,
 		CreatedAt:   firedAt,
 	}
+	notificationType := NotificationTypeSSHLogin
+	if event.Severity == models.SSHLoginSeverityHigh {
+		notificationType = NotificationTypeSSHLoginAnomaly
+		record.AlertType = "ssh_login_anomaly"
+		record.Level = "critical"
+		record.Message = fmt.Sprintf("SSH异常登录：用户 %s，来源 %s，归属地 %s，终端 %s，会话 %s。%s", event.Username, sourceAddr, locationText, event.TTY, event.SessionID, event.AnomalyDetail)
+	}
+
+	go func(record *models.AlertRecord, agent *models.Agent) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.notificationSvc.SendAlertNotification(ctx, notificationType, record, agent); err != nil {
+			s.logger.Error("发送SSH登录通知失败",
+				zap.String("agentId", agentID),
+				zap.Error(err),
+			)
+		}
+	}(record, &agent)
+}
+
+// === 暴力破解封禁 ===
+
+const (
+	defaultSSHBanMaxFailures = 5
+	defaultSSHBanWindow      = 10   // 分钟
+	defaultSSHBanDuration    = 60   // 分钟
+	maxSSHBanWindow          = 1440 // 分钟
+	maxSSHBanDuration        = 43200
+	minSSHBanIPv4Prefix      = 8
+	minSSHBanIPv6Prefix      = 32
+)
+
+// normalizeSSHBanPolicy 补全默认值并限制取值范围，避免误封过大的网段
+func normalizeSSHBanPolicy(policy *models.SSHBanPolicy) {
+	if policy.MaxFailures <= 0 {
+		policy.MaxFailures = defaultSSHBanMaxFailures
+	}
+	if policy.Window <= 0 {
+		policy.Window = defaultSSHBanWindow
+	}
+	policy.Window = min(policy.Window, maxSSHBanWindow)
+	if policy.BanDuration <= 0 {
+		policy.BanDuration = defaultSSHBanDuration
+	}
+	policy.BanDuration = min(policy.BanDuration, maxSSHBanDuration)
+	if policy.IPv4Prefix <= 0 || policy.IPv4Prefix > 32 {
+		policy.IPv4Prefix = 32
+	}
+	policy.IPv4Prefix = max(policy.IPv4Prefix, minSSHBanIPv4Prefix)
+	if policy.IPv6Prefix <= 0 || policy.IPv6Prefix > 128 {
+		policy.IPv6Prefix = 128
+	}
+	policy.IPv6Prefix = max(policy.IPv6Prefix, minSSHBanIPv6Prefix)
+}
+
+// sshBanNetwork 按策略将来源 IP 聚合为封禁网段
+func sshBanNetwork(ip net.IP, policy *models.SSHBanPolicy) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		mask := net.CIDRMask(policy.IPv4Prefix, 32)
+		return &net.IPNet{IP: ip4.Mask(mask), Mask: mask}
+	}
+	mask := net.CIDRMask(policy.IPv6Prefix, 128)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// sshBanEndTime 封禁实际结束的时间
+func sshBanEndTime(ban *models.SSHBan) int64 {
+	switch ban.Status {
+	case models.SSHBanStatusLifted:
+		return ban.LiftedAt
+	case models.SSHBanStatusFailed:
+		return ban.CreatedAt
+	default:
+		return ban.ExpiresAt
+	}
+}
+
+// sshBanWindowStart 计算统计登录失败次数的起始时间，latest 为该网段最近一次封禁（可为 nil）
+// 已封禁时不重复封禁（banned 为 true）；封禁结束前的失败记录不再计入，避免解封后立即再次封禁
+func sshBanWindowStart(now int64, policy *models.SSHBanPolicy, latest *models.SSHBan) (since int64, banned bool) {
+	since = now - int64(policy.Window)*time.Minute.Milliseconds()
+	if latest == nil {
+		return since, false
+	}
+	if (latest.Status == models.SSHBanStatusPending || latest.Status == models.SSHBanStatusActive) && latest.ExpiresAt > now {
+		return since, true
+	}
+	return max(since, sshBanEndTime(latest)), false
+}
+
+// countSSHBanFailures 统计落在封禁网段内的失败次数
+func countSSHBanFailures(counts []repo.SSHFailedIPCount, banNet *net.IPNet) int {
+	failures := 0
+	for _, item := range counts {
+		if parsed := net.ParseIP(item.IP); parsed != nil && banNet.Contains(parsed) {
+			failures += item.Count
+		}
+	}
+	return failures
+}
+
+// handleFailedLogin 统计时间窗口内的登录失败次数，达到阈值后下发封禁
+func (s *SSHLoginService) handleFailedLogin(ctx context.Context, agentID string, config *models.SSHLoginConfigData, ipLocation string, eventData protocol.SSHLoginEvent) {
+	policy := config.BanPolicy
+	if policy == nil || !policy.Enabled {
+		return
+	}
+	normalizeSSHBanPolicy(policy)
+
+	ip := net.ParseIP(eventData.IP)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+		return
+	}
+	if config.IsIPWhitelisted(eventData.IP) {
+		return
+	}
+	banNet := sshBanNetwork(ip, policy)
+	if config.IsNetWhitelisted(banNet) {
+		s.logger.Info("封禁网段包含白名单地址，跳过封禁", zap.String("agentId", agentID), zap.String("cidr", banNet.String()))
+		return
+	}
+	cidr := banNet.String()
+
+	s.banMu.Lock()
+	defer s.banMu.Unlock()
+
+	now := time.Now().UnixMilli()
+
+	latest, exists, err := s.SSHBanRepo.FindLatest(ctx, agentID, cidr)
+	if err != nil {
+		s.logger.Error("查询SSH封禁记录失败", zap.String("agentId", agentID), zap.Error(err))
+		return
+	}
+	if !exists {
+		latest = nil
+	}
+	since, banned := sshBanWindowStart(now, policy, latest)
+	if banned {
+		return
+	}
+
+	counts, err := s.SSHLoginEventRepo.CountFailedByIPSince(ctx, agentID, since)
+	if err != nil {
+		s.logger.Error("统计SSH登录失败次数失败", zap.String("agentId", agentID), zap.Error(err))
+		return
+	}
+	failures := countSSHBanFailures(counts, banNet)
+	if failures < policy.MaxFailures {
+		return
+	}
+
+	ban := &models.SSHBan{
+		ID:        uuid.NewString(),
+		AgentID:   agentID,
+		CIDR:      cidr,
+		Failures:  failures,
+		Reason:    fmt.Sprintf("%d 分钟内登录失败 %d 次", policy.Window, failures),
+		Status:    models.SSHBanStatusPending,
+		ExpiresAt: now + int64(policy.BanDuration)*time.Minute.Milliseconds(),
+		CreatedAt: now,
+	}
+	if err := s.SSHBanRepo.Create(ctx, ban); err != nil {
+		s.logger.Error("保存SSH封禁记录失败", zap.String("agentId", agentID), zap.Error(err))
+		return
+	}
+
+	if err := s.sendBanRequest(agentID, protocol.SSHBanRequest{
+		ID:       ban.ID,
+		Action:   "ban",
+		CIDR:     cidr,
+		Duration: int64(policy.BanDuration) * 60,
+	}); err != nil {
+		s.logger.Error("下发SSH封禁指令失败", zap.String("agentId", agentID), zap.String("cidr", cidr), zap.Error(err))
+		ban.Status = models.SSHBanStatusFailed
+		ban.Message = fmt.Sprintf("下发封禁指令失败: %v", err)
+		if err := s.SSHBanRepo.UpdateById(ctx, ban); err != nil {
+			s.logger.Error("更新SSH封禁记录失败", zap.String("banId", ban.ID), zap.Error(err))
+		}
+		return
+	}
+
+	s.logger.Info("检测到SSH暴力破解，已下发封禁",
+		zap.String("agentId", agentID),
+		zap.String("cidr", cidr),
+		zap.Int("failures", failures))
+	s.sendBanNotification(ctx, agentID, ban, policy, ipLocation)
+}
+
+// sendBanRequest 下发封禁/解封指令到 Agent
+func (s *SSHLoginService) sendBanRequest(agentID string, req protocol.SSHBanRequest) error {
+	msgBytes, err := json.Marshal(protocol.OutboundMessage{
+		Type: protocol.MessageTypeSSHBan,
+		Data: req,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	return s.wsManager.SendToClient(agentID, msgBytes)
+}
+
+// HandleBanResult 处理 Agent 上报的封禁/解封结果
+func (s *SSHLoginService) HandleBanResult(ctx context.Context, agentID string, result protocol.SSHBanResult) error {
+	ban, exists, err := s.SSHBanRepo.FindByIdExists(ctx, result.ID)
+	if err != nil {
+		return err
+	}
+	if !exists || ban.AgentID != agentID {
+		return nil
+	}
+
+	if result.Action == "unban" {
+		if !result.Success {
+			s.logger.Warn("Agent 解除SSH封禁失败", zap.String("agentId", agentID), zap.String("cidr", result.CIDR), zap.String("message", result.Message))
+		}
+		ban.Message = result.Message
+		return s.SSHBanRepo.UpdateById(ctx, &ban)
+	}
+
+	// 等待确认期间封禁可能已被手动解除
+	if ban.Status != models.SSHBanStatusPending {
+		return nil
+	}
+	ban.Status = models.SSHBanStatusActive
+	if !result.Success {
+		ban.Status = models.SSHBanStatusFailed
+		s.logger.Warn("Agent 执行SSH封禁失败", zap.String("agentId", agentID), zap.String("cidr", result.CIDR), zap.String("message", result.Message))
+	}
+	ban.Message = result.Message
+	return s.SSHBanRepo.UpdateById(ctx, &ban)
+}
+
+// LiftBan 手动解除封禁
+func (s *SSHLoginService) LiftBan(ctx context.Context, agentID, banID, operator string) error {
+	s.banMu.Lock()
+	defer s.banMu.Unlock()
+
+	ban, exists, err := s.SSHBanRepo.FindByIdExists(ctx, banID)
+	if err != nil {
+		return err
+	}
+	if !exists || ban.AgentID != agentID {
+		return orz.NewError(404, "封禁记录不存在")
+	}
+
+	now := time.Now().UnixMilli()
+	if (ban.Status != models.SSHBanStatusPending && ban.Status != models.SSHBanStatusActive) || ban.ExpiresAt <= now {
+		return orz.NewError(400, "封禁已失效，无需解除")
+	}
+
+	ban.Status = models.SSHBanStatusLifted
+	ban.LiftedBy = operator
+	ban.LiftedAt = now
+	if err := s.SSHBanRepo.UpdateById(ctx, &ban); err != nil {
+		return err
+	}
+
+	// Agent 离线时无需处理，重连后按配置全量同步封禁列表
+	if err := s.sendBanRequest(agentID, protocol.SSHBanRequest{
+		ID:     ban.ID,
+		Action: "unban",
+		CIDR:   ban.CIDR,
+	}); err != nil {
+		s.logger.Warn("下发SSH解封指令失败", zap.String("agentId", agentID), zap.String("cidr", ban.CIDR), zap.Error(err))
+	}
+
+	s.logger.Info("已解除SSH封禁", zap.String("agentId", agentID), zap.String("cidr", ban.CIDR), zap.String("operator", operator))
+	return nil
+}
+
+// ExpireBans 将已到期的封禁标记为到期（Agent 端到期自动解除，无需下发指令）
+func (s *SSHLoginService) ExpireBans(ctx context.Context) error {
+	return s.SSHBanRepo.MarkExpired(ctx, time.Now().UnixMilli())
+}
+
+func (s *SSHLoginService) sendBanNotification(ctx context.Context, agentID string, ban *models.SSHBan, policy *models.SSHBanPolicy, ipLocation string) {
+	if s.notificationSvc == nil {
+		return
+	}
+
+	agent, err := s.agentRepo.FindById(ctx, agentID)
+	if err != nil {
+		s.logger.Error("获取探针信息失败", zap.String("agentId", agentID), zap.Error(err))
+		return
+	}
+
+	source := ban.CIDR
+	if maskIP, err := s.notificationSvc.IsMaskIPEnabled(ctx); err == nil && maskIP {
+		source = maskIPAddress(source)
+	}
+	locationText := ipLocation
+	if locationText == "" {
+		locationText = "未知"
+	}
+
+	record := &models.AlertRecord{
+		AgentID:     agentID,
+		AgentName:   agent.Name,
+		AlertType:   "ssh_ban",
+		Message:     fmt.Sprintf("SSH暴力破解已封禁：来源 %s，归属地 %s，%s，封禁 %d 分钟", source, locationText, ban.Reason, policy.BanDuration),
+		Threshold:   float64(policy.MaxFailures),
+		ActualValue: float64(ban.Failures),
+		Level:       "warning",
+		Status:      "notice",
+		FiredAt:     ban.CreatedAt,
+		CreatedAt:   ban.CreatedAt,
+	}
 
 	go func(record *models.AlertRecord, agent *models.Agent) {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		if err := s.notificationSvc.SendAlertNotification(ctx, NotificationTypeSSHLogin, record, agent); err != nil {
-			s.logger.Error("发送SSH登录成功通知失败",
+		if err := s.notificationSvc.SendAlertNotification(ctx, NotificationTypeSSHBan, record, agent); err != nil {
+			s.logger.Error("发送SSH封禁通知失败",
 				zap.String("agentId", agentID),
 				zap.Error(err),
 			)
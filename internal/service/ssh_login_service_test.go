@@ -0,0 +1,106 @@
+package service
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+)
+
+func TestNormalizeSSHBanPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy models.SSHBanPolicy
+		want   models.SSHBanPolicy
+	}{
+		{
+			name:   "未配置时使用默认值",
+			policy: models.SSHBanPolicy{Enabled: true},
+			want:   models.SSHBanPolicy{Enabled: true, MaxFailures: 5, Window: 10, BanDuration: 60, IPv4Prefix: 32, IPv6Prefix: 128},
+		},
+		{
+			name:   "超出上限时截断",
+			policy: models.SSHBanPolicy{MaxFailures: 3, Window: 5000, BanDuration: 100000, IPv4Prefix: 40, IPv6Prefix: 200},
+			want:   models.SSHBanPolicy{MaxFailures: 3, Window: 1440, BanDuration: 43200, IPv4Prefix: 32, IPv6Prefix: 128},
+		},
+		{
+			name:   "网段前缀不小于下限",
+			policy: models.SSHBanPolicy{MaxFailures: 3, Window: 5, BanDuration: 30, IPv4Prefix: 4, IPv6Prefix: 16},
+			want:   models.SSHBanPolicy{MaxFailures: 3, Window: 5, BanDuration: 30, IPv4Prefix: 8, IPv6Prefix: 32},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			normalizeSSHBanPolicy(&policy)
+			if policy != tt.want {
+				t.Errorf("normalizeSSHBanPolicy() = %+v, want %+v", policy, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSHBanNetwork(t *testing.T) {
+	policy := &models.SSHBanPolicy{IPv4Prefix: 24, IPv6Prefix: 64}
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{"IPv4 按 /24 聚合", "203.0.113.77", "203.0.113.0/24"},
+		{"IPv6 按 /64 聚合", "2001:db8:1:2:3:4:5:6", "2001:db8:1:2::/64"},
+		{"IPv4 映射地址按 IPv4 处理", "::ffff:198.51.100.9", "198.51.100.0/24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sshBanNetwork(net.ParseIP(tt.ip), policy).String(); got != tt.want {
+				t.Errorf("sshBanNetwork(%s) = %s, want %s", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSHBanWindowStart(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC).UnixMilli()
+	minute := time.Minute.Milliseconds()
+	policy := &models.SSHBanPolicy{Window: 10}
+	windowStart := now - 10*minute
+
+	tests := []struct {
+		name       string
+		latest     *models.SSHBan
+		wantSince  int64
+		wantBanned bool
+	}{
+		{"没有封禁记录", nil, windowStart, false},
+		{"封禁生效中", &models.SSHBan{Status: models.SSHBanStatusActive, ExpiresAt: now + minute}, windowStart, true},
+		{"封禁等待探针确认", &models.SSHBan{Status: models.SSHBanStatusPending, ExpiresAt: now + minute}, windowStart, true},
+		{"封禁已到期但状态未更新", &models.SSHBan{Status: models.SSHBanStatusActive, ExpiresAt: now - 3*minute}, now - 3*minute, false},
+		{"窗口内手动解除，只统计解除后的失败", &models.SSHBan{Status: models.SSHBanStatusLifted, LiftedAt: now - 2*minute, ExpiresAt: now + minute}, now - 2*minute, false},
+		{"窗口前到期，按完整窗口统计", &models.SSHBan{Status: models.SSHBanStatusExpired, ExpiresAt: now - 30*minute}, windowStart, false},
+		{"下发失败的封禁从创建时间起统计", &models.SSHBan{Status: models.SSHBanStatusFailed, CreatedAt: now - 5*minute, ExpiresAt: now + minute}, now - 5*minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, banned := sshBanWindowStart(now, policy, tt.latest)
+			if since != tt.wantSince || banned != tt.wantBanned {
+				t.Errorf("sshBanWindowStart() = (%d, %v), want (%d, %v)", since, banned, tt.wantSince, tt.wantBanned)
+			}
+		})
+	}
+}
+
+func TestCountSSHBanFailures(t *testing.T) {
+	_, banNet, _ := net.ParseCIDR("203.0.113.0/24")
+	counts := []repo.SSHFailedIPCount{
+		{IP: "203.0.113.1", Count: 2},
+		{IP: "203.0.113.200", Count: 1},
+		{IP: "198.51.100.1", Count: 4},
+		{IP: "invalid", Count: 1},
+	}
+	if got := countSSHBanFailures(counts, banNet); got != 3 {
+		t.Errorf("countSSHBanFailures() = %d, want 3", got)
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// openSession 登录成功后记录在线会话
+func (s *SSHLoginService) openSession(ctx context.Context, event *models.SSHLoginEvent) error {
+	if event.SessionID != "" {
+		// 会话ID为 sshd 进程 PID，可能被复用；仍在线的旧会话说明登出事件丢失，直接结束
+		stale, exists, err := s.SSHSessionRepo.FindActive(ctx, event.AgentID, event.SessionID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			s.endSession(stale, event.Timestamp)
+			if err := s.SSHSessionRepo.UpdateById(ctx, stale); err != nil {
+				return err
+			}
+		}
+	}
+
+	session := &models.SSHSession{
+		ID:           uuid.NewString(),
+		AgentID:      event.AgentID,
+		SessionID:    event.SessionID,
+		Username:     event.Username,
+		IP:           event.IP,
+		IPLocation:   event.IPLocation,
+		Port:         event.Port,
+		TTY:          event.TTY,
+		LoginEventID: event.ID,
+		Status:       models.SSHSessionStatusActive,
+		StartedAt:    event.Timestamp,
+		CreatedAt:    time.Now().UnixMilli(),
+	}
+	return s.SSHSessionRepo.Create(ctx, session)
+}
+
+// closeSession 处理登出事件，结束对应的在线会话
+func (s *SSHLoginService) closeSession(ctx context.Context, agentID string, eventData protocol.SSHLoginEvent) error {
+	endedAt := eventData.Timestamp
+	if endedAt == 0 {
+		endedAt = time.Now().UnixMilli()
+	}
+
+	var session *models.SSHSession
+	exists := false
+	if eventData.SessionID != "" {
+		var err error
+		session, exists, err = s.SSHSessionRepo.FindActive(ctx, agentID, eventData.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+	if !exists {
+		if eventData.OpenedAt == 0 {
+			// 监控启用前开始的会话，无法得知开始时间，不做记录
+			s.logger.Debug("未找到登出事件对应的SSH会话", zap.String("agentId", agentID), zap.String("sessionId", eventData.SessionID))
+			return nil
+		}
+		// 服务端未收到登录事件（如网络中断），按 Agent 关联的开始时间补录
+		session = &models.SSHSession{
+			ID:         uuid.NewString(),
+			AgentID:    agentID,
+			SessionID:  eventData.SessionID,
+			Username:   eventData.Username,
+			IP:         eventData.IP,
+			IPLocation: s.lookupLocation(eventData.IP),
+			Port:       eventData.Port,
+			TTY:        eventData.TTY,
+			StartedAt:  eventData.OpenedAt,
+			CreatedAt:  time.Now().UnixMilli(),
+		}
+		s.endSession(session, endedAt)
+		return s.SSHSessionRepo.Create(ctx, session)
+	}
+
+	s.endSession(session, endedAt)
+	if err := s.SSHSessionRepo.UpdateById(ctx, session); err != nil {
+		return err
+	}
+
+	s.logger.Info("SSH会话已结束",
+		zap.String("agentId", agentID),
+		zap.String("username", session.Username),
+		zap.String("sessionId", session.SessionID),
+		zap.Int64("duration", session.Duration))
+	return nil
+}
+
+// endSession 将会话标记为结束并计算时长
+func (s *SSHLoginService) endSession(session *models.SSHSession, endedAt int64) {
+	session.Status = models.SSHSessionStatusClosed
+	session.EndedAt = max(endedAt, session.StartedAt)
+	session.Duration = (session.EndedAt - session.StartedAt) / 1000
+}
+
+func (s *SSHLoginService) lookupLocation(ip string) string {
+	if geo := s.lookupGeo(ip); geo != nil {
+		return geo.Location
+	}
+	return ""
+}
+
+// HandleSudoEvent 处理 Agent 上报的 sudo 提权记录
+func (s *SSHLoginService) HandleSudoEvent(ctx context.Context, agentID string, eventData protocol.SSHSudoEvent) error {
+	config, err := s.GetConfig(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	if config == nil || !config.Enabled {
+		return nil
+	}
+
+	event := &models.SSHSudoEvent{
+		ID:        uuid.NewString(),
+		AgentID:   agentID,
+		Username:  eventData.Username,
+		TTY:       eventData.TTY,
+		RunAs:     eventData.RunAs,
+		Command:   eventData.Command,
+		PWD:       eventData.PWD,
+		Success:   eventData.Success,
+		Message:   eventData.Message,
+		Timestamp: eventData.Timestamp,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if eventData.SessionID != "" {
+		session, exists, err := s.SSHSessionRepo.FindActive(ctx, agentID, eventData.SessionID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			event.SessionID = session.ID
+		}
+	}
+
+	if err := s.SSHSudoEventRepo.Create(ctx, event); err != nil {
+		s.logger.Error("保存sudo提权记录失败", zap.Error(err))
+		return err
+	}
+	if event.SessionID != "" {
+		if err := s.SSHSessionRepo.IncrSudo(ctx, event.SessionID, event.Timestamp); err != nil {
+			s.logger.Warn("更新SSH会话sudo次数失败", zap.String("sessionId", event.SessionID), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("sudo提权记录已保存",
+		zap.String("agentId", agentID),
+		zap.String("username", event.Username),
+		zap.String("runAs", event.RunAs),
+		zap.Bool("success", event.Success),
+		zap.String("sessionId", event.SessionID))
+	return nil
+}
+
+// FillLiveDuration 在线会话的时长按当前时间计算
+func (s *SSHLoginService) FillLiveDuration(sessions []models.SSHSession) {
+	now := time.Now().UnixMilli()
+	for i := range sessions {
+		if sessions[i].Status == models.SSHSessionStatusActive && now > sessions[i].StartedAt {
+			sessions[i].Duration = (now - sessions[i].StartedAt) / 1000
+		}
+	}
+}
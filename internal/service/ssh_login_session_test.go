@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestSSHLoginService 创建使用内存数据库的 SSH 登录服务，探针 a1 已启用登录监控
+func newTestSSHLoginService(t *testing.T) *SSHLoginService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Agent{}, &models.SSHLoginEvent{}, &models.SSHSession{}, &models.SSHSudoEvent{}); err != nil {
+		t.Fatalf("创建测试表失败: %v", err)
+	}
+	agent := &models.Agent{ID: "a1", Name: "web-1", SSHLoginConfig: datatypes.NewJSONType(models.SSHLoginConfigData{Enabled: true})}
+	if err := db.Create(agent).Error; err != nil {
+		t.Fatalf("写入探针失败: %v", err)
+	}
+	return NewSSHLoginService(zap.NewNop(), db, nil, nil, nil)
+}
+
+// findSessions 查询探针的所有会话，按开始时间排序
+func findSessions(t *testing.T, s *SSHLoginService) []models.SSHSession {
+	t.Helper()
+	var sessions []models.SSHSession
+	if err := s.SSHSessionRepo.GetDB(context.Background()).Order("started_at asc").Find(&sessions).Error; err != nil {
+		t.Fatalf("查询会话失败: %v", err)
+	}
+	return sessions
+}
+
+func TestSSHSessionPairing(t *testing.T) {
+	login := func(sessionID string, timestamp int64) protocol.SSHLoginEvent {
+		return protocol.SSHLoginEvent{Username: "root", IP: "10.0.0.5", Status: "success", TTY: "pts/0", SessionID: sessionID, Timestamp: timestamp}
+	}
+	logout := func(sessionID string, timestamp, openedAt int64) protocol.SSHLoginEvent {
+		return protocol.SSHLoginEvent{Username: "root", IP: "10.0.0.5", Status: "logout", SessionID: sessionID, Timestamp: timestamp, OpenedAt: openedAt}
+	}
+
+	tests := []struct {
+		name   string
+		events []protocol.SSHLoginEvent
+		want   []models.SSHSession // 按开始时间排序的会话，只比较状态、开始结束时间和时长
+	}{
+		{
+			name:   "登录后登出",
+			events: []protocol.SSHLoginEvent{login("100", 1_000), logout("100", 61_000, 1_000)},
+			want:   []models.SSHSession{{Status: models.SSHSessionStatusClosed, StartedAt: 1_000, EndedAt: 61_000, Duration: 60}},
+		},
+		{
+			name:   "未登出的会话保持在线",
+			events: []protocol.SSHLoginEvent{login("100", 1_000)},
+			want:   []models.SSHSession{{Status: models.SSHSessionStatusActive, StartedAt: 1_000}},
+		},
+		{
+			name:   "只结束会话ID相同的会话",
+			events: []protocol.SSHLoginEvent{login("100", 1_000), login("200", 2_000), logout("200", 12_000, 2_000)},
+			want: []models.SSHSession{
+				{Status: models.SSHSessionStatusActive, StartedAt: 1_000},
+				{Status: models.SSHSessionStatusClosed, StartedAt: 2_000, EndedAt: 12_000, Duration: 10},
+			},
+		},
+		{
+			name:   "会话ID被复用时结束丢失登出事件的旧会话",
+			events: []protocol.SSHLoginEvent{login("100", 1_000), login("100", 31_000)},
+			want: []models.SSHSession{
+				{Status: models.SSHSessionStatusClosed, StartedAt: 1_000, EndedAt: 31_000, Duration: 30},
+				{Status: models.SSHSessionStatusActive, StartedAt: 31_000},
+			},
+		},
+		{
+			name:   "未收到登录事件时按开始时间补录",
+			events: []protocol.SSHLoginEvent{logout("100", 121_000, 1_000)},
+			want:   []models.SSHSession{{Status: models.SSHSessionStatusClosed, StartedAt: 1_000, EndedAt: 121_000, Duration: 120}},
+		},
+		{
+			name:   "开始时间未知的登出事件不记录",
+			events: []protocol.SSHLoginEvent{logout("100", 121_000, 0)},
+		},
+		{
+			name:   "登出时间早于开始时间时时长为 0",
+			events: []protocol.SSHLoginEvent{login("100", 5_000), logout("100", 4_000, 5_000)},
+			want:   []models.SSHSession{{Status: models.SSHSessionStatusClosed, StartedAt: 5_000, EndedAt: 5_000}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSSHLoginService(t)
+			for _, event := range tt.events {
+				if err := s.HandleEvent(context.Background(), "a1", event); err != nil {
+					t.Fatalf("HandleEvent() 失败: %v", err)
+				}
+			}
+
+			sessions := findSessions(t, s)
+			if len(sessions) != len(tt.want) {
+				t.Fatalf("会话数量 = %d, want %d: %+v", len(sessions), len(tt.want), sessions)
+			}
+			for i, want := range tt.want {
+				got := sessions[i]
+				if got.Status != want.Status || got.StartedAt != want.StartedAt || got.EndedAt != want.EndedAt || got.Duration != want.Duration {
+					t.Errorf("会话[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSSHSudoAttribution(t *testing.T) {
+	s := newTestSSHLoginService(t)
+	ctx := context.Background()
+
+	for _, event := range []protocol.SSHLoginEvent{
+		{Username: "root", IP: "10.0.0.5", Status: "success", SessionID: "100", Timestamp: 1_000},
+		{Username: "deploy", IP: "10.0.0.6", Status: "success", SessionID: "200", Timestamp: 2_000},
+		{Username: "deploy", IP: "10.0.0.6", Status: "logout", SessionID: "200", Timestamp: 3_000, OpenedAt: 2_000},
+	} {
+		if err := s.HandleEvent(ctx, "a1", event); err != nil {
+			t.Fatalf("HandleEvent() 失败: %v", err)
+		}
+	}
+
+	sudos := []protocol.SSHSudoEvent{
+		{Username: "root", RunAs: "root", Command: "/usr/bin/systemctl restart nginx", Success: true, SessionID: "100", Timestamp: 5_000},
+		// 乱序到达的较早记录不回退最近一次 sudo 时间
+		{Username: "root", RunAs: "root", Command: "/usr/bin/id", Success: true, SessionID: "100", Timestamp: 4_000},
+		// 会话已结束
+		{Username: "deploy", RunAs: "root", Command: "/usr/bin/id", SessionID: "200", Timestamp: 6_000},
+		// 非 SSH 会话
+		{Username: "admin", RunAs: "root", Command: "/usr/bin/id", Timestamp: 7_000},
+	}
+	for _, event := range sudos {
+		if err := s.HandleSudoEvent(ctx, "a1", event); err != nil {
+			t.Fatalf("HandleSudoEvent() 失败: %v", err)
+		}
+	}
+
+	sessions := findSessions(t, s)
+	if len(sessions) != 2 {
+		t.Fatalf("会话数量 = %d, want 2", len(sessions))
+	}
+	active, closed := sessions[0], sessions[1]
+	if active.SudoCount != 2 || active.LastSudoAt != 5_000 {
+		t.Errorf("在线会话 sudo 次数 = %d，最近时间 = %d，want 2, 5000", active.SudoCount, active.LastSudoAt)
+	}
+	if closed.SudoCount != 0 {
+		t.Errorf("已结束的会话不应计入 sudo，实际 %d", closed.SudoCount)
+	}
+
+	var events []models.SSHSudoEvent
+	if err := s.SSHSudoEventRepo.GetDB(ctx).Order("timestamp asc").Find(&events).Error; err != nil {
+		t.Fatalf("查询 sudo 记录失败: %v", err)
+	}
+	if len(events) != len(sudos) {
+		t.Fatalf("sudo 记录数量 = %d, want %d", len(events), len(sudos))
+	}
+	wantSession := []string{active.ID, active.ID, "", ""}
+	for i, event := range events {
+		if event.SessionID != wantSession[i] {
+			t.Errorf("sudo 记录 %s 关联会话 = %q, want %q", event.Command, event.SessionID, wantSession[i])
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/dushixiang/pika/internal/models"
@@ -11,12 +13,16 @@ import (
 	"github.com/dushixiang/pika/internal/repo"
 	"github.com/dushixiang/pika/internal/websocket"
 
+	"github.com/go-orz/orz"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// maxTamperUnlockMinutes 单次临时解锁的最长时间（分钟），与探针端上限一致
+const maxTamperUnlockMinutes = 24 * 60
+
 type TamperService struct {
 	logger          *zap.Logger
 	agentRepo       *repo.AgentRepo
@@ -55,6 +61,13 @@ func (s *TamperService) UpdateConfigByAgentID(ctx context.Context, agentID strin
 
 // UpdateConfig 更新探针的防篡改配置
 func (s *TamperService) UpdateConfig(ctx context.Context, agentID string, req *models.TamperProtectConfigData) error {
+	if !isValidTamperMode(req.Mode) {
+		return orz.NewError(400, "不支持的保护模式")
+	}
+	if req.HashInterval < 0 {
+		return orz.NewError(400, "哈希校验间隔不能为负数")
+	}
+
 	// 查找现有配置
 	config, err := s.GetConfigByAgentID(ctx, agentID)
 	if err != nil {
@@ -64,9 +77,13 @@ func (s *TamperService) UpdateConfig(ctx context.Context, agentID string, req *m
 	// 获取旧的路径列表和启用状态用于比对
 	var oldPaths []string
 	var wasEnabled bool
+	var optionsChanged bool
 	if config != nil {
 		oldPaths = config.Paths
 		wasEnabled = config.Enabled
+		optionsChanged = normalizeTamperMode(config.Mode) != normalizeTamperMode(req.Mode) ||
+			config.HashInterval != req.HashInterval ||
+			!sameStringSet(config.EnforcePaths, filterEnforcePaths(req.Paths, req.EnforcePaths))
 	}
 
 	var added, removed []string
@@ -89,9 +106,20 @@ func (s *TamperService) UpdateConfig(ctx context.Context, agentID string, req *m
 
 	// 创建或更新配置
 	newConfig := &models.TamperProtectConfigData{
-		Enabled:     req.Enabled,
-		Paths:       req.Paths,
-		ApplyStatus: "pending",
+		Enabled:      req.Enabled,
+		Paths:        req.Paths,
+		Mode:         normalizeTamperMode(req.Mode),
+		HashInterval: req.HashInterval,
+		EnforcePaths: filterEnforcePaths(req.Paths, req.EnforcePaths),
+		ApplyStatus:  "pending",
+	}
+	// 保留仍然有效的临时解锁窗口（禁用或移除目录时探针端会一并取消）
+	if req.Enabled && config != nil {
+		for _, window := range activeUnlocks(config.Unlocks, time.Now().UnixMilli()) {
+			if slices.Contains(req.Paths, window.Path) {
+				newConfig.Unlocks = append(newConfig.Unlocks, window)
+			}
+		}
 	}
 
 	// 保存配置到数据库
@@ -101,7 +129,9 @@ func (s *TamperService) UpdateConfig(ctx context.Context, agentID string, req *m
 
 	go func() {
 		// 下发增量配置到探针
-		if err := s.sendIncrementalConfigToAgent(agentID, added, removed); err != nil {
+		// 启用状态下仅修改了保护模式、校验间隔或强制恢复策略，也需要下发
+		force := req.Enabled && optionsChanged
+		if err := s.sendIncrementalConfigToAgent(agentID, added, removed, newConfig, force); err != nil {
 			s.logger.Warn("下发防篡改配置到探针失败",
 				zap.String("agentId", agentID),
 				zap.Strings("added", added),
@@ -151,36 +181,191 @@ func (s *TamperService) calculatePathDiff(oldPaths, newPaths []string) (added, r
 
 // BuildInitialConfig 构建探针初始化时的配置（用于探针连接时）
 // 根据 enabled 状态决定发送的内容：
-// - enabled=true: 发送所有配置的路径作为新增
+// - enabled=true: 发送所有配置的路径作为新增，并携带保护模式
 // - enabled=false: 发送空配置
-func (s *TamperService) BuildInitialConfig(ctx context.Context, agentID string) (added, removed []string, err error) {
+func (s *TamperService) BuildInitialConfig(ctx context.Context, agentID string) (*protocol.TamperProtectConfig, error) {
 	config, err := s.GetConfigByAgentID(ctx, agentID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	// 如果启用了防篡改，将所有路径作为新增发送
+	// 如果启用了防篡改，将所有路径作为新增发送，并携带剩余的临时解锁窗口
 	if config != nil && config.Enabled && len(config.Paths) > 0 {
-		return config.Paths, []string{}, nil
+		configData := &protocol.TamperProtectConfig{
+			Added:        config.Paths,
+			Removed:      []string{},
+			Mode:         normalizeTamperMode(config.Mode),
+			HashInterval: config.HashInterval,
+			Enforce:      config.EnforcePaths,
+		}
+		now := time.Now().UnixMilli()
+		for _, window := range activeUnlocks(config.Unlocks, now) {
+			configData.Unlock = append(configData.Unlock, protocol.TamperUnlock{
+				Path:     window.Path,
+				Duration: int((window.Until - now + 999) / 1000),
+			})
+		}
+		return configData, nil
 	}
 
 	// 未启用或没有配置，返回空
-	return []string{}, []string{}, nil
+	return &protocol.TamperProtectConfig{
+		Added:   []string{},
+		Removed: []string{},
+	}, nil
 }
 
 // sendIncrementalConfigToAgent 通过WebSocket下发配置到探针（增量更新）
-func (s *TamperService) sendIncrementalConfigToAgent(agentID string, added, removed []string) error {
+func (s *TamperService) sendIncrementalConfigToAgent(agentID string, added, removed []string, config *models.TamperProtectConfigData, force bool) error {
 	// 如果没有任何变更，不需要下发
-	if len(added) == 0 && len(removed) == 0 {
+	if len(added) == 0 && len(removed) == 0 && !force {
 		return nil
 	}
 
 	// 构建增量更新配置消息
 	configData := protocol.TamperProtectConfig{
-		Added:   added,
-		Removed: removed,
+		Added:        added,
+		Removed:      removed,
+		Mode:         config.Mode,
+		HashInterval: config.HashInterval,
+	}
+	if config.Enabled {
+		configData.Enforce = config.EnforcePaths
+	}
+
+	return s.sendConfigMessage(agentID, configData)
+}
+
+// RefreshSnapshot 通知探针以当前内容刷新强制恢复快照，paths 为空时刷新所有强制恢复目录
+func (s *TamperService) RefreshSnapshot(ctx context.Context, agentID string, paths []string) error {
+	config, err := s.GetConfigByAgentID(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	if !config.Enabled || len(config.EnforcePaths) == 0 {
+		return orz.NewError(400, "未启用强制恢复策略")
+	}
+
+	enforced := make(map[string]bool, len(config.EnforcePaths))
+	for _, path := range config.EnforcePaths {
+		enforced[path] = true
+	}
+	for _, path := range paths {
+		if !enforced[path] {
+			return orz.NewError(400, fmt.Sprintf("目录未启用强制恢复: %s", path))
+		}
+	}
+	if len(paths) == 0 {
+		paths = config.EnforcePaths
+	}
+
+	// 携带完整的保护选项，避免探针端重置模式和强制恢复策略
+	configData := protocol.TamperProtectConfig{
+		Mode:            normalizeTamperMode(config.Mode),
+		HashInterval:    config.HashInterval,
+		Enforce:         config.EnforcePaths,
+		RefreshSnapshot: paths,
+	}
+	if err := s.sendConfigMessage(agentID, configData); err != nil {
+		return orz.NewError(400, "探针不在线")
+	}
+
+	config.ApplyStatus = "pending"
+	config.ApplyMessage = ""
+	return s.UpdateConfigByAgentID(ctx, agentID, config)
+}
+
+// Unlock 临时解除目录保护，到期后探针自动重新保护并以当前内容更新基线，窗口内不发送通知
+func (s *TamperService) Unlock(ctx context.Context, agentID, path string, minutes int, reason, operator string) (*models.TamperUnlockWindow, error) {
+	if minutes <= 0 || minutes > maxTamperUnlockMinutes {
+		return nil, orz.NewError(400, fmt.Sprintf("解锁时长需在 1-%d 分钟之间", maxTamperUnlockMinutes))
+	}
+
+	config, err := s.GetConfigByAgentID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled || !slices.Contains(config.Paths, path) {
+		return nil, orz.NewError(400, "目录未受保护")
+	}
+
+	now := time.Now().UnixMilli()
+	window := models.TamperUnlockWindow{
+		Path:      path,
+		Until:     now + int64(minutes)*time.Minute.Milliseconds(),
+		Reason:    reason,
+		CreatedBy: operator,
+		CreatedAt: now,
+	}
+
+	if err := s.sendUnlock(agentID, config, protocol.TamperUnlock{Path: path, Duration: minutes * 60}); err != nil {
+		return nil, orz.NewError(400, "探针不在线")
+	}
+
+	unlocks := []models.TamperUnlockWindow{window}
+	for _, existing := range activeUnlocks(config.Unlocks, now) {
+		if existing.Path != path {
+			unlocks = append(unlocks, existing)
+		}
+	}
+	config.Unlocks = unlocks
+	config.ApplyStatus = "pending"
+	config.ApplyMessage = ""
+	if err := s.UpdateConfigByAgentID(ctx, agentID, config); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("临时解除防篡改保护",
+		zap.String("agentId", agentID),
+		zap.String("path", path),
+		zap.Int("minutes", minutes),
+		zap.String("operator", operator))
+	return &window, nil
+}
+
+// Relock 提前结束临时解锁窗口
+func (s *TamperService) Relock(ctx context.Context, agentID, path string) error {
+	config, err := s.GetConfigByAgentID(ctx, agentID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	var unlocks []models.TamperUnlockWindow
+	found := false
+	for _, window := range activeUnlocks(config.Unlocks, now) {
+		if window.Path == path {
+			found = true
+			continue
+		}
+		unlocks = append(unlocks, window)
 	}
+	if !found {
+		return orz.NewError(400, "目录未处于临时解锁状态")
+	}
+
+	if err := s.sendUnlock(agentID, config, protocol.TamperUnlock{Path: path, Duration: 0}); err != nil {
+		return orz.NewError(400, "探针不在线")
+	}
+
+	config.Unlocks = unlocks
+	config.ApplyStatus = "pending"
+	config.ApplyMessage = ""
+	return s.UpdateConfigByAgentID(ctx, agentID, config)
+}
+
+// sendUnlock 下发临时解锁（携带完整的保护选项，避免探针端重置模式和强制恢复策略）
+func (s *TamperService) sendUnlock(agentID string, config *models.TamperProtectConfigData, unlock protocol.TamperUnlock) error {
+	return s.sendConfigMessage(agentID, protocol.TamperProtectConfig{
+		Mode:         normalizeTamperMode(config.Mode),
+		HashInterval: config.HashInterval,
+		Enforce:      config.EnforcePaths,
+		Unlock:       []protocol.TamperUnlock{unlock},
+	})
+}
 
+// sendConfigMessage 通过WebSocket发送防篡改配置消息
+func (s *TamperService) sendConfigMessage(agentID string, configData protocol.TamperProtectConfig) error {
 	msgBytes, err := json.Marshal(protocol.OutboundMessage{
 		Type: protocol.MessageTypeTamperProtect,
 		Data: configData,
@@ -201,17 +386,51 @@ func (s *TamperService) CreateEvent(ctx context.Context, agentID string, eventDa
 		Path:      eventData.Path,
 		Operation: eventData.Operation,
 		Details:   eventData.Details,
+		OldHash:   eventData.OldHash,
+		NewHash:   eventData.NewHash,
+		Restored:  eventData.Restored,
 		Timestamp: eventData.Timestamp,
 		CreatedAt: time.Now().UnixMilli(),
 	}
+	if eventData.Process != nil {
+		event.Process = datatypes.NewJSONType(*eventData.Process)
+	}
 	if err := s.TamperEventRepo.Create(ctx, event); err != nil {
 		return err
 	}
 
+	if s.isNotificationSuppressed(ctx, agentID, eventData) {
+		return nil
+	}
 	s.sendTamperEventNotification(ctx, agentID, eventData)
 	return nil
 }
 
+// isNotificationSuppressed 临时解锁窗口内的事件以及解锁/重新保护事件不发送通知
+func (s *TamperService) isNotificationSuppressed(ctx context.Context, agentID string, eventData *protocol.TamperEventData) bool {
+	if eventData.Operation == "unlock" || eventData.Operation == "relock" {
+		return true
+	}
+
+	config, err := s.GetConfigByAgentID(ctx, agentID)
+	if err != nil {
+		return false
+	}
+	timestamp := eventData.Timestamp
+	if timestamp == 0 {
+		timestamp = time.Now().UnixMilli()
+	}
+	for _, window := range config.Unlocks {
+		if timestamp < window.CreatedAt || timestamp > window.Until {
+			continue
+		}
+		if eventData.Path == window.Path || strings.HasPrefix(eventData.Path, strings.TrimSuffix(window.Path, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *TamperService) sendTamperEventNotification(ctx context.Context, agentID string, eventData *protocol.TamperEventData) {
 	if s.notificationSvc == nil {
 		return
@@ -233,11 +452,19 @@ func (s *TamperService) sendTamperEventNotification(ctx context.Context, agentID
 		restoredText = "是"
 	}
 
+	message := fmt.Sprintf("防篡改事件：路径 %s，操作 %s，详情 %s，自动恢复 %s", eventData.Path, eventData.Operation, eventData.Details, restoredText)
+	if eventData.OldHash != "" || eventData.NewHash != "" {
+		message += fmt.Sprintf("，哈希 %s → %s", shortHash(eventData.OldHash), shortHash(eventData.NewHash))
+	}
+	if eventData.Process != nil {
+		message += "，" + formatTamperProcess(eventData.Process)
+	}
+
 	record := &models.AlertRecord{
 		AgentID:     agentID,
 		AgentName:   agent.Name,
 		AlertType:   "tamper",
-		Message:     fmt.Sprintf("防篡改事件：路径 %s，操作 %s，详情 %s，自动恢复 %s", eventData.Path, eventData.Operation, eventData.Details, restoredText),
+		Message:     message,
 		Threshold:   0,
 		ActualValue: 0,
 		Level:       "warning",
@@ -286,3 +513,90 @@ func (s *TamperService) HandleConfigResult(ctx context.Context, agentID string,
 func (s *TamperService) DeleteEventsByAgentID(ctx context.Context, id string) error {
 	return s.TamperEventRepo.DeleteEventsByAgentID(ctx, id)
 }
+
+// isValidTamperMode 校验防篡改保护模式
+func isValidTamperMode(mode string) bool {
+	switch mode {
+	case "", models.TamperModeImmutable, models.TamperModeHash, models.TamperModeHybrid:
+		return true
+	}
+	return false
+}
+
+// normalizeTamperMode 空模式视为默认的不可变属性模式
+func normalizeTamperMode(mode string) string {
+	if mode == "" {
+		return models.TamperModeImmutable
+	}
+	return mode
+}
+
+// activeUnlocks 过滤出尚未到期的临时解锁窗口
+func activeUnlocks(unlocks []models.TamperUnlockWindow, now int64) []models.TamperUnlockWindow {
+	var result []models.TamperUnlockWindow
+	for _, window := range unlocks {
+		if window.Until > now {
+			result = append(result, window)
+		}
+	}
+	return result
+}
+
+// filterEnforcePaths 强制恢复目录必须是受保护目录
+func filterEnforcePaths(paths, enforcePaths []string) []string {
+	protected := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		protected[path] = true
+	}
+
+	var result []string
+	for _, path := range enforcePaths {
+		if protected[path] && !slices.Contains(result, path) {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// sameStringSet 判断两个字符串列表的元素是否相同（忽略顺序）
+func sameStringSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, item := range a {
+		if !slices.Contains(b, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTamperProcess 格式化修改文件的进程信息
+func formatTamperProcess(p *protocol.TamperProcess) string {
+	user := p.Username
+	if user == "" {
+		user = fmt.Sprintf("uid=%d", p.UID)
+	}
+	text := fmt.Sprintf("进程 %s（PID %d，用户 %s）", p.Exe, p.PID, user)
+	if p.Exe == "" {
+		text = fmt.Sprintf("进程 PID %d（用户 %s）", p.PID, user)
+	}
+	if p.Cmdline != "" {
+		text += "，命令 " + truncateString(p.Cmdline, 200)
+	}
+	if len(p.ProcessTree) > 0 {
+		text += "，进程链 " + strings.Join(p.ProcessTree, " → ")
+	}
+	return text
+}
+
+// shortHash 截取哈希前 12 位用于通知展示
+func shortHash(hash string) string {
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
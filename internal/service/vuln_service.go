@@ -0,0 +1,684 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// 漏洞库数据来源（导入格式）
+const (
+	VulnSourceOSV    = "osv"    // OSV 格式（JSON 数组/单个对象/zip 包）
+	VulnSourceDebian = "debian" // Debian Security Tracker JSON
+
+	vulnImportBatchSize = 2000
+)
+
+// debianReleaseVersions Debian 代号与版本号映射（Security Tracker 按代号组织数据）
+var debianReleaseVersions = map[string]string{
+	"buster":   "10",
+	"bullseye": "11",
+	"bookworm": "12",
+	"trixie":   "13",
+	"forky":    "14",
+}
+
+// vulnSeverityRank 严重程度排序
+var vulnSeverityRank = map[string]int{
+	SeverityCritical: 0,
+	SeverityHigh:     1,
+	SeverityMedium:   2,
+	SeverityLow:      3,
+	"unknown":        4,
+}
+
+// VulnerabilityService 离线漏洞库服务
+type VulnerabilityService struct {
+	logger *zap.Logger
+	*orz.Service
+	VulnerabilityRepo *repo.VulnerabilityRepo
+	AgentRepo         *repo.AgentRepo
+}
+
+func NewVulnerabilityService(logger *zap.Logger, db *gorm.DB) *VulnerabilityService {
+	return &VulnerabilityService{
+		logger:            logger,
+		Service:           orz.NewService(db),
+		VulnerabilityRepo: repo.NewVulnerabilityRepo(db),
+		AgentRepo:         repo.NewAgentRepo(db),
+	}
+}
+
+// VulnImportResult 漏洞库导入结果
+type VulnImportResult struct {
+	Source  string `json:"source"`  // 数据来源
+	Records int    `json:"records"` // 写入条目数
+	Skipped int    `json:"skipped"` // 跳过的条目数（已撤回/不受影响/无法识别）
+}
+
+// VulnDBStatus 漏洞库状态
+type VulnDBStatus struct {
+	Total   int64                          `json:"total"`
+	Sources []repo.VulnerabilitySourceStat `json:"sources"`
+}
+
+// AgentVulnerabilityReport 探针漏洞匹配结果
+type AgentVulnerabilityReport struct {
+	AgentID            string                 `json:"agentId"`
+	AuditID            int64                  `json:"auditId"`            // 使用的审计记录ID
+	AuditTime          int64                  `json:"auditTime"`          // 审计时间
+	Manager            string                 `json:"manager"`            // 包管理器
+	Distro             string                 `json:"distro"`             // 发行版
+	DistroVersion      string                 `json:"distroVersion"`      // 发行版版本
+	Ecosystems         []string               `json:"ecosystems"`         // 匹配使用的漏洞库生态
+	TotalPackages      int                    `json:"totalPackages"`      // 已安装软件包数量
+	VulnerablePackages int                    `json:"vulnerablePackages"` // 存在漏洞的软件包数量
+	SeverityCounts     map[string]int         `json:"severityCounts"`     // 各严重程度漏洞数量
+	Items              []PackageVulnerability `json:"items"`
+	Message            string                 `json:"message,omitempty"`
+}
+
+// PackageVulnerability 软件包漏洞
+type PackageVulnerability struct {
+	Package      string   `json:"package"`      // 已安装软件包名
+	Version      string   `json:"version"`      // 已安装版本
+	Source       string   `json:"source"`       // 源码包名
+	Arch         string   `json:"arch"`         // 架构
+	VulnID       string   `json:"vulnId"`       // 漏洞ID
+	CVEs         []string `json:"cves"`         // 关联 CVE ID
+	Severity     string   `json:"severity"`     // 严重程度
+	Summary      string   `json:"summary"`      // 描述
+	FixedVersion string   `json:"fixedVersion"` // 修复版本，空表示尚未修复
+	DataSource   string   `json:"dataSource"`   // 漏洞库数据来源
+}
+
+// Import 导入漏洞库，同一来源的旧数据会被整体替换
+func (s *VulnerabilityService) Import(ctx context.Context, source string, reader io.ReaderAt, size int64) (*VulnImportResult, error) {
+	result := &VulnImportResult{Source: source}
+	importedAt := time.Now().UnixMilli()
+
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.VulnerabilityRepo.DeleteBySource(ctx, source); err != nil {
+			return err
+		}
+
+		batch := make([]models.Vulnerability, 0, vulnImportBatchSize)
+		flush := func() error {
+			if err := s.VulnerabilityRepo.CreateBatch(ctx, batch); err != nil {
+				return err
+			}
+			result.Records += len(batch)
+			batch = batch[:0]
+			return nil
+		}
+		emit := func(item models.Vulnerability) error {
+			item.Source = source
+			item.ImportedAt = importedAt
+			batch = append(batch, item)
+			if len(batch) >= vulnImportBatchSize {
+				return flush()
+			}
+			return nil
+		}
+
+		var err error
+		switch source {
+		case VulnSourceOSV:
+			err = s.importOSV(reader, size, emit, result)
+		case VulnSourceDebian:
+			err = s.importDebianTracker(io.NewSectionReader(reader, 0, size), emit, result)
+		default:
+			return orz.NewError(400, "不支持的漏洞库格式: "+source)
+		}
+		if err != nil {
+			return err
+		}
+		return flush()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("漏洞库导入完成",
+		zap.String("source", source),
+		zap.Int("records", result.Records),
+		zap.Int("skipped", result.Skipped))
+	return result, nil
+}
+
+// importOSV 导入 OSV 格式数据，自动识别 zip 包、JSON 数组及单个 JSON 对象
+func (s *VulnerabilityService) importOSV(reader io.ReaderAt, size int64, emit func(models.Vulnerability) error, result *VulnImportResult) error {
+	header := make([]byte, 2)
+	if _, err := reader.ReadAt(header, 0); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	if bytes.Equal(header, []byte("PK")) {
+		zipReader, err := zip.NewReader(reader, size)
+		if err != nil {
+			return orz.NewError(400, "无法解析 zip 文件: "+err.Error())
+		}
+		for _, file := range zipReader.File {
+			if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(file.Name), ".json") {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return err
+			}
+			err = decodeOSVStream(rc, emit, result)
+			rc.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", file.Name, err)
+			}
+		}
+		return nil
+	}
+
+	return decodeOSVStream(io.NewSectionReader(reader, 0, size), emit, result)
+}
+
+// osvEntry OSV 漏洞条目（仅解析需要的字段）
+type osvEntry struct {
+	ID               string         `json:"id"`
+	Aliases          []string       `json:"aliases"`
+	Upstream         []string       `json:"upstream"`
+	Summary          string         `json:"summary"`
+	Details          string         `json:"details"`
+	Withdrawn        string         `json:"withdrawn"`
+	DatabaseSpecific map[string]any `json:"database_specific"`
+	Affected         []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string              `json:"type"`
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		Versions          []string       `json:"versions"`
+		EcosystemSpecific map[string]any `json:"ecosystem_specific"`
+		DatabaseSpecific  map[string]any `json:"database_specific"`
+	} `json:"affected"`
+}
+
+// decodeOSVStream 流式解析 OSV JSON（数组或单个对象）
+func decodeOSVStream(r io.Reader, emit func(models.Vulnerability) error, result *VulnImportResult) error {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err != nil {
+		return orz.NewError(400, "OSV 数据为空")
+	}
+
+	decoder := json.NewDecoder(br)
+	if first != '[' {
+		var entry osvEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return orz.NewError(400, "无法解析 OSV 数据: "+err.Error())
+		}
+		return emitOSVEntry(&entry, emit, result)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		var entry osvEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return orz.NewError(400, "无法解析 OSV 数据: "+err.Error())
+		}
+		if err := emitOSVEntry(&entry, emit, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitOSVEntry 将 OSV 条目拆分为每个受影响软件包、每个版本区间一条记录
+func emitOSVEntry(entry *osvEntry, emit func(models.Vulnerability) error, result *VulnImportResult) error {
+	if entry.ID == "" || entry.Withdrawn != "" {
+		result.Skipped++
+		return nil
+	}
+
+	aliases := mergeUniqueStrings(entry.Aliases, entry.Upstream)
+	summary := entry.Summary
+	if summary == "" {
+		summary = truncateString(entry.Details, 512)
+	}
+
+	for _, affected := range entry.Affected {
+		if affected.Package.Ecosystem == "" || affected.Package.Name == "" {
+			result.Skipped++
+			continue
+		}
+
+		base := models.Vulnerability{
+			VulnID:    entry.ID,
+			Aliases:   aliases,
+			Ecosystem: affected.Package.Ecosystem,
+			Package:   affected.Package.Name,
+			Severity: firstVulnSeverity(
+				affected.EcosystemSpecific["urgency"],
+				affected.EcosystemSpecific["severity"],
+				affected.DatabaseSpecific["severity"],
+				entry.DatabaseSpecific["severity"],
+			),
+			Summary: summary,
+		}
+
+		emitted := false
+		for _, rng := range affected.Ranges {
+			if rng.Type == "GIT" {
+				continue
+			}
+			// 按事件顺序拼接区间：introduced 开启区间，fixed/last_affected 关闭区间
+			var current *models.Vulnerability
+			for _, event := range rng.Events {
+				if introduced, ok := event["introduced"]; ok {
+					item := base
+					item.Introduced = introduced
+					current = &item
+					continue
+				}
+				if current == nil {
+					continue
+				}
+				if fixed, ok := event["fixed"]; ok {
+					current.Fixed = fixed
+				} else if lastAffected, ok := event["last_affected"]; ok {
+					current.LastAffected = lastAffected
+				} else {
+					continue
+				}
+				if err := emit(*current); err != nil {
+					return err
+				}
+				emitted = true
+				current = nil
+			}
+			// 未关闭的区间表示尚未修复
+			if current != nil {
+				if err := emit(*current); err != nil {
+					return err
+				}
+				emitted = true
+			}
+		}
+
+		if len(affected.Versions) > 0 {
+			item := base
+			item.Versions = affected.Versions
+			if err := emit(item); err != nil {
+				return err
+			}
+			emitted = true
+		}
+
+		if !emitted {
+			result.Skipped++
+		}
+	}
+	return nil
+}
+
+// debianTrackerCVE Debian Security Tracker 中单个 CVE 的数据
+type debianTrackerCVE struct {
+	Description string `json:"description"`
+	Releases    map[string]struct {
+		Status       string `json:"status"`
+		FixedVersion string `json:"fixed_version"`
+		Urgency      string `json:"urgency"`
+	} `json:"releases"`
+}
+
+// importDebianTracker 流式导入 Debian Security Tracker JSON（https://security-tracker.debian.org/tracker/data/json）
+// 结构为 {源码包名: {CVE ID: {description, releases: {代号: {status, fixed_version, urgency}}}}}
+func (s *VulnerabilityService) importDebianTracker(r io.Reader, emit func(models.Vulnerability) error, result *VulnImportResult) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return orz.NewError(400, "无法解析 Debian Security Tracker 数据")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return orz.NewError(400, "无法解析 Debian Security Tracker 数据: "+err.Error())
+		}
+		packageName, _ := token.(string)
+
+		var cves map[string]debianTrackerCVE
+		if err := decoder.Decode(&cves); err != nil {
+			return orz.NewError(400, "无法解析 Debian Security Tracker 数据: "+err.Error())
+		}
+
+		for cveID, cve := range cves {
+			// TEMP- 等临时编号没有 CVE ID，跳过
+			if !strings.HasPrefix(cveID, "CVE-") {
+				result.Skipped++
+				continue
+			}
+			for codename, release := range cve.Releases {
+				version, ok := debianReleaseVersions[codename]
+				if !ok {
+					continue
+				}
+				fixed := release.FixedVersion
+				switch release.Status {
+				case "resolved":
+					// fixed_version 为 0 表示该版本不受影响
+					if fixed == "" || fixed == "0" {
+						result.Skipped++
+						continue
+					}
+				case "open", "undetermined":
+					fixed = ""
+				default:
+					result.Skipped++
+					continue
+				}
+
+				if err := emit(models.Vulnerability{
+					VulnID:     cveID,
+					Aliases:    datatypes.JSONSlice[string]{},
+					Ecosystem:  "Debian:" + version,
+					Package:    packageName,
+					Introduced: "0",
+					Fixed:      fixed,
+					Severity:   normalizeVulnSeverity(release.Urgency),
+					Summary:    truncateString(cve.Description, 512),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetStatus 获取漏洞库状态
+func (s *VulnerabilityService) GetStatus(ctx context.Context) (*VulnDBStatus, error) {
+	stats, err := s.VulnerabilityRepo.GetSourceStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := &VulnDBStatus{Sources: stats}
+	for _, stat := range stats {
+		status.Total += stat.Count
+	}
+	return status, nil
+}
+
+// Clear 清空漏洞库，source 为空时清空全部来源
+func (s *VulnerabilityService) Clear(ctx context.Context, source string) (int64, error) {
+	return s.VulnerabilityRepo.DeleteBySource(ctx, source)
+}
+
+// MatchAgent 使用探针最近一次审计的软件包清单匹配漏洞库
+func (s *VulnerabilityService) MatchAgent(ctx context.Context, agentID string) (*AgentVulnerabilityReport, error) {
+	report := &AgentVulnerabilityReport{
+		AgentID:        agentID,
+		SeverityCounts: map[string]int{},
+		Items:          []PackageVulnerability{},
+	}
+
+	record, err := s.AgentRepo.GetLatestAuditResultByType(ctx, agentID, "vps_audit")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			report.Message = "暂无审计记录，请先执行安全审计"
+			return report, nil
+		}
+		return nil, err
+	}
+	report.AuditID = record.ID
+	report.AuditTime = record.EndTime
+
+	var result protocol.VPSAuditResult
+	if err := json.Unmarshal([]byte(record.Result), &result); err != nil {
+		return nil, err
+	}
+	assets := result.AssetInventory.PackageAssets
+	if assets == nil || len(assets.Packages) == 0 {
+		report.Message = "最近一次审计未包含软件包信息，请升级探针后重新审计"
+		return report, nil
+	}
+
+	report.Manager = assets.Manager
+	report.Distro = assets.Distro
+	report.DistroVersion = assets.DistroVersion
+	report.TotalPackages = len(assets.Packages)
+	report.Ecosystems = vulnEcosystems(assets)
+	if len(report.Ecosystems) == 0 {
+		report.Message = fmt.Sprintf("暂不支持的发行版: %s %s", assets.Distro, assets.DistroVersion)
+		return report, nil
+	}
+
+	// 漏洞库中 Debian/Ubuntu/Alpine 等按源码包记录，二进制包名与源码包名都参与匹配
+	nameSet := make(map[string]struct{})
+	for _, pkg := range assets.Packages {
+		nameSet[pkg.Name] = struct{}{}
+		if pkg.Source != "" {
+			nameSet[pkg.Source] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		names = append(names, name)
+	}
+
+	vulns, err := s.VulnerabilityRepo.FindByEcosystemsAndPackages(ctx, report.Ecosystems, names)
+	if err != nil {
+		return nil, err
+	}
+	byPackage := make(map[string][]models.Vulnerability)
+	for _, vuln := range vulns {
+		byPackage[vuln.Package] = append(byPackage[vuln.Package], vuln)
+	}
+
+	for _, pkg := range assets.Packages {
+		candidates := append([]models.Vulnerability{}, byPackage[pkg.Name]...)
+		if pkg.Source != "" && pkg.Source != pkg.Name {
+			candidates = append(candidates, byPackage[pkg.Source]...)
+		}
+
+		// 同一漏洞可能同时存在于多个来源（如 DSA 与 CVE），按首个 CVE ID 去重
+		seen := make(map[string]bool)
+		for i := range candidates {
+			vuln := &candidates[i]
+			if !vulnAffects(assets.Manager, pkg.Version, vuln) {
+				continue
+			}
+			cves := vulnCVEs(vuln)
+			key := vuln.VulnID
+			if len(cves) > 0 {
+				key = cves[0]
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			report.Items = append(report.Items, PackageVulnerability{
+				Package:      pkg.Name,
+				Version:      pkg.Version,
+				Source:       pkg.Source,
+				Arch:         pkg.Arch,
+				VulnID:       vuln.VulnID,
+				CVEs:         cves,
+				Severity:     vuln.Severity,
+				Summary:      vuln.Summary,
+				FixedVersion: vuln.Fixed,
+				DataSource:   vuln.Source,
+			})
+			report.SeverityCounts[vuln.Severity]++
+		}
+		if len(seen) > 0 {
+			report.VulnerablePackages++
+		}
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if vulnSeverityRank[a.Severity] != vulnSeverityRank[b.Severity] {
+			return vulnSeverityRank[a.Severity] < vulnSeverityRank[b.Severity]
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.VulnID < b.VulnID
+	})
+
+	return report, nil
+}
+
+// vulnEcosystems 根据发行版信息推导 OSV 生态名称
+func vulnEcosystems(assets *protocol.PackageAssets) []string {
+	version := assets.DistroVersion
+	major, _, _ := strings.Cut(version, ".")
+
+	switch assets.Distro {
+	case "debian":
+		if major == "" {
+			return nil
+		}
+		return []string{"Debian:" + major}
+	case "ubuntu":
+		if version == "" {
+			return nil
+		}
+		return []string{"Ubuntu:" + version}
+	case "alpine":
+		parts := strings.Split(version, ".")
+		if len(parts) < 2 {
+			return nil
+		}
+		return []string{"Alpine:v" + parts[0] + "." + parts[1]}
+	case "rocky":
+		return []string{"Rocky Linux:" + major}
+	case "almalinux":
+		return []string{"AlmaLinux:" + major}
+	case "rhel", "centos":
+		return []string{"Red Hat:enterprise_linux:" + major}
+	default:
+		return nil
+	}
+}
+
+// vulnAffects 判断已安装版本是否受漏洞影响
+func vulnAffects(scheme, version string, vuln *models.Vulnerability) bool {
+	for _, affected := range vuln.Versions {
+		if affected == version {
+			return true
+		}
+	}
+	// 仅列出具体版本的条目
+	if vuln.Introduced == "" {
+		return false
+	}
+	if vuln.Introduced != "0" && compareVersions(scheme, version, vuln.Introduced) < 0 {
+		return false
+	}
+	if vuln.Fixed != "" {
+		return compareVersions(scheme, version, vuln.Fixed) < 0
+	}
+	if vuln.LastAffected != "" {
+		return compareVersions(scheme, version, vuln.LastAffected) <= 0
+	}
+	return true
+}
+
+// vulnCVEs 提取漏洞关联的 CVE ID
+func vulnCVEs(vuln *models.Vulnerability) []string {
+	var cves []string
+	if strings.HasPrefix(vuln.VulnID, "CVE-") {
+		cves = append(cves, vuln.VulnID)
+	}
+	for _, alias := range vuln.Aliases {
+		if strings.HasPrefix(alias, "CVE-") && alias != vuln.VulnID {
+			cves = append(cves, alias)
+		}
+	}
+	return cves
+}
+
+// firstVulnSeverity 返回第一个可识别的严重程度
+func firstVulnSeverity(values ...any) string {
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			if severity := normalizeVulnSeverity(str); severity != "unknown" {
+				return severity
+			}
+		}
+	}
+	return "unknown"
+}
+
+// normalizeVulnSeverity 统一各数据源的严重程度表述
+func normalizeVulnSeverity(value string) string {
+	value = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(value, "*")))
+	switch value {
+	case "critical":
+		return SeverityCritical
+	case "high", "important":
+		return SeverityHigh
+	case "medium", "moderate":
+		return SeverityMedium
+	case "low", "negligible", "unimportant":
+		return SeverityLow
+	default:
+		return "unknown"
+	}
+}
+
+func mergeUniqueStrings(lists ...[]string) datatypes.JSONSlice[string] {
+	seen := make(map[string]bool)
+	merged := datatypes.JSONSlice[string]{}
+	for _, list := range lists {
+		for _, item := range list {
+			if item == "" || seen[item] {
+				continue
+			}
+			seen[item] = true
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+func truncateString(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit]) + "..."
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b == ' ' || b == '\n' || b == '\r' || b == '\t' {
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 版本比较方式，对应探针端包管理器
+const (
+	VersionSchemeDpkg = "dpkg"
+	VersionSchemeRpm  = "rpm"
+	VersionSchemeApk  = "apk"
+)
+
+// compareVersions 按包管理器的版本规则比较 a、b，返回 -1/0/1
+func compareVersions(scheme, a, b string) int {
+	switch scheme {
+	case VersionSchemeRpm:
+		return compareRpmVersion(a, b)
+	case VersionSchemeApk:
+		return compareApkVersion(a, b)
+	default:
+		return compareDpkgVersion(a, b)
+	}
+}
+
+// compareDpkgVersion 比较 Debian 版本号 [epoch:]upstream[-revision]
+func compareDpkgVersion(a, b string) int {
+	epochA, upstreamA, revisionA := splitDpkgVersion(a)
+	epochB, upstreamB, revisionB := splitDpkgVersion(b)
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+	if r := dpkgVerRevCmp(upstreamA, upstreamB); r != 0 {
+		return r
+	}
+	return dpkgVerRevCmp(revisionA, revisionB)
+}
+
+func splitDpkgVersion(v string) (epoch int, upstream, revision string) {
+	v = strings.TrimSpace(v)
+	if idx := strings.Index(v, ":"); idx >= 0 {
+		epoch, _ = strconv.Atoi(v[:idx])
+		v = v[idx+1:]
+	}
+	if idx := strings.LastIndex(v, "-"); idx >= 0 {
+		return epoch, v[:idx], v[idx+1:]
+	}
+	return epoch, v, ""
+}
+
+// dpkgOrder 非数字字符排序权重：~ 最小，其次是字符串结尾，字母小于其他符号
+func dpkgOrder(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// dpkgVerRevCmp 实现 dpkg 的 verrevcmp 算法
+func dpkgVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		// 比较非数字部分
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := 0, 0
+			if i < len(a) {
+				ac = dpkgOrder(a[i])
+			}
+			if j < len(b) {
+				bc = dpkgOrder(b[j])
+			}
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+
+		// 跳过前导零后比较数字部分
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+// compareRpmVersion 比较 RPM 版本号 [epoch:]version[-release]
+func compareRpmVersion(a, b string) int {
+	epochA, versionA, releaseA := splitRpmVersion(a)
+	epochB, versionB, releaseB := splitRpmVersion(b)
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+	if r := rpmVerCmp(versionA, versionB); r != 0 {
+		return r
+	}
+	// 任意一方缺少 release 时只比较 version
+	if releaseA == "" || releaseB == "" {
+		return 0
+	}
+	return rpmVerCmp(releaseA, releaseB)
+}
+
+func splitRpmVersion(v string) (epoch int, version, release string) {
+	v = strings.TrimSpace(v)
+	if idx := strings.Index(v, ":"); idx >= 0 {
+		epoch, _ = strconv.Atoi(v[:idx])
+		v = v[idx+1:]
+	}
+	if idx := strings.LastIndex(v, "-"); idx >= 0 {
+		return epoch, v[:idx], v[idx+1:]
+	}
+	return epoch, v, ""
+}
+
+// rpmVerCmp 实现 rpm 的 rpmvercmp 算法（支持 ~ 与 ^）
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		// ~ 排在任何内容之前
+		tildeA := i < len(a) && a[i] == '~'
+		tildeB := j < len(b) && b[j] == '~'
+		if tildeA || tildeB {
+			if !tildeA {
+				return 1
+			}
+			if !tildeB {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// ^ 排在任何内容之前，但在字符串结尾之后
+		caretA := i < len(a) && a[i] == '^'
+		caretB := j < len(b) && b[j] == '^'
+		if caretA || caretB {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if !caretA {
+				return 1
+			}
+			if !caretB {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		startA, startB := i, j
+		numeric := isDigit(a[i])
+		if numeric {
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+		} else {
+			for i < len(a) && isAlpha(a[i]) {
+				i++
+			}
+			for j < len(b) && isAlpha(b[j]) {
+				j++
+			}
+		}
+		segA, segB := a[startA:i], b[startB:j]
+
+		// 类型不同：数字段大于字母段
+		if segB == "" {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				return sign(len(segA) - len(segB))
+			}
+		}
+		if r := strings.Compare(segA, segB); r != 0 {
+			return r
+		}
+	}
+
+	if i >= len(a) && j >= len(b) {
+		return 0
+	}
+	if i >= len(a) {
+		return -1
+	}
+	return 1
+}
+
+// apkPreReleaseSuffixes apk 预发布后缀，排在正式版本之前
+var apkPreReleaseSuffixes = []string{"_alpha", "_beta", "_pre", "_rc"}
+
+// compareApkVersion 比较 Alpine 版本号 version[_suffix][-rN]
+// 将预发布后缀转换为 ~，其他后缀转换为 .，再按 dpkg 规则比较
+func compareApkVersion(a, b string) int {
+	return compareDpkgVersion(normalizeApkVersion(a), normalizeApkVersion(b))
+}
+
+func normalizeApkVersion(v string) string {
+	v = strings.TrimSpace(v)
+	for _, suffix := range apkPreReleaseSuffixes {
+		v = strings.ReplaceAll(v, suffix, "~"+suffix[1:])
+	}
+	return strings.ReplaceAll(v, "_", ".")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
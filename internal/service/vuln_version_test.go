@@ -0,0 +1,104 @@
+package service
+
+import "testing"
+
+type versionCompareCase struct {
+	name string
+	a, b string
+	want int
+}
+
+func runVersionCompareCases(t *testing.T, compare func(a, b string) int, tests []versionCompareCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			// 交换参数结果取反
+			if got := compare(tt.b, tt.a); got != -tt.want {
+				t.Errorf("compare(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareDpkgVersion(t *testing.T) {
+	runVersionCompareCases(t, compareDpkgVersion, []versionCompareCase{
+		{"相同版本", "1.2.3-1", "1.2.3-1", 0},
+		{"epoch 优先", "1:1.0", "2.0", 1},
+		{"缺少 epoch 等于 0", "0:1.0", "1.0", 0},
+		{"数字按数值比较", "1.10", "1.9", 1},
+		{"前导零", "1.01", "1.1", 0},
+		{"~ 早于正式版本", "1.0~rc1", "1.0", -1},
+		{"~ 之间比较", "1.0~rc1", "1.0~rc2", -1},
+		{"双 ~ 更早", "1.0~~", "1.0~", -1},
+		{"字母在结尾之后", "1.0a", "1.0", 1},
+		{"+ 在字母之后", "1.0+b1", "1.0a", 1},
+		{"修订号比较", "2.30-1ubuntu1", "2.30-1", 1},
+		{"修订号中的 ~", "1.0-1~bpo1", "1.0-1", -1},
+		{"缺少修订号等于 0", "1.0", "1.0-0", 0},
+		{"缺少修订号小于修订号 1", "1.0", "1.0-1", -1},
+		{"upstream 中包含 -", "1.0-beta-2", "1.0-beta-1", 1},
+	})
+}
+
+func TestCompareRpmVersion(t *testing.T) {
+	runVersionCompareCases(t, compareRpmVersion, []versionCompareCase{
+		{"相同版本", "1.2.3-1.el8", "1.2.3-1.el8", 0},
+		{"epoch 优先", "1:1.0-1", "2.0-1", 1},
+		{"数字按数值比较", "1.10", "1.9", 1},
+		{"前导零", "1.01", "1.1", 0},
+		{"数字段大于字母段", "1.1", "1.a", 1},
+		{"段数更多的版本更新", "2.0.1", "2.0", 1},
+		{"剩余字母更新", "1.0a", "1.0", 1},
+		{"分隔符不参与比较", "1_0", "1.0", 0},
+		{"~ 早于正式版本", "1.0~rc1", "1.0", -1},
+		{"~ 之间比较", "1.0~rc1", "1.0~rc2", -1},
+		{"^ 晚于正式版本", "1.0^git1", "1.0", 1},
+		{"^ 早于下一个版本", "1.0^git1", "1.0.1", -1},
+		{"~ 后的 ^", "1.0~rc1^git1", "1.0~rc1", 1},
+		{"release 比较", "1.0-2.el8", "1.0-1.el8", 1},
+		{"缺少 release 只比较 version", "1.0", "1.0-3.el8", 0},
+		{"缺少 release 时 version 仍生效", "1.1", "1.0-3.el8", 1},
+	})
+}
+
+func TestCompareApkVersion(t *testing.T) {
+	runVersionCompareCases(t, compareApkVersion, []versionCompareCase{
+		{"相同版本", "1.2.3-r0", "1.2.3-r0", 0},
+		{"数字按数值比较", "1.10.0", "1.9.9", 1},
+		{"前导零", "1.02-r0", "1.2-r0", 0},
+		{"_rc 早于正式版本", "1.2.3_rc1-r0", "1.2.3-r0", -1},
+		{"_rc 之间比较", "1.2.3_rc1", "1.2.3_rc2", -1},
+		{"预发布后缀顺序 alpha < beta", "1.2.3_alpha1", "1.2.3_beta1", -1},
+		{"预发布后缀顺序 beta < pre", "1.2.3_beta1", "1.2.3_pre1", -1},
+		{"预发布后缀顺序 pre < rc", "1.2.3_pre1", "1.2.3_rc1", -1},
+		{"_p 晚于正式版本", "1.2.3_p1", "1.2.3", 1},
+		{"_p 早于下一个版本", "1.2.3_p1", "1.2.4", -1},
+		{"_p 之间比较", "1.2.3_p2", "1.2.3_p10", -1},
+		{"-r 修订号", "1.2.3-r1", "1.2.3-r0", 1},
+		{"预发布版本的修订号", "1.2.3_rc1-r5", "1.2.3-r0", -1},
+	})
+}
+
+func TestCompareVersionsScheme(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme string
+		a, b   string
+		want   int
+	}{
+		{"dpkg", VersionSchemeDpkg, "1.0", "1.0-0", 0},
+		{"rpm 缺少 release", VersionSchemeRpm, "1.0", "1.0-5", 0},
+		{"apk _p 后缀", VersionSchemeApk, "1.0_p1", "1.0", 1},
+		{"未知方式按 dpkg", "", "1.0~rc1", "1.0", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareVersions(tt.scheme, tt.a, tt.b); got != tt.want {
+				t.Errorf("compareVersions(%q, %q, %q) = %d, want %d", tt.scheme, tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
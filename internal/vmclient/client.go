@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -34,7 +35,8 @@ type ResultData struct {
 // Result 单个时间序列结果
 type Result struct {
 	Metric map[string]string `json:"metric"`
-	Values [][]interface{}   `json:"values"` // [[timestamp, value], ...]
+	Values [][]interface{}   `json:"values"`          // [[timestamp, value], ...]
+	Value  []interface{}     `json:"value,omitempty"` // 即时查询结果 [timestamp, value]
 }
 
 // DataPoint 数据点
@@ -257,6 +259,43 @@ func (c *VMClient) DeleteSeries(ctx context.Context, matchers []string) error {
 	return nil
 }
 
+// ConvertVectorToDataPoints 将即时查询的 vector 结果转换为数据点列表（每个序列一个数据点）
+func ConvertVectorToDataPoints(result *QueryResult) []DataPoint {
+	if result == nil || len(result.Data.Result) == 0 {
+		return []DataPoint{}
+	}
+
+	points := make([]DataPoint, 0, len(result.Data.Result))
+	for _, r := range result.Data.Result {
+		if len(r.Value) < 2 {
+			continue
+		}
+
+		timestamp, ok := r.Value[0].(float64)
+		if !ok {
+			continue
+		}
+
+		valueStr, ok := r.Value[1].(string)
+		if !ok {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			continue
+		}
+
+		points = append(points, DataPoint{
+			Timestamp: int64(timestamp * 1000),
+			Value:     value,
+			Labels:    r.Metric,
+		})
+	}
+
+	return points
+}
+
 // ConvertToDataPoints 将查询结果转换为数据点列表
 func ConvertToDataPoints(result *QueryResult) []DataPoint {
 	if result == nil || len(result.Data.Result) == 0 {
@@ -29,6 +29,7 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		service.NewGitHubOAuthService,
 		service.NewApiKeyService,
 		service.NewAlertService,
+		service.NewAlertSilenceService,
 		service.NewPropertyService,
 		service.NewNotificationService,
 		service.NewMonitorService,
@@ -39,6 +40,9 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		service.NewDDNSService,
 		service.NewSSHLoginService,
 		service.NewPublicIPService,
+		service.NewAuditRuleService,
+		service.NewAuditScheduleService,
+		service.NewVulnerabilityService,
 
 		service.NewNotifier,
 		// WebSocket Manager
@@ -47,6 +51,7 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		// Handlers
 		handler.NewAgentHandler,
 		handler.NewAlertHandler,
+		handler.NewAlertSilenceHandler,
 		handler.NewPropertyHandler,
 		handler.NewMonitorHandler,
 		handler.NewApiKeyHandler,
@@ -55,6 +60,9 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		handler.NewDNSProviderHandler,
 		handler.NewDDNSHandler,
 		handler.NewSSHLoginHandler,
+		handler.NewAuditRuleHandler,
+		handler.NewAuditScheduleHandler,
+		handler.NewVulnerabilityHandler,
 
 		// App Components
 		wire.Struct(new(AppComponents), "*"),
@@ -64,28 +72,36 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 
 // AppComponents 应用组件
 type AppComponents struct {
-	AccountHandler     *handler.AccountHandler
-	AgentHandler       *handler.AgentHandler
-	ApiKeyHandler      *handler.ApiKeyHandler
-	AlertHandler       *handler.AlertHandler
-	PropertyHandler    *handler.PropertyHandler
-	MonitorHandler     *handler.MonitorHandler
-	TamperHandler      *handler.TamperHandler
-	DNSProviderHandler *handler.DNSProviderHandler
-	DDNSHandler        *handler.DDNSHandler
-	SSHLoginHandler    *handler.SSHLoginHandler
-
-	AgentService    *service.AgentService
-	TrafficService  *service.TrafficService
-	MetricService   *service.MetricService
-	AlertService    *service.AlertService
-	PropertyService *service.PropertyService
-	MonitorService  *service.MonitorService
-	ApiKeyService   *service.ApiKeyService
-	TamperService   *service.TamperService
-	DDNSService     *service.DDNSService
-	SSHLoginService *service.SSHLoginService
-	PublicIPService *service.PublicIPService
+	AccountHandler       *handler.AccountHandler
+	AgentHandler         *handler.AgentHandler
+	ApiKeyHandler        *handler.ApiKeyHandler
+	AlertHandler         *handler.AlertHandler
+	AlertSilenceHandler  *handler.AlertSilenceHandler
+	PropertyHandler      *handler.PropertyHandler
+	MonitorHandler       *handler.MonitorHandler
+	TamperHandler        *handler.TamperHandler
+	DNSProviderHandler   *handler.DNSProviderHandler
+	DDNSHandler          *handler.DDNSHandler
+	SSHLoginHandler      *handler.SSHLoginHandler
+	AuditRuleHandler     *handler.AuditRuleHandler
+	AuditScheduleHandler *handler.AuditScheduleHandler
+	VulnerabilityHandler *handler.VulnerabilityHandler
+
+	AgentService         *service.AgentService
+	TrafficService       *service.TrafficService
+	MetricService        *service.MetricService
+	AlertService         *service.AlertService
+	PropertyService      *service.PropertyService
+	MonitorService       *service.MonitorService
+	ApiKeyService        *service.ApiKeyService
+	TamperService        *service.TamperService
+	DDNSService          *service.DDNSService
+	SSHLoginService      *service.SSHLoginService
+	PublicIPService      *service.PublicIPService
+	AuditRuleService     *service.AuditRuleService
+	AuditScheduleService *service.AuditScheduleService
+	VulnerabilityService *service.VulnerabilityService
+	Notifier             *service.Notifier
 
 	WSManager *websocket.Manager
 	VMClient  *vmclient.VMClient
@@ -29,7 +29,8 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	apiKeyService := service.NewApiKeyService(logger, db)
 	propertyService := service.NewPropertyService(logger, db)
 	notifier := service.NewNotifier(logger)
-	notificationService := service.NewNotificationService(logger, propertyService, notifier)
+	alertSilenceService := service.NewAlertSilenceService(logger, db)
+	notificationService := service.NewNotificationService(logger, propertyService, notifier, alertSilenceService)
 	trafficService := service.NewTrafficService(logger, db, notificationService)
 	vmClient := provideVMClient(cfg, logger)
 	metricService := service.NewMetricService(logger, db, propertyService, trafficService, vmClient)
@@ -37,8 +38,11 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	if err != nil {
 		return nil, err
 	}
-	agentService := service.NewAgentService(logger, db, apiKeyService, metricService, geoIPService)
+	auditRuleService := service.NewAuditRuleService(logger, db, cfg)
 	manager := websocket.NewManager(logger)
+	auditScheduleService := service.NewAuditScheduleService(logger, db, propertyService, manager)
+	vulnerabilityService := service.NewVulnerabilityService(logger, db)
+	agentService := service.NewAgentService(logger, db, apiKeyService, metricService, geoIPService, auditRuleService, notificationService, auditScheduleService)
 	monitorService := service.NewMonitorService(logger, db, metricService, manager)
 	tamperService := service.NewTamperService(logger, db, manager, notificationService)
 	ddnsService := service.NewDDNSService(logger, db, propertyService, manager)
@@ -46,38 +50,50 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	publicIPService := service.NewPublicIPService(logger, propertyService, manager)
 	agentHandler := handler.NewAgentHandler(logger, agentService, trafficService, metricService, monitorService, tamperService, ddnsService, sshLoginService, apiKeyService, propertyService, manager)
 	apiKeyHandler := handler.NewApiKeyHandler(logger, apiKeyService)
-	alertService := service.NewAlertService(logger, db, propertyService, monitorService, notifier)
+	alertService := service.NewAlertService(logger, db, propertyService, monitorService, notifier, vmClient, alertSilenceService)
 	alertHandler := handler.NewAlertHandler(logger, alertService)
+	alertSilenceHandler := handler.NewAlertSilenceHandler(logger, alertSilenceService)
 	propertyHandler := handler.NewPropertyHandler(logger, propertyService, notifier)
 	monitorHandler := handler.NewMonitorHandler(logger, monitorService, metricService, agentService)
 	tamperHandler := handler.NewTamperHandler(logger, tamperService)
 	dnsProviderHandler := handler.NewDNSProviderHandler(logger, propertyService)
 	ddnsHandler := handler.NewDDNSHandler(logger, ddnsService)
 	sshLoginHandler := handler.NewSSHLoginHandler(logger, sshLoginService)
+	auditRuleHandler := handler.NewAuditRuleHandler(logger, auditRuleService)
+	auditScheduleHandler := handler.NewAuditScheduleHandler(logger, auditScheduleService)
+	vulnerabilityHandler := handler.NewVulnerabilityHandler(logger, vulnerabilityService)
 	appComponents := &AppComponents{
-		AccountHandler:     accountHandler,
-		AgentHandler:       agentHandler,
-		ApiKeyHandler:      apiKeyHandler,
-		AlertHandler:       alertHandler,
-		PropertyHandler:    propertyHandler,
-		MonitorHandler:     monitorHandler,
-		TamperHandler:      tamperHandler,
-		DNSProviderHandler: dnsProviderHandler,
-		DDNSHandler:        ddnsHandler,
-		SSHLoginHandler:    sshLoginHandler,
-		AgentService:       agentService,
-		TrafficService:     trafficService,
-		MetricService:      metricService,
-		AlertService:       alertService,
-		PropertyService:    propertyService,
-		MonitorService:     monitorService,
-		ApiKeyService:      apiKeyService,
-		TamperService:      tamperService,
-		DDNSService:        ddnsService,
-		SSHLoginService:    sshLoginService,
-		PublicIPService:    publicIPService,
-		WSManager:          manager,
-		VMClient:           vmClient,
+		AccountHandler:       accountHandler,
+		AgentHandler:         agentHandler,
+		ApiKeyHandler:        apiKeyHandler,
+		AlertHandler:         alertHandler,
+		AlertSilenceHandler:  alertSilenceHandler,
+		PropertyHandler:      propertyHandler,
+		MonitorHandler:       monitorHandler,
+		TamperHandler:        tamperHandler,
+		DNSProviderHandler:   dnsProviderHandler,
+		DDNSHandler:          ddnsHandler,
+		SSHLoginHandler:      sshLoginHandler,
+		AuditRuleHandler:     auditRuleHandler,
+		AuditScheduleHandler: auditScheduleHandler,
+		VulnerabilityHandler: vulnerabilityHandler,
+		AgentService:         agentService,
+		TrafficService:       trafficService,
+		MetricService:        metricService,
+		AlertService:         alertService,
+		PropertyService:      propertyService,
+		MonitorService:       monitorService,
+		ApiKeyService:        apiKeyService,
+		TamperService:        tamperService,
+		DDNSService:          ddnsService,
+		SSHLoginService:      sshLoginService,
+		PublicIPService:      publicIPService,
+		AuditRuleService:     auditRuleService,
+		AuditScheduleService: auditScheduleService,
+		VulnerabilityService: vulnerabilityService,
+		Notifier:             notifier,
+		WSManager:            manager,
+		VMClient:             vmClient,
 	}
 	return appComponents, nil
 }
@@ -86,28 +102,36 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 
 // AppComponents 应用组件
 type AppComponents struct {
-	AccountHandler     *handler.AccountHandler
-	AgentHandler       *handler.AgentHandler
-	ApiKeyHandler      *handler.ApiKeyHandler
-	AlertHandler       *handler.AlertHandler
-	PropertyHandler    *handler.PropertyHandler
-	MonitorHandler     *handler.MonitorHandler
-	TamperHandler      *handler.TamperHandler
-	DNSProviderHandler *handler.DNSProviderHandler
-	DDNSHandler        *handler.DDNSHandler
-	SSHLoginHandler    *handler.SSHLoginHandler
-
-	AgentService    *service.AgentService
-	TrafficService  *service.TrafficService
-	MetricService   *service.MetricService
-	AlertService    *service.AlertService
-	PropertyService *service.PropertyService
-	MonitorService  *service.MonitorService
-	ApiKeyService   *service.ApiKeyService
-	TamperService   *service.TamperService
-	DDNSService     *service.DDNSService
-	SSHLoginService *service.SSHLoginService
-	PublicIPService *service.PublicIPService
+	AccountHandler       *handler.AccountHandler
+	AgentHandler         *handler.AgentHandler
+	ApiKeyHandler        *handler.ApiKeyHandler
+	AlertHandler         *handler.AlertHandler
+	AlertSilenceHandler  *handler.AlertSilenceHandler
+	PropertyHandler      *handler.PropertyHandler
+	MonitorHandler       *handler.MonitorHandler
+	TamperHandler        *handler.TamperHandler
+	DNSProviderHandler   *handler.DNSProviderHandler
+	DDNSHandler          *handler.DDNSHandler
+	SSHLoginHandler      *handler.SSHLoginHandler
+	AuditRuleHandler     *handler.AuditRuleHandler
+	AuditScheduleHandler *handler.AuditScheduleHandler
+	VulnerabilityHandler *handler.VulnerabilityHandler
+
+	AgentService         *service.AgentService
+	TrafficService       *service.TrafficService
+	MetricService        *service.MetricService
+	AlertService         *service.AlertService
+	PropertyService      *service.PropertyService
+	MonitorService       *service.MonitorService
+	ApiKeyService        *service.ApiKeyService
+	TamperService        *service.TamperService
+	DDNSService          *service.DDNSService
+	SSHLoginService      *service.SSHLoginService
+	PublicIPService      *service.PublicIPService
+	AuditRuleService     *service.AuditRuleService
+	AuditScheduleService *service.AuditScheduleService
+	VulnerabilityService *service.VulnerabilityService
+	Notifier             *service.Notifier
 
 	WSManager *websocket.Manager
 	VMClient  *vmclient.VMClient
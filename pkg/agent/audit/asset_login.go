@@ -243,7 +243,7 @@ func (lac *LoginAssetsCollector) collectFailedLoginsFromAuthLog() []protocol.Log
 	}
 
 	for i := len(matchedLines) - 1; i >= startIdx; i-- {
-		record := lac.parseFailedLoginFromLog(matchedLines[i])
+		record := ParseFailedLoginLine(matchedLines[i])
 		if record != nil {
 			records = append(records, *record)
 		}
@@ -252,8 +252,8 @@ func (lac *LoginAssetsCollector) collectFailedLoginsFromAuthLog() []protocol.Log
 	return records
 }
 
-// parseFailedLoginFromLog 从日志行解析失败登录
-func (lac *LoginAssetsCollector) parseFailedLoginFromLog(line string) *protocol.LoginRecord {
+// ParseFailedLoginLine 从认证日志行解析失败登录（SSH 登录监控实时跟踪日志时复用）
+func ParseFailedLoginLine(line string) *protocol.LoginRecord {
 	// 简化解析，提取用户名和IP
 	username := "unknown"
 	ip := "unknown"
@@ -311,7 +311,7 @@ func (lac *LoginAssetsCollector) parseFailedLoginFromLog(line string) *protocol.
 	}
 
 	// 尝试解析日志时间
-	timestamp := lac.parseSyslogTime(line)
+	timestamp := ParseSyslogTime(line)
 
 	return &protocol.LoginRecord{
 		Username:  username,
@@ -322,8 +322,8 @@ func (lac *LoginAssetsCollector) parseFailedLoginFromLog(line string) *protocol.
 	}
 }
 
-// parseSyslogTime 解析syslog时间格式
-func (lac *LoginAssetsCollector) parseSyslogTime(line string) int64 {
+// ParseSyslogTime 解析syslog时间格式
+func ParseSyslogTime(line string) int64 {
 	fields := strings.Fields(line)
 	if len(fields) < 1 {
 		return time.Now().UnixMilli()
@@ -339,6 +339,7 @@ func (lac *LoginAssetsCollector) parseSyslogTime(line string) int64 {
 			"2006-01-02T15:04:05.000000-07:00", // 带微秒
 			"2006-01-02T15:04:05.000000+08:00", // 带微秒和时区
 			"2006-01-02T15:04:05-07:00",        // 不带微秒
+			"2006-01-02T15:04:05-0700",         // journalctl short-iso（较旧版本）
 		}
 		for _, format := range isoFormats {
 			if t, err := time.Parse(format, fields[0]); err == nil {
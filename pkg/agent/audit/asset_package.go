@@ -0,0 +1,245 @@
+package audit
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+const (
+	dpkgStatusPath   = "/var/lib/dpkg/status"
+	apkInstalledPath = "/lib/apk/db/installed"
+	osReleasePath    = "/etc/os-release"
+)
+
+// PackageAssetsCollector 软件包资产收集器
+type PackageAssetsCollector struct {
+	config   *Config
+	executor *CommandExecutor
+}
+
+// NewPackageAssetsCollector 创建软件包资产收集器
+func NewPackageAssetsCollector(config *Config, executor *CommandExecutor) *PackageAssetsCollector {
+	return &PackageAssetsCollector{
+		config:   config,
+		executor: executor,
+	}
+}
+
+// Collect 收集软件包资产
+func (pac *PackageAssetsCollector) Collect() *protocol.PackageAssets {
+	assets := &protocol.PackageAssets{}
+	pac.collectOSRelease(assets)
+
+	// 按包管理器依次尝试，命中一个即可（漏洞匹配需要完整列表，不做数量限制）
+	if _, err := os.Stat(dpkgStatusPath); err == nil {
+		assets.Manager = "dpkg"
+		assets.Packages = pac.collectDpkgPackages()
+	} else if _, err := os.Stat(apkInstalledPath); err == nil {
+		assets.Manager = "apk"
+		assets.Packages = pac.collectApkPackages()
+	} else if _, err := exec.LookPath("rpm"); err == nil {
+		assets.Manager = "rpm"
+		assets.Packages = pac.collectRpmPackages()
+	} else {
+		globalLogger.Debug("未检测到支持的包管理器")
+		return nil
+	}
+
+	assets.Total = len(assets.Packages)
+	return assets
+}
+
+// collectOSRelease 读取发行版信息
+func (pac *PackageAssetsCollector) collectOSRelease(assets *protocol.PackageAssets) {
+	file, err := os.Open(osReleasePath)
+	if err != nil {
+		globalLogger.Warn("读取%s失败: %v", osReleasePath, err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			assets.Distro = value
+		case "VERSION_ID":
+			assets.DistroVersion = value
+		case "VERSION_CODENAME":
+			assets.Codename = value
+		}
+	}
+}
+
+// collectDpkgPackages 解析 dpkg 状态数据库
+func (pac *PackageAssetsCollector) collectDpkgPackages() []protocol.PackageInfo {
+	var packages []protocol.PackageInfo
+
+	file, err := os.Open(dpkgStatusPath)
+	if err != nil {
+		globalLogger.Warn("读取%s失败: %v", dpkgStatusPath, err)
+		return packages
+	}
+	defer file.Close()
+
+	var current protocol.PackageInfo
+	var status string
+	flush := func() {
+		// 只保留已安装的包，忽略 deinstall/config-files 等状态
+		if current.Name != "" && current.Version != "" && strings.HasSuffix(status, " installed") {
+			packages = append(packages, current)
+		}
+		current = protocol.PackageInfo{}
+		status = ""
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		// 续行（如 Description 多行内容）
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Package":
+			current.Name = value
+		case "Status":
+			status = value
+		case "Version":
+			current.Version = value
+		case "Architecture":
+			current.Arch = value
+		case "Source":
+			// Source 字段可能带版本，如 "openssl (3.0.11-1)"
+			if idx := strings.Index(value, " ("); idx > 0 {
+				value = value[:idx]
+			}
+			current.Source = value
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		globalLogger.Warn("解析%s失败: %v", dpkgStatusPath, err)
+	}
+
+	return packages
+}
+
+// collectApkPackages 解析 apk 已安装数据库
+func (pac *PackageAssetsCollector) collectApkPackages() []protocol.PackageInfo {
+	var packages []protocol.PackageInfo
+
+	file, err := os.Open(apkInstalledPath)
+	if err != nil {
+		globalLogger.Warn("读取%s失败: %v", apkInstalledPath, err)
+		return packages
+	}
+	defer file.Close()
+
+	var current protocol.PackageInfo
+	flush := func() {
+		if current.Name != "" && current.Version != "" {
+			packages = append(packages, current)
+		}
+		current = protocol.PackageInfo{}
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+		value := line[2:]
+		switch line[0] {
+		case 'P':
+			current.Name = value
+		case 'V':
+			current.Version = value
+		case 'A':
+			current.Arch = value
+		case 'o':
+			current.Source = value
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		globalLogger.Warn("解析%s失败: %v", apkInstalledPath, err)
+	}
+
+	return packages
+}
+
+// collectRpmPackages 通过 rpm 命令查询已安装软件包
+func (pac *PackageAssetsCollector) collectRpmPackages() []protocol.PackageInfo {
+	var packages []protocol.PackageInfo
+
+	output, err := pac.executor.Execute("rpm", "-qa", "--queryformat",
+		"%{NAME}\t%{EPOCH}\t%{VERSION}\t%{RELEASE}\t%{ARCH}\t%{SOURCERPM}\n")
+	if err != nil {
+		globalLogger.Warn("查询rpm数据库失败: %v", err)
+		return packages
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 6 || fields[0] == "" || fields[0] == "gpg-pubkey" {
+			continue
+		}
+
+		version := fields[2] + "-" + fields[3]
+		if epoch := fields[1]; epoch != "" && epoch != "(none)" && epoch != "0" {
+			version = epoch + ":" + version
+		}
+
+		packages = append(packages, protocol.PackageInfo{
+			Name:    fields[0],
+			Version: version,
+			Arch:    fields[4],
+			Source:  rpmSourceName(fields[5]),
+		})
+	}
+
+	return packages
+}
+
+// rpmSourceName 从 SOURCERPM (name-version-release.src.rpm) 中提取源码包名
+func rpmSourceName(sourceRPM string) string {
+	name := strings.TrimSuffix(sourceRPM, ".src.rpm")
+	if name == sourceRPM || name == "(none)" {
+		return ""
+	}
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndex(name, "-")
+		if idx <= 0 {
+			return ""
+		}
+		name = name[:idx]
+	}
+	return name
+}
@@ -31,6 +31,7 @@ type Auditor struct {
 	fileAssetsCollector    *FileAssetsCollector
 	kernelAssetsCollector  *KernelAssetsCollector
 	loginAssetsCollector   *LoginAssetsCollector
+	packageAssetsCollector *PackageAssetsCollector
 }
 
 // NewAuditor 创建审计器
@@ -55,6 +56,7 @@ func NewAuditor(config *Config) *Auditor {
 		fileAssetsCollector:    NewFileAssetsCollector(config, executor),
 		kernelAssetsCollector:  NewKernelAssetsCollector(config, executor),
 		loginAssetsCollector:   NewLoginAssetsCollector(config, executor),
+		packageAssetsCollector: NewPackageAssetsCollector(config, executor),
 	}
 }
 
@@ -142,6 +144,9 @@ func (a *Auditor) collectAssets() *protocol.AssetInventory {
 		{"登录资产", func() {
 			inventory.LoginAssets = a.loginAssetsCollector.Collect()
 		}},
+		{"软件包资产", func() {
+			inventory.PackageAssets = a.packageAssetsCollector.Collect()
+		}},
 	}
 
 	// 并发执行
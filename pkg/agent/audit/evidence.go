@@ -64,6 +64,11 @@ func (ec *EvidenceCollector) CollectFileEvidence(filePath string, riskLevel stri
 	}
 }
 
+// BuildProcessTree 构建进程树（供防篡改事件进程归因复用）
+func (ec *EvidenceCollector) BuildProcessTree(p *process.Process) []string {
+	return ec.buildProcessTree(p)
+}
+
 // buildProcessTree 构建进程树
 func (ec *EvidenceCollector) buildProcessTree(p *process.Process) []string {
 	var tree []string
@@ -88,12 +88,16 @@ type Agent struct {
 
 // New 创建 Agent 实例
 func New(cfg *config.Config) *Agent {
+	tamperProtector := tamper.NewProtector()
+	// 防篡改事件进程归因，复用审计模块的进程树构建
+	tamperProtector.EnableAttribution(audit.NewEvidenceCollector().BuildProcessTree)
+
 	return &Agent{
 		cfg:              cfg,
 		idMgr:            id.NewManager(),
 		collectorManager: collector.NewManager(cfg),
 		outboundBuffer:   newOutboundBuffer(),
-		tamperProtector:  tamper.NewProtector(),
+		tamperProtector:  tamperProtector,
 		sshMonitor:       sshmonitor.NewMonitor(),
 	}
 }
@@ -315,6 +319,8 @@ func (a *Agent) readLoop(conn *websocket.Conn, done chan struct{}) error {
 			go a.handlePublicIPConfig(msg.Data)
 		case protocol.MessageTypeSSHLoginConfig:
 			go a.handleSSHLoginConfig(msg.Data)
+		case protocol.MessageTypeSSHBan:
+			go a.handleSSHBan(msg.Data)
 		case protocol.MessageTypeUninstall:
 			go a.handleUninstall()
 		default:
@@ -760,7 +766,7 @@ func (a *Agent) handleTamperProtect(data json.RawMessage) {
 		return
 	}
 
-	slog.Info("收到防篡改保护增量配置", "added", tamperProtectConfig.Added, "removed", tamperProtectConfig.Removed)
+	slog.Info("收到防篡改保护增量配置", "added", tamperProtectConfig.Added, "removed", tamperProtectConfig.Removed, "mode", tamperProtectConfig.Mode)
 
 	conn := a.getActiveConn()
 	if conn == nil {
@@ -768,10 +774,26 @@ func (a *Agent) handleTamperProtect(data json.RawMessage) {
 		return
 	}
 
-	// 如果没有新增也没有移除，不需要做任何操作
+	// 先应用保护模式，模式变化时会对已保护的目录进行切换
+	if err := a.tamperProtector.SetOptions(tamper.Options{
+		Mode:         tamperProtectConfig.Mode,
+		HashInterval: time.Duration(tamperProtectConfig.HashInterval) * time.Second,
+	}); err != nil {
+		slog.Warn("应用防篡改保护模式失败", "error", err)
+		a.sendTamperProtectResponse(false, fmt.Sprintf("应用保护模式失败: %v", err), a.tamperProtector.GetProtectedPaths(), nil, nil)
+		return
+	}
+
+	// 如果没有新增也没有移除，仅应用强制恢复策略和快照刷新
 	if len(tamperProtectConfig.Added) == 0 && len(tamperProtectConfig.Removed) == 0 {
-		slog.Info("配置无变化，跳过更新")
-		a.sendTamperProtectResponse(true, "", a.tamperProtector.GetProtectedPaths(), []string{}, []string{})
+		slog.Info("保护目录无变化，跳过增量更新")
+		message, err := a.applyTamperPolicies(tamperProtectConfig)
+		if err != nil {
+			slog.Warn("应用防篡改强制恢复策略失败", "error", err)
+			a.sendTamperProtectResponse(false, err.Error(), a.tamperProtector.GetProtectedPaths(), []string{}, []string{})
+			return
+		}
+		a.sendTamperProtectResponse(true, message, a.tamperProtector.GetProtectedPaths(), []string{}, []string{})
 		return
 	}
 
@@ -794,9 +816,48 @@ func (a *Agent) handleTamperProtect(data json.RawMessage) {
 	message := fmt.Sprintf("防篡改保护已更新: 新增 %d 个, 移除 %d 个, 当前保护 %d 个目录",
 		len(result.Added), len(result.Removed), len(result.Current))
 	slog.Info(message)
+
+	policyMessage, err := a.applyTamperPolicies(tamperProtectConfig)
+	if err != nil {
+		slog.Warn("应用防篡改强制恢复策略失败", "error", err)
+		a.sendTamperProtectResponse(false, fmt.Sprintf("%s; %v", message, err), result.Current, result.Added, result.Removed)
+		return
+	}
+	if policyMessage != "" {
+		message = fmt.Sprintf("%s; %s", message, policyMessage)
+	}
 	a.sendTamperProtectResponse(true, message, result.Current, result.Added, result.Removed)
 }
 
+// applyTamperPolicies 应用强制恢复策略和临时解锁窗口，并按需刷新快照
+func (a *Agent) applyTamperPolicies(config protocol.TamperProtectConfig) (string, error) {
+	if err := a.tamperProtector.SetEnforcePaths(config.Enforce); err != nil {
+		return "", fmt.Errorf("应用强制恢复策略失败: %w", err)
+	}
+
+	var messages []string
+	for _, unlock := range config.Unlock {
+		if err := a.tamperProtector.Unlock(unlock.Path, time.Duration(unlock.Duration)*time.Second); err != nil {
+			return "", fmt.Errorf("临时解锁失败: %w", err)
+		}
+		if unlock.Duration > 0 {
+			messages = append(messages, fmt.Sprintf("已临时解锁 %s %d 秒", unlock.Path, unlock.Duration))
+		} else {
+			messages = append(messages, fmt.Sprintf("已重新保护 %s", unlock.Path))
+		}
+	}
+
+	if len(config.RefreshSnapshot) > 0 {
+		refreshed, err := a.tamperProtector.RefreshSnapshots(config.RefreshSnapshot)
+		if err != nil {
+			return "", fmt.Errorf("刷新快照失败: %w", err)
+		}
+		slog.Info("已刷新防篡改快照", "paths", refreshed)
+		messages = append(messages, fmt.Sprintf("已刷新快照 %d 个", len(refreshed)))
+	}
+	return strings.Join(messages, "; "), nil
+}
+
 // sendTamperProtectResponse 发送防篡改保护响应
 func (a *Agent) sendTamperProtectResponse(success bool, message string, paths []string, added []string, removed []string) {
 	resp := protocol.TamperProtectResponse{
@@ -833,6 +894,20 @@ func (a *Agent) tamperEventLoop(ctx context.Context, done chan struct{}) {
 				Operation: event.Operation,
 				Timestamp: event.Timestamp.UnixMilli(),
 				Details:   event.Details,
+				OldHash:   event.OldHash,
+				NewHash:   event.NewHash,
+				Restored:  event.Restored,
+			}
+			if event.Process != nil {
+				eventData.Process = &protocol.TamperProcess{
+					PID:         event.Process.PID,
+					PPID:        event.Process.PPID,
+					UID:         event.Process.UID,
+					Username:    event.Process.Username,
+					Exe:         event.Process.Exe,
+					Cmdline:     event.Process.Cmdline,
+					ProcessTree: event.Process.ProcessTree,
+				}
 			}
 
 			buffered, err := a.sendOutboundMessage(protocol.OutboundMessage{
@@ -1117,9 +1192,50 @@ func (a *Agent) sendSSHLoginConfigResult(success bool, enabled bool, message str
 	}
 }
 
+// handleSSHBan 处理服务端下发的封禁/解封指令
+func (a *Agent) handleSSHBan(data json.RawMessage) {
+	var req protocol.SSHBanRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		slog.Warn("解析SSH封禁指令失败", "error", err)
+		return
+	}
+
+	var err error
+	message := ""
+	switch req.Action {
+	case "ban":
+		err = a.sshMonitor.Ban(req.CIDR, time.Duration(req.Duration)*time.Second)
+		message = "封禁已生效"
+	case "unban":
+		err = a.sshMonitor.Unban(req.CIDR)
+		message = "封禁已解除"
+	default:
+		err = fmt.Errorf("未知的封禁操作: %s", req.Action)
+	}
+	if err != nil {
+		slog.Warn("执行SSH封禁指令失败", "action", req.Action, "cidr", req.CIDR, "error", err)
+		message = err.Error()
+	}
+
+	result := protocol.SSHBanResult{
+		ID:      req.ID,
+		Action:  req.Action,
+		CIDR:    req.CIDR,
+		Success: err == nil,
+		Message: message,
+	}
+	if _, err := a.sendOutboundMessage(protocol.OutboundMessage{
+		Type: protocol.MessageTypeSSHBanResult,
+		Data: result,
+	}); err != nil {
+		slog.Warn("发送SSH封禁结果失败", "error", err)
+	}
+}
+
 // sshLoginEventLoop SSH登录事件监控循环
 func (a *Agent) sshLoginEventLoop(ctx context.Context, done chan struct{}) {
 	eventCh := a.sshMonitor.GetEvents()
+	sudoCh := a.sshMonitor.GetSudoEvents()
 
 	for {
 		select {
@@ -1140,6 +1256,13 @@ func (a *Agent) sshLoginEventLoop(ctx context.Context, done chan struct{}) {
 			} else {
 				slog.Info("已上报SSH登录事件", "user", event.Username, "ip", event.IP, "status", event.Status)
 			}
+		case event := <-sudoCh:
+			if _, err := a.sendOutboundMessage(protocol.OutboundMessage{
+				Type: protocol.MessageTypeSSHSudoEvent,
+				Data: event,
+			}); err != nil {
+				slog.Warn("发送sudo提权事件失败", "error", err)
+			}
 		}
 	}
 }
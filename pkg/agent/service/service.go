@@ -352,6 +352,7 @@ func UninstallAgent(cfgPath string) error {
 	if err := monitor.Stop(); err != nil {
 		slog.Warn("清理SSH监控配置失败", "error", err)
 	}
+	monitor.ClearBans()
 
 	// 删除配置文件
 	if err := os.Remove(cfgPath); err != nil {
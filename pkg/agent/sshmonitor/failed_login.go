@@ -0,0 +1,199 @@
+package sshmonitor
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/pkg/agent/audit"
+)
+
+const (
+	// authLogPollInterval 认证日志轮询间隔
+	authLogPollInterval = time.Second
+	// journalRetryInterval journalctl 异常退出后的重启间隔
+	journalRetryInterval = 10 * time.Second
+	// maxRepeatedEvents rsyslog 合并的重复记录最多展开的事件数
+	maxRepeatedEvents = 100
+)
+
+// authLogPaths 认证日志候选路径，与审计模块读取失败登录的顺序一致
+var authLogPaths = []string{
+	"/var/log/auth.log", // Debian/Ubuntu
+	"/var/log/secure",   // RHEL/CentOS/Fedora
+	"/var/log/messages", // 某些系统的备用位置
+}
+
+var (
+	portPattern     = regexp.MustCompile(`\bport (\d+)`)
+	repeatedPattern = regexp.MustCompile(`message repeated (\d+) times`)
+)
+
+// watchFailedLogins 跟踪认证日志中的 SSH 登录失败和 sudo 记录，没有日志文件时使用 journald
+func (m *Monitor) watchFailedLogins(ctx context.Context) {
+	for _, path := range authLogPaths {
+		if _, err := os.Stat(path); err == nil {
+			slog.Info("开始跟踪认证日志中的SSH登录失败记录", "path", path)
+			m.tailAuthLog(ctx, path)
+			return
+		}
+	}
+
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		slog.Warn("未找到认证日志或 journalctl，无法监控SSH登录失败")
+		return
+	}
+	slog.Info("开始通过 journald 跟踪SSH登录失败记录")
+	for {
+		err := m.followJournal(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		slog.Warn("跟踪 journald 中断，稍后重试", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(journalRetryInterval):
+		}
+	}
+}
+
+// tailAuthLog 从文件末尾开始跟踪认证日志，日志轮转或截断后从新文件开头继续读取
+func (m *Monitor) tailAuthLog(ctx context.Context, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		slog.Warn("打开认证日志失败", "path", path, "error", err)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	// 只处理监控启动后新增的记录
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		slog.Warn("定位认证日志末尾失败", "path", path, "error", err)
+		return
+	}
+	reader := bufio.NewReader(file)
+	partial := ""
+
+	ticker := time.NewTicker(authLogPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			line, err := reader.ReadString('\n')
+			offset += int64(len(line))
+			if err != nil {
+				// 不完整的行留到下次读取
+				partial += line
+				break
+			}
+			m.handleAuthLogLine(partial + line)
+			partial = ""
+		}
+
+		current, err := os.Stat(path)
+		if err != nil {
+			// 轮转过程中文件可能暂时不存在
+			continue
+		}
+		opened, err := file.Stat()
+		if err != nil || (os.SameFile(current, opened) && current.Size() >= offset) {
+			continue
+		}
+
+		reopened, err := os.Open(path)
+		if err != nil {
+			slog.Warn("重新打开认证日志失败", "path", path, "error", err)
+			continue
+		}
+		_ = file.Close()
+		file = reopened
+		reader.Reset(file)
+		offset = 0
+		partial = ""
+		slog.Info("认证日志已轮转，重新打开", "path", path)
+	}
+}
+
+// followJournal 通过 journalctl 跟踪 sshd 和 sudo 日志，直到命令退出或 ctx 取消
+func (m *Monitor) followJournal(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "journalctl", "-f", "-n", "0", "-o", "short-iso", "-t", "sshd", "-t", "sshd-session", "-t", "sudo")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		m.handleAuthLogLine(scanner.Text())
+	}
+	return cmd.Wait()
+}
+
+// handleAuthLogLine 解析 sshd 的登录失败记录并生成失败事件，sudo 记录交由 handleSudoLine 处理
+func (m *Monitor) handleAuthLogLine(line string) {
+	if strings.Contains(line, "sudo") && strings.Contains(line, "COMMAND=") {
+		m.handleSudoLine(line)
+		return
+	}
+	// 与审计模块一致，只匹配信息最完整的 Failed password，避免同一次尝试重复计数
+	if !strings.Contains(line, "sshd") || !strings.Contains(line, "Failed password") {
+		return
+	}
+
+	record := audit.ParseFailedLoginLine(line)
+	if record == nil || record.IP == "unknown" {
+		return
+	}
+
+	event := protocol.SSHLoginEvent{
+		Username:  record.Username,
+		IP:        record.IP,
+		Timestamp: record.Timestamp,
+		Status:    "failed",
+	}
+	if match := portPattern.FindStringSubmatch(line); match != nil {
+		event.Port = match[1]
+	}
+
+	// rsyslog 会把连续相同的记录合并为 "message repeated N times: [ ... ]"
+	count := 1
+	if match := repeatedPattern.FindStringSubmatch(line); match != nil {
+		if n, err := strconv.Atoi(match[1]); err == nil && n > 1 {
+			count = min(n, maxRepeatedEvents)
+		}
+	}
+
+	for i := 0; i < count; i++ {
+		select {
+		case m.eventCh <- event:
+		default:
+			slog.Warn("事件队列已满，丢弃事件")
+			return
+		}
+	}
+	slog.Info("检测到SSH登录失败", "user", event.Username, "ip", event.IP, "count", count)
+}
@@ -0,0 +1,92 @@
+package sshmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+func TestHandleAuthLogLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		count    int
+		username string
+		ip       string
+		port     string
+	}{
+		{
+			name:     "密码错误",
+			line:     "Jan 18 10:30:00 host sshd[1234]: Failed password for root from 203.0.113.5 port 52144 ssh2",
+			count:    1,
+			username: "root",
+			ip:       "203.0.113.5",
+			port:     "52144",
+		},
+		{
+			name:     "不存在的用户",
+			line:     "2026-01-18T10:30:00.123456+08:00 host sshd[1234]: Failed password for invalid user admin from 2001:db8::1 port 40000 ssh2",
+			count:    1,
+			username: "admin",
+			ip:       "2001:db8::1",
+			port:     "40000",
+		},
+		{
+			name:     "rsyslog 合并的重复记录",
+			line:     "Jan 18 10:30:00 host sshd[1234]: message repeated 3 times: [ Failed password for root from 203.0.113.5 port 52144 ssh2]",
+			count:    3,
+			username: "root",
+			ip:       "203.0.113.5",
+			port:     "52144",
+		},
+		{
+			name:  "重复次数超过上限时截断",
+			line:  "Jan 18 10:30:00 host sshd[1234]: message repeated 5000 times: [ Failed password for root from 203.0.113.5 port 52144 ssh2]",
+			count: maxRepeatedEvents,
+			ip:    "203.0.113.5",
+		},
+		{
+			name: "同一次尝试的 pam_unix 记录不重复计数",
+			line: "Jan 18 10:30:00 host sshd[1234]: pam_unix(sshd:auth): authentication failure; logname= uid=0 euid=0 tty=ssh ruser= rhost=203.0.113.5  user=root",
+		},
+		{
+			name: "登录成功",
+			line: "Jan 18 10:30:00 host sshd[1234]: Accepted publickey for root from 203.0.113.5 port 52144 ssh2",
+		},
+		{
+			name: "其他程序的记录",
+			line: "Jan 18 10:30:00 host vsftpd[99]: Failed password for root from 203.0.113.5",
+		},
+		{
+			name: "缺少来源地址",
+			line: "Jan 18 10:30:00 host sshd[1234]: Failed password for root",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMonitor()
+			m.eventCh = make(chan protocol.SSHLoginEvent, maxRepeatedEvents+1)
+			m.handleAuthLogLine(tt.line)
+
+			if len(m.eventCh) != tt.count {
+				t.Fatalf("事件数量 = %d, want %d", len(m.eventCh), tt.count)
+			}
+			for len(m.eventCh) > 0 {
+				event := <-m.eventCh
+				if event.Status != "failed" || event.IP != tt.ip {
+					t.Errorf("事件 = %+v, want ip %s", event, tt.ip)
+				}
+				if tt.username != "" && event.Username != tt.username {
+					t.Errorf("用户名 = %q, want %q", event.Username, tt.username)
+				}
+				if tt.port != "" && event.Port != tt.port {
+					t.Errorf("端口 = %q, want %q", event.Port, tt.port)
+				}
+				if event.Timestamp <= 0 || event.Timestamp > time.Now().Add(time.Hour).UnixMilli() {
+					t.Errorf("时间戳无效: %d", event.Timestamp)
+				}
+			}
+		})
+	}
+}
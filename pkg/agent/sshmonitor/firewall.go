@@ -0,0 +1,364 @@
+package sshmonitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+const (
+	firewallBackendNft      = "nft"
+	firewallBackendIptables = "iptables"
+
+	// nftables 表和集合，集合元素自带超时，到期由内核自动移除
+	nftTable   = "pika"
+	nftSetIPv4 = "ssh_ban4"
+	nftSetIPv6 = "ssh_ban6"
+
+	// iptablesChain iptables 封禁链，iptables 无原生超时，由定时器解封
+	iptablesChain = "PIKA_SSH_BAN"
+
+	firewallCommandTimeout = 10 * time.Second
+)
+
+// Firewall 基于 nftables/iptables 的来源地址封禁，只丢弃封禁地址访问 sshd 端口的入站流量，不影响其他服务
+type Firewall struct {
+	mu      sync.Mutex
+	backend string
+	ready   bool
+	ports   []int                  // 封禁生效的 sshd 端口，创建封禁表/链时检测
+	timers  map[string]*time.Timer // iptables 封禁到期定时器
+}
+
+// NewFirewall 创建封禁管理器
+func NewFirewall() *Firewall {
+	return &Firewall{
+		timers: make(map[string]*time.Timer),
+	}
+}
+
+// Ban 封禁 IP 或网段，d 为封禁时长
+func (f *Firewall) Ban(cidr string, d time.Duration) error {
+	ipNet, err := ParseBanCIDR(cidr)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("封禁时长无效: %s", d)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.ensure(); err != nil {
+		return err
+	}
+	if err := f.add(ipNet, d); err != nil {
+		return err
+	}
+	slog.Info("已封禁来源地址", "cidr", ipNet.String(), "duration", d, "ports", f.ports, "backend", f.backend)
+	return nil
+}
+
+// Unban 解除封禁
+func (f *Firewall) Unban(cidr string) error {
+	ipNet, err := ParseBanCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.ensure(); err != nil {
+		return err
+	}
+	if err := f.remove(ipNet); err != nil {
+		return err
+	}
+	slog.Info("已解除来源地址封禁", "cidr", ipNet.String(), "backend", f.backend)
+	return nil
+}
+
+// Sync 以服务端下发的封禁列表为准重建封禁规则
+func (f *Firewall) Sync(bans []protocol.SSHBan) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.flush()
+	if len(bans) == 0 {
+		return nil
+	}
+	if err := f.ensure(); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, ban := range bans {
+		ipNet, err := ParseBanCIDR(ban.CIDR)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := f.add(ipNet, time.Duration(ban.Duration)*time.Second); err != nil {
+			slog.Warn("恢复封禁失败", "cidr", ban.CIDR, "error", err)
+			lastErr = err
+		}
+	}
+	slog.Info("已同步封禁列表", "count", len(bans), "backend", f.backend)
+	return lastErr
+}
+
+// Flush 清除所有封禁规则
+func (f *Firewall) Flush() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flush()
+}
+
+// ensure 检测防火墙后端并创建封禁表/链(内部方法,调用方持有 f.mu)
+func (f *Firewall) ensure() error {
+	if f.ready {
+		return nil
+	}
+	if f.backend == "" {
+		switch {
+		case commandExists("nft"):
+			f.backend = firewallBackendNft
+		case commandExists("iptables"):
+			f.backend = firewallBackendIptables
+		default:
+			return fmt.Errorf("未找到 nftables 或 iptables，无法封禁")
+		}
+	}
+
+	f.ports = sshdPorts()
+
+	var err error
+	if f.backend == firewallBackendNft {
+		err = f.ensureNft()
+	} else {
+		err = f.ensureIptables()
+	}
+	if err != nil {
+		return err
+	}
+	f.ready = true
+	return nil
+}
+
+func (f *Firewall) ensureNft() error {
+	script := fmt.Sprintf(`table inet %[1]s {
+	set %[2]s { type ipv4_addr; flags interval, timeout; }
+	set %[3]s { type ipv6_addr; flags interval, timeout; }
+	chain input {
+		type filter hook input priority -10; policy accept;
+		ip saddr @%[2]s tcp dport { %[4]s } drop
+		ip6 saddr @%[3]s tcp dport { %[4]s } drop
+	}
+}
+`, nftTable, nftSetIPv4, nftSetIPv6, joinPorts(f.ports, ", "))
+
+	// 先删除旧表，避免重复添加规则
+	_ = runFirewallCommand("", "nft", "delete", "table", "inet", nftTable)
+	if err := runFirewallCommand(script, "nft", "-f", "-"); err != nil {
+		return fmt.Errorf("创建 nftables 封禁表失败: %w", err)
+	}
+	return nil
+}
+
+func (f *Firewall) ensureIptables() error {
+	for _, cmd := range iptablesCommands() {
+		_ = runFirewallCommand("", cmd, "-N", iptablesChain)
+		if runFirewallCommand("", cmd, "-C", "INPUT", "-j", iptablesChain) != nil {
+			if err := runFirewallCommand("", cmd, "-I", "INPUT", "-j", iptablesChain); err != nil {
+				return fmt.Errorf("创建 %s 封禁链失败: %w", cmd, err)
+			}
+		}
+	}
+	return nil
+}
+
+// add 添加封禁，已存在时刷新时长(内部方法,调用方持有 f.mu)
+func (f *Firewall) add(ipNet *net.IPNet, d time.Duration) error {
+	cidr := ipNet.String()
+	if f.backend == firewallBackendNft {
+		set := nftSet(ipNet)
+		_ = runFirewallCommand("", "nft", "delete", "element", "inet", nftTable, set, "{ "+cidr+" }")
+		element := fmt.Sprintf("{ %s timeout %ds }", cidr, int64(d.Seconds()))
+		if err := runFirewallCommand("", "nft", "add", "element", "inet", nftTable, set, element); err != nil {
+			return fmt.Errorf("添加 nftables 封禁失败: %w", err)
+		}
+		return nil
+	}
+
+	cmd := iptablesCommand(ipNet)
+	rule := f.iptablesRule(cidr)
+	if runFirewallCommand("", cmd, append([]string{"-C", iptablesChain}, rule...)...) != nil {
+		if err := runFirewallCommand("", cmd, append([]string{"-A", iptablesChain}, rule...)...); err != nil {
+			return fmt.Errorf("添加 %s 封禁失败: %w", cmd, err)
+		}
+	}
+	if timer, ok := f.timers[cidr]; ok {
+		timer.Stop()
+	}
+	f.timers[cidr] = time.AfterFunc(d, func() {
+		f.expire(cidr)
+	})
+	return nil
+}
+
+// remove 删除封禁(内部方法,调用方持有 f.mu)
+func (f *Firewall) remove(ipNet *net.IPNet) error {
+	cidr := ipNet.String()
+	if f.backend == firewallBackendNft {
+		if err := runFirewallCommand("", "nft", "delete", "element", "inet", nftTable, nftSet(ipNet), "{ "+cidr+" }"); err != nil {
+			// 元素可能已超时被内核移除
+			slog.Debug("删除 nftables 封禁失败", "cidr", cidr, "error", err)
+		}
+		return nil
+	}
+
+	if timer, ok := f.timers[cidr]; ok {
+		timer.Stop()
+		delete(f.timers, cidr)
+	}
+	cmd := iptablesCommand(ipNet)
+	rule := f.iptablesRule(cidr)
+	for runFirewallCommand("", cmd, append([]string{"-C", iptablesChain}, rule...)...) == nil {
+		if err := runFirewallCommand("", cmd, append([]string{"-D", iptablesChain}, rule...)...); err != nil {
+			return fmt.Errorf("删除 %s 封禁失败: %w", cmd, err)
+		}
+	}
+	return nil
+}
+
+// expire iptables 封禁到期回调
+func (f *Firewall) expire(cidr string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.timers[cidr]; !ok {
+		return
+	}
+	delete(f.timers, cidr)
+
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return
+	}
+	if err := f.remove(ipNet); err != nil {
+		slog.Warn("封禁到期解除失败", "cidr", cidr, "error", err)
+		return
+	}
+	slog.Info("封禁已到期解除", "cidr", cidr)
+}
+
+// flush 删除封禁表/链(内部方法,调用方持有 f.mu)
+func (f *Firewall) flush() {
+	for cidr, timer := range f.timers {
+		timer.Stop()
+		delete(f.timers, cidr)
+	}
+	f.ready = false
+
+	if commandExists("nft") {
+		_ = runFirewallCommand("", "nft", "delete", "table", "inet", nftTable)
+	}
+	for _, cmd := range iptablesCommands() {
+		_ = runFirewallCommand("", cmd, "-D", "INPUT", "-j", iptablesChain)
+		_ = runFirewallCommand("", cmd, "-F", iptablesChain)
+		_ = runFirewallCommand("", cmd, "-X", iptablesChain)
+	}
+}
+
+// ParseBanCIDR 解析封禁地址，单个 IP 转换为 /32 或 /128 网段
+func ParseBanCIDR(value string) (*net.IPNet, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("封禁地址格式错误: %s", value)
+		}
+		return ipNet, nil
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("封禁地址格式错误: %s", value)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// iptablesRule 封禁规则（不含链名），仅匹配 sshd 端口
+func (f *Firewall) iptablesRule(cidr string) []string {
+	return []string{"-s", cidr, "-p", "tcp", "-m", "multiport", "--dports", joinPorts(f.ports, ","), "-j", "DROP"}
+}
+
+func joinPorts(ports []int, sep string) string {
+	values := make([]string, 0, len(ports))
+	for _, port := range ports {
+		values = append(values, strconv.Itoa(port))
+	}
+	return strings.Join(values, sep)
+}
+
+func nftSet(ipNet *net.IPNet) string {
+	if ipNet.IP.To4() != nil {
+		return nftSetIPv4
+	}
+	return nftSetIPv6
+}
+
+func iptablesCommand(ipNet *net.IPNet) string {
+	if ipNet.IP.To4() != nil {
+		return "iptables"
+	}
+	return "ip6tables"
+}
+
+// iptablesCommands 当前系统可用的 iptables 命令
+func iptablesCommands() []string {
+	var commands []string
+	for _, cmd := range []string{"iptables", "ip6tables"} {
+		if commandExists(cmd) {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// runFirewallCommand 执行防火墙命令，stdin 非空时作为标准输入
+func runFirewallCommand(stdin string, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), firewallCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package sshmonitor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSSHDPorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		ports    []int
+		includes []string
+	}{
+		{
+			name:    "未配置端口",
+			content: "#Port 22\nPermitRootLogin no\n",
+		},
+		{
+			name:    "多个端口",
+			content: "Port 22\nport 2222\nPort 22\n",
+			ports:   []int{22, 2222},
+		},
+		{
+			name:    "带端口的监听地址",
+			content: "ListenAddress 0.0.0.0:2200\nListenAddress [::]:2201\nListenAddress 10.0.0.1\n",
+			ports:   []int{2200, 2201},
+		},
+		{
+			name:     "Include 和 Match 块",
+			content:  "Include /etc/ssh/sshd_config.d/*.conf\nPort 2022\nMatch User git\n\tPort 9999\n",
+			ports:    []int{2022},
+			includes: []string{"/etc/ssh/sshd_config.d/*.conf"},
+		},
+		{
+			name:    "sshd -T 输出",
+			content: "port 22\nport 8022\naddressfamily any\nlistenaddress [::]:22\nlistenaddress 0.0.0.0:8022\n",
+			ports:   []int{22, 8022},
+		},
+		{
+			name:    "无效端口",
+			content: "Port 0\nPort 70000\nPort ssh\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, includes := parseSSHDPorts(tt.content)
+			if !reflect.DeepEqual(ports, tt.ports) || !reflect.DeepEqual(includes, tt.includes) {
+				t.Errorf("parseSSHDPorts() = %v, %v, want %v, %v", ports, includes, tt.ports, tt.includes)
+			}
+		})
+	}
+}
+
+func TestFirewallRuleMatchesSSHPorts(t *testing.T) {
+	f := &Firewall{ports: []int{22, 2222}}
+	want := []string{"-s", "203.0.113.0/24", "-p", "tcp", "-m", "multiport", "--dports", "22,2222", "-j", "DROP"}
+	if got := f.iptablesRule("203.0.113.0/24"); !reflect.DeepEqual(got, want) {
+		t.Errorf("iptablesRule() = %v, want %v", got, want)
+	}
+}
+
+func TestParseBanCIDR(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"203.0.113.5", "203.0.113.5/32", false},
+		{" 2001:db8::1 ", "2001:db8::1/128", false},
+		{"203.0.113.77/24", "203.0.113.0/24", false},
+		{"203.0.113", "", true},
+		{"203.0.113.0/33", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ipNet, err := ParseBanCIDR(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBanCIDR(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err == nil && ipNet.String() != tt.want {
+				t.Errorf("ParseBanCIDR(%q) = %s, want %s", tt.value, ipNet, tt.want)
+			}
+		})
+	}
+}
@@ -11,12 +11,19 @@ import (
 	"github.com/dushixiang/pika/internal/protocol"
 )
 
-// SendEventFromEnv 从 PAM 环境变量构建并发送事件
+// SendEventFromEnv 从 PAM 环境变量构建并发送事件（open_session 为登录，close_session 为登出）
 func SendEventFromEnv() error {
-	if os.Getenv("PAM_TYPE") != "open_session" {
+	var status string
+	switch os.Getenv("PAM_TYPE") {
+	case "open_session":
+		status = "success"
+	case "close_session":
+		status = "logout"
+	default:
 		return nil
 	}
 	event := BuildEventFromEnv()
+	event.Status = status
 	return SendEvent(event)
 }
 
@@ -57,7 +64,8 @@ func BuildEventFromEnv() protocol.SSHLoginEvent {
 		Timestamp: now,
 		Status:    "success",
 		TTY:       tty,
-		SessionID: strconv.Itoa(os.Getpid()),
+		// pam_exec 由 sshd 会话进程调用，open/close_session 时父进程相同，以其 PID 关联同一会话
+		SessionID: strconv.Itoa(os.Getppid()),
 	}
 }
 
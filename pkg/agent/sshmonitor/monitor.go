@@ -29,7 +29,10 @@ type Monitor struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
 	eventCh     chan protocol.SSHLoginEvent
+	sudoCh      chan protocol.SSHSudoEvent
 	hookManager *HookManager
+	firewall    *Firewall
+	sessions    *sessionTracker
 }
 
 // NewMonitor 创建监控器
@@ -38,7 +41,10 @@ func NewMonitor() *Monitor {
 		enabled:     false,
 		socketPath:  DefaultSocketPath,
 		eventCh:     make(chan protocol.SSHLoginEvent, 100),
+		sudoCh:      make(chan protocol.SSHSudoEvent, 100),
 		hookManager: NewHookManager(),
+		firewall:    NewFirewall(),
+		sessions:    newSessionTracker(),
 	}
 }
 
@@ -58,6 +64,8 @@ func (m *Monitor) Start(ctx context.Context, config protocol.SSHLoginConfig) err
 	}
 
 	if !config.Enabled {
+		// 清理上次运行残留的封禁规则
+		m.firewall.Flush()
 		slog.Info("SSH登录监控已禁用")
 		return nil
 	}
@@ -67,6 +75,15 @@ func (m *Monitor) Start(ctx context.Context, config protocol.SSHLoginConfig) err
 	}
 	m.enabled = true
 
+	// 跟踪登录失败和 sudo 记录，并恢复服务端记录的封禁
+	go m.watchFailedLogins(m.ctx)
+	// 恢复服务端记录的在线会话，并定期清理已结束的会话
+	m.sessions.reset(config.Sessions)
+	go m.reapSessions(m.ctx)
+	if err := m.firewall.Sync(config.Bans); err != nil {
+		slog.Warn("同步封禁列表失败", "error", err)
+	}
+
 	// 安装 PAM Hook
 	if err := m.hookManager.Install(); err != nil {
 		if os.IsPermission(err) {
@@ -118,16 +135,49 @@ func (m *Monitor) stopInternal() error {
 		slog.Warn("卸载 PAM Hook 失败", "error", err)
 	}
 
+	// 停止监控后不再维护封禁，清除所有封禁规则
+	m.firewall.Flush()
+	m.sessions.reset(nil)
+
 	m.enabled = false
 	slog.Info("SSH登录监控已停止")
 	return nil
 }
 
+// Ban 封禁来源地址（IP 或 CIDR），到期自动解封
+func (m *Monitor) Ban(cidr string, d time.Duration) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("SSH登录封禁仅支持 Linux 系统")
+	}
+	return m.firewall.Ban(cidr, d)
+}
+
+// Unban 解除来源地址封禁
+func (m *Monitor) Unban(cidr string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("SSH登录封禁仅支持 Linux 系统")
+	}
+	return m.firewall.Unban(cidr)
+}
+
+// ClearBans 清除所有封禁规则（卸载探针时使用）
+func (m *Monitor) ClearBans() {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	m.firewall.Flush()
+}
+
 // GetEvents 获取事件通道
 func (m *Monitor) GetEvents() <-chan protocol.SSHLoginEvent {
 	return m.eventCh
 }
 
+// GetSudoEvents 获取 sudo 提权事件通道
+func (m *Monitor) GetSudoEvents() <-chan protocol.SSHSudoEvent {
+	return m.sudoCh
+}
+
 // initSocket 初始化 socket 监听
 func (m *Monitor) initSocket(ctx context.Context) error {
 	if err := os.MkdirAll(DefaultSocketDir, 0755); err != nil {
@@ -190,6 +240,13 @@ func (m *Monitor) readLoop() {
 		if event.Status == "" {
 			event.Status = "success"
 		}
+		if event.Status == "logout" {
+			if !m.sessions.close(&event) {
+				slog.Debug("未找到登出事件对应的会话", "user", event.Username, "sessionId", event.SessionID)
+			}
+		} else if event.Status == "success" {
+			m.sessions.open(event)
+		}
 
 		select {
 		case m.eventCh <- event:
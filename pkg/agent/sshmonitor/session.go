@@ -0,0 +1,226 @@
+package sshmonitor
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+const (
+	// sessionReapInterval 检查会话进程是否存活的间隔
+	sessionReapInterval = time.Minute
+	// maxTrackedSessions 最多跟踪的在线会话数
+	maxTrackedSessions = 4096
+	// sessionTTYSearchDepth 从 sshd 会话进程向下查找终端的最大层数
+	sessionTTYSearchDepth = 3
+)
+
+// sessionTracker 跟踪在线的 SSH 会话，将登出事件与登录事件关联并计算会话时长
+type sessionTracker struct {
+	mu       sync.Mutex
+	sessions map[string]protocol.SSHLoginEvent // 会话ID -> 登录事件
+}
+
+func newSessionTracker() *sessionTracker {
+	return &sessionTracker{
+		sessions: make(map[string]protocol.SSHLoginEvent),
+	}
+}
+
+// reset 以服务端记录的在线会话重建跟踪状态
+func (t *sessionTracker) reset(sessions []protocol.SSHLoginEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sessions = make(map[string]protocol.SSHLoginEvent, len(sessions))
+	for _, session := range sessions {
+		if session.SessionID != "" {
+			t.sessions[session.SessionID] = session
+		}
+	}
+}
+
+// open 记录会话开始
+func (t *sessionTracker) open(event protocol.SSHLoginEvent) {
+	if event.SessionID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.sessions) >= maxTrackedSessions {
+		slog.Warn("跟踪的SSH会话过多，忽略新会话", "sessionId", event.SessionID)
+		return
+	}
+	t.sessions[event.SessionID] = event
+}
+
+// close 结束会话，按会话ID关联登录事件（找不到时按用户和终端关联），补全会话开始时间和时长
+func (t *sessionTracker) close(event *protocol.SSHLoginEvent) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	opened, ok := t.sessions[event.SessionID]
+	if !ok {
+		for id, session := range t.sessions {
+			if session.Username == event.Username && event.TTY != "" && event.TTY != "unknown" &&
+				normalizeTTY(session.TTY) == normalizeTTY(event.TTY) {
+				opened, ok = session, true
+				event.SessionID = id
+				break
+			}
+		}
+	}
+	if !ok {
+		return false
+	}
+	delete(t.sessions, event.SessionID)
+
+	if event.IP == "" || event.IP == "localhost" {
+		event.IP = opened.IP
+	}
+	if event.Port == "" {
+		event.Port = opened.Port
+	}
+	event.OpenedAt = opened.Timestamp
+	if event.Timestamp > opened.Timestamp {
+		event.Duration = (event.Timestamp - opened.Timestamp) / 1000
+	}
+	return true
+}
+
+// findByTTY 查找 sudo 记录所属的会话：优先按用户和终端匹配，该用户只有一个在线会话时直接归属该会话
+func (t *sessionTracker) findByTTY(username, tty string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tty = normalizeTTY(tty)
+	matched := ""
+	count := 0
+	for id, session := range t.sessions {
+		if session.Username != username {
+			continue
+		}
+		count++
+		matched = id
+
+		sessionTTY := normalizeTTY(session.TTY)
+		if !strings.HasPrefix(sessionTTY, "pts/") && !strings.HasPrefix(sessionTTY, "tty") {
+			// sshd 可能只设置 PAM_TTY=ssh，此时从会话进程的子进程中查找实际终端
+			if resolved := resolveSessionTTY(id); resolved != "" {
+				session.TTY = resolved
+				t.sessions[id] = session
+				sessionTTY = resolved
+			}
+		}
+		if tty != "" && sessionTTY == tty {
+			return id
+		}
+	}
+	if count == 1 {
+		return matched
+	}
+	return ""
+}
+
+// reap 清理会话进程已退出的会话（如探针重启期间结束的会话），返回补发的登出事件
+func (t *sessionTracker) reap() []protocol.SSHLoginEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	var events []protocol.SSHLoginEvent
+	for id, session := range t.sessions {
+		pid, err := strconv.ParseInt(id, 10, 32)
+		if err != nil {
+			continue
+		}
+		if exists, err := process.PidExists(int32(pid)); err != nil || exists {
+			continue
+		}
+		delete(t.sessions, id)
+
+		event := protocol.SSHLoginEvent{
+			Username:  session.Username,
+			IP:        session.IP,
+			Port:      session.Port,
+			Timestamp: now,
+			Status:    "logout",
+			TTY:       session.TTY,
+			SessionID: id,
+			OpenedAt:  session.Timestamp,
+		}
+		if now > session.Timestamp {
+			event.Duration = (now - session.Timestamp) / 1000
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// reapSessions 定期清理已结束但未收到登出事件的会话
+func (m *Monitor) reapSessions(ctx context.Context) {
+	ticker := time.NewTicker(sessionReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, event := range m.sessions.reap() {
+			select {
+			case m.eventCh <- event:
+				slog.Info("SSH会话进程已退出，补发登出事件", "user", event.Username, "sessionId", event.SessionID)
+			default:
+				slog.Warn("事件队列已满，丢弃事件")
+			}
+		}
+	}
+}
+
+// resolveSessionTTY 从 sshd 会话进程的子进程中查找分配的终端
+func resolveSessionTTY(sessionID string) string {
+	pid, err := strconv.ParseInt(sessionID, 10, 32)
+	if err != nil {
+		return ""
+	}
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return ""
+	}
+	return findProcessTTY(p, sessionTTYSearchDepth)
+}
+
+func findProcessTTY(p *process.Process, depth int) string {
+	if terminal, err := p.Terminal(); err == nil && terminal != "" {
+		return normalizeTTY(terminal)
+	}
+	if depth <= 0 {
+		return ""
+	}
+	children, err := p.Children()
+	if err != nil {
+		return ""
+	}
+	for _, child := range children {
+		if tty := findProcessTTY(child, depth-1); tty != "" {
+			return tty
+		}
+	}
+	return ""
+}
+
+// normalizeTTY 统一终端名称格式（/dev/pts/0 -> pts/0）
+func normalizeTTY(tty string) string {
+	tty = strings.TrimPrefix(tty, "/dev/")
+	return strings.TrimPrefix(tty, "/")
+}
@@ -0,0 +1,96 @@
+package sshmonitor
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSSHDPort = 22
+	// maxSSHDIncludeDepth Include 嵌套的最大深度，避免配置循环引用
+	maxSSHDIncludeDepth = 5
+)
+
+// sshdPorts 获取 sshd 监听的端口：优先使用 sshd -T 输出的生效配置，失败时解析配置文件，均未配置时为 22
+func sshdPorts() []int {
+	ctx, cancel := context.WithTimeout(context.Background(), firewallCommandTimeout)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx, "sshd", "-T").Output(); err == nil {
+		if ports, _ := parseSSHDPorts(string(output)); len(ports) > 0 {
+			return ports
+		}
+	}
+	if ports := sshdConfigPorts(SSHDConfigFile, 0); len(ports) > 0 {
+		return ports
+	}
+	return []int{defaultSSHDPort}
+}
+
+// sshdConfigPorts 解析配置文件及其 Include 的文件中配置的端口
+func sshdConfigPorts(path string, depth int) []int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	ports, includes := parseSSHDPorts(string(data))
+	if depth >= maxSSHDIncludeDepth {
+		return ports
+	}
+	for _, pattern := range includes {
+		// 相对路径相对于 /etc/ssh
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(SSHDConfigFile), pattern)
+		}
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			ports = appendPort(ports, sshdConfigPorts(match, depth+1)...)
+		}
+	}
+	return ports
+}
+
+// parseSSHDPorts 解析 sshd 配置（或 sshd -T 输出）中的 Port 和带端口的 ListenAddress，返回端口和 Include 的路径
+// 遇到 Match 块后停止解析，Match 块内不能修改监听端口
+func parseSSHDPorts(content string) (ports []int, includes []string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "port":
+			if port, err := strconv.Atoi(fields[1]); err == nil && port > 0 && port <= 65535 {
+				ports = appendPort(ports, port)
+			}
+		case "listenaddress":
+			if _, value, err := net.SplitHostPort(fields[1]); err == nil {
+				if port, err := strconv.Atoi(value); err == nil && port > 0 && port <= 65535 {
+					ports = appendPort(ports, port)
+				}
+			}
+		case "include":
+			includes = append(includes, fields[1:]...)
+		case "match":
+			return ports, includes
+		}
+	}
+	return ports, includes
+}
+
+func appendPort(ports []int, values ...int) []int {
+	for _, port := range values {
+		if !slices.Contains(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
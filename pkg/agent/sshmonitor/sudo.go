@@ -0,0 +1,82 @@
+package sshmonitor
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/pkg/agent/audit"
+)
+
+// sudoPattern 匹配 sudo 的命令记录，如 "sudo:    alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/usr/bin/id"
+var sudoPattern = regexp.MustCompile(`\bsudo(?:\[\d+\])?:\s+(\S+) : (.*)$`)
+
+// ParseSudoLine 解析认证日志中的 sudo 命令记录，非命令记录返回 nil
+func ParseSudoLine(line string) *protocol.SSHSudoEvent {
+	match := sudoPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if match == nil {
+		return nil
+	}
+
+	event := &protocol.SSHSudoEvent{
+		Username:  match[1],
+		Success:   true,
+		Timestamp: audit.ParseSyslogTime(line),
+	}
+	rest := match[2]
+	// COMMAND 总在最后且可能包含分隔符，单独截取
+	if idx := strings.Index(rest, "COMMAND="); idx != -1 {
+		event.Command = strings.TrimSpace(rest[idx+len("COMMAND="):])
+		rest = rest[:idx]
+	}
+	if event.Command == "" {
+		return nil
+	}
+
+	var messages []string
+	for _, part := range strings.Split(rest, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		switch {
+		case ok && key == "TTY":
+			event.TTY = value
+		case ok && key == "PWD":
+			event.PWD = value
+		case ok && key == "USER":
+			event.RunAs = value
+		case ok && strings.ToUpper(key) == key:
+			// 其他字段（如 ENV）忽略
+		default:
+			// 失败记录在字段前带有原因，如 "3 incorrect password attempts"、"user NOT in sudoers"
+			messages = append(messages, part)
+		}
+	}
+	if len(messages) > 0 {
+		event.Success = false
+		event.Message = strings.Join(messages, "; ")
+	}
+	if event.TTY == "unknown" {
+		event.TTY = ""
+	}
+	return event
+}
+
+// handleSudoLine 解析 sudo 命令记录，关联到所属会话后上报
+func (m *Monitor) handleSudoLine(line string) {
+	event := ParseSudoLine(line)
+	if event == nil {
+		return
+	}
+	event.SessionID = m.sessions.findByTTY(event.Username, event.TTY)
+
+	select {
+	case m.sudoCh <- *event:
+		slog.Info("检测到sudo提权", "user", event.Username, "tty", event.TTY, "runAs", event.RunAs, "success", event.Success)
+	default:
+		slog.Warn("事件队列已满，丢弃事件")
+	}
+}
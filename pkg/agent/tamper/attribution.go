@@ -0,0 +1,134 @@
+package tamper
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+const (
+	// attributionTTL 文件修改记录的有效期，超过后不再用于事件归因
+	attributionTTL = 30 * time.Second
+	// attributionDedup 同一进程短时间内重复写入同一文件时不重复采集进程信息
+	attributionDedup = time.Second
+	// maxAttributions 最多保留的文件修改记录数
+	maxAttributions = 4096
+)
+
+// ProcessInfo 修改文件的进程信息
+type ProcessInfo struct {
+	PID         int32    `json:"pid"`
+	PPID        int32    `json:"ppid"`
+	UID         uint32   `json:"uid"`
+	Username    string   `json:"username"`
+	Exe         string   `json:"exe"`
+	Cmdline     string   `json:"cmdline"`
+	ProcessTree []string `json:"processTree"` // 父进程链（从祖先到当前进程）
+}
+
+// ProcessTreeFunc 构建进程树，由调用方注入（复用审计模块的实现）
+type ProcessTreeFunc func(p *process.Process) []string
+
+// attributionSource 提供文件修改进程的事件来源（fanotify 或 audit netlink）
+type attributionSource interface {
+	// addTree 开始监控受保护路径（目录包括其子目录）
+	addTree(root string) error
+	// removeTree 停止监控受保护路径
+	removeTree(root string) error
+	// run 持续读取事件直到 ctx 取消，每次文件变化回调路径和进程 PID
+	run(ctx context.Context, handle func(path string, pid int32))
+}
+
+type attribution struct {
+	info *ProcessInfo
+	at   time.Time
+}
+
+// attributor 记录最近修改文件的进程，用于为防篡改事件归因
+type attributor struct {
+	mu        sync.Mutex
+	recent    map[string]attribution // 文件路径 -> 最近一次修改
+	buildTree ProcessTreeFunc
+}
+
+func newAttributor(buildTree ProcessTreeFunc) *attributor {
+	return &attributor{
+		recent:    make(map[string]attribution),
+		buildTree: buildTree,
+	}
+}
+
+// record 记录文件被进程修改（忽略探针自身的写入，如按快照恢复）
+func (a *attributor) record(path string, pid int32) {
+	if pid <= 0 || int(pid) == os.Getpid() {
+		return
+	}
+
+	now := time.Now()
+	a.mu.Lock()
+	if last, ok := a.recent[path]; ok && last.info.PID == pid && now.Sub(last.at) < attributionDedup {
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+
+	info := a.collect(pid)
+	if info == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.recent) >= maxAttributions {
+		a.prune(now)
+	}
+	a.recent[path] = attribution{info: info, at: now}
+}
+
+// lookup 查找最近修改该文件的进程
+func (a *attributor) lookup(path string) *ProcessInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	last, ok := a.recent[path]
+	if !ok || time.Since(last.at) > attributionTTL {
+		return nil
+	}
+	return last.info
+}
+
+// prune 清理过期记录，仍超出上限时全部清空
+func (a *attributor) prune(now time.Time) {
+	for path, last := range a.recent {
+		if now.Sub(last.at) > attributionTTL {
+			delete(a.recent, path)
+		}
+	}
+	if len(a.recent) >= maxAttributions {
+		a.recent = make(map[string]attribution)
+	}
+}
+
+// collect 采集进程信息（进程可能已退出，此时只保留 PID）
+func (a *attributor) collect(pid int32) *ProcessInfo {
+	info := &ProcessInfo{PID: pid}
+
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return info
+	}
+	info.PPID, _ = p.Ppid()
+	info.Exe, _ = p.Exe()
+	info.Cmdline, _ = p.Cmdline()
+	info.Username, _ = p.Username()
+	if uids, err := p.Uids(); err == nil && len(uids) > 0 {
+		info.UID = uids[0]
+	}
+	if a.buildTree != nil {
+		info.ProcessTree = a.buildTree(p)
+	}
+	return info
+}
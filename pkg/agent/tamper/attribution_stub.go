@@ -0,0 +1,10 @@
+//go:build !linux
+
+package tamper
+
+import "errors"
+
+// newAttributionSource 非 Linux 系统不支持进程归因
+func newAttributionSource() (attributionSource, error) {
+	return nil, errors.New("process attribution is not supported on non-Linux systems")
+}
@@ -0,0 +1,385 @@
+//go:build linux
+
+package tamper
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// auditRuleKey 探针添加的审计规则的 key，用于从审计日志中筛选事件
+	auditRuleKey = "pika-tamper"
+	// auditNlgrpReadlog 只读订阅审计日志的多播组（Linux 3.16+，需要 CAP_AUDIT_READ），不影响 auditd
+	auditNlgrpReadlog = 1
+	// auditReplyTimeout 等待内核响应的超时时间
+	auditReplyTimeout = 2 * time.Second
+	// auditPollInterval 读取审计日志的超时时间，用于检查 ctx 是否取消
+	auditPollInterval = time.Second
+)
+
+// auditWatcher 基于 audit netlink 获取修改文件的进程，用于不支持 fanotify FAN_REPORT_DFID_NAME 的内核
+// （需要 CAP_AUDIT_CONTROL 和 CAP_AUDIT_READ，内核审计需已启用；未运行 auditd 时审计记录会同时写入内核日志）
+type auditWatcher struct {
+	ctrl int // 管理规则的 socket
+	log  int // 订阅审计日志的 socket
+
+	mu    sync.Mutex
+	seq   uint32
+	rules map[string][]byte // 受保护路径 -> 已添加的规则
+}
+
+// newAuditWatcher 创建 audit netlink 监控
+func newAuditWatcher() (*auditWatcher, error) {
+	ctrl, err := openAuditSocket(0)
+	if err != nil {
+		return nil, fmt.Errorf("连接 audit netlink 失败: %w", err)
+	}
+	w := &auditWatcher{ctrl: ctrl, log: -1, rules: make(map[string][]byte)}
+
+	status, err := w.request(unix.AUDIT_GET, nil, true)
+	if err != nil {
+		w.close()
+		return nil, fmt.Errorf("获取审计状态失败: %w", err)
+	}
+	// audit_status: mask(4) + enabled(4)
+	if len(status) < 8 || binary.NativeEndian.Uint32(status[4:8]) == 0 {
+		w.close()
+		return nil, errors.New("内核审计未启用")
+	}
+
+	w.log, err = openAuditSocket(auditNlgrpReadlog)
+	if err != nil {
+		w.close()
+		return nil, fmt.Errorf("订阅审计日志失败: %w", err)
+	}
+	return w, nil
+}
+
+func openAuditSocket(groups uint32) (int, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_AUDIT)
+	if err != nil {
+		return -1, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: groups}); err != nil {
+		_ = unix.Close(fd)
+		return -1, err
+	}
+	timeout := auditReplyTimeout
+	if groups != 0 {
+		timeout = auditPollInterval
+	}
+	tv := unix.NsecToTimeval(timeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		_ = unix.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// addTree 添加审计监控规则（目录规则包括其子目录和新建的子目录）
+func (w *auditWatcher) addTree(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	rule := auditWatchRule(root, info.IsDir())
+	if _, err := w.request(unix.AUDIT_ADD_RULE, rule, false); err != nil && !errors.Is(err, unix.EEXIST) {
+		return err
+	}
+	w.mu.Lock()
+	w.rules[root] = rule
+	w.mu.Unlock()
+	return nil
+}
+
+// removeTree 删除审计监控规则
+func (w *auditWatcher) removeTree(root string) error {
+	w.mu.Lock()
+	rule, ok := w.rules[root]
+	delete(w.rules, root)
+	w.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	_, err := w.request(unix.AUDIT_DEL_RULE, rule, false)
+	return err
+}
+
+// run 读取审计日志，ctx 取消时删除规则并关闭 socket
+func (w *auditWatcher) run(ctx context.Context, handle func(path string, pid int32)) {
+	defer func() {
+		w.mu.Lock()
+		rules := w.rules
+		w.rules = make(map[string][]byte)
+		w.mu.Unlock()
+		for _, rule := range rules {
+			_, _ = w.request(unix.AUDIT_DEL_RULE, rule, false)
+		}
+		w.close()
+	}()
+
+	assembler := &auditAssembler{handle: handle}
+	buf := make([]byte, 64*1024)
+	for ctx.Err() == nil {
+		n, _, err := unix.Recvfrom(w.log, buf, 0)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			if errors.Is(err, unix.ENOBUFS) {
+				continue // 缓冲区溢出丢失部分记录
+			}
+			slog.Warn("读取审计日志失败", "error", err)
+			return
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, msg := range msgs {
+			assembler.add(msg.Header.Type, strings.TrimRight(string(msg.Data), "\x00"))
+		}
+	}
+}
+
+func (w *auditWatcher) close() {
+	if w.ctrl >= 0 {
+		_ = unix.Close(w.ctrl)
+		w.ctrl = -1
+	}
+	if w.log >= 0 {
+		_ = unix.Close(w.log)
+		w.log = -1
+	}
+}
+
+// request 发送请求并等待内核确认，wantReply 时返回同类型的响应内容
+func (w *auditWatcher) request(msgType uint16, data []byte, wantReply bool) ([]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	seq := w.seq
+	msg := make([]byte, unix.NLMSG_HDRLEN, unix.NLMSG_HDRLEN+len(data))
+	binary.NativeEndian.PutUint32(msg[0:4], uint32(unix.NLMSG_HDRLEN+len(data)))
+	binary.NativeEndian.PutUint16(msg[4:6], msgType)
+	binary.NativeEndian.PutUint16(msg[6:8], unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+	binary.NativeEndian.PutUint32(msg[8:12], seq)
+	msg = append(msg, data...)
+	if err := unix.Sendto(w.ctrl, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	// AUDIT_GET 的响应可能在确认之后到达
+	var reply []byte
+	acked := false
+	buf := make([]byte, unix.Getpagesize())
+	for !acked || (wantReply && reply == nil) {
+		n, _, err := unix.Recvfrom(w.ctrl, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != seq {
+				continue
+			}
+			switch m.Header.Type {
+			case unix.NLMSG_ERROR:
+				if len(m.Data) < 4 {
+					return nil, errors.New("无效的 netlink 响应")
+				}
+				if errno := int32(binary.NativeEndian.Uint32(m.Data[0:4])); errno != 0 {
+					return nil, unix.Errno(-errno)
+				}
+				acked = true
+			case msgType:
+				reply = append([]byte(nil), m.Data...)
+			}
+		}
+	}
+	return reply, nil
+}
+
+// auditWatchRule 构造 audit_rule_data，等价于 auditctl -w <path> -p wa -k pika-tamper
+func auditWatchRule(path string, dir bool) []byte {
+	const (
+		headerWords = 3 // flags、action、field_count
+		fieldsIndex = headerWords + unix.AUDIT_BITMASK_SIZE
+		valuesIndex = fieldsIndex + unix.AUDIT_MAX_FIELDS
+		flagsIndex  = valuesIndex + unix.AUDIT_MAX_FIELDS
+		buflenIndex = flagsIndex + unix.AUDIT_MAX_FIELDS
+	)
+	pathField := uint32(unix.AUDIT_WATCH)
+	if dir {
+		pathField = unix.AUDIT_DIR
+	}
+	fields := []struct {
+		field uint32
+		value uint32
+	}{
+		{pathField, uint32(len(path))},
+		{unix.AUDIT_PERM, unix.AUDIT_PERM_WRITE | unix.AUDIT_PERM_ATTR},
+		{unix.AUDIT_FILTERKEY, uint32(len(auditRuleKey))},
+	}
+
+	words := make([]uint32, buflenIndex+1)
+	words[0] = unix.AUDIT_FILTER_EXIT
+	words[1] = unix.AUDIT_ALWAYS
+	words[2] = uint32(len(fields))
+	for i := 0; i < unix.AUDIT_BITMASK_SIZE; i++ {
+		words[headerWords+i] = 0xffffffff // 所有系统调用
+	}
+	for i, f := range fields {
+		words[fieldsIndex+i] = f.field
+		words[valuesIndex+i] = f.value
+		words[flagsIndex+i] = unix.AUDIT_EQUAL
+	}
+	words[buflenIndex] = uint32(len(path) + len(auditRuleKey))
+
+	data := make([]byte, 0, len(words)*4+len(path)+len(auditRuleKey))
+	for _, word := range words {
+		data = binary.NativeEndian.AppendUint32(data, word)
+	}
+	data = append(data, path...)
+	return append(data, auditRuleKey...)
+}
+
+// auditEvent 同一审计事件（相同序号）的 SYSCALL、CWD、PATH 记录
+type auditEvent struct {
+	matched bool // 由探针的规则触发
+	pid     int32
+	cwd     string
+	paths   []string
+}
+
+// auditAssembler 按事件序号合并审计记录，事件结束时回调受影响的文件
+type auditAssembler struct {
+	serial string
+	event  auditEvent
+	handle func(path string, pid int32)
+}
+
+func (a *auditAssembler) add(msgType uint16, text string) {
+	serial, fields, ok := parseAuditRecord(text)
+	if !ok {
+		return
+	}
+	if serial != a.serial {
+		a.flush()
+		a.serial = serial
+	}
+
+	switch msgType {
+	case unix.AUDIT_SYSCALL:
+		a.event.matched = decodeAuditString(fields["key"]) == auditRuleKey
+		if pid, err := strconv.ParseInt(fields["pid"], 10, 32); err == nil {
+			a.event.pid = int32(pid)
+		}
+	case unix.AUDIT_CWD:
+		a.event.cwd = decodeAuditString(fields["cwd"])
+	case unix.AUDIT_PATH:
+		// PARENT 记录是所在目录，只关注被创建、删除、修改的文件本身
+		if fields["nametype"] == "PARENT" {
+			return
+		}
+		if name := decodeAuditString(fields["name"]); name != "" {
+			a.event.paths = append(a.event.paths, name)
+		}
+	case unix.AUDIT_EOE:
+		a.flush()
+	}
+}
+
+func (a *auditAssembler) flush() {
+	event := a.event
+	a.event = auditEvent{}
+	a.serial = ""
+	if !event.matched || event.pid <= 0 {
+		return
+	}
+	for _, name := range event.paths {
+		if !filepath.IsAbs(name) {
+			if event.cwd == "" {
+				continue
+			}
+			name = filepath.Join(event.cwd, name)
+		}
+		a.handle(filepath.Clean(name), event.pid)
+	}
+}
+
+// parseAuditRecord 解析 "audit(时间戳:序号): key=value ..." 格式的审计记录
+func parseAuditRecord(text string) (string, map[string]string, bool) {
+	if !strings.HasPrefix(text, "audit(") {
+		return "", nil, false
+	}
+	end := strings.Index(text, "):")
+	if end < 0 {
+		return "", nil, false
+	}
+	stamp := text[len("audit("):end]
+	colon := strings.LastIndexByte(stamp, ':')
+	if colon < 0 {
+		return "", nil, false
+	}
+
+	fields := make(map[string]string)
+	rest := text[end+2:]
+	for {
+		rest = strings.TrimLeft(rest, " ")
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			break
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				value, rest = rest, ""
+			} else {
+				value, rest = rest[:closing+2], rest[closing+2:]
+			}
+		} else if space := strings.IndexByte(rest, ' '); space >= 0 {
+			value, rest = rest[:space], rest[space:]
+		} else {
+			value, rest = rest, ""
+		}
+		fields[key] = value
+	}
+	return stamp[colon+1:], fields, true
+}
+
+// decodeAuditString 解码审计记录中的字符串：带引号的原文、十六进制编码（含空格等特殊字符）或 (null)
+func decodeAuditString(value string) string {
+	if value == "" || value == "(null)" {
+		return ""
+	}
+	if strings.HasPrefix(value, `"`) {
+		return strings.Trim(value, `"`)
+	}
+	if decoded, err := hex.DecodeString(value); err == nil {
+		return string(decoded)
+	}
+	return value
+}
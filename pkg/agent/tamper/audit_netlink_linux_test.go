@@ -0,0 +1,189 @@
+package tamper
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseAuditRecord(t *testing.T) {
+	serial, fields, ok := parseAuditRecord(`audit(1700000000.123:456): arch=c000003e syscall=87 success=yes exit=0 ppid=1 pid=4321 comm="rm -f" exe="/usr/bin/rm" key="pika-tamper"`)
+	if !ok {
+		t.Fatalf("应解析成功")
+	}
+	if serial != "456" {
+		t.Errorf("serial = %q", serial)
+	}
+	if fields["pid"] != "4321" || fields["comm"] != `"rm -f"` || fields["key"] != `"pika-tamper"` {
+		t.Errorf("字段解析错误: %v", fields)
+	}
+
+	if _, _, ok := parseAuditRecord("type=SYSCALL msg=audit(1:2): pid=1"); ok {
+		t.Errorf("非内核审计格式应解析失败")
+	}
+}
+
+func TestDecodeAuditString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"带引号", `"/var/www/index.html"`, "/var/www/index.html"},
+		{"十六进制编码", "2F7661722F7777772F6120622E68746D6C", "/var/www/a b.html"},
+		{"空值", "(null)", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeAuditString(tt.value); got != tt.want {
+				t.Errorf("decodeAuditString(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditAssembler(t *testing.T) {
+	type record struct {
+		msgType uint16
+		text    string
+	}
+	tests := []struct {
+		name    string
+		records []record
+		want    []string
+	}{
+		{
+			name: "删除文件",
+			records: []record{
+				{unix.AUDIT_SYSCALL, `audit(1.0:10): syscall=87 pid=100 key="pika-tamper"`},
+				{unix.AUDIT_CWD, `audit(1.0:10): cwd="/root"`},
+				{unix.AUDIT_PATH, `audit(1.0:10): item=0 name="/var/www/" nametype=PARENT`},
+				{unix.AUDIT_PATH, `audit(1.0:10): item=1 name="/var/www/index.html" nametype=DELETE`},
+				{unix.AUDIT_EOE, `audit(1.0:10): `},
+			},
+			want: []string{"/var/www/index.html"},
+		},
+		{
+			name: "相对路径按工作目录解析",
+			records: []record{
+				{unix.AUDIT_SYSCALL, `audit(1.0:11): syscall=82 pid=100 key="pika-tamper"`},
+				{unix.AUDIT_CWD, `audit(1.0:11): cwd="/var/www"`},
+				{unix.AUDIT_PATH, `audit(1.0:11): item=2 name="old.html" nametype=DELETE`},
+				{unix.AUDIT_PATH, `audit(1.0:11): item=3 name="new.html" nametype=CREATE`},
+				{unix.AUDIT_EOE, `audit(1.0:11): `},
+			},
+			want: []string{"/var/www/old.html", "/var/www/new.html"},
+		},
+		{
+			name: "其他规则触发的事件",
+			records: []record{
+				{unix.AUDIT_SYSCALL, `audit(1.0:12): syscall=87 pid=100 key="other"`},
+				{unix.AUDIT_PATH, `audit(1.0:12): item=1 name="/etc/shadow" nametype=DELETE`},
+				{unix.AUDIT_EOE, `audit(1.0:12): `},
+			},
+		},
+		{
+			name: "缺少结束记录时按序号切分",
+			records: []record{
+				{unix.AUDIT_SYSCALL, `audit(1.0:13): syscall=87 pid=100 key="pika-tamper"`},
+				{unix.AUDIT_PATH, `audit(1.0:13): item=1 name="/var/www/a.html" nametype=DELETE`},
+				{unix.AUDIT_SYSCALL, `audit(1.0:14): syscall=87 pid=100 key=(null)`},
+			},
+			want: []string{"/var/www/a.html"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			a := &auditAssembler{handle: func(path string, pid int32) {
+				if pid != 100 {
+					t.Errorf("pid = %d", pid)
+				}
+				got = append(got, path)
+			}}
+			for _, r := range tt.records {
+				a.add(r.msgType, r.text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("回调路径 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditWatchRule(t *testing.T) {
+	const path = "/var/www"
+	rule := auditWatchRule(path, true)
+	word := func(i int) uint32 {
+		return binary.NativeEndian.Uint32(rule[i*4:])
+	}
+	fieldsIndex := 3 + unix.AUDIT_BITMASK_SIZE
+	buflenIndex := fieldsIndex + 3*unix.AUDIT_MAX_FIELDS
+
+	if word(0) != unix.AUDIT_FILTER_EXIT || word(1) != unix.AUDIT_ALWAYS || word(2) != 3 {
+		t.Errorf("规则头不正确: flags=%d action=%d fields=%d", word(0), word(1), word(2))
+	}
+	if word(fieldsIndex) != unix.AUDIT_DIR || word(fieldsIndex+unix.AUDIT_MAX_FIELDS) != uint32(len(path)) {
+		t.Errorf("目录字段不正确")
+	}
+	if word(buflenIndex) != uint32(len(path)+len(auditRuleKey)) {
+		t.Errorf("buflen = %d", word(buflenIndex))
+	}
+	if got := string(rule[(buflenIndex+1)*4:]); got != path+auditRuleKey {
+		t.Errorf("字符串字段 = %q", got)
+	}
+	if file := auditWatchRule("/etc/passwd", false); binary.NativeEndian.Uint32(file[fieldsIndex*4:]) != unix.AUDIT_WATCH {
+		t.Errorf("单个文件应使用 AUDIT_WATCH")
+	}
+}
+
+// TestAuditWatcher 需要 root 权限且内核审计已启用，验证删除文件能归因
+func TestAuditWatcher(t *testing.T) {
+	w, err := newAuditWatcher()
+	if err != nil {
+		t.Skipf("audit netlink 不可用: %v", err)
+	}
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "index.html"), "original")
+	if err := w.addTree(root); err != nil {
+		w.close()
+		t.Skipf("添加审计规则失败: %v", err)
+	}
+
+	found := make(chan int32, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.run(ctx, func(path string, pid int32) {
+			if path == filepath.Join(root, "index.html") {
+				select {
+				case found <- pid:
+				default:
+				}
+			}
+		})
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if err := os.Remove(filepath.Join(root, "index.html")); err != nil {
+		t.Fatalf("删除文件失败: %v", err)
+	}
+	select {
+	case pid := <-found:
+		if int(pid) != os.Getpid() {
+			t.Errorf("pid = %d, want %d", pid, os.Getpid())
+		}
+	case <-time.After(3 * time.Second):
+		t.Errorf("未收到删除事件")
+	}
+}
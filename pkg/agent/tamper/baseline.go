@@ -0,0 +1,295 @@
+package tamper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/pkg/agent/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	baselineDBName  = "tamper_baseline.db"
+	baselineTimeout = 2 * time.Second
+	// unlockBucket 临时解锁窗口（受保护路径均为绝对路径，不会与此名称冲突）
+	unlockBucket = "__unlocks__"
+)
+
+// FileRecord 文件基线记录
+type FileRecord struct {
+	Hash    string `json:"hash"`    // SHA-256
+	Size    int64  `json:"size"`    // 文件大小
+	Mode    uint32 `json:"mode"`    // 文件权限
+	ModTime int64  `json:"modTime"` // 修改时间(毫秒)
+}
+
+// BaselineStore 文件哈希基线存储（bbolt），每个受保护路径一个 bucket
+// 数据库在首次使用时打开并一直保持，停止保护时通过 Close 释放
+type BaselineStore struct {
+	path string
+	mu   sync.Mutex
+	db   *bolt.DB
+}
+
+// NewBaselineStore 创建基线存储
+func NewBaselineStore(path string) *BaselineStore {
+	return &BaselineStore{path: path}
+}
+
+// defaultBaselinePath 默认基线数据库路径
+func defaultBaselinePath() string {
+	return filepath.Join(utils.GetSafeHomeDir(), ".pika", baselineDBName)
+}
+
+// Has 判断指定受保护路径是否已有基线
+func (s *BaselineStore) Has(root string) (bool, error) {
+	var exists bool
+	err := s.view(func(tx *bolt.Tx) error {
+		exists = tx.Bucket([]byte(root)) != nil
+		return nil
+	})
+	return exists, err
+}
+
+// Load 读取指定受保护路径的完整基线
+func (s *BaselineStore) Load(root string) (map[string]FileRecord, error) {
+	records := make(map[string]FileRecord)
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(root))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var record FileRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("解析基线记录失败: %w", err)
+			}
+			records[string(k)] = record
+			return nil
+		})
+	})
+	return records, err
+}
+
+// Get 读取单个文件的基线记录
+func (s *BaselineStore) Get(root, path string) (*FileRecord, error) {
+	var record *FileRecord
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(root))
+		if bucket == nil {
+			return nil
+		}
+		v := bucket.Get([]byte(path))
+		if v == nil {
+			return nil
+		}
+		record = &FileRecord{}
+		return json.Unmarshal(v, record)
+	})
+	return record, err
+}
+
+// Replace 使用新的基线整体替换指定受保护路径的基线
+func (s *BaselineStore) Replace(root string, records map[string]FileRecord) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(root)) != nil {
+			if err := tx.DeleteBucket([]byte(root)); err != nil {
+				return fmt.Errorf("删除旧基线失败: %w", err)
+			}
+		}
+		bucket, err := tx.CreateBucket([]byte(root))
+		if err != nil {
+			return fmt.Errorf("创建基线桶失败: %w", err)
+		}
+		for path, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(path), data); err != nil {
+				return fmt.Errorf("写入基线失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete 删除指定受保护路径的基线
+func (s *BaselineStore) Delete(root string) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(root)) == nil {
+			return nil
+		}
+		return tx.DeleteBucket([]byte(root))
+	})
+}
+
+// SaveUnlock 保存临时解锁窗口的到期时间，探针重启后据此恢复解锁状态或重建基线
+func (s *BaselineStore) SaveUnlock(root string, until time.Time) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(unlockBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(root), []byte(strconv.FormatInt(until.UnixMilli(), 10)))
+	})
+}
+
+// GetUnlock 读取临时解锁窗口的到期时间
+func (s *BaselineStore) GetUnlock(root string) (time.Time, bool, error) {
+	var until time.Time
+	var found bool
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(unlockBucket))
+		if bucket == nil {
+			return nil
+		}
+		v := bucket.Get([]byte(root))
+		if v == nil {
+			return nil
+		}
+		ms, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return err
+		}
+		until, found = time.UnixMilli(ms), true
+		return nil
+	})
+	return until, found, err
+}
+
+// DeleteUnlock 删除临时解锁窗口
+func (s *BaselineStore) DeleteUnlock(root string) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(unlockBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(root))
+	})
+}
+
+// Close 关闭基线数据库，之后的读写会重新打开
+func (s *BaselineStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+func (s *BaselineStore) view(fn func(tx *bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db == nil {
+		// 尚无基线时不创建数据库文件
+		if _, err := os.Stat(s.path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	db, err := s.openDB()
+	if err != nil {
+		return err
+	}
+	return db.View(fn)
+}
+
+func (s *BaselineStore) update(fn func(tx *bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := s.openDB()
+	if err != nil {
+		return err
+	}
+	return db.Update(fn)
+}
+
+// openDB 返回已打开的数据库，未打开时打开（调用方需持有 s.mu）
+func (s *BaselineStore) openDB() (*bolt.DB, error) {
+	if s.db != nil {
+		return s.db, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, fmt.Errorf("创建基线目录失败: %w", err)
+	}
+
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: baselineTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("打开基线数据库失败: %w", err)
+	}
+	s.db = db
+	return db, nil
+}
+
+// hashFile 计算文件 SHA-256，非普通文件返回 nil
+func hashFile(path string) (*FileRecord, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return &FileRecord{
+		Hash:    hex.EncodeToString(h.Sum(nil)),
+		Size:    info.Size(),
+		Mode:    uint32(info.Mode().Perm()),
+		ModTime: info.ModTime().UnixMilli(),
+	}, nil
+}
+
+// scanTree 计算受保护路径下所有普通文件的哈希（不跟随符号链接）
+func scanTree(root string) (map[string]FileRecord, error) {
+	records := make(map[string]FileRecord)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // 跳过无法访问的子路径
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		record, err := hashFile(path)
+		if err != nil || record == nil {
+			return nil
+		}
+		records[path] = *record
+		return nil
+	})
+	return records, err
+}
@@ -0,0 +1,321 @@
+//go:build linux
+
+package tamper
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// fanotifyFileMask 文件内容被修改、写方式打开后关闭（覆盖新建文件）
+	fanotifyFileMask = unix.FAN_MODIFY | unix.FAN_CLOSE_WRITE
+	// fanotifyEntryMask 目录项变化：创建、删除、移入、移出（包括子目录）
+	fanotifyEntryMask = unix.FAN_CREATE | unix.FAN_DELETE | unix.FAN_MOVED_FROM | unix.FAN_MOVED_TO | unix.FAN_ONDIR
+	// fanotifyDirMask 受保护目录的完整掩码
+	fanotifyDirMask = fanotifyFileMask | fanotifyEntryMask | unix.FAN_EVENT_ON_CHILD
+
+	// fanotifyMetadataSize fanotify_event_metadata 的大小
+	fanotifyMetadataSize = 24
+)
+
+// fanotifyWatcher 基于 fanotify（FAN_REPORT_DFID_NAME，Linux 5.9+）获取修改、删除、移动文件的进程 PID（需要 CAP_SYS_ADMIN）
+type fanotifyWatcher struct {
+	file *os.File
+	fd   int
+
+	mu       sync.Mutex
+	roots    map[string]bool   // 受保护路径 -> 是否为目录
+	parents  map[string]int    // 单文件保护时标记的父目录 -> 引用数
+	mountFds map[unix.Fsid]int // 文件系统 -> 用于解析文件句柄的目录 fd
+}
+
+// fanotifyEvent 从 fanotify 事件中解析出的目录句柄和文件名
+type fanotifyEvent struct {
+	mask       uint64
+	pid        int32
+	fsid       unix.Fsid
+	handleType int32
+	handle     []byte
+	name       string
+}
+
+// newAttributionSource 创建进程归因来源，优先使用 fanotify，内核不支持时回退到 audit netlink
+func newAttributionSource() (attributionSource, error) {
+	fanotify, fanErr := newFanotifyWatcher()
+	if fanErr == nil {
+		return fanotify, nil
+	}
+	audit, auditErr := newAuditWatcher()
+	if auditErr != nil {
+		return nil, errors.Join(fanErr, auditErr)
+	}
+	slog.Info("fanotify 不可用，进程归因使用 audit netlink", "error", fanErr)
+	return audit, nil
+}
+
+// newFanotifyWatcher 创建 fanotify 监控
+func newFanotifyWatcher() (*fanotifyWatcher, error) {
+	fd, err := unix.FanotifyInit(unix.FAN_CLASS_NOTIF|unix.FAN_CLOEXEC|unix.FAN_NONBLOCK|unix.FAN_REPORT_DFID_NAME, unix.O_RDONLY|unix.O_LARGEFILE|unix.O_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("初始化 fanotify 失败（需要 Linux 5.9+）: %w", err)
+	}
+	return &fanotifyWatcher{
+		file:     os.NewFile(uintptr(fd), "fanotify"),
+		fd:       fd,
+		roots:    make(map[string]bool),
+		parents:  make(map[string]int),
+		mountFds: make(map[unix.Fsid]int),
+	}, nil
+}
+
+// addTree 标记受保护路径下的所有目录，新建或移入的子目录在事件中自动标记
+func (w *fanotifyWatcher) addTree(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	dir := root
+	if !info.IsDir() {
+		dir = filepath.Dir(root)
+	}
+	if err := w.openMount(dir); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.roots[root] = info.IsDir()
+	w.mu.Unlock()
+
+	if info.IsDir() {
+		return w.markTree(root, unix.FAN_MARK_ADD)
+	}
+	// 单个文件：标记文件本身的修改，删除和重命名通过父目录的目录项事件获得
+	if err := unix.FanotifyMark(w.fd, unix.FAN_MARK_ADD, fanotifyFileMask, unix.AT_FDCWD, root); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.parents[dir]++
+	w.mu.Unlock()
+	return unix.FanotifyMark(w.fd, unix.FAN_MARK_ADD, fanotifyEntryMask, unix.AT_FDCWD, dir)
+}
+
+// removeTree 取消受保护路径的标记
+func (w *fanotifyWatcher) removeTree(root string) error {
+	w.mu.Lock()
+	isDir, ok := w.roots[root]
+	delete(w.roots, root)
+	w.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if isDir {
+		return w.markTree(root, unix.FAN_MARK_REMOVE)
+	}
+
+	err := unix.FanotifyMark(w.fd, unix.FAN_MARK_REMOVE, fanotifyFileMask, unix.AT_FDCWD, root)
+	dir := filepath.Dir(root)
+	w.mu.Lock()
+	w.parents[dir]--
+	release := w.parents[dir] <= 0 && !w.coveredByDir(dir)
+	if w.parents[dir] <= 0 {
+		delete(w.parents, dir)
+	}
+	w.mu.Unlock()
+	if release {
+		_ = unix.FanotifyMark(w.fd, unix.FAN_MARK_REMOVE, fanotifyEntryMask, unix.AT_FDCWD, dir)
+	}
+	return err
+}
+
+func (w *fanotifyWatcher) markTree(root string, flags uint) error {
+	var lastErr error
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if err := unix.FanotifyMark(w.fd, flags, fanotifyDirMask, unix.AT_FDCWD, path); err != nil {
+			lastErr = err
+		}
+		return nil
+	})
+	return lastErr
+}
+
+// openMount 为路径所在文件系统打开一个目录 fd，供 open_by_handle_at 解析目录句柄
+func (w *fanotifyWatcher) openMount(dir string) error {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.mountFds[stat.Fsid]; ok {
+		return nil
+	}
+	fd, err := unix.Open(dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	w.mountFds[stat.Fsid] = fd
+	return nil
+}
+
+// covers 判断路径是否属于受保护路径（单文件保护时父目录的其他文件不归因）
+func (w *fanotifyWatcher) covers(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.roots[path]; ok {
+		return true
+	}
+	return w.coveredByDir(path)
+}
+
+// coveredByDir 判断路径是否位于受保护目录下（调用方持有锁）
+func (w *fanotifyWatcher) coveredByDir(path string) bool {
+	for root, isDir := range w.roots {
+		if isDir && (path == root || strings.HasPrefix(path, root+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve 将目录句柄和文件名解析为完整路径（目录已被删除时解析失败）
+func (w *fanotifyWatcher) resolve(event fanotifyEvent) (string, bool) {
+	w.mu.Lock()
+	mountFd, ok := w.mountFds[event.fsid]
+	w.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+
+	fd, err := unix.OpenByHandleAt(mountFd, unix.NewFileHandle(event.handleType, event.handle), unix.O_PATH|unix.O_CLOEXEC)
+	if err != nil {
+		return "", false
+	}
+	dir, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(fd))
+	_ = unix.Close(fd)
+	if err != nil {
+		return "", false
+	}
+	return eventPath(dir, event.name), true
+}
+
+// eventPath 拼接事件路径，名称为 "." 表示目录本身
+func eventPath(dir, name string) string {
+	if name == "" || name == "." {
+		return dir
+	}
+	return filepath.Join(dir, name)
+}
+
+// run 读取 fanotify 事件，ctx 取消时关闭 fd 并退出
+func (w *fanotifyWatcher) run(ctx context.Context, handle func(path string, pid int32)) {
+	go func() {
+		<-ctx.Done()
+		_ = w.file.Close()
+		w.mu.Lock()
+		for fsid, fd := range w.mountFds {
+			_ = unix.Close(fd)
+			delete(w.mountFds, fsid)
+		}
+		w.mu.Unlock()
+	}()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				slog.Warn("读取 fanotify 事件失败", "error", err)
+			}
+			return
+		}
+
+		for _, event := range parseFanotifyEvents(buf[:n]) {
+			path, ok := w.resolve(event)
+			if !ok || !w.covers(path) {
+				continue
+			}
+			// 新建或移入的子目录需要单独标记，否则其中的修改无法归因
+			if event.mask&unix.FAN_ONDIR != 0 && event.mask&(unix.FAN_CREATE|unix.FAN_MOVED_TO) != 0 {
+				if err := w.markTree(path, unix.FAN_MARK_ADD); err != nil {
+					slog.Warn("标记新建目录失败", "path", path, "error", err)
+				}
+			}
+			handle(path, event.pid)
+		}
+	}
+}
+
+// parseFanotifyEvents 解析 FAN_REPORT_DFID_NAME 模式下读取到的事件（元数据后跟目录句柄和文件名）
+func parseFanotifyEvents(buf []byte) []fanotifyEvent {
+	var events []fanotifyEvent
+	for offset := 0; offset+fanotifyMetadataSize <= len(buf); {
+		data := buf[offset:]
+		eventLen := int(binary.NativeEndian.Uint32(data[0:4]))
+		metaLen := int(binary.NativeEndian.Uint16(data[6:8]))
+		if data[4] != unix.FANOTIFY_METADATA_VERSION || eventLen < fanotifyMetadataSize || eventLen > len(data) ||
+			metaLen < fanotifyMetadataSize || metaLen > eventLen {
+			break
+		}
+		offset += eventLen
+
+		mask := binary.NativeEndian.Uint64(data[8:16])
+		pid := int32(binary.NativeEndian.Uint32(data[20:24]))
+		// 队列溢出等事件不携带信息记录
+		for info := data[metaLen:eventLen]; len(info) >= 4; {
+			infoType := info[0]
+			infoLen := int(binary.NativeEndian.Uint16(info[2:4]))
+			if infoLen < 4 || infoLen > len(info) {
+				break
+			}
+			if infoType == unix.FAN_EVENT_INFO_TYPE_DFID_NAME {
+				if event, ok := parseDFIDName(info[4:infoLen]); ok {
+					event.mask = mask
+					event.pid = pid
+					events = append(events, event)
+				}
+			}
+			info = info[infoLen:]
+		}
+	}
+	return events
+}
+
+// parseDFIDName 解析 fanotify_event_info_fid：fsid、file_handle 和以 NUL 结尾的文件名
+func parseDFIDName(data []byte) (fanotifyEvent, bool) {
+	const headerSize = 16 // fsid(8) + handle_bytes(4) + handle_type(4)
+	if len(data) < headerSize {
+		return fanotifyEvent{}, false
+	}
+	var event fanotifyEvent
+	event.fsid.Val[0] = int32(binary.NativeEndian.Uint32(data[0:4]))
+	event.fsid.Val[1] = int32(binary.NativeEndian.Uint32(data[4:8]))
+	handleBytes := int(binary.NativeEndian.Uint32(data[8:12]))
+	event.handleType = int32(binary.NativeEndian.Uint32(data[12:16]))
+	if handleBytes > len(data)-headerSize {
+		return fanotifyEvent{}, false
+	}
+	event.handle = append([]byte(nil), data[headerSize:headerSize+handleBytes]...)
+
+	name := data[headerSize+handleBytes:]
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+	event.name = string(name)
+	return event, true
+}
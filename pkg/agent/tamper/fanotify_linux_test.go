@@ -0,0 +1,183 @@
+package tamper
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// buildFanotifyEvent 构造一个 FAN_REPORT_DFID_NAME 事件（元数据 + DFID_NAME 信息记录）
+func buildFanotifyEvent(mask uint64, pid int32, handle []byte, name string) []byte {
+	info := binary.NativeEndian.AppendUint32(nil, 11) // fsid
+	info = binary.NativeEndian.AppendUint32(info, 22)
+	info = binary.NativeEndian.AppendUint32(info, uint32(len(handle)))
+	info = binary.NativeEndian.AppendUint32(info, 1) // handle_type
+	info = append(info, handle...)
+	info = append(info, name...)
+	info = append(info, 0)
+	for (4+len(info))%8 != 0 {
+		info = append(info, 0)
+	}
+
+	eventLen := fanotifyMetadataSize + 4 + len(info)
+	buf := binary.NativeEndian.AppendUint32(nil, uint32(eventLen))
+	buf = append(buf, unix.FANOTIFY_METADATA_VERSION, 0)
+	buf = binary.NativeEndian.AppendUint16(buf, fanotifyMetadataSize)
+	buf = binary.NativeEndian.AppendUint64(buf, mask)
+	buf = binary.NativeEndian.AppendUint32(buf, uint32(0xffffffff)) // FAN_NOFD
+	buf = binary.NativeEndian.AppendUint32(buf, uint32(pid))
+	buf = append(buf, unix.FAN_EVENT_INFO_TYPE_DFID_NAME, 0)
+	buf = binary.NativeEndian.AppendUint16(buf, uint16(4+len(info)))
+	return append(buf, info...)
+}
+
+func TestParseFanotifyEvents(t *testing.T) {
+	buf := buildFanotifyEvent(unix.FAN_DELETE, 100, []byte{1, 2, 3, 4, 5, 6, 7, 8}, "index.html")
+	buf = append(buf, buildFanotifyEvent(unix.FAN_CREATE|unix.FAN_ONDIR, 200, []byte{9, 9, 9, 9}, "uploads")...)
+	// 截断的事件应被忽略
+	buf = append(buf, buildFanotifyEvent(unix.FAN_MODIFY, 300, []byte{1}, "x")[:20]...)
+
+	events := parseFanotifyEvents(buf)
+	if len(events) != 2 {
+		t.Fatalf("应解析出 2 个事件，实际 %d 个: %+v", len(events), events)
+	}
+	tests := []struct {
+		name   string
+		event  fanotifyEvent
+		mask   uint64
+		pid    int32
+		handle int
+		file   string
+	}{
+		{"删除文件", events[0], unix.FAN_DELETE, 100, 8, "index.html"},
+		{"创建目录", events[1], unix.FAN_CREATE | unix.FAN_ONDIR, 200, 4, "uploads"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.event.mask != tt.mask || tt.event.pid != tt.pid || tt.event.name != tt.file {
+				t.Errorf("事件解析错误: %+v", tt.event)
+			}
+			if len(tt.event.handle) != tt.handle || tt.event.handleType != 1 {
+				t.Errorf("文件句柄解析错误: %+v", tt.event)
+			}
+			if tt.event.fsid.Val != [2]int32{11, 22} {
+				t.Errorf("fsid = %v", tt.event.fsid.Val)
+			}
+		})
+	}
+}
+
+func TestEventPath(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		file string
+		want string
+	}{
+		{"目录中的文件", "/var/www", "index.html", "/var/www/index.html"},
+		{"目录本身", "/var/www", ".", "/var/www"},
+		{"无文件名", "/var/www", "", "/var/www"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventPath(tt.dir, tt.file); got != tt.want {
+				t.Errorf("eventPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFanotifyCovers(t *testing.T) {
+	w := &fanotifyWatcher{roots: map[string]bool{
+		"/var/www":        true,
+		"/etc/nginx.conf": false,
+	}}
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"受保护目录", "/var/www", true},
+		{"子目录中的文件", "/var/www/static/app.js", true},
+		{"前缀相同的其他目录", "/var/www2/index.html", false},
+		{"受保护文件", "/etc/nginx.conf", true},
+		{"受保护文件的同级文件", "/etc/passwd", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.covers(tt.path); got != tt.want {
+				t.Errorf("covers(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFanotifyWatcher 需要 root 权限和 Linux 5.9+，验证删除、重命名和新建子目录中的修改都能归因
+func TestFanotifyWatcher(t *testing.T) {
+	w, err := newFanotifyWatcher()
+	if err != nil {
+		t.Skipf("fanotify 不可用: %v", err)
+	}
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "index.html"), "original")
+	writeTestFile(t, filepath.Join(root, "old.html"), "old")
+	if err := w.addTree(root); err != nil {
+		t.Skipf("标记目录失败: %v", err)
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string]int32)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.run(ctx, func(path string, pid int32) {
+		mu.Lock()
+		seen[path] = pid
+		mu.Unlock()
+	})
+
+	if err := os.Remove(filepath.Join(root, "index.html")); err != nil {
+		t.Fatalf("删除文件失败: %v", err)
+	}
+	if err := os.Rename(filepath.Join(root, "old.html"), filepath.Join(root, "new.html")); err != nil {
+		t.Fatalf("重命名文件失败: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	// 等待新目录被标记
+	time.Sleep(200 * time.Millisecond)
+	writeTestFile(t, filepath.Join(root, "sub", "shell.php"), "<?php")
+
+	want := []string{
+		filepath.Join(root, "index.html"),
+		filepath.Join(root, "old.html"),
+		filepath.Join(root, "new.html"),
+		filepath.Join(root, "sub"),
+		filepath.Join(root, "sub", "shell.php"),
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for _, path := range want {
+		for {
+			mu.Lock()
+			pid, ok := seen[path]
+			mu.Unlock()
+			if ok {
+				if int(pid) != os.Getpid() {
+					t.Errorf("%s 的进程 PID = %d, want %d", path, pid, os.Getpid())
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("未收到 %s 的事件", path)
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
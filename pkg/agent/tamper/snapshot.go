@@ -0,0 +1,269 @@
+package tamper
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/pkg/agent/utils"
+)
+
+const (
+	snapshotDirName = "tamper_snapshots"
+	// maxSnapshotSize 单个受保护路径快照的最大原始大小，避免误将大目录纳入强制恢复
+	maxSnapshotSize = 512 << 20
+)
+
+// SnapshotEntry 快照中的条目
+type SnapshotEntry struct {
+	Type     byte        // tar.TypeReg / tar.TypeDir / tar.TypeSymlink
+	Hash     string      // SHA-256（仅普通文件）
+	Mode     os.FileMode // 文件权限
+	Linkname string      // 符号链接目标
+}
+
+// Snapshot 受保护路径的快照清单（内容保存在压缩包中，按需解压）
+type Snapshot struct {
+	Root      string
+	Entries   map[string]SnapshotEntry
+	CreatedAt time.Time
+}
+
+// SnapshotStore 受保护路径的压缩快照存储（tar.gz），每个受保护路径一个文件
+type SnapshotStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewSnapshotStore 创建快照存储
+func NewSnapshotStore(dir string) *SnapshotStore {
+	return &SnapshotStore{dir: dir}
+}
+
+// defaultSnapshotDir 默认快照目录
+func defaultSnapshotDir() string {
+	return filepath.Join(utils.GetSafeHomeDir(), ".pika", snapshotDirName)
+}
+
+// archivePath 受保护路径对应的快照文件
+func (s *SnapshotStore) archivePath(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+".tar.gz")
+}
+
+// Create 为受保护路径创建快照，覆盖已有快照
+func (s *SnapshotStore) Create(root string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建快照目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建快照文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	snapshot, err := writeSnapshot(tmp, root)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), s.archivePath(root)); err != nil {
+		return nil, fmt.Errorf("保存快照失败: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Load 读取受保护路径的快照清单，快照不存在时返回 nil
+func (s *SnapshotStore) Load(root string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archive := s.archivePath(root)
+	info, err := os.Stat(archive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Root:      root,
+		Entries:   make(map[string]SnapshotEntry),
+		CreatedAt: info.ModTime(),
+	}
+	err = s.walk(archive, func(hdr *tar.Header, r io.Reader) error {
+		entry := SnapshotEntry{
+			Type:     hdr.Typeflag,
+			Mode:     os.FileMode(hdr.Mode).Perm(),
+			Linkname: hdr.Linkname,
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			h := sha256.New()
+			if _, err := io.Copy(h, r); err != nil {
+				return err
+			}
+			entry.Hash = hex.EncodeToString(h.Sum(nil))
+		}
+		snapshot.Entries[hdr.Name] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取快照失败: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Extract 遍历快照，对需要恢复的条目调用 fn
+func (s *SnapshotStore) Extract(root string, paths map[string]bool, fn func(hdr *tar.Header, r io.Reader) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.walk(s.archivePath(root), func(hdr *tar.Header, r io.Reader) error {
+		if !paths[hdr.Name] {
+			return nil
+		}
+		return fn(hdr, r)
+	})
+}
+
+// Delete 删除受保护路径的快照
+func (s *SnapshotStore) Delete(root string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.archivePath(root)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *SnapshotStore) walk(archive string, fn func(hdr *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// writeSnapshot 将受保护路径打包写入 w，条目名称为绝对路径（父目录总在子条目之前）
+func writeSnapshot(w io.Writer, root string) (*Snapshot, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	snapshot := &Snapshot{
+		Root:      root,
+		Entries:   make(map[string]SnapshotEntry),
+		CreatedAt: time.Now(),
+	}
+
+	var total int64
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // 跳过无法访问的子路径
+		}
+
+		var link string
+		switch {
+		case info.Mode().IsRegular(), info.IsDir():
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return nil
+			}
+		default:
+			return nil // 跳过设备文件、管道等
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		entry := SnapshotEntry{
+			Type:     hdr.Typeflag,
+			Mode:     info.Mode().Perm(),
+			Linkname: link,
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+			if total > maxSnapshotSize {
+				return fmt.Errorf("受保护路径超过快照大小上限 %d MB", maxSnapshotSize>>20)
+			}
+			hash, err := copyFileHashed(tw, path)
+			if err != nil {
+				return err
+			}
+			entry.Hash = hash
+		}
+		snapshot.Entries[path] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建快照失败: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// copyFileHashed 复制文件内容到 w 并返回 SHA-256
+func copyFileHashed(w io.Writer, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
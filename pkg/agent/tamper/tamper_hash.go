@@ -0,0 +1,230 @@
+package tamper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// periodicHashCheck 定期校验所有受保护路径的哈希基线，并按快照恢复强制恢复路径
+func (p *Protector) periodicHashCheck(ctx context.Context, ticker *time.Ticker) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkAllHashes()
+			p.checkAllEnforced()
+		}
+	}
+}
+
+// checkAllHashes 校验所有受保护路径的哈希基线
+func (p *Protector) checkAllHashes() {
+	p.mu.RLock()
+	if !usesHash(p.mode) {
+		p.mu.RUnlock()
+		return
+	}
+	paths := p.getCurrentPaths()
+	p.mu.RUnlock()
+
+	for _, path := range paths {
+		if p.isUnlocked(path) {
+			continue
+		}
+		p.verifyRoot(path)
+	}
+}
+
+// buildBaseline 计算并保存受保护路径的哈希基线
+func (p *Protector) buildBaseline(root string) error {
+	records, err := scanTree(root)
+	if err != nil {
+		return err
+	}
+	if err := p.baseline.Replace(root, records); err != nil {
+		return err
+	}
+	p.clearReported(root)
+	slog.Info("已建立哈希基线", "path", root, "files", len(records))
+	return nil
+}
+
+// verifyRoot 全量校验受保护路径与基线的差异
+func (p *Protector) verifyRoot(root string) {
+	baseline, err := p.baseline.Load(root)
+	if err != nil {
+		slog.Warn("读取哈希基线失败", "path", root, "error", err)
+		return
+	}
+
+	current, err := scanTree(root)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("计算文件哈希失败", "path", root, "error", err)
+			return
+		}
+		// 受保护路径本身被删除，基线中的文件全部视为删除
+		current = map[string]FileRecord{}
+	}
+
+	paths := make([]string, 0, len(baseline)+len(current))
+	for path := range baseline {
+		paths = append(paths, path)
+	}
+	for path := range current {
+		if _, ok := baseline[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	emitted, suppressed := 0, 0
+	for _, path := range paths {
+		var oldRecord, newRecord *FileRecord
+		if record, ok := baseline[path]; ok {
+			oldRecord = &record
+		}
+		if record, ok := current[path]; ok {
+			newRecord = &record
+		}
+
+		event, changed := p.diffRecord(path, oldRecord, newRecord)
+		if !changed {
+			continue
+		}
+		if emitted >= maxHashEventsPerScan {
+			suppressed++
+			continue
+		}
+		p.emitEvent(*event)
+		emitted++
+	}
+
+	if suppressed > 0 {
+		p.emitEvent(TamperEvent{
+			Path:      root,
+			Operation: "modified",
+			Timestamp: time.Now(),
+			Details:   fmt.Sprintf("另有 %d 个文件与哈希基线不一致", suppressed),
+		})
+	}
+}
+
+// scheduleVerifyFile 延迟校验单个文件，合并短时间内的连续写入事件（如先截断再写入）
+func (p *Protector) scheduleVerifyFile(path string) {
+	p.reportMu.Lock()
+	defer p.reportMu.Unlock()
+
+	if timer, ok := p.pending[path]; ok {
+		timer.Reset(hashVerifyDelay)
+		return
+	}
+	p.pending[path] = time.AfterFunc(hashVerifyDelay, func() {
+		p.reportMu.Lock()
+		delete(p.pending, path)
+		p.reportMu.Unlock()
+		p.verifyFile(path)
+	})
+}
+
+// verifyFile 校验单个文件与基线的差异
+func (p *Protector) verifyFile(path string) {
+	root := p.findRoot(path)
+	if root == "" || p.isUnlocked(root) {
+		return
+	}
+
+	oldRecord, err := p.baseline.Get(root, path)
+	if err != nil {
+		slog.Warn("读取哈希基线失败", "path", path, "error", err)
+		return
+	}
+
+	newRecord, err := hashFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		slog.Warn("计算文件哈希失败", "path", path, "error", err)
+		return
+	}
+
+	if event, changed := p.diffRecord(path, oldRecord, newRecord); changed {
+		p.emitEvent(*event)
+	}
+}
+
+// diffRecord 比较基线记录与当前记录，同一差异只上报一次
+func (p *Protector) diffRecord(path string, oldRecord, newRecord *FileRecord) (*TamperEvent, bool) {
+	var oldHash, newHash string
+	if oldRecord != nil {
+		oldHash = oldRecord.Hash
+	}
+	if newRecord != nil {
+		newHash = newRecord.Hash
+	}
+
+	p.reportMu.Lock()
+	defer p.reportMu.Unlock()
+
+	if oldHash == newHash {
+		// 已恢复为基线内容
+		delete(p.reported, path)
+		return nil, false
+	}
+	if last, ok := p.reported[path]; ok && last == newHash {
+		return nil, false
+	}
+	p.reported[path] = newHash
+
+	event := &TamperEvent{
+		Path:      path,
+		Timestamp: time.Now(),
+		OldHash:   oldHash,
+		NewHash:   newHash,
+	}
+	switch {
+	case oldRecord == nil:
+		event.Operation = "added"
+		event.Details = "新增基线外的文件"
+	case newRecord == nil:
+		event.Operation = "deleted"
+		event.Details = "基线中的文件被删除"
+	default:
+		event.Operation = "modified"
+		event.Details = "文件内容与哈希基线不一致"
+	}
+	return event, true
+}
+
+// findRoot 查找路径所属的受保护路径（最长匹配）
+func (p *Protector) findRoot(path string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var root string
+	for protected := range p.paths {
+		if path == protected || strings.HasPrefix(path, strings.TrimSuffix(protected, "/")+"/") {
+			if len(protected) > len(root) {
+				root = protected
+			}
+		}
+	}
+	return root
+}
+
+// clearReported 清除受保护路径下已上报的差异记录
+func (p *Protector) clearReported(root string) {
+	prefix := strings.TrimSuffix(root, "/") + "/"
+
+	p.reportMu.Lock()
+	defer p.reportMu.Unlock()
+	for path := range p.reported {
+		if path == root || strings.HasPrefix(path, prefix) {
+			delete(p.reported, path)
+		}
+	}
+}
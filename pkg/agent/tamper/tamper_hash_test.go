@@ -0,0 +1,88 @@
+package tamper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestBaselineStoreReopen(t *testing.T) {
+	store := NewBaselineStore(filepath.Join(t.TempDir(), "baseline.db"))
+
+	exists, err := store.Has("/var/www")
+	if err != nil || exists {
+		t.Fatalf("空存储 Has() = %v, %v", exists, err)
+	}
+	if _, err := os.Stat(store.path); !os.IsNotExist(err) {
+		t.Errorf("只读操作不应创建数据库文件")
+	}
+
+	records := map[string]FileRecord{"/var/www/index.html": {Hash: "abc", Size: 3}}
+	if err := store.Replace("/var/www", records); err != nil {
+		t.Fatalf("Replace() 失败: %v", err)
+	}
+	if store.db == nil {
+		t.Fatalf("写入后数据库应保持打开")
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() 失败: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Errorf("重复 Close() 不应报错: %v", err)
+	}
+
+	// 关闭后读取会重新打开
+	record, err := store.Get("/var/www", "/var/www/index.html")
+	if err != nil || record == nil || record.Hash != "abc" {
+		t.Errorf("重新打开后 Get() = %+v, %v", record, err)
+	}
+	_ = store.Close()
+}
+
+// waitEvent 等待指定路径和操作的防篡改事件
+func waitEvent(t *testing.T, p *Protector, path, operation string) {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case event := <-p.eventCh:
+			if event.Path == path && event.Operation == operation {
+				return
+			}
+		case <-timeout:
+			t.Errorf("未收到 %s 的 %s 事件", path, operation)
+			return
+		}
+	}
+}
+
+func TestHashModeWatchesSubdirectories(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("防篡改功能仅支持 Linux 系统")
+	}
+	p := newTestProtector(t, ModeHash)
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "static", "js"), 0755); err != nil {
+		t.Fatalf("创建测试目录失败: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, "static", "js", "app.js"), "console.log(1)")
+
+	if _, err := p.UpdatePaths(context.Background(), []string{root}); err != nil {
+		t.Fatalf("UpdatePaths() 失败: %v", err)
+	}
+	defer p.StopAll()
+
+	// 已有子目录中的修改
+	writeTestFile(t, filepath.Join(root, "static", "js", "app.js"), "hacked")
+	waitEvent(t, p, filepath.Join(root, "static", "js", "app.js"), "modified")
+
+	// 新建子目录后立即写入的文件
+	if err := os.Mkdir(filepath.Join(root, "uploads"), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, "uploads", "shell.php"), "<?php")
+	waitEvent(t, p, filepath.Join(root, "uploads", "shell.php"), "added")
+}
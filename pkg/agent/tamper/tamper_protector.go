@@ -3,22 +3,50 @@ package tamper
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// 保护模式
+const (
+	ModeImmutable = "immutable" // 不可变属性(chattr +i)，默认模式
+	ModeHash      = "hash"      // SHA-256 哈希基线校验，适用于不支持不可变属性的文件系统
+	ModeHybrid    = "hybrid"    // 不可变属性 + 哈希基线校验
+)
+
+const (
+	defaultHashInterval = 5 * time.Minute
+	minHashInterval     = 30 * time.Second
+	// maxHashEventsPerScan 单次全量校验最多上报的文件级事件数，超出部分汇总为一条事件
+	maxHashEventsPerScan = 50
+	// hashVerifyDelay 文件事件触发哈希校验的延迟，用于合并连续写入
+	hashVerifyDelay = 500 * time.Millisecond
+)
+
 // TamperEvent 防篡改事件
 type TamperEvent struct {
-	Path      string    `json:"path"`      // 被修改的路径
-	Operation string    `json:"operation"` // 操作类型: write, remove, rename, chmod
-	Timestamp time.Time `json:"timestamp"` // 事件时间
-	Details   string    `json:"details"`   // 详细信息
+	Path      string       `json:"path"`               // 被修改的路径
+	Operation string       `json:"operation"`          // 操作类型: write, remove, rename, chmod, modified, added, deleted, restore, unlock, relock
+	Timestamp time.Time    `json:"timestamp"`          // 事件时间
+	Details   string       `json:"details"`            // 详细信息
+	OldHash   string       `json:"oldHash,omitempty"`  // 基线中的 SHA-256（哈希模式）；restore 时为被篡改的内容
+	NewHash   string       `json:"newHash,omitempty"`  // 当前的 SHA-256（哈希模式）；restore 时为快照中的内容
+	Restored  bool         `json:"restored,omitempty"` // 是否已按快照恢复（仅 restore）
+	Process   *ProcessInfo `json:"process,omitempty"`  // 修改文件的进程（fanotify/audit 归因，可能为空）
+}
+
+// Options 保护选项
+type Options struct {
+	Mode         string        // 保护模式: immutable/hash/hybrid
+	HashInterval time.Duration // 哈希基线定期校验间隔
 }
 
 // AttributeTamperAlert 属性篡改告警
@@ -47,15 +75,147 @@ type Protector struct {
 	alertCh     chan AttributeTamperAlert // 属性篡改告警通道
 	watcherOnce sync.Once                 // 确保 watcher 只创建一次
 	checkTicker *time.Ticker              // 属性检查定时器
+	hashTicker  *time.Ticker              // 哈希基线校验定时器
+
+	mode         string         // 保护模式
+	hashInterval time.Duration  // 哈希基线校验间隔
+	baseline     *BaselineStore // 哈希基线存储
+
+	reportMu sync.Mutex
+	reported map[string]string      // 已上报的哈希差异(路径 -> 当前哈希，删除为空)，避免重复上报
+	pending  map[string]*time.Timer // 等待校验的文件
+
+	enforce        map[string]bool        // 启用强制恢复策略的受保护路径
+	snapshots      *SnapshotStore         // 压缩快照存储
+	restoreMu      sync.Mutex             // 串行化恢复操作
+	manifests      map[string]*Snapshot   // 已加载的快照清单
+	pendingRestore map[string]*time.Timer // 等待恢复的路径
+	restoringMu    sync.Mutex
+	restoring      map[string]time.Time // 正在恢复的路径 -> 忽略其文件事件的截止时间
+
+	unlockMu     sync.Mutex
+	unlocked     map[string]time.Time   // 处于临时解锁窗口的路径 -> 到期时间
+	relockTimers map[string]*time.Timer // 到期自动重新保护的定时器
+
+	attr   *attributor       // 进程归因，未启用时为 nil
+	source attributionSource // 进程归因事件来源（fanotify 或 audit netlink），不可用时为 nil
 }
 
 // NewProtector 创建防篡改保护器
 func NewProtector() *Protector {
 	return &Protector{
-		paths:   make(map[string]bool),
-		eventCh: make(chan TamperEvent, 100),
-		alertCh: make(chan AttributeTamperAlert, 50),
+		paths:        make(map[string]bool),
+		eventCh:      make(chan TamperEvent, 100),
+		alertCh:      make(chan AttributeTamperAlert, 50),
+		mode:         ModeImmutable,
+		hashInterval: defaultHashInterval,
+		baseline:     NewBaselineStore(defaultBaselinePath()),
+		reported:     make(map[string]string),
+		pending:      make(map[string]*time.Timer),
+
+		enforce:        make(map[string]bool),
+		snapshots:      NewSnapshotStore(defaultSnapshotDir()),
+		manifests:      make(map[string]*Snapshot),
+		pendingRestore: make(map[string]*time.Timer),
+		restoring:      make(map[string]time.Time),
+
+		unlocked:     make(map[string]time.Time),
+		relockTimers: make(map[string]*time.Timer),
+	}
+}
+
+// SetOptions 设置保护选项，模式变化时对已保护的路径进行切换
+func (p *Protector) SetOptions(opts Options) error {
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeImmutable
+	}
+	if mode != ModeImmutable && mode != ModeHash && mode != ModeHybrid {
+		return fmt.Errorf("不支持的保护模式: %s", opts.Mode)
+	}
+	interval := opts.HashInterval
+	if interval <= 0 {
+		interval = defaultHashInterval
+	}
+	if interval < minHashInterval {
+		interval = minHashInterval
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if interval != p.hashInterval {
+		p.hashInterval = interval
+		if p.hashTicker != nil {
+			p.hashTicker.Reset(interval)
+		}
+	}
+
+	if mode == p.mode {
+		return nil
 	}
+
+	oldMode := p.mode
+	p.mode = mode
+	slog.Info("防篡改保护模式已切换", "from", oldMode, "to", mode)
+
+	var lastErr error
+	for path := range p.paths {
+		// 临时解锁中的路径在重新保护时按新模式处理
+		if p.isUnlocked(path) {
+			continue
+		}
+
+		// 不可变属性切换
+		if usesImmutable(oldMode) && !usesImmutable(mode) {
+			if err := p.setImmutableAll(path, false); err != nil {
+				slog.Warn("移除不可变属性失败", "path", path, "error", err)
+				lastErr = err
+			}
+		} else if !usesImmutable(oldMode) && usesImmutable(mode) {
+			if err := p.setImmutableAll(path, true); err != nil {
+				slog.Warn("设置不可变属性失败", "path", path, "error", err)
+				lastErr = err
+			}
+		}
+
+		// 哈希基线切换
+		if usesHash(oldMode) && !usesHash(mode) {
+			p.clearReported(path)
+			if err := p.baseline.Delete(path); err != nil {
+				slog.Warn("删除哈希基线失败", "path", path, "error", err)
+				lastErr = err
+			}
+		} else if !usesHash(oldMode) && usesHash(mode) {
+			if err := p.buildBaseline(path); err != nil {
+				slog.Warn("建立哈希基线失败", "path", path, "error", err)
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// EnableAttribution 启用进程归因（Linux 下基于 fanotify，内核不支持时回退到 audit netlink，需要 root 权限），需在添加保护目录之前调用
+func (p *Protector) EnableAttribution(buildTree ProcessTreeFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attr = newAttributor(buildTree)
+}
+
+// GetMode 获取当前保护模式
+func (p *Protector) GetMode() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.mode
+}
+
+func usesImmutable(mode string) bool {
+	return mode == ModeImmutable || mode == ModeHybrid
+}
+
+func usesHash(mode string) bool {
+	return mode == ModeHash || mode == ModeHybrid
 }
 
 // ApplyIncrementalUpdate 应用增量更新（服务端已计算好新增和移除）
@@ -238,13 +398,18 @@ func (p *Protector) StopAll() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	// 基线数据库在下次保护时重新打开
+	var lastErr error
+	if err := p.baseline.Close(); err != nil {
+		slog.Warn("关闭哈希基线数据库失败", "error", err)
+		lastErr = err
+	}
+
 	if len(p.paths) == 0 {
 		slog.Info("没有正在保护的目录")
-		return nil
+		return lastErr
 	}
 
-	var lastErr error
-
 	// 取消 context
 	if p.cancel != nil {
 		p.cancel()
@@ -256,6 +421,10 @@ func (p *Protector) StopAll() error {
 		p.checkTicker.Stop()
 		p.checkTicker = nil
 	}
+	if p.hashTicker != nil {
+		p.hashTicker.Stop()
+		p.hashTicker = nil
+	}
 
 	// 关闭监控器
 	if p.watcher != nil {
@@ -266,9 +435,23 @@ func (p *Protector) StopAll() error {
 		p.watcher = nil
 		p.watcherOnce = sync.Once{} // 重置,允许下次重新创建
 	}
+	// 进程归因来源在 context 取消后自行关闭
+	p.source = nil
+
+	// 停止解锁定时器（持久化的解锁窗口保留，重新保护时恢复）
+	p.unlockMu.Lock()
+	for path, timer := range p.relockTimers {
+		timer.Stop()
+		delete(p.relockTimers, path)
+	}
+	p.unlocked = make(map[string]time.Time)
+	p.unlockMu.Unlock()
 
-	// 移除所有目录的不可变属性
+	// 移除所有目录的不可变属性（哈希基线保留，便于重新保护时发现停止期间的变化）
 	for path := range p.paths {
+		if !usesImmutable(p.mode) {
+			continue
+		}
 		if err := p.setImmutable(path, false); err != nil {
 			slog.Warn("移除目录不可变属性失败", "path", path, "error", err)
 			lastErr = err
@@ -277,8 +460,11 @@ func (p *Protector) StopAll() error {
 		}
 	}
 
-	// 清空路径列表
+	// 清空路径列表，快照保留但需在重新保护时重新加载
 	p.paths = make(map[string]bool)
+	p.restoreMu.Lock()
+	p.manifests = make(map[string]*Snapshot)
+	p.restoreMu.Unlock()
 
 	slog.Info("已停止所有防篡改保护")
 	return lastErr
@@ -331,12 +517,27 @@ func (p *Protector) initWatcher(ctx context.Context) error {
 		// 创建 context
 		p.ctx, p.cancel = context.WithCancel(ctx)
 
-		// 启动监控循环
-		go p.watchLoop()
+		// 启动监控循环（goroutine 持有自己的引用，避免 StopAll 置空字段后访问空指针）
+		go p.watchLoop(p.ctx, p.watcher)
 
 		// 启动定期属性检查
 		p.checkTicker = time.NewTicker(5 * time.Second)
-		go p.periodicAttributeCheck()
+		go p.periodicAttributeCheck(p.ctx, p.checkTicker)
+
+		// 启动定期哈希基线校验和快照恢复
+		p.hashTicker = time.NewTicker(p.hashInterval)
+		go p.periodicHashCheck(p.ctx, p.hashTicker)
+
+		// 启动进程归因
+		if p.attr != nil {
+			source, sourceErr := newAttributionSource()
+			if sourceErr != nil {
+				slog.Warn("进程归因不可用，防篡改事件将不包含进程信息", "error", sourceErr)
+			} else {
+				p.source = source
+				go source.run(p.ctx, p.attr.record)
+			}
+		}
 
 		slog.Info("文件监控器已启动")
 	})
@@ -351,42 +552,188 @@ func (p *Protector) addPath(path string) error {
 		return fmt.Errorf("无法访问路径: %w", err)
 	}
 
-	// 如果是目录，递归设置所有文件和子目录的不可变属性
-	if info.IsDir() {
-		if err := p.setImmutableRecursive(path, true); err != nil {
-			return fmt.Errorf("递归设置目录不可变属性失败: %w", err)
+	// 探针重启前处于临时解锁窗口：窗口未结束时保持解锁，已结束时以当前内容重建基线和快照
+	unlocked, rebaseline := p.resumeUnlock(path)
+	if unlocked {
+		if p.watcher != nil {
+			if err := p.addWatch(path, info.IsDir()); err != nil {
+				p.stopUnlock(path)
+				return fmt.Errorf("添加路径到监控失败: %w", err)
+			}
 		}
-	} else {
-		// 单个文件，直接设置不可变属性
-		if err := p.setImmutable(path, true); err != nil {
-			return fmt.Errorf("设置文件不可变属性失败: %w", err)
+		p.markAttribution(path)
+		return nil
+	}
+	if rebaseline {
+		p.dropSnapshot(path)
+	}
+
+	// 哈希模式：已有基线时先校验（可发现未受保护期间的变化），否则建立基线
+	// 需在设置不可变属性之前完成，失败时无需回滚
+	if usesHash(p.mode) {
+		exists, err := p.baseline.Has(path)
+		if err != nil {
+			return fmt.Errorf("读取哈希基线失败: %w", err)
+		}
+		if exists && !rebaseline {
+			p.verifyRoot(path)
+		} else if err := p.buildBaseline(path); err != nil {
+			return fmt.Errorf("建立哈希基线失败: %w", err)
+		}
+	}
+
+	if usesImmutable(p.mode) {
+		if info.IsDir() {
+			// 如果是目录，递归设置所有文件和子目录的不可变属性
+			if err := p.setImmutableRecursive(path, true); err != nil {
+				return fmt.Errorf("递归设置目录不可变属性失败: %w", err)
+			}
+		} else {
+			// 单个文件，直接设置不可变属性
+			if err := p.setImmutable(path, true); err != nil {
+				return fmt.Errorf("设置文件不可变属性失败: %w", err)
+			}
 		}
 	}
 
 	// 添加到监控
 	if p.watcher != nil {
-		if err := p.watcher.Add(path); err != nil {
+		if err := p.addWatch(path, info.IsDir()); err != nil {
 			// 如果添加监控失败,尝试回滚不可变属性
-			if info.IsDir() {
-				_ = p.setImmutableRecursive(path, false)
-			} else {
-				_ = p.setImmutable(path, false)
+			if usesImmutable(p.mode) {
+				_ = p.setImmutableAll(path, false)
 			}
 			return fmt.Errorf("添加路径到监控失败: %w", err)
 		}
 	}
+	p.markAttribution(path)
 
 	return nil
 }
 
+// addWatch 将路径加入文件监控，目录需逐个添加子目录（inotify 不递归）
+func (p *Protector) addWatch(path string, isDir bool) error {
+	if !isDir {
+		return p.watcher.Add(path)
+	}
+	return watchTree(p.watcher, path)
+}
+
+// removeWatch 从文件监控中移除路径及其子目录，嵌套的其他受保护路径保留(内部方法,不加锁)
+func (p *Protector) removeWatch(path string) {
+	for _, watched := range p.watcher.WatchList() {
+		if !isUnder(watched, path) {
+			continue
+		}
+		nested := false
+		for protected := range p.paths {
+			if protected != path && len(protected) > len(path) && isUnder(watched, protected) {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+		if err := p.watcher.Remove(watched); err != nil {
+			slog.Warn("从监控中移除路径失败", "path", watched, "error", err)
+			// 继续执行,不返回错误
+		}
+	}
+}
+
+// watchTree 监控目录及其所有子目录，子目录添加失败（如超出 inotify 监控数上限）时依赖定期校验
+func watchTree(watcher *fsnotify.Watcher, root string) error {
+	if err := watcher.Add(root); err != nil {
+		return err
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			slog.Warn("添加子目录到监控失败", "path", path, "error", err)
+		}
+		return nil
+	})
+}
+
+// isUnder 判断 path 是否为 root 本身或位于 root 之下
+func isUnder(path, root string) bool {
+	return path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/")
+}
+
+// watchNewDir 将受保护路径下新建（或移入）的目录加入监控
+// 目录加入监控前可能已写入文件，哈希模式下补充校验其中的文件
+func (p *Protector) watchNewDir(watcher *fsnotify.Watcher, path string) {
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return
+	}
+	root := p.findRoot(path)
+	if root == "" {
+		return
+	}
+	if err := watchTree(watcher, path); err != nil {
+		slog.Warn("添加新建目录到监控失败", "path", path, "error", err)
+		return
+	}
+	if !usesHash(p.GetMode()) || p.isUnlocked(root) {
+		return
+	}
+	_ = filepath.WalkDir(path, func(entry string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			p.scheduleVerifyFile(entry)
+		}
+		return nil
+	})
+}
+
+// markAttribution 将路径加入进程归因监控，失败不影响保护
+func (p *Protector) markAttribution(path string) {
+	if p.source == nil {
+		return
+	}
+	if err := p.source.addTree(path); err != nil {
+		slog.Warn("添加进程归因监控失败", "path", path, "error", err)
+	}
+}
+
+// setImmutableAll 设置或移除路径（文件或目录树）的不可变属性
+func (p *Protector) setImmutableAll(path string, immutable bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("无法访问路径: %w", err)
+	}
+	if info.IsDir() {
+		return p.setImmutableRecursive(path, immutable)
+	}
+	return p.setImmutable(path, immutable)
+}
+
 // removePath 移除目录保护(内部方法,不加锁)
 func (p *Protector) removePath(path string) error {
 	// 从监控中移除
 	if p.watcher != nil {
-		if err := p.watcher.Remove(path); err != nil {
-			slog.Warn("从监控中移除路径失败", "error", err)
-			// 继续执行,不返回错误
-		}
+		p.removeWatch(path)
+	}
+	if p.source != nil {
+		_ = p.source.removeTree(path)
+	}
+
+	// 取消保护时删除哈希基线、快照和解锁窗口
+	p.stopUnlock(path)
+	if err := p.baseline.DeleteUnlock(path); err != nil {
+		slog.Warn("删除解锁状态失败", "path", path, "error", err)
+	}
+	p.clearReported(path)
+	if err := p.baseline.Delete(path); err != nil {
+		slog.Warn("删除哈希基线失败", "path", path, "error", err)
+	}
+	p.dropSnapshot(path)
+
+	if !usesImmutable(p.mode) {
+		return nil
 	}
 
 	// 检查路径是否存在
@@ -416,17 +763,20 @@ func (p *Protector) removePath(path string) error {
 }
 
 // watchLoop 监控循环
-func (p *Protector) watchLoop() {
+func (p *Protector) watchLoop(ctx context.Context, watcher *fsnotify.Watcher) {
 	for {
 		select {
-		case <-p.ctx.Done():
+		case <-ctx.Done():
 			return
-		case event, ok := <-p.watcher.Events:
+		case event, ok := <-watcher.Events:
 			if !ok {
 				return
 			}
 			p.handleEvent(event)
-		case err, ok := <-p.watcher.Errors:
+			if event.Has(fsnotify.Create) {
+				p.watchNewDir(watcher, event.Name)
+			}
+		case err, ok := <-watcher.Errors:
 			if !ok {
 				return
 			}
@@ -437,6 +787,38 @@ func (p *Protector) watchLoop() {
 
 // handleEvent 处理文件系统事件
 func (p *Protector) handleEvent(event fsnotify.Event) {
+	// 恢复文件时写入的临时文件
+	if strings.HasPrefix(filepath.Base(event.Name), restoreTempPrefix) {
+		return
+	}
+	mode := p.GetMode()
+
+	// 临时解锁窗口内的变化不上报
+	if p.isUnlocked(p.findRoot(event.Name)) {
+		return
+	}
+
+	// 探针自身恢复文件产生的事件不再恢复和上报，哈希模式仍需校验以更新上报状态
+	restoring := p.isRestoring(event.Name)
+
+	// 强制恢复策略：按快照回滚被修改、新增或删除的文件
+	if !restoring && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+		if root, _ := p.enforcedRoot(event.Name); root != "" {
+			p.scheduleRestore(event.Name)
+		}
+	}
+
+	// 哈希模式下根据内容变化精确上报，不再上报原始文件事件
+	if usesHash(mode) && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+		p.scheduleVerifyFile(event.Name)
+		if mode == ModeHash {
+			return
+		}
+	}
+	if mode == ModeHash || restoring {
+		return
+	}
+
 	var operation string
 	var details string
 
@@ -468,22 +850,30 @@ func (p *Protector) handleEvent(event fsnotify.Event) {
 		Details:   details,
 	}
 
-	// 发送事件(非阻塞)
+	p.emitEvent(tamperEvent)
+}
+
+// emitEvent 发送事件(非阻塞)
+func (p *Protector) emitEvent(event TamperEvent) {
+	if event.Process == nil && p.attr != nil {
+		event.Process = p.attr.lookup(event.Path)
+	}
+
 	select {
-	case p.eventCh <- tamperEvent:
-		slog.Warn("检测到文件变动", "path", event.Name, "operation", operation, "details", details)
+	case p.eventCh <- event:
+		slog.Warn("检测到文件变动", "path", event.Path, "operation", event.Operation, "details", event.Details)
 	default:
-		slog.Warn("事件队列已满,丢弃事件", "path", event.Name)
+		slog.Warn("事件队列已满,丢弃事件", "path", event.Path)
 	}
 }
 
 // periodicAttributeCheck 定期检查所有受保护目录的不可变属性
-func (p *Protector) periodicAttributeCheck() {
+func (p *Protector) periodicAttributeCheck(ctx context.Context, ticker *time.Ticker) {
 	for {
 		select {
-		case <-p.ctx.Done():
+		case <-ctx.Done():
 			return
-		case <-p.checkTicker.C:
+		case <-ticker.C:
 			p.checkAllAttributes()
 		}
 	}
@@ -492,6 +882,10 @@ func (p *Protector) periodicAttributeCheck() {
 // checkAllAttributes 检查所有受保护目录的属性
 func (p *Protector) checkAllAttributes() {
 	p.mu.RLock()
+	if !usesImmutable(p.mode) {
+		p.mu.RUnlock()
+		return
+	}
 	paths := make([]string, 0, len(p.paths))
 	for path := range p.paths {
 		paths = append(paths, path)
@@ -499,6 +893,9 @@ func (p *Protector) checkAllAttributes() {
 	p.mu.RUnlock()
 
 	for _, path := range paths {
+		if p.isUnlocked(path) {
+			continue
+		}
 		p.checkAndRestoreImmutable(path)
 	}
 }
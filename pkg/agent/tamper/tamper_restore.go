@@ -0,0 +1,516 @@
+package tamper
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// restoreTempPrefix 恢复文件时使用的临时文件前缀，恢复逻辑会忽略此类文件
+const restoreTempPrefix = ".pika-restore-"
+
+// restoreEventGrace 恢复完成后继续忽略该路径文件事件的时长，文件事件异步到达
+const restoreEventGrace = 2 * time.Second
+
+// SetEnforcePaths 设置启用强制恢复策略的受保护路径（完整列表）
+// 新启用的路径已有快照时先按快照恢复（可回滚未受保护期间的变化），否则以当前内容创建快照
+func (p *Protector) SetEnforcePaths(paths []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	enforce := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		enforce[path] = true
+	}
+	p.enforce = enforce
+
+	immutable := usesImmutable(p.mode)
+	var lastErr error
+	for path := range p.paths {
+		if enforce[path] {
+			if err := p.ensureSnapshot(path, immutable); err != nil {
+				slog.Warn("建立防篡改快照失败", "path", path, "error", err)
+				lastErr = err
+			}
+		} else {
+			p.dropSnapshot(path)
+		}
+	}
+	return lastErr
+}
+
+// RefreshSnapshots 以当前内容重新创建快照（同时重建哈希基线），paths 为空时刷新所有强制恢复路径
+func (p *Protector) RefreshSnapshots(paths []string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(paths) == 0 {
+		for path := range p.enforce {
+			paths = append(paths, path)
+		}
+	}
+
+	var refreshed []string
+	var lastErr error
+	for _, path := range paths {
+		if !p.paths[path] || !p.enforce[path] {
+			lastErr = fmt.Errorf("路径未启用强制恢复: %s", path)
+			continue
+		}
+		if err := p.createSnapshot(path); err != nil {
+			slog.Warn("刷新防篡改快照失败", "path", path, "error", err)
+			lastErr = err
+			continue
+		}
+		if usesHash(p.mode) {
+			if err := p.buildBaseline(path); err != nil {
+				slog.Warn("重建哈希基线失败", "path", path, "error", err)
+			}
+		}
+		refreshed = append(refreshed, path)
+	}
+	return refreshed, lastErr
+}
+
+// ensureSnapshot 加载快照并按快照恢复，快照不存在时创建(内部方法,调用方持有 p.mu)
+func (p *Protector) ensureSnapshot(root string, immutable bool) error {
+	p.restoreMu.Lock()
+	_, loaded := p.manifests[root]
+	p.restoreMu.Unlock()
+	if loaded {
+		return nil
+	}
+
+	snapshot, err := p.snapshots.Load(root)
+	if err != nil {
+		return err
+	}
+	if snapshot == nil {
+		return p.createSnapshot(root)
+	}
+
+	p.restoreMu.Lock()
+	p.manifests[root] = snapshot
+	p.restoreMu.Unlock()
+
+	p.enforceRoot(root, immutable)
+	return nil
+}
+
+// createSnapshot 创建快照并更新内存中的清单
+func (p *Protector) createSnapshot(root string) error {
+	p.restoreMu.Lock()
+	defer p.restoreMu.Unlock()
+
+	snapshot, err := p.snapshots.Create(root)
+	if err != nil {
+		return err
+	}
+	p.manifests[root] = snapshot
+	slog.Info("已建立防篡改快照", "path", root, "entries", len(snapshot.Entries))
+	return nil
+}
+
+// dropSnapshot 删除快照
+func (p *Protector) dropSnapshot(root string) {
+	p.restoreMu.Lock()
+	defer p.restoreMu.Unlock()
+
+	delete(p.manifests, root)
+	if err := p.snapshots.Delete(root); err != nil {
+		slog.Warn("删除防篡改快照失败", "path", root, "error", err)
+	}
+}
+
+// enforcedRoot 返回路径所属的强制恢复受保护路径以及是否使用不可变属性
+func (p *Protector) enforcedRoot(path string) (string, bool) {
+	root := p.findRoot(path)
+	if root == "" || p.isUnlocked(root) {
+		return "", false
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !p.enforce[root] {
+		return "", false
+	}
+	return root, usesImmutable(p.mode)
+}
+
+// checkAllEnforced 定期按快照恢复所有强制恢复路径（文件监控不递归子目录，依赖此处兜底）
+func (p *Protector) checkAllEnforced() {
+	p.mu.RLock()
+	immutable := usesImmutable(p.mode)
+	var roots []string
+	for path := range p.paths {
+		if p.enforce[path] {
+			roots = append(roots, path)
+		}
+	}
+	p.mu.RUnlock()
+
+	for _, root := range roots {
+		p.enforceRoot(root, immutable)
+	}
+}
+
+// scheduleRestore 延迟恢复单个路径，合并短时间内的连续事件
+func (p *Protector) scheduleRestore(path string) {
+	if strings.HasPrefix(filepath.Base(path), restoreTempPrefix) {
+		return
+	}
+
+	p.restoreMu.Lock()
+	defer p.restoreMu.Unlock()
+
+	if timer, ok := p.pendingRestore[path]; ok {
+		timer.Reset(hashVerifyDelay)
+		return
+	}
+	p.pendingRestore[path] = time.AfterFunc(hashVerifyDelay, func() {
+		p.restoreMu.Lock()
+		delete(p.pendingRestore, path)
+		p.restoreMu.Unlock()
+		p.restorePath(path)
+	})
+}
+
+// restorePath 按快照恢复单个路径
+func (p *Protector) restorePath(path string) {
+	root, immutable := p.enforcedRoot(path)
+	if root == "" {
+		return
+	}
+
+	p.restoreMu.Lock()
+	snapshot := p.manifests[root]
+	p.restoreMu.Unlock()
+	if snapshot == nil {
+		return
+	}
+
+	entry, ok := snapshot.Entries[path]
+	if ok && entry.Type == tar.TypeDir {
+		// 目录被删除或替换时需要恢复整个子树
+		if info, err := os.Lstat(path); err != nil || !info.IsDir() || info.Mode().Perm() != entry.Mode {
+			p.enforceRoot(root, immutable)
+		}
+		return
+	}
+
+	p.restoreMu.Lock()
+	defer p.restoreMu.Unlock()
+
+	if !ok {
+		if _, err := os.Lstat(path); err != nil {
+			return
+		}
+		p.emitEvent(p.removeExtra(path, immutable))
+		return
+	}
+
+	if matched, currentHash := entryMatches(path, entry); !matched {
+		for _, event := range p.restoreEntries(root, map[string]string{path: currentHash}, snapshot, immutable) {
+			p.emitEvent(event)
+		}
+	}
+}
+
+// enforceRoot 全量比对受保护路径与快照，删除快照外的条目并恢复被修改或删除的条目
+func (p *Protector) enforceRoot(root string, immutable bool) {
+	if p.isUnlocked(root) {
+		return
+	}
+
+	p.restoreMu.Lock()
+	defer p.restoreMu.Unlock()
+
+	snapshot := p.manifests[root]
+	if snapshot == nil {
+		return
+	}
+
+	var events []TamperEvent
+
+	// 删除快照外新增的文件和目录
+	var extras []string
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if _, ok := snapshot.Entries[path]; ok {
+			return nil
+		}
+		extras = append(extras, path)
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	for _, path := range extras {
+		events = append(events, p.removeExtra(path, immutable))
+	}
+
+	// 恢复被修改或删除的条目
+	targets := make(map[string]string)
+	for path, entry := range snapshot.Entries {
+		if matched, currentHash := entryMatches(path, entry); !matched {
+			targets[path] = currentHash
+		}
+	}
+	if len(targets) > 0 {
+		events = append(events, p.restoreEntries(root, targets, snapshot, immutable)...)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+	for i, event := range events {
+		if i >= maxHashEventsPerScan {
+			p.emitEvent(TamperEvent{
+				Path:      root,
+				Operation: "restore",
+				Timestamp: time.Now(),
+				Details:   fmt.Sprintf("另有 %d 个路径已按快照恢复", len(events)-maxHashEventsPerScan),
+				Restored:  true,
+			})
+			break
+		}
+		p.emitEvent(event)
+	}
+}
+
+// markRestoring 标记路径正在恢复，恢复前后各调用一次，截止时间从最后一次调用算起
+func (p *Protector) markRestoring(path string) {
+	p.restoringMu.Lock()
+	defer p.restoringMu.Unlock()
+
+	now := time.Now()
+	for restoring, until := range p.restoring {
+		if now.After(until) {
+			delete(p.restoring, restoring)
+		}
+	}
+	p.restoring[path] = now.Add(restoreEventGrace)
+}
+
+// isRestoring 判断路径是否正在由探针恢复
+func (p *Protector) isRestoring(path string) bool {
+	p.restoringMu.Lock()
+	defer p.restoringMu.Unlock()
+
+	until, ok := p.restoring[path]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(p.restoring, path)
+		return false
+	}
+	return true
+}
+
+// removeExtra 删除快照外新增的路径(调用方持有 restoreMu)
+func (p *Protector) removeExtra(path string, immutable bool) TamperEvent {
+	event := TamperEvent{
+		Path:      path,
+		Operation: "restore",
+		Timestamp: time.Now(),
+	}
+	if record, err := hashFile(path); err == nil && record != nil {
+		event.OldHash = record.Hash
+	}
+
+	p.markRestoring(path)
+	err := p.withParentWritable(path, immutable, func() error {
+		if immutable {
+			_ = p.setImmutableRecursive(path, false)
+		}
+		return os.RemoveAll(path)
+	})
+	p.markRestoring(path)
+	if err != nil {
+		event.Details = fmt.Sprintf("删除快照外新增的文件失败: %v", err)
+		slog.Warn("删除快照外新增的文件失败", "path", path, "error", err)
+		return event
+	}
+	event.Details = "已删除快照外新增的文件"
+	event.Restored = true
+	return event
+}
+
+// restoreEntries 从快照恢复指定条目，targets 为路径到当前哈希的映射(调用方持有 restoreMu)
+func (p *Protector) restoreEntries(root string, targets map[string]string, snapshot *Snapshot, immutable bool) []TamperEvent {
+	paths := make(map[string]bool, len(targets))
+	for path := range targets {
+		paths[path] = true
+	}
+
+	results := make(map[string]error, len(targets))
+	err := p.snapshots.Extract(root, paths, func(hdr *tar.Header, r io.Reader) error {
+		p.markRestoring(hdr.Name)
+		results[hdr.Name] = p.withParentWritable(hdr.Name, immutable, func() error {
+			return p.restoreEntry(hdr, r, immutable)
+		})
+		p.markRestoring(hdr.Name)
+		return nil
+	})
+	if err != nil {
+		slog.Warn("读取防篡改快照失败", "path", root, "error", err)
+	}
+
+	events := make([]TamperEvent, 0, len(targets))
+	for path, currentHash := range targets {
+		entry := snapshot.Entries[path]
+		event := TamperEvent{
+			Path:      path,
+			Operation: "restore",
+			Timestamp: time.Now(),
+			OldHash:   currentHash,
+			NewHash:   entry.Hash,
+		}
+
+		restoreErr, extracted := results[path]
+		switch {
+		case !extracted && err != nil:
+			restoreErr = err
+		case !extracted:
+			restoreErr = fmt.Errorf("快照中缺少该条目")
+		}
+		if restoreErr != nil {
+			event.Details = fmt.Sprintf("从快照恢复失败: %v", restoreErr)
+			slog.Warn("从快照恢复失败", "path", path, "error", restoreErr)
+		} else {
+			event.Restored = true
+			if entry.Type == tar.TypeDir {
+				event.Details = "已从快照恢复目录"
+			} else if currentHash == "" {
+				event.Details = "已从快照恢复被删除的文件"
+			} else {
+				event.Details = "已从快照恢复被修改的文件"
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// restoreEntry 将快照条目写回文件系统
+func (p *Protector) restoreEntry(hdr *tar.Header, r io.Reader, immutable bool) error {
+	path := hdr.Name
+	mode := os.FileMode(hdr.Mode).Perm()
+
+	// 类型不一致时先删除现有路径
+	if info, err := os.Lstat(path); err == nil {
+		sameType := (hdr.Typeflag == tar.TypeDir && info.IsDir()) ||
+			(hdr.Typeflag == tar.TypeReg && info.Mode().IsRegular())
+		if !sameType {
+			if immutable {
+				_ = p.setImmutableRecursive(path, false)
+			}
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		} else if immutable {
+			_ = p.setImmutable(path, false)
+		}
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(path, mode); err != nil {
+			return err
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+	case tar.TypeSymlink:
+		if err := os.Symlink(hdr.Linkname, path); err != nil {
+			return err
+		}
+		return nil
+	case tar.TypeReg:
+		tmp, err := os.CreateTemp(filepath.Dir(path), restoreTempPrefix+"*")
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(tmp, r); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := os.Chmod(tmp.Name(), mode); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	default:
+		return fmt.Errorf("不支持的快照条目类型: %c", hdr.Typeflag)
+	}
+
+	if immutable {
+		return p.setImmutable(path, true)
+	}
+	return nil
+}
+
+// withParentWritable 不可变属性模式下临时移除父目录的不可变属性，完成后恢复父目录原有的属性
+// 受保护路径本身的父目录不在保护范围内，通常没有不可变属性，此时不做任何修改
+func (p *Protector) withParentWritable(path string, immutable bool, fn func() error) error {
+	if !immutable {
+		return fn()
+	}
+	parent := filepath.Dir(path)
+	wasImmutable, err := p.checkImmutable(parent)
+	if err != nil || !wasImmutable {
+		return fn()
+	}
+	// 修改父目录属性同样会产生文件事件
+	p.markRestoring(parent)
+	defer p.markRestoring(parent)
+	if err := p.setImmutable(parent, false); err != nil {
+		slog.Debug("移除父目录不可变属性失败", "path", parent, "error", err)
+	}
+	defer func() {
+		_ = p.setImmutable(parent, true)
+	}()
+	return fn()
+}
+
+// entryMatches 判断路径当前状态是否与快照条目一致，返回当前文件的哈希（不存在时为空）
+func entryMatches(path string, entry SnapshotEntry) (bool, string) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, ""
+	}
+
+	switch entry.Type {
+	case tar.TypeDir:
+		return info.IsDir() && info.Mode().Perm() == entry.Mode, ""
+	case tar.TypeSymlink:
+		if info.Mode()&os.ModeSymlink == 0 {
+			return false, ""
+		}
+		link, err := os.Readlink(path)
+		return err == nil && link == entry.Linkname, ""
+	default:
+		record, err := hashFile(path)
+		if err != nil || record == nil {
+			return false, ""
+		}
+		return record.Hash == entry.Hash && os.FileMode(record.Mode) == entry.Mode, record.Hash
+	}
+}
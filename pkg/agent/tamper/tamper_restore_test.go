@@ -0,0 +1,168 @@
+package tamper
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// newTestProtector 创建使用临时目录存储快照和基线的保护器
+func newTestProtector(t *testing.T, mode string) *Protector {
+	t.Helper()
+	p := NewProtector()
+	dir := t.TempDir()
+	p.mode = mode
+	p.snapshots = NewSnapshotStore(filepath.Join(dir, "snapshots"))
+	p.baseline = NewBaselineStore(filepath.Join(dir, "baseline.db"))
+	return p
+}
+
+// drainEvents 取出事件通道中的所有事件
+func drainEvents(p *Protector) []TamperEvent {
+	var events []TamperEvent
+	for {
+		select {
+		case event := <-p.eventCh:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入测试文件失败: %v", err)
+	}
+}
+
+func TestEnforceRootRestoresSnapshot(t *testing.T) {
+	p := newTestProtector(t, ModeHash)
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatalf("创建测试目录失败: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, "index.html"), "original")
+	writeTestFile(t, filepath.Join(root, "sub", "app.js"), "console.log(1)")
+
+	p.paths[root] = true
+	p.enforce[root] = true
+	if err := p.createSnapshot(root); err != nil {
+		t.Fatalf("创建快照失败: %v", err)
+	}
+
+	// 修改、删除文件并新增快照外的文件
+	writeTestFile(t, filepath.Join(root, "index.html"), "hacked")
+	if err := os.Remove(filepath.Join(root, "sub", "app.js")); err != nil {
+		t.Fatalf("删除测试文件失败: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, "shell.php"), "<?php eval($_GET['c']);")
+
+	p.enforceRoot(root, false)
+
+	if data, _ := os.ReadFile(filepath.Join(root, "index.html")); string(data) != "original" {
+		t.Errorf("被修改的文件应恢复为快照内容，实际为 %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "sub", "app.js")); string(data) != "console.log(1)" {
+		t.Errorf("子目录中被删除的文件应恢复，实际为 %q", data)
+	}
+	if _, err := os.Lstat(filepath.Join(root, "shell.php")); !os.IsNotExist(err) {
+		t.Errorf("快照外新增的文件应被删除")
+	}
+	entries, _ := os.ReadDir(root)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), restoreTempPrefix) {
+			t.Errorf("不应残留恢复临时文件: %s", entry.Name())
+		}
+	}
+
+	events := drainEvents(p)
+	if len(events) != 3 {
+		t.Fatalf("应上报 3 个恢复事件，实际 %d 个: %+v", len(events), events)
+	}
+	for _, event := range events {
+		if event.Operation != "restore" || !event.Restored {
+			t.Errorf("恢复事件不正确: %+v", event)
+		}
+		if !p.isRestoring(event.Path) {
+			t.Errorf("恢复后应在一段时间内忽略路径的文件事件: %s", event.Path)
+		}
+	}
+}
+
+func TestEnforceSingleFileRootKeepsParentAttr(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("防篡改功能仅支持 Linux 系统")
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("此测试需要 root 权限才能设置不可变属性")
+	}
+	p := newTestProtector(t, ModeImmutable)
+	parent := t.TempDir()
+	root := filepath.Join(parent, "nginx.conf")
+	writeTestFile(t, root, "original")
+	if err := p.setImmutable(root, true); err != nil {
+		t.Skipf("文件系统不支持不可变属性: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = p.setImmutable(root, false)
+		_ = p.setImmutable(parent, false)
+	})
+	if err := p.setImmutable(root, false); err != nil {
+		t.Fatalf("移除不可变属性失败: %v", err)
+	}
+
+	p.paths[root] = true
+	p.enforce[root] = true
+	if err := p.createSnapshot(root); err != nil {
+		t.Fatalf("创建快照失败: %v", err)
+	}
+	writeTestFile(t, root, "hacked")
+
+	p.enforceRoot(root, true)
+
+	if data, _ := os.ReadFile(root); string(data) != "original" {
+		t.Errorf("单文件保护路径应恢复为快照内容，实际为 %q", data)
+	}
+	if immutable, err := p.checkImmutable(root); err != nil || !immutable {
+		t.Errorf("恢复后的文件应重新设置不可变属性: %v, %v", immutable, err)
+	}
+	if immutable, err := p.checkImmutable(parent); err != nil || immutable {
+		t.Errorf("保护范围外的父目录不应被设置不可变属性: %v, %v", immutable, err)
+	}
+}
+
+func TestHandleEventIgnoresRestore(t *testing.T) {
+	p := newTestProtector(t, ModeImmutable)
+	root := t.TempDir()
+	p.paths[root] = true
+
+	restored := filepath.Join(root, "index.html")
+	p.markRestoring(restored)
+
+	tests := []struct {
+		name   string
+		event  fsnotify.Event
+		expect bool
+	}{
+		{"恢复临时文件创建", fsnotify.Event{Name: filepath.Join(root, restoreTempPrefix+"123"), Op: fsnotify.Create}, false},
+		{"恢复临时文件重命名", fsnotify.Event{Name: filepath.Join(root, restoreTempPrefix+"123"), Op: fsnotify.Rename}, false},
+		{"正在恢复的路径", fsnotify.Event{Name: restored, Op: fsnotify.Create}, false},
+		{"正在恢复的路径属性变化", fsnotify.Event{Name: restored, Op: fsnotify.Chmod}, false},
+		{"其他路径写入", fsnotify.Event{Name: filepath.Join(root, "other.html"), Op: fsnotify.Write}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.handleEvent(tt.event)
+			events := drainEvents(p)
+			if got := len(events) > 0; got != tt.expect {
+				t.Errorf("是否上报事件 = %v, 期望 %v, 事件: %+v", got, tt.expect, events)
+			}
+		})
+	}
+}
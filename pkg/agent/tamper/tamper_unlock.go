@@ -0,0 +1,208 @@
+package tamper
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// maxUnlockDuration 单次临时解锁的最长时间
+const maxUnlockDuration = 24 * time.Hour
+
+// Unlock 临时解除受保护路径的保护（用于发布），窗口内不上报事件、不恢复文件
+// 到期后由探针自行重新保护并以当前内容更新基线和快照，无需服务端参与；d<=0 表示立即重新保护
+func (p *Protector) Unlock(path string, d time.Duration) error {
+	if d <= 0 {
+		return p.Relock(path)
+	}
+	if d > maxUnlockDuration {
+		d = maxUnlockDuration
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paths[path] {
+		return fmt.Errorf("目录未受保护: %s", path)
+	}
+
+	until := time.Now().Add(d)
+	if err := p.baseline.SaveUnlock(path, until); err != nil {
+		return fmt.Errorf("保存解锁状态失败: %w", err)
+	}
+
+	alreadyUnlocked := p.isUnlocked(path)
+	p.startUnlock(path, until)
+	if alreadyUnlocked {
+		slog.Info("已延长临时解锁窗口", "path", path, "until", until)
+		return nil
+	}
+
+	if usesImmutable(p.mode) {
+		if err := p.setImmutableAll(path, false); err != nil {
+			slog.Warn("临时解锁时移除不可变属性失败", "path", path, "error", err)
+		}
+	}
+	p.clearReported(path)
+
+	slog.Info("已临时解除目录保护", "path", path, "until", until)
+	p.emitEvent(TamperEvent{
+		Path:      path,
+		Operation: "unlock",
+		Timestamp: time.Now(),
+		Details:   fmt.Sprintf("临时解除保护，将于 %s 自动重新保护", until.Format("2006-01-02 15:04:05")),
+	})
+	return nil
+}
+
+// Relock 结束临时解锁窗口，重新保护并以当前内容更新基线和快照
+func (p *Protector) Relock(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.finishUnlock(path)
+}
+
+// expireUnlock 解锁窗口到期回调，窗口已被延长或提前结束时忽略
+func (p *Protector) expireUnlock(path string, until time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.unlockMu.Lock()
+	current, ok := p.unlocked[path]
+	p.unlockMu.Unlock()
+	if !ok || !current.Equal(until) {
+		return
+	}
+	if err := p.finishUnlock(path); err != nil {
+		slog.Warn("临时解锁到期重新保护失败", "path", path, "error", err)
+	}
+}
+
+// finishUnlock 结束解锁窗口并重新保护(内部方法,调用方持有 p.mu)
+func (p *Protector) finishUnlock(path string) error {
+	if !p.stopUnlock(path) {
+		return nil
+	}
+	if err := p.baseline.DeleteUnlock(path); err != nil {
+		slog.Warn("删除解锁状态失败", "path", path, "error", err)
+	}
+	if !p.paths[path] {
+		return nil
+	}
+	return p.reprotect(path)
+}
+
+// GetUnlocked 获取处于临时解锁窗口的路径及到期时间
+func (p *Protector) GetUnlocked() map[string]time.Time {
+	p.unlockMu.Lock()
+	defer p.unlockMu.Unlock()
+
+	result := make(map[string]time.Time, len(p.unlocked))
+	for path, until := range p.unlocked {
+		result[path] = until
+	}
+	return result
+}
+
+// reprotect 以当前内容重建哈希基线和快照，并恢复不可变属性(内部方法,调用方持有 p.mu)
+func (p *Protector) reprotect(path string) error {
+	var lastErr error
+	if usesHash(p.mode) {
+		if err := p.buildBaseline(path); err != nil {
+			slog.Warn("重建哈希基线失败", "path", path, "error", err)
+			lastErr = err
+		}
+	}
+	if p.enforce[path] {
+		if err := p.createSnapshot(path); err != nil {
+			slog.Warn("重建防篡改快照失败", "path", path, "error", err)
+			lastErr = err
+		}
+	}
+	if usesImmutable(p.mode) {
+		if err := p.setImmutableAll(path, true); err != nil {
+			slog.Warn("重新设置不可变属性失败", "path", path, "error", err)
+			lastErr = err
+		}
+	}
+
+	details := "临时解锁结束，已重新保护并更新基线"
+	if lastErr != nil {
+		details = fmt.Sprintf("临时解锁结束，重新保护失败: %v", lastErr)
+	}
+	slog.Info("已重新保护目录", "path", path, "error", lastErr)
+	p.emitEvent(TamperEvent{
+		Path:      path,
+		Operation: "relock",
+		Timestamp: time.Now(),
+		Details:   details,
+		Restored:  lastErr == nil,
+	})
+	return lastErr
+}
+
+// resumeUnlock 添加目录时处理持久化的解锁窗口(内部方法,调用方持有 p.mu)
+// 返回 unlocked=true 表示窗口仍有效，目录保持解锁；rebaseline=true 表示窗口已在探针停止期间结束，需要以当前内容重建基线
+func (p *Protector) resumeUnlock(path string) (unlocked, rebaseline bool) {
+	until, found, err := p.baseline.GetUnlock(path)
+	if err != nil {
+		slog.Warn("读取解锁状态失败", "path", path, "error", err)
+		return false, false
+	}
+	if !found {
+		return false, false
+	}
+
+	if time.Now().Before(until) {
+		p.startUnlock(path, until)
+		slog.Info("恢复临时解锁窗口", "path", path, "until", until)
+		return true, false
+	}
+
+	if err := p.baseline.DeleteUnlock(path); err != nil {
+		slog.Warn("删除解锁状态失败", "path", path, "error", err)
+	}
+	slog.Info("临时解锁窗口已过期，将以当前内容重建基线", "path", path)
+	return false, true
+}
+
+// startUnlock 记录解锁窗口并设置到期自动重新保护
+func (p *Protector) startUnlock(path string, until time.Time) {
+	p.unlockMu.Lock()
+	defer p.unlockMu.Unlock()
+
+	if timer, ok := p.relockTimers[path]; ok {
+		timer.Stop()
+	}
+	p.unlocked[path] = until
+	p.relockTimers[path] = time.AfterFunc(time.Until(until), func() {
+		p.expireUnlock(path, until)
+	})
+}
+
+// stopUnlock 取消解锁窗口，返回之前是否处于解锁状态
+func (p *Protector) stopUnlock(path string) bool {
+	p.unlockMu.Lock()
+	defer p.unlockMu.Unlock()
+
+	if timer, ok := p.relockTimers[path]; ok {
+		timer.Stop()
+		delete(p.relockTimers, path)
+	}
+	if _, ok := p.unlocked[path]; !ok {
+		return false
+	}
+	delete(p.unlocked, path)
+	return true
+}
+
+// isUnlocked 判断受保护路径是否处于临时解锁窗口
+func (p *Protector) isUnlocked(root string) bool {
+	if root == "" {
+		return false
+	}
+	p.unlockMu.Lock()
+	defer p.unlockMu.Unlock()
+	_, ok := p.unlocked[root]
+	return ok
+}
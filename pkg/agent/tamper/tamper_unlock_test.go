@@ -0,0 +1,144 @@
+package tamper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newUnlockTestProtector 创建保护单个目录的哈希模式保护器
+func newUnlockTestProtector(t *testing.T) (*Protector, string) {
+	t.Helper()
+	p := newTestProtector(t, ModeHash)
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "index.html"), "v1")
+	p.paths[root] = true
+	if err := p.buildBaseline(root); err != nil {
+		t.Fatalf("建立哈希基线失败: %v", err)
+	}
+	t.Cleanup(func() {
+		p.stopUnlock(root)
+		_ = p.baseline.Close()
+	})
+	return p, root
+}
+
+func TestUnlockExpiresAndRebaselines(t *testing.T) {
+	p, root := newUnlockTestProtector(t)
+	file := filepath.Join(root, "index.html")
+
+	if err := p.Unlock(root, 200*time.Millisecond); err != nil {
+		t.Fatalf("Unlock() 失败: %v", err)
+	}
+	if !p.isUnlocked(root) {
+		t.Fatalf("解锁后应处于解锁窗口")
+	}
+	if _, found, _ := p.baseline.GetUnlock(root); !found {
+		t.Errorf("解锁窗口应持久化，探针重启后恢复")
+	}
+	waitEvent(t, p, root, "unlock")
+
+	// 窗口内发布新版本
+	writeTestFile(t, file, "v2")
+
+	waitEvent(t, p, root, "relock")
+	if p.isUnlocked(root) {
+		t.Errorf("窗口到期后应重新保护")
+	}
+	if _, found, _ := p.baseline.GetUnlock(root); found {
+		t.Errorf("重新保护后应删除持久化的解锁窗口")
+	}
+	want, err := hashFile(file)
+	if err != nil {
+		t.Fatalf("计算文件哈希失败: %v", err)
+	}
+	record, err := p.baseline.Get(root, file)
+	if err != nil || record == nil || record.Hash != want.Hash {
+		t.Errorf("重新保护后应以窗口内发布的内容更新基线: %+v, %v", record, err)
+	}
+}
+
+func TestUnlockExtendsWindow(t *testing.T) {
+	p, root := newUnlockTestProtector(t)
+
+	if err := p.Unlock(root, 100*time.Millisecond); err != nil {
+		t.Fatalf("Unlock() 失败: %v", err)
+	}
+	if err := p.Unlock(root, time.Hour); err != nil {
+		t.Fatalf("延长解锁窗口失败: %v", err)
+	}
+	// 原窗口的到期时间已过，延长后的窗口不受影响
+	time.Sleep(300 * time.Millisecond)
+	if !p.isUnlocked(root) {
+		t.Fatalf("延长后的窗口不应按原到期时间结束")
+	}
+	until := p.GetUnlocked()[root]
+	if remaining := time.Until(until); remaining < 59*time.Minute {
+		t.Errorf("延长后的剩余时间 = %s", remaining)
+	}
+
+	// 过期的回调（如已被延长的旧定时器）不应结束当前窗口
+	p.expireUnlock(root, until.Add(-time.Minute))
+	if !p.isUnlocked(root) {
+		t.Errorf("旧窗口的到期回调不应重新保护")
+	}
+
+	// 提前结束
+	if err := p.Unlock(root, 0); err != nil {
+		t.Fatalf("提前结束解锁窗口失败: %v", err)
+	}
+	if p.isUnlocked(root) {
+		t.Errorf("d<=0 应立即重新保护")
+	}
+}
+
+func TestUnlockLimits(t *testing.T) {
+	p, root := newUnlockTestProtector(t)
+
+	if err := p.Unlock(filepath.Join(root, "other"), time.Minute); err == nil {
+		t.Errorf("未受保护的路径不能解锁")
+	}
+
+	if err := p.Unlock(root, 7*24*time.Hour); err != nil {
+		t.Fatalf("Unlock() 失败: %v", err)
+	}
+	if remaining := time.Until(p.GetUnlocked()[root]); remaining > maxUnlockDuration {
+		t.Errorf("解锁时间应限制在 %s 以内，实际剩余 %s", maxUnlockDuration, remaining)
+	}
+}
+
+func TestResumeUnlock(t *testing.T) {
+	tests := []struct {
+		name           string
+		until          time.Duration
+		wantUnlocked   bool
+		wantRebaseline bool
+	}{
+		{"窗口仍有效时保持解锁", time.Hour, true, false},
+		{"窗口在探针停止期间结束时重建基线", -time.Minute, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, root := newUnlockTestProtector(t)
+			if err := p.baseline.SaveUnlock(root, time.Now().Add(tt.until)); err != nil {
+				t.Fatalf("保存解锁状态失败: %v", err)
+			}
+
+			unlocked, rebaseline := p.resumeUnlock(root)
+			if unlocked != tt.wantUnlocked || rebaseline != tt.wantRebaseline {
+				t.Errorf("resumeUnlock() = (%v, %v), want (%v, %v)", unlocked, rebaseline, tt.wantUnlocked, tt.wantRebaseline)
+			}
+			if p.isUnlocked(root) != tt.wantUnlocked {
+				t.Errorf("isUnlocked() = %v, want %v", p.isUnlocked(root), tt.wantUnlocked)
+			}
+			if _, found, _ := p.baseline.GetUnlock(root); found != tt.wantUnlocked {
+				t.Errorf("持久化的解锁状态 = %v, want %v", found, tt.wantUnlocked)
+			}
+		})
+	}
+
+	p, root := newUnlockTestProtector(t)
+	if unlocked, rebaseline := p.resumeUnlock(root); unlocked || rebaseline {
+		t.Errorf("没有解锁记录时 resumeUnlock() = (%v, %v)", unlocked, rebaseline)
+	}
+}
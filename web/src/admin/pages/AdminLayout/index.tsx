@@ -5,6 +5,7 @@ import {App as AntApp, Avatar, Button, ConfigProvider, Dropdown, Space, theme} f
 import {
     Activity,
     AlertTriangle,
+    BellOff,
     BookOpen,
     Eye,
     Globe,
@@ -81,6 +82,12 @@ const AdminLayout = () => {
                 path: '/admin/alert-records',
                 icon: <AlertTriangle className="h-4 w-4" strokeWidth={2}/>,
             },
+            {
+                key: 'silences',
+                label: '告警静默',
+                path: '/admin/silences',
+                icon: <BellOff className="h-4 w-4" strokeWidth={2}/>,
+            },
             {
                 key: 'settings',
                 label: '系统设置',
@@ -1,10 +1,12 @@
 import {useState} from 'react';
-import {Alert, App, Button, Space, Spin} from 'antd';
+import {Alert, App, Button, Space, Spin, Tabs} from 'antd';
 import {RefreshCw, Shield} from 'lucide-react';
 import {useQuery} from '@tanstack/react-query';
 import {getAgentForAdmin, getAuditResult, sendAuditCommand, type VPSAuditResult} from '@/api/agent.ts';
 import {getErrorMessage} from '@/lib/utils';
 import AuditResultView from './AuditResultView';
+import AuditDiffView from './AuditDiffView';
+import AgentVulnerabilities from './AgentVulnerabilities';
 
 interface AgentAuditProps {
     agentId: string;
@@ -130,7 +132,25 @@ const AgentAudit = ({agentId}: AgentAuditProps) => {
                     重新审计
                 </Button>
             </div>
-            <AuditResultView result={auditResult}/>
+            <Tabs
+                items={[
+                    {
+                        key: 'result',
+                        label: '最新结果',
+                        children: <AuditResultView result={auditResult}/>,
+                    },
+                    {
+                        key: 'diff',
+                        label: '变化对比',
+                        children: <AuditDiffView agentId={agentId}/>,
+                    },
+                    {
+                        key: 'vulnerabilities',
+                        label: '软件包漏洞',
+                        children: <AgentVulnerabilities agentId={agentId}/>,
+                    },
+                ]}
+            />
         </Space>
     );
 };
@@ -0,0 +1,153 @@
+import {Alert, Card, Descriptions, Empty, Space, Spin, Table, Tag} from 'antd';
+import type {ColumnsType} from 'antd/es/table';
+import {useQuery} from '@tanstack/react-query';
+import dayjs from 'dayjs';
+import {getAgentVulnerabilities, type PackageVulnerability} from '@/api/agent.ts';
+import {getErrorMessage} from '@/lib/utils';
+
+interface AgentVulnerabilitiesProps {
+    agentId: string;
+}
+
+const severityColors: Record<string, string> = {
+    critical: 'red',
+    high: 'volcano',
+    medium: 'orange',
+    low: 'blue',
+    unknown: 'default',
+};
+
+const severityLabels: Record<string, string> = {
+    critical: '严重',
+    high: '高危',
+    medium: '中危',
+    low: '低危',
+    unknown: '未知',
+};
+
+const severityOrder = ['critical', 'high', 'medium', 'low', 'unknown'];
+
+// 基于最近一次审计采集的软件包清单匹配离线漏洞库
+const AgentVulnerabilities = ({agentId}: AgentVulnerabilitiesProps) => {
+    const {data: report, isLoading, error} = useQuery({
+        queryKey: ['admin', 'agent', agentId, 'vulnerabilities'],
+        queryFn: async () => {
+            const response = await getAgentVulnerabilities(agentId);
+            return response.data;
+        },
+        enabled: !!agentId,
+        retry: false,
+    });
+
+    if (isLoading) {
+        return (
+            <div className="text-center py-12">
+                <Spin/>
+            </div>
+        );
+    }
+
+    if (error) {
+        return <Alert type="error" showIcon title={getErrorMessage(error, '获取漏洞信息失败')}/>;
+    }
+
+    if (!report) {
+        return <Empty description="暂无漏洞信息"/>;
+    }
+
+    const columns: ColumnsType<PackageVulnerability> = [
+        {
+            title: '软件包',
+            dataIndex: 'package',
+            key: 'package',
+            render: (value: string, record) => (
+                <div>
+                    <div className="font-medium">{value}</div>
+                    {record.source && record.source !== value && (
+                        <div className="text-xs text-gray-500">源码包: {record.source}</div>
+                    )}
+                </div>
+            ),
+        },
+        {
+            title: '已安装版本',
+            dataIndex: 'version',
+            key: 'version',
+            render: (value: string) => <span className="font-mono text-xs">{value}</span>,
+        },
+        {
+            title: '修复版本',
+            dataIndex: 'fixedVersion',
+            key: 'fixedVersion',
+            render: (value: string) => value ? <span className="font-mono text-xs">{value}</span> : <Tag>未修复</Tag>,
+        },
+        {
+            title: '严重程度',
+            dataIndex: 'severity',
+            key: 'severity',
+            width: 100,
+            sorter: (a, b) => severityOrder.indexOf(a.severity) - severityOrder.indexOf(b.severity),
+            defaultSortOrder: 'ascend',
+            render: (value: string) => <Tag color={severityColors[value]}>{severityLabels[value] || value}</Tag>,
+        },
+        {
+            title: '漏洞',
+            key: 'vuln',
+            render: (_, record) => (
+                <div>
+                    <div className="font-mono text-xs">{record.vulnId}</div>
+                    {record.cves?.length > 0 && (
+                        <div className="text-xs text-gray-500">{record.cves.join(', ')}</div>
+                    )}
+                </div>
+            ),
+        },
+        {
+            title: '描述',
+            dataIndex: 'summary',
+            key: 'summary',
+            ellipsis: true,
+        },
+    ];
+
+    return (
+        <Space orientation="vertical" style={{width: '100%'}} size="middle">
+            {report.message && <Alert type="info" showIcon title={report.message}/>}
+
+            <Card size="small">
+                <Descriptions column={{xs: 1, sm: 2, lg: 4}} size="small">
+                    <Descriptions.Item label="发行版">
+                        {report.distro ? `${report.distro} ${report.distroVersion}` : '-'}
+                    </Descriptions.Item>
+                    <Descriptions.Item label="包管理器">{report.manager || '-'}</Descriptions.Item>
+                    <Descriptions.Item label="软件包">
+                        {report.vulnerablePackages} / {report.totalPackages} 存在漏洞
+                    </Descriptions.Item>
+                    <Descriptions.Item label="审计时间">
+                        {report.auditTime ? dayjs(report.auditTime).format('YYYY-MM-DD HH:mm:ss') : '-'}
+                    </Descriptions.Item>
+                </Descriptions>
+                <Space size={4} wrap>
+                    {severityOrder
+                        .filter((severity) => report.severityCounts?.[severity])
+                        .map((severity) => (
+                            <Tag key={severity} color={severityColors[severity]}>
+                                {severityLabels[severity]} {report.severityCounts[severity]}
+                            </Tag>
+                        ))}
+                </Space>
+            </Card>
+
+            <Table<PackageVulnerability>
+                size="small"
+                rowKey={(record) => `${record.package}-${record.vulnId}`}
+                columns={columns}
+                dataSource={report.items}
+                pagination={{pageSize: 20, showSizeChanger: false}}
+                locale={{emptyText: <Empty description="未发现已知漏洞"/>}}
+            />
+        </Space>
+    );
+};
+
+export default AgentVulnerabilities;
@@ -0,0 +1,140 @@
+import {useState} from 'react';
+import {Alert, Card, Empty, List, Select, Space, Spin, Tag} from 'antd';
+import {useQuery} from '@tanstack/react-query';
+import dayjs from 'dayjs';
+import {diffAuditResults, listAuditResults} from '@/api/agent.ts';
+import {getErrorMessage} from '@/lib/utils';
+
+interface AuditDiffViewProps {
+    agentId: string;
+}
+
+const formatTime = (value: number) => dayjs(value).format('YYYY-MM-DD HH:mm:ss');
+
+// 两次审计之间的资产变化（新增/移除的端口、用户、定时任务等）
+const AuditDiffView = ({agentId}: AuditDiffViewProps) => {
+    const [baseId, setBaseId] = useState<number>();
+    const [targetId, setTargetId] = useState<number>();
+
+    const {data: history, isLoading: historyLoading} = useQuery({
+        queryKey: ['admin', 'agent', agentId, 'audit', 'results'],
+        queryFn: async () => {
+            const response = await listAuditResults(agentId);
+            return response.data.items;
+        },
+        enabled: !!agentId,
+    });
+
+    const comparable = (history?.length ?? 0) >= 2;
+
+    // 未选择时后端默认对比最近两次
+    const {data: diff, isLoading: diffLoading, error} = useQuery({
+        queryKey: ['admin', 'agent', agentId, 'audit', 'diff', baseId, targetId],
+        queryFn: async () => {
+            const response = await diffAuditResults(agentId, baseId, targetId);
+            return response.data;
+        },
+        enabled: !!agentId && comparable,
+        retry: false,
+    });
+
+    if (historyLoading) {
+        return (
+            <div className="text-center py-12">
+                <Spin/>
+            </div>
+        );
+    }
+
+    if (!comparable) {
+        return <Empty description="至少需要两次审计结果才能对比变化"/>;
+    }
+
+    const options = history!.map((item) => ({
+        value: item.id,
+        label: `#${item.id} ${formatTime(item.createdAt)}`,
+    }));
+    const changedSections = diff?.sections.filter((section) => section.added.length > 0 || section.removed.length > 0) ?? [];
+
+    return (
+        <Space orientation="vertical" style={{width: '100%'}} size="middle">
+            <div className="flex flex-wrap items-center gap-2">
+                <span>基准</span>
+                <Select
+                    style={{minWidth: 240}}
+                    placeholder="上一次审计"
+                    allowClear
+                    options={options}
+                    value={baseId}
+                    onChange={setBaseId}
+                />
+                <span>对比</span>
+                <Select
+                    style={{minWidth: 240}}
+                    placeholder="最近一次审计"
+                    allowClear
+                    options={options}
+                    value={targetId}
+                    onChange={setTargetId}
+                />
+            </div>
+
+            {error && (
+                <Alert type="error" showIcon title={getErrorMessage(error, '对比审计结果失败')}/>
+            )}
+
+            {diffLoading && (
+                <div className="text-center py-12">
+                    <Spin/>
+                </div>
+            )}
+
+            {diff && (
+                <>
+                    <div className="flex flex-wrap items-center gap-2 text-sm">
+                        <span>{formatTime(diff.baseTime)} → {formatTime(diff.targetTime)}</span>
+                        <Tag color="green">新增 {diff.totalAdded}</Tag>
+                        <Tag color="red">移除 {diff.totalRemoved}</Tag>
+                    </div>
+
+                    {changedSections.length === 0 ? (
+                        <Alert type="success" showIcon title="两次审计之间没有资产变化"/>
+                    ) : (
+                        changedSections.map((section) => (
+                            <Card
+                                key={section.name}
+                                size="small"
+                                title={section.title}
+                                extra={
+                                    <Space size={4}>
+                                        <Tag color="green">+{section.added.length}</Tag>
+                                        <Tag color="red">-{section.removed.length}</Tag>
+                                    </Space>
+                                }
+                            >
+                                <List
+                                    size="small"
+                                    dataSource={[
+                                        ...section.added.map((item) => ({sign: '+', item})),
+                                        ...section.removed.map((item) => ({sign: '-', item})),
+                                    ]}
+                                    renderItem={({sign, item}) => (
+                                        <List.Item>
+                                            <span
+                                                className={`font-mono text-xs ${sign === '+' ? 'text-green-600' : 'text-red-600'}`}
+                                            >
+                                                {sign} {item}
+                                            </span>
+                                        </List.Item>
+                                    )}
+                                />
+                            </Card>
+                        ))
+                    )}
+                </>
+            )}
+        </Space>
+    );
+};
+
+export default AuditDiffView;
@@ -0,0 +1,182 @@
+import React, {useState} from 'react';
+import {App, Button, Popconfirm, Select, Table, Tag, Tooltip} from 'antd';
+import type {ColumnsType, TablePaginationConfig} from 'antd/es/table';
+import {ShieldBan} from 'lucide-react';
+import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
+import type {SSHBan, SSHBanStatus} from '@/types';
+import {getSSHBans, liftSSHBan} from '@/api/agent';
+import {getErrorMessage} from '@/lib/utils';
+import dayjs from 'dayjs';
+
+const statusOptions: { value: SSHBanStatus; label: string; color: string }[] = [
+    {value: 'active', label: '生效中', color: 'error'},
+    {value: 'pending', label: '下发中', color: 'processing'},
+    {value: 'failed', label: '封禁失败', color: 'warning'},
+    {value: 'lifted', label: '已解除', color: 'default'},
+    {value: 'expired', label: '已到期', color: 'default'},
+];
+
+interface SSHLoginBansProps {
+    agentId: string;
+}
+
+const SSHLoginBans: React.FC<SSHLoginBansProps> = ({agentId}) => {
+    const {message} = App.useApp();
+    const queryClient = useQueryClient();
+    const [pageIndex, setPageIndex] = useState(1);
+    const [pageSize, setPageSize] = useState(20);
+    const [status, setStatus] = useState<SSHBanStatus | undefined>();
+
+    const {data: bansPaging, isLoading, isFetching} = useQuery({
+        queryKey: ['admin', 'agents', 'ssh-bans', agentId, pageIndex, pageSize, status],
+        queryFn: () => getSSHBans(agentId, {
+            pageIndex,
+            pageSize,
+            status,
+            sortField: 'createdAt',
+            sortOrder: 'descend',
+        }),
+    });
+
+    const liftMutation = useMutation({
+        mutationFn: (banId: string) => liftSSHBan(agentId, banId),
+        onSuccess: () => {
+            message.success('封禁已解除');
+            queryClient.invalidateQueries({queryKey: ['admin', 'agents', 'ssh-bans', agentId]});
+        },
+        onError: (error: unknown) => {
+            console.error('Failed to lift SSH ban:', error);
+            message.error(getErrorMessage(error, '解除封禁失败'));
+        },
+    });
+
+    const columns: ColumnsType<SSHBan> = [
+        {
+            title: '封禁时间',
+            dataIndex: 'createdAt',
+            key: 'createdAt',
+            width: 180,
+            render: (_, record) => (
+                <span className="text-sm">
+                    {dayjs(record.createdAt).format('YYYY-MM-DD HH:mm:ss')}
+                </span>
+            ),
+        },
+        {
+            title: '来源地址',
+            dataIndex: 'cidr',
+            key: 'cidr',
+            width: 180,
+            render: (_, record) => (
+                <span className="font-mono text-sm">{record.cidr}</span>
+            ),
+        },
+        {
+            title: '状态',
+            dataIndex: 'status',
+            key: 'status',
+            width: 110,
+            render: (_, record) => {
+                const option = statusOptions.find(item => item.value === record.status);
+                const tag = <Tag variant={'filled'} color={option?.color}>{option?.label || record.status}</Tag>;
+                return record.message ? <Tooltip title={record.message}>{tag}</Tooltip> : tag;
+            },
+        },
+        {
+            title: '原因',
+            dataIndex: 'reason',
+            key: 'reason',
+            width: 200,
+        },
+        {
+            title: '到期时间',
+            dataIndex: 'expiresAt',
+            key: 'expiresAt',
+            width: 180,
+            render: (_, record) => (
+                <span className="text-sm">
+                    {dayjs(record.expiresAt).format('YYYY-MM-DD HH:mm:ss')}
+                </span>
+            ),
+        },
+        {
+            title: '解除',
+            key: 'lifted',
+            width: 180,
+            render: (_, record) => record.liftedAt ? (
+                <span className="text-sm text-gray-500">
+                    {record.liftedBy || '-'} · {dayjs(record.liftedAt).format('MM-DD HH:mm')}
+                </span>
+            ) : '-',
+        },
+        {
+            title: '操作',
+            key: 'action',
+            width: 100,
+            fixed: 'right',
+            render: (_, record) => (record.status === 'active' || record.status === 'pending') ? (
+                <Popconfirm
+                    title="确定解除该封禁吗？"
+                    onConfirm={() => liftMutation.mutate(record.id)}
+                >
+                    <Button type="link" size="small" danger loading={liftMutation.isPending}>
+                        解除
+                    </Button>
+                </Popconfirm>
+            ) : null,
+        },
+    ];
+
+    const handleTableChange = (pagination: TablePaginationConfig) => {
+        setPageIndex(pagination.current || 1);
+        setPageSize(pagination.pageSize || pageSize);
+    };
+
+    return (
+        <div className="space-y-4">
+            <div style={{display: 'flex', justifyContent: 'space-between', alignItems: 'center'}}>
+                <h3 className="text-lg font-medium">封禁记录</h3>
+                <Select
+                    allowClear
+                    placeholder="全部状态"
+                    style={{width: 140}}
+                    value={status}
+                    options={statusOptions.map(({value, label}) => ({value, label}))}
+                    onChange={(value) => {
+                        setStatus(value);
+                        setPageIndex(1);
+                    }}
+                />
+            </div>
+
+            <Table<SSHBan>
+                columns={columns}
+                dataSource={bansPaging?.items || []}
+                loading={isLoading || isFetching}
+                rowKey="id"
+                scroll={{x: 1100}}
+                pagination={{
+                    current: pageIndex,
+                    pageSize,
+                    total: bansPaging?.total || 0,
+                    showSizeChanger: true,
+                    showTotal: (total) => `共 ${total} 条`,
+                }}
+                onChange={handleTableChange}
+                locale={{
+                    emptyText: (
+                        <div className="py-8 text-center text-gray-500">
+                            <ShieldBan size={48} className="mx-auto mb-2 opacity-20"/>
+                            <p>暂无封禁记录</p>
+                            <p className="text-sm mt-2">
+                                请先在"监控配置"中启用暴力破解自动封禁
+                            </p>
+                        </div>
+                    ),
+                }}
+            />
+        </div>
+    );
+};
+
+export default SSHLoginBans;
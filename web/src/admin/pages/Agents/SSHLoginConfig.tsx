@@ -1,13 +1,23 @@
 import React, {useEffect} from 'react';
-import {Alert, App, Button, Card, Form, Input, Space, Switch} from 'antd';
+import {Alert, App, Button, Card, Col, Form, Input, InputNumber, Row, Space, Switch} from 'antd';
 import {Save, Terminal} from 'lucide-react';
 import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
-import type {SSHLoginConfig as SSHLoginConfigType} from '@/types';
+import type {SSHBanPolicy} from '@/types';
 import {getSSHLoginConfig, updateSSHLoginConfig} from '@/api/agent';
 import {getErrorMessage} from '@/lib/utils';
 
 const {TextArea} = Input;
 
+// 默认封禁策略，与服务端默认值一致
+const defaultBanPolicy: SSHBanPolicy = {
+    enabled: false,
+    maxFailures: 5,
+    window: 10,
+    banDuration: 60,
+    ipv4Prefix: 32,
+    ipv6Prefix: 128,
+};
+
 // 验证 IP 地址格式（支持 IPv4 和 CIDR）
 const validateIPOrCIDR = (value: string): boolean => {
     // IPv4 地址正则
@@ -68,6 +78,7 @@ const SSHLoginConfig: React.FC<SSHLoginConfigProps> = ({agentId}) => {
             return updateSSHLoginConfig(agentId, {
                 enabled: values.enabled,
                 ipWhitelist: ipWhitelist,
+                banPolicy: {...defaultBanPolicy, ...values.banPolicy},
             });
         },
         onSuccess: () => {
@@ -86,11 +97,13 @@ const SSHLoginConfig: React.FC<SSHLoginConfigProps> = ({agentId}) => {
             form.setFieldsValue({
                 enabled: config.enabled || false,
                 ipWhitelistText: formatIPWhitelist(config.ipWhitelist || []),
+                banPolicy: {...defaultBanPolicy, ...config.banPolicy},
             });
         } else {
             form.setFieldsValue({
                 enabled: false,
                 ipWhitelistText: '',
+                banPolicy: defaultBanPolicy,
             });
         }
     }, [config, form]);
@@ -122,6 +135,7 @@ const SSHLoginConfig: React.FC<SSHLoginConfigProps> = ({agentId}) => {
                     initialValues={{
                         enabled: false,
                         ipWhitelistText: '',
+                        banPolicy: defaultBanPolicy,
                     }}
                 >
                     <Form.Item
@@ -139,13 +153,66 @@ const SSHLoginConfig: React.FC<SSHLoginConfigProps> = ({agentId}) => {
                     <Form.Item
                         label="IP 白名单"
                         name="ipWhitelistText"
-                        extra={'白名单中的 IP 地址登录时只记录不发送通知。每行一个 IP 地址或 CIDR 网段，例如：192.168.1.1 或 192.168.1.0/24'}
+                        extra={'白名单中的 IP 地址登录时只记录不发送通知，也不会被自动封禁。每行一个 IP 地址或 CIDR 网段，例如：192.168.1.1 或 192.168.1.0/24'}
                     >
                         <TextArea
                             rows={6}
                             placeholder={'每行一个 IP 地址或 CIDR 网段，例如：\n192.168.1.1\n192.168.1.0/24\n10.0.0.0/8'}
                         />
                     </Form.Item>
+
+                    <h4 className="text-base font-medium mb-4">暴力破解封禁</h4>
+
+                    <Form.Item
+                        label="自动封禁"
+                        name={['banPolicy', 'enabled']}
+                        valuePropName="checked"
+                        extra={'探针跟踪认证日志中的登录失败记录，时间窗口内失败次数达到阈值后通过 nftables/iptables 丢弃来源地址访问 SSH 端口的流量（不影响其他服务），到期自动解封'}
+                    >
+                        <Switch
+                            checkedChildren="已启用"
+                            unCheckedChildren="已禁用"
+                        />
+                    </Form.Item>
+
+                    <Row gutter={16}>
+                        <Col xs={24} md={8}>
+                            <Form.Item label="统计窗口（分钟）" name={['banPolicy', 'window']}>
+                                <InputNumber min={1} max={1440} style={{width: '100%'}}/>
+                            </Form.Item>
+                        </Col>
+                        <Col xs={24} md={8}>
+                            <Form.Item label="失败次数阈值" name={['banPolicy', 'maxFailures']}>
+                                <InputNumber min={1} max={1000} style={{width: '100%'}}/>
+                            </Form.Item>
+                        </Col>
+                        <Col xs={24} md={8}>
+                            <Form.Item label="封禁时长（分钟）" name={['banPolicy', 'banDuration']}>
+                                <InputNumber min={1} max={43200} style={{width: '100%'}}/>
+                            </Form.Item>
+                        </Col>
+                    </Row>
+
+                    <Row gutter={16}>
+                        <Col xs={24} md={12}>
+                            <Form.Item
+                                label="IPv4 聚合前缀"
+                                name={['banPolicy', 'ipv4Prefix']}
+                                extra={'32 表示按单个 IP 统计和封禁，24 表示按 /24 网段'}
+                            >
+                                <InputNumber min={8} max={32} style={{width: '100%'}}/>
+                            </Form.Item>
+                        </Col>
+                        <Col xs={24} md={12}>
+                            <Form.Item
+                                label="IPv6 聚合前缀"
+                                name={['banPolicy', 'ipv6Prefix']}
+                                extra={'128 表示按单个 IP 统计和封禁，64 表示按 /64 网段'}
+                            >
+                                <InputNumber min={32} max={128} style={{width: '100%'}}/>
+                            </Form.Item>
+                        </Col>
+                    </Row>
                 </Form>
 
                 {config?.applyStatus && (
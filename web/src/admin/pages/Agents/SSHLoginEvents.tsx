@@ -47,6 +47,26 @@ const SSHLoginEvents: React.FC<SSHLoginEventsProps> = ({agentId}) => {
                 )
             ),
         },
+        {
+            title: '异常',
+            dataIndex: 'severity',
+            key: 'severity',
+            width: 100,
+            render: (_, record) => {
+                if (!record.severity) {
+                    return '-';
+                }
+                return (
+                    <Tooltip title={record.anomalyDetail}>
+                        {record.severity === 'high' ? (
+                            <Tag variant={'filled'} color="error">高危</Tag>
+                        ) : (
+                            <Tag variant={'filled'} color="warning">可疑</Tag>
+                        )}
+                    </Tooltip>
+                );
+            },
+        },
         {
             title: '用户名',
             dataIndex: 'username',
@@ -72,6 +92,18 @@ const SSHLoginEvents: React.FC<SSHLoginEventsProps> = ({agentId}) => {
             width: 140,
             render: (_, record) => record.ipLocation || '-',
         },
+        {
+            title: '运营商',
+            dataIndex: 'asn',
+            key: 'asn',
+            width: 160,
+            ellipsis: true,
+            render: (_, record) => record.asn ? (
+                <Tooltip title={record.asOrg}>
+                    <span className="text-sm">AS{record.asn} {record.asOrg}</span>
+                </Tooltip>
+            ) : '-',
+        },
         {
             title: '端口',
             dataIndex: 'port',
@@ -174,7 +206,7 @@ const SSHLoginEvents: React.FC<SSHLoginEventsProps> = ({agentId}) => {
                 dataSource={eventsPaging?.items || []}
                 loading={isLoading || isFetching}
                 rowKey="id"
-                scroll={{x: 1000}}
+                scroll={{x: 1260}}
                 pagination={{
                     current: pageIndex,
                     pageSize,
@@ -1,13 +1,15 @@
 import React, {useState} from 'react';
 import SSHLoginConfig from './SSHLoginConfig';
 import SSHLoginEvents from './SSHLoginEvents';
+import SSHLoginBans from './SSHLoginBans';
+import SSHLoginSessions from './SSHLoginSessions';
 
 interface SSHLoginMonitorProps {
     agentId: string;
 }
 
 const SSHLoginMonitor: React.FC<SSHLoginMonitorProps> = ({agentId}) => {
-    const [activeTab, setActiveTab] = useState<'config' | 'events'>('config');
+    const [activeTab, setActiveTab] = useState<'config' | 'events' | 'sessions' | 'bans'>('config');
 
     return (
         <div className="space-y-4">
@@ -33,6 +35,26 @@ const SSHLoginMonitor: React.FC<SSHLoginMonitorProps> = ({agentId}) => {
                 >
                     登录事件
                 </button>
+                <button
+                    className={`px-4 py-2 text-sm font-medium transition-colors ${
+                        activeTab === 'sessions'
+                            ? 'border-b-2 border-blue-500 text-blue-600'
+                            : 'text-gray-600 hover:text-gray-900'
+                    }`}
+                    onClick={() => setActiveTab('sessions')}
+                >
+                    会话记录
+                </button>
+                <button
+                    className={`px-4 py-2 text-sm font-medium transition-colors ${
+                        activeTab === 'bans'
+                            ? 'border-b-2 border-blue-500 text-blue-600'
+                            : 'text-gray-600 hover:text-gray-900'
+                    }`}
+                    onClick={() => setActiveTab('bans')}
+                >
+                    封禁记录
+                </button>
             </div>
 
             {/* 配置面板 */}
@@ -40,6 +62,12 @@ const SSHLoginMonitor: React.FC<SSHLoginMonitorProps> = ({agentId}) => {
 
             {/* 事件列表面板 */}
             {activeTab === 'events' && <SSHLoginEvents agentId={agentId}/>}
+
+            {/* 会话记录面板 */}
+            {activeTab === 'sessions' && <SSHLoginSessions agentId={agentId}/>}
+
+            {/* 封禁记录面板 */}
+            {activeTab === 'bans' && <SSHLoginBans agentId={agentId}/>}
         </div>
     );
 };
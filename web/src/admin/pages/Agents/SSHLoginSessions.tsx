@@ -0,0 +1,240 @@
+import React, {useState} from 'react';
+import {Select, Table, Tag, Tooltip} from 'antd';
+import type {ColumnsType, TablePaginationConfig} from 'antd/es/table';
+import {MonitorSmartphone} from 'lucide-react';
+import {useQuery} from '@tanstack/react-query';
+import type {SSHSession, SSHSessionStatus, SSHSudoEvent} from '@/types';
+import {getSSHSessions, getSSHSessionSudoEvents} from '@/api/agent';
+import {formatUptime} from '@/lib/format';
+import dayjs from 'dayjs';
+
+const statusOptions: { value: SSHSessionStatus; label: string; color: string }[] = [
+    {value: 'active', label: '在线', color: 'success'},
+    {value: 'closed', label: '已结束', color: 'default'},
+];
+
+interface SessionSudoEventsProps {
+    agentId: string;
+    sessionId: string;
+}
+
+// 会话内的 sudo 提权记录
+const SessionSudoEvents: React.FC<SessionSudoEventsProps> = ({agentId, sessionId}) => {
+    const {data, isLoading} = useQuery({
+        queryKey: ['admin', 'agents', 'ssh-sessions', agentId, sessionId, 'sudo'],
+        queryFn: () => getSSHSessionSudoEvents(agentId, sessionId, {
+            pageIndex: 1,
+            pageSize: 100,
+            sortField: 'timestamp',
+            sortOrder: 'ascend',
+        }),
+    });
+
+    const columns: ColumnsType<SSHSudoEvent> = [
+        {
+            title: '时间',
+            dataIndex: 'timestamp',
+            key: 'timestamp',
+            width: 180,
+            render: (_, record) => (
+                <span className="text-sm">{dayjs(record.timestamp).format('YYYY-MM-DD HH:mm:ss')}</span>
+            ),
+        },
+        {
+            title: '目标用户',
+            dataIndex: 'runAs',
+            key: 'runAs',
+            width: 100,
+            render: (_, record) => record.runAs || '-',
+        },
+        {
+            title: '命令',
+            dataIndex: 'command',
+            key: 'command',
+            render: (_, record) => (
+                <Tooltip title={record.pwd ? `工作目录：${record.pwd}` : undefined}>
+                    <span className="font-mono text-xs break-all">{record.command}</span>
+                </Tooltip>
+            ),
+        },
+        {
+            title: '结果',
+            dataIndex: 'success',
+            key: 'success',
+            width: 160,
+            render: (_, record) => record.success ? (
+                <Tag variant={'filled'} color="success">成功</Tag>
+            ) : (
+                <Tooltip title={record.message}>
+                    <Tag variant={'filled'} color="error">失败</Tag>
+                </Tooltip>
+            ),
+        },
+    ];
+
+    return (
+        <Table<SSHSudoEvent>
+            size="small"
+            columns={columns}
+            dataSource={data?.items || []}
+            loading={isLoading}
+            rowKey="id"
+            pagination={false}
+            locale={{emptyText: '会话内没有 sudo 记录'}}
+        />
+    );
+};
+
+interface SSHLoginSessionsProps {
+    agentId: string;
+}
+
+const SSHLoginSessions: React.FC<SSHLoginSessionsProps> = ({agentId}) => {
+    const [pageIndex, setPageIndex] = useState(1);
+    const [pageSize, setPageSize] = useState(20);
+    const [status, setStatus] = useState<SSHSessionStatus | undefined>();
+
+    const {data: sessionsPaging, isLoading, isFetching} = useQuery({
+        queryKey: ['admin', 'agents', 'ssh-sessions', agentId, pageIndex, pageSize, status],
+        queryFn: () => getSSHSessions(agentId, {
+            pageIndex,
+            pageSize,
+            status,
+            sortField: 'startedAt',
+            sortOrder: 'descend',
+        }),
+    });
+
+    const columns: ColumnsType<SSHSession> = [
+        {
+            title: '开始时间',
+            dataIndex: 'startedAt',
+            key: 'startedAt',
+            width: 180,
+            render: (_, record) => (
+                <span className="text-sm">{dayjs(record.startedAt).format('YYYY-MM-DD HH:mm:ss')}</span>
+            ),
+        },
+        {
+            title: '用户名',
+            dataIndex: 'username',
+            key: 'username',
+            width: 120,
+            render: (_, record) => <span className="font-mono text-sm">{record.username}</span>,
+        },
+        {
+            title: '来源',
+            key: 'source',
+            width: 200,
+            render: (_, record) => (
+                <div>
+                    <div className="font-mono text-sm">
+                        {record.ip}{record.port ? `:${record.port}` : ''}
+                    </div>
+                    {record.ipLocation && (
+                        <div className="text-xs text-gray-500">{record.ipLocation}</div>
+                    )}
+                </div>
+            ),
+        },
+        {
+            title: '终端',
+            dataIndex: 'tty',
+            key: 'tty',
+            width: 100,
+            render: (_, record) => <span className="font-mono text-sm">{record.tty || '-'}</span>,
+        },
+        {
+            title: '状态',
+            dataIndex: 'status',
+            key: 'status',
+            width: 100,
+            render: (_, record) => {
+                const option = statusOptions.find(item => item.value === record.status);
+                return <Tag variant={'filled'} color={option?.color}>{option?.label || record.status}</Tag>;
+            },
+        },
+        {
+            title: '时长',
+            dataIndex: 'duration',
+            key: 'duration',
+            width: 140,
+            render: (_, record) => formatUptime(record.duration),
+        },
+        {
+            title: '结束时间',
+            dataIndex: 'endedAt',
+            key: 'endedAt',
+            width: 180,
+            render: (_, record) => record.endedAt ? (
+                <span className="text-sm">{dayjs(record.endedAt).format('YYYY-MM-DD HH:mm:ss')}</span>
+            ) : '-',
+        },
+        {
+            title: 'sudo',
+            dataIndex: 'sudoCount',
+            key: 'sudoCount',
+            width: 80,
+            render: (_, record) => record.sudoCount > 0 ? (
+                <Tag variant={'filled'} color="warning">{record.sudoCount}</Tag>
+            ) : '-',
+        },
+    ];
+
+    const handleTableChange = (pagination: TablePaginationConfig) => {
+        setPageIndex(pagination.current || 1);
+        setPageSize(pagination.pageSize || pageSize);
+    };
+
+    return (
+        <div className="space-y-4">
+            <div style={{display: 'flex', justifyContent: 'space-between', alignItems: 'center'}}>
+                <h3 className="text-lg font-medium">会话记录</h3>
+                <Select
+                    allowClear
+                    placeholder="全部状态"
+                    style={{width: 140}}
+                    value={status}
+                    options={statusOptions.map(({value, label}) => ({value, label}))}
+                    onChange={(value) => {
+                        setStatus(value);
+                        setPageIndex(1);
+                    }}
+                />
+            </div>
+
+            <Table<SSHSession>
+                columns={columns}
+                dataSource={sessionsPaging?.items || []}
+                loading={isLoading || isFetching}
+                rowKey="id"
+                scroll={{x: 1100}}
+                expandable={{
+                    rowExpandable: (record) => record.sudoCount > 0,
+                    expandedRowRender: (record) => <SessionSudoEvents agentId={agentId} sessionId={record.id}/>,
+                }}
+                pagination={{
+                    current: pageIndex,
+                    pageSize,
+                    total: sessionsPaging?.total || 0,
+                    showSizeChanger: true,
+                    showTotal: (total) => `共 ${total} 条`,
+                }}
+                onChange={handleTableChange}
+                locale={{
+                    emptyText: (
+                        <div className="py-8 text-center text-gray-500">
+                            <MonitorSmartphone size={48} className="mx-auto mb-2 opacity-20"/>
+                            <p>暂无会话记录</p>
+                            <p className="text-sm mt-2">
+                                启用SSH登录监控后，登录和登出都会被记录为会话
+                            </p>
+                        </div>
+                    ),
+                }}
+            />
+        </div>
+    );
+};
+
+export default SSHLoginSessions;
@@ -1,8 +1,9 @@
 import React, {useEffect, useState} from 'react';
-import {Alert, App, Button, Card, Form, Input, List, Space, Switch} from 'antd';
-import {Plus, Save, Shield, Trash2} from 'lucide-react';
+import {Alert, App, Button, Card, Form, Input, InputNumber, List, Popconfirm, Select, Space, Switch, Tag, Tooltip} from 'antd';
+import {Camera, Lock, Plus, Save, Shield, Trash2, Unlock} from 'lucide-react';
 import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
-import {getTamperConfig, updateTamperConfig} from '@/api/tamper';
+import dayjs from 'dayjs';
+import {getTamperConfig, refreshTamperSnapshot, relockTamperPath, unlockTamperPath, updateTamperConfig} from '@/api/tamper';
 import {getErrorMessage} from '@/lib/utils';
 
 interface TamperProtectionConfigProps {
@@ -14,7 +15,9 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
     const [form] = Form.useForm();
     const queryClient = useQueryClient();
     const [editPaths, setEditPaths] = useState<string[]>([]);
+    const [enforcePaths, setEnforcePaths] = useState<string[]>([]);
     const [newPath, setNewPath] = useState('');
+    const [unlockMinutes, setUnlockMinutes] = useState<number>(30);
 
     // 获取防篡改配置
     const {data: config, isLoading} = useQuery({
@@ -29,7 +32,11 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
     const saveMutation = useMutation({
         mutationFn: async () => {
             const values = form.getFieldsValue();
-            return updateTamperConfig(agentId, values.enabled, editPaths);
+            return updateTamperConfig(agentId, values.enabled, editPaths, {
+                mode: values.mode,
+                hashInterval: values.hashInterval || 0,
+                enforcePaths: enforcePaths.filter(p => editPaths.includes(p)),
+            });
         },
         onSuccess: () => {
             message.success('配置已保存');
@@ -41,6 +48,52 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
         },
     });
 
+    // 刷新快照 mutation
+    const snapshotMutation = useMutation({
+        mutationFn: (path: string) => refreshTamperSnapshot(agentId, [path]),
+        onSuccess: () => {
+            message.success('已通知探针刷新快照');
+            queryClient.invalidateQueries({queryKey: ['tamperConfig', agentId]});
+        },
+        onError: (error: unknown) => {
+            message.error(getErrorMessage(error, '刷新快照失败'));
+        },
+    });
+
+    // 临时解锁 mutation
+    const unlockMutation = useMutation({
+        mutationFn: (path: string) => unlockTamperPath(agentId, path, unlockMinutes),
+        onSuccess: () => {
+            message.success('已临时解除保护');
+            queryClient.invalidateQueries({queryKey: ['tamperConfig', agentId]});
+        },
+        onError: (error: unknown) => {
+            message.error(getErrorMessage(error, '临时解锁失败'));
+        },
+    });
+
+    // 提前重新保护 mutation
+    const relockMutation = useMutation({
+        mutationFn: (path: string) => relockTamperPath(agentId, path),
+        onSuccess: () => {
+            message.success('已重新保护');
+            queryClient.invalidateQueries({queryKey: ['tamperConfig', agentId]});
+        },
+        onError: (error: unknown) => {
+            message.error(getErrorMessage(error, '重新保护失败'));
+        },
+    });
+
+    // 获取目录当前有效的临时解锁窗口
+    const getUnlockWindow = (path: string) => {
+        return config?.unlocks?.find(w => w.path === path && w.until > Date.now());
+    };
+
+    // 切换强制恢复策略
+    const handleToggleEnforce = (path: string, checked: boolean) => {
+        setEnforcePaths(checked ? [...enforcePaths, path] : enforcePaths.filter(p => p !== path));
+    };
+
     // 添加路径
     const handleAddPath = () => {
         if (newPath.trim() && !editPaths.includes(newPath.trim())) {
@@ -52,19 +105,26 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
     // 删除路径
     const handleRemovePath = (path: string) => {
         setEditPaths(editPaths.filter(p => p !== path));
+        setEnforcePaths(enforcePaths.filter(p => p !== path));
     };
 
     // 初始化表单值
     useEffect(() => {
         if (config) {
             setEditPaths(config.paths || []);
+            setEnforcePaths(config.enforcePaths || []);
             form.setFieldsValue({
                 enabled: config.enabled || false,
+                mode: config.mode || 'immutable',
+                hashInterval: config.hashInterval || undefined,
             });
         } else {
             setEditPaths([]);
+            setEnforcePaths([]);
             form.setFieldsValue({
                 enabled: false,
+                mode: 'immutable',
+                hashInterval: undefined,
             });
         }
     }, [config, form]);
@@ -102,6 +162,7 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
                     layout="vertical"
                     initialValues={{
                         enabled: false,
+                        mode: 'immutable',
                     }}
                 >
                     <Form.Item
@@ -115,6 +176,30 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
                             unCheckedChildren="已禁用"
                         />
                     </Form.Item>
+                    <Form.Item
+                        label="保护模式"
+                        name="mode"
+                        extra="哈希基线模式不锁定文件，而是记录每个文件的 SHA-256 并在文件变化及定期校验时上报修改、新增、删除的文件"
+                    >
+                        <Select
+                            options={[
+                                {label: '不可变属性（chattr +i）', value: 'immutable'},
+                                {label: 'SHA-256 哈希基线', value: 'hash'},
+                                {label: '不可变属性 + 哈希基线', value: 'hybrid'},
+                            ]}
+                        />
+                    </Form.Item>
+                    <Form.Item noStyle shouldUpdate={(prev, cur) => prev.mode !== cur.mode}>
+                        {({getFieldValue}) => getFieldValue('mode') !== 'immutable' && (
+                            <Form.Item
+                                label="定期校验间隔（秒）"
+                                name="hashInterval"
+                                extra="默认 300 秒，最小 30 秒"
+                            >
+                                <InputNumber min={30} placeholder="300" style={{width: '100%'}}/>
+                            </Form.Item>
+                        )}
+                    </Form.Item>
                 </Form>
 
                 <div>
@@ -144,9 +229,63 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
                         <List
                             bordered
                             dataSource={editPaths}
-                            renderItem={(path) => (
+                            renderItem={(path) => {
+                                const unlockWindow = getUnlockWindow(path);
+                                const saved = config?.enabled && config.paths?.includes(path);
+                                return (
                                 <List.Item
                                     actions={[
+                                        ...(saved ? [unlockWindow ? (
+                                            <Popconfirm
+                                                key="relock"
+                                                title="立即重新保护？"
+                                                description="将以目录当前内容更新基线和快照"
+                                                onConfirm={() => relockMutation.mutate(path)}
+                                            >
+                                                <Tooltip title="提前结束临时解锁">
+                                                    <Button type="text" icon={<Lock size={16}/>} loading={relockMutation.isPending}/>
+                                                </Tooltip>
+                                            </Popconfirm>
+                                        ) : (
+                                            <Popconfirm
+                                                key="unlock"
+                                                title="临时解除保护"
+                                                description={
+                                                    <Space>
+                                                        <span>时长（分钟）</span>
+                                                        <InputNumber min={1} max={1440} value={unlockMinutes} onChange={(v) => setUnlockMinutes(v || 30)}/>
+                                                    </Space>
+                                                }
+                                                onConfirm={() => unlockMutation.mutate(path)}
+                                            >
+                                                <Tooltip title="临时解锁（用于发布，窗口内不通知，到期自动重新保护）">
+                                                    <Button type="text" icon={<Unlock size={16}/>} loading={unlockMutation.isPending}/>
+                                                </Tooltip>
+                                            </Popconfirm>
+                                        )] : []),
+                                        <Tooltip key="enforce" title="强制恢复：文件被修改、新增或删除后自动按快照回滚">
+                                            <Switch
+                                                size="small"
+                                                checkedChildren="强制恢复"
+                                                unCheckedChildren="仅告警"
+                                                checked={enforcePaths.includes(path)}
+                                                onChange={(checked) => handleToggleEnforce(path, checked)}
+                                            />
+                                        </Tooltip>,
+                                        ...(config?.enabled && config.enforcePaths?.includes(path) ? [
+                                            <Popconfirm
+                                                key="snapshot"
+                                                title="以当前内容刷新快照？"
+                                                description="刷新后将以目录当前内容作为恢复依据"
+                                                onConfirm={() => snapshotMutation.mutate(path)}
+                                            >
+                                                <Button
+                                                    type="text"
+                                                    icon={<Camera size={16}/>}
+                                                    loading={snapshotMutation.isPending}
+                                                />
+                                            </Popconfirm>,
+                                        ] : []),
                                         <Button
                                             key="delete"
                                             type="text"
@@ -156,9 +295,19 @@ const TamperProtectionConfig: React.FC<TamperProtectionConfigProps> = ({agentId}
                                         />,
                                     ]}
                                 >
-                                    <span className="font-mono text-sm">{path}</span>
+                                    <Space>
+                                        <span className="font-mono text-sm">{path}</span>
+                                        {unlockWindow && (
+                                            <Tooltip title={unlockWindow.reason || undefined}>
+                                                <Tag color="orange">
+                                                    临时解锁至 {dayjs(unlockWindow.until).format('HH:mm:ss')}
+                                                </Tag>
+                                            </Tooltip>
+                                        )}
+                                    </Space>
                                 </List.Item>
-                            )}
+                                );
+                            }}
                         />
                     )}
                 </div>
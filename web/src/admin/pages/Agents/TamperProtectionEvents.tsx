@@ -45,6 +45,12 @@ const TamperProtectionEvents: React.FC<TamperProtectionEventsProps> = ({agentId}
                     DELETE: 'red',
                     RENAME: 'purple',
                     CHMOD: 'cyan',
+                    added: 'blue',
+                    modified: 'orange',
+                    deleted: 'red',
+                    restore: 'green',
+                    unlock: 'gold',
+                    relock: 'geekblue',
                 };
                 return (
                     <Tag color={operationColors[record.operation] || 'default'}>
@@ -77,6 +83,45 @@ const TamperProtectionEvents: React.FC<TamperProtectionEventsProps> = ({agentId}
                 ) : '-'
             ),
         },
+        {
+            title: '进程',
+            key: 'process',
+            width: 200,
+            ellipsis: true,
+            render: (_, record) => (
+                record.process?.pid ? (
+                    <Tooltip title={<div className="text-xs">
+                        <div>PID: {record.process.pid}（父进程 {record.process.ppid}）</div>
+                        <div>用户: {record.process.username || record.process.uid}</div>
+                        {record.process.cmdline && <div>命令: {record.process.cmdline}</div>}
+                        {record.process.processTree?.map((node, index) => (
+                            <div key={index} className="font-mono">{'  '.repeat(index)}└ {node}</div>
+                        ))}
+                    </div>}>
+                        <span className="font-mono text-xs">
+                            {record.process.exe || `PID ${record.process.pid}`}
+                        </span>
+                    </Tooltip>
+                ) : '-'
+            ),
+        },
+        {
+            title: '哈希变化',
+            key: 'hash',
+            width: 220,
+            render: (_, record) => (
+                record.oldHash || record.newHash ? (
+                    <Tooltip title={<div className="font-mono text-xs">
+                        <div>基线: {record.oldHash || '-'}</div>
+                        <div>当前: {record.newHash || '-'}</div>
+                    </div>}>
+                        <span className="font-mono text-xs">
+                            {record.oldHash ? record.oldHash.slice(0, 8) : '-'} → {record.newHash ? record.newHash.slice(0, 8) : '-'}
+                        </span>
+                    </Tooltip>
+                ) : '-'
+            ),
+        },
     ];
 
     const {
@@ -1,9 +1,9 @@
 import React, {useState} from 'react';
 import {useSearchParams} from 'react-router-dom';
-import {App, Divider, Select, Space, Table, Tag} from 'antd';
+import {App, Button, Divider, Form, Input, Modal, Select, Space, Table, Tag, Tooltip} from 'antd';
 import type {ColumnsType, TablePaginationConfig} from 'antd/es/table';
 import {Trash2} from 'lucide-react';
-import {clearAlertRecords, getAlertRecords} from '@/api/alert.ts';
+import {acknowledgeAlertRecord, assignAlertRecord, clearAlertRecords, getAlertRecords} from '@/api/alert.ts';
 import type {AlertRecord} from '@/types';
 import dayjs from 'dayjs';
 import {getErrorMessage} from '@/lib/utils';
@@ -16,6 +16,12 @@ const AlertRecordList = () => {
     const queryClient = useQueryClient();
     const [searchParams, setSearchParams] = useSearchParams();
     const [selectedAgentId, setSelectedAgentId] = useState<string>('');
+    const [ackFilter, setAckFilter] = useState<string>('');
+    // 确认或指派弹窗
+    const [actionRecord, setActionRecord] = useState<AlertRecord | null>(null);
+    const [actionType, setActionType] = useState<'ack' | 'assign'>('ack');
+    const [actionLoading, setActionLoading] = useState(false);
+    const [actionForm] = Form.useForm<{ comment?: string; assignee?: string }>();
 
     const pageIndex = Number(searchParams.get('pageIndex')) || 1;
     const pageSize = Number(searchParams.get('pageSize')) || 20;
@@ -39,6 +45,9 @@ const AlertRecordList = () => {
         cert: 'HTTPS证书',
         service: '服务下线',
         agent_offline: '探针离线',
+        promql: '自定义规则',
+        disk_forecast: '磁盘写满预测',
+        traffic_forecast: '流量超额预测',
     };
 
     // 告警级别映射
@@ -52,6 +61,22 @@ const AlertRecordList = () => {
         return <Tag color={levelConfig.color}>{levelConfig.text}</Tag>;
     };
 
+    // 规则来源映射
+    const getRuleSourceTag = (record: AlertRecord) => {
+        switch (record.ruleSource) {
+            case 'agent':
+                return <Tag color="purple">探针规则集 · {record.ruleSetName}</Tag>;
+            case 'tag':
+                return <Tag color="cyan">标签规则集 · {record.ruleSetName}</Tag>;
+            case 'promql':
+                return <Tag color="geekblue">PromQL · {record.ruleSetName}</Tag>;
+            case 'global':
+                return <Tag>全局规则</Tag>;
+            default:
+                return '-';
+        }
+    };
+
     // 状态映射
     const getStatusTag = (status: string) => {
         const config = {
@@ -100,8 +125,13 @@ const AlertRecordList = () => {
         isLoading,
         isFetching,
     } = useQuery({
-        queryKey: ['admin', 'alert-records', pageIndex, pageSize, selectedAgentId],
-        queryFn: () => getAlertRecords(pageIndex, pageSize, selectedAgentId || undefined),
+        queryKey: ['admin', 'alert-records', pageIndex, pageSize, selectedAgentId, ackFilter],
+        queryFn: () => getAlertRecords(
+            pageIndex,
+            pageSize,
+            selectedAgentId || undefined,
+            ackFilter ? ackFilter === 'acked' : undefined,
+        ),
     });
 
     // 处理表格变化
@@ -121,6 +151,44 @@ const AlertRecordList = () => {
         setSearchParams(nextParams);
     };
 
+    // 处理确认状态筛选变化
+    const handleAckFilterChange = (value: string) => {
+        setAckFilter(value || '');
+        const nextParams = new URLSearchParams(searchParams);
+        nextParams.set('pageIndex', '1');
+        setSearchParams(nextParams);
+    };
+
+    const openAction = (record: AlertRecord, type: 'ack' | 'assign') => {
+        setActionRecord(record);
+        setActionType(type);
+        actionForm.resetFields();
+        actionForm.setFieldsValue({assignee: record.assignee});
+    };
+
+    const handleAction = async () => {
+        if (!actionRecord) {
+            return;
+        }
+        const values = await actionForm.validateFields();
+        setActionLoading(true);
+        try {
+            if (actionType === 'ack') {
+                await acknowledgeAlertRecord(actionRecord.id, values.comment || '');
+                messageApi.success('告警已确认');
+            } else {
+                await assignAlertRecord(actionRecord.id, values.assignee || '');
+                messageApi.success(values.assignee ? '指派成功' : '已取消指派');
+            }
+            setActionRecord(null);
+            queryClient.invalidateQueries({queryKey: ['admin', 'alert-records']});
+        } catch (error: unknown) {
+            messageApi.error(getErrorMessage(error, '操作失败'));
+        } finally {
+            setActionLoading(false);
+        }
+    };
+
     // 清空记录
     const handleClear = () => {
         modal.confirm({
@@ -166,12 +234,27 @@ const AlertRecordList = () => {
             dataIndex: 'message',
             width: 320,
             ellipsis: true,
+            render: (_, record) => {
+                const labels = Object.entries(record.labels || {})
+                    .filter(([key]) => key !== '__name__')
+                    .map(([key, value]) => `${key}="${value}"`);
+                if (labels.length === 0) {
+                    return record.message;
+                }
+                return <Tooltip title={`{${labels.join(', ')}}`}>{record.message}</Tooltip>;
+            },
         },
         {
             title: '阈值',
             dataIndex: 'threshold',
             width: 100,
             render: (_, record) => {
+                if (record.alertType === 'promql') {
+                    return '-';
+                }
+                if (record.alertType === 'disk_forecast') {
+                    return `${record.threshold.toFixed(0)} 小时`;
+                }
                 if (record.alertType === 'network') {
                     return `${record.threshold.toFixed(2)} MB/s`;
                 }
@@ -189,6 +272,12 @@ const AlertRecordList = () => {
             dataIndex: 'actualValue',
             width: 100,
             render: (_, record) => {
+                if (record.alertType === 'promql') {
+                    return record.actualValue.toFixed(2);
+                }
+                if (record.alertType === 'disk_forecast') {
+                    return `${record.actualValue.toFixed(1)} 小时`;
+                }
                 if (record.alertType === 'network') {
                     return `${record.actualValue.toFixed(2)} MB/s`;
                 }
@@ -207,7 +296,8 @@ const AlertRecordList = () => {
             width: 100,
             render: (_, record) => {
                 // 只有已恢复的告警才显示恢复值
-                if (record.status !== 'resolved' || record.resolvedValue === undefined) {
+                if (record.status !== 'resolved' || record.resolvedValue === undefined
+                    || ['promql', 'disk_forecast', 'traffic_forecast'].includes(record.alertType)) {
                     return '-';
                 }
                 if (record.alertType === 'network') {
@@ -222,6 +312,13 @@ const AlertRecordList = () => {
                 return `${record.resolvedValue.toFixed(2)}%`;
             },
         },
+        {
+            title: '规则来源',
+            dataIndex: 'ruleSource',
+            width: 180,
+            ellipsis: true,
+            render: (_, record) => getRuleSourceTag(record),
+        },
         {
             title: '告警级别',
             dataIndex: 'level',
@@ -231,8 +328,29 @@ const AlertRecordList = () => {
         {
             title: '状态',
             dataIndex: 'status',
-            width: 100,
-            render: (_, record) => getStatusTag(record.status),
+            width: 160,
+            render: (_, record) => (
+                <Space size={4}>
+                    {getStatusTag(record.status)}
+                    {record.silenced && (
+                        <Tooltip title="匹配告警静默，未发送通知">
+                            <Tag>已静默</Tag>
+                        </Tooltip>
+                    )}
+                    {record.flapping && (
+                        <Tooltip title={`抖动期间触发/恢复 ${record.flapCount || 0} 次${record.flapEndedAt ? '，已结束' : ''}`}>
+                            <Tag color={record.flapEndedAt ? 'default' : 'purple'}>抖动</Tag>
+                        </Tooltip>
+                    )}
+                    {record.inhibited && (
+                        <Tooltip
+                            title={`${record.inhibitReason || '告警抑制'}${record.inhibitedBy ? `（源告警 #${record.inhibitedBy}）` : ''}，未发送通知`}
+                        >
+                            <Tag>已抑制</Tag>
+                        </Tooltip>
+                    )}
+                </Space>
+            ),
         },
         {
             title: '触发时间',
@@ -253,6 +371,46 @@ const AlertRecordList = () => {
             width: 130,
             render: (_, record) => formatDuration(record.firedAt, record.resolvedAt, record.status),
         },
+        {
+            title: '处理',
+            key: 'handling',
+            width: 200,
+            render: (_, record) => (
+                <Space direction="vertical" size={2}>
+                    {record.acknowledgedAt ? (
+                        <Tooltip
+                            title={`${dayjs(record.acknowledgedAt).format('YYYY-MM-DD HH:mm:ss')}${record.ackComment ? `：${record.ackComment}` : ''}`}
+                        >
+                            <Tag color="green">{record.acknowledgedBy} 已确认</Tag>
+                        </Tooltip>
+                    ) : record.status === 'firing' ? (
+                        <Tag color="orange">未确认</Tag>
+                    ) : null}
+                    {record.assignee && <span className="text-xs text-gray-500">负责人：{record.assignee}</span>}
+                    {(record.repeatCount > 0 || !!record.escalatedAt) && (
+                        <span className="text-xs text-gray-500">
+                            {record.repeatCount > 0 && `已提醒 ${record.repeatCount} 次`}
+                            {record.repeatCount > 0 && !!record.escalatedAt && '，'}
+                            {!!record.escalatedAt && '已升级'}
+                        </span>
+                    )}
+                </Space>
+            ),
+        },
+        {
+            title: '操作',
+            key: 'actions',
+            width: 120,
+            fixed: 'right',
+            render: (_, record) => (
+                <Space size={0}>
+                    {record.status === 'firing' && !record.acknowledgedAt && (
+                        <Button type="link" size="small" onClick={() => openAction(record, 'ack')}>确认</Button>
+                    )}
+                    <Button type="link" size="small" onClick={() => openAction(record, 'assign')}>指派</Button>
+                </Space>
+            ),
+        },
     ];
 
     return (
@@ -289,6 +447,17 @@ const AlertRecordList = () => {
                             onChange={handleAgentChange}
                             options={agentOptions}
                         />
+                        <Select
+                            placeholder="确认状态"
+                            allowClear
+                            style={{width: 140}}
+                            value={ackFilter || undefined}
+                            onChange={handleAckFilterChange}
+                            options={[
+                                {label: '未确认', value: 'unacked'},
+                                {label: '已确认', value: 'acked'},
+                            ]}
+                        />
                     </Space>
                 </div>
 
@@ -298,7 +467,7 @@ const AlertRecordList = () => {
                     loading={isLoading || isFetching}
                     rowKey="id"
                     size={'small'}
-                    scroll={{x: 1700}}
+                    scroll={{x: 2200}}
                     tableLayout="fixed"
                     pagination={{
                         current: pageIndex,
@@ -312,6 +481,28 @@ const AlertRecordList = () => {
                     onChange={handleTableChange}
                 />
             </div>
+
+            <Modal
+                title={actionType === 'ack' ? '确认告警' : '指派负责人'}
+                open={!!actionRecord}
+                onCancel={() => setActionRecord(null)}
+                onOk={handleAction}
+                confirmLoading={actionLoading}
+                forceRender
+            >
+                <div className="text-sm text-gray-500 mb-4">{actionRecord?.message}</div>
+                <Form form={actionForm} layout="vertical">
+                    {actionType === 'ack' ? (
+                        <Form.Item label="备注" name="comment" tooltip="确认后不再重复通知和升级">
+                            <Input.TextArea rows={3} placeholder="如：已在处理，预计 30 分钟内恢复"/>
+                        </Form.Item>
+                    ) : (
+                        <Form.Item label="负责人" name="assignee" tooltip="留空表示取消指派">
+                            <Input placeholder="输入用户名"/>
+                        </Form.Item>
+                    )}
+                </Form>
+            </Modal>
         </div>
     );
 };
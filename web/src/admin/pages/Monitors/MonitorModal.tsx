@@ -3,8 +3,8 @@ import {App, Button, Form, Input, InputNumber, Modal, Select, Space, Switch} fro
 import {MinusCircle, PlusCircle} from 'lucide-react';
 import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
 import {listAgentsByAdmin} from '@/api/agent.ts';
-import {createMonitor, getMonitor, updateMonitor} from '@/api/monitor.ts';
-import type {Agent, MonitorTaskRequest} from '@/types';
+import {createMonitor, getMonitor, listMonitors, updateMonitor} from '@/api/monitor.ts';
+import type {Agent, MonitorTask, MonitorTaskRequest} from '@/types';
 import {getErrorMessage} from '@/lib/utils';
 import {hasText} from "@/lib/strings.ts";
 
@@ -37,6 +37,15 @@ const MonitorModal = ({open, monitorId, onCancel, onSuccess}: MonitorModalProps)
         enabled: open,
     });
 
+    const {data: monitors = []} = useQuery({
+        queryKey: ['admin', 'monitors', 'all'],
+        queryFn: async () => {
+            const response = await listMonitors(1, 1000);
+            return response.data.items || [];
+        },
+        enabled: open,
+    });
+
     const {
         data: monitor,
         isLoading: detailLoading,
@@ -72,6 +81,18 @@ const MonitorModal = ({open, monitorId, onCancel, onSuccess}: MonitorModalProps)
         [agents],
     );
 
+    // 父监控项不能是自身
+    const parentOptions = useMemo(
+        () =>
+            monitors
+                .filter((item: MonitorTask) => item.id !== monitorId)
+                .map((item: MonitorTask) => ({
+                    label: item.name,
+                    value: item.id,
+                })),
+        [monitors, monitorId],
+    );
+
     useEffect(() => {
         if (!open) {
             return;
@@ -89,6 +110,7 @@ const MonitorModal = ({open, monitorId, onCancel, onSuccess}: MonitorModalProps)
                 interval: 60,
                 agentIds: [],
                 tags: [],
+                parentId: undefined,
                 httpMethod: 'GET',
                 httpTimeout: 60,
                 httpExpectedStatusCode: 200,
@@ -120,6 +142,7 @@ const MonitorModal = ({open, monitorId, onCancel, onSuccess}: MonitorModalProps)
             interval: monitor.interval || 60,
             agentIds: monitor.agentIds || [],
             tags: monitor.tags || [],
+            parentId: monitor.parentId || undefined,
             httpMethod: monitor.httpConfig?.method || 'GET',
             httpTimeout: monitor.httpConfig?.timeout || 60,
             httpExpectedStatusCode: monitor.httpConfig?.expectedStatusCode || 200,
@@ -168,6 +191,7 @@ const MonitorModal = ({open, monitorId, onCancel, onSuccess}: MonitorModalProps)
                 interval: values.interval || 60,
                 agentIds: values.agentIds || [],
                 tags: values.tags || [],
+                parentId: values.parentId || '',
             };
 
             if (values.type === 'tcp') {
@@ -276,6 +300,20 @@ const MonitorModal = ({open, monitorId, onCancel, onSuccess}: MonitorModalProps)
                     />
                 </Form.Item>
 
+                <Form.Item
+                    label="父监控项"
+                    name="parentId"
+                    extra="父监控项下线时，不再通知本监控项的服务下线和证书告警"
+                >
+                    <Select
+                        placeholder="选择上游依赖的监控项（可选）"
+                        options={parentOptions}
+                        showSearch
+                        optionFilterProp="label"
+                        allowClear
+                    />
+                </Form.Item>
+
                 <Form.Item
                     label="检测频率 (秒)"
                     name="interval"
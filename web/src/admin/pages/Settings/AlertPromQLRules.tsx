@@ -0,0 +1,315 @@
+import { useState } from 'react';
+import { App, Button, Form, Input, InputNumber, Modal, Popconfirm, Select, Space, Switch, Table, Tag } from 'antd';
+import type { ColumnsType } from 'antd/es/table';
+import { Play, Plus, Trash2 } from 'lucide-react';
+import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
+import type { AlertPromQLRule, AlertPromQLRuleRequest, PromQLSeries } from '@/types';
+import {
+    createAlertPromQLRule,
+    deleteAlertPromQLRule,
+    getAlertPromQLRules,
+    previewPromQL,
+    updateAlertPromQLRule,
+} from '@/api/alert';
+import { getErrorMessage } from '@/lib/utils';
+
+const severityOptions = [
+    { value: 'info', label: '信息', color: 'blue' },
+    { value: 'warning', label: '警告', color: 'orange' },
+    { value: 'critical', label: '严重', color: 'red' },
+];
+
+// 表单中标签以键值对列表编辑
+interface PromQLRuleFormValues extends Omit<AlertPromQLRuleRequest, 'labels'> {
+    labels?: { key: string; value: string }[];
+}
+
+const formatLabels = (labels: Record<string, string>) =>
+    Object.entries(labels)
+        .filter(([key]) => key !== '__name__')
+        .map(([key, value]) => `${key}="${value}"`)
+        .join(', ');
+
+const AlertPromQLRules = () => {
+    const [form] = Form.useForm<PromQLRuleFormValues>();
+    const { message: messageApi } = App.useApp();
+    const queryClient = useQueryClient();
+    const [modalOpen, setModalOpen] = useState(false);
+    const [editing, setEditing] = useState<AlertPromQLRule | null>(null);
+    const [previewSeries, setPreviewSeries] = useState<PromQLSeries[] | null>(null);
+
+    const { data: rules, isLoading } = useQuery({
+        queryKey: ['admin', 'alert-promql-rules'],
+        queryFn: getAlertPromQLRules,
+    });
+
+    const invalidate = () => queryClient.invalidateQueries({ queryKey: ['admin', 'alert-promql-rules'] });
+
+    const saveMutation = useMutation({
+        mutationFn: (values: AlertPromQLRuleRequest) =>
+            editing ? updateAlertPromQLRule(editing.id, values) : createAlertPromQLRule(values),
+        onSuccess: () => {
+            messageApi.success(editing ? '规则更新成功' : '规则创建成功');
+            setModalOpen(false);
+            invalidate();
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '保存规则失败'));
+        },
+    });
+
+    const deleteMutation = useMutation({
+        mutationFn: deleteAlertPromQLRule,
+        onSuccess: () => {
+            messageApi.success('规则已删除');
+            invalidate();
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '删除规则失败'));
+        },
+    });
+
+    const previewMutation = useMutation({
+        mutationFn: previewPromQL,
+        onSuccess: (series) => setPreviewSeries(series),
+        onError: (error: unknown) => {
+            setPreviewSeries(null);
+            messageApi.error(getErrorMessage(error, '查询失败'));
+        },
+    });
+
+    const openModal = (rule?: AlertPromQLRule) => {
+        setEditing(rule || null);
+        setPreviewSeries(null);
+        form.resetFields();
+        if (rule) {
+            form.setFieldsValue({
+                ...rule,
+                labels: Object.entries(rule.labels || {}).map(([key, value]) => ({ key, value })),
+            });
+        } else {
+            form.setFieldsValue({
+                enabled: true,
+                for: 60,
+                severity: 'warning',
+                labels: [],
+            });
+        }
+        setModalOpen(true);
+    };
+
+    const handlePreview = () => {
+        const expr = form.getFieldValue('expr');
+        if (!expr?.trim()) {
+            messageApi.warning('请先输入 PromQL 表达式');
+            return;
+        }
+        previewMutation.mutate(expr);
+    };
+
+    const handleSubmit = async () => {
+        const values = await form.validateFields();
+        const labels: Record<string, string> = {};
+        values.labels?.forEach((item) => {
+            if (item?.key) {
+                labels[item.key] = item.value || '';
+            }
+        });
+        saveMutation.mutate({ ...values, labels });
+    };
+
+    const columns: ColumnsType<AlertPromQLRule> = [
+        {
+            title: '名称',
+            dataIndex: 'name',
+            key: 'name',
+            width: 180,
+            render: (_, record) => (
+                <div>
+                    <div className="font-medium">{record.name}</div>
+                    {record.description && <div className="text-xs text-gray-500">{record.description}</div>}
+                </div>
+            ),
+        },
+        {
+            title: '表达式',
+            dataIndex: 'expr',
+            key: 'expr',
+            render: (_, record) => <span className="font-mono text-xs break-all">{record.expr}</span>,
+        },
+        {
+            title: '持续时间',
+            dataIndex: 'for',
+            key: 'for',
+            width: 100,
+            render: (_, record) => `${record.for} 秒`,
+        },
+        {
+            title: '级别',
+            dataIndex: 'severity',
+            key: 'severity',
+            width: 80,
+            render: (_, record) => {
+                const option = severityOptions.find((item) => item.value === record.severity);
+                return <Tag color={option?.color}>{option?.label || record.severity}</Tag>;
+            },
+        },
+        {
+            title: '状态',
+            dataIndex: 'enabled',
+            key: 'enabled',
+            width: 80,
+            render: (_, record) => record.enabled ? (
+                <Tag variant={'filled'} color="success">启用</Tag>
+            ) : (
+                <Tag variant={'filled'}>停用</Tag>
+            ),
+        },
+        {
+            title: '操作',
+            key: 'actions',
+            width: 140,
+            render: (_, record) => (
+                <Space>
+                    <Button type="link" size="small" onClick={() => openModal(record)}>编辑</Button>
+                    <Popconfirm
+                        title="确定删除该规则吗？"
+                        description="该规则正在进行的告警将被标记为已恢复"
+                        onConfirm={() => deleteMutation.mutate(record.id)}
+                    >
+                        <Button type="link" size="small" danger>删除</Button>
+                    </Popconfirm>
+                </Space>
+            ),
+        },
+    ];
+
+    return (
+        <div className="space-y-4">
+            <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center' }}>
+                <div className="text-sm text-gray-500">
+                    基于 VictoriaMetrics 中的指标自定义告警，每 30 秒评估一次，表达式返回的每个序列独立触发和恢复告警
+                </div>
+                <Button type="primary" icon={<Plus size={16} />} onClick={() => openModal()}>
+                    新建规则
+                </Button>
+            </div>
+
+            <Table<AlertPromQLRule>
+                columns={columns}
+                dataSource={rules || []}
+                loading={isLoading}
+                rowKey="id"
+                pagination={false}
+                locale={{ emptyText: '暂无自定义 PromQL 规则' }}
+            />
+
+            <Modal
+                title={editing ? '编辑 PromQL 规则' : '新建 PromQL 规则'}
+                open={modalOpen}
+                width={800}
+                onCancel={() => setModalOpen(false)}
+                onOk={handleSubmit}
+                confirmLoading={saveMutation.isPending}
+                forceRender
+            >
+                <Form form={form} layout="vertical">
+                    <Form.Item label="名称" name="name" rules={[{ required: true, message: '请输入名称' }]}>
+                        <Input placeholder="如：磁盘写入过高" />
+                    </Form.Item>
+                    <Form.Item label="描述" name="description">
+                        <Input.TextArea rows={2} />
+                    </Form.Item>
+                    <Form.Item
+                        label="PromQL 表达式"
+                        name="expr"
+                        rules={[{ required: true, message: '请输入 PromQL 表达式' }]}
+                        tooltip="表达式返回的序列即为告警中的序列，如 pika_disk_write_bytes_rate > 100 * 1024 * 1024"
+                    >
+                        <Input.TextArea
+                            rows={3}
+                            className="font-mono"
+                            placeholder='avg by (agent_id) (pika_cpu_usage_percent) > 90'
+                        />
+                    </Form.Item>
+                    <div className="mb-4 space-y-2">
+                        <Button icon={<Play size={14} />} loading={previewMutation.isPending} onClick={handlePreview}>
+                            预览当前结果
+                        </Button>
+                        {previewSeries && (
+                            <Table<PromQLSeries>
+                                size="small"
+                                dataSource={previewSeries}
+                                rowKey={(item) => formatLabels(item.labels)}
+                                pagination={false}
+                                scroll={{ y: 200 }}
+                                locale={{ emptyText: '当前没有序列满足条件' }}
+                                columns={[
+                                    {
+                                        title: '标签',
+                                        key: 'labels',
+                                        render: (_, item) => (
+                                            <span className="font-mono text-xs break-all">{`{${formatLabels(item.labels)}}`}</span>
+                                        ),
+                                    },
+                                    {
+                                        title: '值',
+                                        dataIndex: 'value',
+                                        key: 'value',
+                                        width: 120,
+                                        render: (_, item) => item.value.toFixed(2),
+                                    },
+                                ]}
+                            />
+                        )}
+                    </div>
+                    <div className="flex items-center gap-8">
+                        <Form.Item label="持续时间（秒）" name="for" tooltip="序列持续存在多久后触发告警，0 表示立即触发">
+                            <InputNumber min={0} max={86400} />
+                        </Form.Item>
+                        <Form.Item label="告警级别" name="severity">
+                            <Select
+                                style={{ width: 120 }}
+                                options={severityOptions.map(({ value, label }) => ({ value, label }))}
+                            />
+                        </Form.Item>
+                        <Form.Item label="启用" name="enabled" valuePropName="checked">
+                            <Switch checkedChildren="开启" unCheckedChildren="关闭" />
+                        </Form.Item>
+                    </div>
+                    <Form.Item
+                        label="告警消息"
+                        name="summary"
+                        tooltip="支持 {{value}}、{{labels}}、{{labels.xxx}}、{{rule.name}}，留空时使用默认格式"
+                    >
+                        <Input placeholder="{{labels.mountpoint}} 写入速率 {{value}} B/s" />
+                    </Form.Item>
+                    <Form.Item label="附加标签" tooltip="附加到告警记录，同名时覆盖序列的标签">
+                        <Form.List name="labels">
+                            {(fields, { add, remove }) => (
+                                <div className="space-y-2">
+                                    {fields.map(({ key, name }) => (
+                                        <Space key={key} align="baseline">
+                                            <Form.Item name={[name, 'key']} noStyle>
+                                                <Input placeholder="名称" style={{ width: 180 }} />
+                                            </Form.Item>
+                                            <Form.Item name={[name, 'value']} noStyle>
+                                                <Input placeholder="值" style={{ width: 240 }} />
+                                            </Form.Item>
+                                            <Button type="text" icon={<Trash2 size={14} />} onClick={() => remove(name)} />
+                                        </Space>
+                                    ))}
+                                    <Button type="dashed" icon={<Plus size={14} />} onClick={() => add()}>
+                                        添加标签
+                                    </Button>
+                                </div>
+                            )}
+                        </Form.List>
+                    </Form.Item>
+                </Form>
+            </Modal>
+        </div>
+    );
+};
+
+export default AlertPromQLRules;
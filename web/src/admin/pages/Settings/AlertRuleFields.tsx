@@ -0,0 +1,265 @@
+import { Card, Form, InputNumber, Select, Switch } from 'antd';
+
+// 告警规则表单项（全局告警配置和告警规则集共用，字段位于 rules 下）
+const AlertRuleFields = () => {
+    return (
+        <>
+            {[
+                { key: 'cpu', title: 'CPU 告警规则', thresholdLabel: 'CPU 使用率阈值 (%)', max: 100 },
+                { key: 'memory', title: '内存告警规则', thresholdLabel: '内存使用率阈值 (%)', max: 100 },
+                {
+                    key: 'disk',
+                    title: '磁盘告警规则',
+                    thresholdLabel: '磁盘使用率阈值 (%)',
+                    max: 100,
+                    targets: { name: 'diskMountPoints', label: '目标挂载点', placeholder: '如 /var、~^/data' },
+                },
+                {
+                    key: 'network',
+                    title: '网速告警规则',
+                    thresholdLabel: '网速阈值 (MB/s)',
+                    max: 10000,
+                    targets: { name: 'networkInterfaces', label: '目标网卡', placeholder: '如 eth0、~^ens' },
+                },
+            ].map((rule) => (
+                <Card key={rule.key} title={rule.title} type="inner">
+                    <Form.Item noStyle shouldUpdate>
+                        {({ getFieldValue }) => {
+                            const enabled = getFieldValue(['rules', `${rule.key}Enabled`]);
+                            return (
+                                <div className="flex items-center gap-8 flex-wrap">
+                                    <Form.Item
+                                        label="开关"
+                                        name={['rules', `${rule.key}Enabled`]}
+                                        valuePropName="checked"
+                                        className="mb-0"
+                                    >
+                                        <Switch />
+                                    </Form.Item>
+                                    <Form.Item
+                                        label={rule.thresholdLabel}
+                                        name={['rules', `${rule.key}Threshold`]}
+                                        className="mb-0"
+                                    >
+                                        <InputNumber
+                                            min={0}
+                                            max={rule.max}
+                                            style={{ width: '100%' }}
+                                            disabled={!enabled}
+                                        />
+                                    </Form.Item>
+                                    <Form.Item
+                                        label="持续时间（秒）"
+                                        name={['rules', `${rule.key}Duration`]}
+                                        className="mb-0"
+                                    >
+                                        <InputNumber min={1} max={3600} style={{ width: '100%' }}
+                                            disabled={!enabled} />
+                                    </Form.Item>
+                                    <Form.Item
+                                        label="恢复阈值"
+                                        name={['rules', `${rule.key}RecoveryThreshold`]}
+                                        className="mb-0"
+                                        tooltip="告警中低于该值才开始恢复，留空或 0 表示与告警阈值相同"
+                                    >
+                                        <InputNumber
+                                            min={0}
+                                            max={rule.max}
+                                            style={{ width: '100%' }}
+                                            disabled={!enabled}
+                                        />
+                                    </Form.Item>
+                                    <Form.Item
+                                        label="恢复持续时间（秒）"
+                                        name={['rules', `${rule.key}RecoveryDuration`]}
+                                        className="mb-0"
+                                        tooltip="低于恢复阈值持续该时间后才恢复，0 表示立即恢复"
+                                    >
+                                        <InputNumber min={0} max={3600} style={{ width: '100%' }}
+                                            disabled={!enabled} />
+                                    </Form.Item>
+                                    {rule.targets && (
+                                        <Form.Item
+                                            label={rule.targets.label}
+                                            name={['rules', rule.targets.name]}
+                                            className="mb-0"
+                                            tooltip="按名称精确匹配，以 ~ 开头时按正则匹配；每个匹配项单独告警，留空时按汇总值告警"
+                                        >
+                                            <Select
+                                                mode="tags"
+                                                open={false}
+                                                tokenSeparators={[',', ' ']}
+                                                placeholder={rule.targets.placeholder}
+                                                style={{ minWidth: 240 }}
+                                                disabled={!enabled}
+                                            />
+                                        </Form.Item>
+                                    )}
+                                </div>
+                            );
+                        }}
+                    </Form.Item>
+                </Card>
+            ))}
+
+            <Card title="HTTPS 证书告警规则" type="inner">
+                <Form.Item noStyle shouldUpdate>
+                    {({ getFieldValue }) => {
+                        const enabled = getFieldValue(['rules', 'certEnabled']);
+                        return (
+                            <div className="flex items-center gap-8">
+                                <Form.Item
+                                    label="开关"
+                                    name={['rules', 'certEnabled']}
+                                    valuePropName="checked"
+                                    className="mb-0"
+                                >
+                                    <Switch />
+                                </Form.Item>
+                                <Form.Item
+                                    label="证书剩余天数阈值（天）"
+                                    name={['rules', 'certThreshold']}
+                                    className="mb-0"
+                                    tooltip="当证书剩余天数低于此阈值时触发告警"
+                                >
+                                    <InputNumber
+                                        min={1}
+                                        max={365}
+                                        style={{ width: '100%' }}
+                                        disabled={!enabled}
+                                    />
+                                </Form.Item>
+                            </div>
+                        );
+                    }}
+                </Form.Item>
+            </Card>
+
+            <Card title="服务下线告警规则" type="inner">
+                <Form.Item noStyle shouldUpdate>
+                    {({ getFieldValue }) => {
+                        const enabled = getFieldValue(['rules', 'serviceEnabled']);
+                        return (
+                            <div className="flex items-center gap-8">
+                                <Form.Item
+                                    label="开关"
+                                    name={['rules', 'serviceEnabled']}
+                                    valuePropName="checked"
+                                    className="mb-0"
+                                >
+                                    <Switch />
+                                </Form.Item>
+                                <Form.Item
+                                    label="持续时间（秒）"
+                                    name={['rules', 'serviceDuration']}
+                                    className="mb-0"
+                                    tooltip="服务持续离线多久后触发告警"
+                                >
+                                    <InputNumber
+                                        min={1}
+                                        max={3600}
+                                        style={{ width: '100%' }}
+                                        disabled={!enabled}
+                                    />
+                                </Form.Item>
+                            </div>
+                        );
+                    }}
+                </Form.Item>
+            </Card>
+
+            <Card title="探针离线告警规则" type="inner">
+                <Form.Item noStyle shouldUpdate>
+                    {({ getFieldValue }) => {
+                        const enabled = getFieldValue(['rules', 'agentOfflineEnabled']);
+                        return (
+                            <div className="flex items-center gap-8">
+                                <Form.Item
+                                    label="开关"
+                                    name={['rules', 'agentOfflineEnabled']}
+                                    valuePropName="checked"
+                                    className="mb-0"
+                                >
+                                    <Switch />
+                                </Form.Item>
+                                <Form.Item
+                                    label="持续时间（秒）"
+                                    name={['rules', 'agentOfflineDuration']}
+                                    className="mb-0"
+                                    tooltip="探针持续离线多久后触发告警"
+                                >
+                                    <InputNumber
+                                        min={1}
+                                        max={3600}
+                                        style={{ width: '100%' }}
+                                        disabled={!enabled}
+                                    />
+                                </Form.Item>
+                            </div>
+                        );
+                    }}
+                </Form.Item>
+            </Card>
+
+            <Card title="预测告警规则" type="inner">
+                <div className="text-sm text-gray-500 mb-4">
+                    对各挂载点剩余空间做线性回归预测写满时间；流量按当前计费周期的平均使用速度预测重置前是否超出限额
+                </div>
+                <Form.Item noStyle shouldUpdate>
+                    {({ getFieldValue }) => {
+                        const enabled = getFieldValue(['rules', 'diskForecastEnabled']);
+                        return (
+                            <div className="flex items-center gap-8 flex-wrap">
+                                <Form.Item
+                                    label="磁盘写满预测"
+                                    name={['rules', 'diskForecastEnabled']}
+                                    valuePropName="checked"
+                                    className="mb-0"
+                                >
+                                    <Switch />
+                                </Form.Item>
+                                <Form.Item
+                                    label="预计写满时间（小时）"
+                                    name={['rules', 'diskForecastHours']}
+                                    className="mb-0"
+                                    tooltip="预计在该时间内写满时触发告警，低于一半时为严重告警"
+                                >
+                                    <InputNumber
+                                        min={1}
+                                        max={720}
+                                        style={{ width: '100%' }}
+                                        disabled={!enabled}
+                                    />
+                                </Form.Item>
+                                <Form.Item
+                                    label="预测依据（小时）"
+                                    name={['rules', 'diskForecastLookback']}
+                                    className="mb-0"
+                                    tooltip="使用最近多少小时的数据计算趋势"
+                                >
+                                    <InputNumber
+                                        min={1}
+                                        max={168}
+                                        style={{ width: '100%' }}
+                                        disabled={!enabled}
+                                    />
+                                </Form.Item>
+                            </div>
+                        );
+                    }}
+                </Form.Item>
+                <Form.Item
+                    label="流量超额预测"
+                    name={['rules', 'trafficForecastEnabled']}
+                    valuePropName="checked"
+                    tooltip="仅对设置了流量限额和重置日的探针生效，周期开始满一天后才预测"
+                    className="mb-0 mt-4"
+                >
+                    <Switch />
+                </Form.Item>
+            </Card>
+        </>
+    );
+};
+
+export default AlertRuleFields;
@@ -0,0 +1,254 @@
+import { useState } from 'react';
+import { App, Button, Card, Form, Input, InputNumber, Modal, Popconfirm, Select, Space, Switch, Table, Tag } from 'antd';
+import type { ColumnsType } from 'antd/es/table';
+import { Plus } from 'lucide-react';
+import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
+import type { AlertRuleSet, AlertRuleSetRequest } from '@/types';
+import { createAlertRuleSet, deleteAlertRuleSet, getAlertRuleSets, updateAlertRuleSet } from '@/api/alert';
+import { getAlertConfig } from '@/api/property';
+import { getTags, listAgentsByAdmin } from '@/api/agent';
+import { getErrorMessage } from '@/lib/utils';
+import AlertRuleFields from './AlertRuleFields';
+
+const AlertRuleSets = () => {
+    const [form] = Form.useForm<AlertRuleSetRequest>();
+    const { message: messageApi } = App.useApp();
+    const queryClient = useQueryClient();
+    const [modalOpen, setModalOpen] = useState(false);
+    const [editing, setEditing] = useState<AlertRuleSet | null>(null);
+
+    const { data: ruleSets, isLoading } = useQuery({
+        queryKey: ['admin', 'alert-rule-sets'],
+        queryFn: getAlertRuleSets,
+    });
+
+    const { data: alertConfig } = useQuery({
+        queryKey: ['alertConfig'],
+        queryFn: getAlertConfig,
+    });
+
+    const { data: agents } = useQuery({
+        queryKey: ['agents-for-alert-rule-sets'],
+        queryFn: async () => {
+            const response = await listAgentsByAdmin();
+            return response.data;
+        },
+    });
+
+    const { data: tags } = useQuery({
+        queryKey: ['admin', 'agents', 'tags'],
+        queryFn: async () => {
+            const response = await getTags();
+            return response.data.tags || [];
+        },
+    });
+
+    const agentNameMap = new Map((agents || []).map((agent) => [agent.id, agent.name || agent.id]));
+
+    const invalidate = () => queryClient.invalidateQueries({ queryKey: ['admin', 'alert-rule-sets'] });
+
+    const saveMutation = useMutation({
+        mutationFn: (values: AlertRuleSetRequest) =>
+            editing ? updateAlertRuleSet(editing.id, values) : createAlertRuleSet(values),
+        onSuccess: () => {
+            messageApi.success(editing ? '规则集更新成功' : '规则集创建成功');
+            setModalOpen(false);
+            invalidate();
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '保存规则集失败'));
+        },
+    });
+
+    const deleteMutation = useMutation({
+        mutationFn: deleteAlertRuleSet,
+        onSuccess: () => {
+            messageApi.success('规则集已删除');
+            invalidate();
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '删除规则集失败'));
+        },
+    });
+
+    const openModal = (ruleSet?: AlertRuleSet) => {
+        setEditing(ruleSet || null);
+        form.resetFields();
+        if (ruleSet) {
+            form.setFieldsValue(ruleSet);
+        } else {
+            // 新建时以全局规则为初始值
+            form.setFieldsValue({
+                enabled: true,
+                priority: 0,
+                agentIds: [],
+                tags: [],
+                rules: alertConfig?.rules,
+            });
+        }
+        setModalOpen(true);
+    };
+
+    const handleSubmit = async () => {
+        const values = await form.validateFields();
+        saveMutation.mutate(values);
+    };
+
+    const columns: ColumnsType<AlertRuleSet> = [
+        {
+            title: '名称',
+            dataIndex: 'name',
+            key: 'name',
+            width: 180,
+            render: (_, record) => (
+                <div>
+                    <div className="font-medium">{record.name}</div>
+                    {record.description && <div className="text-xs text-gray-500">{record.description}</div>}
+                </div>
+            ),
+        },
+        {
+            title: '适用范围',
+            key: 'scope',
+            render: (_, record) => (
+                <Space size={[4, 4]} wrap>
+                    {record.agentIds?.map((id) => (
+                        <Tag key={`agent-${id}`} color="blue">{agentNameMap.get(id) || id}</Tag>
+                    ))}
+                    {record.tags?.map((tag) => (
+                        <Tag key={`tag-${tag}`}>#{tag}</Tag>
+                    ))}
+                </Space>
+            ),
+        },
+        {
+            title: '优先级',
+            dataIndex: 'priority',
+            key: 'priority',
+            width: 80,
+        },
+        {
+            title: '状态',
+            dataIndex: 'enabled',
+            key: 'enabled',
+            width: 80,
+            render: (_, record) => record.enabled ? (
+                <Tag variant={'filled'} color="success">启用</Tag>
+            ) : (
+                <Tag variant={'filled'}>停用</Tag>
+            ),
+        },
+        {
+            title: '操作',
+            key: 'actions',
+            width: 140,
+            render: (_, record) => (
+                <Space>
+                    <Button type="link" size="small" onClick={() => openModal(record)}>编辑</Button>
+                    <Popconfirm
+                        title="确定删除该规则集吗？"
+                        description="删除后匹配的探针将回退到标签规则集或全局规则"
+                        onConfirm={() => deleteMutation.mutate(record.id)}
+                    >
+                        <Button type="link" size="small" danger>删除</Button>
+                    </Popconfirm>
+                </Space>
+            ),
+        },
+    ];
+
+    return (
+        <div className="space-y-4">
+            <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center' }}>
+                <div className="text-sm text-gray-500">
+                    按探针或标签覆盖全局告警规则，优先级：探针 &gt; 标签 &gt; 全局；同一层级匹配多个规则集时优先级数值大的生效
+                </div>
+                <Button type="primary" icon={<Plus size={16} />} onClick={() => openModal()}>
+                    新建规则集
+                </Button>
+            </div>
+
+            <Table<AlertRuleSet>
+                columns={columns}
+                dataSource={ruleSets || []}
+                loading={isLoading}
+                rowKey="id"
+                pagination={false}
+                locale={{ emptyText: '暂无规则集，所有探针使用全局告警规则' }}
+            />
+
+            <Modal
+                title={editing ? '编辑告警规则集' : '新建告警规则集'}
+                open={modalOpen}
+                width={900}
+                onCancel={() => setModalOpen(false)}
+                onOk={handleSubmit}
+                confirmLoading={saveMutation.isPending}
+                forceRender
+            >
+                <Form form={form} layout="vertical">
+                    <Space direction="vertical" className="w-full">
+                        <Card title="基本信息" type="inner">
+                            <Form.Item label="名称" name="name" rules={[{ required: true, message: '请输入名称' }]}>
+                                <Input placeholder="如：数据库服务器" />
+                            </Form.Item>
+                            <Form.Item label="描述" name="description">
+                                <Input.TextArea rows={2} />
+                            </Form.Item>
+                            <Form.Item
+                                label="指定探针"
+                                name="agentIds"
+                                tooltip="按探针匹配的规则集优先于按标签匹配的规则集"
+                            >
+                                <Select
+                                    mode="multiple"
+                                    allowClear
+                                    placeholder="选择探针"
+                                    optionFilterProp="label"
+                                    options={(agents || []).map((agent) => ({
+                                        label: agent.name || agent.id,
+                                        value: agent.id,
+                                    }))}
+                                />
+                            </Form.Item>
+                            <Form.Item
+                                label="指定标签"
+                                name="tags"
+                                dependencies={['agentIds']}
+                                rules={[
+                                    ({ getFieldValue }) => ({
+                                        validator: (_, value: string[]) => {
+                                            if ((value?.length || 0) > 0 || (getFieldValue('agentIds')?.length || 0) > 0) {
+                                                return Promise.resolve();
+                                            }
+                                            return Promise.reject(new Error('请至少指定一个探针或标签'));
+                                        },
+                                    }),
+                                ]}
+                            >
+                                <Select
+                                    mode="tags"
+                                    allowClear
+                                    placeholder="选择或输入标签"
+                                    options={(tags || []).map((tag) => ({ label: tag, value: tag }))}
+                                />
+                            </Form.Item>
+                            <div className="flex items-center gap-8">
+                                <Form.Item label="优先级" name="priority" className="mb-0">
+                                    <InputNumber min={0} max={1000} />
+                                </Form.Item>
+                                <Form.Item label="启用" name="enabled" valuePropName="checked" className="mb-0">
+                                    <Switch checkedChildren="开启" unCheckedChildren="关闭" />
+                                </Form.Item>
+                            </div>
+                        </Card>
+
+                        <AlertRuleFields />
+                    </Space>
+                </Form>
+            </Modal>
+        </div>
+    );
+};
+
+export default AlertRuleSets;
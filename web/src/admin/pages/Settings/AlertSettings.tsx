@@ -1,9 +1,26 @@
 import { useEffect } from 'react';
-import { App, Button, Card, Form, InputNumber, Space, Switch } from 'antd';
+import { App, Button, Card, Form, Input, InputNumber, Select, Space, Switch } from 'antd';
+import { MinusCircle, PlusCircle } from 'lucide-react';
 import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
 import type { AlertConfig } from '@/api/property';
-import { getAlertConfig, saveAlertConfig } from '@/api/property';
+import { getAlertConfig, getNotificationChannels, saveAlertConfig } from '@/api/property';
 import { getErrorMessage } from '@/lib/utils';
+import AlertRuleFields from './AlertRuleFields';
+import { getNotificationChannelTypeLabel } from './NotificationChannelFields';
+
+// 可参与抑制的告警类型
+const inhibitAlertTypeOptions = [
+    { value: 'agent_offline', label: '探针离线' },
+    { value: 'service', label: '服务下线' },
+    { value: 'cert', label: 'HTTPS证书' },
+    { value: 'cpu', label: 'CPU使用率' },
+    { value: 'memory', label: '内存使用率' },
+    { value: 'disk', label: '磁盘使用率' },
+    { value: 'network', label: '网速' },
+    { value: 'promql', label: '自定义规则' },
+    { value: 'disk_forecast', label: '磁盘写满预测' },
+    { value: 'traffic_forecast', label: '流量超额预测' },
+];
 
 const AlertSettings = () => {
     const [form] = Form.useForm();
@@ -16,6 +33,17 @@ const AlertSettings = () => {
         queryFn: getAlertConfig,
     });
 
+    // 获取通知渠道，用于选择升级通知渠道
+    const { data: channels = [] } = useQuery({
+        queryKey: ['notificationChannels'],
+        queryFn: getNotificationChannels,
+    });
+
+    const escalationChannelOptions = channels.map((channel) => ({
+        value: channel.id,
+        label: channel.name || getNotificationChannelTypeLabel(channel.type),
+    }));
+
     // 设置表单默认值
     useEffect(() => {
         if (configData) {
@@ -73,6 +101,13 @@ const AlertSettings = () => {
                         >
                             <Switch checkedChildren="开启" unCheckedChildren="关闭" />
                         </Form.Item>
+                        <Form.Item
+                            label="SSH 暴力破解封禁通知"
+                            name={['notifications', 'sshBanEnabled']}
+                            valuePropName="checked"
+                        >
+                            <Switch checkedChildren="开启" unCheckedChildren="关闭" />
+                        </Form.Item>
                         <Form.Item
                             label="防篡改事件通知"
                             name={['notifications', 'tamperEventEnabled']}
@@ -80,155 +115,163 @@ const AlertSettings = () => {
                         >
                             <Switch checkedChildren="开启" unCheckedChildren="关闭" />
                         </Form.Item>
+                        <Form.Item
+                            label="审计资产变化通知"
+                            name={['notifications', 'auditDriftEnabled']}
+                            valuePropName="checked"
+                        >
+                            <Switch checkedChildren="开启" unCheckedChildren="关闭" />
+                        </Form.Item>
+                    </Card>
+
+                    <Card title="重复通知与升级" type="inner">
+                        <div className="text-sm text-gray-500 mb-4">
+                            仅对未确认的严重告警生效，在告警记录中确认后停止提醒
+                        </div>
+                        <div className="flex items-center gap-8 flex-wrap">
+                            <Form.Item
+                                label="重复通知"
+                                name={['escalation', 'repeatEnabled']}
+                                valuePropName="checked"
+                            >
+                                <Switch checkedChildren="开启" unCheckedChildren="关闭" />
+                            </Form.Item>
+                            <Form.Item label="间隔（分钟）" name={['escalation', 'repeatInterval']}>
+                                <InputNumber min={1} max={1440} />
+                            </Form.Item>
+                            <Form.Item
+                                label="最多次数"
+                                name={['escalation', 'repeatMaxTimes']}
+                                tooltip="0 表示不限制"
+                            >
+                                <InputNumber min={0} max={1000} />
+                            </Form.Item>
+                        </div>
+                        <div className="flex items-center gap-8 flex-wrap">
+                            <Form.Item
+                                label="超时升级"
+                                name={['escalation', 'escalationEnabled']}
+                                valuePropName="checked"
+                            >
+                                <Switch checkedChildren="开启" unCheckedChildren="关闭" />
+                            </Form.Item>
+                            <Form.Item
+                                label="超时时间（分钟）"
+                                name={['escalation', 'escalationTimeout']}
+                                tooltip="告警触发后超过该时间仍未确认，则发送到升级通知渠道"
+                            >
+                                <InputNumber min={1} max={10080} />
+                            </Form.Item>
+                        </div>
+                        <Form.Item
+                            label="升级通知渠道"
+                            name={['escalation', 'escalationChannels']}
+                            tooltip="在通知渠道中配置即可，无需启用；升级后的重复通知也会发送到这些渠道"
+                            className="mb-0"
+                        >
+                            <Select
+                                mode="multiple"
+                                allowClear
+                                placeholder="选择升级通知渠道"
+                                options={escalationChannelOptions}
+                            />
+                        </Form.Item>
                     </Card>
 
-                    {/*<Divider orientation="left">告警规则</Divider>*/}
-
-                    {[
-                        { key: 'cpu', title: 'CPU 告警规则', thresholdLabel: 'CPU 使用率阈值 (%)', max: 100 },
-                        { key: 'memory', title: '内存告警规则', thresholdLabel: '内存使用率阈值 (%)', max: 100 },
-                        { key: 'disk', title: '磁盘告警规则', thresholdLabel: '磁盘使用率阈值 (%)', max: 100 },
-                        { key: 'network', title: '网速告警规则', thresholdLabel: '网速阈值 (MB/s)', max: 10000 },
-                    ].map((rule) => (
-                        <Card key={rule.key} title={rule.title} type="inner">
-                            <Form.Item noStyle shouldUpdate>
-                                {({ getFieldValue }) => {
-                                    const enabled = getFieldValue(['rules', `${rule.key}Enabled`]);
-                                    return (
-                                        <div className="flex items-center gap-8">
+                    <Card title="抖动检测" type="inner">
+                        <div className="text-sm text-gray-500 mb-4">
+                            CPU、内存、磁盘和网速告警在窗口内频繁触发/恢复时合并为一条抖动告警，只通知一次，窗口内不再变化后发送汇总通知
+                        </div>
+                        <div className="flex items-center gap-8 flex-wrap">
+                            <Form.Item
+                                label="启用"
+                                name={['flapping', 'enabled']}
+                                valuePropName="checked"
+                            >
+                                <Switch checkedChildren="开启" unCheckedChildren="关闭" />
+                            </Form.Item>
+                            <Form.Item label="检测窗口（分钟）" name={['flapping', 'window']}>
+                                <InputNumber min={1} max={1440} />
+                            </Form.Item>
+                            <Form.Item
+                                label="触发/恢复次数"
+                                name={['flapping', 'threshold']}
+                                tooltip="窗口内触发和恢复的总次数达到该值时判定为抖动"
+                            >
+                                <InputNumber min={2} max={100} />
+                            </Form.Item>
+                        </div>
+                    </Card>
+
+                    <Card title="告警抑制" type="inner">
+                        <div className="text-sm text-gray-500 mb-4">
+                            同一探针存在告警中的源告警时，目标类型的告警只记录不通知；监控项的父监控项下线时同样抑制其服务下线和证书告警
+                        </div>
+                        <Form.List name="inhibitRules">
+                            {(fields, { add, remove }) => (
+                                <div className="space-y-2">
+                                    {fields.map(({ key, name, ...restField }) => (
+                                        <div key={key} className="flex items-start gap-2 flex-wrap">
                                             <Form.Item
-                                                label="开关"
-                                                name={['rules', `${rule.key}Enabled`]}
+                                                {...restField}
+                                                name={[name, 'enabled']}
                                                 valuePropName="checked"
-                                                className="mb-0"
                                             >
-                                                <Switch />
+                                                <Switch checkedChildren="启用" unCheckedChildren="停用" />
                                             </Form.Item>
                                             <Form.Item
-                                                label={rule.thresholdLabel}
-                                                name={['rules', `${rule.key}Threshold`]}
-                                                className="mb-0"
+                                                {...restField}
+                                                name={[name, 'name']}
+                                                rules={[{ required: true, message: '请输入规则名称' }]}
                                             >
-                                                <InputNumber
-                                                    min={0}
-                                                    max={rule.max}
-                                                    style={{ width: '100%' }}
-                                                    disabled={!enabled}
+                                                <Input placeholder="规则名称" style={{ width: 160 }} />
+                                            </Form.Item>
+                                            <Form.Item
+                                                {...restField}
+                                                name={[name, 'sourceTypes']}
+                                                rules={[{ required: true, message: '请选择源告警' }]}
+                                            >
+                                                <Select
+                                                    mode="multiple"
+                                                    placeholder="源告警"
+                                                    options={inhibitAlertTypeOptions}
+                                                    style={{ width: 220 }}
                                                 />
                                             </Form.Item>
                                             <Form.Item
-                                                label="持续时间（秒）"
-                                                name={['rules', `${rule.key}Duration`]}
-                                                className="mb-0"
+                                                {...restField}
+                                                name={[name, 'targetTypes']}
+                                                rules={[{ required: true, message: '请选择被抑制的告警' }]}
                                             >
-                                                <InputNumber min={1} max={3600} style={{ width: '100%' }}
-                                                    disabled={!enabled} />
+                                                <Select
+                                                    mode="multiple"
+                                                    placeholder="被抑制的告警"
+                                                    options={inhibitAlertTypeOptions}
+                                                    style={{ width: 360 }}
+                                                />
                                             </Form.Item>
-                                        </div>
-                                    );
-                                }}
-                            </Form.Item>
-                        </Card>
-                    ))}
-
-                    <Card title="HTTPS 证书告警规则" type="inner">
-                        <Form.Item noStyle shouldUpdate>
-                            {({ getFieldValue }) => {
-                                const enabled = getFieldValue(['rules', 'certEnabled']);
-                                return (
-                                    <div className="flex items-center gap-8">
-                                        <Form.Item
-                                            label="开关"
-                                            name={['rules', 'certEnabled']}
-                                            valuePropName="checked"
-                                            className="mb-0"
-                                        >
-                                            <Switch />
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="证书剩余天数阈值（天）"
-                                            name={['rules', 'certThreshold']}
-                                            className="mb-0"
-                                            tooltip="当证书剩余天数低于此阈值时触发告警"
-                                        >
-                                            <InputNumber
-                                                min={1}
-                                                max={365}
-                                                style={{ width: '100%' }}
-                                                disabled={!enabled}
-                                            />
-                                        </Form.Item>
-                                    </div>
-                                );
-                            }}
-                        </Form.Item>
-                    </Card>
-
-                    <Card title="服务下线告警规则" type="inner">
-                        <Form.Item noStyle shouldUpdate>
-                            {({ getFieldValue }) => {
-                                const enabled = getFieldValue(['rules', 'serviceEnabled']);
-                                return (
-                                    <div className="flex items-center gap-8">
-                                        <Form.Item
-                                            label="开关"
-                                            name={['rules', 'serviceEnabled']}
-                                            valuePropName="checked"
-                                            className="mb-0"
-                                        >
-                                            <Switch />
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="持续时间（秒）"
-                                            name={['rules', 'serviceDuration']}
-                                            className="mb-0"
-                                            tooltip="服务持续离线多久后触发告警"
-                                        >
-                                            <InputNumber
-                                                min={1}
-                                                max={3600}
-                                                style={{ width: '100%' }}
-                                                disabled={!enabled}
+                                            <Button
+                                                type="text"
+                                                danger
+                                                icon={<MinusCircle size={16} />}
+                                                onClick={() => remove(name)}
                                             />
-                                        </Form.Item>
-                                    </div>
-                                );
-                            }}
-                        </Form.Item>
+                                        </div>
+                                    ))}
+                                    <Button
+                                        type="dashed"
+                                        block
+                                        icon={<PlusCircle size={16} />}
+                                        onClick={() => add({ name: '', enabled: true, sourceTypes: [], targetTypes: [] })}
+                                    >
+                                        添加抑制规则
+                                    </Button>
+                                </div>
+                            )}
+                        </Form.List>
                     </Card>
 
-                    <Card title="探针离线告警规则" type="inner">
-                        <Form.Item noStyle shouldUpdate>
-                            {({ getFieldValue }) => {
-                                const enabled = getFieldValue(['rules', 'agentOfflineEnabled']);
-                                return (
-                                    <div className="flex items-center gap-8">
-                                        <Form.Item
-                                            label="开关"
-                                            name={['rules', 'agentOfflineEnabled']}
-                                            valuePropName="checked"
-                                            className="mb-0"
-                                        >
-                                            <Switch />
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="持续时间（秒）"
-                                            name={['rules', 'agentOfflineDuration']}
-                                            className="mb-0"
-                                            tooltip="探针持续离线多久后触发告警"
-                                        >
-                                            <InputNumber
-                                                min={1}
-                                                max={3600}
-                                                style={{ width: '100%' }}
-                                                disabled={!enabled}
-                                            />
-                                        </Form.Item>
-                                    </div>
-                                );
-                            }}
-                        </Form.Item>
-                    </Card>
+                    <AlertRuleFields />
 
                     <Button
                         type="primary"
@@ -0,0 +1,613 @@
+import {Button, Form, Input, InputNumber, Select, Space, Switch} from 'antd';
+import type {NotificationChannel} from '@/api/property.ts';
+import NotificationCustomHelp from "@admin/pages/Settings/NotificationCustomHelp.tsx";
+
+export interface NotificationChannelType {
+    value: NotificationChannel['type'];
+    label: string;
+    link?: string; // 配置说明文档
+}
+
+export const notificationChannelTypes: NotificationChannelType[] = [
+    {value: 'dingtalk', label: '钉钉', link: 'https://open.dingtalk.com/document/robots/custom-robot-access'},
+    {value: 'wecom', label: '企业微信', link: 'https://work.weixin.qq.com/api/doc/90000/90136/91770'},
+    {value: 'wecomApp', label: '企业微信应用', link: 'https://developer.work.weixin.qq.com/document/path/90236'},
+    {value: 'feishu', label: '飞书', link: 'https://www.feishu.cn/hc/zh-CN/articles/360024984973-%E5%9C%A8%E7%BE%A4%E7%BB%84%E4%B8%AD%E4%BD%BF%E7%94%A8%E6%9C%BA%E5%99%A8%E4%BA%BA'},
+    {value: 'telegram', label: 'Telegram', link: 'https://core.telegram.org/bots/api'},
+    {value: 'email', label: '邮件'},
+    {value: 'slack', label: 'Slack', link: 'https://api.slack.com/messaging/webhooks'},
+    {value: 'discord', label: 'Discord', link: 'https://support.discord.com/hc/en-us/articles/228383668'},
+    {value: 'teams', label: 'Microsoft Teams', link: 'https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/add-incoming-webhook'},
+    {value: 'pagerduty', label: 'PagerDuty', link: 'https://developer.pagerduty.com/docs/events-api-v2/overview/'},
+    {value: 'opsgenie', label: 'Opsgenie', link: 'https://docs.opsgenie.com/docs/alert-api'},
+    {value: 'ntfy', label: 'ntfy', link: 'https://docs.ntfy.sh/publish/'},
+    {value: 'gotify', label: 'Gotify', link: 'https://gotify.net/docs/pushmsg'},
+    {value: 'bark', label: 'Bark', link: 'https://bark.day.app/#/tutorial'},
+    {value: 'serverchan', label: 'Server 酱', link: 'https://sct.ftqq.com/'},
+    {value: 'webhook', label: '自定义 Webhook'},
+];
+
+export const getNotificationChannelTypeLabel = (type: string) => {
+    return notificationChannelTypes.find((item) => item.value === type)?.label || type;
+};
+
+// 验证 token 字段，检查是否误输入了完整的 URL
+const validateToken = (_: any, value: string) => {
+    if (value && (value.startsWith('http://') || value.startsWith('https://'))) {
+        return Promise.reject(new Error('请只输入 Token，不要包含完整的 URL 地址'));
+    }
+    return Promise.resolve();
+};
+
+interface Props {
+    type: string;
+}
+
+// 各类型通知渠道的配置项，字段保存在 config 中
+const NotificationChannelFields = ({type}: Props) => {
+    switch (type) {
+        case 'dingtalk':
+            return (
+                <>
+                    <Form.Item
+                        label="访问令牌 (Access Token)"
+                        name={['config', 'secretKey']}
+                        rules={[
+                            {required: true, message: '请输入访问令牌'},
+                            {validator: validateToken}
+                        ]}
+                        tooltip="在钉钉机器人配置中获取的 access_token"
+                    >
+                        <Input placeholder="输入访问令牌"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="加签密钥（可选）"
+                        name={['config', 'signSecret']}
+                        tooltip="如果启用了加签，请填写 SEC 开头的密钥"
+                    >
+                        <Input.Password placeholder="SEC 开头的加签密钥"/>
+                    </Form.Item>
+                </>
+            );
+        case 'wecom':
+            return (
+                <>
+                    <Form.Item
+                        label="Webhook Key"
+                        name={['config', 'secretKey']}
+                        rules={[
+                            {required: true, message: '请输入 Webhook Key'},
+                            {validator: validateToken}
+                        ]}
+                        tooltip="企业微信群机器人的 Webhook Key"
+                    >
+                        <Input placeholder="输入 Webhook Key"/>
+                    </Form.Item>
+                </>
+            );
+        case 'wecomApp':
+            return (
+                <>
+                    <Form.Item
+                        label="origin"
+                        name={['config', 'origin']}
+                        initialValue="https://qyapi.weixin.qq.com"
+                        rules={[{required: true, message: '请输入企业微信应用origin'}]}
+                        tooltip="企业微信应用origin， Pika部署在可信IP的服务器下保持默认即可"
+                    >
+                        <Input placeholder="https://qyapi.weixin.qq.com"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="corpid"
+                        name={['config', 'corpId']}
+                        rules={[{required: true, message: '请输入企业微信的corpid'}]}
+                        tooltip="企业微信的corpid"
+                    >
+                        <Input placeholder="输入您的企业的corpid"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="corpsecret"
+                        name={['config', 'corpSecret']}
+                        rules={[{required: true, message: '请输入企业微信应用的corpsecret'}]}
+                        tooltip="企业微信应用的corpsecret"
+                    >
+                        <Input.Password placeholder="输入您的企业应用的corpsecret"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="agentid"
+                        name={['config', 'agentId']}
+                        rules={[{required: true, message: '请输入企业微信应用的agentid'}]}
+                        tooltip="企业微信应用的agentid"
+                    >
+                        <InputNumber style={{width: '100%'}}
+                                     placeholder="输入您的企业应用的agentid"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="touser"
+                        name={['config', 'toUser']}
+                        initialValue="@all"
+                        rules={[{required: true, message: '请输入接收消息的用户'}]}
+                        tooltip="接收告警消息的用户"
+                    >
+                        <Input placeholder="输入接收告警消息的用户，全部可填@all"/>
+                    </Form.Item>
+                </>
+            );
+        case 'feishu':
+            return (
+                <>
+                    <Form.Item
+                        label="Webhook Token"
+                        name={['config', 'secretKey']}
+                        rules={[
+                            {required: true, message: '请输入 Webhook Token'},
+                            {validator: validateToken}
+                        ]}
+                        tooltip="飞书群机器人的 Webhook Token"
+                    >
+                        <Input placeholder="输入 Webhook Token"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="签名密钥（可选）"
+                        name={['config', 'signSecret']}
+                        tooltip="如果启用了签名验证，请填写密钥"
+                    >
+                        <Input.Password placeholder="输入签名密钥"/>
+                    </Form.Item>
+                </>
+            );
+        case 'telegram':
+            return (
+                <>
+                    <Form.Item
+                        label="Bot Token"
+                        name={['config', 'botToken']}
+                        rules={[
+                            {required: true, message: '请输入 Bot Token'},
+                            {validator: validateToken}
+                        ]}
+                        tooltip="通过 @BotFather 创建机器人后获得的 token"
+                    >
+                        <Input.Password placeholder="输入 Bot Token"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Chat ID"
+                        name={['config', 'chatID']}
+                        rules={[{required: true, message: '请输入 Chat ID'}]}
+                        tooltip="可以是用户 ID、群组 ID 或频道 ID，通过 @userinfobot 等机器人获取"
+                    >
+                        <Input placeholder="输入 Chat ID，例如：123456789"/>
+                    </Form.Item>
+                </>
+            );
+        case 'email':
+            return (
+                <>
+                    <Form.Item
+                        label="SMTP 服务器"
+                        name={['config', 'smtpHost']}
+                        rules={[{required: true, message: '请输入 SMTP 服务器地址'}]}
+                        tooltip="邮件服务商的 SMTP 服务器地址，如 smtp.gmail.com"
+                    >
+                        <Input placeholder="例如：smtp.gmail.com"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="SMTP 端口"
+                        name={['config', 'smtpPort']}
+                        initialValue={587}
+                        rules={[{required: true, message: '请输入 SMTP 端口'}]}
+                        tooltip="通常为 587（STARTTLS）或 465（SSL/TLS）"
+                    >
+                        <Input type="number" placeholder="587"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="发件人邮箱"
+                        name={['config', 'fromEmail']}
+                        rules={[
+                            {required: true, message: '请输入发件人邮箱'},
+                            {type: 'email', message: '请输入有效的邮箱地址'}
+                        ]}
+                        tooltip="用于发送告警邮件的邮箱地址"
+                    >
+                        <Input placeholder="your-email@example.com"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="邮箱密码/授权码"
+                        name={['config', 'password']}
+                        rules={[{required: true, message: '请输入邮箱密码或授权码'}]}
+                        tooltip="某些邮件服务商（如 Gmail、QQ 邮箱）需要使用授权码而非密码"
+                    >
+                        <Input.Password placeholder="输入邮箱密码或授权码"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="收件人邮箱"
+                        name={['config', 'toEmail']}
+                        rules={[
+                            {required: true, message: '请输入收件人邮箱'},
+                            {type: 'email', message: '请输入有效的邮箱地址'}
+                        ]}
+                        tooltip="接收告警邮件的邮箱地址"
+                    >
+                        <Input placeholder="receiver@example.com"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="邮件主题"
+                        name={['config', 'subject']}
+                        tooltip="告警邮件的主题，默认为 'Pika 告警通知'"
+                    >
+                        <Input placeholder="Pika 告警通知"/>
+                    </Form.Item>
+                </>
+            );
+        case 'slack':
+            return (
+                <Form.Item
+                    noStyle
+                    shouldUpdate={(prevValues, currentValues) =>
+                        prevValues.config?.botToken !== currentValues.config?.botToken
+                    }
+                >
+                    {({getFieldValue}) => (
+                        <>
+                            <Form.Item
+                                label="Incoming Webhook URL"
+                                name={['config', 'webhookUrl']}
+                                rules={[
+                                    {required: !getFieldValue(['config', 'botToken']), message: '请输入 Webhook URL 或 Bot Token'},
+                                    {type: 'url', message: '请输入有效的 URL'},
+                                ]}
+                                tooltip="使用 Incoming Webhook 发送，不支持会话回复"
+                            >
+                                <Input placeholder="https://hooks.slack.com/services/..."/>
+                            </Form.Item>
+                            <Form.Item
+                                label="Bot Token（可选）"
+                                name={['config', 'botToken']}
+                                tooltip="填写后使用 chat.postMessage 发送，恢复消息回复到触发消息所在会话，需要 chat:write 权限"
+                            >
+                                <Input.Password placeholder="xoxb- 开头的 Bot Token"/>
+                            </Form.Item>
+                            <Form.Item
+                                label="频道 ID"
+                                name={['config', 'channel']}
+                                rules={[{required: !!getFieldValue(['config', 'botToken']), message: '使用 Bot Token 时请输入频道 ID'}]}
+                                tooltip="使用 Bot Token 时必填，机器人需已加入该频道"
+                            >
+                                <Input placeholder="例如：C0123456789"/>
+                            </Form.Item>
+                        </>
+                    )}
+                </Form.Item>
+            );
+        case 'discord':
+            return (
+                <>
+                    <Form.Item
+                        label="Webhook URL"
+                        name={['config', 'webhookUrl']}
+                        rules={[
+                            {required: true, message: '请输入 Webhook URL'},
+                            {type: 'url', message: '请输入有效的 URL'},
+                        ]}
+                    >
+                        <Input placeholder="https://discord.com/api/webhooks/..."/>
+                    </Form.Item>
+                    <Form.Item
+                        label="显示名称（可选）"
+                        name={['config', 'username']}
+                        tooltip="覆盖 Webhook 默认的显示名称"
+                    >
+                        <Input placeholder="Pika"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="论坛频道"
+                        name={['config', 'forum']}
+                        valuePropName="checked"
+                        tooltip="Webhook 属于论坛频道时开启，每条告警创建一个帖子，恢复消息发送到同一帖子"
+                    >
+                        <Switch/>
+                    </Form.Item>
+                </>
+            );
+        case 'teams':
+            return (
+                <>
+                    <Form.Item
+                        label="Webhook URL"
+                        name={['config', 'webhookUrl']}
+                        rules={[
+                            {required: true, message: '请输入 Webhook URL'},
+                            {type: 'url', message: '请输入有效的 URL'},
+                        ]}
+                        tooltip="Teams Workflows 或传入 Webhook 的地址，消息以自适应卡片发送"
+                    >
+                        <Input placeholder="https://..."/>
+                    </Form.Item>
+                </>
+            );
+        case 'pagerduty':
+            return (
+                <>
+                    <Form.Item
+                        label="Routing Key"
+                        name={['config', 'routingKey']}
+                        rules={[{required: true, message: '请输入 Routing Key'}]}
+                        tooltip="服务中 Events API v2 集成的 Integration Key，告警触发时创建事件，恢复时自动解决"
+                    >
+                        <Input.Password placeholder="输入 Events API v2 Integration Key"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Events API 地址（可选）"
+                        name={['config', 'apiUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="默认为 https://events.pagerduty.com/v2/enqueue，欧盟账户可使用 https://events.eu.pagerduty.com/v2/enqueue"
+                    >
+                        <Input placeholder="https://events.pagerduty.com/v2/enqueue"/>
+                    </Form.Item>
+                </>
+            );
+        case 'opsgenie':
+            return (
+                <>
+                    <Form.Item
+                        label="API Key"
+                        name={['config', 'apiKey']}
+                        rules={[{required: true, message: '请输入 API Key'}]}
+                        tooltip="Opsgenie API 集成的 API Key，告警触发时创建告警，恢复时自动关闭"
+                    >
+                        <Input.Password placeholder="输入 API 集成的 API Key"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="API 地址（可选）"
+                        name={['config', 'apiUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="默认为 https://api.opsgenie.com，欧盟账户使用 https://api.eu.opsgenie.com"
+                    >
+                        <Input placeholder="https://api.opsgenie.com"/>
+                    </Form.Item>
+                </>
+            );
+        case 'ntfy':
+            return (
+                <>
+                    <Form.Item
+                        label="服务器地址"
+                        name={['config', 'serverUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="可选，默认为 https://ntfy.sh，自建服务时填写服务地址"
+                    >
+                        <Input placeholder="https://ntfy.sh"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Topic"
+                        name={['config', 'topic']}
+                        rules={[{required: true, message: '请输入 Topic'}]}
+                        tooltip="订阅的主题名称"
+                    >
+                        <Input placeholder="pika-alerts"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Access Token"
+                        name={['config', 'token']}
+                        tooltip="可选，主题需要鉴权时填写"
+                    >
+                        <Input.Password placeholder="tk_xxx"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Pika 访问地址"
+                        name={['config', 'clickUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="可选，填写后点击通知可直接打开对应探针详情页"
+                    >
+                        <Input placeholder="https://pika.example.com"/>
+                    </Form.Item>
+                </>
+            );
+        case 'gotify':
+            return (
+                <>
+                    <Form.Item
+                        label="服务器地址"
+                        name={['config', 'serverUrl']}
+                        rules={[
+                            {required: true, message: '请输入服务器地址'},
+                            {type: 'url', message: '请输入有效的 URL'},
+                        ]}
+                        tooltip="Gotify 服务地址"
+                    >
+                        <Input placeholder="https://gotify.example.com"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="App Token"
+                        name={['config', 'token']}
+                        rules={[{required: true, message: '请输入 App Token'}]}
+                        tooltip="在 Gotify 中创建应用后获取的 Token"
+                    >
+                        <Input.Password placeholder="Axxxxxxxxxxxxxx"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Pika 访问地址"
+                        name={['config', 'clickUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="可选，填写后点击通知可直接打开对应探针详情页"
+                    >
+                        <Input placeholder="https://pika.example.com"/>
+                    </Form.Item>
+                </>
+            );
+        case 'bark':
+            return (
+                <>
+                    <Form.Item
+                        label="服务器地址"
+                        name={['config', 'serverUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="可选，默认为 https://api.day.app，自建服务时填写服务地址"
+                    >
+                        <Input placeholder="https://api.day.app"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Device Key"
+                        name={['config', 'deviceKey']}
+                        rules={[{required: true, message: '请输入 Device Key'}]}
+                        tooltip="Bark App 中显示的设备 Key，严重告警以重要警告推送，静音模式下也会响铃"
+                    >
+                        <Input.Password placeholder="xxxxxxxxxxxxxxxxxxxxxx"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Pika 访问地址"
+                        name={['config', 'clickUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="可选，填写后点击通知可直接打开对应探针详情页"
+                    >
+                        <Input placeholder="https://pika.example.com"/>
+                    </Form.Item>
+                </>
+            );
+        case 'serverchan':
+            return (
+                <>
+                    <Form.Item
+                        label="SendKey"
+                        name={['config', 'sendKey']}
+                        rules={[{required: true, message: '请输入 SendKey'}]}
+                        tooltip="支持 Server 酱 Turbo 和 Server 酱³ 的 SendKey，自动识别推送地址"
+                    >
+                        <Input.Password placeholder="SCTxxx 或 sctpxxx"/>
+                    </Form.Item>
+                    <Form.Item
+                        label="Pika 访问地址"
+                        name={['config', 'clickUrl']}
+                        rules={[{type: 'url', message: '请输入有效的 URL'}]}
+                        tooltip="可选，填写后点击通知可直接打开对应探针详情页"
+                    >
+                        <Input placeholder="https://pika.example.com"/>
+                    </Form.Item>
+                </>
+            );
+        case 'webhook':
+            return (
+                <>
+                    <Form.Item
+                        label="Webhook URL"
+                        name={['config', 'url']}
+                        rules={[
+                            {required: true, message: '请输入自定义 Webhook URL'},
+                            {type: 'url', message: '请输入有效的 URL'},
+                        ]}
+                    >
+                        <Input placeholder="https://your-server.com/webhook"/>
+                    </Form.Item>
+                    {/* HTTP 方法 */}
+                    <Form.Item
+                        label="HTTP 方法"
+                        name={['config', 'method']}
+                        initialValue="POST"
+                        tooltip="选择 HTTP 请求方法"
+                    >
+                        <Select
+                            placeholder="选择 HTTP 方法"
+                            options={[
+                                {label: 'GET', value: 'GET'},
+                                {label: 'POST', value: 'POST'},
+                                {label: 'PUT', value: 'PUT'},
+                                {label: 'PATCH', value: 'PATCH'},
+                                {label: 'DELETE', value: 'DELETE'},
+                            ]}
+                        />
+                    </Form.Item>
+
+                    {/* 自定义请求体 */}
+                    <Form.Item
+                        label="自定义请求体"
+                        name={['config', 'customBody']}
+                        rules={[
+                            {
+                                required: true,
+                                message: '请输入自定义请求体模板'
+                            }
+                        ]}
+                        tooltip="支持变量替换，可用变量见下方说明"
+                    >
+                        <Input.TextArea
+                            rows={6}
+                            placeholder='示例: {"alert": "{{alert.message}}", "host": "{{agent.hostname}}"}'
+                        />
+                    </Form.Item>
+
+                    {/* 自定义请求头 */}
+                    <Form.Item label="自定义请求头"
+                               tooltip="添加自定义 HTTP 请求头">
+                        <Form.List name={['config', 'headers']}>
+                            {(fields, {add, remove}) => (
+                                <>
+                                    {fields.map(({
+                                                     key,
+                                                     name,
+                                                     ...restField
+                                                 }) => (
+                                        <Space
+                                            key={key}
+                                            style={{
+                                                display: 'flex',
+                                                marginBottom: 8
+                                            }}
+                                            align="baseline"
+                                        >
+                                            <Form.Item
+                                                {...restField}
+                                                name={[name, 'key']}
+                                                rules={[{
+                                                    required: true,
+                                                    message: '请输入 Header 名称'
+                                                }]}
+                                            >
+                                                <Input
+                                                    placeholder="Header 名称"
+                                                    style={{width: 200}}
+                                                />
+                                            </Form.Item>
+                                            <Form.Item
+                                                {...restField}
+                                                name={[name, 'value']}
+                                                rules={[{
+                                                    required: true,
+                                                    message: '请输入 Header 值'
+                                                }]}
+                                            >
+                                                <Input
+                                                    placeholder="Header 值"
+                                                    style={{width: 300}}
+                                                />
+                                            </Form.Item>
+                                            <Button
+                                                onClick={() => remove(name)}
+                                                danger
+                                                type="link"
+                                            >
+                                                删除
+                                            </Button>
+                                        </Space>
+                                    ))}
+                                    <Form.Item>
+                                        <Button
+                                            type="dashed"
+                                            onClick={() => add()}
+                                            block
+                                        >
+                                            添加请求头
+                                        </Button>
+                                    </Form.Item>
+                                </>
+                            )}
+                        </Form.List>
+                    </Form.Item>
+                    <NotificationCustomHelp/>
+                </>
+            );
+        default:
+            return null;
+    }
+};
+
+export default NotificationChannelFields;
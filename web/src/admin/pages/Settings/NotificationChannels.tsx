@@ -1,38 +1,117 @@
-import {useEffect, useState} from 'react';
-import {Alert, App, Button, Card, Form, Input, InputNumber, Select, Space, Spin, Switch} from 'antd';
-import {TestTube} from 'lucide-react';
+import {useState} from 'react';
+import {App, Button, Card, Form, Input, Modal, Popconfirm, Select, Space, Switch, Table, Tag} from 'antd';
+import type {ColumnsType} from 'antd/es/table';
+import {Plus, TestTube} from 'lucide-react';
 import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
 import {
     getNotificationChannels,
     type NotificationChannel,
+    type NotificationRoute,
     saveNotificationChannels,
     testNotificationChannel,
 } from '@/api/property.ts';
+import {getTags} from '@/api/agent';
 import {getErrorMessage} from '@/lib/utils';
-import NotificationCustomHelp from "@admin/pages/Settings/NotificationCustomHelp.tsx";
+import NotificationChannelFields, {
+    getNotificationChannelTypeLabel,
+    notificationChannelTypes,
+} from "@admin/pages/Settings/NotificationChannelFields.tsx";
+import NotificationTemplateFields, {templateEvents} from "@admin/pages/Settings/NotificationTemplateFields.tsx";
+
+const alertTypeOptions = [
+    {value: 'cpu', label: 'CPU使用率'},
+    {value: 'memory', label: '内存使用率'},
+    {value: 'disk', label: '磁盘使用率'},
+    {value: 'network', label: '网速'},
+    {value: 'traffic', label: '流量'},
+    {value: 'cert', label: 'HTTPS证书'},
+    {value: 'service', label: '服务下线'},
+    {value: 'agent_offline', label: '探针离线'},
+    {value: 'promql', label: '自定义规则'},
+    {value: 'disk_forecast', label: '磁盘写满预测'},
+    {value: 'traffic_forecast', label: '流量超额预测'},
+    {value: 'ssh_login', label: 'SSH登录成功'},
+    {value: 'ssh_ban', label: 'SSH暴力破解封禁'},
+    {value: 'tamper', label: '防篡改事件'},
+    {value: 'audit_drift', label: '审计资产变化'},
+];
+
+const levelOptions = [
+    {value: 'info', label: '信息'},
+    {value: 'warning', label: '警告'},
+    {value: 'critical', label: '严重'},
+];
+
+const eventOptions = templateEvents.map((item) => ({value: item.key, label: item.label}));
+
+const alertTypeNameMap = new Map(alertTypeOptions.map((item) => [item.value, item.label]));
+const levelNameMap = new Map(levelOptions.map((item) => [item.value, item.label]));
+const eventNameMap = new Map<string, string>(eventOptions.map((item) => [item.value, item.label]));
+
+// 路由规则的简要描述，如：CPU使用率 · 严重 · #db
+const describeRoute = (route: NotificationRoute) => {
+    const parts = [
+        route.alertTypes?.map((item) => alertTypeNameMap.get(item) || item).join('/'),
+        route.levels?.map((item) => levelNameMap.get(item) || item).join('/'),
+        route.agentTags?.map((item) => `#${item}`).join(' '),
+        route.events?.map((item) => eventNameMap.get(item) || item).join('/'),
+    ].filter((item) => item);
+    return parts.length > 0 ? parts.join(' · ') : '全部通知';
+};
+
+// 表单值与渠道配置的转换：请求头在表单中以数组形式编辑
+const toFormValues = (channel: NotificationChannel) => {
+    const headers = channel.config?.headers || {};
+    return {
+        ...channel,
+        routes: channel.routes || [],
+        config: {
+            ...channel.config,
+            headers: Object.entries(headers).map(([key, value]) => ({key, value})),
+        },
+    };
+};
+
+const toChannelConfig = (config: Record<string, any> = {}) => {
+    const result: Record<string, any> = {...config};
+
+    if (Array.isArray(config.headers)) {
+        const headers: Record<string, string> = {};
+        config.headers.forEach((item: { key: string; value: string }) => {
+            if (item?.key && item?.value) {
+                headers[item.key] = item.value;
+            }
+        });
+        result.headers = Object.keys(headers).length > 0 ? headers : undefined;
+    }
+
+    // 消息模板，只保存非空模板
+    const templates = Object.fromEntries(
+        Object.entries(config.templates || {})
+            .filter(([, template]) => typeof template === 'string' && template.trim() !== '')
+    );
+    result.templates = Object.keys(templates).length > 0 ? templates : undefined;
+    return result;
+};
+
+// 去掉空条件，所有条件为空的路由规则会接收全部通知
+const toRoutes = (routes: NotificationRoute[] = []) => {
+    return routes.map((route) => ({
+        alertTypes: route.alertTypes?.length ? route.alertTypes : undefined,
+        levels: route.levels?.length ? route.levels : undefined,
+        agentTags: route.agentTags?.length ? route.agentTags : undefined,
+        events: route.events?.length ? route.events : undefined,
+    }));
+};
 
 const NotificationChannels = () => {
     const [form] = Form.useForm();
     const {message: messageApi} = App.useApp();
     const queryClient = useQueryClient();
-    const [savedValues, setSavedValues] = useState<Record<string, any>>({});
-
-    // 验证 token 字段，检查是否误输入了完整的 URL
-    const validateToken = (_: any, value: string) => {
-        if (value && (value.startsWith('http://') || value.startsWith('https://'))) {
-            return Promise.reject(new Error('请只输入 Token，不要包含完整的 URL 地址'));
-        }
-        return Promise.resolve();
-    };
+    const [modalOpen, setModalOpen] = useState(false);
+    const [editing, setEditing] = useState<NotificationChannel | null>(null);
 
-    // 监听各个通知渠道的启用状态
-    const dingtalkEnabled = Form.useWatch('dingtalkEnabled', form);
-    const wecomEnabled = Form.useWatch('wecomEnabled', form);
-    const wecomAppEnabled = Form.useWatch('wecomAppEnabled', form);
-    const feishuEnabled = Form.useWatch('feishuEnabled', form);
-    const telegramEnabled = Form.useWatch('telegramEnabled', form);
-    const emailEnabled = Form.useWatch('emailEnabled', form);
-    const webhookEnabled = Form.useWatch('webhookEnabled', form);
+    const channelType = Form.useWatch('type', form);
 
     // 获取通知渠道列表
     const {data: channels = [], isLoading} = useQuery({
@@ -40,11 +119,20 @@ const NotificationChannels = () => {
         queryFn: getNotificationChannels,
     });
 
-    // 保存 mutation
+    const {data: tags} = useQuery({
+        queryKey: ['admin', 'agents', 'tags'],
+        queryFn: async () => {
+            const response = await getTags();
+            return response.data.tags || [];
+        },
+    });
+
+    // 保存 mutation，每次保存完整的渠道列表
     const saveMutation = useMutation({
         mutationFn: saveNotificationChannels,
         onSuccess: () => {
             messageApi.success('保存成功');
+            setModalOpen(false);
             queryClient.invalidateQueries({queryKey: ['notificationChannels']});
         },
         onError: (error: unknown) => {
@@ -63,790 +151,246 @@ const NotificationChannels = () => {
         },
     });
 
-    // 将渠道数组转换为表单值
-    useEffect(() => {
-        if (channels.length > 0) {
-            const formValues: Record<string, any> = {};
-
-            channels.forEach((channel) => {
-                if (channel.type === 'dingtalk') {
-                    formValues.dingtalkEnabled = channel.enabled;
-                    formValues.dingtalkSecretKey = channel.config?.secretKey || '';
-                    formValues.dingtalkSignSecret = channel.config?.signSecret || '';
-                } else if (channel.type === 'wecom') {
-                    formValues.wecomEnabled = channel.enabled;
-                    formValues.wecomSecretKey = channel.config?.secretKey || '';
-                } else if (channel.type === 'wecomApp') {
-                    formValues.wecomAppEnabled = channel.enabled;
-                    formValues.wecomAppOrigin = channel.config?.origin || 'https://qyapi.weixin.qq.com';
-                    formValues.wecomAppCorpId = channel.config?.corpId || '';
-                    formValues.wecomAppCorpSecret = channel.config?.corpSecret || '';
-                    formValues.wecomAppAgentId = channel.config?.agentId;
-                    formValues.wecomAppToUser = channel.config?.toUser || '@all';
-                } else if (channel.type === 'feishu') {
-                    formValues.feishuEnabled = channel.enabled;
-                    formValues.feishuSecretKey = channel.config?.secretKey || '';
-                    formValues.feishuSignSecret = channel.config?.signSecret || '';
-                } else if (channel.type === 'telegram') {
-                    formValues.telegramEnabled = channel.enabled;
-                    formValues.telegramBotToken = channel.config?.botToken || '';
-                    formValues.telegramChatID = channel.config?.chatID || '';
-                } else if (channel.type === 'email') {
-                    formValues.emailEnabled = channel.enabled;
-                    formValues.emailSmtpHost = channel.config?.smtpHost || '';
-                    formValues.emailSmtpPort = channel.config?.smtpPort || 587;
-                    formValues.emailFromEmail = channel.config?.fromEmail || '';
-                    formValues.emailPassword = channel.config?.password || '';
-                    formValues.emailToEmail = channel.config?.toEmail || '';
-                    formValues.emailSubject = channel.config?.subject || 'Pika 告警通知';
-                } else if (channel.type === 'webhook') {
-                    formValues.webhookEnabled = channel.enabled;
-                    formValues.webhookUrl = channel.config?.url || '';
-                    formValues.webhookMethod = channel.config?.method || 'POST';
-                    formValues.webhookCustomBody = channel.config?.customBody || '';
-
-                    // 解析 headers 为数组形式方便编辑
-                    const headers = channel.config?.headers || {};
-                    formValues.webhookHeaders = Object.entries(headers).map(([key, value]) => ({
-                        key,
-                        value
-                    }));
-                }
+    const openModal = (channel?: NotificationChannel) => {
+        setEditing(channel || null);
+        form.resetFields();
+        if (channel) {
+            form.setFieldsValue(toFormValues(channel));
+        } else {
+            form.setFieldsValue({
+                type: 'dingtalk',
+                enabled: true,
+                routes: [],
+                config: {},
             });
-
-            form.setFieldsValue(formValues);
-            // 保存当前值作为"已保存"的值，用于检测是否有未保存的修改
-            setSavedValues(formValues);
-        }
-    }, [channels, form]);
-
-    // 将表单值转换回渠道数组
-    const handleSave = async () => {
-        try {
-            const values = await form.validateFields();
-            const newChannels: NotificationChannel[] = [];
-
-            // 钉钉
-            if (values.dingtalkEnabled || values.dingtalkSecretKey) {
-                newChannels.push({
-                    type: 'dingtalk',
-                    enabled: values.dingtalkEnabled || false,
-                    config: {
-                        secretKey: values.dingtalkSecretKey || '',
-                        signSecret: values.dingtalkSignSecret || '',
-                    },
-                });
-            }
-
-            // 企业微信
-            if (values.wecomEnabled || values.wecomSecretKey) {
-                newChannels.push({
-                    type: 'wecom',
-                    enabled: values.wecomEnabled || false,
-                    config: {
-                        secretKey: values.wecomSecretKey || '',
-                    },
-                });
-            }
-
-            // 企业微信应用
-            if (values.wecomAppEnabled || values.wecomAppOrigin) {
-                newChannels.push({
-                    type: 'wecomApp',
-                    enabled: values.wecomAppEnabled || false,
-                    config: {
-                        origin: values.wecomAppOrigin || '',
-                        corpId: values.wecomAppCorpId || '',
-                        corpSecret: values.wecomAppCorpSecret || '',
-                        agentId: values.wecomAppAgentId,
-                        toUser: values.wecomAppToUser || '',
-                    },
-                });
-            }
-
-            // 飞书
-            if (values.feishuEnabled || values.feishuSecretKey) {
-                newChannels.push({
-                    type: 'feishu',
-                    enabled: values.feishuEnabled || false,
-                    config: {
-                        secretKey: values.feishuSecretKey || '',
-                        signSecret: values.feishuSignSecret || '',
-                    },
-                });
-            }
-
-            // Telegram
-            if (values.telegramEnabled || values.telegramBotToken) {
-                newChannels.push({
-                    type: 'telegram',
-                    enabled: values.telegramEnabled || false,
-                    config: {
-                        botToken: values.telegramBotToken || '',
-                        chatID: values.telegramChatID || '',
-                    },
-                });
-            }
-
-            // 邮件
-            if (values.emailEnabled || values.emailSmtpHost) {
-                newChannels.push({
-                    type: 'email',
-                    enabled: values.emailEnabled || false,
-                    config: {
-                        smtpHost: values.emailSmtpHost || '',
-                        smtpPort: values.emailSmtpPort || 587,
-                        fromEmail: values.emailFromEmail || '',
-                        password: values.emailPassword || '',
-                        toEmail: values.emailToEmail || '',
-                        subject: values.emailSubject || 'Pika 告警通知',
-                    },
-                });
-            }
-
-            // 自定义Webhook
-            if (values.webhookEnabled || values.webhookUrl) {
-                // 将 headers 数组转换为对象
-                const headersObj: Record<string, string> = {};
-                if (values.webhookHeaders && Array.isArray(values.webhookHeaders)) {
-                    values.webhookHeaders.forEach((item: { key: string; value: string }) => {
-                        if (item.key && item.value) {
-                            headersObj[item.key] = item.value;
-                        }
-                    });
-                }
-
-                newChannels.push({
-                    type: 'webhook',
-                    enabled: values.webhookEnabled || false,
-                    config: {
-                        url: values.webhookUrl || '',
-                        method: values.webhookMethod || 'POST',
-                        customBody: values.webhookCustomBody || '',
-                        headers: Object.keys(headersObj).length > 0 ? headersObj : undefined,
-                    },
-                });
-            }
-
-            saveMutation.mutate(newChannels);
-        } catch (error) {
-            // 表单验证失败
         }
+        setModalOpen(true);
     };
 
-    const handleTest = (type: string) => {
-        testMutation.mutate(type);
+    const handleSubmit = async () => {
+        const values = await form.validateFields();
+        const channel: NotificationChannel = {
+            id: editing?.id || '',
+            name: values.name,
+            type: values.type,
+            enabled: values.enabled || false,
+            routes: toRoutes(values.routes),
+            config: toChannelConfig({...editing?.config, ...values.config}),
+        };
+        const newChannels = editing
+            ? channels.map((item) => item.id === editing.id ? channel : item)
+            : [...channels, channel];
+        saveMutation.mutate(newChannels);
     };
 
-    if (isLoading) {
-        return (
-            <div className="flex justify-center items-center py-20">
-                <Spin/>
-            </div>
-        );
-    }
-
-    return (
-        <div>
-            <div className="mb-4">
-                <h2 className="text-xl font-bold">通知渠道管理</h2>
-                <p className="text-gray-500 my-2">配置钉钉、企业微信、飞书和自定义Webhook通知渠道</p>
-                <Alert title="更改配置后，请点击下方的保存后再进行测试。" type="info" showIcon/>
-            </div>
-
-            <Form form={form} layout="vertical" onFinish={handleSave}>
-                <Space orientation={'vertical'} className={'w-full'}>
-                    {/* 钉钉通知 */}
-                    <Card
-                        title={
-                            <div className={'flex items-center gap-2'}>
-                                <div>钉钉通知</div>
-                                <div className={'text-xs font-normal'}>
-                                    了解更多：<a href="https://open.dingtalk.com/document/robots/custom-robot-access"
-                                                target="_blank"
-                                                rel="noopener noreferrer">https://open.dingtalk.com/document/robots/custom-robot-access</a>
-                                </div>
-                            </div>
-                        }
-                        type="inner"
-                        className="mb-4"
-                        extra={
-                            <Button
-                                type="link"
-                                size="small"
-                                icon={<TestTube size={14}/>}
-                                onClick={() => handleTest('dingtalk')}
-                                loading={testMutation.isPending}
-                                disabled={!dingtalkEnabled}
-                            >
-                                测试
-                            </Button>
-                        }
-                    >
-                        <Form.Item label="启用钉钉通知" name="dingtalkEnabled" valuePropName="checked">
-                            <Switch/>
-                        </Form.Item>
+    const handleToggle = (channel: NotificationChannel, enabled: boolean) => {
+        saveMutation.mutate(channels.map((item) => item.id === channel.id ? {...item, enabled} : item));
+    };
 
-                        <Form.Item
-                            noStyle
-                            shouldUpdate={(prevValues, currentValues) =>
-                                prevValues.dingtalkEnabled !== currentValues.dingtalkEnabled
-                            }
-                        >
-                            {({getFieldValue}) =>
-                                getFieldValue('dingtalkEnabled') ? (
-                                    <>
-                                        <Form.Item
-                                            label="访问令牌 (Access Token)"
-                                            name="dingtalkSecretKey"
-                                            rules={[
-                                                {required: true, message: '请输入访问令牌'},
-                                                {validator: validateToken}
-                                            ]}
-                                            tooltip="在钉钉机器人配置中获取的 access_token"
-                                        >
-                                            <Input placeholder="输入访问令牌"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="加签密钥（可选）"
-                                            name="dingtalkSignSecret"
-                                            tooltip="如果启用了加签，请填写 SEC 开头的密钥"
-                                        >
-                                            <Input.Password placeholder="SEC 开头的加签密钥"/>
-                                        </Form.Item>
-                                    </>
-                                ) : null
-                            }
-                        </Form.Item>
-                    </Card>
+    const handleDelete = (channel: NotificationChannel) => {
+        saveMutation.mutate(channels.filter((item) => item.id !== channel.id));
+    };
 
-                    {/* 企业微信通知 */}
-                    <Card
-                        title={
-                            <div className={'flex items-center gap-2'}>
-                                <div>企业微信通知</div>
-                                <div className={'text-xs font-normal'}>
-                                    了解更多：<a href="https://work.weixin.qq.com/api/doc/90000/90136/91770"
-                                                target="_blank"
-                                                rel="noopener noreferrer">https://work.weixin.qq.com/api/doc/90000/90136/91770</a>
-                                </div>
-                            </div>
-                        }
-                        type="inner"
-                        className="mb-4"
-                        extra={
-                            <Button
-                                type="link"
-                                size="small"
-                                icon={<TestTube size={14}/>}
-                                onClick={() => handleTest('wecom')}
-                                loading={testMutation.isPending}
-                                disabled={!wecomEnabled}
-                            >
-                                测试
-                            </Button>
-                        }
+    const columns: ColumnsType<NotificationChannel> = [
+        {
+            title: '名称',
+            key: 'name',
+            width: 200,
+            render: (_, record) => (
+                <div className="font-medium">{record.name || getNotificationChannelTypeLabel(record.type)}</div>
+            ),
+        },
+        {
+            title: '类型',
+            dataIndex: 'type',
+            key: 'type',
+            width: 140,
+            render: (type: string) => getNotificationChannelTypeLabel(type),
+        },
+        {
+            title: '路由规则',
+            key: 'routes',
+            render: (_, record) => (
+                <Space size={[4, 4]} wrap>
+                    {record.routes && record.routes.length > 0 ? (
+                        record.routes.map((route, index) => (
+                            <Tag key={index} color="blue">{describeRoute(route)}</Tag>
+                        ))
+                    ) : (
+                        <Tag>全部通知</Tag>
+                    )}
+                </Space>
+            ),
+        },
+        {
+            title: '启用',
+            dataIndex: 'enabled',
+            key: 'enabled',
+            width: 80,
+            render: (_, record) => (
+                <Switch
+                    size="small"
+                    checked={record.enabled}
+                    loading={saveMutation.isPending}
+                    onChange={(checked) => handleToggle(record, checked)}
+                />
+            ),
+        },
+        {
+            title: '操作',
+            key: 'actions',
+            width: 200,
+            render: (_, record) => (
+                <Space>
+                    <Button
+                        type="link"
+                        size="small"
+                        icon={<TestTube size={14}/>}
+                        onClick={() => testMutation.mutate(record.id)}
+                        loading={testMutation.isPending && testMutation.variables === record.id}
+                        disabled={!record.enabled}
                     >
-                        <Form.Item label="启用企业微信通知" name="wecomEnabled" valuePropName="checked">
-                            <Switch/>
-                        </Form.Item>
-
-                        <Form.Item
-                            noStyle
-                            shouldUpdate={(prevValues, currentValues) => prevValues.wecomEnabled !== currentValues.wecomEnabled}
-                        >
-                            {({getFieldValue}) =>
-                                getFieldValue('wecomEnabled') ? (
-                                    <Form.Item
-                                        label="Webhook Key"
-                                        name="wecomSecretKey"
-                                        rules={[
-                                            {required: true, message: '请输入 Webhook Key'},
-                                            {validator: validateToken}
-                                        ]}
-                                        tooltip="企业微信群机器人的 Webhook Key"
-                                    >
-                                        <Input placeholder="输入 Webhook Key"/>
-                                    </Form.Item>
-                                ) : null
-                            }
-                        </Form.Item>
-                    </Card>
-
-                    {/* 企业微信应用通知 */}
-                    <Card
-                        title={
-                            <div className={'flex items-center gap-2'}>
-                                <div>企业微信应用通知</div>
-                                <div className={'text-xs font-normal'}>
-                                    了解更多：<a href="https://developer.work.weixin.qq.com/document/path/90236"
-                                                target="_blank"
-                                                rel="noopener noreferrer">https://developer.work.weixin.qq.com/document/path/90236</a>
-                                </div>
-                            </div>
-                        }
-                        type="inner"
-                        className="mb-4"
-                        extra={
-                            <Button
-                                type="link"
-                                size="small"
-                                icon={<TestTube size={14}/>}
-                                onClick={() => handleTest('wecomApp')}
-                                loading={testMutation.isPending}
-                                disabled={!wecomAppEnabled}
-                            >
-                                测试
-                            </Button>
-                        }
+                        测试
+                    </Button>
+                    <Button type="link" size="small" onClick={() => openModal(record)}>编辑</Button>
+                    <Popconfirm
+                        title="确定删除该通知渠道吗？"
+                        description="升级通知中引用该渠道的配置将不再生效"
+                        onConfirm={() => handleDelete(record)}
                     >
-                        <Form.Item label="启用企业微信应用通知" name="wecomAppEnabled" valuePropName="checked">
-                            <Switch/>
-                        </Form.Item>
-
-                        <Form.Item
-                            noStyle
-                            shouldUpdate={(prevValues, currentValues) => prevValues.wecomAppEnabled !== currentValues.wecomAppEnabled}
-                        >
-                            {({getFieldValue}) =>
-                                getFieldValue('wecomAppEnabled') ? (
-                                    <>
-                                        <Form.Item
-                                            label="origin"
-                                            name="wecomAppOrigin"
-                                            initialValue="https://qyapi.weixin.qq.com"
-                                            rules={[{required: true, message: '请输入企业微信应用origin'}]}
-                                            tooltip="企业微信应用origin， Pika部署在可信IP的服务器下保持默认即可"
-                                        >
-                                            <Input placeholder="https://qyapi.weixin.qq.com"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="corpid"
-                                            name="wecomAppCorpId"
-                                            rules={[{required: true, message: '请输入企业微信的corpid'}]}
-                                            tooltip="企业微信的corpid"
-                                        >
-                                            <Input placeholder="输入您的企业的corpid"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="corpsecret"
-                                            name="wecomAppCorpSecret"
-                                            rules={[{required: true, message: '请输入企业微信应用的corpsecret'}]}
-                                            tooltip="企业微信应用的corpsecret"
-                                        >
-                                            <Input.Password placeholder="输入您的企业应用的corpsecret"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="agentid"
-                                            name="wecomAppAgentId"
-                                            rules={[{required: true, message: '请输入企业微信应用的agentid'}]}
-                                            tooltip="企业微信应用的agentid"
-                                        >
-                                            <InputNumber style={{width: '100%'}}
-                                                         placeholder="输入您的企业应用的agentid"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="touser"
-                                            name="wecomAppToUser"
-                                            initialValue="@all"
-                                            rules={[{required: true, message: '请输入接收消息的用户'}]}
-                                            tooltip="接收告警消息的用户"
-                                        >
-                                            <Input placeholder="输入接收告警消息的用户，全部可填@all"/>
-                                        </Form.Item>
-                                    </>
-                                ) : null
-                            }
-                        </Form.Item>
-                    </Card>
+                        <Button type="link" size="small" danger>删除</Button>
+                    </Popconfirm>
+                </Space>
+            ),
+        },
+    ];
 
-                    {/* 飞书通知 */}
-                    <Card
-                        title={
-                            <div className={'flex items-center gap-2'}>
-                                <div>飞书通知</div>
-                                <div className={'text-xs font-normal'}>
-                                    点击 <a
-                                    href="https://www.feishu.cn/hc/zh-CN/articles/360024984973-%E5%9C%A8%E7%BE%A4%E7%BB%84%E4%B8%AD%E4%BD%BF%E7%94%A8%E6%9C%BA%E5%99%A8%E4%BA%BA"
-                                    target="_blank"
-                                    rel="noopener noreferrer">这里</a>
-                                    了解如何获取 Webhook URL。
-                                </div>
-                            </div>
-                        }
-                        type="inner"
-                        className="mb-4"
-                        extra={
-                            <Button
-                                type="link"
-                                size="small"
-                                icon={<TestTube size={14}/>}
-                                onClick={() => handleTest('feishu')}
-                                loading={testMutation.isPending}
-                                disabled={!feishuEnabled}
-                            >
-                                测试
-                            </Button>
-                        }
-                    >
-                        <Form.Item label="启用飞书通知" name="feishuEnabled" valuePropName="checked">
-                            <Switch/>
-                        </Form.Item>
+    const channelTypeInfo = notificationChannelTypes.find((item) => item.value === channelType);
 
-                        <Form.Item
-                            noStyle
-                            shouldUpdate={(prevValues, currentValues) =>
-                                prevValues.feishuEnabled !== currentValues.feishuEnabled
-                            }
-                        >
-                            {({getFieldValue}) =>
-                                getFieldValue('feishuEnabled') ? (
-                                    <>
-                                        <Form.Item
-                                            label="Webhook Token"
-                                            name="feishuSecretKey"
-                                            rules={[
-                                                {required: true, message: '请输入 Webhook Token'},
-                                                {validator: validateToken}
-                                            ]}
-                                            tooltip="飞书群机器人的 Webhook Token"
-                                        >
-                                            <Input placeholder="输入 Webhook Token"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="签名密钥（可选）"
-                                            name="feishuSignSecret"
-                                            tooltip="如果启用了签名验证，请填写密钥"
-                                        >
-                                            <Input.Password placeholder="输入签名密钥"/>
-                                        </Form.Item>
-                                    </>
-                                ) : null
-                            }
-                        </Form.Item>
-                    </Card>
+    return (
+        <div>
+            <div className="mb-4 flex justify-between items-start gap-4">
+                <div>
+                    <h2 className="text-xl font-bold">通知渠道管理</h2>
+                    <p className="text-gray-500 my-2">
+                        支持钉钉、企业微信、飞书、Telegram、邮件、Slack、Discord、Teams、PagerDuty、Opsgenie、ntfy、Gotify、Bark、Server 酱和自定义Webhook，同一类型可添加多个渠道，并通过路由规则按告警类型、级别、探针标签和事件类型分发通知
+                    </p>
+                </div>
+                <Button type="primary" icon={<Plus size={16}/>} onClick={() => openModal()}>
+                    添加渠道
+                </Button>
+            </div>
 
-                    {/* Telegram 通知 */}
-                    <Card
-                        title={
-                            <div className={'flex items-center gap-2'}>
-                                <div>Telegram 通知</div>
-                                <div className={'text-xs font-normal'}>
-                                    了解更多：<a href="https://core.telegram.org/bots/api"
-                                                target="_blank"
-                                                rel="noopener noreferrer">https://core.telegram.org/bots/api</a>
-                                </div>
-                            </div>
-                        }
-                        type="inner"
-                        className="mb-4"
-                        extra={
-                            <Button
-                                type="link"
-                                size="small"
-                                icon={<TestTube size={14}/>}
-                                onClick={() => handleTest('telegram')}
-                                loading={testMutation.isPending}
-                                disabled={!telegramEnabled}
+            <Table<NotificationChannel>
+                columns={columns}
+                dataSource={channels}
+                loading={isLoading}
+                rowKey="id"
+                pagination={false}
+                locale={{emptyText: '暂无通知渠道'}}
+            />
+
+            <Modal
+                title={editing ? '编辑通知渠道' : '添加通知渠道'}
+                open={modalOpen}
+                width={800}
+                onCancel={() => setModalOpen(false)}
+                onOk={handleSubmit}
+                confirmLoading={saveMutation.isPending}
+                forceRender
+            >
+                <Form form={form} layout="vertical">
+                    <Space orientation={'vertical'} className={'w-full'}>
+                        <Card title="基本信息" type="inner">
+                            <Form.Item
+                                label="类型"
+                                name="type"
+                                rules={[{required: true, message: '请选择渠道类型'}]}
+                                extra={channelTypeInfo?.link && (
+                                    <span className={'text-xs'}>
+                                        了解更多：<a href={channelTypeInfo.link} target="_blank"
+                                                    rel="noopener noreferrer">{channelTypeInfo.link}</a>
+                                    </span>
+                                )}
                             >
-                                测试
-                            </Button>
-                        }
-                    >
-                        <Form.Item label="启用 Telegram 通知" name="telegramEnabled" valuePropName="checked">
-                            <Switch/>
-                        </Form.Item>
-
-                        <Form.Item
-                            noStyle
-                            shouldUpdate={(prevValues, currentValues) =>
-                                prevValues.telegramEnabled !== currentValues.telegramEnabled
-                            }
-                        >
-                            {({getFieldValue}) =>
-                                getFieldValue('telegramEnabled') ? (
-                                    <>
-                                        <Form.Item
-                                            label="Bot Token"
-                                            name="telegramBotToken"
-                                            rules={[
-                                                {required: true, message: '请输入 Bot Token'},
-                                                {validator: validateToken}
-                                            ]}
-                                            tooltip="通过 @BotFather 创建机器人后获得的 token"
-                                        >
-                                            <Input.Password placeholder="输入 Bot Token"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="Chat ID"
-                                            name="telegramChatID"
-                                            rules={[{required: true, message: '请输入 Chat ID'}]}
-                                            tooltip="可以是用户 ID、群组 ID 或频道 ID，通过 @userinfobot 等机器人获取"
-                                        >
-                                            <Input placeholder="输入 Chat ID，例如：123456789"/>
-                                        </Form.Item>
-                                    </>
-                                ) : null
-                            }
-                        </Form.Item>
-                    </Card>
-
-                    {/* 邮件通知 */}
-                    <Card
-                        title={
-                            <div className={'flex items-center gap-2'}>
-                                <div>邮件通知</div>
-                                <div className={'text-xs font-normal text-gray-500'}>
-                                    使用 SMTP 协议发送邮件告警
-                                </div>
+                                <Select
+                                    disabled={!!editing}
+                                    options={notificationChannelTypes}
+                                    onChange={() => form.setFieldValue('config', {})}
+                                />
+                            </Form.Item>
+                            <Form.Item label="名称" name="name" tooltip="留空时显示渠道类型">
+                                <Input placeholder="如：运维值班群"/>
+                            </Form.Item>
+                            <Form.Item label="启用" name="enabled" valuePropName="checked" className="mb-0">
+                                <Switch/>
+                            </Form.Item>
+                        </Card>
+
+                        <Card title="渠道配置" type="inner">
+                            <NotificationChannelFields type={channelType}/>
+                            <NotificationTemplateFields/>
+                        </Card>
+
+                        <Card title="路由规则" type="inner">
+                            <div className="text-sm text-gray-500 mb-4">
+                                未配置路由规则时接收全部通知；配置后只接收匹配任一规则的通知，同一规则内的条件需同时满足，条件留空表示不限。
+                                「通知事件」包含防篡改、SSH 登录和流量事件。升级通知不受路由规则限制。
                             </div>
-                        }
-                        type="inner"
-                        className="mb-4"
-                        extra={
-                            <Button
-                                type="link"
-                                size="small"
-                                icon={<TestTube size={14}/>}
-                                onClick={() => handleTest('email')}
-                                loading={testMutation.isPending}
-                                disabled={!emailEnabled}
-                            >
-                                测试
-                            </Button>
-                        }
-                    >
-                        <Form.Item label="启用邮件通知" name="emailEnabled" valuePropName="checked">
-                            <Switch/>
-                        </Form.Item>
-
-                        <Form.Item
-                            noStyle
-                            shouldUpdate={(prevValues, currentValues) =>
-                                prevValues.emailEnabled !== currentValues.emailEnabled
-                            }
-                        >
-                            {({getFieldValue}) =>
-                                getFieldValue('emailEnabled') ? (
-                                    <>
-                                        <Form.Item
-                                            label="SMTP 服务器"
-                                            name="emailSmtpHost"
-                                            rules={[{required: true, message: '请输入 SMTP 服务器地址'}]}
-                                            tooltip="邮件服务商的 SMTP 服务器地址，如 smtp.gmail.com"
-                                        >
-                                            <Input placeholder="例如：smtp.gmail.com"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="SMTP 端口"
-                                            name="emailSmtpPort"
-                                            rules={[{required: true, message: '请输入 SMTP 端口'}]}
-                                            tooltip="通常为 587（STARTTLS）或 465（SSL/TLS）"
-                                        >
-                                            <Input type="number" placeholder="587"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="发件人邮箱"
-                                            name="emailFromEmail"
-                                            rules={[
-                                                {required: true, message: '请输入发件人邮箱'},
-                                                {type: 'email', message: '请输入有效的邮箱地址'}
-                                            ]}
-                                            tooltip="用于发送告警邮件的邮箱地址"
-                                        >
-                                            <Input placeholder="your-email@example.com"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="邮箱密码/授权码"
-                                            name="emailPassword"
-                                            rules={[{required: true, message: '请输入邮箱密码或授权码'}]}
-                                            tooltip="某些邮件服务商（如 Gmail、QQ 邮箱）需要使用授权码而非密码"
-                                        >
-                                            <Input.Password placeholder="输入邮箱密码或授权码"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="收件人邮箱"
-                                            name="emailToEmail"
-                                            rules={[
-                                                {required: true, message: '请输入收件人邮箱'},
-                                                {type: 'email', message: '请输入有效的邮箱地址'}
-                                            ]}
-                                            tooltip="接收告警邮件的邮箱地址"
-                                        >
-                                            <Input placeholder="receiver@example.com"/>
-                                        </Form.Item>
-                                        <Form.Item
-                                            label="邮件主题"
-                                            name="emailSubject"
-                                            tooltip="告警邮件的主题，默认为 'Pika 告警通知'"
-                                        >
-                                            <Input placeholder="Pika 告警通知"/>
-                                        </Form.Item>
-                                    </>
-                                ) : null
-                            }
-                        </Form.Item>
-                    </Card>
-
-                    {/* 自定义 Webhook */}
-                    <Card
-                        title="自定义 Webhook"
-                        type="inner"
-                        className="mb-4"
-                        extra={
-                            <Button
-                                type="link"
-                                size="small"
-                                icon={<TestTube size={14}/>}
-                                onClick={() => handleTest('webhook')}
-                                loading={testMutation.isPending}
-                                disabled={!webhookEnabled}
-                            >
-                                测试
-                            </Button>
-                        }
-                    >
-                        <Form.Item label="启用自定义 Webhook" name="webhookEnabled" valuePropName="checked">
-                            <Switch/>
-                        </Form.Item>
-
-                        <Form.Item
-                            noStyle
-                            shouldUpdate={(prevValues, currentValues) =>
-                                prevValues.webhookEnabled !== currentValues.webhookEnabled
-                            }
-                        >
-                            {({getFieldValue}) =>
-                                getFieldValue('webhookEnabled') ? (
+                            <Form.List name="routes">
+                                {(fields, {add, remove}) => (
                                     <>
-                                        <Form.Item
-                                            label="Webhook URL"
-                                            name="webhookUrl"
-                                            rules={[
-                                                {required: true, message: '请输入自定义 Webhook URL'},
-                                                {type: 'url', message: '请输入有效的 URL'},
-                                            ]}
-                                        >
-                                            <Input placeholder="https://your-server.com/webhook"/>
-                                        </Form.Item>
-                                        {/* HTTP 方法 */}
-                                        <Form.Item
-                                            label="HTTP 方法"
-                                            name="webhookMethod"
-                                            tooltip="选择 HTTP 请求方法"
-                                        >
-                                            <Select
-                                                placeholder="选择 HTTP 方法"
-                                                options={[
-                                                    {label: 'GET', value: 'GET'},
-                                                    {label: 'POST', value: 'POST'},
-                                                    {label: 'PUT', value: 'PUT'},
-                                                    {label: 'PATCH', value: 'PATCH'},
-                                                    {label: 'DELETE', value: 'DELETE'},
-                                                ]}
-                                            />
-                                        </Form.Item>
-
-                                        {/* 自定义请求体 */}
-                                        <Form.Item
-                                            label="自定义请求体"
-                                            name="webhookCustomBody"
-                                            rules={[
-                                                {
-                                                    required: true,
-                                                    message: '请输入自定义请求体模板'
-                                                }
-                                            ]}
-                                            tooltip="支持变量替换，可用变量见下方说明"
-                                        >
-                                            <Input.TextArea
-                                                rows={6}
-                                                placeholder='示例: {"alert": "{{alert.message}}", "host": "{{agent.hostname}}"}'
-                                            />
-                                        </Form.Item>
-
-                                        {/* 自定义请求头 */}
-                                        <Form.Item label="自定义请求头"
-                                                   tooltip="添加自定义 HTTP 请求头">
-                                            <Form.List name="webhookHeaders">
-                                                {(fields, {add, remove}) => (
-                                                    <>
-                                                        {fields.map(({
-                                                                         key,
-                                                                         name,
-                                                                         ...restField
-                                                                     }) => (
-                                                            <Space
-                                                                key={key}
-                                                                style={{
-                                                                    display: 'flex',
-                                                                    marginBottom: 8
-                                                                }}
-                                                                align="baseline"
-                                                            >
-                                                                <Form.Item
-                                                                    {...restField}
-                                                                    name={[name, 'key']}
-                                                                    rules={[{
-                                                                        required: true,
-                                                                        message: '请输入 Header 名称'
-                                                                    }]}
-                                                                >
-                                                                    <Input
-                                                                        placeholder="Header 名称"
-                                                                        style={{width: 200}}
-                                                                    />
-                                                                </Form.Item>
-                                                                <Form.Item
-                                                                    {...restField}
-                                                                    name={[name, 'value']}
-                                                                    rules={[{
-                                                                        required: true,
-                                                                        message: '请输入 Header 值'
-                                                                    }]}
-                                                                >
-                                                                    <Input
-                                                                        placeholder="Header 值"
-                                                                        style={{width: 300}}
-                                                                    />
-                                                                </Form.Item>
-                                                                <Button
-                                                                    onClick={() => remove(name)}
-                                                                    danger
-                                                                    type="link"
-                                                                >
-                                                                    删除
-                                                                </Button>
-                                                            </Space>
-                                                        ))}
-                                                        <Form.Item>
-                                                            <Button
-                                                                type="dashed"
-                                                                onClick={() => add()}
-                                                                block
-                                                            >
-                                                                添加请求头
-                                                            </Button>
-                                                        </Form.Item>
-                                                    </>
-                                                )}
-                                            </Form.List>
-                                        </Form.Item>
+                                        {fields.map(({key, name, ...restField}) => (
+                                            <div key={key}
+                                                 className={'border dark:border-gray-700 rounded-md p-3 mb-3'}>
+                                                <div className="flex justify-between items-center mb-2">
+                                                    <div className="font-medium">规则 {name + 1}</div>
+                                                    <Button onClick={() => remove(name)} danger type="link"
+                                                            size="small">
+                                                        删除
+                                                    </Button>
+                                                </div>
+                                                <div className="grid grid-cols-1 md:grid-cols-2 gap-x-4">
+                                                    <Form.Item {...restField} label="告警类型" name={[name, 'alertTypes']}>
+                                                        <Select mode="multiple" allowClear placeholder="不限"
+                                                                options={alertTypeOptions}/>
+                                                    </Form.Item>
+                                                    <Form.Item {...restField} label="告警级别" name={[name, 'levels']}>
+                                                        <Select mode="multiple" allowClear placeholder="不限"
+                                                                options={levelOptions}/>
+                                                    </Form.Item>
+                                                    <Form.Item
+                                                        {...restField}
+                                                        label="探针标签"
+                                                        name={[name, 'agentTags']}
+                                                        tooltip="探针包含任一标签即匹配"
+                                                        className="mb-0"
+                                                    >
+                                                        <Select
+                                                            mode="tags"
+                                                            allowClear
+                                                            placeholder="不限"
+                                                            options={(tags || []).map((tag) => ({label: tag, value: tag}))}
+                                                        />
+                                                    </Form.Item>
+                                                    <Form.Item {...restField} label="事件类型" name={[name, 'events']}
+                                                               className="mb-0">
+                                                        <Select mode="multiple" allowClear placeholder="不限"
+                                                                options={eventOptions}/>
+                                                    </Form.Item>
+                                                </div>
+                                            </div>
+                                        ))}
+                                        <Button type="dashed" onClick={() => add({})} block>
+                                            添加路由规则
+                                        </Button>
                                     </>
-                                ) : null
-                            }
-                        </Form.Item>
-
-                        <NotificationCustomHelp/>
-                    </Card>
-
-                    <Form.Item>
-                        <Button type="primary" htmlType="submit" loading={saveMutation.isPending}>
-                            保存配置
-                        </Button>
-                    </Form.Item>
-                </Space>
-            </Form>
+                                )}
+                            </Form.List>
+                        </Card>
+                    </Space>
+                </Form>
+            </Modal>
         </div>
     );
 };
@@ -16,7 +16,7 @@ const NotificationCustomHelp = () => {
                     <div className={'border dark:border-gray-700 p-2 rounded-md text-xs mt-1 bg-gray-50 dark:bg-gray-800'}>
                         <div className={'font-semibold mb-1'}>可用变量：</div>
                         <div className={'grid grid-cols-2 gap-x-4 gap-y-1'}>
-                            <div>• <code className={'bg-gray-100 dark:bg-gray-700 px-1 rounded'}>{`{{message}}`}</code> - 告警消息</div>
+                            <div>• <code className={'bg-gray-100 dark:bg-gray-700 px-1 rounded'}>{`{{message}}`}</code> - 告警消息（配置了消息模板时为模板渲染结果）</div>
                             <div>• <code className={'bg-gray-100 dark:bg-gray-700 px-1 rounded'}>{`{{agent.id}}`}</code> - 探针ID</div>
                             <div>• <code className={'bg-gray-100 dark:bg-gray-700 px-1 rounded'}>{`{{agent.name}}`}</code> - 探针名称</div>
                             <div>• <code className={'bg-gray-100 dark:bg-gray-700 px-1 rounded'}>{`{{agent.hostname}}`}</code> - 主机名</div>
@@ -0,0 +1,133 @@
+import {useState} from 'react';
+import {App, Button, Collapse, type CollapseProps, Form, Input, Tabs} from 'antd';
+import {useMutation} from '@tanstack/react-query';
+import {type NotificationTemplateEvent, previewNotificationTemplate} from '@/api/property.ts';
+import {getErrorMessage} from '@/lib/utils';
+
+export const templateEvents: { key: NotificationTemplateEvent; label: string; placeholder: string }[] = [
+    {
+        key: 'firing',
+        label: '告警触发',
+        placeholder: '{{.LevelIcon}} {{.TypeName}}\n探针: {{.Agent.Name}} ({{.IP}})\n{{.Record.Message}}\n当前值: {{round .Record.ActualValue 2}}\n时间: {{time .Record.FiredAt}}',
+    },
+    {
+        key: 'resolved',
+        label: '告警恢复',
+        placeholder: '✅ {{.TypeName}}已恢复\n探针: {{.Agent.Name}}\n告警值: {{round .Record.ActualValue 2}}，恢复值: {{round .Record.ResolvedValue 2}}\n恢复时间: {{time .Record.ResolvedAt}}',
+    },
+    {
+        key: 'notice',
+        label: '通知事件',
+        placeholder: '{{.LevelIcon}} {{.TypeName}}\n探针: {{.Agent.Name}}\n{{.Record.Message}}',
+    },
+    {
+        key: 'tamper',
+        label: '防篡改',
+        placeholder: '🛡️ {{.Agent.Name}} 文件被篡改\n{{.Record.Message}}\n时间: {{timeIn .Record.FiredAt "Asia/Shanghai"}}',
+    },
+    {
+        key: 'ssh_login',
+        label: 'SSH 登录',
+        placeholder: '🔐 {{.Agent.Name}} SSH 登录\n{{.Record.Message}}',
+    },
+    {
+        key: 'traffic',
+        label: '流量',
+        placeholder: '📶 {{.Agent.Name}} {{.Record.Message}}\n{{with .Agent.TrafficStats.Data}}已使用 {{bytes .Used}} / {{bytes .Limit}}{{end}}',
+    },
+];
+
+const TemplateHelp = () => (
+    <div className={'border dark:border-gray-700 p-2 rounded-md text-xs bg-gray-50 dark:bg-gray-800 space-y-1 mb-3'}>
+        <div>
+            使用 Go <code className={'bg-gray-100 dark:bg-gray-700 px-1 rounded'}>text/template</code> 语法，留空时使用默认消息；
+            防篡改、SSH 登录和流量事件未配置模板时使用「通知事件」模板。
+        </div>
+        <div>
+            可用数据：<code>.Agent</code> 探针、<code>.Record</code> 告警记录、<code>.Metrics</code> 最新指标（如 <code>.Metrics.CPU.UsagePercent</code>，使用前用 <code>with</code> 判断）、
+            <code>.TypeName</code> 告警类型名称、<code>.LevelIcon</code> 级别图标、<code>.IP</code> 探针IP、<code>.Message</code> 默认消息、<code>.Event</code> 事件类型。
+        </div>
+        <div>
+            辅助函数：<code>bytes</code> 字节格式化、<code>duration</code> 毫秒时长、<code>since</code> 距今时长、<code>time</code> 时间戳格式化、
+            <code>timeIn</code> 按时区格式化、<code>percent</code>、<code>round</code>、<code>upper</code>、<code>lower</code>、<code>default</code>。
+        </div>
+        <div>
+            Slack、Discord、Teams、Opsgenie 的模板替换卡片正文，PagerDuty 替换事件摘要，自定义 Webhook 的 <code>{'{{message}}'}</code> 变量为模板渲染结果。
+        </div>
+    </div>
+);
+
+const TemplateEditor = ({event, placeholder}: {
+    event: NotificationTemplateEvent;
+    placeholder: string;
+}) => {
+    const form = Form.useFormInstance();
+    const {message: messageApi} = App.useApp();
+    const [preview, setPreview] = useState<string>();
+
+    const previewMutation = useMutation({
+        mutationFn: (template: string) => previewNotificationTemplate(template, event),
+        onSuccess: (data) => {
+            setPreview(data.content);
+        },
+        onError: (error: unknown) => {
+            setPreview(undefined);
+            messageApi.error(getErrorMessage(error, '预览失败'));
+        },
+    });
+
+    const handlePreview = () => {
+        const template = form.getFieldValue(['config', 'templates', event]);
+        if (!template || !template.trim()) {
+            messageApi.warning('请先输入模板');
+            return;
+        }
+        previewMutation.mutate(template);
+    };
+
+    return (
+        <>
+            <Form.Item name={['config', 'templates', event]} className={'mb-2'}>
+                <Input.TextArea rows={6} placeholder={placeholder} className={'font-mono'}/>
+            </Form.Item>
+            <Button size="small" onClick={handlePreview} loading={previewMutation.isPending}>
+                预览
+            </Button>
+            {preview !== undefined && (
+                <pre className={'text-xs bg-gray-100 dark:bg-gray-700 p-2 rounded mt-2 whitespace-pre-wrap'}>
+                    {preview}
+                </pre>
+            )}
+        </>
+    );
+};
+
+const NotificationTemplateFields = () => {
+    const items: CollapseProps['items'] = [
+        {
+            key: 'templates',
+            label: '消息模板',
+            forceRender: true,
+            children: <>
+                <TemplateHelp/>
+                <Tabs
+                    size="small"
+                    items={templateEvents.map((item) => ({
+                        key: item.key,
+                        label: item.label,
+                        forceRender: true,
+                        children: <TemplateEditor event={item.key} placeholder={item.placeholder}/>,
+                    }))}
+                />
+            </>,
+        },
+    ];
+
+    return <Collapse
+        bordered={false}
+        items={items}
+        className={'mb-4'}
+    />;
+};
+
+export default NotificationTemplateFields;
@@ -0,0 +1,152 @@
+import {useState} from 'react';
+import {Alert, App, Button, Card, Popconfirm, Select, Space, Spin, Statistic, Table, Upload} from 'antd';
+import type {UploadFile} from 'antd';
+import {Trash2, Upload as UploadIcon} from 'lucide-react';
+import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
+import dayjs from 'dayjs';
+import {clearVulnDB, getVulnDBStatus, importVulnDB} from '@/api/agent.ts';
+import {getErrorMessage} from '@/lib/utils';
+
+type VulnDBFormat = 'osv' | 'debian';
+
+const formatOptions = [
+    {value: 'osv', label: 'OSV（JSON / zip 包）'},
+    {value: 'debian', label: 'Debian Security Tracker JSON'},
+];
+
+// 离线漏洞库：导入 OSV 或 Debian 安全数据，用于匹配探针已安装软件包的漏洞
+const VulnDatabase = () => {
+    const {message: messageApi} = App.useApp();
+    const queryClient = useQueryClient();
+    const [format, setFormat] = useState<VulnDBFormat>('osv');
+    const [fileList, setFileList] = useState<UploadFile[]>([]);
+
+    const {data: status, isLoading} = useQuery({
+        queryKey: ['admin', 'vuln-db', 'status'],
+        queryFn: async () => {
+            const response = await getVulnDBStatus();
+            return response.data;
+        },
+    });
+
+    const importMutation = useMutation({
+        mutationFn: (file: File) => importVulnDB(file, format),
+        onSuccess: (response) => {
+            const result = response.data;
+            messageApi.success(`导入完成：${result.records} 条记录，跳过 ${result.skipped} 条`);
+            setFileList([]);
+            queryClient.invalidateQueries({queryKey: ['admin', 'vuln-db']});
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '导入漏洞库失败'));
+        },
+    });
+
+    const clearMutation = useMutation({
+        mutationFn: (source?: string) => clearVulnDB(source),
+        onSuccess: (response) => {
+            messageApi.success(`已删除 ${response.data.deleted} 条记录`);
+            queryClient.invalidateQueries({queryKey: ['admin', 'vuln-db']});
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '清空漏洞库失败'));
+        },
+    });
+
+    const handleImport = () => {
+        const file = fileList[0]?.originFileObj;
+        if (!file) {
+            messageApi.warning('请选择漏洞库文件');
+            return;
+        }
+        importMutation.mutate(file);
+    };
+
+    if (isLoading) {
+        return (
+            <div className="text-center py-12">
+                <Spin/>
+            </div>
+        );
+    }
+
+    return (
+        <Space orientation="vertical" style={{width: '100%'}} size="large">
+            <Alert
+                type="info"
+                showIcon
+                title="漏洞库用于匹配探针已安装软件包的已知漏洞，数据完全离线保存。可从 OSV（osv-dev 存储桶的各生态 all.zip）或 Debian Security Tracker 下载后导入，同一来源重复导入会覆盖旧数据。"
+            />
+
+            <Card title="漏洞库状态" size="small">
+                <Space orientation="vertical" style={{width: '100%'}}>
+                    <Statistic title="漏洞记录总数" value={status?.total ?? 0}/>
+                    <Table
+                        size="small"
+                        rowKey="source"
+                        pagination={false}
+                        dataSource={status?.sources ?? []}
+                        columns={[
+                            {title: '来源', dataIndex: 'source', key: 'source'},
+                            {title: '记录数', dataIndex: 'count', key: 'count'},
+                            {
+                                title: '导入时间',
+                                dataIndex: 'importedAt',
+                                key: 'importedAt',
+                                render: (value: number) => value ? dayjs(value).format('YYYY-MM-DD HH:mm:ss') : '-',
+                            },
+                            {
+                                title: '操作',
+                                key: 'action',
+                                width: 100,
+                                render: (_, record) => (
+                                    <Popconfirm
+                                        title={`确定清空来源 ${record.source} 的漏洞数据吗？`}
+                                        onConfirm={() => clearMutation.mutate(record.source)}
+                                    >
+                                        <Button type="link" danger size="small">清空</Button>
+                                    </Popconfirm>
+                                ),
+                            },
+                        ]}
+                    />
+                </Space>
+            </Card>
+
+            <Card title="导入漏洞库" size="small">
+                <Space orientation="vertical" style={{width: '100%'}}>
+                    <Select<VulnDBFormat>
+                        style={{width: 280}}
+                        value={format}
+                        options={formatOptions}
+                        onChange={setFormat}
+                    />
+                    <Upload
+                        maxCount={1}
+                        accept=".json,.zip"
+                        fileList={fileList}
+                        beforeUpload={() => false}
+                        onChange={({fileList}) => setFileList(fileList.slice(-1))}
+                    >
+                        <Button icon={<UploadIcon size={16}/>}>选择文件</Button>
+                    </Upload>
+                    <Space>
+                        <Button type="primary" onClick={handleImport} loading={importMutation.isPending}>
+                            导入
+                        </Button>
+                        <Popconfirm
+                            title="确定清空全部漏洞库数据吗？"
+                            onConfirm={() => clearMutation.mutate(undefined)}
+                        >
+                            <Button danger icon={<Trash2 size={16}/>} loading={clearMutation.isPending}>
+                                清空全部
+                            </Button>
+                        </Popconfirm>
+                    </Space>
+                </Space>
+            </Card>
+        </Space>
+    );
+};
+
+export default VulnDatabase;
@@ -1,9 +1,12 @@
 import {Tabs} from 'antd';
-import {Bell, MessageSquare, Settings2, Wifi} from 'lucide-react';
+import {Bell, Code, Layers, MessageSquare, Settings2, ShieldAlert, Wifi} from 'lucide-react';
 import AlertSettings from './AlertSettings';
+import AlertRuleSets from './AlertRuleSets';
+import AlertPromQLRules from './AlertPromQLRules';
 import NotificationChannels from './NotificationChannels';
 import SystemConfig from './SystemConfig';
 import PublicIPConfig from './PublicIPConfig';
+import VulnDatabase from './VulnDatabase';
 import {PageHeader} from "@admin/components";
 import {useSearchParams} from "react-router-dom";
 
@@ -74,6 +77,36 @@ const Settings = () => {
             ),
             children: <AlertSettings/>,
         },
+        {
+            key: 'alert-rule-sets',
+            label: (
+                <span className="flex items-center gap-2">
+                    <Layers size={16}/>
+                    告警规则集
+                </span>
+            ),
+            children: <AlertRuleSets/>,
+        },
+        {
+            key: 'alert-promql-rules',
+            label: (
+                <span className="flex items-center gap-2">
+                    <Code size={16}/>
+                    PromQL 规则
+                </span>
+            ),
+            children: <AlertPromQLRules/>,
+        },
+        {
+            key: 'vuln-db',
+            label: (
+                <span className="flex items-center gap-2">
+                    <ShieldAlert size={16}/>
+                    漏洞库
+                </span>
+            ),
+            children: <VulnDatabase/>,
+        },
     ];
 
     return (
@@ -0,0 +1,444 @@
+import {useEffect, useState} from 'react';
+import {useSearchParams} from 'react-router-dom';
+import {
+    App,
+    Button,
+    Checkbox,
+    DatePicker,
+    Form,
+    Input,
+    Modal,
+    Popconfirm,
+    Radio,
+    Select,
+    Space,
+    Switch,
+    Table,
+    Tag,
+    TimePicker
+} from 'antd';
+import type {ColumnsType} from 'antd/es/table';
+import {Plus} from 'lucide-react';
+import dayjs, {type Dayjs} from 'dayjs';
+import customParseFormat from 'dayjs/plugin/customParseFormat';
+import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
+import {PageHeader} from '@admin/components';
+import type {AlertSilence, AlertSilenceMode, AlertSilenceRequest} from '@/types';
+import {createAlertSilence, deleteAlertSilence, getAlertSilences, updateAlertSilence} from '@/api/silence';
+import {getTags, listAgentsByAdmin} from '@/api/agent';
+import {listMonitors} from '@/api/monitor';
+import {getErrorMessage} from '@/lib/utils';
+
+dayjs.extend(customParseFormat);
+
+const alertTypeOptions = [
+    {value: 'cpu', label: 'CPU使用率'},
+    {value: 'memory', label: '内存使用率'},
+    {value: 'disk', label: '磁盘使用率'},
+    {value: 'network', label: '网速'},
+    {value: 'traffic', label: '流量'},
+    {value: 'cert', label: 'HTTPS证书'},
+    {value: 'service', label: '服务下线'},
+    {value: 'agent_offline', label: '探针离线'},
+    {value: 'promql', label: '自定义规则'},
+    {value: 'disk_forecast', label: '磁盘写满预测'},
+    {value: 'traffic_forecast', label: '流量超额预测'},
+    {value: 'ssh_login', label: 'SSH登录成功'},
+    {value: 'ssh_ban', label: 'SSH暴力破解封禁'},
+    {value: 'tamper', label: '防篡改事件'},
+    {value: 'audit_drift', label: '审计资产变化'},
+];
+
+const weekdayOptions = [
+    {value: 1, label: '周一'},
+    {value: 2, label: '周二'},
+    {value: 3, label: '周三'},
+    {value: 4, label: '周四'},
+    {value: 5, label: '周五'},
+    {value: 6, label: '周六'},
+    {value: 0, label: '周日'},
+];
+
+// 表单中时间以 dayjs 对象编辑
+interface SilenceFormValues extends Omit<AlertSilenceRequest, 'startsAt' | 'endsAt' | 'startTime' | 'endTime'> {
+    range?: [Dayjs, Dayjs];
+    startTime?: Dayjs;
+    endTime?: Dayjs;
+}
+
+const SilenceList = () => {
+    const [form] = Form.useForm<SilenceFormValues>();
+    const {message: messageApi} = App.useApp();
+    const queryClient = useQueryClient();
+    const [searchParams, setSearchParams] = useSearchParams();
+    const [searchValue, setSearchValue] = useState('');
+    const [modalOpen, setModalOpen] = useState(false);
+    const [editing, setEditing] = useState<AlertSilence | null>(null);
+    const mode = Form.useWatch('mode', form) as AlertSilenceMode | undefined;
+
+    const pageIndex = Number(searchParams.get('pageIndex')) || 1;
+    const pageSize = Number(searchParams.get('pageSize')) || 10;
+    const keyword = searchParams.get('keyword') ?? '';
+
+    const {data: silencePaging, isLoading, isFetching} = useQuery({
+        queryKey: ['admin', 'silences', pageIndex, pageSize, keyword],
+        queryFn: async () => {
+            const response = await getAlertSilences(pageIndex, pageSize, keyword || undefined);
+            return response.data;
+        },
+    });
+
+    const {data: agents} = useQuery({
+        queryKey: ['agents-for-silences'],
+        queryFn: async () => {
+            const response = await listAgentsByAdmin();
+            return response.data;
+        },
+    });
+
+    const {data: tags} = useQuery({
+        queryKey: ['admin', 'agents', 'tags'],
+        queryFn: async () => {
+            const response = await getTags();
+            return response.data.tags || [];
+        },
+    });
+
+    const {data: monitors} = useQuery({
+        queryKey: ['monitors-for-silences'],
+        queryFn: async () => {
+            const response = await listMonitors(1, 1000);
+            return response.data.items || [];
+        },
+    });
+
+    useEffect(() => {
+        setSearchValue(keyword);
+    }, [keyword]);
+
+    const agentNameMap = new Map((agents || []).map((agent) => [agent.id, agent.name || agent.id]));
+    const monitorNameMap = new Map((monitors || []).map((monitor) => [monitor.id, monitor.name]));
+    const alertTypeNameMap = new Map(alertTypeOptions.map((item) => [item.value, item.label]));
+
+    const invalidate = () => queryClient.invalidateQueries({queryKey: ['admin', 'silences']});
+
+    const saveMutation = useMutation({
+        mutationFn: (values: AlertSilenceRequest) =>
+            editing ? updateAlertSilence(editing.id, values) : createAlertSilence(values),
+        onSuccess: () => {
+            messageApi.success(editing ? '静默更新成功' : '静默创建成功');
+            setModalOpen(false);
+            invalidate();
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '保存静默失败'));
+        },
+    });
+
+    const deleteMutation = useMutation({
+        mutationFn: deleteAlertSilence,
+        onSuccess: () => {
+            messageApi.success('静默已删除');
+            invalidate();
+        },
+        onError: (error: unknown) => {
+            messageApi.error(getErrorMessage(error, '删除静默失败'));
+        },
+    });
+
+    const handleTableChange = (newPagination: any) => {
+        const nextParams = new URLSearchParams(searchParams);
+        nextParams.set('pageIndex', String(newPagination.current || 1));
+        nextParams.set('pageSize', String(newPagination.pageSize || pageSize));
+        setSearchParams(nextParams);
+    };
+
+    const handleSearch = (value: string) => {
+        const trimmedValue = value.trim();
+        setSearchValue(trimmedValue);
+        const nextParams = new URLSearchParams(searchParams);
+        if (trimmedValue) {
+            nextParams.set('keyword', trimmedValue);
+        } else {
+            nextParams.delete('keyword');
+        }
+        nextParams.set('pageIndex', '1');
+        setSearchParams(nextParams);
+    };
+
+    const openModal = (silence?: AlertSilence) => {
+        setEditing(silence || null);
+        form.resetFields();
+        if (silence) {
+            form.setFieldsValue({
+                name: silence.name,
+                comment: silence.comment,
+                enabled: silence.enabled,
+                agentIds: silence.agentIds || [],
+                tags: silence.tags || [],
+                alertTypes: silence.alertTypes || [],
+                monitorIds: silence.monitorIds || [],
+                mode: silence.mode,
+                range: silence.mode === 'once' ? [dayjs(silence.startsAt), dayjs(silence.endsAt)] : undefined,
+                weekdays: silence.weekdays || [],
+                startTime: silence.startTime ? dayjs(silence.startTime, 'HH:mm') : undefined,
+                endTime: silence.endTime ? dayjs(silence.endTime, 'HH:mm') : undefined,
+            });
+        } else {
+            form.setFieldsValue({
+                enabled: true,
+                mode: 'once',
+                range: [dayjs(), dayjs().add(2, 'hour')],
+                weekdays: [],
+                agentIds: [],
+                tags: [],
+                alertTypes: [],
+                monitorIds: [],
+            });
+        }
+        setModalOpen(true);
+    };
+
+    const handleSubmit = async () => {
+        const {range, startTime, endTime, ...values} = await form.validateFields();
+        const request: AlertSilenceRequest = {...values};
+        if (values.mode === 'once' && range) {
+            request.startsAt = range[0].valueOf();
+            request.endsAt = range[1].valueOf();
+        } else {
+            request.startTime = startTime?.format('HH:mm');
+            request.endTime = endTime?.format('HH:mm');
+        }
+        saveMutation.mutate(request);
+    };
+
+    const formatSchedule = (silence: AlertSilence) => {
+        if (silence.mode === 'once') {
+            return `${dayjs(silence.startsAt).format('YYYY-MM-DD HH:mm')} ~ ${dayjs(silence.endsAt).format('YYYY-MM-DD HH:mm')}`;
+        }
+        const days = weekdayOptions
+            .filter((item) => silence.weekdays?.includes(item.value))
+            .map((item) => item.label)
+            .join('、');
+        const crossDay = silence.endTime <= silence.startTime ? '（次日）' : '';
+        return `每${days} ${silence.startTime} ~ ${crossDay}${silence.endTime}`;
+    };
+
+    const columns: ColumnsType<AlertSilence> = [
+        {
+            title: '名称',
+            dataIndex: 'name',
+            width: 200,
+            render: (_, record) => (
+                <div>
+                    <div className="font-medium">{record.name}</div>
+                    {record.comment && <div className="text-xs text-gray-500">{record.comment}</div>}
+                </div>
+            ),
+        },
+        {
+            title: '匹配条件',
+            key: 'matchers',
+            render: (_, record) => {
+                const empty = !record.agentIds?.length && !record.tags?.length
+                    && !record.alertTypes?.length && !record.monitorIds?.length;
+                if (empty) {
+                    return <Tag color="red">全部告警</Tag>;
+                }
+                return (
+                    <Space size={[4, 4]} wrap>
+                        {record.agentIds?.map((id) => (
+                            <Tag key={`agent-${id}`} color="blue">{agentNameMap.get(id) || id}</Tag>
+                        ))}
+                        {record.tags?.map((tag) => (
+                            <Tag key={`tag-${tag}`}>#{tag}</Tag>
+                        ))}
+                        {record.alertTypes?.map((type) => (
+                            <Tag key={`type-${type}`} color="orange">{alertTypeNameMap.get(type) || type}</Tag>
+                        ))}
+                        {record.monitorIds?.map((id) => (
+                            <Tag key={`monitor-${id}`} color="purple">{monitorNameMap.get(id) || id}</Tag>
+                        ))}
+                    </Space>
+                );
+            },
+        },
+        {
+            title: '时间',
+            key: 'schedule',
+            width: 280,
+            render: (_, record) => formatSchedule(record),
+        },
+        {
+            title: '状态',
+            key: 'status',
+            width: 100,
+            render: (_, record) => {
+                if (!record.enabled) {
+                    return <Tag variant={'filled'}>停用</Tag>;
+                }
+                return record.active ? (
+                    <Tag variant={'filled'} color="success">生效中</Tag>
+                ) : (
+                    <Tag variant={'filled'} color="default">未生效</Tag>
+                );
+            },
+        },
+        {
+            title: '操作',
+            key: 'actions',
+            width: 140,
+            render: (_, record) => (
+                <Space>
+                    <Button type="link" size="small" onClick={() => openModal(record)}>编辑</Button>
+                    <Popconfirm
+                        title="确定删除该静默吗？"
+                        onConfirm={() => deleteMutation.mutate(record.id)}
+                    >
+                        <Button type="link" size="small" danger>删除</Button>
+                    </Popconfirm>
+                </Space>
+            ),
+        },
+    ];
+
+    return (
+        <div className="space-y-6">
+            <PageHeader
+                title="告警静默"
+                description="在维护窗口内静默匹配的告警，告警仍会记录但不发送通知；所有条件同时满足时匹配，为空的条件不限"
+            />
+
+            <div className="bg-white dark:bg-[#1c1c21] rounded-2xl border border-gray-100 dark:border-white/5 shadow-sm p-4 sm:p-6 space-y-4">
+                <div className="flex flex-col md:flex-row justify-between items-start md:items-center gap-4">
+                    <Input.Search
+                        placeholder="按名称搜索"
+                        allowClear
+                        value={searchValue}
+                        onChange={(event) => {
+                            const nextValue = event.target.value;
+                            setSearchValue(nextValue);
+                            if (!nextValue) {
+                                handleSearch('');
+                            }
+                        }}
+                        onSearch={handleSearch}
+                        className="w-full max-w-md"
+                    />
+                    <Button type="primary" icon={<Plus size={16}/>} onClick={() => openModal()}>
+                        新建静默
+                    </Button>
+                </div>
+
+                <Table<AlertSilence>
+                    columns={columns}
+                    dataSource={silencePaging?.items || []}
+                    loading={isLoading || isFetching}
+                    rowKey="id"
+                    pagination={{
+                        current: pageIndex,
+                        pageSize,
+                        total: silencePaging?.total || 0,
+                        showSizeChanger: true,
+                    }}
+                    onChange={handleTableChange}
+                />
+            </div>
+
+            <Modal
+                title={editing ? '编辑告警静默' : '新建告警静默'}
+                open={modalOpen}
+                width={720}
+                onCancel={() => setModalOpen(false)}
+                onOk={handleSubmit}
+                confirmLoading={saveMutation.isPending}
+                forceRender
+            >
+                <Form form={form} layout="vertical">
+                    <Form.Item label="名称" name="name" rules={[{required: true, message: '请输入名称'}]}>
+                        <Input placeholder="如：数据库例行维护"/>
+                    </Form.Item>
+                    <Form.Item label="备注" name="comment">
+                        <Input.TextArea rows={2} placeholder="维护原因等"/>
+                    </Form.Item>
+
+                    <Form.Item label="时间类型" name="mode">
+                        <Radio.Group>
+                            <Radio.Button value="once">指定时间段</Radio.Button>
+                            <Radio.Button value="weekly">每周固定时段</Radio.Button>
+                        </Radio.Group>
+                    </Form.Item>
+                    {mode === 'weekly' ? (
+                        <>
+                            <Form.Item
+                                label="生效日期"
+                                name="weekdays"
+                                rules={[{required: true, type: 'array', min: 1, message: '请至少选择一天'}]}
+                            >
+                                <Checkbox.Group options={weekdayOptions}/>
+                            </Form.Item>
+                            <div className="flex items-center gap-8">
+                                <Form.Item label="开始时间" name="startTime" rules={[{required: true, message: '请选择开始时间'}]}>
+                                    <TimePicker format="HH:mm"/>
+                                </Form.Item>
+                                <Form.Item
+                                    label="结束时间"
+                                    name="endTime"
+                                    tooltip="早于开始时间表示跨天，如 22:00 ~ 02:00"
+                                    rules={[{required: true, message: '请选择结束时间'}]}
+                                >
+                                    <TimePicker format="HH:mm"/>
+                                </Form.Item>
+                            </div>
+                        </>
+                    ) : (
+                        <Form.Item label="时间段" name="range" rules={[{required: true, message: '请选择时间段'}]}>
+                            <DatePicker.RangePicker showTime={{format: 'HH:mm'}} format="YYYY-MM-DD HH:mm"/>
+                        </Form.Item>
+                    )}
+
+                    <Form.Item label="探针" name="agentIds">
+                        <Select
+                            mode="multiple"
+                            allowClear
+                            placeholder="不限"
+                            optionFilterProp="label"
+                            options={(agents || []).map((agent) => ({
+                                label: agent.name || agent.id,
+                                value: agent.id,
+                            }))}
+                        />
+                    </Form.Item>
+                    <Form.Item label="探针标签" name="tags" tooltip="探针包含任一标签即匹配">
+                        <Select
+                            mode="tags"
+                            allowClear
+                            placeholder="不限"
+                            options={(tags || []).map((tag) => ({label: tag, value: tag}))}
+                        />
+                    </Form.Item>
+                    <Form.Item label="告警类型" name="alertTypes">
+                        <Select mode="multiple" allowClear placeholder="不限" options={alertTypeOptions}/>
+                    </Form.Item>
+                    <Form.Item label="监控项" name="monitorIds" tooltip="用于证书和服务下线告警">
+                        <Select
+                            mode="multiple"
+                            allowClear
+                            placeholder="不限"
+                            optionFilterProp="label"
+                            options={(monitors || []).map((monitor) => ({
+                                label: monitor.name,
+                                value: monitor.id,
+                            }))}
+                        />
+                    </Form.Item>
+                    <Form.Item label="启用" name="enabled" valuePropName="checked">
+                        <Switch checkedChildren="开启" unCheckedChildren="关闭"/>
+                    </Form.Item>
+                </Form>
+            </Modal>
+        </div>
+    );
+};
+
+export default SilenceList;
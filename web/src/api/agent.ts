@@ -2,8 +2,11 @@ import {del, get, post, put} from './request';
 import type {
     Agent,
     LatestMetrics,
+    SSHBan,
     SSHLoginConfig,
     SSHLoginEvent,
+    SSHSession,
+    SSHSudoEvent,
     TrafficStats,
     UpdateSSHLoginConfigRequest,
     UpdateTrafficConfigRequest
@@ -373,6 +376,22 @@ export interface KernelAssets {
     securityModules?: SecurityModuleInfo;
 }
 
+export interface PackageInfo {
+    name: string;
+    version: string;
+    arch?: string;
+    source?: string;
+}
+
+export interface PackageAssets {
+    manager: 'dpkg' | 'rpm' | 'apk';
+    distro?: string;
+    distroVersion?: string;
+    codename?: string;
+    packages?: PackageInfo[];
+    total: number;
+}
+
 export interface AssetInventory {
     networkAssets?: NetworkAssets;
     processAssets?: ProcessAssets;
@@ -380,6 +399,7 @@ export interface AssetInventory {
     fileAssets?: FileAssets;
     kernelAssets?: KernelAssets;
     loginAssets?: LoginAssets;
+    packageAssets?: PackageAssets;
 }
 
 export interface AuditStatistics {
@@ -443,6 +463,90 @@ export const listAuditResults = (agentId: string) => {
     return get<{ items: AuditResultSummary[]; total: number }>(`/admin/agents/${agentId}/audit/results`);
 };
 
+export interface AuditDiffSection {
+    name: string;
+    title: string;
+    added: string[];
+    removed: string[];
+}
+
+// 两次审计结果的差异
+export interface AuditDiff {
+    baseId: number;
+    targetId: number;
+    baseTime: number;
+    targetTime: number;
+    sections: AuditDiffSection[];
+    totalAdded: number;
+    totalRemoved: number;
+}
+
+// 对比两次审计结果（不传参数时对比最近两次）
+export const diffAuditResults = (agentId: string, base?: number, target?: number) => {
+    const params = new URLSearchParams();
+    if (base) params.set('base', String(base));
+    if (target) params.set('target', String(target));
+    const query = params.toString();
+    return get<AuditDiff>(`/admin/agents/${agentId}/audit/diff${query ? `?${query}` : ''}`);
+};
+
+// 软件包漏洞
+export interface PackageVulnerability {
+    package: string;
+    version: string;
+    source: string;
+    arch: string;
+    vulnId: string;
+    cves: string[];
+    severity: 'critical' | 'high' | 'medium' | 'low' | 'unknown';
+    summary: string;
+    fixedVersion: string;
+    dataSource: string;
+}
+
+// 探针漏洞匹配结果
+export interface AgentVulnerabilityReport {
+    agentId: string;
+    auditId: number;
+    auditTime: number;
+    manager: string;
+    distro: string;
+    distroVersion: string;
+    ecosystems: string[];
+    totalPackages: number;
+    vulnerablePackages: number;
+    severityCounts: Record<string, number>;
+    items: PackageVulnerability[];
+    message?: string;
+}
+
+export interface VulnDBStatus {
+    total: number;
+    sources: { source: string; count: number; importedAt: number }[];
+}
+
+// 获取探针已安装软件包的漏洞（基于最近一次审计）
+export const getAgentVulnerabilities = (agentId: string) => {
+    return get<AgentVulnerabilityReport>(`/admin/agents/${agentId}/vulnerabilities`);
+};
+
+// 获取离线漏洞库状态
+export const getVulnDBStatus = () => {
+    return get<VulnDBStatus>('/admin/vuln-db/status');
+};
+
+// 导入离线漏洞库
+export const importVulnDB = (file: File, format: 'osv' | 'debian') => {
+    const formData = new FormData();
+    formData.append('file', file);
+    return post<{ source: string; records: number; skipped: number }>(`/admin/vuln-db/import?format=${format}`, formData, {timeout: 600000});
+};
+
+// 清空离线漏洞库（指定 source 时仅清空该来源）
+export const clearVulnDB = (source?: string) => {
+    return del<{ deleted: number }>(`/admin/vuln-db${source ? `?source=${encodeURIComponent(source)}` : ''}`);
+};
+
 // 更新探针名称
 export const updateAgentName = (agentId: string, name: string) => {
     return put(`/admin/agents/${agentId}/name`, {name});
@@ -567,6 +671,32 @@ export const deleteSSHLoginEvents = async (agentId: string) => {
     await del(`/admin/agents/${agentId}/ssh-login/events`);
 };
 
+// 获取 SSH 封禁记录列表
+export const getSSHBans = async (agentId: string, params?: any) => {
+    const query = qs.stringify(params);
+    const response = await get<{ items: SSHBan[]; total: number }>(`/admin/agents/${agentId}/ssh-login/bans?${query}`);
+    return response.data;
+};
+
+// 解除 SSH 封禁
+export const liftSSHBan = async (agentId: string, banId: string) => {
+    await del(`/admin/agents/${agentId}/ssh-login/bans/${banId}`);
+};
+
+// 获取 SSH 会话列表（在线和历史）
+export const getSSHSessions = async (agentId: string, params?: any) => {
+    const query = qs.stringify(params);
+    const response = await get<{ items: SSHSession[]; total: number }>(`/admin/agents/${agentId}/ssh-login/sessions?${query}`);
+    return response.data;
+};
+
+// 获取 SSH 会话内的 sudo 提权记录
+export const getSSHSessionSudoEvents = async (agentId: string, sessionId: string, params?: any) => {
+    const query = qs.stringify(params);
+    const response = await get<{ items: SSHSudoEvent[]; total: number }>(`/admin/agents/${agentId}/ssh-login/sessions/${sessionId}/sudo?${query}`);
+    return response.data;
+};
+
 // 清理残留的探针指标数据
 export interface CleanupMetricsResponse {
     message: string;
@@ -1,5 +1,13 @@
-import {del, get} from './request';
-import type {AlertRecord} from '@/types';
+import {del, get, post, put} from './request';
+import type {
+    AlertPromQLRule,
+    AlertPromQLRuleRequest,
+    AlertRecord,
+    AlertRuleSet,
+    AlertRuleSetRequest,
+    EffectiveAlertRules,
+    PromQLSeries,
+} from '@/types';
 
 // 注意：告警配置相关 API 已迁移到 property.ts 中
 // 使用 getAlertConfig() 和 saveAlertConfig() 从 '@/api/property' 导入
@@ -9,6 +17,7 @@ export const getAlertRecords = async (
     pageIndex: number = 1,
     pageSize: number = 20,
     agentId?: string,
+    acknowledged?: boolean,
 ): Promise<{
     items: AlertRecord[];
     total: number;
@@ -21,6 +30,9 @@ export const getAlertRecords = async (
     if (agentId) {
         params.append('agentId', agentId);
     }
+    if (acknowledged !== undefined) {
+        params.append('acknowledged', String(acknowledged));
+    }
 
     const response = await get<{
         items: AlertRecord[];
@@ -35,3 +47,67 @@ export const clearAlertRecords = async (agentId?: string): Promise<void> => {
     if (agentId) url += `?agentId=${agentId}`;
     await del(url);
 };
+
+// 确认告警
+export const acknowledgeAlertRecord = (id: number, comment: string) => {
+    return post<AlertRecord>(`/admin/alert-records/${id}/ack`, {comment});
+};
+
+// 指派告警负责人，负责人为空表示取消指派
+export const assignAlertRecord = (id: number, assignee: string) => {
+    return post<AlertRecord>(`/admin/alert-records/${id}/assign`, {assignee});
+};
+
+// 获取告警规则集列表
+export const getAlertRuleSets = async (): Promise<AlertRuleSet[]> => {
+    const response = await get<AlertRuleSet[]>('/admin/alert-rule-sets');
+    return response.data;
+};
+
+// 创建告警规则集
+export const createAlertRuleSet = (data: AlertRuleSetRequest) => {
+    return post<AlertRuleSet>('/admin/alert-rule-sets', data);
+};
+
+// 更新告警规则集
+export const updateAlertRuleSet = (id: string, data: AlertRuleSetRequest) => {
+    return put<AlertRuleSet>(`/admin/alert-rule-sets/${id}`, data);
+};
+
+// 删除告警规则集
+export const deleteAlertRuleSet = (id: string) => {
+    return del(`/admin/alert-rule-sets/${id}`);
+};
+
+// 获取探针生效的告警规则
+export const getEffectiveAlertRules = async (agentId: string): Promise<EffectiveAlertRules> => {
+    const response = await get<EffectiveAlertRules>(`/admin/agents/${agentId}/alert-rules`);
+    return response.data;
+};
+
+// 获取 PromQL 告警规则列表
+export const getAlertPromQLRules = async (): Promise<AlertPromQLRule[]> => {
+    const response = await get<AlertPromQLRule[]>('/admin/alert-promql-rules');
+    return response.data;
+};
+
+// 创建 PromQL 告警规则
+export const createAlertPromQLRule = (data: AlertPromQLRuleRequest) => {
+    return post<AlertPromQLRule>('/admin/alert-promql-rules', data);
+};
+
+// 更新 PromQL 告警规则
+export const updateAlertPromQLRule = (id: string, data: AlertPromQLRuleRequest) => {
+    return put<AlertPromQLRule>(`/admin/alert-promql-rules/${id}`, data);
+};
+
+// 删除 PromQL 告警规则
+export const deleteAlertPromQLRule = (id: string) => {
+    return del(`/admin/alert-promql-rules/${id}`);
+};
+
+// 预览 PromQL 表达式当前返回的序列
+export const previewPromQL = async (expr: string): Promise<PromQLSeries[]> => {
+    const response = await post<PromQLSeries[]>('/admin/alert-promql-rules/preview', {expr});
+    return response.data;
+};
@@ -52,17 +52,33 @@ export const saveMetricsConfig = async (config: MetricsConfig): Promise<void> =>
 
 const PROPERTY_ID_NOTIFICATION_CHANNELS = 'notification_channels';
 
-// 通知渠道配置（通过 type 标识，不再使用独立ID）
+export type NotificationTemplateEvent = 'firing' | 'resolved' | 'notice' | 'tamper' | 'ssh_login' | 'traffic';
+
+// 通知路由规则：同一规则内各条件需同时满足，条件为空表示不限制
+export interface NotificationRoute {
+    alertTypes?: string[]; // 告警类型
+    levels?: string[]; // 告警级别
+    agentTags?: string[]; // 探针标签，包含任一标签即匹配
+    events?: NotificationTemplateEvent[]; // 事件类型
+}
+
+// 通知渠道配置，同一类型可配置多个实例
 export interface NotificationChannel {
-    type: 'dingtalk' | 'wecom' | 'wecomApp' | 'feishu' | 'email' | 'webhook' | 'telegram'; // 渠道类型，作为唯一标识
+    id: string; // 渠道ID，旧版本配置使用 type 作为ID
+    name?: string; // 渠道名称
+    type: 'dingtalk' | 'wecom' | 'wecomApp' | 'feishu' | 'email' | 'webhook' | 'telegram' | 'slack' | 'discord' | 'teams' | 'pagerduty' | 'opsgenie' | 'ntfy' | 'gotify' | 'bark' | 'serverchan'; // 渠道类型
     enabled: boolean; // 是否启用
+    routes?: NotificationRoute[]; // 路由规则，匹配任一规则时发送，为空时接收全部通知
     config: Record<string, any>; // JSON配置，根据type不同而不同
 }
 
 // 获取通知渠道列表
 export const getNotificationChannels = async (): Promise<NotificationChannel[]> => {
     const channels = await getProperty<NotificationChannel[]>(PROPERTY_ID_NOTIFICATION_CHANNELS);
-    return channels || [];
+    return (channels || []).map((channel) => ({
+        ...channel,
+        id: channel.id || channel.type,
+    }));
 };
 
 // 保存通知渠道列表
@@ -71,8 +87,17 @@ export const saveNotificationChannels = async (channels: NotificationChannel[]):
 };
 
 // 测试通知渠道（从数据库读取配置）
-export const testNotificationChannel = async (type: string): Promise<{ message: string }> => {
-    const response = await post<{ message: string }>(`/admin/notification-channels/${type}/test`);
+export const testNotificationChannel = async (id: string): Promise<{ message: string }> => {
+    const response = await post<{ message: string }>(`/admin/notification-channels/${id}/test`);
+    return response.data;
+};
+
+// 使用示例告警记录预览通知模板
+export const previewNotificationTemplate = async (template: string, event: NotificationTemplateEvent): Promise<{ content: string }> => {
+    const response = await post<{ content: string }>('/admin/notification-channels/template/preview', {
+        template,
+        event,
+    });
     return response.data;
 };
 
@@ -133,27 +158,68 @@ export interface AlertRules {
     cpuEnabled: boolean;
     cpuThreshold: number;
     cpuDuration: number;
+    cpuRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    cpuRecoveryDuration: number;   // 恢复持续时间（秒）
     memoryEnabled: boolean;
     memoryThreshold: number;
     memoryDuration: number;
+    memoryRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    memoryRecoveryDuration: number;   // 恢复持续时间（秒）
     diskEnabled: boolean;
     diskThreshold: number;
     diskDuration: number;
+    diskRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    diskRecoveryDuration: number;   // 恢复持续时间（秒）
+    diskMountPoints?: string[];     // 按挂载点告警（精确名称或以 ~ 开头的正则），为空时按汇总值告警
     networkEnabled: boolean;
     networkThreshold: number;  // 网速阈值(MB/s)
     networkDuration: number;
+    networkRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    networkRecoveryDuration: number;   // 恢复持续时间（秒）
+    networkInterfaces?: string[];      // 按网卡告警（精确名称或以 ~ 开头的正则），为空时按汇总值告警
     certEnabled: boolean;      // HTTPS 证书告警开关
     certThreshold: number;     // 证书剩余天数阈值（天）
     serviceEnabled: boolean;   // 服务下线告警开关
     serviceDuration: number;   // 服务下线持续时间（秒）
     agentOfflineEnabled: boolean;   // 探针离线告警开关
     agentOfflineDuration: number;   // 探针离线持续时间（秒）
+    diskForecastEnabled: boolean;    // 磁盘写满预测告警开关
+    diskForecastHours: number;       // 预计多少小时内写满时告警
+    diskForecastLookback: number;    // 用于预测的历史数据时长（小时）
+    trafficForecastEnabled: boolean; // 流量超额预测告警开关
 }
 
 export interface AlertNotifications {
     trafficEnabled: boolean;         // 流量告警通知
     sshLoginSuccessEnabled: boolean; // SSH 登录成功通知
+    sshBanEnabled: boolean;          // SSH 暴力破解封禁通知
     tamperEventEnabled: boolean;     // 防篡改事件通知
+    auditDriftEnabled: boolean;      // 定时审计资产变化通知
+}
+
+// 未确认严重告警的重复通知和升级
+export interface AlertEscalation {
+    repeatEnabled: boolean;         // 是否重复通知未确认的严重告警
+    repeatInterval: number;         // 重复通知间隔（分钟）
+    repeatMaxTimes: number;         // 最多重复通知次数（0 表示不限制）
+    escalationEnabled: boolean;     // 是否在超时未确认后升级通知
+    escalationTimeout: number;      // 告警触发后多久未确认则升级（分钟）
+    escalationChannels: string[];   // 升级通知渠道ID
+}
+
+// 指标告警抖动检测
+export interface AlertFlapping {
+    enabled: boolean;    // 是否启用抖动检测
+    window: number;      // 检测窗口（分钟）
+    threshold: number;   // 窗口内触发/恢复次数达到该值时判定为抖动
+}
+
+// 告警抑制规则：同一探针存在告警中的源告警时，不再通知目标类型的告警
+export interface AlertInhibitRule {
+    name: string;            // 规则名称
+    enabled: boolean;        // 是否启用
+    sourceTypes: string[];   // 源告警类型
+    targetTypes: string[];   // 被抑制的告警类型
 }
 
 // 全局告警配置
@@ -162,6 +228,9 @@ export interface AlertConfig {
     maskIP: boolean;   // 是否在通知中打码 IP 地址
     rules: AlertRules;
     notifications: AlertNotifications;
+    escalation: AlertEscalation;
+    inhibitRules: AlertInhibitRule[];
+    flapping: AlertFlapping;
 }
 
 // 获取告警配置
@@ -0,0 +1,40 @@
+import {del, get, post, put} from './request';
+import type {AlertSilence, AlertSilenceRequest} from '@/types';
+
+export interface AlertSilenceListResponse {
+    items: AlertSilence[];
+    total: number;
+}
+
+// 获取告警静默列表（分页）
+export const getAlertSilences = (pageIndex: number, pageSize: number, name?: string) => {
+    const params = new URLSearchParams();
+    params.append('pageIndex', pageIndex.toString());
+    params.append('pageSize', pageSize.toString());
+    params.set('sortOrder', 'desc');
+    params.set('sortField', 'createdAt');
+    if (name) {
+        params.append('name', name);
+    }
+    return get<AlertSilenceListResponse>(`/admin/silences?${params.toString()}`);
+};
+
+// 获取告警静默详情
+export const getAlertSilence = (id: string) => {
+    return get<AlertSilence>(`/admin/silences/${id}`);
+};
+
+// 创建告警静默
+export const createAlertSilence = (data: AlertSilenceRequest) => {
+    return post<AlertSilence>('/admin/silences', data);
+};
+
+// 更新告警静默
+export const updateAlertSilence = (id: string, data: AlertSilenceRequest) => {
+    return put<AlertSilence>(`/admin/silences/${id}`, data);
+};
+
+// 删除告警静默
+export const deleteAlertSilence = (id: string) => {
+    return del(`/admin/silences/${id}`);
+};
@@ -1,23 +1,58 @@
 import request from './request';
 import qs from 'qs';
 
+// 防篡改保护模式：不可变属性 / SHA-256 哈希基线 / 两者同时
+export type TamperMode = 'immutable' | 'hash' | 'hybrid';
+
+export interface TamperOptions {
+    mode?: TamperMode;
+    hashInterval?: number;
+    enforcePaths?: string[];
+}
+
+export interface TamperUnlockWindow {
+    path: string;
+    until: number;
+    reason: string;
+    createdBy: string;
+    createdAt: number;
+}
+
 export interface TamperConfig {
     id: string;
     agentId: string;
     enabled: boolean;
     paths: string[];
+    mode?: TamperMode;
+    hashInterval?: number;
+    enforcePaths?: string[];
+    unlocks?: TamperUnlockWindow[];
     applyStatus?: string;
     applyMessage?: string;
     createdAt: number;
     updatedAt: number;
 }
 
+export interface TamperProcess {
+    pid: number;
+    ppid: number;
+    uid: number;
+    username?: string;
+    exe?: string;
+    cmdline?: string;
+    processTree?: string[];
+}
+
 export interface TamperEvent {
     id: string;
     agentId: string;
     path: string;
     operation: string;
     details: string;
+    oldHash?: string;
+    newHash?: string;
+    restored?: boolean;
+    process?: TamperProcess;
     timestamp: number;
     createdAt: number;
 }
@@ -48,13 +83,28 @@ export const getTamperConfig = (agentId: string) => {
 };
 
 // 更新防篡改配置
-export const updateTamperConfig = (agentId: string, enabled: boolean, paths: string[]) => {
+export const updateTamperConfig = (agentId: string, enabled: boolean, paths: string[], options?: TamperOptions) => {
     return request.put<TamperConfig>(
         `/admin/agents/${agentId}/tamper/config`,
-        {enabled, paths}
+        {enabled, paths, ...options}
     );
 };
 
+// 以当前内容刷新强制恢复快照（paths 为空时刷新所有强制恢复目录）
+export const refreshTamperSnapshot = (agentId: string, paths?: string[]) => {
+    return request.post(`/admin/agents/${agentId}/tamper/snapshot`, {paths: paths || []});
+};
+
+// 临时解除目录保护，到期后探针自动重新保护并更新基线
+export const unlockTamperPath = (agentId: string, path: string, minutes: number, reason?: string) => {
+    return request.post<TamperUnlockWindow>(`/admin/agents/${agentId}/tamper/unlock`, {path, minutes, reason});
+};
+
+// 提前结束临时解锁
+export const relockTamperPath = (agentId: string, path: string) => {
+    return request.delete(`/admin/agents/${agentId}/tamper/unlock?${qs.stringify({path})}`);
+};
+
 // 获取防篡改事件
 export const getTamperEvents = (agentId: string, params?: any) => {
     const paramStr = qs.stringify(params);
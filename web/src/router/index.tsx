@@ -21,6 +21,7 @@ const PublicMonitorDetailPage = lazy(() => import('@portal/pages/MonitorDetail.t
 const MonitorListPage = lazy(() => import('@admin/pages/Monitors/MonitorList'));
 const DDNSPage = lazy(() => import('@admin/pages/DDNS'));
 const AlertRecordListPage = lazy(() => import('@admin/pages/AlertRecords'));
+const SilenceListPage = lazy(() => import('@admin/pages/Silences'));
 
 const LoadingFallback = () => (
     <div className="flex h-[75vh] w-full items-center justify-center text-gray-500 dark:text-cyan-300">
@@ -123,6 +124,10 @@ const router = createBrowserRouter([
                 path: 'alert-records',
                 element: lazyLoad(AlertRecordListPage),
             },
+            {
+                path: 'silences',
+                element: lazyLoad(SilenceListPage),
+            },
             {
                 path: 'settings',
                 element: lazyLoad(SettingsPage),
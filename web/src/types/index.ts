@@ -65,6 +65,7 @@ export interface TamperProtectConfig {
 export interface SSHLoginConfigData {
     enabled: boolean;
     ipWhitelist?: string[];  // IP白名单，白名单中的IP只记录不发送通知
+    banPolicy?: SSHBanPolicy; // 暴力破解封禁策略
     applyStatus?: string;
     applyMessage?: string;
 }
@@ -322,6 +323,7 @@ export interface MonitorTask {
     agentIds?: string[];
     agentNames?: string[];
     tags?: string[];       // 标签列表，拥有这些标签的探针都会执行此监控
+    parentId?: string;     // 父监控项 ID，父监控项下线时抑制本监控项的告警
     createdAt: number;
     updatedAt: number;
 }
@@ -340,6 +342,7 @@ export interface MonitorTaskRequest {
     icmpConfig?: MonitorIcmpConfig | null;
     agentIds?: string[];
     tags?: string[];       // 标签列表
+    parentId?: string;     // 父监控项 ID
 }
 
 export interface MonitorListResponse {
@@ -485,27 +488,68 @@ export interface AlertRules {
     cpuEnabled: boolean;
     cpuThreshold: number;
     cpuDuration: number;
+    cpuRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    cpuRecoveryDuration: number;   // 恢复持续时间（秒）
     memoryEnabled: boolean;
     memoryThreshold: number;
     memoryDuration: number;
+    memoryRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    memoryRecoveryDuration: number;   // 恢复持续时间（秒）
     diskEnabled: boolean;
     diskThreshold: number;
     diskDuration: number;
+    diskRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    diskRecoveryDuration: number;   // 恢复持续时间（秒）
+    diskMountPoints?: string[];     // 按挂载点告警（精确名称或以 ~ 开头的正则），为空时按汇总值告警
     networkEnabled: boolean;
     networkThreshold: number;  // 网速阈值(MB/s)
     networkDuration: number;
+    networkRecoveryThreshold: number;  // 恢复阈值（0 表示与告警阈值相同）
+    networkRecoveryDuration: number;   // 恢复持续时间（秒）
+    networkInterfaces?: string[];      // 按网卡告警（精确名称或以 ~ 开头的正则），为空时按汇总值告警
     certEnabled: boolean;      // HTTPS 证书告警开关
     certThreshold: number;     // 证书剩余天数阈值（天）
     serviceEnabled: boolean;   // 服务下线告警开关
     serviceDuration: number;   // 服务下线持续时间（秒）
     agentOfflineEnabled: boolean;   // 探针离线告警开关
     agentOfflineDuration: number;   // 探针离线持续时间（秒）
+    diskForecastEnabled: boolean;    // 磁盘写满预测告警开关
+    diskForecastHours: number;       // 预计多少小时内写满时告警
+    diskForecastLookback: number;    // 用于预测的历史数据时长（小时）
+    trafficForecastEnabled: boolean; // 流量超额预测告警开关
 }
 
 export interface AlertNotifications {
     trafficEnabled: boolean;         // 流量告警通知
     sshLoginSuccessEnabled: boolean; // SSH 登录成功通知
+    sshBanEnabled: boolean;          // SSH 暴力破解封禁通知
     tamperEventEnabled: boolean;     // 防篡改事件通知
+    auditDriftEnabled: boolean;      // 定时审计资产变化通知
+}
+
+// 未确认严重告警的重复通知和升级
+export interface AlertEscalation {
+    repeatEnabled: boolean;         // 是否重复通知未确认的严重告警
+    repeatInterval: number;         // 重复通知间隔（分钟）
+    repeatMaxTimes: number;         // 最多重复通知次数（0 表示不限制）
+    escalationEnabled: boolean;     // 是否在超时未确认后升级通知
+    escalationTimeout: number;      // 告警触发后多久未确认则升级（分钟）
+    escalationChannels: string[];   // 升级通知渠道ID
+}
+
+// 指标告警抖动检测
+export interface AlertFlapping {
+    enabled: boolean;    // 是否启用抖动检测
+    window: number;      // 检测窗口（分钟）
+    threshold: number;   // 窗口内触发/恢复次数达到该值时判定为抖动
+}
+
+// 告警抑制规则：同一探针存在告警中的源告警时，不再通知目标类型的告警
+export interface AlertInhibitRule {
+    name: string;            // 规则名称
+    enabled: boolean;        // 是否启用
+    sourceTypes: string[];   // 源告警类型
+    targetTypes: string[];   // 被抑制的告警类型
 }
 
 // 全局告警配置（现在存储在 Property 中）
@@ -514,6 +558,9 @@ export interface AlertConfig {
     maskIP: boolean;   // 是否在通知中打码 IP 地址
     rules: AlertRules;
     notifications: AlertNotifications;
+    escalation: AlertEscalation;
+    inhibitRules: AlertInhibitRule[];
+    flapping: AlertFlapping;
 }
 
 export interface AlertRecord {
@@ -531,10 +578,140 @@ export interface AlertRecord {
     status: string;
     firedAt: number;
     resolvedAt?: number;
+    ruleSource?: AlertRuleSource;  // 产生告警的规则来源
+    ruleSetId?: string;            // 产生告警的规则集或 PromQL 规则ID（全局规则为空）
+    ruleSetName?: string;          // 产生告警的规则集或 PromQL 规则名称
+    labels?: Record<string, string> | null;  // 告警序列的标签（PromQL 规则）
+    monitorId?: string;            // 监控项ID（证书、服务下线告警）
+    device?: string;               // 磁盘挂载点或网卡名称（按设备告警）
+    stateId?: string;              // 产生告警的告警状态ID（外部事件的去重标识）
+    silenced: boolean;             // 是否被静默（不发送通知）
+    silenceId?: string;            // 匹配的静默ID
+    inhibited: boolean;            // 是否被抑制（由其他告警引起，不发送通知）
+    inhibitedBy?: number;          // 引起抑制的告警记录ID
+    inhibitReason?: string;        // 抑制原因
+    acknowledgedAt?: number;       // 确认时间
+    acknowledgedBy?: string;       // 确认人
+    ackComment?: string;           // 确认备注
+    assignee?: string;             // 负责人
+    assignedAt?: number;           // 指派时间
+    repeatCount: number;           // 未确认时已重复通知的次数
+    lastRepeatAt?: number;         // 最后一次重复通知时间
+    escalatedAt?: number;          // 升级通知时间
+    flapping: boolean;             // 是否为抖动告警
+    flapCount?: number;            // 抖动期间的状态变化次数
+    flapEndedAt?: number;          // 抖动结束时间
+    createdAt: number;
+    updatedAt: number;
+}
+
+// 告警规则来源：全局配置、按标签匹配的规则集、按探针匹配的规则集、自定义 PromQL 规则
+export type AlertRuleSource = 'global' | 'tag' | 'agent' | 'promql';
+
+// 告警规则集（按探针或标签覆盖全局告警规则，优先级：探针 > 标签 > 全局）
+export interface AlertRuleSet {
+    id: string;
+    name: string;
+    description: string;
+    enabled: boolean;
+    agentIds: string[];
+    tags: string[];
+    priority: number;
+    rules: AlertRules;
+    createdAt: number;
+    updatedAt: number;
+}
+
+export interface AlertRuleSetRequest {
+    name: string;
+    description?: string;
+    enabled: boolean;
+    agentIds: string[];
+    tags: string[];
+    priority: number;
+    rules: AlertRules;
+}
+
+// 自定义 PromQL 告警规则，表达式返回的每个序列独立触发和恢复告警
+export interface AlertPromQLRule {
+    id: string;
+    name: string;
+    description: string;
+    enabled: boolean;
+    expr: string;
+    for: number;          // 序列持续存在多久后触发（秒）
+    severity: 'info' | 'warning' | 'critical';
+    labels: Record<string, string> | null;
+    summary: string;      // 告警消息模板，支持 {{value}}、{{labels.xxx}}
     createdAt: number;
     updatedAt: number;
 }
 
+export interface AlertPromQLRuleRequest {
+    name: string;
+    description?: string;
+    enabled: boolean;
+    expr: string;
+    for: number;
+    severity: string;
+    labels?: Record<string, string>;
+    summary?: string;
+}
+
+// PromQL 表达式返回的序列
+export interface PromQLSeries {
+    labels: Record<string, string>;
+    value: number;
+}
+
+// 探针生效的告警规则
+export interface EffectiveAlertRules {
+    rules: AlertRules;
+    source: AlertRuleSource;
+    ruleSetId?: string;
+    ruleSetName?: string;
+}
+
+// 告警静默时间类型：指定时间段、每周固定时段
+export type AlertSilenceMode = 'once' | 'weekly';
+
+// 告警静默（维护窗口），匹配的告警仍会记录但不发送通知
+export interface AlertSilence {
+    id: string;
+    name: string;
+    comment: string;
+    enabled: boolean;
+    agentIds: string[] | null;
+    tags: string[] | null;
+    alertTypes: string[] | null;
+    monitorIds: string[] | null;
+    mode: AlertSilenceMode;
+    startsAt: number;         // 开始时间（once，时间戳毫秒）
+    endsAt: number;           // 结束时间（once，时间戳毫秒）
+    weekdays: number[] | null; // 每周生效的日期（weekly，0 表示周日）
+    startTime: string;        // 每日开始时间 HH:mm（weekly）
+    endTime: string;          // 每日结束时间 HH:mm（weekly，早于开始时间表示跨天）
+    active: boolean;          // 当前是否生效
+    createdAt: number;
+    updatedAt: number;
+}
+
+export interface AlertSilenceRequest {
+    name: string;
+    comment?: string;
+    enabled: boolean;
+    agentIds?: string[];
+    tags?: string[];
+    alertTypes?: string[];
+    monitorIds?: string[];
+    mode: AlertSilenceMode;
+    startsAt?: number;
+    endsAt?: number;
+    weekdays?: number[];
+    startTime?: string;
+    endTime?: string;
+}
+
 // 流量统计相关
 export interface TrafficAlerts {
     sent80: boolean;
@@ -567,6 +744,7 @@ export interface UpdateTrafficConfigRequest {
 export interface SSHLoginConfig {
     enabled: boolean;
     ipWhitelist?: string[];  // IP白名单，白名单中的IP只记录不发送通知
+    banPolicy?: SSHBanPolicy; // 暴力破解封禁策略
     applyStatus?: string;  // 配置应用状态: success/failed/pending
     applyMessage?: string; // 应用结果消息
 }
@@ -577,11 +755,17 @@ export interface SSHLoginEvent {
     username: string;
     ip: string;
     ipLocation?: string;
+    countryCode?: string;
+    asn?: number;
+    asOrg?: string;
     port?: string;
     status: 'success' | 'failed';
     method?: string;
     tty?: string;
     sessionId?: string;
+    anomalies?: string[];     // 异常类型: new_country/new_asn/impossible_travel/unusual_hour/new_network
+    anomalyDetail?: string;   // 异常说明
+    severity?: 'high' | 'low'; // 异常严重程度
     timestamp: number;
     createdAt: number;
 }
@@ -589,6 +773,72 @@ export interface SSHLoginEvent {
 export interface UpdateSSHLoginConfigRequest {
     enabled: boolean;
     ipWhitelist?: string[];  // IP白名单，白名单中的IP只记录不发送通知
+    banPolicy?: SSHBanPolicy; // 不传时保留原有封禁策略
+}
+
+// SSH 暴力破解封禁策略
+export interface SSHBanPolicy {
+    enabled: boolean;
+    maxFailures: number; // 时间窗口内允许的登录失败次数
+    window: number;      // 统计时间窗口（分钟）
+    banDuration: number; // 封禁时长（分钟）
+    ipv4Prefix: number;  // IPv4 聚合前缀长度，32 表示单个 IP
+    ipv6Prefix: number;  // IPv6 聚合前缀长度，128 表示单个 IP
+}
+
+export type SSHBanStatus = 'pending' | 'active' | 'failed' | 'lifted' | 'expired';
+
+// SSH 封禁记录
+export interface SSHBan {
+    id: string;
+    agentId: string;
+    cidr: string;
+    failures: number;
+    reason: string;
+    status: SSHBanStatus;
+    message?: string;
+    expiresAt: number;
+    liftedBy?: string;
+    liftedAt?: number;
+    createdAt: number;
+}
+
+export type SSHSessionStatus = 'active' | 'closed';
+
+// SSH 会话
+export interface SSHSession {
+    id: string;
+    agentId: string;
+    sessionId: string;   // 探针上报的会话ID（sshd 会话进程 PID）
+    username: string;
+    ip: string;
+    ipLocation?: string;
+    port?: string;
+    tty?: string;
+    loginEventId?: string;
+    status: SSHSessionStatus;
+    startedAt: number;
+    endedAt?: number;
+    duration: number;    // 会话时长（秒），在线会话为截至查询时的时长
+    sudoCount: number;
+    lastSudoAt?: number;
+    createdAt: number;
+}
+
+// SSH 会话内的 sudo 提权记录
+export interface SSHSudoEvent {
+    id: string;
+    agentId: string;
+    sessionId: string;
+    username: string;
+    tty?: string;
+    runAs?: string;
+    command: string;
+    pwd?: string;
+    success: boolean;
+    message?: string;
+    timestamp: number;
+    createdAt: number;
 }
 
 // 导出 DDNS 相关类型